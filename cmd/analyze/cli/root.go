@@ -40,6 +40,9 @@ func RootCmd() *cobra.Command {
 
 	cmd.Flags().String("analyzers", "", "filename or url of the analyzers to use")
 	cmd.Flags().Bool("debug", false, "enable debug logging")
+	cmd.Flags().StringSlice("message-catalog", []string{}, "files with translations of analyzer outcome messages")
+	cmd.Flags().String("language", "", "language to translate analyzer outcome messages to with --message-catalog, such as de or pt-BR. defaults to the language of the locale")
+	cmd.Flags().String("cache-file", "", "file to cache analyzer results in, so that analyzing the same bundle again only runs the analyzers that changed")
 
 	viper.BindPFlags(cmd.Flags())
 
@@ -9,6 +9,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/troubleshoot/cmd/util"
 	analyzer "github.com/replicatedhq/troubleshoot/pkg/analyze"
+	"github.com/replicatedhq/troubleshoot/pkg/logger"
 	"github.com/spf13/viper"
 )
 
@@ -48,11 +49,40 @@ func runAnalyzers(v *viper.Viper, bundlePath string) error {
 		specContent = string(body)
 	}
 
-	analyzeResults, err := analyzer.DownloadAndAnalyze(bundlePath, specContent)
+	var cache *analyzer.AnalyzerCache
+	if cacheFile := v.GetString("cache-file"); cacheFile != "" {
+		cache, err = analyzer.LoadAnalyzerCache(cacheFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	catalog, err := analyzer.LoadMessageCatalogs(v.GetStringSlice("message-catalog"))
+	if err != nil {
+		return err
+	}
+	language := v.GetString("language")
+	if language == "" {
+		// defaults to the language of the locale
+		language = analyzer.LanguageFromEnv()
+	}
+
+	analyzeResults, err := analyzer.DownloadAndAnalyzeWithOptions(bundlePath, specContent, analyzer.DownloadAndAnalyzeOptions{
+		Cache:          cache,
+		MessageCatalog: catalog,
+		Language:       language,
+	})
 	if err != nil {
 		return errors.Wrap(err, "failed to download and analyze bundle")
 	}
 
+	if cache != nil {
+		if err := cache.Save(); err != nil {
+			return err
+		}
+		logger.Printf("%d analyzers were cached, %d were run", cache.Hits, cache.Misses)
+	}
+
 	for _, analyzeResult := range analyzeResults {
 		if analyzeResult.IsPass {
 			fmt.Printf("Pass: %s\n %s\n", analyzeResult.Title, analyzeResult.Message)
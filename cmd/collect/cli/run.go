@@ -18,6 +18,7 @@ import (
 	"github.com/replicatedhq/troubleshoot/pkg/docrewrite"
 	"github.com/replicatedhq/troubleshoot/pkg/k8sutil"
 	"github.com/replicatedhq/troubleshoot/pkg/specs"
+	"github.com/replicatedhq/troubleshoot/pkg/strictdecode"
 	"github.com/replicatedhq/troubleshoot/pkg/supportbundle"
 	"github.com/spf13/viper"
 	"k8s.io/apimachinery/pkg/labels"
@@ -89,7 +90,7 @@ func runCollect(v *viper.Viper, arg string) error {
 	multidocs := strings.Split(string(collectorContent), "\n---\n")
 
 	troubleshootclientsetscheme.AddToScheme(scheme.Scheme)
-	decode := scheme.Codecs.UniversalDeserializer().Decode
+	decode := strictdecode.NewDecoder(scheme.Codecs.UniversalDeserializer()).Decode
 
 	additionalRedactors := &troubleshootv1beta2.Redactor{}
 	for idx, redactor := range v.GetStringSlice("redactors") {
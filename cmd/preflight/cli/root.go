@@ -5,8 +5,11 @@ import (
 	"strings"
 
 	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
 	"github.com/replicatedhq/troubleshoot/pkg/k8sutil"
+	"github.com/replicatedhq/troubleshoot/pkg/logger"
 	"github.com/replicatedhq/troubleshoot/pkg/preflight"
+	"github.com/replicatedhq/troubleshoot/pkg/profiling"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"k8s.io/klog/v2"
@@ -14,11 +17,14 @@ import (
 
 func RootCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "preflight [url]",
+		Use:   "preflight [urls...]",
 		Args:  cobra.MinimumNArgs(1),
 		Short: "Run and retrieve preflight checks in a cluster",
 		Long: `A preflight check is a set of validations that can and should be run to ensure
-that a cluster meets the requirements to run an application.`,
+that a cluster meets the requirements to run an application.
+
+Several specs can be given, and a spec can have several documents. Their collectors
+and analyzers are merged in order, and the name and settings of the first spec are used.`,
 		SilenceUsage: true,
 		PreRun: func(cmd *cobra.Command, args []string) {
 			v := viper.GetViper()
@@ -30,7 +36,20 @@ that a cluster meets the requirements to run an application.`,
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			v := viper.GetViper()
-			return preflight.RunPreflights(v.GetBool("interactive"), v.GetString("output"), v.GetString("format"), args[0])
+
+			if profileDir := v.GetString("profile"); profileDir != "" {
+				stopProfiling, err := profiling.Start(profileDir)
+				if err != nil {
+					return errors.Wrap(err, "failed to start profiling")
+				}
+				defer func() {
+					if err := stopProfiling(); err != nil {
+						logger.Printf("Failed to write profiles: %v", err)
+					}
+				}()
+			}
+
+			return preflight.RunPreflights(v.GetBool("interactive"), v.GetString("output"), v.GetString("format"), args)
 		},
 	}
 
@@ -38,6 +57,7 @@ that a cluster meets the requirements to run an application.`,
 
 	cmd.AddCommand(VersionCmd())
 	preflight.AddFlags(cmd.PersistentFlags())
+	cmd.Flags().String("profile", "", "write cpu, heap and trace profiles of the preflight run to this directory")
 
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 
@@ -21,11 +21,13 @@ func Analyze() *cobra.Command {
 		Use:   "analyze [url]",
 		Args:  cobra.MinimumNArgs(1),
 		Short: "analyze a support bundle",
-		Long:  `Analyze a support bundle using the Analyzer definitions provided`,
+		Long: `Analyze a support bundle using the Analyzer definitions provided. Bundles in S3 or MinIO, at
+s3://bucket/key urls, are streamed rather than downloaded first.`,
 		PreRun: func(cmd *cobra.Command, args []string) {
 			viper.BindPFlag("bundle", cmd.Flags().Lookup("bundle"))
 			viper.BindPFlag("output", cmd.Flags().Lookup("output"))
 			viper.BindPFlag("quiet", cmd.Flags().Lookup("quiet"))
+			viper.BindPFlag("cache-file", cmd.Flags().Lookup("cache-file"))
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			v := viper.GetViper()
@@ -38,11 +40,26 @@ func Analyze() *cobra.Command {
 				return err
 			}
 
-			result, err := analyzer.DownloadAndAnalyze(v.GetString("bundle"), analyzerSpec)
+			var cache *analyzer.AnalyzerCache
+			if cacheFile := v.GetString("cache-file"); cacheFile != "" {
+				cache, err = analyzer.LoadAnalyzerCache(cacheFile)
+				if err != nil {
+					return err
+				}
+			}
+
+			result, err := analyzer.DownloadAndAnalyzeWithCache(v.GetString("bundle"), analyzerSpec, cache)
 			if err != nil {
 				return err
 			}
 
+			if cache != nil {
+				if err := cache.Save(); err != nil {
+					return err
+				}
+				logger.Printf("%d analyzers were cached, %d were run", cache.Hits, cache.Misses)
+			}
+
 			var data interface{}
 			switch v.GetString("compatibility") {
 			case "support-bundle":
@@ -70,12 +87,13 @@ func Analyze() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().String("bundle", "", "filename of the support bundle to analyze")
+	cmd.Flags().String("bundle", "", "filename, url or s3://bucket/key of the support bundle or must-gather to analyze")
 	cmd.MarkFlagRequired("bundle")
 	cmd.Flags().String("output", "", "output format: json, yaml")
 	cmd.Flags().String("compatibility", "", "output compatibility mode: support-bundle")
 	cmd.Flags().MarkHidden("compatibility")
 	cmd.Flags().Bool("quiet", false, "enable/disable error messaging and only show parseable output")
+	cmd.Flags().String("cache-file", "", "file to cache analyzer results in, so that analyzing the same bundle again only runs the analyzers that changed")
 
 	viper.BindPFlags(cmd.Flags())
 
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	analyzer "github.com/replicatedhq/troubleshoot/pkg/analyze"
+	"github.com/replicatedhq/troubleshoot/pkg/diff"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+func Diff() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff [bundle-a] [bundle-b]",
+		Args:  cobra.RangeArgs(1, 2),
+		Short: "compare two support bundles",
+		Long: `Compare two support bundles, or extracted support bundle directories, and report the files that
+were added, removed or changed, the cluster resources that changed, and the analyzer results that
+differ. Useful to compare a broken environment against a known good one.
+
+With --assert-profile, compare one support bundle against a reference profile of the values that its
+files are expected to have, such as the Kubernetes version and distribution in summary.json, and
+report the values that drifted from it. The command fails if any did.`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlag("output", cmd.Flags().Lookup("output"))
+			viper.BindPFlag("assert-profile", cmd.Flags().Lookup("assert-profile"))
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			if profilePath := v.GetString("assert-profile"); profilePath != "" {
+				if len(args) != 1 {
+					return errors.New("--assert-profile compares one bundle")
+				}
+				return assertProfile(args[0], profilePath, v.GetString("output"))
+			}
+			if len(args) != 2 {
+				return errors.New("diff compares two bundles")
+			}
+
+			rootDirs := []string{}
+			for _, bundlePath := range args {
+				bundleDir, err := openBundle(bundlePath)
+				if err != nil {
+					return err
+				}
+				if bundleDir != bundlePath {
+					defer os.RemoveAll(bundleDir)
+				}
+
+				rootDir, err := analyzer.FindBundleRootDir(bundleDir)
+				if err != nil {
+					return errors.Wrapf(err, "failed to find bundle root dir of %s", bundlePath)
+				}
+				rootDirs = append(rootDirs, rootDir)
+			}
+
+			result, err := diff.Bundles(rootDirs[0], rootDirs[1])
+			if err != nil {
+				return errors.Wrap(err, "failed to compare bundles")
+			}
+
+			return writeDiffResult(result, result.WriteText, v.GetString("output"))
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", "", "output format: text, json, yaml")
+	cmd.Flags().String("assert-profile", "", "compare the bundle against this reference profile and fail if it drifted from it")
+
+	return cmd
+}
+
+func assertProfile(bundlePath string, profilePath string, output string) error {
+	b, err := os.ReadFile(profilePath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read profile")
+	}
+	profile, err := diff.LoadProfile(b)
+	if err != nil {
+		return err
+	}
+
+	bundleDir, err := openBundle(bundlePath)
+	if err != nil {
+		return err
+	}
+	if bundleDir != bundlePath {
+		defer os.RemoveAll(bundleDir)
+	}
+	rootDir, err := analyzer.FindBundleRootDir(bundleDir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find bundle root dir of %s", bundlePath)
+	}
+
+	result, err := diff.AssertProfile(rootDir, profile)
+	if err != nil {
+		return errors.Wrap(err, "failed to compare the bundle with the profile")
+	}
+
+	if err := writeDiffResult(result, result.WriteText, output); err != nil {
+		return err
+	}
+	if len(result.Drift) > 0 {
+		return errors.Errorf("%d values drifted from the profile", len(result.Drift))
+	}
+	return nil
+}
+
+// writeDiffResult writes a result of diff in the output format, with writeText for text
+func writeDiffResult(result interface{}, writeText func(io.Writer) error, output string) error {
+	switch output {
+	case "", "text":
+		return writeText(os.Stdout)
+	case "json":
+		formatted, err := json.MarshalIndent(result, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n", formatted)
+	case "yaml":
+		formatted, err := yaml.Marshal(result)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s", formatted)
+	default:
+		return fmt.Errorf("unsupported output format: %q", output)
+	}
+
+	return nil
+}
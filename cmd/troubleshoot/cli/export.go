@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	analyzer "github.com/replicatedhq/troubleshoot/pkg/analyze"
+	"github.com/replicatedhq/troubleshoot/pkg/export"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func Export() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [bundle]",
+		Args:  cobra.ExactArgs(1),
+		Short: "export a support bundle to another layout",
+		Long: `Export a support bundle, or an extracted support bundle directory, to the directory
+layout of another tool so that existing pipelines can read it. Supported formats: must-gather`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlag("format", cmd.Flags().Lookup("format"))
+			viper.BindPFlag("output", cmd.Flags().Lookup("output"))
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			bundlePath := args[0]
+			outputDir := v.GetString("output")
+			if outputDir == "" {
+				name := bundleName(bundlePath)
+				outputDir = name + "-" + v.GetString("format")
+			}
+
+			bundleDir, err := openBundle(bundlePath)
+			if err != nil {
+				return err
+			}
+			if bundleDir != bundlePath {
+				defer os.RemoveAll(bundleDir)
+			}
+
+			rootDir, err := analyzer.FindBundleRootDir(bundleDir)
+			if err != nil {
+				return errors.Wrap(err, "failed to find bundle root dir")
+			}
+
+			switch v.GetString("format") {
+			case "must-gather":
+				err = export.MustGather(rootDir, outputDir)
+			default:
+				return fmt.Errorf("unsupported export format: %q", v.GetString("format"))
+			}
+			if err != nil {
+				return errors.Wrap(err, "failed to export bundle")
+			}
+
+			fmt.Printf("%s\n", outputDir)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("format", "must-gather", "layout to export the bundle to: must-gather")
+	cmd.Flags().StringP("output", "o", "", "directory to write the exported bundle to")
+
+	return cmd
+}
+
+// openBundle returns the directory of an extracted bundle, extracting archives to a temp dir. Archives
+// in object storage, at s3://bucket/key urls, are streamed rather than downloaded.
+func openBundle(bundlePath string) (string, error) {
+	var f io.ReadCloser
+	if analyzer.IsS3URL(bundlePath) {
+		r, err := analyzer.OpenS3Bundle(bundlePath)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to open bundle")
+		}
+		f = r
+	} else {
+		info, err := os.Stat(bundlePath)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to stat bundle")
+		}
+		if info.IsDir() {
+			return bundlePath, nil
+		}
+
+		file, err := os.Open(bundlePath)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to open bundle")
+		}
+		f = file
+	}
+	defer f.Close()
+
+	tmpDir, err := ioutil.TempDir("", "troubleshoot-export-")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create temp dir")
+	}
+
+	if err := analyzer.ExtractTroubleshootBundle(f, tmpDir); err != nil {
+		os.RemoveAll(tmpDir)
+		return "", errors.Wrap(err, "failed to extract bundle")
+	}
+
+	return tmpDir, nil
+}
+
+// bundleName returns the name of a bundle without its extension, to name the files made from it
+func bundleName(bundlePath string) string {
+	if analyzer.IsS3URL(bundlePath) {
+		if u, err := url.Parse(bundlePath); err == nil {
+			bundlePath = u.Path
+		}
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(filepath.Base(bundlePath), ".tar.gz"), ".tgz")
+}
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -200,6 +201,16 @@ func drawDetails(analysisResult *analyzerunner.AnalyzeResult) {
 	ui.Render(message)
 	currentTop = currentTop + height + 1
 
+	if analysisResult.DocString != "" {
+		docString := widgets.NewParagraph()
+		docString.Text = analysisResult.DocString
+		docString.Border = false
+		height = estimateNumberOfLines(docString.Text, termWidth/2) + 2
+		docString.SetRect(termWidth/2, currentTop, termWidth, currentTop+height)
+		ui.Render(docString)
+		currentTop = currentTop + height + 1
+	}
+
 	if analysisResult.URI != "" {
 		uri := widgets.NewParagraph()
 		uri.Text = fmt.Sprintf("For more information: %s", analysisResult.URI)
@@ -209,6 +220,16 @@ func drawDetails(analysisResult *analyzerunner.AnalyzeResult) {
 		height = estimateNumberOfLines(uri.Text, termWidth/2) + 2
 		uri.SetRect(termWidth/2, currentTop, termWidth, currentTop+height)
 		ui.Render(uri)
+		currentTop = currentTop + height + 1
+	}
+
+	if analysisResult.RemediationURI != "" {
+		remediation := widgets.NewParagraph()
+		remediation.Text = fmt.Sprintf("Remediation: %s", analysisResult.RemediationURI)
+		remediation.Border = false
+		height = estimateNumberOfLines(remediation.Text, termWidth/2) + 2
+		remediation.SetRect(termWidth/2, currentTop, termWidth, currentTop+height)
+		ui.Render(remediation)
 	}
 }
 
@@ -262,6 +283,18 @@ func save(analyzeResults []*analyzerunner.AnalyzeResult) (string, error) {
 			result = result + fmt.Sprintf("URI: %s\n", analyzeResult.URI)
 		}
 
+		if analyzeResult.Category != "" {
+			result = result + fmt.Sprintf("Category: %s\n", analyzeResult.Category)
+		}
+
+		if len(analyzeResult.Tags) > 0 {
+			result = result + fmt.Sprintf("Tags: %s\n", strings.Join(analyzeResult.Tags, ", "))
+		}
+
+		if analyzeResult.RemediationURI != "" {
+			result = result + fmt.Sprintf("Remediation: %s\n", analyzeResult.RemediationURI)
+		}
+
 		result = result + "\n------------\n"
 
 		results = results + result
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"github.com/replicatedhq/troubleshoot/pkg/supportbundle"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func RBAC() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rbac",
+		Args:  cobra.NoArgs,
+		Short: "print the roles that the collectors of a spec need",
+		Long: `Print a ServiceAccount, and the ClusterRole, Roles and bindings with the least privileges that the
+collectors of a support bundle spec need, as yaml that can be applied with kubectl. Collectors in all
+namespaces need a ClusterRole, and collectors in a namespace need a Role in that namespace.
+
+The custom resources collected by clusterResources, and custom collectors, are not included.`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlag("spec", cmd.Flags().Lookup("spec"))
+			viper.BindPFlag("namespace", cmd.Flags().Lookup("namespace"))
+			viper.BindPFlag("name", cmd.Flags().Lookup("name"))
+			viper.BindPFlag("service-account", cmd.Flags().Lookup("service-account"))
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			supportBundle, err := supportbundle.GetSupportBundleFromURI(v.GetString("spec"))
+			if err != nil {
+				return errors.Wrap(err, "failed to load support bundle spec")
+			}
+
+			// the same collectors as a support bundle runs
+			collectors := collect.ScopeCollectorsToNodes(supportBundle.Spec.Collectors, supportBundle.Spec.NodeSelector)
+			collectors = collect.EnsureCollectorInList(collectors, troubleshootv1beta2.Collect{ClusterInfo: &troubleshootv1beta2.ClusterInfo{}})
+			collectors = collect.EnsureCollectorInList(collectors, troubleshootv1beta2.Collect{ClusterResources: &troubleshootv1beta2.ClusterResources{}})
+
+			manifests, err := collect.RBACManifests(collectors, collect.RBACManifestOptions{
+				Name:           v.GetString("name"),
+				Namespace:      v.GetString("namespace"),
+				ServiceAccount: v.GetString("service-account"),
+			})
+			if err != nil {
+				return errors.Wrap(err, "failed to generate rbac manifests")
+			}
+
+			fmt.Printf("%s", manifests)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("spec", "", "support bundle spec to print the roles of")
+	cmd.MarkFlagRequired("spec")
+	cmd.Flags().StringP("namespace", "n", "default", "namespace of the service account, and of collectors that do not set one")
+	cmd.Flags().String("name", "troubleshoot", "name of the roles and role bindings")
+	cmd.Flags().String("service-account", "troubleshoot", "name of the service account to bind the roles to")
+
+	return cmd
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	analyzer "github.com/replicatedhq/troubleshoot/pkg/analyze"
+	"github.com/replicatedhq/troubleshoot/pkg/k8sutil"
+	"github.com/replicatedhq/troubleshoot/pkg/redact"
+	"github.com/replicatedhq/troubleshoot/pkg/supportbundle"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/client-go/rest"
+)
+
+func Recollect() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recollect [urls...]",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "run collectors of a support bundle again",
+		Long: `Run the collectors with the given titles again, such as logs/my-app, and write a copy of an existing
+support bundle with their files replaced by what they collect now. This is for refreshing a stale or
+failed collector without collecting the whole bundle again. The collectors are read from the given specs,
+which should be the specs the bundle was collected with. The original bundle is not modified, and the
+analysis is not run again.`,
+		SilenceUsage: true,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlag("bundle", cmd.Flags().Lookup("bundle"))
+			viper.BindPFlag("collector", cmd.Flags().Lookup("collector"))
+			viper.BindPFlag("output", cmd.Flags().Lookup("output"))
+			viper.BindPFlag("redact", cmd.Flags().Lookup("redact"))
+			viper.BindPFlag("redactors", cmd.Flags().Lookup("redactors"))
+			viper.BindPFlag("since-time", cmd.Flags().Lookup("since-time"))
+			viper.BindPFlag("since", cmd.Flags().Lookup("since"))
+			viper.BindPFlag("reproducible", cmd.Flags().Lookup("reproducible"))
+			viper.BindPFlag("no-uri", cmd.Flags().Lookup("no-uri"))
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			bundlePath := v.GetString("bundle")
+			if bundlePath == "" {
+				return errors.New("--bundle is required")
+			}
+			titles := v.GetStringSlice("collector")
+			if len(titles) == 0 {
+				return errors.New("at least one --collector is required")
+			}
+
+			outputFilename := v.GetString("output")
+			if outputFilename == "" {
+				name := bundleName(bundlePath)
+				outputFilename = name + "-recollected.tar.gz"
+			}
+			if _, err := os.Stat(outputFilename); err == nil {
+				return errors.Errorf("%s already exists", outputFilename)
+			}
+
+			var sinceTime *time.Time
+			if v.GetString("since-time") != "" || v.GetString("since") != "" {
+				var err error
+				sinceTime, err = parseTimeFlags(v)
+				if err != nil {
+					return err
+				}
+			}
+
+			mainBundle, additionalRedactors, err := loadSpecArgs(args, !v.GetBool("no-uri"))
+			if err != nil {
+				return err
+			}
+			for idx, redactor := range v.GetStringSlice("redactors") {
+				redactorObj, err := supportbundle.GetRedactorFromURI(redactor)
+				if err != nil {
+					return errors.Wrapf(err, "failed to get redactor spec %s, #%d", redactor, idx)
+				}
+				supportbundle.AppendRedactor(additionalRedactors, redactorObj)
+			}
+			if err := redact.ValidateDefaultRedactorGroups(additionalRedactors.Spec.ExcludeDefaultRedactors); err != nil {
+				return errors.Wrap(err, "failed to exclude default redactors")
+			}
+
+			restConfig, err := k8sutil.GetRESTConfig()
+			if err != nil {
+				return errors.Wrap(err, "failed to convert kube flags to rest config")
+			}
+			restConfig.WarningHandler = rest.NoWarnings{}
+
+			bundleDir, err := openBundle(bundlePath)
+			if err != nil {
+				return err
+			}
+			if bundleDir != bundlePath {
+				defer os.RemoveAll(bundleDir)
+			}
+
+			rootDir, err := analyzer.FindBundleRootDir(bundleDir)
+			if err != nil {
+				return errors.Wrap(err, "failed to find bundle root dir")
+			}
+
+			progressChan := make(chan interface{})
+			go func() {
+				for msg := range progressChan {
+					if err, ok := msg.(error); ok {
+						fmt.Fprintf(os.Stderr, " * %v\n", err)
+					}
+				}
+			}()
+			defer close(progressChan)
+
+			response, err := supportbundle.RecollectBundle(rootDir, &mainBundle.Spec, additionalRedactors, titles, outputFilename, supportbundle.SupportBundleCreateOpts{
+				KubernetesRestConfig: restConfig,
+				Namespace:            v.GetString("namespace"),
+				ProgressChan:         progressChan,
+				SinceTime:            sinceTime,
+				Redact:               v.GetBool("redact"),
+				FromCLI:              true,
+				Reproducible:         v.GetBool("reproducible"),
+			})
+			if err != nil {
+				return errors.Wrap(err, "failed to run collectors again")
+			}
+
+			fmt.Printf("%s\n", response.ArchivePath)
+			printCollectionErrorsSummary(response.CollectionErrors)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("bundle", "", "support bundle, or extracted support bundle directory, to run the collectors again for")
+	cmd.Flags().StringSlice("collector", []string{}, "titles of the collectors to run again, such as logs/my-app")
+	cmd.Flags().StringP("output", "o", "", "file name of the new bundle, defaults to the bundle's name with -recollected appended. a path ending in .zip is written as a .zip archive")
+	cmd.Flags().Bool("redact", true, "enable/disable default redactions of the collected files")
+	cmd.Flags().StringSlice("redactors", []string{}, "names of the additional redactors to use")
+	cmd.Flags().String("since-time", "", "only collect logs and events after a specific date (RFC3339)")
+	cmd.Flags().String("since", "", "only collect logs and events newer than a relative duration like 5s, 2m, or 3h.")
+	cmd.Flags().Bool("reproducible", false, "write an archive with the same bytes for the same files, without file timestamps, owners, permissions or collection times")
+	cmd.Flags().Bool("no-uri", false, "do not retrieve the upstream spec referenced by the uri: field of a spec")
+
+	return cmd
+}
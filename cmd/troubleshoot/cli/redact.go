@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	analyzer "github.com/replicatedhq/troubleshoot/pkg/analyze"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/k8sutil"
+	"github.com/replicatedhq/troubleshoot/pkg/redact"
+	"github.com/replicatedhq/troubleshoot/pkg/supportbundle"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+	"k8s.io/client-go/kubernetes"
+)
+
+func Redact() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "redact [bundle]",
+		Args:  cobra.ExactArgs(1),
+		Short: "redact an existing support bundle",
+		Long: `Run the default redactors and the redactors of the given specs against a support bundle, or an
+extracted support bundle directory, and write the redacted bundle to a new archive. This is for bundles
+that were collected before a secret was known to need redacting. The original bundle is not modified.
+
+With --dry-run, the file, line and redactor of every redaction that would be made are printed instead,
+so redactors can be tuned before they are shipped. Files in tar archives within the bundle are not
+previewed.`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlag("redactors", cmd.Flags().Lookup("redactors"))
+			viper.BindPFlag("dry-run", cmd.Flags().Lookup("dry-run"))
+			viper.BindPFlag("output", cmd.Flags().Lookup("output"))
+			viper.BindPFlag("format", cmd.Flags().Lookup("format"))
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			additionalRedactors := &troubleshootv1beta2.Redactor{}
+			for idx, redactor := range v.GetStringSlice("redactors") {
+				redactorObj, err := supportbundle.GetRedactorFromURI(redactor)
+				if err != nil {
+					return errors.Wrapf(err, "failed to get redactor spec %s, #%d", redactor, idx)
+				}
+				supportbundle.AppendRedactor(additionalRedactors, redactorObj)
+			}
+
+			redactOpts := redact.Options{ExcludeDefaultRedactors: additionalRedactors.Spec.ExcludeDefaultRedactors}
+			if err := redact.ValidateDefaultRedactorGroups(redactOpts.ExcludeDefaultRedactors); err != nil {
+				return errors.Wrap(err, "failed to exclude default redactors")
+			}
+
+			redactors, err := resolveRedactorValues(additionalRedactors.Spec.Redactors)
+			if err != nil {
+				return err
+			}
+
+			bundlePath := args[0]
+			bundleDir, err := openBundle(bundlePath)
+			if err != nil {
+				return err
+			}
+			if bundleDir != bundlePath {
+				defer os.RemoveAll(bundleDir)
+			}
+
+			rootDir, err := analyzer.FindBundleRootDir(bundleDir)
+			if err != nil {
+				return errors.Wrap(err, "failed to find bundle root dir")
+			}
+
+			if !v.GetBool("dry-run") {
+				outputFilename := v.GetString("output")
+				if outputFilename == "" {
+					name := bundleName(bundlePath)
+					outputFilename = name + "-redacted.tar.gz"
+				}
+				if _, err := os.Stat(outputFilename); err == nil {
+					return errors.Errorf("%s already exists", outputFilename)
+				}
+
+				redactions, err := supportbundle.RedactBundle(rootDir, redactors, outputFilename, redactOpts)
+				if err != nil {
+					return errors.Wrap(err, "failed to redact bundle")
+				}
+
+				fmt.Printf("%s\n%s\n", redactions.Summary(), outputFilename)
+				return nil
+			}
+
+			redactions, err := redact.DryRun(rootDir, redactors, redactOpts)
+			if err != nil {
+				return errors.Wrap(err, "failed to preview redactions")
+			}
+
+			switch v.GetString("format") {
+			case "", "text":
+				fmt.Printf("%s\n%s", redactions.Details(), redactions.Summary())
+			case "json":
+				formatted, err := json.MarshalIndent(redactions, "", "    ")
+				if err != nil {
+					return err
+				}
+				fmt.Printf("%s\n", formatted)
+			case "yaml":
+				formatted, err := yaml.Marshal(redactions)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("%s", formatted)
+			default:
+				return fmt.Errorf("unsupported output format: %q", v.GetString("format"))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSlice("redactors", []string{}, "names of the additional redactors to use")
+	cmd.Flags().Bool("dry-run", false, "print the redactions that would be made instead of writing a redacted bundle")
+	cmd.Flags().StringP("output", "o", "", "file name of the redacted bundle, defaults to the bundle's name with -redacted appended")
+	cmd.Flags().String("format", "", "output format of --dry-run: text, json, yaml")
+
+	cmd.AddCommand(RedactTest())
+
+	return cmd
+}
+
+// resolveRedactorValues reads the valuesFrom of the redactors from the cluster. The cluster is only
+// needed when there are valuesFrom.
+func resolveRedactorValues(redactors []*troubleshootv1beta2.Redact) ([]*troubleshootv1beta2.Redact, error) {
+	hasValuesFrom := false
+	for _, redactor := range redactors {
+		if redactor != nil && len(redactor.Removals.ValuesFrom) > 0 {
+			hasValuesFrom = true
+		}
+	}
+	if !hasValuesFrom {
+		return redactors, nil
+	}
+
+	restConfig, err := k8sutil.GetRESTConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert kube flags to rest config")
+	}
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create kubernetes client")
+	}
+
+	resolved, err := redact.ResolveValuesFrom(context.Background(), client, redactors)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve redactor values")
+	}
+	return resolved, nil
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/redact"
+	"github.com/replicatedhq/troubleshoot/pkg/supportbundle"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func RedactTest() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test [files]",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "test redactors against sample files",
+		Long: `Run the default redactors and the redactors of the given specs against sample files, and print a
+diff of each file before and after it was redacted, with the number of redactions made by each redactor.
+Redactors of the specs that did not redact anything are listed, so redaction rules can be checked
+without collecting a support bundle. The files are not modified.
+
+A file is redacted as the file at the same path in a support bundle, for redactors with file selectors.
+A different path can be given after an =, e.g. ./samples/api.log=app/logs/api.log.`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlag("redactors", cmd.Flags().Lookup("redactors"))
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			additionalRedactors := &troubleshootv1beta2.Redactor{}
+			for idx, redactor := range v.GetStringSlice("redactors") {
+				redactorObj, err := supportbundle.GetRedactorFromURI(redactor)
+				if err != nil {
+					return errors.Wrapf(err, "failed to get redactor spec %s, #%d", redactor, idx)
+				}
+				supportbundle.AppendRedactor(additionalRedactors, redactorObj)
+			}
+
+			redactOpts := redact.Options{ExcludeDefaultRedactors: additionalRedactors.Spec.ExcludeDefaultRedactors}
+			if err := redact.ValidateDefaultRedactorGroups(redactOpts.ExcludeDefaultRedactors); err != nil {
+				return errors.Wrap(err, "failed to exclude default redactors")
+			}
+
+			redactors, err := resolveRedactorValues(additionalRedactors.Spec.Redactors)
+			if err != nil {
+				return err
+			}
+
+			samples := []redact.Sample{}
+			for _, arg := range args {
+				file, path := arg, filepath.ToSlash(filepath.Clean(arg))
+				if i := strings.LastIndex(arg, "="); i != -1 {
+					file, path = arg[:i], arg[i+1:]
+				}
+				input, err := ioutil.ReadFile(file)
+				if err != nil {
+					return errors.Wrapf(err, "failed to read %s", file)
+				}
+				samples = append(samples, redact.Sample{Path: path, Input: input})
+			}
+
+			result, err := redact.RedactSamples(samples, redactors, redactOpts)
+			if err != nil {
+				return errors.Wrap(err, "failed to redact samples")
+			}
+
+			for _, sample := range result.Samples {
+				diff, err := sample.Diff()
+				if err != nil {
+					return errors.Wrapf(err, "failed to diff %s", sample.Path)
+				}
+				if diff == "" {
+					fmt.Printf("%s: nothing redacted\n\n", sample.Path)
+					continue
+				}
+				printRedactionDiff(diff)
+				fmt.Println()
+			}
+
+			fmt.Print(result.Redactions.Summary())
+			if len(result.Unmatched) > 0 {
+				fmt.Printf("\nRedactors that did not redact anything:\n")
+				for _, name := range result.Unmatched {
+					fmt.Printf("  %s\n", name)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSlice("redactors", []string{}, "names of the additional redactors to use")
+
+	return cmd
+}
+
+// printRedactionDiff prints a unified diff with removed lines in red and added lines in green. Colors
+// are turned off when stdout is not a terminal.
+func printRedactionDiff(diff string) {
+	removed := color.New(color.FgRed)
+	added := color.New(color.FgGreen)
+	hunk := color.New(color.FgCyan)
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			color.New(color.Bold).Fprint(os.Stdout, line)
+		case strings.HasPrefix(line, "@@"):
+			hunk.Fprint(os.Stdout, line)
+		case strings.HasPrefix(line, "-"):
+			removed.Fprint(os.Stdout, line)
+		case strings.HasPrefix(line, "+"):
+			added.Fprint(os.Stdout, line)
+		default:
+			fmt.Print(line)
+		}
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"io/ioutil"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
 	"github.com/replicatedhq/troubleshoot/pkg/k8sutil"
 	"github.com/replicatedhq/troubleshoot/pkg/logger"
+	"github.com/replicatedhq/troubleshoot/pkg/profiling"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"k8s.io/klog/v2"
@@ -15,11 +18,15 @@ import (
 
 func RootCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "support-bundle [url]",
+		Use:   "support-bundle [urls...]",
 		Args:  cobra.MinimumNArgs(0),
 		Short: "Generate a support bundle",
 		Long: `A support bundle is an archive of files, output, metrics and state
-from a server that can be used to assist when troubleshooting a Kubernetes cluster.`,
+from a server that can be used to assist when troubleshooting a Kubernetes cluster.
+
+Several specs can be given, and a spec can have several documents. Their collectors,
+analyzers and redactors are merged in order, and the name and settings of the first
+spec are used.`,
 		SilenceUsage: true,
 		PreRun: func(cmd *cobra.Command, args []string) {
 			v := viper.GetViper()
@@ -33,6 +40,19 @@ from a server that can be used to assist when troubleshooting a Kubernetes clust
 			v := viper.GetViper()
 
 			logger.SetQuiet(v.GetBool("quiet"))
+
+			if profileDir := v.GetString("profile"); profileDir != "" {
+				stopProfiling, err := profiling.Start(profileDir)
+				if err != nil {
+					return errors.Wrap(err, "failed to start profiling")
+				}
+				defer func() {
+					if err := stopProfiling(); err != nil {
+						logger.Printf("Failed to write profiles: %v", err)
+					}
+				}()
+			}
+
 			return runTroubleshoot(v, args)
 		},
 	}
@@ -40,6 +60,12 @@ from a server that can be used to assist when troubleshooting a Kubernetes clust
 	cobra.OnInitialize(initConfig)
 
 	cmd.AddCommand(Analyze())
+	cmd.AddCommand(Export())
+	cmd.AddCommand(Diff())
+	cmd.AddCommand(Redact())
+	cmd.AddCommand(Recollect())
+	cmd.AddCommand(RBAC())
+	cmd.AddCommand(Serve())
 	cmd.AddCommand(VersionCmd())
 
 	cmd.Flags().StringSlice("redactors", []string{}, "names of the additional redactors to use")
@@ -48,10 +74,30 @@ from a server that can be used to assist when troubleshooting a Kubernetes clust
 	cmd.Flags().Bool("collect-without-permissions", true, "always generate a support bundle, even if it some require additional permissions")
 	cmd.Flags().StringSliceP("selector", "l", []string{"troubleshoot.io/kind=supportbundle-spec"}, "selector to filter on for loading additional support bundle specs found in secrets within the cluster")
 	cmd.Flags().Bool("load-cluster-specs", false, "enable/disable loading additional support bundle specs found in secrets within the cluster. required when no specs are provided on the command line")
-	cmd.Flags().String("since-time", "", "force pod logs collectors to return logs after a specific date (RFC3339)")
-	cmd.Flags().String("since", "", "force pod logs collectors to return logs newer than a relative duration like 5s, 2m, or 3h.")
-	cmd.Flags().StringP("output", "o", "", "specify the output file path for the support bundle")
+	cmd.Flags().String("since-time", "", "only collect logs and events after a specific date (RFC3339)")
+	cmd.Flags().String("since", "", "only collect logs and events newer than a relative duration like 5s, 2m, or 3h.")
+	cmd.Flags().StringP("output", "o", "", "specify the output file path for the support bundle. a path ending in .zip is written as a .zip archive, and dir://path writes the bundle to a directory like --output-dir")
+	cmd.Flags().String("output-dir", "", "write the support bundle to this directory instead of an archive. the directory must be empty or not exist")
+	cmd.Flags().Bool("reproducible", false, "write archives with the same bytes for the same collected files, without file timestamps, owners, permissions or collection times, so that they can be diffed and deduplicated")
+	cmd.Flags().Int("collect-concurrency", 0, "number of collectors to run at the same time, overrides the spec's collectConcurrency")
+	cmd.Flags().String("disk-reserve", "100Mi", "free disk space to leave for the bundle's archive, such as 500Mi or 2Gi. collection pauses, and then stops, if collected files would use it. 0 disables the check")
+	cmd.Flags().String("disk-write-rate", "", "limit how fast collected files are written to disk, in bytes per second, such as 20Mi")
+	cmd.Flags().Duration("disk-reserve-wait", time.Minute, "how long collection pauses for disk space to be freed before it stops, with the files collected until then in the bundle")
+	cmd.Flags().StringSlice("namespace-bundles", []string{}, "also write a support bundle for each of these namespaces, with only the namespace's data and cluster scoped data")
+	cmd.Flags().String("upload", "", "upload the support bundle archive to s3://bucket/prefix after it is created")
+	cmd.Flags().String("upload-sse", "", "server side encryption of the uploaded archive: AES256, aws:kms")
+	cmd.Flags().String("upload-sse-kms-key-id", "", "kms key of aws:kms server side encryption of the uploaded archive")
+	cmd.Flags().String("upload-url", "", "upload the support bundle archive to this signed url after it is created, resuming from the last chunk sent if the upload fails")
+	cmd.Flags().Bool("host", false, "collect only host collectors, for a host that may not be in a cluster. the default host collectors are collected if no specs are provided")
+	cmd.Flags().String("summary-file", "", "write a json summary of the run to this file, with the bundle's path and size, the collectors that had errors and the counts of analyzer outcomes")
+	cmd.Flags().Bool("exit-code", false, "exit with 3 if collectors had errors, or else with 4 if analyzers failed, rather than 0 when a bundle is created")
+	cmd.Flags().Bool("estimate", false, "print the projected size of what each collector will collect, without collecting anything")
+	cmd.Flags().StringSlice("message-catalog", []string{}, "files with translations of analyzer outcome messages")
+	cmd.Flags().String("language", "", "language to translate analyzer outcome messages to with --message-catalog, such as de or pt-BR. defaults to the language of the locale")
 	cmd.Flags().Bool("debug", false, "enable debug logging")
+	cmd.Flags().String("profile", "", "write cpu, heap and trace profiles of the collection run to this directory")
+	cmd.Flags().StringSlice("values", []string{}, "path to a yaml file with values available to templated exclude expressions")
+	cmd.Flags().StringArray("set", []string{}, "set values available to templated exclude expressions (e.g. --set key=value)")
 
 	// hidden in favor of the `insecure-skip-tls-verify` flag
 	cmd.Flags().Bool("allow-insecure-connections", false, "when set, do not verify TLS certs when retrieving spec and reporting results")
@@ -72,6 +118,10 @@ from a server that can be used to assist when troubleshooting a Kubernetes clust
 
 func InitAndExecute() {
 	if err := RootCmd().Execute(); err != nil {
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -21,14 +22,17 @@ import (
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
 	"github.com/replicatedhq/troubleshoot/pkg/client/troubleshootclientset/scheme"
 	troubleshootclientsetscheme "github.com/replicatedhq/troubleshoot/pkg/client/troubleshootclientset/scheme"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
 	"github.com/replicatedhq/troubleshoot/pkg/convert"
 	"github.com/replicatedhq/troubleshoot/pkg/httputil"
 	"github.com/replicatedhq/troubleshoot/pkg/k8sutil"
 	"github.com/replicatedhq/troubleshoot/pkg/logger"
 	"github.com/replicatedhq/troubleshoot/pkg/specs"
 	"github.com/replicatedhq/troubleshoot/pkg/supportbundle"
+	"github.com/replicatedhq/troubleshoot/pkg/upload"
 	"github.com/spf13/viper"
 	spin "github.com/tj/go-spin"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -39,7 +43,11 @@ const (
 )
 
 func runTroubleshoot(v *viper.Viper, arg []string) error {
-	if v.GetBool("load-cluster-specs") == false && len(arg) < 1 {
+	// host mode collects from a host that may not be in a cluster, with the default host collectors if no
+	// specs are provided
+	hostMode := v.GetBool("host")
+
+	if v.GetBool("load-cluster-specs") == false && len(arg) < 1 && !hostMode {
 		return errors.New("flag load-cluster-specs must be set if no specs are provided on the command line")
 	}
 
@@ -62,7 +70,11 @@ func runTroubleshoot(v *viper.Viper, arg []string) error {
 
 	restConfig, err := k8sutil.GetRESTConfig()
 	if err != nil {
-		return errors.Wrap(err, "failed to convert kube flags to rest config")
+		if !hostMode {
+			return errors.Wrap(err, "failed to convert kube flags to rest config")
+		}
+		// host collectors don't use it
+		restConfig = &rest.Config{}
 	}
 
 	var sinceTime *time.Time
@@ -73,46 +85,50 @@ func runTroubleshoot(v *viper.Viper, arg []string) error {
 		}
 	}
 
-	if v.GetBool("allow-insecure-connections") || v.GetBool("insecure-skip-tls-verify") {
-		httputil.AddTransport(&http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		})
+	if v.GetString("output") != "" && v.GetString("output-dir") != "" {
+		return errors.New("at most one of `output` or `output-dir` may be specified")
 	}
 
-	var mainBundle *troubleshootv1beta2.SupportBundle
-
-	troubleshootclientsetscheme.AddToScheme(scheme.Scheme)
-	additionalRedactors := &troubleshootv1beta2.Redactor{}
-
-	// Defining `v` below will render using `v` in reference to Viper unusable.
-	// Therefore refactoring `v` to `val` will make sure we can still use it.
-	for i, val := range arg {
+	if v.GetString("upload") != "" && v.GetString("upload-url") != "" {
+		return errors.New("at most one of `upload` or `upload-url` may be specified")
+	}
+	if (v.GetString("upload") != "" || v.GetString("upload-url") != "") && isOutputDir(v) {
+		return errors.New("uploads are of an archive, and cannot be used with `output-dir`")
+	}
+	if (v.GetString("upload") != "" || v.GetString("upload-url") != "") && strings.HasSuffix(v.GetString("output"), ".zip") {
+		return errors.New("uploads are of a .tar.gz archive, and cannot be used with a .zip `output`")
+	}
 
-		collectorContent, err := supportbundle.LoadSupportBundleSpec(val)
+	// created before collecting, so that a bad upload url fails fast
+	var uploader upload.Uploader
+	uploadNamespaceBundles := false
+	if uploadURL := v.GetString("upload"); uploadURL != "" {
+		uploader, err = upload.NewUploader(uploadURL, upload.Options{
+			ServerSideEncryption: v.GetString("upload-sse"),
+			SSEKMSKeyID:          v.GetString("upload-sse-kms-key-id"),
+		})
 		if err != nil {
-			return errors.Wrap(err, "failed to load support bundle spec")
+			return errors.Wrap(err, "failed to create uploader")
 		}
-		multidocs := strings.Split(string(collectorContent), "\n---\n")
-		// Referencing `ParseSupportBundle with a secondary arg of `no-uri`
-		// Will make sure we can enable or disable the use of the `Spec.uri` field for an upstream spec.
-		// This change will not have an impact on KOTS' usage of `ParseSupportBundle`
-		// As Kots uses `load.go` directly.
-		supportBundle, err := supportbundle.ParseSupportBundle([]byte(multidocs[0]), !v.GetBool("no-uri"))
-		if err != nil {
-			return errors.Wrap(err, "failed to parse support bundle spec")
+		uploadNamespaceBundles = true
+	} else if uploadURL := v.GetString("upload-url"); uploadURL != "" {
+		// a signed url is for a single archive, so namespace bundles are not uploaded to it
+		resumableUploader := &upload.ResumableUploader{URL: uploadURL}
+		if !v.GetBool("quiet") {
+			resumableUploader.Progress = upload.ProgressBar(os.Stderr, "Uploading support bundle")
 		}
+		uploader = resumableUploader
+	}
 
-		if i == 0 {
-			mainBundle = supportBundle
-		} else {
-			mainBundle = supportbundle.ConcatSpec(mainBundle, supportBundle)
-		}
+	if v.GetBool("allow-insecure-connections") || v.GetBool("insecure-skip-tls-verify") {
+		httputil.AddTransport(&http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		})
+	}
 
-		parsedRedactors, err := supportbundle.ParseRedactorsFromSpec(multidocs)
-		if err != nil {
-			return errors.Wrap(err, "failed to parse redactors from doc")
-		}
-		additionalRedactors.Spec.Redactors = append(additionalRedactors.Spec.Redactors, parsedRedactors...)
+	mainBundle, additionalRedactors, err := loadSpecArgs(arg, !v.GetBool("no-uri"))
+	if err != nil {
+		return err
 	}
 
 	if v.GetBool("load-cluster-specs") {
@@ -146,24 +162,31 @@ func runTroubleshoot(v *viper.Viper, arg []string) error {
 		if bundlesFromSecrets != nil {
 			for _, bundle := range bundlesFromSecrets {
 				multidocs := strings.Split(string(bundle), "\n---\n")
-				parsedBundlesFromSecrets, err := supportbundle.ParseSupportBundleFromDoc([]byte(multidocs[0]))
+				parsedBundlesFromSecrets, err := supportbundle.ParseSupportBundleDocs(multidocs, true)
 				if err != nil {
 					logger.Printf("failed to parse support bundle spec:  %s", err)
 					continue
 				}
 
+				parsedBundlesFromSecrets, importedRedactors, err := supportbundle.ResolveImports(parsedBundlesFromSecrets, "")
+				if err != nil {
+					logger.Printf("failed to resolve support bundle spec imports:  %s", err)
+					continue
+				}
+				supportbundle.AppendRedactor(additionalRedactors, importedRedactors)
+
 				if mainBundle == nil {
 					mainBundle = parsedBundlesFromSecrets
 				} else {
 					mainBundle = supportbundle.ConcatSpec(mainBundle, parsedBundlesFromSecrets)
 				}
 
-				parsedRedactors, err := supportbundle.ParseRedactorsFromSpec(multidocs)
+				parsedRedactors, err := supportbundle.ParseRedactorFromSpec(multidocs)
 				if err != nil {
 					logger.Printf("failed to parse redactors from doc:  %s", err)
 					continue
 				}
-				additionalRedactors.Spec.Redactors = append(additionalRedactors.Spec.Redactors, parsedRedactors...)
+				supportbundle.AppendRedactor(additionalRedactors, parsedRedactors)
 			}
 		}
 		if mainBundle == nil {
@@ -171,21 +194,63 @@ func runTroubleshoot(v *viper.Viper, arg []string) error {
 		}
 	}
 
+	if mainBundle == nil && hostMode {
+		mainBundle = &troubleshootv1beta2.SupportBundle{}
+		mainBundle.Name = "host"
+	}
+
 	if mainBundle == nil {
 		return errors.New("no support bundle specs provided to run")
+	} else if hostMode {
+		mainBundle.Spec = supportbundle.HostModeSpec(mainBundle.Spec)
 	} else if mainBundle.Spec.Collectors == nil && mainBundle.Spec.HostCollectors == nil {
 		return errors.New("no collectors specified in support bundle")
 	}
 
+	values, err := specs.LoadValues(v.GetStringSlice("values"), v.GetStringSlice("set"))
+	if err != nil {
+		return errors.Wrap(err, "failed to load values")
+	}
+
+	excludeConfig := restConfig
+	if hostMode {
+		// there may not be a cluster to get the facts of
+		excludeConfig = nil
+	}
+	if err := specs.RenderExcludesForCluster(mainBundle, values, excludeConfig); err != nil {
+		return errors.Wrap(err, "failed to render exclude expressions")
+	}
+
+	catalog, err := analyzer.LoadMessageCatalogs(v.GetStringSlice("message-catalog"))
+	if err != nil {
+		return err
+	}
+	analyzer.LocalizeAnalyzers(mainBundle.Spec.Analyzers, mainBundle.Spec.HostAnalyzers, catalog, messageLanguage(v))
+
+	if v.GetBool("estimate") {
+		estimates, err := supportbundle.EstimateSupportBundle(&mainBundle.Spec, supportbundle.SupportBundleCreateOpts{
+			KubernetesRestConfig: restConfig,
+			Namespace:            v.GetString("namespace"),
+			SinceTime:            sinceTime,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to estimate support bundle size")
+		}
+		return supportbundle.WriteEstimates(os.Stdout, estimates)
+	}
+
+	diskOpts, err := diskGuardOptions(v)
+	if err != nil {
+		return err
+	}
+
 	for idx, redactor := range v.GetStringSlice("redactors") {
 		redactorObj, err := supportbundle.GetRedactorFromURI(redactor)
 		if err != nil {
 			return errors.Wrapf(err, "failed to get redactor spec %s, #%d", redactor, idx)
 		}
 
-		if redactorObj != nil {
-			additionalRedactors.Spec.Redactors = append(additionalRedactors.Spec.Redactors, redactorObj.Spec.Redactors...)
-		}
+		supportbundle.AppendRedactor(additionalRedactors, redactorObj)
 	}
 
 	var collectorCB func(chan interface{}, string)
@@ -213,6 +278,7 @@ func runTroubleshoot(v *viper.Viper, arg []string) error {
 		s := spin.New()
 		go func() {
 			currentDir := ""
+			completed := ""
 			for {
 				select {
 				case msg := <-progressChan:
@@ -222,6 +288,8 @@ func runTroubleshoot(v *viper.Viper, arg []string) error {
 						c.Println(fmt.Sprintf("%s\r * %v", cursor.ClearEntireLine(), msg))
 					case string:
 						currentDir = filepath.Base(msg)
+					case collect.CollectProgress:
+						completed = fmt.Sprintf("[%d/%d] ", msg.CompletedCount, msg.TotalCount)
 					}
 				case <-finishedCh:
 					fmt.Printf("\r%s\r", cursor.ClearEntireLine())
@@ -230,7 +298,7 @@ func runTroubleshoot(v *viper.Viper, arg []string) error {
 					if currentDir == "" {
 						fmt.Printf("\r%s \033[36mCollecting support bundle\033[m %s", cursor.ClearEntireLine(), s.Next())
 					} else {
-						fmt.Printf("\r%s \033[36mCollecting support bundle\033[m %s %s", cursor.ClearEntireLine(), s.Next(), currentDir)
+						fmt.Printf("\r%s \033[36mCollecting support bundle\033[m %s %s%s", cursor.ClearEntireLine(), s.Next(), completed, currentDir)
 					}
 				}
 			}
@@ -255,8 +323,13 @@ func runTroubleshoot(v *viper.Viper, arg []string) error {
 		ProgressChan:              progressChan,
 		SinceTime:                 sinceTime,
 		OutputPath:                v.GetString("output"),
+		OutputDir:                 v.GetString("output-dir"),
 		Redact:                    v.GetBool("redact"),
 		FromCLI:                   true,
+		NamespaceBundles:          v.GetStringSlice("namespace-bundles"),
+		CollectConcurrency:        v.GetInt("collect-concurrency"),
+		Reproducible:              v.GetBool("reproducible"),
+		Disk:                      diskOpts,
 	}
 
 	nonInteractiveOutput := analysisOutput{}
@@ -270,10 +343,37 @@ func runTroubleshoot(v *viper.Viper, arg []string) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to run collect and analyze process")
 	}
+	if uploader != nil {
+		// the spinner would draw over the upload's progress
+		if interactive && !isFinishedChClosed {
+			close(finishedCh)
+			isFinishedChClosed = true
+		}
+		if err := uploadArchives(uploader, response, uploadNamespaceBundles); err != nil {
+			return err
+		}
+	}
+
+	summary, err := supportbundle.NewRunSummary(response)
+	if err != nil {
+		return errors.Wrap(err, "failed to summarize support bundle")
+	}
+	if summaryFile := v.GetString("summary-file"); summaryFile != "" {
+		if err := supportbundle.WriteRunSummary(summaryFile, summary); err != nil {
+			return err
+		}
+	}
+	var exitErr error
+	if v.GetBool("exit-code") {
+		exitErr = runExitError(summary)
+	}
+
 	if len(response.AnalyzerResults) > 0 {
 		if interactive {
-			close(finishedCh) // this removes the spinner
-			isFinishedChClosed = true
+			if !isFinishedChClosed {
+				close(finishedCh) // this removes the spinner
+				isFinishedChClosed = true
+			}
 
 			if err := showInteractiveResults(mainBundle.Name, response.AnalyzerResults); err != nil {
 				interactive = false
@@ -289,21 +389,29 @@ func runTroubleshoot(v *viper.Viper, arg []string) error {
 named %s. Please upload it on the Troubleshoot page of
 the %s Admin Console to begin analysis.`
 			fmt.Printf(f, appName, response.ArchivePath, appName)
-			return nil
+			if len(response.CollectionErrors) > 0 {
+				fmt.Println()
+			}
+			printCollectionErrorsSummary(response.CollectionErrors)
+			return exitErr
 		}
 
 		if !interactive {
 			nonInteractiveOutput.ArchivePath = response.ArchivePath
+			nonInteractiveOutput.NamespaceArchivePaths = response.NamespaceArchivePaths
+			nonInteractiveOutput.CollectionErrors = response.CollectionErrors
 			output, err := nonInteractiveOutput.FormattedAnalysisOutput()
 			if err != nil {
 				return errors.Wrap(err, "failed to format non-interactive output")
 			}
 			fmt.Println(output)
-			return nil
+			return exitErr
 		}
 
 		fmt.Printf("\n%s\n", response.ArchivePath)
-		return nil
+		printNamespaceArchivePaths(response.NamespaceArchivePaths)
+		printCollectionErrorsSummary(response.CollectionErrors)
+		return exitErr
 	}
 
 	if interactive {
@@ -312,12 +420,158 @@ the %s Admin Console to begin analysis.`
 	if response.FileUploaded {
 		fmt.Printf("A support bundle has been created and uploaded to your cluster for analysis. Please visit the Troubleshoot page to continue.\n")
 		fmt.Printf("A copy of this support bundle was written to the current directory, named %q\n", response.ArchivePath)
+	} else if isOutputDir(v) {
+		fmt.Printf("A support bundle has been written to the directory %q\n", response.ArchivePath)
 	} else {
 		fmt.Printf("A support bundle has been created in the current directory named %q\n", response.ArchivePath)
 	}
+	printNamespaceArchivePaths(response.NamespaceArchivePaths)
+	printCollectionErrorsSummary(response.CollectionErrors)
+	return exitErr
+}
+
+// loadSpecArgs loads the support bundle specs and redactors of the spec arguments, which are merged in
+// order. The uri of a spec is followed to an upstream spec if followURI is set. The bundle is nil if
+// there are no arguments.
+func loadSpecArgs(args []string, followURI bool) (*troubleshootv1beta2.SupportBundle, *troubleshootv1beta2.Redactor, error) {
+	var mainBundle *troubleshootv1beta2.SupportBundle
+
+	troubleshootclientsetscheme.AddToScheme(scheme.Scheme)
+	additionalRedactors := &troubleshootv1beta2.Redactor{}
+
+	for i, val := range args {
+
+		collectorContent, err := supportbundle.LoadSupportBundleSpec(val)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to load support bundle spec")
+		}
+		multidocs := strings.Split(string(collectorContent), "\n---\n")
+		// Referencing `ParseSupportBundleDocs with a secondary arg of `no-uri`
+		// Will make sure we can enable or disable the use of the `Spec.uri` field for an upstream spec.
+		// This change will not have an impact on KOTS' usage of `ParseSupportBundle`.
+		// Every SupportBundle and Collector document of the spec is merged, in order.
+		// As Kots uses `load.go` directly.
+		supportBundle, err := supportbundle.ParseSupportBundleDocs(multidocs, followURI)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to parse support bundle spec")
+		}
+
+		supportBundle, importedRedactors, err := supportbundle.ResolveImports(supportBundle, val)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to resolve support bundle spec imports")
+		}
+		supportbundle.AppendRedactor(additionalRedactors, importedRedactors)
+
+		if i == 0 {
+			mainBundle = supportBundle
+		} else {
+			mainBundle = supportbundle.ConcatSpec(mainBundle, supportBundle)
+		}
+
+		parsedRedactors, err := supportbundle.ParseRedactorFromSpec(multidocs)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to parse redactors from doc")
+		}
+		supportbundle.AppendRedactor(additionalRedactors, parsedRedactors)
+	}
+
+	return mainBundle, additionalRedactors, nil
+}
+
+// exitCodeError is returned by a run that created a bundle, to exit with code rather than 1
+type exitCodeError struct {
+	code    int
+	message string
+}
+
+func (e *exitCodeError) Error() string {
+	return e.message
+}
+
+// runExitError returns the error to exit with the exit code of a summary, or nil if it is ExitCodeOK
+func runExitError(summary *supportbundle.RunSummary) error {
+	switch summary.ExitCode {
+	case supportbundle.ExitCodeCollectionErrors:
+		return &exitCodeError{code: summary.ExitCode, message: fmt.Sprintf("%d collectors had errors", len(summary.CollectorFailures))}
+	case supportbundle.ExitCodeAnalysisFailed:
+		return &exitCodeError{code: summary.ExitCode, message: fmt.Sprintf("%d analyzers failed", summary.Analysis.Fail)}
+	}
+	return nil
+}
+
+// messageLanguage returns the language to translate analyzer outcome messages to, which defaults to
+// the language of the locale
+func messageLanguage(v *viper.Viper) string {
+	if language := v.GetString("language"); language != "" {
+		return language
+	}
+	return analyzer.LanguageFromEnv()
+}
+
+// isOutputDir returns true if the bundle is written to a directory, with --output-dir or an --output of
+// dir://path
+func isOutputDir(v *viper.Viper) bool {
+	return v.GetString("output-dir") != "" || strings.HasPrefix(v.GetString("output"), supportbundle.OutputDirPrefix)
+}
+
+// uploadArchives uploads the archive of a support bundle, and of its namespace bundles if
+// withNamespaceBundles is set. The local archives are kept.
+func uploadArchives(uploader upload.Uploader, response *supportbundle.SupportBundleResponse, withNamespaceBundles bool) error {
+	archivePaths := []string{response.ArchivePath}
+	if withNamespaceBundles {
+		namespaces := []string{}
+		for namespace := range response.NamespaceArchivePaths {
+			namespaces = append(namespaces, namespace)
+		}
+		sort.Strings(namespaces)
+		for _, namespace := range namespaces {
+			archivePaths = append(archivePaths, response.NamespaceArchivePaths[namespace])
+		}
+	}
+
+	for _, archivePath := range archivePaths {
+		location, err := uploader.Upload(archivePath)
+		if err != nil {
+			return errors.Wrapf(err, "failed to upload %s", archivePath)
+		}
+		logger.Printf("Uploaded %s to %s", archivePath, location)
+	}
 	return nil
 }
 
+// printCollectionErrorsSummary prints the collectors that had errors, by error code. The errors are
+// in the bundle's error manifest.
+func printCollectionErrorsSummary(collectionErrors collect.CollectionErrors) {
+	if len(collectionErrors) == 0 {
+		return
+	}
+
+	summary := collectionErrors.Summary()
+	codes := []string{}
+	for code := range summary {
+		codes = append(codes, string(code))
+	}
+	sort.Strings(codes)
+
+	fmt.Printf("%d collectors had errors, see %s in the support bundle:\n", len(collectionErrors), collect.CollectionErrorsFilename)
+	for _, code := range codes {
+		titles := summary[collect.ErrorCode(code)]
+		fmt.Printf("  %s (%d): %s\n", code, len(titles), strings.Join(titles, ", "))
+	}
+}
+
+func printNamespaceArchivePaths(archivePaths map[string]string) {
+	namespaces := []string{}
+	for namespace := range archivePaths {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	for _, namespace := range namespaces {
+		fmt.Printf("A support bundle for namespace %s has been created named %q\n", namespace, archivePaths[namespace])
+	}
+}
+
 func getExpectedContentType(uploadURL string) string {
 	parsedURL, err := url.Parse(uploadURL)
 	if err != nil {
@@ -351,6 +605,35 @@ func parseTimeFlags(v *viper.Viper) (*time.Time, error) {
 	return &sinceTime, nil
 }
 
+// diskGuardOptions parses the --disk-reserve, --disk-write-rate and --disk-reserve-wait flags
+func diskGuardOptions(v *viper.Viper) (collect.DiskGuardOptions, error) {
+	opts := collect.DiskGuardOptions{
+		PauseTimeout: v.GetDuration("disk-reserve-wait"),
+	}
+
+	if reserve := v.GetString("disk-reserve"); reserve != "" {
+		quantity, err := resource.ParseQuantity(reserve)
+		if err != nil {
+			return opts, errors.Wrap(err, "unable to parse --disk-reserve flag")
+		}
+		if quantity.Sign() > 0 {
+			opts.ReserveBytes = uint64(quantity.Value())
+		}
+	}
+
+	if writeRate := v.GetString("disk-write-rate"); writeRate != "" {
+		quantity, err := resource.ParseQuantity(writeRate)
+		if err != nil {
+			return opts, errors.Wrap(err, "unable to parse --disk-write-rate flag")
+		}
+		if quantity.Sign() > 0 {
+			opts.WriteBytesPerSecond = int(quantity.Value())
+		}
+	}
+
+	return opts, nil
+}
+
 func shouldRetryRequest(err error) bool {
 	if strings.Contains(err.Error(), "x509") && canTryInsecure() {
 		httputil.AddTransport(&http.Transport{
@@ -375,21 +658,30 @@ func canTryInsecure() bool {
 }
 
 type analysisOutput struct {
-	Analysis    []*analyzer.AnalyzeResult
-	ArchivePath string
+	Analysis              []*analyzer.AnalyzeResult
+	ArchivePath           string
+	NamespaceArchivePaths map[string]string
+	CollectionErrors      collect.CollectionErrors
 }
 
 func (a *analysisOutput) FormattedAnalysisOutput() (outputJson string, err error) {
 	type convertedOutput struct {
-		ConvertedAnalysis []*convert.Result `json:"analyzerResults"`
-		ArchivePath       string            `json:"archivePath"`
+		ConvertedAnalysis     []*convert.Result `json:"analyzerResults"`
+		ArchivePath           string            `json:"archivePath"`
+		NamespaceArchivePaths map[string]string `json:"namespaceArchivePaths,omitempty"`
+		// the collectors with errors of each code
+		CollectionErrors map[collect.ErrorCode][]string `json:"collectionErrors,omitempty"`
 	}
 
 	converted := convert.FromAnalyzerResult(a.Analysis)
 
 	o := convertedOutput{
-		ConvertedAnalysis: converted,
-		ArchivePath:       a.ArchivePath,
+		ConvertedAnalysis:     converted,
+		ArchivePath:           a.ArchivePath,
+		NamespaceArchivePaths: a.NamespaceArchivePaths,
+	}
+	if len(a.CollectionErrors) > 0 {
+		o.CollectionErrors = a.CollectionErrors.Summary()
 	}
 
 	formatted, err := json.MarshalIndent(o, "", "    ")
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/troubleshoot/pkg/k8sutil"
+	"github.com/replicatedhq/troubleshoot/pkg/serve"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func Serve() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Args:  cobra.NoArgs,
+		Short: "serve an API to collect support bundles on request",
+		Long: `Serve an API that collects a support bundle from a submitted spec, reports the progress of the
+collection and serves the bundle for download once it has been collected. Requests are
+authenticated with a bearer token, set with --auth-token or the TROUBLESHOOT_AUTH_TOKEN
+environment variable. Anyone with the token can run any collector with the server's access to
+the cluster.`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlag("address", cmd.Flags().Lookup("address"))
+			viper.BindPFlag("auth-token", cmd.Flags().Lookup("auth-token"))
+			viper.BindPFlag("data-dir", cmd.Flags().Lookup("data-dir"))
+			viper.BindPFlag("tls-cert-file", cmd.Flags().Lookup("tls-cert-file"))
+			viper.BindPFlag("tls-key-file", cmd.Flags().Lookup("tls-key-file"))
+			viper.BindPFlag("max-queued-collections", cmd.Flags().Lookup("max-queued-collections"))
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			restConfig, err := k8sutil.GetRESTConfig()
+			if err != nil {
+				return errors.Wrap(err, "failed to convert kube flags to rest config")
+			}
+
+			dataDir := v.GetString("data-dir")
+			if dataDir == "" {
+				dataDir = filepath.Join(os.TempDir(), "troubleshoot-serve")
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return serve.Run(ctx, serve.ServerOptions{
+				Address:              v.GetString("address"),
+				Token:                v.GetString("auth-token"),
+				DataDir:              dataDir,
+				CertFile:             v.GetString("tls-cert-file"),
+				KeyFile:              v.GetString("tls-key-file"),
+				KubernetesRestConfig: restConfig,
+				MaxQueuedCollections: v.GetInt("max-queued-collections"),
+			})
+		},
+	}
+
+	cmd.Flags().String("address", ":8080", "address to listen on")
+	// --token is the kube flag for the bearer token of the API server
+	cmd.Flags().String("auth-token", "", "bearer token to authenticate requests with")
+	cmd.Flags().String("data-dir", "", "directory to keep bundles in, a directory in the temp dir by default")
+	cmd.Flags().String("tls-cert-file", "", "serve TLS with this certificate")
+	cmd.Flags().String("tls-key-file", "", "serve TLS with this key")
+	cmd.Flags().Int("max-queued-collections", serve.DefaultMaxQueuedCollections, "how many collections can wait to run before requests are rejected")
+
+	k8sutil.AddFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"os"
+	"strings"
+
+	"github.com/replicatedhq/troubleshoot/pkg/webhook"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func RootCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "troubleshoot-webhook",
+		Short:        "Run the admission webhook for troubleshoot resources",
+		Long:         `Serve defaulting and validating admission webhooks for Preflight, SupportBundle and Redactor resources.`,
+		SilenceUsage: true,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			return webhook.Run(ctrl.SetupSignalHandler(), webhook.ServerOptions{
+				Host:    v.GetString("host"),
+				Port:    v.GetInt("port"),
+				CertDir: v.GetString("cert-dir"),
+			})
+		},
+	}
+
+	cobra.OnInitialize(initConfig)
+
+	cmd.Flags().String("host", "", "address to listen on, all addresses if empty")
+	cmd.Flags().Int("port", 9443, "port to listen on")
+	cmd.Flags().String("cert-dir", "/tmp/k8s-webhook-server/serving-certs", "directory containing tls.crt and tls.key")
+
+	viper.BindPFlags(cmd.Flags())
+
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+
+	return cmd
+}
+
+func InitAndExecute() {
+	if err := RootCmd().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func initConfig() {
+	viper.SetEnvPrefix("TROUBLESHOOT")
+	viper.AutomaticEnv()
+}
@@ -0,0 +1,9 @@
+package main
+
+import (
+	"github.com/replicatedhq/troubleshoot/cmd/webhook/cli"
+)
+
+func main() {
+	cli.InitAndExecute()
+}
@@ -23,6 +23,11 @@ type AnalyzeResult struct {
 	IconKey string
 	IconURI string
 
+	DocString      string
+	Category       string
+	Tags           []string
+	RemediationURI string
+
 	InvolvedObject *corev1.ObjectReference
 }
 
@@ -63,8 +68,9 @@ func HostAnalyze(hostAnalyzer *troubleshootv1beta2.HostAnalyze, getFile getColle
 
 	result, err := analyzer.Analyze(getFile)
 	if err != nil {
-		return NewAnalyzeResultError(analyzer, errors.Wrap(err, "analyze"))
+		result = NewAnalyzeResultError(analyzer, errors.Wrap(err, "analyze"))
 	}
+	setAnalyzeMeta(result, GetAnalyzeMeta(hostAnalyzer))
 	return result
 }
 
@@ -84,6 +90,15 @@ func NewAnalyzeResultError(analyzer HostAnalyzer, err error) []*AnalyzeResult {
 }
 
 func Analyze(analyzer *troubleshootv1beta2.Analyze, getFile getCollectedFileContents, findFiles getChildCollectedFileContents) ([]*AnalyzeResult, error) {
+	results, err := analyze(analyzer, getFile, findFiles)
+	if err != nil {
+		return nil, err
+	}
+	setAnalyzeMeta(results, GetAnalyzeMeta(analyzer))
+	return results, nil
+}
+
+func analyze(analyzer *troubleshootv1beta2.Analyze, getFile getCollectedFileContents, findFiles getChildCollectedFileContents) ([]*AnalyzeResult, error) {
 	if analyzer == nil {
 		return nil, errors.New("nil analyzer")
 	}
@@ -501,6 +516,118 @@ func Analyze(analyzer *troubleshootv1beta2.Analyze, getFile getCollectedFileCont
 		return []*AnalyzeResult{result}, nil
 	}
 
+	if analyzer.DaemonSetCoverage != nil {
+		isExcluded, err := isExcluded(analyzer.DaemonSetCoverage.Exclude)
+		if err != nil {
+			return nil, err
+		}
+		if isExcluded {
+			return nil, nil
+		}
+		results, err := analyzeDaemonSetCoverage(analyzer.DaemonSetCoverage, getFile, findFiles)
+		if err != nil {
+			return nil, err
+		}
+		for i := range results {
+			results[i].Strict = analyzer.DaemonSetCoverage.Strict.BoolOrDefaultFalse()
+		}
+		return results, nil
+	}
+
+	if analyzer.NodeReadiness != nil {
+		isExcluded, err := isExcluded(analyzer.NodeReadiness.Exclude)
+		if err != nil {
+			return nil, err
+		}
+		if isExcluded {
+			return nil, nil
+		}
+		results, err := analyzeNodeReadiness(analyzer.NodeReadiness, getFile, findFiles)
+		if err != nil {
+			return nil, err
+		}
+		for i := range results {
+			results[i].Strict = analyzer.NodeReadiness.Strict.BoolOrDefaultFalse()
+		}
+		return results, nil
+	}
+
+	if analyzer.PodFailures != nil {
+		isExcluded, err := isExcluded(analyzer.PodFailures.Exclude)
+		if err != nil {
+			return nil, err
+		}
+		if isExcluded {
+			return nil, nil
+		}
+		results, err := analyzePodFailures(analyzer.PodFailures, findFiles)
+		if err != nil {
+			return nil, err
+		}
+		for i := range results {
+			results[i].Strict = analyzer.PodFailures.Strict.BoolOrDefaultFalse()
+		}
+		return results, nil
+	}
+
+	if analyzer.StorageHealth != nil {
+		isExcluded, err := isExcluded(analyzer.StorageHealth.Exclude)
+		if err != nil {
+			return nil, err
+		}
+		if isExcluded {
+			return nil, nil
+		}
+		results, err := analyzeStorageHealth(analyzer.StorageHealth, getFile, findFiles)
+		if err != nil {
+			return nil, err
+		}
+		for i := range results {
+			results[i].Strict = analyzer.StorageHealth.Strict.BoolOrDefaultFalse()
+		}
+		return results, nil
+	}
+
+	if analyzer.GoProfile != nil {
+		isExcluded, err := isExcluded(analyzer.GoProfile.Exclude)
+		if err != nil {
+			return nil, err
+		}
+		if isExcluded {
+			return nil, nil
+		}
+		results, err := analyzeGoProfile(analyzer.GoProfile, findFiles)
+		if err != nil {
+			return nil, err
+		}
+		for i := range results {
+			results[i].Strict = analyzer.GoProfile.Strict.BoolOrDefaultFalse()
+		}
+		return results, nil
+	}
+
+	if analyzer.IngressRouting != nil {
+		isExcluded, err := isExcluded(analyzer.IngressRouting.Exclude)
+		if err != nil {
+			return nil, err
+		}
+		if isExcluded {
+			return nil, nil
+		}
+		results, err := analyzeIngressRouting(analyzer.IngressRouting, getFile, findFiles)
+		if err != nil {
+			return nil, err
+		}
+		for i := range results {
+			results[i].Strict = analyzer.IngressRouting.Strict.BoolOrDefaultFalse()
+		}
+		return results, nil
+	}
+
+	if analyzer.Compound != nil {
+		return nil, errors.New("compound analyzers are run with AnalyzeCompound, with the results of the analyzers before them")
+	}
+
 	return nil, errors.New("invalid analyzer")
 }
 
@@ -525,3 +652,50 @@ func GetExcludeFlag(analyzer *troubleshootv1beta2.Analyze) *multitype.BoolOrStri
 
 	return nil
 }
+
+// GetAnalyzeMeta returns the metadata of the analyzer that is set in an Analyze or HostAnalyze
+func GetAnalyzeMeta(analyzer interface{}) *troubleshootv1beta2.AnalyzeMeta {
+	reflected := reflect.ValueOf(analyzer)
+	if reflected.Kind() != reflect.Ptr || reflected.IsNil() {
+		return nil
+	}
+
+	reflected = reflected.Elem()
+	if reflected.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < reflected.NumField(); i++ {
+		if reflected.Field(i).Kind() != reflect.Ptr || reflected.Field(i).IsNil() {
+			continue
+		}
+
+		field := reflect.Indirect(reflected.Field(i)).FieldByName("AnalyzeMeta")
+		if !field.IsValid() {
+			continue
+		}
+		meta, ok := field.Interface().(troubleshootv1beta2.AnalyzeMeta)
+		if !ok {
+			continue
+		}
+		return &meta
+	}
+
+	return nil
+}
+
+func setAnalyzeMeta(results []*AnalyzeResult, meta *troubleshootv1beta2.AnalyzeMeta) {
+	if meta == nil {
+		return
+	}
+
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		result.DocString = meta.DocString
+		result.Category = meta.Category
+		result.Tags = meta.Tags
+		result.RemediationURI = meta.RemediationURI
+	}
+}
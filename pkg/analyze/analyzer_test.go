@@ -65,3 +65,30 @@ func Test_GetExcludeFlag(t *testing.T) {
 		})
 	}
 }
+
+func Test_setAnalyzeMeta(t *testing.T) {
+	analyzer := &troubleshootv1beta2.Analyze{
+		ClusterVersion: &troubleshootv1beta2.ClusterVersion{
+			AnalyzeMeta: troubleshootv1beta2.AnalyzeMeta{
+				CheckName:      "Kubernetes version",
+				DocString:      "Checks that the cluster is running a supported version of Kubernetes",
+				Category:       "Cluster",
+				Tags:           []string{"kubernetes", "version"},
+				RemediationURI: "https://kubernetes.io/docs/tasks/administer-cluster/cluster-upgrade/",
+			},
+		},
+	}
+
+	meta := GetAnalyzeMeta(analyzer)
+	require.NotNil(t, meta)
+
+	results := []*AnalyzeResult{{Title: "Kubernetes version"}, nil}
+	setAnalyzeMeta(results, meta)
+
+	assert.Equal(t, "Checks that the cluster is running a supported version of Kubernetes", results[0].DocString)
+	assert.Equal(t, "Cluster", results[0].Category)
+	assert.Equal(t, []string{"kubernetes", "version"}, results[0].Tags)
+	assert.Equal(t, "https://kubernetes.io/docs/tasks/administer-cluster/cluster-upgrade/", results[0].RemediationURI)
+
+	assert.Nil(t, GetAnalyzeMeta(&troubleshootv1beta2.Analyze{}))
+}
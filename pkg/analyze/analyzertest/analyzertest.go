@@ -0,0 +1,192 @@
+// Package analyzertest runs analyzer specs against fixture directories that are laid out like an
+// extracted support bundle, so that analyzers can be unit tested without collecting a bundle.
+//
+//	func TestAnalyzers(t *testing.T) {
+//		results := analyzertest.Run(t, "testdata/old-cluster", spec)
+//		analyzertest.AssertFail(t, results, "Required Kubernetes Version", "requires at least Kubernetes 1.22")
+//	}
+package analyzertest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	analyzer "github.com/replicatedhq/troubleshoot/pkg/analyze"
+)
+
+// Bundle is a fixture directory that analyzers are run against as if it were a support bundle. Files
+// are read from where they would be in a bundle, e.g. cluster-info/cluster_version.json.
+type Bundle struct {
+	Dir string
+}
+
+// Results are the results of the analyzers of a spec, in the order they ran
+type Results []*analyzer.AnalyzeResult
+
+// LoadBundle returns dir as a bundle, failing t if it is not a directory
+func LoadBundle(t testing.TB, dir string) *Bundle {
+	t.Helper()
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatalf("failed to load fixture bundle: %v", err)
+	}
+	if !info.IsDir() {
+		t.Fatalf("failed to load fixture bundle: %s is not a directory", dir)
+	}
+
+	return &Bundle{Dir: dir}
+}
+
+// Run loads the fixture directory dir and runs the analyzers of spec against it
+func Run(t testing.TB, dir string, spec string) Results {
+	t.Helper()
+
+	return LoadBundle(t, dir).Analyze(t, spec)
+}
+
+// Analyze runs the analyzers and host analyzers of spec, an Analyzer or SupportBundle spec, against the
+// bundle. Compound analyzers are run against the results of the analyzers before them, as they are when a
+// bundle is analyzed. t fails if spec can not be parsed or an analyzer returns an error.
+func (b *Bundle) Analyze(t testing.TB, spec string) Results {
+	t.Helper()
+
+	analyzers, hostAnalyzers, err := analyzer.ParseAnalyzers(spec)
+	if err != nil {
+		t.Fatalf("failed to parse analyzers: %v", err)
+	}
+
+	results := Results{}
+	for i, a := range analyzers {
+		var analyzeResults []*analyzer.AnalyzeResult
+		if a.Compound != nil {
+			analyzeResults, err = analyzer.AnalyzeCompound(a, results)
+		} else {
+			analyzeResults, err = analyzer.Analyze(a, b.getFileContents, b.getChildFileContents)
+		}
+		if err != nil {
+			t.Errorf("analyzer %d failed to run: %v", i, err)
+			continue
+		}
+		for _, r := range analyzeResults {
+			if r != nil {
+				results = append(results, r)
+			}
+		}
+	}
+	for _, a := range hostAnalyzers {
+		results = append(results, analyzer.HostAnalyze(a, b.getFileContents, b.getChildFileContents)...)
+	}
+
+	return results
+}
+
+func (b *Bundle) getFileContents(fileName string) ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join(b.Dir, fileName))
+}
+
+func (b *Bundle) getChildFileContents(dirName string) (map[string][]byte, error) {
+	files, err := filepath.Glob(filepath.Join(b.Dir, dirName))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid glob %q", dirName)
+	}
+	fileArr := map[string][]byte{}
+	for _, filePath := range files {
+		bytes, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read %q", filePath)
+		}
+		fileArr[filePath] = bytes
+	}
+	return fileArr, nil
+}
+
+// Get returns the first result with title, or nil if there is none
+func (r Results) Get(title string) *analyzer.AnalyzeResult {
+	for _, result := range r {
+		if result.Title == title {
+			return result
+		}
+	}
+	return nil
+}
+
+// String lists the outcome, title and message of each result, to show what did run when an assertion fails
+func (r Results) String() string {
+	lines := []string{}
+	for _, result := range r {
+		lines = append(lines, fmt.Sprintf("%s %q: %s", outcome(result), result.Title, result.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// AssertPass asserts that the result with title passed, and that its message contains message if it is not empty
+func AssertPass(t testing.TB, results Results, title string, message string) bool {
+	t.Helper()
+
+	return assertOutcome(t, results, title, "pass", message)
+}
+
+// AssertWarn asserts that the result with title warned, and that its message contains message if it is not empty
+func AssertWarn(t testing.TB, results Results, title string, message string) bool {
+	t.Helper()
+
+	return assertOutcome(t, results, title, "warn", message)
+}
+
+// AssertFail asserts that the result with title failed, and that its message contains message if it is not empty
+func AssertFail(t testing.TB, results Results, title string, message string) bool {
+	t.Helper()
+
+	return assertOutcome(t, results, title, "fail", message)
+}
+
+// AssertNoFailures asserts that none of the results failed
+func AssertNoFailures(t testing.TB, results Results) bool {
+	t.Helper()
+
+	ok := true
+	for _, result := range results {
+		if result.IsFail {
+			t.Errorf("expected no failures, %q failed: %s", result.Title, result.Message)
+			ok = false
+		}
+	}
+	return ok
+}
+
+func assertOutcome(t testing.TB, results Results, title string, expected string, message string) bool {
+	t.Helper()
+
+	result := results.Get(title)
+	if result == nil {
+		t.Errorf("no result with title %q, the results are:\n%s", title, results)
+		return false
+	}
+	if actual := outcome(result); actual != expected {
+		t.Errorf("expected %q to %s, its outcome is %s: %s", title, expected, actual, result.Message)
+		return false
+	}
+	if !strings.Contains(result.Message, message) {
+		t.Errorf("expected the message of %q to contain %q, it is %q", title, message, result.Message)
+		return false
+	}
+	return true
+}
+
+func outcome(result *analyzer.AnalyzeResult) string {
+	switch {
+	case result.IsFail:
+		return "fail"
+	case result.IsWarn:
+		return "warn"
+	case result.IsPass:
+		return "pass"
+	}
+	return "none"
+}
@@ -0,0 +1,118 @@
+package analyzertest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSpec = `apiVersion: troubleshoot.sh/v1beta2
+kind: Analyzer
+metadata:
+  name: test
+spec:
+  analyzers:
+    - clusterVersion:
+        outcomes:
+          - fail:
+              when: "< 1.22.0"
+              message: The application requires at least Kubernetes 1.22.0
+          - pass:
+              message: Your cluster meets the required version of Kubernetes
+    - deploymentStatus:
+        name: api
+        namespace: default
+        outcomes:
+          - fail:
+              when: "< 1"
+              message: The API is not running
+          - warn:
+              when: "< 2"
+              message: The API is degraded
+          - pass:
+              message: The API is running
+`
+
+// recordingT records the errors of assertions, so that failing assertions can be tested
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestRun(t *testing.T) {
+	results := Run(t, "testdata/bundle", testSpec)
+	require.Len(t, results, 2)
+
+	AssertFail(t, results, "Required Kubernetes Version", "requires at least Kubernetes 1.22.0")
+	AssertWarn(t, results, "api Status", "degraded")
+	assert.Nil(t, results.Get("missing"))
+}
+
+func TestRun_Compound(t *testing.T) {
+	spec := testSpec + `    - compound:
+        checkName: Upgrade Readiness
+        outcomes:
+          - fail:
+              when: "analyzer(Required Kubernetes Version) == fail && analyzer(api Status) >= warn"
+              message: Upgrade Kubernetes and scale up the API before upgrading the application
+          - pass:
+              message: Ready to upgrade
+`
+	results := Run(t, "testdata/bundle", spec)
+	require.Len(t, results, 3)
+
+	AssertFail(t, results, "Upgrade Readiness", "Upgrade Kubernetes")
+}
+
+func TestAssertions(t *testing.T) {
+	results := Run(t, "testdata/bundle", testSpec)
+
+	tests := []struct {
+		name         string
+		assert       func(t testing.TB) bool
+		expectErrors []string
+	}{
+		{
+			name:   "matching outcome",
+			assert: func(t testing.TB) bool { return AssertFail(t, results, "Required Kubernetes Version", "") },
+		},
+		{
+			name:         "other outcome",
+			assert:       func(t testing.TB) bool { return AssertPass(t, results, "Required Kubernetes Version", "") },
+			expectErrors: []string{`expected "Required Kubernetes Version" to pass, its outcome is fail: The application requires at least Kubernetes 1.22.0`},
+		},
+		{
+			name:         "other message",
+			assert:       func(t testing.TB) bool { return AssertWarn(t, results, "api Status", "is running") },
+			expectErrors: []string{`expected the message of "api Status" to contain "is running", it is "The API is degraded"`},
+		},
+		{
+			name:   "missing result",
+			assert: func(t testing.TB) bool { return AssertPass(t, results, "Database", "") },
+			expectErrors: []string{`no result with title "Database", the results are:
+fail "Required Kubernetes Version": The application requires at least Kubernetes 1.22.0
+warn "api Status": The API is degraded`},
+		},
+		{
+			name:         "no failures",
+			assert:       func(t testing.TB) bool { return AssertNoFailures(t, results) },
+			expectErrors: []string{`expected no failures, "Required Kubernetes Version" failed: The application requires at least Kubernetes 1.22.0`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &recordingT{TB: t}
+			ok := tt.assert(recorder)
+			assert.Equal(t, len(tt.expectErrors) == 0, ok)
+			assert.Equal(t, tt.expectErrors, recorder.errors)
+		})
+	}
+}
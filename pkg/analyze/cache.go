@@ -0,0 +1,223 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/troubleshoot/pkg/version"
+)
+
+// AnalyzerCache holds the results of analyzers from earlier runs against a bundle, so that running the same
+// analyzers again only runs the ones whose spec, or the files of the bundle they read, have changed. This
+// keeps large analyzer specs fast to iterate on.
+//
+// The results of an analyzer are cached with the hashes of the files it read, and are used again while the
+// bundle has the same files with the same hashes. Analyzers that fail are not cached.
+type AnalyzerCache struct {
+	path    string
+	entries map[string]*analyzerCacheEntry
+	// used are the entries that were read or written during this run, which are the ones that are saved
+	used map[string]*analyzerCacheEntry
+	// hashes of the files of the bundle, so that each is only read once to check the entries
+	hashes map[string]string
+
+	// Hits and Misses are the number of analyzers whose results were and were not in the cache
+	Hits   int
+	Misses int
+}
+
+type analyzerCacheFile struct {
+	Entries map[string]*analyzerCacheEntry `json:"entries"`
+}
+
+type analyzerCacheEntry struct {
+	// Files are the hashes of the files the analyzer read, which are empty for files that did not exist
+	Files map[string]string `json:"files,omitempty"`
+	// Globs are the files that matched each pattern the analyzer read, with their hashes
+	Globs   map[string]map[string]string `json:"globs,omitempty"`
+	Results []*AnalyzeResult             `json:"results"`
+}
+
+// LoadAnalyzerCache reads the cache at path, which is empty if the file does not exist yet
+func LoadAnalyzerCache(path string) (*AnalyzerCache, error) {
+	c := &AnalyzerCache{
+		path:    path,
+		entries: map[string]*analyzerCacheEntry{},
+		used:    map[string]*analyzerCacheEntry{},
+		hashes:  map[string]string{},
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read analyzer cache")
+	}
+
+	file := analyzerCacheFile{}
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse analyzer cache %s", path)
+	}
+	if file.Entries != nil {
+		c.entries = file.Entries
+	}
+
+	return c, nil
+}
+
+// Save writes the results of the analyzers of this run to the cache file. Analyzers that were not run,
+// such as ones that have since been removed from the spec or changed, are dropped.
+func (c *AnalyzerCache) Save() error {
+	b, err := json.Marshal(analyzerCacheFile{Entries: c.used})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal analyzer cache")
+	}
+	if err := ioutil.WriteFile(c.path, b, 0644); err != nil {
+		return errors.Wrap(err, "failed to write analyzer cache")
+	}
+	return nil
+}
+
+// run returns the cached results of the analyzer spec, which is a *troubleshootv1beta2.Analyze or
+// *troubleshootv1beta2.HostAnalyze, or runs it with the files of the bundle and caches the results.
+// A nil cache always runs the analyzer.
+func (c *AnalyzerCache) run(spec interface{}, fcp fileContentProvider, analyze func(getCollectedFileContents, getChildCollectedFileContents) ([]*AnalyzeResult, error)) ([]*AnalyzeResult, error) {
+	if c == nil {
+		return analyze(fcp.getFileContents, fcp.getChildFileContents)
+	}
+
+	key, err := analyzerCacheKey(spec)
+	if err != nil {
+		return analyze(fcp.getFileContents, fcp.getChildFileContents)
+	}
+
+	if entry, ok := c.entries[key]; ok && c.isCurrent(entry, fcp) {
+		c.Hits++
+		c.used[key] = entry
+		return entry.Results, nil
+	}
+	c.Misses++
+
+	recorder := &analyzerCacheRecorder{
+		cache: c,
+		fcp:   fcp,
+		entry: &analyzerCacheEntry{Files: map[string]string{}, Globs: map[string]map[string]string{}},
+	}
+	results, err := analyze(recorder.getFile, recorder.findFiles)
+	if err != nil || recorder.failed {
+		return results, err
+	}
+
+	recorder.entry.Results = results
+	c.entries[key] = recorder.entry
+	c.used[key] = recorder.entry
+	return results, nil
+}
+
+// isCurrent returns true if the files that the analyzer of the entry read have not changed
+func (c *AnalyzerCache) isCurrent(entry *analyzerCacheEntry, fcp fileContentProvider) bool {
+	for name, hash := range entry.Files {
+		current, ok := c.hashFile(fcp, name)
+		if !ok || current != hash {
+			return false
+		}
+	}
+
+	for pattern, hashes := range entry.Globs {
+		files, err := fcp.getChildFileContents(pattern)
+		if err != nil || len(files) != len(hashes) {
+			return false
+		}
+		for path, b := range files {
+			if hashes[fcp.relativePath(path)] != hashContents(b) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// hashFile returns the hash of a file of the bundle, which is empty if the file does not exist, and false
+// if it could not be read
+func (c *AnalyzerCache) hashFile(fcp fileContentProvider, name string) (string, bool) {
+	path := filepath.Join(fcp.rootDir, name)
+	if hash, ok := c.hashes[path]; ok {
+		return hash, true
+	}
+
+	b, err := fcp.getFileContents(name)
+	if err != nil && !os.IsNotExist(err) {
+		return "", false
+	}
+	hash := ""
+	if err == nil {
+		hash = hashContents(b)
+	}
+	c.hashes[path] = hash
+	return hash, true
+}
+
+// analyzerCacheRecorder records the files that an analyzer reads
+type analyzerCacheRecorder struct {
+	cache *AnalyzerCache
+	fcp   fileContentProvider
+	entry *analyzerCacheEntry
+	// failed is true if a file could not be read for a reason other than not existing, in which case the
+	// results are not cached
+	failed bool
+}
+
+func (r *analyzerCacheRecorder) getFile(name string) ([]byte, error) {
+	b, err := r.fcp.getFileContents(name)
+	switch {
+	case err == nil:
+		r.entry.Files[name] = hashContents(b)
+		r.cache.hashes[filepath.Join(r.fcp.rootDir, name)] = r.entry.Files[name]
+	case os.IsNotExist(err):
+		r.entry.Files[name] = ""
+	default:
+		r.failed = true
+	}
+	return b, err
+}
+
+func (r *analyzerCacheRecorder) findFiles(pattern string) (map[string][]byte, error) {
+	files, err := r.fcp.getChildFileContents(pattern)
+	if err != nil {
+		r.failed = true
+		return files, err
+	}
+
+	hashes := map[string]string{}
+	for path, b := range files {
+		hashes[r.fcp.relativePath(path)] = hashContents(b)
+	}
+	r.entry.Globs[pattern] = hashes
+	return files, nil
+}
+
+// analyzerCacheKey is the hash of the analyzer spec and of the version of troubleshoot, as analyzers can
+// have different results in another version
+func analyzerCacheKey(spec interface{}) (string, error) {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s %T ", version.Version(), version.GitSHA(), spec)
+	h.Write(b)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashContents(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
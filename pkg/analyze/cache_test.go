@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeLocalWithCache(t *testing.T) {
+	dir := t.TempDir()
+	writeBundleFile := func(name, contents string) {
+		filename := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755))
+		require.NoError(t, ioutil.WriteFile(filename, []byte(contents), 0644))
+	}
+	writeBundleFile("version.yaml", "apiVersion: troubleshoot.sh/v1beta2\n")
+	writeBundleFile("cluster-info/cluster_version.json", `{"info": {"gitVersion": "v1.26.1"}, "string": "v1.26.1"}`)
+	writeBundleFile("app/logs/api.log", "started\n")
+	writeBundleFile("app/logs/worker.log", "started\n")
+
+	analyzers := []*troubleshootv1beta2.Analyze{
+		{
+			ClusterVersion: &troubleshootv1beta2.ClusterVersion{
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{Fail: &troubleshootv1beta2.SingleOutcome{When: "< 1.25.0", Message: "too old"}},
+					{Pass: &troubleshootv1beta2.SingleOutcome{Message: "supported"}},
+				},
+			},
+		},
+		{
+			TextAnalyze: &troubleshootv1beta2.TextAnalyze{
+				AnalyzeMeta:   troubleshootv1beta2.AnalyzeMeta{CheckName: "Panics"},
+				CollectorName: "app/logs",
+				FileName:      "*.log",
+				RegexPattern:  "panic",
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{Fail: &troubleshootv1beta2.SingleOutcome{When: "true", Message: "panicked"}},
+					{Pass: &troubleshootv1beta2.SingleOutcome{When: "false", Message: "no panics"}},
+				},
+			},
+		},
+	}
+	cacheFile := filepath.Join(t.TempDir(), "cache.json")
+
+	analyze := func() ([]*AnalyzeResult, *AnalyzerCache) {
+		cache, err := LoadAnalyzerCache(cacheFile)
+		require.NoError(t, err)
+		results, err := AnalyzeLocalWithCache(dir, analyzers, nil, cache)
+		require.NoError(t, err)
+		require.NoError(t, cache.Save())
+		return results, cache
+	}
+
+	messages := func(results []*AnalyzeResult) []string {
+		messages := []string{}
+		for _, result := range results {
+			messages = append(messages, result.Message)
+		}
+		return messages
+	}
+
+	results, cache := analyze()
+	assert.Equal(t, 0, cache.Hits)
+	assert.Equal(t, 2, cache.Misses)
+	assert.ElementsMatch(t, []string{"supported", "no panics", "no panics"}, messages(results))
+
+	results, cache = analyze()
+	assert.Equal(t, 2, cache.Hits)
+	assert.Equal(t, 0, cache.Misses)
+	assert.ElementsMatch(t, []string{"supported", "no panics", "no panics"}, messages(results))
+
+	// a log that changed only runs the analyzer that read it
+	writeBundleFile("app/logs/worker.log", "panic: runtime error\n")
+	results, cache = analyze()
+	assert.Equal(t, 1, cache.Hits)
+	assert.Equal(t, 1, cache.Misses)
+	assert.ElementsMatch(t, []string{"supported", "panicked", "no panics"}, messages(results))
+
+	// so does a new file that matches
+	writeBundleFile("app/logs/scheduler.log", "started\n")
+	_, cache = analyze()
+	assert.Equal(t, 1, cache.Hits)
+	assert.Equal(t, 1, cache.Misses)
+
+	// and a changed analyzer
+	analyzers[0].ClusterVersion.Outcomes[0].Fail.When = "< 1.27.0"
+	results, cache = analyze()
+	assert.Equal(t, 1, cache.Hits)
+	assert.Equal(t, 1, cache.Misses)
+	assert.Equal(t, "too old", results[0].Message)
+}
+
+func TestLoadAnalyzerCache_Invalid(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "cache.json")
+	require.NoError(t, ioutil.WriteFile(cacheFile, []byte("not json"), 0644))
+
+	_, err := LoadAnalyzerCache(cacheFile)
+	assert.Error(t, err)
+}
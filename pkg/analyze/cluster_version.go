@@ -2,6 +2,7 @@ package analyzer
 
 import (
 	"encoding/json"
+	"regexp"
 	"strings"
 
 	"github.com/blang/semver"
@@ -21,10 +22,14 @@ func analyzeClusterVersion(analyzer *troubleshootv1beta2.ClusterVersion, getColl
 		return nil, errors.Wrap(err, "failed to parse cluster_version.json")
 	}
 
-	k8sVersion, err := semver.Make(strings.TrimLeft(collectorClusterVersion.String, "v"))
+	k8sVersion, err := semver.ParseTolerant(collectorClusterVersion.String)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to parse semver from cluster_version.json")
 	}
+	// distributions add to the version, such as v1.24.0-eks-2d98532 or v1.24.0+k3s1, which is not a
+	// pre-release of 1.24.0 and would not match >=1.24.0 if it were one
+	k8sVersion.Pre = nil
+	k8sVersion.Build = nil
 
 	return analyzeClusterVersionResult(k8sVersion, analyzer.Outcomes, analyzer.CheckName)
 }
@@ -73,7 +78,7 @@ func analyzeClusterVersionResult(k8sVersion semver.Version, outcomes []*troubles
 			return &result, nil
 		}
 
-		whenRange, err := semver.ParseRange(when)
+		whenRange, err := parseClusterVersionRange(when)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to parse semver range")
 		}
@@ -88,3 +93,24 @@ func analyzeClusterVersionResult(k8sVersion semver.Version, outcomes []*troubles
 
 	return &AnalyzeResult{}, nil
 }
+
+// clusterVersionRangeVersionRX matches the versions of a range, such as 1.19 in >=1.19 <1.24.0, and what
+// follows them
+var clusterVersionRangeVersionRX = regexp.MustCompile(`(^|[\s<>=!])v?(\d+(?:\.\d+){0,2})(\S*)`)
+
+// parseClusterVersionRange parses a semver range, such as ">=1.19.0 <1.24.0" or ">=1.22.0 || =1.18.x".
+// Versions can have a v prefix and leave out the minor and patch, which are 0.
+func parseClusterVersionRange(when string) (semver.Range, error) {
+	expanded := clusterVersionRangeVersionRX.ReplaceAllStringFunc(when, func(match string) string {
+		parts := clusterVersionRangeVersionRX.FindStringSubmatch(match)
+		prefix, version, suffix := parts[1], parts[2], parts[3]
+		if suffix == "" {
+			for strings.Count(version, ".") < 2 {
+				version += ".0"
+			}
+		}
+		return prefix + version + suffix
+	})
+
+	return semver.ParseRange(expanded)
+}
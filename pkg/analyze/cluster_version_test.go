@@ -6,6 +6,8 @@ import (
 
 	"github.com/blang/semver"
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_analyzeClusterVersionResult(t *testing.T) {
@@ -103,3 +105,41 @@ func Test_analyzeClusterVersionResult(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseClusterVersionRange(t *testing.T) {
+	tests := []struct {
+		when    string
+		version string
+		want    bool
+	}{
+		{when: ">=1.19.0 <1.24.0", version: "1.23.17", want: true},
+		{when: ">=1.19.0 <1.24.0", version: "1.24.0", want: false},
+		{when: ">=1.19 <1.24", version: "1.19.0", want: true},
+		{when: ">= v1.19 < v1.24", version: "1.24.1", want: false},
+		{when: "<1.20 || >=1.26", version: "1.27.4", want: true},
+		{when: "1.22.x", version: "1.22.9", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.when+" "+tt.version, func(t *testing.T) {
+			whenRange, err := parseClusterVersionRange(tt.when)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, whenRange(semver.MustParse(tt.version)))
+		})
+	}
+}
+
+func Test_analyzeClusterVersion_Distribution(t *testing.T) {
+	getCollectedFileContents := func(string) ([]byte, error) {
+		return []byte(`{"info": {"gitVersion": "v1.24.0-eks-2d98532"}, "string": "v1.24.0-eks-2d98532"}`), nil
+	}
+
+	result, err := analyzeClusterVersion(&troubleshootv1beta2.ClusterVersion{
+		Outcomes: []*troubleshootv1beta2.Outcome{
+			{Fail: &troubleshootv1beta2.SingleOutcome{When: "<1.24", Message: "too old"}},
+			{Pass: &troubleshootv1beta2.SingleOutcome{When: ">=1.24.0 <1.28.0", Message: "supported"}},
+		},
+	}, getCollectedFileContents)
+	require.NoError(t, err)
+	assert.True(t, result.IsPass)
+	assert.Equal(t, "supported", result.Message)
+}
@@ -0,0 +1,22 @@
+package analyzer
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+)
+
+// GetCollectionErrors returns the errors of the collectors that failed or collected only some of their
+// data, by collector name, so that an analyzer can tell that a file is missing because its collector
+// failed. Bundles collected by older versions have no error manifest, and have no collection errors.
+func GetCollectionErrors(getFile getCollectedFileContents) (collect.CollectionErrors, error) {
+	b, err := getFile(collect.CollectionErrorsFilename)
+	if os.IsNotExist(errors.Cause(err)) {
+		return collect.CollectionErrors{}, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to read collection errors")
+	}
+
+	return collect.ParseCollectionErrors(b)
+}
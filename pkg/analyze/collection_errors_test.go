@@ -0,0 +1,37 @@
+package analyzer
+
+import (
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCollectionErrors(t *testing.T) {
+	getFile := func(name string) ([]byte, error) {
+		assert.Equal(t, "errors.json", name)
+		return []byte(`{"logs/web": [{"code": "permission-denied", "message": "pods is forbidden"}]}`), nil
+	}
+	collectionErrors, err := GetCollectionErrors(getFile)
+	require.NoError(t, err)
+	assert.Equal(t, collect.CollectionErrors{
+		"logs/web": {{Code: collect.ErrorCodePermissionDenied, Message: "pods is forbidden"}},
+	}, collectionErrors)
+
+	// bundles collected by older versions
+	getFile = func(name string) ([]byte, error) {
+		return nil, errors.Wrap(os.ErrNotExist, "failed to read file")
+	}
+	collectionErrors, err = GetCollectionErrors(getFile)
+	require.NoError(t, err)
+	assert.Empty(t, collectionErrors)
+
+	getFile = func(name string) ([]byte, error) {
+		return []byte("not json"), nil
+	}
+	_, err = GetCollectionErrors(getFile)
+	assert.Error(t, err)
+}
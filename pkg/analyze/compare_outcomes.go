@@ -0,0 +1,278 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	iutils "github.com/replicatedhq/troubleshoot/pkg/interfaceutils"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// comparedValue is the value of a collected document that the outcomes of a jsonCompare or yamlCompare
+// analyzer are evaluated against
+type comparedValue struct {
+	value interface{}
+	found bool
+	// err is why the value could not be selected, which is only an error for outcomes that compare it
+	err error
+}
+
+// selectComparedValue selects the value of doc at jsonPath, a JSONPath expression such as
+// {.items[*].metadata.name}, or else at path, the dotted path of the analyzers. A JSONPath expression
+// that matches several values selects them as a list. The error is for an invalid JSONPath expression.
+func selectComparedValue(doc interface{}, path string, jsonPath string) (comparedValue, error) {
+	if jsonPath != "" {
+		expression := jsonPath
+		if !strings.HasPrefix(expression, "{") {
+			expression = "{" + expression + "}"
+		}
+
+		j := jsonpath.New("compare")
+		j.AllowMissingKeys(true)
+		if err := j.Parse(expression); err != nil {
+			return comparedValue{}, errors.Wrapf(err, "failed to parse jsonPath: %s", jsonPath)
+		}
+		results, err := j.FindResults(normalizeComparedValue(doc))
+		if err != nil {
+			return comparedValue{}, errors.Wrapf(err, "failed to get object at jsonPath: %s", jsonPath)
+		}
+
+		values := []interface{}{}
+		for _, result := range results {
+			for _, value := range result {
+				values = append(values, value.Interface())
+			}
+		}
+		switch len(values) {
+		case 0:
+			return comparedValue{}, nil
+		case 1:
+			return comparedValue{value: values[0], found: true}, nil
+		default:
+			return comparedValue{value: values, found: true}, nil
+		}
+	}
+
+	if path != "" {
+		value, err := iutils.GetAtPath(doc, path)
+		if err != nil {
+			return comparedValue{err: errors.Wrapf(err, "failed to get object at path: %s", path)}, nil
+		}
+		return comparedValue{value: value, found: value != nil}, nil
+	}
+
+	return comparedValue{value: doc, found: doc != nil}, nil
+}
+
+// evaluateCompareOutcomes returns the result of the first outcome whose "when" matches. A "when" of
+// "true" or "false" matches when the value is, or is not, equal to the analyzer's value, which is
+// parsed by expected; the pass outcome defaults to "true" and the others to "false". An outcome without a
+// "when" always matches if the analyzer has no value, so that it can follow outcomes that use operators.
+// Otherwise "when" is one of:
+//
+//	exists, !exists       the path selects a value, or does not
+//	== <v>, != <v>        the value is, or is not, equal to v
+//	> <n>, >= <n>, < <n>, <= <n>
+//	                      the value is a number that compares to n
+//	contains <v>          the value is a string that contains v, a list with an item equal to v, or a
+//	                      map with the key v
+//
+// v is parsed as json, or is a string if it is not json.
+func evaluateCompareOutcomes(result *AnalyzeResult, outcomes []*troubleshootv1beta2.Outcome, actual comparedValue, hasValue bool, expected func() (interface{}, error)) (*AnalyzeResult, error) {
+	var equal *bool
+	isEqual := func() (bool, error) {
+		if equal == nil {
+			if actual.err != nil {
+				return false, actual.err
+			}
+			e, err := expected()
+			if err != nil {
+				return false, err
+			}
+			isEqual := reflect.DeepEqual(actual.value, e)
+			equal = &isEqual
+		}
+		return *equal, nil
+	}
+
+	for _, outcome := range outcomes {
+		var single *troubleshootv1beta2.SingleOutcome
+		defaultWhen := "false"
+		switch {
+		case outcome.Fail != nil:
+			single = outcome.Fail
+		case outcome.Warn != nil:
+			single = outcome.Warn
+		case outcome.Pass != nil:
+			single = outcome.Pass
+			defaultWhen = "true" // default to passing when values are equal
+		default:
+			continue
+		}
+
+		when := strings.TrimSpace(single.When)
+		if when == "" {
+			when = defaultWhen
+		}
+
+		var matches bool
+		if single.When == "" && !hasValue {
+			matches = true
+		} else if whenEqual, err := strconv.ParseBool(when); err == nil {
+			equal, err := isEqual()
+			if err != nil {
+				return nil, err
+			}
+			matches = whenEqual == equal
+		} else {
+			matches, err = compareValue(actual, when)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to process when statement: %s", single.When)
+			}
+		}
+		if !matches {
+			continue
+		}
+
+		result.IsFail = single == outcome.Fail
+		result.IsWarn = single == outcome.Warn
+		result.IsPass = single == outcome.Pass
+		result.Message = single.Message
+		result.URI = single.URI
+
+		return result, nil
+	}
+
+	return &AnalyzeResult{
+		Title:   result.Title,
+		IconKey: result.IconKey,
+		IconURI: result.IconURI,
+		IsFail:  true,
+		Message: "Invalid analyzer",
+	}, nil
+}
+
+// compareValue returns whether the value matches a "when" that is not "true" or "false"
+func compareValue(actual comparedValue, when string) (bool, error) {
+	switch when {
+	case "exists":
+		return actual.found, nil
+	case "!exists":
+		return !actual.found, nil
+	}
+
+	parts := strings.SplitN(when, " ", 2)
+	if len(parts) != 2 {
+		return false, errors.New("expected an operator and a value")
+	}
+	operator, operand := parts[0], parseComparedOperand(strings.TrimSpace(parts[1]))
+
+	if actual.err != nil {
+		return false, actual.err
+	}
+	if !actual.found {
+		return false, nil
+	}
+	value := normalizeComparedValue(actual.value)
+
+	switch operator {
+	case "=", "==":
+		return reflect.DeepEqual(value, operand), nil
+	case "!=":
+		return !reflect.DeepEqual(value, operand), nil
+	case "contains":
+		switch v := value.(type) {
+		case string:
+			return strings.Contains(v, fmt.Sprint(operand)), nil
+		case []interface{}:
+			for _, item := range v {
+				if reflect.DeepEqual(item, operand) {
+					return true, nil
+				}
+			}
+			return false, nil
+		case map[string]interface{}:
+			_, ok := v[fmt.Sprint(operand)]
+			return ok, nil
+		}
+		return false, errors.Errorf("cannot check if a %T contains a value", value)
+	case "<", "<=", ">", ">=":
+		actualNumber, ok := comparedNumber(value)
+		if !ok {
+			return false, errors.Errorf("value %v is not a number", value)
+		}
+		operandNumber, ok := comparedNumber(operand)
+		if !ok {
+			return false, errors.Errorf("%v is not a number", operand)
+		}
+		switch operator {
+		case "<":
+			return actualNumber < operandNumber, nil
+		case "<=":
+			return actualNumber <= operandNumber, nil
+		case ">":
+			return actualNumber > operandNumber, nil
+		default:
+			return actualNumber >= operandNumber, nil
+		}
+	}
+
+	return false, errors.Errorf("unknown comparator: %q", operator)
+}
+
+// parseComparedOperand parses the value of a "when" as json, or returns it as a string
+func parseComparedOperand(operand string) interface{} {
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(operand), &parsed); err != nil {
+		return operand
+	}
+	return parsed
+}
+
+func comparedNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// normalizeComparedValue converts a value parsed from yaml to the types it would have if it was parsed
+// from json, so that yaml and json values compare the same way
+func normalizeComparedValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for key, item := range v {
+			m[fmt.Sprint(key)] = normalizeComparedValue(item)
+		}
+		return m
+	case map[string]interface{}:
+		m := map[string]interface{}{}
+		for key, item := range v {
+			m[key] = normalizeComparedValue(item)
+		}
+		return m
+	case []interface{}:
+		items := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			items = append(items, normalizeComparedValue(item))
+		}
+		return items
+	case int, int64, uint64, float32, json.Number:
+		n, err := strconv.ParseFloat(fmt.Sprint(v), 64)
+		if err != nil {
+			return v
+		}
+		return n
+	}
+	return value
+}
@@ -0,0 +1,278 @@
+package analyzer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+)
+
+// AnalyzeCompound runs a compound analyzer against the results of the analyzers that ran before it
+func AnalyzeCompound(analyzer *troubleshootv1beta2.Analyze, previous []*AnalyzeResult) ([]*AnalyzeResult, error) {
+	if analyzer == nil || analyzer.Compound == nil {
+		return nil, errors.New("not a compound analyzer")
+	}
+
+	isExcluded, err := isExcluded(analyzer.Compound.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	if isExcluded {
+		return nil, nil
+	}
+
+	result, err := analyzeCompound(analyzer.Compound, previous)
+	if err != nil {
+		return nil, err
+	}
+	result.Strict = analyzer.Compound.Strict.BoolOrDefaultFalse()
+
+	results := []*AnalyzeResult{result}
+	setAnalyzeMeta(results, GetAnalyzeMeta(analyzer))
+	return results, nil
+}
+
+func analyzeCompound(analyzer *troubleshootv1beta2.CompoundAnalyze, previous []*AnalyzeResult) (*AnalyzeResult, error) {
+	title := analyzer.CheckName
+	if title == "" {
+		title = "Compound"
+	}
+	result := &AnalyzeResult{Title: title}
+
+	outcomes := compoundOutcomes(previous)
+
+	for _, outcome := range analyzer.Outcomes {
+		var single *troubleshootv1beta2.SingleOutcome
+		switch {
+		case outcome.Fail != nil:
+			single = outcome.Fail
+		case outcome.Warn != nil:
+			single = outcome.Warn
+		case outcome.Pass != nil:
+			single = outcome.Pass
+		default:
+			continue
+		}
+
+		if single.When != "" {
+			matches, err := evaluateCompoundWhen(single.When, outcomes)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to process when statement: %s", single.When)
+			}
+			if !matches {
+				continue
+			}
+		}
+
+		result.IsFail = single == outcome.Fail
+		result.IsWarn = single == outcome.Warn
+		result.IsPass = single == outcome.Pass
+		result.Message = single.Message
+		result.URI = single.URI
+
+		return result, nil
+	}
+
+	return result, nil
+}
+
+// compound outcomes are ordered by severity, so that "analyzer(x) >= warn" matches warn and fail
+const (
+	compoundOutcomeMissing = iota
+	compoundOutcomePass
+	compoundOutcomeWarn
+	compoundOutcomeFail
+)
+
+var compoundOutcomeNames = map[string]int{
+	"missing": compoundOutcomeMissing,
+	"pass":    compoundOutcomePass,
+	"warn":    compoundOutcomeWarn,
+	"fail":    compoundOutcomeFail,
+}
+
+// compoundOutcomes returns the outcome of each analyzer by title. An analyzer with several results, such
+// as one for each object, has the most severe of their outcomes.
+func compoundOutcomes(results []*AnalyzeResult) map[string]int {
+	outcomes := map[string]int{}
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		outcome := compoundOutcomeMissing
+		switch {
+		case result.IsFail:
+			outcome = compoundOutcomeFail
+		case result.IsWarn:
+			outcome = compoundOutcomeWarn
+		case result.IsPass:
+			outcome = compoundOutcomePass
+		}
+		if outcome > outcomes[result.Title] {
+			outcomes[result.Title] = outcome
+		}
+	}
+	return outcomes
+}
+
+// evaluateCompoundWhen evaluates an expression of comparisons such as "analyzer(Ceph Status) == fail",
+// joined by && and ||, negated by ! and grouped by parentheses. An outcome is pass, warn, fail or missing,
+// which is the outcome of an analyzer that did not run or was excluded, and outcomes can be compared by
+// severity with <, <=, > and >=. Analyzers have no value other than their outcome, so comparing one to a
+// number, such as "analyzer(Node Count) < 3", is an error.
+func evaluateCompoundWhen(when string, outcomes map[string]int) (bool, error) {
+	tokens, err := tokenizeCompoundWhen(when)
+	if err != nil {
+		return false, err
+	}
+	p := &compoundParser{tokens: tokens, outcomes: outcomes}
+	matches, err := p.parseOr()
+	if err != nil {
+		return false, err
+	}
+	if p.pos < len(p.tokens) {
+		return false, errors.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	return matches, nil
+}
+
+// tokenizeCompoundWhen splits an expression into operators, parentheses, analyzer references such as
+// "analyzer(Ceph Status)", and words
+func tokenizeCompoundWhen(when string) ([]string, error) {
+	tokens := []string{}
+	for i := 0; i < len(when); {
+		switch c := when[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case strings.HasPrefix(when[i:], "analyzer("):
+			end := strings.Index(when[i:], ")")
+			if end < 0 {
+				return nil, errors.New("unterminated analyzer(")
+			}
+			tokens = append(tokens, when[i:i+end+1])
+			i += end + 1
+		case strings.HasPrefix(when[i:], "&&"), strings.HasPrefix(when[i:], "||"),
+			strings.HasPrefix(when[i:], "=="), strings.HasPrefix(when[i:], "!="),
+			strings.HasPrefix(when[i:], "<="), strings.HasPrefix(when[i:], ">="):
+			tokens = append(tokens, when[i:i+2])
+			i += 2
+		case strings.ContainsRune("()!<>", rune(c)):
+			tokens = append(tokens, string(c))
+			i++
+		default:
+			end := i
+			for end < len(when) && !strings.ContainsRune(" \t\n()!<>=&|", rune(when[end])) {
+				end++
+			}
+			if end == i {
+				return nil, errors.Errorf("unexpected %q", string(c))
+			}
+			tokens = append(tokens, when[i:end])
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type compoundParser struct {
+	tokens   []string
+	pos      int
+	outcomes map[string]int
+}
+
+func (p *compoundParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *compoundParser) next() string {
+	token := p.peek()
+	if token != "" {
+		p.pos++
+	}
+	return token
+}
+
+func (p *compoundParser) parseOr() (bool, error) {
+	matches, err := p.parseAnd()
+	if err != nil {
+		return false, err
+	}
+	for p.peek() == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return false, err
+		}
+		matches = matches || right
+	}
+	return matches, nil
+}
+
+func (p *compoundParser) parseAnd() (bool, error) {
+	matches, err := p.parseUnary()
+	if err != nil {
+		return false, err
+	}
+	for p.peek() == "&&" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return false, err
+		}
+		matches = matches && right
+	}
+	return matches, nil
+}
+
+func (p *compoundParser) parseUnary() (bool, error) {
+	switch token := p.next(); {
+	case token == "!":
+		matches, err := p.parseUnary()
+		return !matches, err
+	case token == "(":
+		matches, err := p.parseOr()
+		if err != nil {
+			return false, err
+		}
+		if p.next() != ")" {
+			return false, errors.New("expected )")
+		}
+		return matches, nil
+	case strings.HasPrefix(token, "analyzer("):
+		title := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(token, "analyzer("), ")"))
+		actual := p.outcomes[title]
+
+		operator := p.next()
+		value := p.next()
+		expected, ok := compoundOutcomeNames[strings.ToLower(value)]
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return false, errors.Errorf("analyzer(%s) can not be compared to %s, analyzers are compared by their outcome: pass, warn, fail or missing", title, value)
+		} else if !ok {
+			return false, errors.Errorf("expected pass, warn, fail or missing after analyzer(%s) %s", title, operator)
+		}
+
+		switch operator {
+		case "==":
+			return actual == expected, nil
+		case "!=":
+			return actual != expected, nil
+		case "<":
+			return actual != compoundOutcomeMissing && actual < expected, nil
+		case "<=":
+			return actual != compoundOutcomeMissing && actual <= expected, nil
+		case ">":
+			return actual != compoundOutcomeMissing && actual > expected, nil
+		case ">=":
+			return actual != compoundOutcomeMissing && actual >= expected, nil
+		}
+		return false, errors.Errorf("unknown comparator: %q", operator)
+	case token == "":
+		return false, errors.New("unexpected end of expression")
+	default:
+		return false, errors.Errorf("unexpected %q", token)
+	}
+}
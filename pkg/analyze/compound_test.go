@@ -0,0 +1,90 @@
+package analyzer
+
+import (
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_evaluateCompoundWhen(t *testing.T) {
+	outcomes := compoundOutcomes([]*AnalyzeResult{
+		{Title: "Ceph Status", IsFail: true},
+		{Title: "node-count", IsWarn: true},
+		{Title: "Pod Status", IsPass: true},
+		{Title: "Pod Status", IsWarn: true},
+		{Title: "Ingress", IsPass: true},
+	})
+
+	tests := []struct {
+		when    string
+		want    bool
+		isError bool
+	}{
+		{when: "analyzer(Ceph Status) == fail", want: true},
+		{when: "analyzer(Ceph Status) == fail && analyzer(node-count) != pass", want: true},
+		{when: "analyzer(Ceph Status) == fail && analyzer(node-count) == pass", want: false},
+		{when: "analyzer(Ingress) == fail || analyzer(node-count) == warn", want: true},
+		{when: "!(analyzer(Ingress) == pass)", want: false},
+		{when: "analyzer(Pod Status) == warn", want: true},
+		{when: "analyzer(node-count) >= warn", want: true},
+		{when: "analyzer(Ingress) > pass", want: false},
+		{when: "analyzer(Ingress) <= WARN", want: true},
+		{when: "analyzer(Not Run) == missing", want: true},
+		{when: "analyzer(Not Run) < fail", want: false},
+		{when: "analyzer(Ingress) == pass && (analyzer(Not Run) == fail || analyzer(Ceph Status) == fail)", want: true},
+		{when: "analyzer(Ingress) == ok", isError: true},
+		{when: "analyzer(node-count) < 3", isError: true},
+		{when: "analyzer(Ingress) ~ pass", isError: true},
+		{when: "analyzer(Ingress == pass", isError: true},
+		{when: "(analyzer(Ingress) == pass", isError: true},
+		{when: "analyzer(Ingress) == pass analyzer(Ingress) == pass", isError: true},
+		{when: "analyzer(Ingress) == pass &&", isError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.when, func(t *testing.T) {
+			got, err := evaluateCompoundWhen(test.when, outcomes)
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestAnalyzeCompound(t *testing.T) {
+	analyzer := &troubleshootv1beta2.Analyze{
+		Compound: &troubleshootv1beta2.CompoundAnalyze{
+			AnalyzeMeta: troubleshootv1beta2.AnalyzeMeta{CheckName: "Storage Redundancy", Category: "storage"},
+			Outcomes: []*troubleshootv1beta2.Outcome{
+				{Fail: &troubleshootv1beta2.SingleOutcome{When: "analyzer(Ceph Status) == fail && analyzer(Node Count) != pass", Message: "Ceph is unhealthy and there are too few nodes to recover"}},
+				{Pass: &troubleshootv1beta2.SingleOutcome{Message: "Storage can recover"}},
+			},
+		},
+	}
+
+	results, err := AnalyzeCompound(analyzer, []*AnalyzeResult{
+		{Title: "Ceph Status", IsFail: true},
+		{Title: "Node Count", IsWarn: true},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].IsFail)
+	assert.Equal(t, "Storage Redundancy", results[0].Title)
+	assert.Equal(t, "storage", results[0].Category)
+
+	results, err = AnalyzeCompound(analyzer, []*AnalyzeResult{
+		{Title: "Ceph Status", IsPass: true},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].IsPass)
+	assert.Equal(t, "Storage can recover", results[0].Message)
+
+	_, err = Analyze(analyzer, nil, nil)
+	assert.Error(t, err)
+}
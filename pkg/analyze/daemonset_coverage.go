@@ -0,0 +1,385 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// daemonSetTolerations are the tolerations that the daemonset controller adds to the pods of every
+// daemonset, so that they are scheduled on nodes with these taints
+var daemonSetTolerations = []corev1.Toleration{
+	{Key: corev1.TaintNodeNotReady, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	{Key: corev1.TaintNodeUnreachable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	{Key: corev1.TaintNodeDiskPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodeMemoryPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodePIDPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodeUnschedulable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+}
+
+// DaemonSetCoverage is what the messages of a daemonSetCoverage analyzer are templated with
+type DaemonSetCoverage struct {
+	Namespace string
+	Name      string
+	// Nodes is the number of schedulable nodes that the daemonset should run on
+	Nodes int
+	// Covered is the number of those nodes that have a ready pod of the daemonset
+	Covered int
+	// Uncovered are the names of the nodes that have no ready pod of the daemonset
+	Uncovered []string
+	// UncoveredNodes is Uncovered, comma separated
+	UncoveredNodes string
+}
+
+func analyzeDaemonSetCoverage(analyzer *troubleshootv1beta2.DaemonSetCoverage, getCollectedFileContents func(string) ([]byte, error), findFiles func(string) (map[string][]byte, error)) ([]*AnalyzeResult, error) {
+	nodesData, err := getCollectedFileContents(filepath.Join("cluster-resources", "nodes.json"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read collected nodes")
+	}
+	var nodes corev1.NodeList
+	if err := json.Unmarshal(nodesData, &nodes); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal node list")
+	}
+
+	fileNames := make([]string, 0)
+	if analyzer.Namespace != "" {
+		fileNames = append(fileNames, filepath.Join("cluster-resources", "daemonsets", fmt.Sprintf("%s.json", analyzer.Namespace)))
+	}
+	for _, ns := range analyzer.Namespaces {
+		fileNames = append(fileNames, filepath.Join("cluster-resources", "daemonsets", fmt.Sprintf("%s.json", ns)))
+	}
+
+	// no namespace specified, so we need to analyze all daemonsets
+	if len(fileNames) == 0 {
+		fileNames = append(fileNames, filepath.Join("cluster-resources", "daemonsets", "*.json"))
+	}
+
+	results := []*AnalyzeResult{}
+	exists := false
+	for _, fileName := range fileNames {
+		files, err := findFiles(fileName)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read collected daemonsets from namespace")
+		}
+
+		for name, collected := range files {
+			var daemonSets appsv1.DaemonSetList
+			if err := json.Unmarshal(collected, &daemonSets); err != nil {
+				return nil, errors.Wrap(err, "failed to unmarshal daemonset list")
+			}
+
+			namespace := strings.TrimSuffix(filepath.Base(name), ".json")
+			pods, err := getDaemonSetPods(namespace, findFiles)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, daemonSet := range daemonSets.Items {
+				if analyzer.Name != "" && daemonSet.Name != analyzer.Name {
+					continue
+				}
+				exists = true
+
+				coverage := getDaemonSetCoverage(daemonSet, nodes.Items, pods)
+				result, err := daemonSetCoverageResult(analyzer, coverage)
+				if err != nil {
+					return nil, err
+				}
+				if result != nil {
+					results = append(results, result)
+				}
+			}
+		}
+	}
+
+	if analyzer.Name != "" && !exists {
+		return []*AnalyzeResult{absentDaemonSetResult(analyzer)}, nil
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Title < results[j].Title
+	})
+
+	return results, nil
+}
+
+func getDaemonSetPods(namespace string, findFiles func(string) (map[string][]byte, error)) ([]corev1.Pod, error) {
+	files, err := findFiles(filepath.Join("cluster-resources", "pods", fmt.Sprintf("%s.json", namespace)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read collected pods from namespace")
+	}
+
+	pods := []corev1.Pod{}
+	for _, collected := range files { // only 1 file here
+		var podList corev1.PodList
+		if err := json.Unmarshal(collected, &podList); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal pod list")
+		}
+		pods = append(pods, podList.Items...)
+	}
+
+	return pods, nil
+}
+
+// getDaemonSetCoverage finds the nodes that the daemonset should run on which do not have a ready pod of
+// the daemonset
+func getDaemonSetCoverage(daemonSet appsv1.DaemonSet, nodes []corev1.Node, pods []corev1.Pod) DaemonSetCoverage {
+	readyNodes := map[string]bool{}
+	for _, pod := range pods {
+		if isDaemonSetPod(daemonSet, pod) && isPodReady(pod) {
+			readyNodes[pod.Spec.NodeName] = true
+		}
+	}
+
+	coverage := DaemonSetCoverage{
+		Namespace: daemonSet.Namespace,
+		Name:      daemonSet.Name,
+		Uncovered: []string{},
+	}
+	for _, node := range nodes {
+		if !daemonSetShouldRunOnNode(daemonSet, node) {
+			continue
+		}
+		coverage.Nodes++
+		if readyNodes[node.Name] {
+			coverage.Covered++
+		} else {
+			coverage.Uncovered = append(coverage.Uncovered, node.Name)
+		}
+	}
+	sort.Strings(coverage.Uncovered)
+	coverage.UncoveredNodes = strings.Join(coverage.Uncovered, ", ")
+
+	return coverage
+}
+
+func daemonSetCoverageResult(analyzer *troubleshootv1beta2.DaemonSetCoverage, coverage DaemonSetCoverage) (*AnalyzeResult, error) {
+	result := &AnalyzeResult{
+		Title:   fmt.Sprintf("%s/%s DaemonSet Coverage", coverage.Namespace, coverage.Name),
+		IconKey: "kubernetes_daemonset_coverage",
+	}
+
+	if len(analyzer.Outcomes) == 0 {
+		if len(coverage.Uncovered) > 0 {
+			result.IsFail = true
+			result.Message = fmt.Sprintf("The daemonset %s/%s has no ready pod on %d of %d nodes: %s", coverage.Namespace, coverage.Name, len(coverage.Uncovered), coverage.Nodes, coverage.UncoveredNodes)
+			return result, nil
+		}
+		// only daemonsets that are analyzed by name are reported when they cover every node
+		if analyzer.Name == "" {
+			return nil, nil
+		}
+		result.IsPass = true
+		result.Message = fmt.Sprintf("The daemonset %s/%s has a ready pod on all %d nodes", coverage.Namespace, coverage.Name, coverage.Nodes)
+		return result, nil
+	}
+
+	// ordering from the spec is important, the first one that matches returns
+	for _, outcome := range analyzer.Outcomes {
+		var single *troubleshootv1beta2.SingleOutcome
+		switch {
+		case outcome.Fail != nil:
+			single = outcome.Fail
+			result.IsFail = true
+		case outcome.Warn != nil:
+			single = outcome.Warn
+			result.IsWarn = true
+		case outcome.Pass != nil:
+			single = outcome.Pass
+			result.IsPass = true
+		default:
+			continue
+		}
+
+		match := single.When != "absent"
+		if match && single.When != "" {
+			var err error
+			match, err = compareActualToWhen(single.When, len(coverage.Uncovered), true)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse when")
+			}
+		}
+		if !match {
+			result.IsFail, result.IsWarn, result.IsPass = false, false, false
+			continue
+		}
+
+		message, err := templateDaemonSetCoverageMessage(single.Message, coverage)
+		if err != nil {
+			return nil, err
+		}
+		result.Message = message
+		result.URI = single.URI
+		return result, nil
+	}
+
+	return nil, nil
+}
+
+func absentDaemonSetResult(analyzer *troubleshootv1beta2.DaemonSetCoverage) *AnalyzeResult {
+	result := &AnalyzeResult{
+		Title:   fmt.Sprintf("%s DaemonSet Coverage", analyzer.Name),
+		IconKey: "kubernetes_daemonset_coverage",
+		IsFail:  true,
+		Message: fmt.Sprintf("The daemonset %q was not found", analyzer.Name),
+	}
+
+	for _, outcome := range analyzer.Outcomes {
+		if outcome.Fail != nil && outcome.Fail.When == "absent" {
+			result.Message = outcome.Fail.Message
+			result.URI = outcome.Fail.URI
+			break
+		}
+		if outcome.Warn != nil && outcome.Warn.When == "absent" {
+			result.IsFail = false
+			result.IsWarn = true
+			result.Message = outcome.Warn.Message
+			result.URI = outcome.Warn.URI
+			break
+		}
+	}
+
+	return result
+}
+
+func templateDaemonSetCoverageMessage(message string, coverage DaemonSetCoverage) (string, error) {
+	tmpl, err := template.New("daemonset").Parse(message)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create new message template")
+	}
+	var m bytes.Buffer
+	if err := tmpl.Execute(&m, coverage); err != nil {
+		return "", errors.Wrap(err, "failed to execute template")
+	}
+	return m.String(), nil
+}
+
+func isDaemonSetPod(daemonSet appsv1.DaemonSet, pod corev1.Pod) bool {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind != "DaemonSet" {
+			continue
+		}
+		if daemonSet.UID != "" {
+			if owner.UID == daemonSet.UID {
+				return true
+			}
+		} else if owner.Name == daemonSet.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func isPodReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// daemonSetShouldRunOnNode returns true if the pods of the daemonset are scheduled on the node. Cordoned
+// nodes are not included, but nodes that are not ready are, as a missing CNI pod is a common reason why.
+func daemonSetShouldRunOnNode(daemonSet appsv1.DaemonSet, node corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+
+	spec := daemonSet.Spec.Template.Spec
+	if !labels.SelectorFromSet(spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return false
+	}
+	if spec.Affinity != nil && spec.Affinity.NodeAffinity != nil && spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		if !nodeSelectorMatchesNode(*spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution, node) {
+			return false
+		}
+	}
+
+	tolerations := append([]corev1.Toleration{}, daemonSetTolerations...)
+	if spec.HostNetwork {
+		tolerations = append(tolerations, corev1.Toleration{Key: corev1.TaintNodeNetworkUnavailable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule})
+	}
+	tolerations = append(tolerations, spec.Tolerations...)
+
+	for i := range node.Spec.Taints {
+		taint := node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(&taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+
+	return true
+}
+
+// nodeSelectorMatchesNode returns true if any of the terms of the node selector match the node
+func nodeSelectorMatchesNode(nodeSelector corev1.NodeSelector, node corev1.Node) bool {
+	for _, term := range nodeSelector.NodeSelectorTerms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+
+		selector, err := nodeSelectorRequirementsAsSelector(term.MatchExpressions)
+		if err != nil || !selector.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		fieldSelector, err := nodeSelectorRequirementsAsSelector(term.MatchFields)
+		if err != nil || !fieldSelector.Matches(labels.Set{"metadata.name": node.Name}) {
+			continue
+		}
+
+		return true
+	}
+	return false
+}
+
+func nodeSelectorRequirementsAsSelector(requirements []corev1.NodeSelectorRequirement) (labels.Selector, error) {
+	selector := labels.NewSelector()
+	for _, requirement := range requirements {
+		var op selection.Operator
+		switch requirement.Operator {
+		case corev1.NodeSelectorOpIn:
+			op = selection.In
+		case corev1.NodeSelectorOpNotIn:
+			op = selection.NotIn
+		case corev1.NodeSelectorOpExists:
+			op = selection.Exists
+		case corev1.NodeSelectorOpDoesNotExist:
+			op = selection.DoesNotExist
+		case corev1.NodeSelectorOpGt:
+			op = selection.GreaterThan
+		case corev1.NodeSelectorOpLt:
+			op = selection.LessThan
+		default:
+			return nil, errors.Errorf("unknown node selector operator %q", requirement.Operator)
+		}
+
+		r, err := labels.NewRequirement(requirement.Key, op, requirement.Values)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse node selector requirement %s", requirement.Key)
+		}
+		selector = selector.Add(*r)
+	}
+	return selector, nil
+}
@@ -0,0 +1,313 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_analyzeDaemonSetCoverage(t *testing.T) {
+	calico := appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "calico-node", Namespace: "kube-system", UID: "calico"},
+		Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			HostNetwork: true,
+			Tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+		}}},
+	}
+	agent := appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "log-agent", Namespace: "kube-system", UID: "agent"},
+		Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			NodeSelector: map[string]string{"logging": "enabled"},
+		}}},
+	}
+
+	nodes := corev1.NodeList{Items: []corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"logging": "enabled"}}},
+		// not ready, as its cni pod is not running
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: corev1.TaintNodeNotReady, Effect: corev1.TaintEffectNoSchedule}, {Key: corev1.TaintNodeNetworkUnavailable, Effect: corev1.TaintEffectNoSchedule}}},
+		},
+		// cordoned
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-3", Labels: map[string]string{"logging": "enabled"}}, Spec: corev1.NodeSpec{Unschedulable: true}},
+		// dedicated to other workloads
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-4"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "control-plane"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: "node-role.kubernetes.io/control-plane", Effect: corev1.TaintEffectNoSchedule}}},
+		},
+	}}
+	daemonSets := appsv1.DaemonSetList{Items: []appsv1.DaemonSet{calico, agent}}
+	pods := corev1.PodList{Items: []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "calico-node-1",
+				Namespace:       "kube-system",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "calico-node", UID: "calico"}},
+			},
+			Spec: corev1.PodSpec{NodeName: "node-1"},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "calico-node-2",
+				Namespace:       "kube-system",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "calico-node", UID: "calico"}},
+			},
+			Spec: corev1.PodSpec{NodeName: "node-2"},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "calico-node-3",
+				Namespace:       "kube-system",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "calico-node", UID: "calico"}},
+			},
+			Spec: corev1.PodSpec{NodeName: "node-3"},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "calico-node-4",
+				Namespace:       "kube-system",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "calico-node", UID: "calico"}},
+			},
+			Spec: corev1.PodSpec{NodeName: "node-4"},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "log-agent-1",
+				Namespace:       "kube-system",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "log-agent", UID: "agent"}},
+			},
+			Spec: corev1.PodSpec{NodeName: "node-1"},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		},
+	}}
+
+	files := map[string][]byte{}
+	for name, obj := range map[string]interface{}{
+		"cluster-resources/nodes.json":                  nodes,
+		"cluster-resources/daemonsets/kube-system.json": daemonSets,
+		"cluster-resources/pods/kube-system.json":       pods,
+	} {
+		b, err := json.Marshal(obj)
+		require.NoError(t, err)
+		files[name] = b
+	}
+	files["cluster-resources/daemonsets/default.json"] = []byte(`{"items":[]}`)
+
+	tests := []struct {
+		name         string
+		analyzer     troubleshootv1beta2.DaemonSetCoverage
+		expectResult []*AnalyzeResult
+	}{
+		{
+			name: "uncovered nodes",
+			analyzer: troubleshootv1beta2.DaemonSetCoverage{
+				Namespace: "kube-system",
+				Name:      "calico-node",
+			},
+			expectResult: []*AnalyzeResult{
+				{
+					Title:   "kube-system/calico-node DaemonSet Coverage",
+					IconKey: "kubernetes_daemonset_coverage",
+					IsFail:  true,
+					Message: "The daemonset kube-system/calico-node has no ready pod on 2 of 4 nodes: control-plane, node-2",
+				},
+			},
+		},
+		{
+			name: "covered nodes",
+			analyzer: troubleshootv1beta2.DaemonSetCoverage{
+				Name: "log-agent",
+			},
+			expectResult: []*AnalyzeResult{
+				{
+					Title:   "kube-system/log-agent DaemonSet Coverage",
+					IconKey: "kubernetes_daemonset_coverage",
+					IsPass:  true,
+					Message: "The daemonset kube-system/log-agent has a ready pod on all 1 nodes",
+				},
+			},
+		},
+		{
+			name: "outcomes",
+			analyzer: troubleshootv1beta2.DaemonSetCoverage{
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{
+						Fail: &troubleshootv1beta2.SingleOutcome{
+							When:    "> 1",
+							Message: "{{ .Name }} is missing from {{ .UncoveredNodes }}",
+						},
+					},
+					{
+						Warn: &troubleshootv1beta2.SingleOutcome{
+							When:    "= 1",
+							Message: "{{ .Name }} is missing from {{ .UncoveredNodes }}",
+						},
+					},
+					{
+						Pass: &troubleshootv1beta2.SingleOutcome{
+							Message: "{{ .Name }} runs on {{ .Covered }}/{{ .Nodes }} nodes",
+						},
+					},
+				},
+				Namespaces: []string{"kube-system"},
+			},
+			expectResult: []*AnalyzeResult{
+				{
+					Title:   "kube-system/calico-node DaemonSet Coverage",
+					IconKey: "kubernetes_daemonset_coverage",
+					IsFail:  true,
+					Message: "calico-node is missing from control-plane, node-2",
+				},
+				{
+					Title:   "kube-system/log-agent DaemonSet Coverage",
+					IconKey: "kubernetes_daemonset_coverage",
+					IsPass:  true,
+					Message: "log-agent runs on 1/1 nodes",
+				},
+			},
+		},
+		{
+			name:     "analyze all daemonsets",
+			analyzer: troubleshootv1beta2.DaemonSetCoverage{},
+			expectResult: []*AnalyzeResult{
+				{
+					Title:   "kube-system/calico-node DaemonSet Coverage",
+					IconKey: "kubernetes_daemonset_coverage",
+					IsFail:  true,
+					Message: "The daemonset kube-system/calico-node has no ready pod on 2 of 4 nodes: control-plane, node-2",
+				},
+			},
+		},
+		{
+			name: "absent",
+			analyzer: troubleshootv1beta2.DaemonSetCoverage{
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{
+						Warn: &troubleshootv1beta2.SingleOutcome{
+							When:    "absent",
+							Message: "no csi node plugin",
+						},
+					},
+				},
+				Name: "csi-node",
+			},
+			expectResult: []*AnalyzeResult{
+				{
+					Title:   "csi-node DaemonSet Coverage",
+					IconKey: "kubernetes_daemonset_coverage",
+					IsWarn:  true,
+					Message: "no csi node plugin",
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			getFile := func(n string) ([]byte, error) {
+				return files[n], nil
+			}
+			findFiles := func(n string) (map[string][]byte, error) {
+				matching := map[string][]byte{}
+				for name, file := range files {
+					if ok, _ := filepath.Match(n, name); ok {
+						matching[name] = file
+					}
+				}
+				return matching, nil
+			}
+
+			actual, err := analyzeDaemonSetCoverage(&test.analyzer, getFile, findFiles)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectResult, actual)
+		})
+	}
+}
+
+func Test_daemonSetShouldRunOnNode(t *testing.T) {
+	affinity := &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelOSStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"linux"}}}},
+				{MatchFields: []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"windows-special"}}}},
+			},
+		},
+	}}
+	daemonSet := appsv1.DaemonSet{Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Affinity: affinity}}}}
+
+	tests := []struct {
+		name string
+		node corev1.Node
+		want bool
+	}{
+		{
+			name: "matching expression",
+			node: corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "linux", Labels: map[string]string{corev1.LabelOSStable: "linux"}}},
+			want: true,
+		},
+		{
+			name: "matching field",
+			node: corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "windows-special", Labels: map[string]string{corev1.LabelOSStable: "windows"}}},
+			want: true,
+		},
+		{
+			name: "not matching",
+			node: corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "windows", Labels: map[string]string{corev1.LabelOSStable: "windows"}}},
+			want: false,
+		},
+		{
+			name: "memory pressure",
+			node: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "linux", Labels: map[string]string{corev1.LabelOSStable: "linux"}},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: corev1.TaintNodeMemoryPressure, Effect: corev1.TaintEffectNoSchedule}}},
+			},
+			want: true,
+		},
+		{
+			name: "untolerated taint",
+			node: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "linux", Labels: map[string]string{corev1.LabelOSStable: "linux"}},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoExecute}}},
+			},
+			want: false,
+		},
+		{
+			name: "network unavailable without host network",
+			node: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "linux", Labels: map[string]string{corev1.LabelOSStable: "linux"}},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: corev1.TaintNodeNetworkUnavailable, Effect: corev1.TaintEffectNoSchedule}}},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, daemonSetShouldRunOnNode(daemonSet, tt.node))
+		})
+	}
+}
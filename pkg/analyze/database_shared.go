@@ -4,16 +4,24 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/troubleshoot/pkg/collect"
 )
 
+// compareDatabaseConditionalToActual evaluates a conditional such as "connected == true" or
+// "version < 10.x". Version conditionals can be a range, such as "version >= 10.x < 16.x", and don't
+// match when the collector couldn't get the version.
+//
+// When the collector collected the topology, "replicas >= 2" compares the number of replicas, and
+// "replicationLag > 30s" the lag of the replica that is furthest behind, in seconds or as a duration.
+// Replication lag conditionals don't match when the lag of no replica is known.
 func compareDatabaseConditionalToActual(conditional string, result *collect.DatabaseConnection) (bool, error) {
-	parts := strings.Split(strings.TrimSpace(conditional), " ")
+	parts := strings.Fields(conditional)
 
-	if len(parts) != 3 {
+	if len(parts) < 3 || (parts[0] != "version" && len(parts) != 3) || len(parts)%2 != 1 {
 		return false, errors.New("unable to parse conditional")
 	}
 
@@ -35,22 +43,86 @@ func compareDatabaseConditionalToActual(conditional string, result *collect.Data
 		return false, errors.New("unable to parse postgres connected analyzer")
 
 	case "version":
-		expected, err := semver.ParseTolerant(strings.Replace(parts[2], "x", "0", -1))
-		if err != nil {
-			return false, errors.Wrap(err, "failed to parse expected version")
+		if result.Version == "" || result.Version == "Unknown" {
+			return false, nil
+		}
+
+		constraints := []string{}
+		for i := 1; i < len(parts); i += 2 {
+			expected, err := semver.ParseTolerant(strings.Replace(parts[i+1], "x", "0", -1))
+			if err != nil {
+				return false, errors.Wrap(err, "failed to parse expected version")
+			}
+			constraints = append(constraints, fmt.Sprintf("%s%s", parts[i], expected.String()))
 		}
+
 		actual, err := semver.ParseTolerant(strings.Replace(result.Version, "x", "0", -1))
 		if err != nil {
 			return false, errors.Wrap(err, "failed to parse postgres db actual version")
 		}
 
-		expectedRange, err := semver.ParseRange(fmt.Sprintf("%s %s", parts[1], expected.String()))
+		expectedRange, err := semver.ParseRange(strings.Join(constraints, " "))
 		if err != nil {
 			return false, errors.Wrap(err, "failed to parse semver range")
 		}
 
 		return expectedRange(actual), nil
+
+	case "replicas":
+		expected, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return false, errors.Wrap(err, "failed to parse replicas")
+		}
+		if result.Topology == nil {
+			return false, nil
+		}
+		return compareDatabaseNumber(parts[1], float64(result.Topology.Replicas()), float64(expected))
+
+	case "replicationLag":
+		expected, err := parseDatabaseLag(parts[2])
+		if err != nil {
+			return false, err
+		}
+		if result.Topology == nil {
+			return false, nil
+		}
+		lag, ok := result.Topology.MaxLagSeconds()
+		if !ok {
+			return false, nil
+		}
+		return compareDatabaseNumber(parts[1], lag, expected)
 	}
 
 	return false, nil
 }
+
+// parseDatabaseLag parses a lag in seconds, such as 30, or a duration, such as 30s or 1m
+func parseDatabaseLag(lag string) (float64, error) {
+	if seconds, err := strconv.ParseFloat(lag, 64); err == nil {
+		return seconds, nil
+	}
+	duration, err := time.ParseDuration(lag)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to parse replication lag")
+	}
+	return duration.Seconds(), nil
+}
+
+func compareDatabaseNumber(operator string, actual float64, expected float64) (bool, error) {
+	switch operator {
+	case "<":
+		return actual < expected, nil
+	case "<=":
+		return actual <= expected, nil
+	case ">":
+		return actual > expected, nil
+	case ">=":
+		return actual >= expected, nil
+	case "=", "==", "===":
+		return actual == expected, nil
+	case "!=", "!==":
+		return actual != expected, nil
+	}
+
+	return false, errors.Errorf("unknown operator %q", operator)
+}
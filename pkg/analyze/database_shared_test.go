@@ -57,6 +57,50 @@ func Test_compareDatabaseConditionalToActual(t *testing.T) {
 			},
 			expectedMatch: true,
 		},
+		{
+			name:        "version 9.6.24, want < 10.x",
+			conditional: "version < 10.x",
+			result: collect.DatabaseConnection{
+				IsConnected: true,
+				Version:     "9.6.24",
+			},
+			expectedMatch: true,
+		},
+		{
+			name:        "version 14.5, want range",
+			conditional: "version >= 10.x < 16.x",
+			result: collect.DatabaseConnection{
+				IsConnected: true,
+				Version:     "14.5",
+			},
+			expectedMatch: true,
+		},
+		{
+			name:        "version 16.1, want range",
+			conditional: "version >= 10.x < 16.x",
+			result: collect.DatabaseConnection{
+				IsConnected: true,
+				Version:     "16.1",
+			},
+			expectedMatch: false,
+		},
+		{
+			name:        "not connected, no version",
+			conditional: "version < 10.x",
+			result: collect.DatabaseConnection{
+				IsConnected: false,
+			},
+			expectedMatch: false,
+		},
+		{
+			name:        "unknown version",
+			conditional: "version < 10.x",
+			result: collect.DatabaseConnection{
+				IsConnected: true,
+				Version:     "Unknown",
+			},
+			expectedMatch: false,
+		},
 	}
 
 	for _, test := range tests {
@@ -71,3 +115,63 @@ func Test_compareDatabaseConditionalToActual(t *testing.T) {
 		})
 	}
 }
+
+func Test_compareDatabaseConditionalToActual_Invalid(t *testing.T) {
+	for _, conditional := range []string{
+		"connected",
+		"connected == true false",
+		"version < 10.x >=",
+	} {
+		_, err := compareDatabaseConditionalToActual(conditional, &collect.DatabaseConnection{Version: "12.0.0"})
+		assert.Error(t, err, conditional)
+	}
+}
+
+func Test_compareDatabaseConditionalToActual_Topology(t *testing.T) {
+	lag := func(seconds float64) *float64 {
+		return &seconds
+	}
+	result := collect.DatabaseConnection{
+		IsConnected: true,
+		Topology: &collect.DatabaseTopology{
+			Members: []collect.DatabaseMember{
+				{Address: "db-0:5432", Role: collect.DatabaseRolePrimary, IsConnected: true},
+				{Address: "10.0.0.2", Role: collect.DatabaseRoleReplica, Discovered: true, LagSeconds: lag(4)},
+				{Address: "10.0.0.3", Role: collect.DatabaseRoleReplica, Discovered: true, LagSeconds: lag(45.5)},
+			},
+		},
+	}
+
+	tests := []struct {
+		conditional   string
+		result        collect.DatabaseConnection
+		expectedMatch bool
+	}{
+		{conditional: "replicas >= 2", result: result, expectedMatch: true},
+		{conditional: "replicas < 2", result: result, expectedMatch: false},
+		{conditional: "replicationLag > 30", result: result, expectedMatch: true},
+		{conditional: "replicationLag > 1m", result: result, expectedMatch: false},
+		{conditional: "replicationLag <= 45.5s", result: result, expectedMatch: true},
+		{conditional: "replicas < 2", result: collect.DatabaseConnection{IsConnected: true}, expectedMatch: false},
+		{
+			conditional: "replicationLag > 30",
+			result: collect.DatabaseConnection{Topology: &collect.DatabaseTopology{Members: []collect.DatabaseMember{
+				{Role: collect.DatabaseRoleReplica},
+			}}},
+			expectedMatch: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.conditional, func(t *testing.T) {
+			actual, err := compareDatabaseConditionalToActual(test.conditional, &test.result)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedMatch, actual)
+		})
+	}
+
+	for _, conditional := range []string{"replicas >= two", "replicationLag > soon", "replicas ~ 2"} {
+		_, err := compareDatabaseConditionalToActual(conditional, &result)
+		assert.Error(t, err, conditional)
+	}
+}
@@ -1,9 +1,11 @@
 package analyzer
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"strings"
+	"text/template"
 
 	"github.com/pkg/errors"
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
@@ -107,6 +109,16 @@ func ParseNodesForProviders(nodes []corev1.Node) (providers, string) {
 			}
 		}
 
+		// the kubelets of k3s and rke2 have it in their version, such as v1.27.4+k3s1 and v1.27.4+rke2r1
+		if strings.Contains(node.Status.NodeInfo.KubeletVersion, "+k3s") {
+			foundProviders.k3s = true
+			stringProvider = "k3s"
+		}
+		if strings.Contains(node.Status.NodeInfo.KubeletVersion, "+rke2") {
+			foundProviders.rke2 = true
+			stringProvider = "rke2"
+		}
+
 		if node.Status.NodeInfo.OSImage == "Docker Desktop" {
 			foundProviders.dockerDesktop = true
 			stringProvider = "dockerDesktop"
@@ -141,8 +153,9 @@ func ParseNodesForProviders(nodes []corev1.Node) (providers, string) {
 	return foundProviders, stringProvider
 }
 
+// analyzeDistribution templates messages with the distribution that was found, such as
+// "{{ .Distribution }} is not supported", which is the name used in when, or empty if none was found
 func analyzeDistribution(analyzer *troubleshootv1beta2.Distribution, getCollectedFileContents func(string) ([]byte, error)) (*AnalyzeResult, error) {
-	var unknownDistribution string
 	collected, err := getCollectedFileContents("cluster-resources/nodes.json")
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get contents of nodes.json")
@@ -153,7 +166,7 @@ func analyzeDistribution(analyzer *troubleshootv1beta2.Distribution, getCollecte
 		return nil, errors.Wrap(err, "failed to unmarshal node list")
 	}
 
-	foundProviders, _ := ParseNodesForProviders(nodes.Items)
+	foundProviders, distribution := ParseNodesForProviders(nodes.Items)
 
 	apiResourcesBytes, err := getCollectedFileContents("cluster-resources/resources.json")
 	// if the file is not found, that is not a fatal error
@@ -163,8 +176,26 @@ func analyzeDistribution(analyzer *troubleshootv1beta2.Distribution, getCollecte
 		if err := json.Unmarshal(apiResourcesBytes, &apiResources); err != nil {
 			return nil, errors.Wrap(err, "failed to unmarshal api resource list")
 		}
-		_ = CheckApiResourcesForProviders(&foundProviders, apiResources, "")
+		distribution = CheckApiResourcesForProviders(&foundProviders, apiResources, distribution)
+	}
+
+	result, err := distributionResult(analyzer, foundProviders)
+	if err != nil {
+		return result, err
+	}
+
+	message, err := renderDistributionMessage(result.Message, distribution)
+	if err != nil {
+		return nil, err
 	}
+	result.Message = message
+
+	return result, nil
+}
+
+// distributionResult is the result of the first outcome that matches the providers that were found
+func distributionResult(analyzer *troubleshootv1beta2.Distribution, foundProviders providers) (*AnalyzeResult, error) {
+	var unknownDistribution string
 
 	title := analyzer.CheckName
 	if title == "" {
@@ -255,6 +286,18 @@ func analyzeDistribution(analyzer *troubleshootv1beta2.Distribution, getCollecte
 	return result, nil
 }
 
+func renderDistributionMessage(message string, distribution string) (string, error) {
+	tmpl, err := template.New("distribution").Parse(message)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create new message template")
+	}
+	var m bytes.Buffer
+	if err := tmpl.Execute(&m, struct{ Distribution string }{distribution}); err != nil {
+		return "", errors.Wrap(err, "failed to execute template")
+	}
+	return m.String(), nil
+}
+
 func compareDistributionConditionalToActual(conditional string, actual providers, unknownDistribution *string) (bool, error) {
 	parts := strings.Split(strings.TrimSpace(conditional), " ")
 
@@ -291,6 +334,8 @@ func compareDistributionConditionalToActual(conditional string, actual providers
 		isMatch = actual.digitalOcean
 	case openShift:
 		isMatch = actual.openShift
+	case tanzu:
+		isMatch = actual.tanzu
 	case kurl:
 		isMatch = actual.kurl
 	case aks:
@@ -1,10 +1,15 @@
 package analyzer
 
 import (
+	"encoding/json"
+	"errors"
 	"testing"
 
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func Test_compareDistributionConditionalToActual(t *testing.T) {
@@ -39,6 +44,14 @@ func Test_compareDistributionConditionalToActual(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			name:        "== tanzu when tanzu is found",
+			conditional: "== tanzu",
+			input: providers{
+				tanzu: true,
+			},
+			expected: true,
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -83,3 +96,57 @@ func Test_mustNormalizeDistributionName(t *testing.T) {
 		})
 	}
 }
+
+func TestParseNodesForProviders_KubeletVersion(t *testing.T) {
+	nodes := []corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: corev1.NodeStatus{
+				NodeInfo: corev1.NodeSystemInfo{KubeletVersion: "v1.27.4+rke2r1"},
+			},
+		},
+	}
+
+	foundProviders, distribution := ParseNodesForProviders(nodes)
+	assert.True(t, foundProviders.rke2)
+	assert.Equal(t, "rke2", distribution)
+}
+
+func TestAnalyzeDistribution(t *testing.T) {
+	nodes, err := json.Marshal(corev1.NodeList{
+		Items: []corev1.Node{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Spec:       corev1.NodeSpec{ProviderID: "gce://project/us-central1-a/node-1"},
+			},
+		},
+	})
+	require.NoError(t, err)
+	getCollectedFileContents := func(name string) ([]byte, error) {
+		if name == "cluster-resources/nodes.json" {
+			return nodes, nil
+		}
+		return nil, errors.New("not found")
+	}
+
+	analyzer := &troubleshootv1beta2.Distribution{
+		Outcomes: []*troubleshootv1beta2.Outcome{
+			{
+				Fail: &troubleshootv1beta2.SingleOutcome{
+					When:    "== gke",
+					Message: "{{ .Distribution }} is not supported",
+				},
+			},
+			{
+				Pass: &troubleshootv1beta2.SingleOutcome{
+					Message: "{{ .Distribution }} is supported",
+				},
+			},
+		},
+	}
+
+	result, err := analyzeDistribution(analyzer, getCollectedFileContents)
+	require.NoError(t, err)
+	assert.True(t, result.IsFail)
+	assert.Equal(t, "gke is not supported", result.Message)
+}
@@ -7,13 +7,17 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 
 	getter "github.com/hashicorp/go-getter"
 	"github.com/pkg/errors"
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
 	troubleshootscheme "github.com/replicatedhq/troubleshoot/pkg/client/troubleshootclientset/scheme"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
 	"github.com/replicatedhq/troubleshoot/pkg/docrewrite"
+	"github.com/replicatedhq/troubleshoot/pkg/export"
 	"github.com/replicatedhq/troubleshoot/pkg/logger"
+	"github.com/replicatedhq/troubleshoot/pkg/strictdecode"
 	"k8s.io/client-go/kubernetes/scheme"
 )
 
@@ -21,8 +25,36 @@ type fileContentProvider struct {
 	rootDir string
 }
 
+// AnalyzerRun is an analyzer that was run against a bundle. Analyzer is the *troubleshootv1beta2.Analyze or
+// *troubleshootv1beta2.HostAnalyze that was run.
+type AnalyzerRun struct {
+	Analyzer  interface{}
+	StartTime time.Time
+	Results   []*AnalyzeResult
+	Err       error
+}
+
 // Analyze local will analyze a locally available (already downloaded) bundle
 func AnalyzeLocal(localBundlePath string, analyzers []*troubleshootv1beta2.Analyze, hostAnalyzers []*troubleshootv1beta2.HostAnalyze) ([]*AnalyzeResult, error) {
+	return AnalyzeLocalWithCallback(localBundlePath, analyzers, hostAnalyzers, nil)
+}
+
+// AnalyzeLocalWithCallback is AnalyzeLocal that calls onRun, if it is not nil, after each analyzer has run
+func AnalyzeLocalWithCallback(localBundlePath string, analyzers []*troubleshootv1beta2.Analyze, hostAnalyzers []*troubleshootv1beta2.HostAnalyze, onRun func(AnalyzerRun)) ([]*AnalyzeResult, error) {
+	return analyzeLocal(localBundlePath, analyzers, hostAnalyzers, nil, onRun)
+}
+
+// AnalyzeLocalWithCache is AnalyzeLocal that uses the results in cache of analyzers that have already run
+// against the same files, and adds the results of the others to it
+func AnalyzeLocalWithCache(localBundlePath string, analyzers []*troubleshootv1beta2.Analyze, hostAnalyzers []*troubleshootv1beta2.HostAnalyze, cache *AnalyzerCache) ([]*AnalyzeResult, error) {
+	return analyzeLocal(localBundlePath, analyzers, hostAnalyzers, cache, nil)
+}
+
+func analyzeLocal(localBundlePath string, analyzers []*troubleshootv1beta2.Analyze, hostAnalyzers []*troubleshootv1beta2.HostAnalyze, cache *AnalyzerCache, onRun func(AnalyzerRun)) ([]*AnalyzeResult, error) {
+	if onRun == nil {
+		onRun = func(AnalyzerRun) {}
+	}
+
 	rootDir, err := FindBundleRootDir(localBundlePath)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to find root dir")
@@ -32,7 +64,18 @@ func AnalyzeLocal(localBundlePath string, analyzers []*troubleshootv1beta2.Analy
 
 	analyzeResults := []*AnalyzeResult{}
 	for _, analyzer := range analyzers {
-		analyzeResult, err := Analyze(analyzer, fcp.getFileContents, fcp.getChildFileContents)
+		startTime := time.Now()
+		var analyzeResult []*AnalyzeResult
+		var err error
+		if analyzer.Compound != nil {
+			// compound analyzers read the results before them rather than files, so they are not cached
+			analyzeResult, err = AnalyzeCompound(analyzer, analyzeResults)
+		} else {
+			analyzeResult, err = cache.run(analyzer, fcp, func(getFile getCollectedFileContents, findFiles getChildCollectedFileContents) ([]*AnalyzeResult, error) {
+				return Analyze(analyzer, getFile, findFiles)
+			})
+		}
+		onRun(AnalyzerRun{Analyzer: analyzer, StartTime: startTime, Results: analyzeResult, Err: err})
 		if err != nil {
 			logger.Printf("An analyzer failed to run: %v", err)
 			continue
@@ -47,7 +90,11 @@ func AnalyzeLocal(localBundlePath string, analyzers []*troubleshootv1beta2.Analy
 	}
 
 	for _, hostAnalyzer := range hostAnalyzers {
-		analyzeResult := HostAnalyze(hostAnalyzer, fcp.getFileContents, fcp.getChildFileContents)
+		startTime := time.Now()
+		analyzeResult, _ := cache.run(hostAnalyzer, fcp, func(getFile getCollectedFileContents, findFiles getChildCollectedFileContents) ([]*AnalyzeResult, error) {
+			return HostAnalyze(hostAnalyzer, getFile, findFiles), nil
+		})
+		onRun(AnalyzerRun{Analyzer: hostAnalyzer, StartTime: startTime, Results: analyzeResult})
 		analyzeResults = append(analyzeResults, analyzeResult...)
 	}
 
@@ -55,21 +102,55 @@ func AnalyzeLocal(localBundlePath string, analyzers []*troubleshootv1beta2.Analy
 }
 
 func DownloadAndAnalyze(bundleURL string, analyzersSpec string) ([]*AnalyzeResult, error) {
+	return DownloadAndAnalyzeWithCache(bundleURL, analyzersSpec, nil)
+}
+
+// DownloadAndAnalyzeWithCache is DownloadAndAnalyze that uses and adds to the results in cache, if it is not nil
+func DownloadAndAnalyzeWithCache(bundleURL string, analyzersSpec string, cache *AnalyzerCache) ([]*AnalyzeResult, error) {
+	return DownloadAndAnalyzeWithOptions(bundleURL, analyzersSpec, DownloadAndAnalyzeOptions{Cache: cache})
+}
+
+type DownloadAndAnalyzeOptions struct {
+	// Cache is used and added to, if it is not nil
+	Cache *AnalyzerCache
+	// MessageCatalog translates the outcome messages of the analyzers to Language, if both are set
+	MessageCatalog *MessageCatalog
+	Language       string
+}
+
+func DownloadAndAnalyzeWithOptions(bundleURL string, analyzersSpec string, opts DownloadAndAnalyzeOptions) ([]*AnalyzeResult, error) {
 	tmpDir, err := ioutil.TempDir("", "troubleshoot-k8s")
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create temp dir")
 	}
 	defer os.RemoveAll(tmpDir)
 
-	if err := downloadTroubleshootBundle(bundleURL, tmpDir); err != nil {
+	bundleDir := filepath.Join(tmpDir, "bundle")
+	if info, err := os.Stat(bundleURL); err == nil && info.IsDir() {
+		bundleDir = bundleURL
+	} else if err := downloadTroubleshootBundle(bundleURL, bundleDir); err != nil {
 		return nil, errors.Wrap(err, "failed to download bundle")
 	}
 
-	rootDir, err := FindBundleRootDir(tmpDir)
+	rootDir, err := FindBundleRootDir(bundleDir)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to find root dir")
 	}
 
+	if err := collect.VerifyBundleManifest(rootDir); err != nil {
+		return nil, err
+	}
+
+	// must-gather archives are converted to a support bundle so that the same analyzers can run against them
+	if _, err := os.Stat(filepath.Join(rootDir, "version.yaml")); os.IsNotExist(err) {
+		if mustGatherDir, ok := export.FindMustGatherRootDir(bundleDir); ok {
+			rootDir = filepath.Join(tmpDir, "must-gather-bundle")
+			if err := export.ImportMustGather(mustGatherDir, rootDir); err != nil {
+				return nil, errors.Wrap(err, "failed to import must-gather")
+			}
+		}
+	}
+
 	_, err = os.Stat(filepath.Join(rootDir, "version.yaml"))
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to read version.yaml")
@@ -85,7 +166,7 @@ func DownloadAndAnalyze(bundleURL string, analyzersSpec string) ([]*AnalyzeResul
 		}
 		analyzers = defaultAnalyzers
 	} else {
-		parsedAnalyzers, parsedHostAnalyzers, err := parseAnalyzers(analyzersSpec)
+		parsedAnalyzers, parsedHostAnalyzers, err := ParseAnalyzers(analyzersSpec)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to parse analyzers")
 		}
@@ -93,10 +174,22 @@ func DownloadAndAnalyze(bundleURL string, analyzersSpec string) ([]*AnalyzeResul
 		hostAnalyzers = parsedHostAnalyzers
 	}
 
-	return AnalyzeLocal(rootDir, analyzers, hostAnalyzers)
+	LocalizeAnalyzers(analyzers, hostAnalyzers, opts.MessageCatalog, opts.Language)
+
+	return AnalyzeLocalWithCache(rootDir, analyzers, hostAnalyzers, opts.Cache)
 }
 
 func downloadTroubleshootBundle(bundleURL string, destDir string) error {
+	// archives in object storage are extracted as they are streamed, rather than downloaded first
+	if IsS3URL(bundleURL) {
+		r, err := OpenS3Bundle(bundleURL)
+		if err != nil {
+			return errors.Wrap(err, "failed to open support bundle")
+		}
+		defer r.Close()
+		return ExtractTroubleshootBundle(r, destDir)
+	}
+
 	if bundleURL[0] == os.PathSeparator {
 		f, err := os.Open(bundleURL)
 		if err != nil {
@@ -181,9 +274,10 @@ func ExtractTroubleshootBundle(reader io.Reader, destDir string) error {
 	return nil
 }
 
-func parseAnalyzers(spec string) ([]*troubleshootv1beta2.Analyze, []*troubleshootv1beta2.HostAnalyze, error) {
+// ParseAnalyzers returns the analyzers and host analyzers of an Analyzer or SupportBundle spec
+func ParseAnalyzers(spec string) ([]*troubleshootv1beta2.Analyze, []*troubleshootv1beta2.HostAnalyze, error) {
 	troubleshootscheme.AddToScheme(scheme.Scheme)
-	decode := scheme.Codecs.UniversalDeserializer().Decode
+	decode := strictdecode.NewDecoder(scheme.Codecs.UniversalDeserializer()).Decode
 
 	convertedSpec, err := docrewrite.ConvertToV1Beta2([]byte(spec))
 	if err != nil {
@@ -228,7 +322,7 @@ spec:
               when: ">= 1.15.0"
               message: Your cluster meets the recommended and required versions of Kubernetes.`
 
-	return parseAnalyzers(spec)
+	return ParseAnalyzers(spec)
 }
 
 // FindBundleRootDir detects whether the bundle is stored inside a subdirectory or not.
@@ -268,6 +362,15 @@ func (f fileContentProvider) getFileContents(fileName string) ([]byte, error) {
 	return ioutil.ReadFile(filepath.Join(f.rootDir, fileName))
 }
 
+// relativePath is the path of a file from getChildFileContents in the bundle
+func (f fileContentProvider) relativePath(path string) string {
+	rel, err := filepath.Rel(f.rootDir, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
 func (f fileContentProvider) getChildFileContents(dirName string) (map[string][]byte, error) {
 	files, err := filepath.Glob(filepath.Join(f.rootDir, dirName))
 	if err != nil {
@@ -0,0 +1,46 @@
+package analyzer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadAndAnalyze_MustGather(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"must-gather.local.123/quay-io-openshift-must-gather/cluster-scoped-resources/core/nodes/node-1.yaml": "apiVersion: v1\nkind: Node\nmetadata:\n  name: node-1\n",
+		"must-gather.local.123/quay-io-openshift-must-gather/cluster-scoped-resources/core/nodes/node-2.yaml": "apiVersion: v1\nkind: Node\nmetadata:\n  name: node-2\n",
+	}
+	for name, contents := range files {
+		filename := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755))
+		require.NoError(t, ioutil.WriteFile(filename, []byte(contents), 0644))
+	}
+
+	spec := `apiVersion: troubleshoot.sh/v1beta2
+kind: Analyzer
+metadata:
+  name: nodes
+spec:
+  analyzers:
+    - nodeResources:
+        checkName: Node count
+        outcomes:
+          - fail:
+              when: "count() < 2"
+              message: Not enough nodes
+          - pass:
+              message: Enough nodes
+`
+
+	results, err := DownloadAndAnalyze(dir, spec)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].IsPass)
+	assert.Equal(t, "Enough nodes", results[0].Message)
+}
@@ -0,0 +1,260 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/google/pprof/profile"
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	GoProfileGoroutine = "goroutine"
+	GoProfileHeap      = "heap"
+)
+
+// goProfileStackFrames is how many frames of the top stack are in TopStack
+const goProfileStackFrames = 4
+
+// GoProfileStats is what the messages of a goProfile analyzer are templated with
+type GoProfileStats struct {
+	File string
+	// Type is goroutine or heap
+	Type string
+	// Goroutines is the number of goroutines of a goroutine profile
+	Goroutines int64
+	// InuseBytes is the in-use heap of a heap profile
+	InuseBytes int64
+	// TopStackPercent is the share of the goroutines, or of the in-use heap, of the stack that has the most
+	TopStackPercent float64
+	// TopStack is the functions of the stack that has the most, leaf first
+	TopStack string
+}
+
+// Example: goroutines > 10000, topStackPercent >= 50, inuseBytes > 2Gi
+var goProfileWhenRX = regexp.MustCompile(`^(goroutines|inuseBytes|topStackPercent)\s*(<=|>=|<|>|=+|!=+)\s*(\S+)$`)
+
+func analyzeGoProfile(analyzer *troubleshootv1beta2.GoProfile, findFiles getChildCollectedFileContents) ([]*AnalyzeResult, error) {
+	title := analyzer.CheckName
+	if title == "" {
+		title = "Go Profile"
+	}
+
+	files, err := findFiles(analyzer.FileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find profiles %s", analyzer.FileName)
+	}
+	if len(files) == 0 {
+		return nil, errors.Errorf("no profiles matching %s were collected", analyzer.FileName)
+	}
+
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	results := []*AnalyzeResult{}
+	for _, filename := range filenames {
+		stats, err := goProfileStats(filename, files[filename])
+		if err != nil {
+			return nil, err
+		}
+		result, err := goProfileResult(analyzer, fmt.Sprintf("%s: %s", title, filename), stats)
+		if err != nil {
+			return nil, err
+		}
+		if result != nil {
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// goProfileResult returns the result of the first outcome that matches the profile, or nil if none do
+func goProfileResult(analyzer *troubleshootv1beta2.GoProfile, title string, stats GoProfileStats) (*AnalyzeResult, error) {
+	for _, outcome := range analyzer.Outcomes {
+		result := &AnalyzeResult{Title: title}
+
+		var single *troubleshootv1beta2.SingleOutcome
+		switch {
+		case outcome.Fail != nil:
+			single, result.IsFail = outcome.Fail, true
+		case outcome.Warn != nil:
+			single, result.IsWarn = outcome.Warn, true
+		case outcome.Pass != nil:
+			single, result.IsPass = outcome.Pass, true
+		default:
+			continue
+		}
+
+		if single.When != "" {
+			matches, err := compareGoProfileWhen(single.When, stats)
+			if err != nil {
+				return nil, err
+			}
+			if !matches {
+				continue
+			}
+		}
+
+		tmpl, err := template.New("goProfile").Parse(single.Message)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create new message template")
+		}
+		var m bytes.Buffer
+		if err := tmpl.Execute(&m, stats); err != nil {
+			return nil, errors.Wrap(err, "failed to execute template")
+		}
+
+		result.Message = m.String()
+		result.URI = single.URI
+		return result, nil
+	}
+
+	return nil, nil
+}
+
+// compareGoProfileWhen returns false for statistics that the type of the profile doesn't have, such as
+// goroutines of a heap profile
+func compareGoProfileWhen(when string, stats GoProfileStats) (bool, error) {
+	matches := goProfileWhenRX.FindStringSubmatch(strings.TrimSpace(when))
+	if matches == nil {
+		return false, errors.Errorf("failed to parse when %q", when)
+	}
+	stat, operator, value := matches[1], matches[2], matches[3]
+
+	var actual, expected float64
+	switch stat {
+	case "goroutines":
+		if stats.Type != GoProfileGoroutine {
+			return false, nil
+		}
+		actual = float64(stats.Goroutines)
+	case "inuseBytes":
+		if stats.Type != GoProfileHeap {
+			return false, nil
+		}
+		actual = float64(stats.InuseBytes)
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to parse %q in when %q", value, when)
+		}
+		expected = float64(quantity.Value())
+	case "topStackPercent":
+		actual = stats.TopStackPercent
+	}
+
+	if stat != "inuseBytes" {
+		var err error
+		expected, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to parse %q in when %q", value, when)
+		}
+	}
+
+	switch operator {
+	case "<":
+		return actual < expected, nil
+	case "<=":
+		return actual <= expected, nil
+	case ">":
+		return actual > expected, nil
+	case ">=":
+		return actual >= expected, nil
+	case "=", "==", "===":
+		return actual == expected, nil
+	default:
+		return actual != expected, nil
+	}
+}
+
+// goProfileStats parses a goroutine or heap profile. Samples are grouped by their stack, which can be
+// split across several samples with different labels.
+func goProfileStats(filename string, data []byte) (GoProfileStats, error) {
+	stats := GoProfileStats{File: filename}
+
+	p, err := profile.ParseData(data)
+	if err != nil {
+		return stats, errors.Wrapf(err, "failed to parse profile %s", filename)
+	}
+
+	valueIndex := -1
+	if p.PeriodType != nil && p.PeriodType.Type == "goroutine" {
+		stats.Type = GoProfileGoroutine
+		valueIndex = 0
+	} else {
+		for i, sampleType := range p.SampleType {
+			if sampleType.Type == "inuse_space" {
+				stats.Type = GoProfileHeap
+				valueIndex = i
+				break
+			}
+		}
+	}
+	if valueIndex == -1 {
+		return stats, errors.Errorf("profile %s is not a goroutine or heap profile", filename)
+	}
+
+	var total int64
+	byStack := map[string]int64{}
+	for _, sample := range p.Sample {
+		if valueIndex >= len(sample.Value) {
+			continue
+		}
+		value := sample.Value[valueIndex]
+		total += value
+		byStack[goProfileStack(sample)] += value
+	}
+
+	if stats.Type == GoProfileGoroutine {
+		stats.Goroutines = total
+	} else {
+		stats.InuseBytes = total
+	}
+
+	var top int64
+	for stack, value := range byStack {
+		// ties are broken by the stack, so that the top stack is the same for every run
+		if value > top || (value == top && stack < stats.TopStack) {
+			top, stats.TopStack = value, stack
+		}
+	}
+	if total > 0 {
+		stats.TopStackPercent = float64(top) * 100 / float64(total)
+	}
+
+	return stats, nil
+}
+
+// goProfileStack returns the functions of the first frames of the sample, leaf first. Frames of profiles
+// that are not symbolized are their addresses.
+func goProfileStack(sample *profile.Sample) string {
+	frames := []string{}
+	for _, location := range sample.Location {
+		if len(location.Line) == 0 {
+			frames = append(frames, fmt.Sprintf("%#x", location.Address))
+		}
+		// inlined functions are first
+		for _, line := range location.Line {
+			if line.Function != nil {
+				frames = append(frames, line.Function.Name)
+			}
+		}
+		if len(frames) >= goProfileStackFrames {
+			break
+		}
+	}
+	if len(frames) > goProfileStackFrames {
+		frames = frames[:goProfileStackFrames]
+	}
+	return strings.Join(frames, " <- ")
+}
@@ -0,0 +1,184 @@
+package analyzer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/pprof/profile"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testGoProfile returns a gzipped protobuf profile with a sample of each value for the stacks, which are
+// function names leaf first
+func testGoProfile(t *testing.T, periodType *profile.ValueType, sampleTypes []*profile.ValueType, samples map[string][]int64, stacks map[string][]string) []byte {
+	p := &profile.Profile{
+		PeriodType: periodType,
+		SampleType: sampleTypes,
+	}
+
+	functions := map[string]*profile.Function{}
+	for name, values := range samples {
+		sample := &profile.Sample{Value: values}
+		for _, functionName := range stacks[name] {
+			function, ok := functions[functionName]
+			if !ok {
+				function = &profile.Function{ID: uint64(len(functions) + 1), Name: functionName}
+				functions[functionName] = function
+				p.Function = append(p.Function, function)
+			}
+			location := &profile.Location{
+				ID:   uint64(len(p.Location) + 1),
+				Line: []profile.Line{{Function: function}},
+			}
+			p.Location = append(p.Location, location)
+			sample.Location = append(sample.Location, location)
+		}
+		p.Sample = append(p.Sample, sample)
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, p.Write(&b))
+	return b.Bytes()
+}
+
+func testGoroutineProfile(t *testing.T) []byte {
+	return testGoProfile(t,
+		&profile.ValueType{Type: "goroutine", Unit: "count"},
+		[]*profile.ValueType{{Type: "goroutine", Unit: "count"}},
+		map[string][]int64{"leak": {9000}, "main": {1}, "http": {999}},
+		map[string][]string{
+			"leak": {"runtime.gopark", "runtime.chanrecv", "main.worker", "main.startWorkers", "main.main"},
+			"main": {"runtime.gopark", "main.main"},
+			"http": {"runtime.gopark", "net/http.(*conn).serve"},
+		},
+	)
+}
+
+func testHeapProfile(t *testing.T) []byte {
+	return testGoProfile(t,
+		&profile.ValueType{Type: "space", Unit: "bytes"},
+		[]*profile.ValueType{
+			{Type: "alloc_objects", Unit: "count"},
+			{Type: "alloc_space", Unit: "bytes"},
+			{Type: "inuse_objects", Unit: "count"},
+			{Type: "inuse_space", Unit: "bytes"},
+		},
+		map[string][]int64{"cache": {10, 4096, 10, 3 << 30}, "buffers": {100, 1 << 30, 1, 1 << 30}},
+		map[string][]string{
+			"cache":   {"main.(*cache).add", "main.handle"},
+			"buffers": {"bytes.growSlice", "bytes.(*Buffer).grow"},
+		},
+	)
+}
+
+func TestGoProfileStats(t *testing.T) {
+	stats, err := goProfileStats("goroutine.pprof", testGoroutineProfile(t))
+	require.NoError(t, err)
+	assert.Equal(t, GoProfileStats{
+		File:            "goroutine.pprof",
+		Type:            GoProfileGoroutine,
+		Goroutines:      10000,
+		TopStackPercent: 90,
+		TopStack:        "runtime.gopark <- runtime.chanrecv <- main.worker <- main.startWorkers",
+	}, stats)
+
+	stats, err = goProfileStats("heap.pprof", testHeapProfile(t))
+	require.NoError(t, err)
+	assert.Equal(t, GoProfileStats{
+		File:            "heap.pprof",
+		Type:            GoProfileHeap,
+		InuseBytes:      4 << 30,
+		TopStackPercent: 75,
+		TopStack:        "main.(*cache).add <- main.handle",
+	}, stats)
+}
+
+func TestGoProfileStats_Text(t *testing.T) {
+	// the format of /debug/pprof/goroutine?debug=1
+	text := `goroutine profile: total 3
+2 @ 0x43a8f6 0x4066ac 0x406298 0x4a7f45 0x46a881
+#	0x4a7f44	main.worker+0x24	/app/main.go:12
+
+1 @ 0x43a8f6 0x46a881
+#	0x43a8f5	runtime.gopark+0xd5	/usr/local/go/src/runtime/proc.go:363
+`
+	stats, err := goProfileStats("goroutine.txt", []byte(text))
+	require.NoError(t, err)
+	assert.Equal(t, GoProfileGoroutine, stats.Type)
+	assert.Equal(t, int64(3), stats.Goroutines)
+	assert.InDelta(t, 66.67, stats.TopStackPercent, 0.01)
+}
+
+func TestAnalyzeGoProfile(t *testing.T) {
+	files := map[string][]byte{
+		"profiles/api/goroutine.pprof": testGoroutineProfile(t),
+		"profiles/api/heap.pprof":      testHeapProfile(t),
+	}
+	findFiles := func(glob string) (map[string][]byte, error) {
+		return files, nil
+	}
+
+	analyzer := &troubleshootv1beta2.GoProfile{
+		AnalyzeMeta: troubleshootv1beta2.AnalyzeMeta{CheckName: "API"},
+		FileName:    "profiles/api/*",
+		Outcomes: []*troubleshootv1beta2.Outcome{
+			{
+				Fail: &troubleshootv1beta2.SingleOutcome{
+					When:    "goroutines > 5000",
+					Message: "{{ .Goroutines }} goroutines, {{ printf \"%.0f\" .TopStackPercent }}% in {{ .TopStack }}",
+				},
+			},
+			{
+				Warn: &troubleshootv1beta2.SingleOutcome{
+					When:    "inuseBytes > 2Gi",
+					Message: "{{ .InuseBytes }} bytes in use",
+				},
+			},
+			{
+				Pass: &troubleshootv1beta2.SingleOutcome{
+					Message: "No leaks in {{ .File }}",
+				},
+			},
+		},
+	}
+
+	results, err := analyzeGoProfile(analyzer, findFiles)
+	require.NoError(t, err)
+	assert.Equal(t, []*AnalyzeResult{
+		{
+			Title:   "API: profiles/api/goroutine.pprof",
+			IsFail:  true,
+			Message: "10000 goroutines, 90% in runtime.gopark <- runtime.chanrecv <- main.worker <- main.startWorkers",
+		},
+		{
+			Title:   "API: profiles/api/heap.pprof",
+			IsWarn:  true,
+			Message: "4294967296 bytes in use",
+		},
+	}, results)
+
+	analyzer.Outcomes[0].Fail.When = "goroutines > 20000"
+	analyzer.Outcomes[1].Warn.When = "topStackPercent > 80"
+	results, err = analyzeGoProfile(analyzer, findFiles)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].IsWarn)
+	assert.True(t, results[1].IsPass)
+	assert.Equal(t, "No leaks in profiles/api/heap.pprof", results[1].Message)
+}
+
+func TestAnalyzeGoProfile_InvalidWhen(t *testing.T) {
+	findFiles := func(glob string) (map[string][]byte, error) {
+		return map[string][]byte{"goroutine.pprof": testGoroutineProfile(t)}, nil
+	}
+
+	_, err := analyzeGoProfile(&troubleshootv1beta2.GoProfile{
+		FileName: "goroutine.pprof",
+		Outcomes: []*troubleshootv1beta2.Outcome{
+			{Fail: &troubleshootv1beta2.SingleOutcome{When: "threads > 10"}},
+		},
+	}, findFiles)
+	assert.EqualError(t, err, `failed to parse when "threads > 10"`)
+}
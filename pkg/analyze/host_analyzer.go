@@ -44,6 +44,10 @@ func GetHostAnalyzer(analyzer *troubleshootv1beta2.HostAnalyze) (HostAnalyzer, b
 		return &AnalyzeHostServices{analyzer.HostServices}, true
 	case analyzer.HostOS != nil:
 		return &AnalyzeHostOS{analyzer.HostOS}, true
+	case analyzer.PortAvailability != nil:
+		return &AnalyzeHostPortAvailability{analyzer.PortAvailability}, true
+	case analyzer.WindowsFeatures != nil:
+		return &AnalyzeHostWindowsFeatures{analyzer.WindowsFeatures}, true
 	default:
 		return nil, false
 	}
@@ -0,0 +1,119 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+)
+
+// AnalyzeHostPortAvailability is an analyzer that will return only one matching result. The first match
+// that is encountered is the one that is returned.
+type AnalyzeHostPortAvailability struct {
+	hostAnalyzer *troubleshootv1beta2.PortAvailabilityAnalyze
+}
+
+func (a *AnalyzeHostPortAvailability) Title() string {
+	return hostAnalyzerTitleOrDefault(a.hostAnalyzer.AnalyzeMeta, "Port Availability")
+}
+
+func (a *AnalyzeHostPortAvailability) IsExcluded() (bool, error) {
+	return isExcluded(a.hostAnalyzer.Exclude)
+}
+
+// Analyze the ports that were checked. The "when" of an outcome is a port status, which is one of
+// "available", "address-in-use", "blocked", "bind-permission-denied" or "error", and matches when any
+// of the ports have it. An outcome with no "when" always matches.
+//
+// Messages can use {{ .Ports }}, which are the ports that have the status, and the processes that are
+// listening on ports that are in use when they could be found, such as "10250/tcp (kubelet, pid 1042)".
+// For outcomes without a "when", they are the ports that are not available.
+func (a *AnalyzeHostPortAvailability) Analyze(getCollectedFileContents func(string) ([]byte, error)) ([]*AnalyzeResult, error) {
+	hostAnalyzer := a.hostAnalyzer
+
+	collectorName := hostAnalyzer.CollectorName
+	if collectorName == "" {
+		collectorName = "portAvailability"
+	}
+	fullPath := path.Join("host-collectors/portAvailability", fmt.Sprintf("%s.json", collectorName))
+
+	collected, err := getCollectedFileContents(fullPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read collected file name: %s", fullPath)
+	}
+	actual := collect.PortAvailabilityResult{}
+	if err := json.Unmarshal(collected, &actual); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal collected")
+	}
+
+	result := &AnalyzeResult{Title: a.Title()}
+
+	for _, outcome := range hostAnalyzer.Outcomes {
+		var single *troubleshootv1beta2.SingleOutcome
+		switch {
+		case outcome.Fail != nil:
+			single = outcome.Fail
+		case outcome.Warn != nil:
+			single = outcome.Warn
+		case outcome.Pass != nil:
+			single = outcome.Pass
+		default:
+			continue
+		}
+
+		ports := portsWithStatus(actual.Ports, single.When)
+		if single.When != "" && len(ports) == 0 {
+			continue
+		}
+
+		result.IsFail = single == outcome.Fail
+		result.IsWarn = single == outcome.Warn
+		result.IsPass = single == outcome.Pass
+
+		message, err := renderPortAvailabilityMessage(single.Message, ports)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to render message")
+		}
+		result.Message = message
+		result.URI = single.URI
+
+		return []*AnalyzeResult{result}, nil
+	}
+
+	return []*AnalyzeResult{result}, nil
+}
+
+// portsWithStatus returns the ports with the status, or that are not available if the status is empty
+func portsWithStatus(ports []collect.PortStatus, status string) []collect.PortStatus {
+	matching := []collect.PortStatus{}
+	for _, port := range ports {
+		if status == "" && port.Status != collect.PortStatusAvailable || status != "" && string(port.Status) == status {
+			matching = append(matching, port)
+		}
+	}
+	return matching
+}
+
+func renderPortAvailabilityMessage(message string, ports []collect.PortStatus) (string, error) {
+	tmpl, err := template.New("portAvailability").Parse(message)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create new message template")
+	}
+
+	descriptions := []string{}
+	for _, port := range ports {
+		descriptions = append(descriptions, port.String())
+	}
+
+	var m bytes.Buffer
+	if err := tmpl.Execute(&m, struct{ Ports string }{strings.Join(descriptions, ", ")}); err != nil {
+		return "", errors.Wrap(err, "failed to execute template")
+	}
+	return m.String(), nil
+}
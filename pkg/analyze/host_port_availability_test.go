@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeHostPortAvailability(t *testing.T) {
+	outcomes := []*troubleshootv1beta2.Outcome{
+		{
+			Fail: &troubleshootv1beta2.SingleOutcome{
+				When:    "address-in-use",
+				Message: "Ports are in use: {{ .Ports }}",
+			},
+		},
+		{
+			Fail: &troubleshootv1beta2.SingleOutcome{
+				When:    "blocked",
+				Message: "Ports are blocked by a firewall: {{ .Ports }}",
+			},
+		},
+		{
+			Warn: &troubleshootv1beta2.SingleOutcome{
+				When:    "bind-permission-denied",
+				Message: "Run as root to check {{ .Ports }}",
+			},
+		},
+		{
+			Pass: &troubleshootv1beta2.SingleOutcome{
+				Message: "All ports are available",
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		ports  []collect.PortStatus
+		result *AnalyzeResult
+	}{
+		{
+			name: "in use",
+			ports: []collect.PortStatus{
+				{Port: 6443, Protocol: "tcp", Status: collect.PortStatusAvailable},
+				{Port: 10250, Protocol: "tcp", Status: collect.NetworkStatusAddressInUse, Process: "kubelet", PID: 1042},
+				{Port: 2379, Protocol: "tcp", Status: collect.NetworkStatusAddressInUse},
+				{Port: 2380, Protocol: "tcp", Status: collect.PortStatusBlocked},
+			},
+			result: &AnalyzeResult{
+				Title:   "Port Availability",
+				IsFail:  true,
+				Message: "Ports are in use: 10250/tcp (kubelet, pid 1042), 2379/tcp",
+			},
+		},
+		{
+			name: "blocked",
+			ports: []collect.PortStatus{
+				{Port: 6443, Protocol: "tcp", Status: collect.PortStatusBlocked},
+				{Port: 8472, Protocol: "udp", Status: collect.PortStatusAvailable},
+			},
+			result: &AnalyzeResult{
+				Title:   "Port Availability",
+				IsFail:  true,
+				Message: "Ports are blocked by a firewall: 6443/tcp",
+			},
+		},
+		{
+			name: "available",
+			ports: []collect.PortStatus{
+				{Port: 6443, Protocol: "tcp", Status: collect.PortStatusAvailable},
+				{Port: 8472, Protocol: "udp", Status: collect.PortStatusAvailable},
+			},
+			result: &AnalyzeResult{
+				Title:   "Port Availability",
+				IsPass:  true,
+				Message: "All ports are available",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := json.Marshal(collect.PortAvailabilityResult{Ports: test.ports})
+			require.NoError(t, err)
+
+			getCollectedFileContents := func(filename string) ([]byte, error) {
+				assert.Equal(t, "host-collectors/portAvailability/control-plane.json", filename)
+				return b, nil
+			}
+
+			a := &AnalyzeHostPortAvailability{&troubleshootv1beta2.PortAvailabilityAnalyze{CollectorName: "control-plane", Outcomes: outcomes}}
+			results, err := a.Analyze(getCollectedFileContents)
+			require.NoError(t, err)
+			assert.Equal(t, []*AnalyzeResult{test.result}, results)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+)
+
+type AnalyzeHostWindowsFeatures struct {
+	hostAnalyzer *troubleshootv1beta2.WindowsFeaturesAnalyze
+}
+
+func (a *AnalyzeHostWindowsFeatures) Title() string {
+	return hostAnalyzerTitleOrDefault(a.hostAnalyzer.AnalyzeMeta, "Windows Features")
+}
+
+func (a *AnalyzeHostWindowsFeatures) IsExcluded() (bool, error) {
+	return isExcluded(a.hostAnalyzer.Exclude)
+}
+
+// Analyze the windows features collection results.
+//
+// When an outcome is specified, the "when" condition must be empty (for default
+// conditions), or made up of 3 parts:
+//
+//   - comma-separated list of feature names, e,g, "Microsoft-Hyper-V,Containers"
+//   - comparison operator ("==", "=", "!=", "<>")
+//   - comma-separated state list ("unknown", "enabled", "disabled", "enablePending", "disablePending", "removed")
+//
+// Feature names are not case sensitive, the same as on Windows.
+//
+// Multiple outcomes can be provided.  Outcomes should not conflict.
+//
+// Default outcomes (with empty when clauses) can be provided for fail, warn and
+// pass.  When multiple defaults are provided, evaluation is processed in the
+// order that they were specified and the first to match is returned.
+//
+//   - a default fail will only trigger if there are no matching non-default pass outcomes.
+//   - a default warn will only trigger if there are no matching non-default pass or fail outcomes.
+//   - a default pass will only trigger if there are no matching non-default fail outcomes.
+func (a *AnalyzeHostWindowsFeatures) Analyze(getCollectedFileContents func(string) ([]byte, error)) ([]*AnalyzeResult, error) {
+	hostAnalyzer := a.hostAnalyzer
+	contents, err := getCollectedFileContents(collect.HostWindowsFeaturesPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get collected file")
+	}
+	collected := make(map[string]collect.WindowsFeatureInfo)
+	if err := json.Unmarshal(contents, &collected); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal windows features")
+	}
+	features := make(map[string]collect.WindowsFeatureInfo, len(collected))
+	for name, feature := range collected {
+		features[strings.ToLower(name)] = feature
+	}
+
+	var coll resultCollector
+	var passed, failed bool
+
+	for _, outcome := range hostAnalyzer.Outcomes {
+		result := &AnalyzeResult{Title: a.Title()}
+
+		if outcome.Fail != nil && outcome.Fail.When != "" {
+			isMatch, err := compareWindowsFeatureConditionalToActual(outcome.Fail.When, features)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to compare %s", outcome.Fail.When)
+			}
+
+			if isMatch {
+				result.IsFail = true
+				result.Message = outcome.Fail.Message
+				result.URI = outcome.Fail.URI
+
+				coll.push(result)
+				failed = true
+			}
+		} else if outcome.Warn != nil && outcome.Warn.When != "" {
+			isMatch, err := compareWindowsFeatureConditionalToActual(outcome.Warn.When, features)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to compare %s", outcome.Warn.When)
+			}
+
+			if isMatch {
+				result.IsWarn = true
+				result.Message = outcome.Warn.Message
+				result.URI = outcome.Warn.URI
+
+				coll.push(result)
+			}
+		} else if outcome.Pass != nil && outcome.Pass.When != "" {
+			isMatch, err := compareWindowsFeatureConditionalToActual(outcome.Pass.When, features)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to compare %s", outcome.Pass.When)
+			}
+
+			if isMatch {
+				result.IsPass = true
+				result.Message = outcome.Pass.Message
+				result.URI = outcome.Pass.URI
+
+				coll.push(result)
+				passed = true
+			}
+		}
+	}
+
+	for _, outcome := range hostAnalyzer.Outcomes {
+		result := &AnalyzeResult{Title: a.Title()}
+
+		if outcome.Fail != nil && outcome.Fail.When == "" && !passed {
+			result.IsFail = true
+			result.Message = outcome.Fail.Message
+			result.URI = outcome.Fail.URI
+
+			coll.push(result)
+			break
+		} else if outcome.Warn != nil && outcome.Warn.When == "" && !passed && !failed {
+			result.IsWarn = true
+			result.Message = outcome.Warn.Message
+			result.URI = outcome.Warn.URI
+
+			coll.push(result)
+			break
+		} else if outcome.Pass != nil && outcome.Pass.When == "" && !failed {
+			result.IsPass = true
+			result.Message = outcome.Pass.Message
+			result.URI = outcome.Pass.URI
+
+			coll.push(result)
+			break
+		}
+	}
+
+	return coll.get(a.Title()), nil
+}
+
+func compareWindowsFeatureConditionalToActual(conditional string, features map[string]collect.WindowsFeatureInfo) (res bool, err error) {
+	parts := strings.Split(conditional, " ")
+	if len(parts) != 3 {
+		return false, fmt.Errorf("Expected exactly 3 parts in conditional, got %d", len(parts))
+	}
+
+	matchFeatures := strings.Split(parts[0], ",")
+	matchStates := strings.Split(parts[2], ",")
+
+	switch parts[1] {
+	case "=", "==":
+		for _, name := range matchFeatures {
+			feature, ok := features[strings.ToLower(name)]
+			if !ok {
+				return false, nil
+			}
+			featureOK := false
+			// Only one state must be true.
+			for _, state := range matchStates {
+				if feature.State == collect.WindowsFeatureState(state) {
+					featureOK = true
+					break
+				}
+			}
+			if !featureOK {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "!=", "<>":
+		for _, name := range matchFeatures {
+			feature, ok := features[strings.ToLower(name)]
+			if !ok {
+				return true, nil
+			}
+
+			for _, state := range matchStates {
+				if feature.State == collect.WindowsFeatureState(state) {
+					return false, nil
+				}
+			}
+		}
+		return true, nil
+	}
+
+	return false, fmt.Errorf("unexpected operator %q", parts[1])
+}
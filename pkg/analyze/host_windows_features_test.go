@@ -0,0 +1,147 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeWindowsFeatures(t *testing.T) {
+	tests := []struct {
+		name         string
+		info         map[string]collect.WindowsFeatureInfo
+		hostAnalyzer *troubleshootv1beta2.WindowsFeaturesAnalyze
+		result       []*AnalyzeResult
+		expectErr    bool
+	}{
+		{
+			name: "hyper-v and containers are enabled",
+			info: map[string]collect.WindowsFeatureInfo{
+				"Microsoft-Hyper-V": {State: "enabled"},
+				"Containers":        {State: "enabled"},
+			},
+			hostAnalyzer: &troubleshootv1beta2.WindowsFeaturesAnalyze{
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{
+						Fail: &troubleshootv1beta2.SingleOutcome{
+							When:    "microsoft-hyper-v,containers != enabled",
+							Message: "Hyper-V and Containers must be enabled",
+						},
+					},
+					{
+						Pass: &troubleshootv1beta2.SingleOutcome{
+							Message: "Hyper-V and Containers are enabled",
+						},
+					},
+				},
+			},
+			result: []*AnalyzeResult{
+				{
+					Title:   "Windows Features",
+					IsPass:  true,
+					Message: "Hyper-V and Containers are enabled",
+				},
+			},
+		},
+		{
+			name: "containers is pending a restart",
+			info: map[string]collect.WindowsFeatureInfo{
+				"Microsoft-Hyper-V": {State: "enabled"},
+				"Containers":        {State: "enablePending"},
+			},
+			hostAnalyzer: &troubleshootv1beta2.WindowsFeaturesAnalyze{
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{
+						Warn: &troubleshootv1beta2.SingleOutcome{
+							When:    "Containers == enablePending",
+							Message: "Restart the host to enable Containers",
+						},
+					},
+					{
+						Fail: &troubleshootv1beta2.SingleOutcome{
+							When:    "Microsoft-Hyper-V,Containers != enabled,enablePending",
+							Message: "Hyper-V and Containers must be enabled",
+						},
+					},
+					{
+						Pass: &troubleshootv1beta2.SingleOutcome{
+							Message: "Hyper-V and Containers are enabled",
+						},
+					},
+				},
+			},
+			result: []*AnalyzeResult{
+				{
+					Title:   "Windows Features",
+					IsWarn:  true,
+					Message: "Restart the host to enable Containers",
+				},
+				{
+					Title:   "Windows Features",
+					IsPass:  true,
+					Message: "Hyper-V and Containers are enabled",
+				},
+			},
+		},
+		{
+			name: "feature is missing",
+			info: map[string]collect.WindowsFeatureInfo{},
+			hostAnalyzer: &troubleshootv1beta2.WindowsFeaturesAnalyze{
+				AnalyzeMeta: troubleshootv1beta2.AnalyzeMeta{CheckName: "Containers"},
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{
+						Fail: &troubleshootv1beta2.SingleOutcome{
+							When:    "Containers != enabled",
+							Message: "Containers must be enabled",
+						},
+					},
+				},
+			},
+			result: []*AnalyzeResult{
+				{
+					Title:   "Containers",
+					IsFail:  true,
+					Message: "Containers must be enabled",
+				},
+			},
+		},
+		{
+			name: "invalid conditional",
+			info: map[string]collect.WindowsFeatureInfo{},
+			hostAnalyzer: &troubleshootv1beta2.WindowsFeaturesAnalyze{
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{
+						Fail: &troubleshootv1beta2.SingleOutcome{
+							When:    "Containers is enabled",
+							Message: "Containers must be enabled",
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := require.New(t)
+			b, err := json.Marshal(test.info)
+			req.NoError(err)
+
+			getCollectedFileContents := func(filename string) ([]byte, error) {
+				req.Equal(collect.HostWindowsFeaturesPath, filename)
+				return b, nil
+			}
+
+			result, err := (&AnalyzeHostWindowsFeatures{test.hostAnalyzer}).Analyze(getCollectedFileContents)
+			if test.expectErr {
+				req.Error(err)
+				return
+			}
+			req.NoError(err)
+			req.Equal(test.result, result)
+		})
+	}
+}
@@ -0,0 +1,667 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// IngressRoutingReason is the link of a route that is broken, and is what the when of an ingressRouting
+// outcome matches
+type IngressRoutingReason string
+
+const (
+	// IngressRoutingNoIngress is a host that no ingress has a rule for
+	IngressRoutingNoIngress IngressRoutingReason = "NoIngress"
+	// IngressRoutingNoPath is a path that the rules for the host have no path for, and that they have no
+	// default backend for
+	IngressRoutingNoPath IngressRoutingReason = "NoPath"
+	// IngressRoutingServiceNotFound is a backend service that does not exist
+	IngressRoutingServiceNotFound IngressRoutingReason = "ServiceNotFound"
+	// IngressRoutingServicePortNotFound is a backend port that the service does not have
+	IngressRoutingServicePortNotFound IngressRoutingReason = "ServicePortNotFound"
+	// IngressRoutingNoEndpoints is a service that selects no pods, or has no endpoints
+	IngressRoutingNoEndpoints IngressRoutingReason = "NoEndpoints"
+	// IngressRoutingNoReadyEndpoints is a service whose pods are not ready
+	IngressRoutingNoReadyEndpoints IngressRoutingReason = "NoReadyEndpoints"
+	// IngressRoutingTargetPortNotFound is a named target port that no ready pod of the service has
+	IngressRoutingTargetPortNotFound IngressRoutingReason = "TargetPortNotFound"
+	// IngressRoutingNoAddress is an ingress that the ingress controller has not given an address, which
+	// it does when it has admitted the ingress. It is only reported if the rest of the route works.
+	IngressRoutingNoAddress IngressRoutingReason = "NoAddress"
+)
+
+// IngressRoute is the route of the requests for a host, and is what the messages of an ingressRouting
+// analyzer are templated with. The fields past the broken link are empty.
+type IngressRoute struct {
+	// Reason is the link that is broken, or empty if the route works
+	Reason IngressRoutingReason
+	Host   string
+	Path   string
+	// Namespace is the namespace of the ingress, and of its backend service
+	Namespace   string
+	Ingress     string
+	Service     string
+	ServicePort string
+	// Endpoints are how many endpoints the service has, and ReadyEndpoints how many of them are ready
+	Endpoints      int
+	ReadyEndpoints int
+	// Evidence is why the link is broken, or where the route ends
+	Evidence string
+}
+
+func analyzeIngressRouting(analyzer *troubleshootv1beta2.IngressRouting, getFile getCollectedFileContents, findFiles getChildCollectedFileContents) ([]*AnalyzeResult, error) {
+	title := analyzer.CheckName
+	if title == "" {
+		title = "Ingress Routing"
+	}
+
+	if len(analyzer.Hosts) == 0 {
+		return nil, errors.New("at least one host is required")
+	}
+	for _, outcome := range analyzer.Outcomes {
+		for _, single := range []*troubleshootv1beta2.SingleOutcome{outcome.Fail, outcome.Warn} {
+			if single != nil && single.When != "" && !isIngressRoutingReason(IngressRoutingReason(single.When)) {
+				return nil, errors.Errorf("unknown ingress routing reason %q", single.When)
+			}
+		}
+	}
+
+	ingresses, err := getCollectedIngresses(findFiles, analyzer.Namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	requestPath := analyzer.Path
+	if requestPath == "" {
+		requestPath = "/"
+	}
+
+	endpointsDir := "service-endpoints"
+	if analyzer.EndpointsCollectorName != "" {
+		endpointsDir = path.Join(endpointsDir, analyzer.EndpointsCollectorName)
+	}
+	resources := &collectedRoutingResources{
+		getFile:      getFile,
+		endpointsDir: endpointsDir,
+		services:     map[string][]corev1.Service{},
+		pods:         map[string][]corev1.Pod{},
+	}
+
+	results := []*AnalyzeResult{}
+	for _, host := range analyzer.Hosts {
+		route, err := followIngressRoute(ingresses, resources, host, requestPath)
+		if err != nil {
+			return nil, err
+		}
+		result, err := ingressRouteResult(analyzer, title, route)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func isIngressRoutingReason(reason IngressRoutingReason) bool {
+	switch reason {
+	case IngressRoutingNoIngress, IngressRoutingNoPath, IngressRoutingServiceNotFound, IngressRoutingServicePortNotFound,
+		IngressRoutingNoEndpoints, IngressRoutingNoReadyEndpoints, IngressRoutingTargetPortNotFound, IngressRoutingNoAddress:
+		return true
+	}
+	return false
+}
+
+func ingressRouteResult(analyzer *troubleshootv1beta2.IngressRouting, title string, route IngressRoute) (*AnalyzeResult, error) {
+	result := &AnalyzeResult{
+		Title:   fmt.Sprintf("%s: %s", title, route.Host),
+		IconKey: "kubernetes_ingress",
+		IconURI: "https://troubleshoot.sh/images/analyzer-icons/ingress-controller.svg?w=20&h=13",
+		Message: defaultIngressRouteMessage(route),
+	}
+	switch route.Reason {
+	case "":
+		result.IsPass = true
+	case IngressRoutingNoAddress:
+		result.IsWarn = true
+	default:
+		result.IsFail = true
+	}
+
+	// ordering from the spec is important, the first one that matches returns
+	for _, outcome := range analyzer.Outcomes {
+		var single *troubleshootv1beta2.SingleOutcome
+		if route.Reason == "" {
+			single = outcome.Pass
+		} else {
+			single = outcome.Fail
+			if single == nil {
+				single = outcome.Warn
+			}
+		}
+		if single == nil || (route.Reason != "" && single.When != "" && IngressRoutingReason(single.When) != route.Reason) {
+			continue
+		}
+
+		tmpl, err := template.New("ingressRouting").Parse(single.Message)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create new message template")
+		}
+		var m bytes.Buffer
+		if err := tmpl.Execute(&m, route); err != nil {
+			return nil, errors.Wrap(err, "failed to execute template")
+		}
+
+		if route.Reason != "" {
+			result.IsFail = outcome.Fail != nil
+			result.IsWarn = outcome.Fail == nil
+		}
+		result.Message = m.String()
+		result.URI = single.URI
+		break
+	}
+
+	return result, nil
+}
+
+func defaultIngressRouteMessage(route IngressRoute) string {
+	request := route.Host + route.Path
+	switch route.Reason {
+	case "":
+		return fmt.Sprintf("%s is routed by ingress %s/%s: %s", request, route.Namespace, route.Ingress, route.Evidence)
+	case IngressRoutingNoIngress:
+		return fmt.Sprintf("No ingress has a rule for %s: %s", route.Host, route.Evidence)
+	case IngressRoutingNoPath:
+		return fmt.Sprintf("No ingress has a path for %s: %s", request, route.Evidence)
+	case IngressRoutingServiceNotFound:
+		return fmt.Sprintf("Service %s/%s of ingress %s does not exist: %s", route.Namespace, route.Service, route.Ingress, route.Evidence)
+	case IngressRoutingServicePortNotFound:
+		return fmt.Sprintf("Service %s/%s of ingress %s has no port %s: %s", route.Namespace, route.Service, route.Ingress, route.ServicePort, route.Evidence)
+	case IngressRoutingNoEndpoints:
+		return fmt.Sprintf("Service %s/%s of ingress %s has no endpoints: %s", route.Namespace, route.Service, route.Ingress, route.Evidence)
+	case IngressRoutingNoReadyEndpoints:
+		return fmt.Sprintf("None of the %d endpoints of service %s/%s of ingress %s are ready: %s", route.Endpoints, route.Namespace, route.Service, route.Ingress, route.Evidence)
+	case IngressRoutingTargetPortNotFound:
+		return fmt.Sprintf("No ready pod of service %s/%s serves port %s: %s", route.Namespace, route.Service, route.ServicePort, route.Evidence)
+	default:
+		return fmt.Sprintf("Ingress %s/%s has no address: %s", route.Namespace, route.Ingress, route.Evidence)
+	}
+}
+
+// collectedRoutingResources reads the services, pods and endpoints of namespaces as routes need them
+type collectedRoutingResources struct {
+	getFile      getCollectedFileContents
+	endpointsDir string
+	services     map[string][]corev1.Service
+	pods         map[string][]corev1.Pod
+}
+
+// getServices returns the services of a namespace, or nil if they were not collected
+func (r *collectedRoutingResources) getServices(namespace string) []corev1.Service {
+	if services, ok := r.services[namespace]; ok {
+		return services
+	}
+	var services corev1.ServiceList
+	if err := unmarshalCollectedFile(r.getFile, filepath.Join("cluster-resources", "services", namespace+".json"), &services); err != nil {
+		r.services[namespace] = nil
+		return nil
+	}
+	r.services[namespace] = services.Items
+	return services.Items
+}
+
+func (r *collectedRoutingResources) getPods(namespace string) []corev1.Pod {
+	if pods, ok := r.pods[namespace]; ok {
+		return pods
+	}
+	var pods corev1.PodList
+	if err := unmarshalCollectedFile(r.getFile, filepath.Join("cluster-resources", "pods", namespace+".json"), &pods); err != nil {
+		r.pods[namespace] = nil
+		return nil
+	}
+	r.pods[namespace] = pods.Items
+	return pods.Items
+}
+
+// getEndpoints returns the endpoints of a service that a serviceEndpoints collector collected, or nil if it
+// did not collect the service
+func (r *collectedRoutingResources) getEndpoints(namespace string, name string) *collect.ServiceEndpointsInfo {
+	var info collect.ServiceEndpointsInfo
+	if err := unmarshalCollectedFile(r.getFile, path.Join(r.endpointsDir, namespace, name+".json"), &info); err != nil {
+		return nil
+	}
+	return &info
+}
+
+// followIngressRoute follows the route of requests for host and requestPath, and returns it with the first
+// link that is broken
+func followIngressRoute(ingresses []networkingv1.Ingress, resources *collectedRoutingResources, host string, requestPath string) (IngressRoute, error) {
+	route := IngressRoute{Host: host, Path: requestPath}
+
+	ingress, backend, reason, evidence := matchIngressBackend(ingresses, host, requestPath)
+	if reason != "" {
+		route.Reason = reason
+		route.Evidence = evidence
+		return route, nil
+	}
+	route.Namespace = ingress.Namespace
+	route.Ingress = ingress.Name
+
+	if backend.Service == nil {
+		route.Evidence = "the backend is not a service"
+		if backend.Resource != nil {
+			route.Evidence = fmt.Sprintf("the backend is %s %s", backend.Resource.Kind, backend.Resource.Name)
+		}
+		return checkIngressAddress(ingress, route), nil
+	}
+
+	route.Service = backend.Service.Name
+	route.ServicePort = backend.Service.Port.Name
+	if route.ServicePort == "" {
+		route.ServicePort = fmt.Sprintf("%d", backend.Service.Port.Number)
+	}
+
+	services := resources.getServices(ingress.Namespace)
+	if services == nil {
+		route.Reason = IngressRoutingServiceNotFound
+		route.Evidence = fmt.Sprintf("the services of namespace %s were not collected", ingress.Namespace)
+		return route, nil
+	}
+	var service *corev1.Service
+	for i := range services {
+		if services[i].Name == backend.Service.Name {
+			service = &services[i]
+			break
+		}
+	}
+	if service == nil {
+		route.Reason = IngressRoutingServiceNotFound
+		route.Evidence = fmt.Sprintf("namespace %s has no service named %s", ingress.Namespace, backend.Service.Name)
+		return route, nil
+	}
+
+	var servicePort *corev1.ServicePort
+	for i, port := range service.Spec.Ports {
+		if (backend.Service.Port.Name != "" && port.Name == backend.Service.Port.Name) ||
+			(backend.Service.Port.Name == "" && port.Port == backend.Service.Port.Number) {
+			servicePort = &service.Spec.Ports[i]
+			break
+		}
+	}
+	if servicePort == nil && service.Spec.Type != corev1.ServiceTypeExternalName {
+		ports := []string{}
+		for _, port := range service.Spec.Ports {
+			ports = append(ports, servicePortString(port))
+		}
+		route.Reason = IngressRoutingServicePortNotFound
+		route.Evidence = fmt.Sprintf("the service's ports are %s", strings.Join(ports, ", "))
+		if len(ports) == 0 {
+			route.Evidence = "the service has no ports"
+		}
+		return route, nil
+	}
+
+	if service.Spec.Type == corev1.ServiceTypeExternalName {
+		route.Evidence = fmt.Sprintf("service %s is an ExternalName service for %s", service.Name, service.Spec.ExternalName)
+		return checkIngressAddress(ingress, route), nil
+	}
+
+	if info := resources.getEndpoints(service.Namespace, service.Name); info != nil {
+		for _, endpoint := range info.Endpoints {
+			route.Endpoints++
+			if endpoint.Ready {
+				route.ReadyEndpoints++
+			}
+		}
+		switch {
+		case route.Endpoints == 0:
+			route.Reason = IngressRoutingNoEndpoints
+			route.Evidence = "the collected endpoints of the service have no addresses"
+			if len(info.Issues) > 0 {
+				route.Evidence = strings.Join(info.Issues, ", ")
+			}
+		case route.ReadyEndpoints == 0:
+			route.Reason = IngressRoutingNoReadyEndpoints
+			route.Evidence = "the collected endpoints of the service are not ready"
+			if len(info.Issues) > 0 {
+				route.Evidence = strings.Join(info.Issues, ", ")
+			}
+		default:
+			route.Evidence = fmt.Sprintf("service %s port %s has %d ready endpoints", service.Name, route.ServicePort, route.ReadyEndpoints)
+		}
+		if route.Reason != "" {
+			return route, nil
+		}
+		return checkIngressAddress(ingress, route), nil
+	}
+
+	if len(service.Spec.Selector) == 0 {
+		route.Evidence = fmt.Sprintf("service %s has no selector, its endpoints are managed outside of kubernetes and were not collected", service.Name)
+		return checkIngressAddress(ingress, route), nil
+	}
+
+	selector := labels.SelectorFromSet(service.Spec.Selector)
+	readyPods := []corev1.Pod{}
+	notReady := []string{}
+	for _, pod := range resources.getPods(service.Namespace) {
+		if !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		route.Endpoints++
+		if isPodReady(pod) {
+			route.ReadyEndpoints++
+			readyPods = append(readyPods, pod)
+		} else {
+			notReady = append(notReady, fmt.Sprintf("%s is %s", pod.Name, podNotReadyReason(pod)))
+		}
+	}
+	if route.Endpoints == 0 {
+		route.Reason = IngressRoutingNoEndpoints
+		route.Evidence = fmt.Sprintf("no pod matches the selector %s", selector.String())
+		return route, nil
+	}
+	if route.ReadyEndpoints == 0 {
+		route.Reason = IngressRoutingNoReadyEndpoints
+		sort.Strings(notReady)
+		route.Evidence = strings.Join(notReady, ", ")
+		return route, nil
+	}
+
+	if targetPort := servicePort.TargetPort.StrVal; targetPort != "" {
+		if !podsHaveContainerPort(readyPods, targetPort) {
+			route.Reason = IngressRoutingTargetPortNotFound
+			route.Evidence = fmt.Sprintf("the target port is %s, and none of the %d ready pods has a container port named %s", targetPort, route.ReadyEndpoints, targetPort)
+			return route, nil
+		}
+	}
+
+	route.Evidence = fmt.Sprintf("service %s port %s has %d ready pods", service.Name, route.ServicePort, route.ReadyEndpoints)
+	return checkIngressAddress(ingress, route), nil
+}
+
+// checkIngressAddress returns the route with NoAddress if the ingress controller has not given the
+// ingress an address
+func checkIngressAddress(ingress networkingv1.Ingress, route IngressRoute) IngressRoute {
+	if len(ingress.Status.LoadBalancer.Ingress) > 0 {
+		return route
+	}
+	route.Reason = IngressRoutingNoAddress
+	route.Evidence = "the ingress controller has not given the ingress an address, it may not have admitted it"
+	if ingress.Spec.IngressClassName != nil {
+		route.Evidence = fmt.Sprintf("the controller of ingress class %s has not given the ingress an address, it may not have admitted it", *ingress.Spec.IngressClassName)
+	}
+	return route
+}
+
+// matchIngressBackend returns the backend of the ingress that requests for host and requestPath are routed
+// to. Rules for the host take precedence over rules for a wildcard host that matches it, which take
+// precedence over rules for all hosts. Of the paths of those rules, an Exact path takes precedence over
+// the longest matching prefix. If none match, the default backend of an ingress with one of the rules is
+// used.
+func matchIngressBackend(ingresses []networkingv1.Ingress, host string, requestPath string) (networkingv1.Ingress, networkingv1.IngressBackend, IngressRoutingReason, string) {
+	type ruleMatch struct {
+		ingress networkingv1.Ingress
+		rule    networkingv1.IngressRule
+	}
+
+	bestHostMatch := 0
+	rules := []ruleMatch{}
+	for _, ingress := range ingresses {
+		for _, rule := range ingress.Spec.Rules {
+			hostMatch := ingressHostMatch(rule.Host, host)
+			if hostMatch == 0 || hostMatch < bestHostMatch {
+				continue
+			}
+			if hostMatch > bestHostMatch {
+				bestHostMatch = hostMatch
+				rules = []ruleMatch{}
+			}
+			rules = append(rules, ruleMatch{ingress: ingress, rule: rule})
+		}
+	}
+	if len(rules) == 0 {
+		evidence := fmt.Sprintf("none of the %d ingresses has a rule for the host", len(ingresses))
+		if len(ingresses) == 0 {
+			evidence = "there are no ingresses"
+		}
+		return networkingv1.Ingress{}, networkingv1.IngressBackend{}, IngressRoutingNoIngress, evidence
+	}
+
+	var best *ruleMatch
+	var bestPath networkingv1.HTTPIngressPath
+	for i, match := range rules {
+		if match.rule.HTTP == nil {
+			continue
+		}
+		for _, p := range match.rule.HTTP.Paths {
+			if !ingressPathMatches(p, requestPath) {
+				continue
+			}
+			if best == nil || ingressPathPrecedes(p, bestPath) {
+				best = &rules[i]
+				bestPath = p
+			}
+		}
+	}
+	if best != nil {
+		return best.ingress, bestPath.Backend, "", ""
+	}
+
+	names := []string{}
+	for _, match := range rules {
+		if match.ingress.Spec.DefaultBackend != nil {
+			return match.ingress, *match.ingress.Spec.DefaultBackend, "", ""
+		}
+		names = append(names, match.ingress.Namespace+"/"+match.ingress.Name)
+	}
+	return networkingv1.Ingress{}, networkingv1.IngressBackend{}, IngressRoutingNoPath,
+		fmt.Sprintf("the rules of %s for the host have no path that matches, and no default backend", strings.Join(uniqueStrings(names), ", "))
+}
+
+// ingressHostMatch returns 3 if ruleHost is host, 2 if it is a wildcard host that matches it, 1 if it is
+// empty, which matches all hosts, and 0 if it does not match
+func ingressHostMatch(ruleHost string, host string) int {
+	switch {
+	case ruleHost == "":
+		return 1
+	case strings.EqualFold(ruleHost, host):
+		return 3
+	case strings.HasPrefix(ruleHost, "*."):
+		// a wildcard matches a single label
+		suffix := ruleHost[1:]
+		if strings.HasSuffix(strings.ToLower(host), strings.ToLower(suffix)) {
+			label := host[:len(host)-len(suffix)]
+			if label != "" && !strings.Contains(label, ".") {
+				return 2
+			}
+		}
+	}
+	return 0
+}
+
+func ingressPathMatches(p networkingv1.HTTPIngressPath, requestPath string) bool {
+	rulePath := p.Path
+	if rulePath == "" {
+		rulePath = "/"
+	}
+	if p.PathType != nil && *p.PathType == networkingv1.PathTypeExact {
+		return rulePath == requestPath
+	}
+	if p.PathType != nil && *p.PathType == networkingv1.PathTypePrefix {
+		// prefixes match by path element, so /foo matches /foo/bar but not /foobar
+		prefix := strings.TrimSuffix(rulePath, "/")
+		return prefix == "" || requestPath == prefix || strings.HasPrefix(requestPath, prefix+"/")
+	}
+	// ImplementationSpecific paths are matched as prefixes, which is what most controllers do
+	return strings.HasPrefix(requestPath, rulePath)
+}
+
+// ingressPathPrecedes returns true if p takes precedence over other, when both match a path
+func ingressPathPrecedes(p networkingv1.HTTPIngressPath, other networkingv1.HTTPIngressPath) bool {
+	isExact := p.PathType != nil && *p.PathType == networkingv1.PathTypeExact
+	otherIsExact := other.PathType != nil && *other.PathType == networkingv1.PathTypeExact
+	if isExact != otherIsExact {
+		return isExact
+	}
+	return len(p.Path) > len(other.Path)
+}
+
+func podsHaveContainerPort(pods []corev1.Pod, name string) bool {
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			for _, port := range container.Ports {
+				if port.Name == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// podNotReadyReason returns why a pod is not ready, for the evidence of a route
+func podNotReadyReason(pod corev1.Pod) string {
+	if pod.DeletionTimestamp != nil {
+		return "terminating"
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason != "" {
+			return status.State.Waiting.Reason
+		}
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return string(pod.Status.Phase)
+	}
+	return "not ready"
+}
+
+func servicePortString(port corev1.ServicePort) string {
+	if port.Name != "" {
+		return fmt.Sprintf("%s (%d)", port.Name, port.Port)
+	}
+	return fmt.Sprintf("%d", port.Port)
+}
+
+func uniqueStrings(values []string) []string {
+	unique := []string{}
+	seen := map[string]bool{}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+// getCollectedIngresses returns the ingresses of namespaces, all namespaces if none are given. Ingresses
+// collected from clusters without networking.k8s.io/v1 are converted from extensions/v1beta1.
+func getCollectedIngresses(findFiles getChildCollectedFileContents, namespaces []string) ([]networkingv1.Ingress, error) {
+	files, err := findFiles(filepath.Join("cluster-resources", "ingress", "*.json"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read collected ingresses")
+	}
+
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ingresses := []networkingv1.Ingress{}
+	for _, name := range names {
+		if !includeCollectedNamespace(name, namespaces) {
+			continue
+		}
+
+		var list struct {
+			APIVersion string `json:"apiVersion"`
+		}
+		if err := json.Unmarshal(files[name], &list); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal ingresses from %s", name)
+		}
+
+		if strings.HasPrefix(list.APIVersion, "extensions/") || list.APIVersion == "networking.k8s.io/v1beta1" {
+			var v1beta1List extensionsv1beta1.IngressList
+			if err := json.Unmarshal(files[name], &v1beta1List); err != nil {
+				return nil, errors.Wrapf(err, "failed to unmarshal ingresses from %s", name)
+			}
+			for _, ingress := range v1beta1List.Items {
+				ingresses = append(ingresses, convertV1beta1Ingress(ingress))
+			}
+			continue
+		}
+
+		var ingressList networkingv1.IngressList
+		if err := json.Unmarshal(files[name], &ingressList); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal ingresses from %s", name)
+		}
+		ingresses = append(ingresses, ingressList.Items...)
+	}
+
+	return ingresses, nil
+}
+
+func convertV1beta1Ingress(in extensionsv1beta1.Ingress) networkingv1.Ingress {
+	out := networkingv1.Ingress{
+		ObjectMeta: in.ObjectMeta,
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: in.Spec.IngressClassName,
+		},
+		Status: networkingv1.IngressStatus{
+			LoadBalancer: in.Status.LoadBalancer,
+		},
+	}
+	if in.Spec.Backend != nil {
+		backend := convertV1beta1IngressBackend(*in.Spec.Backend)
+		out.Spec.DefaultBackend = &backend
+	}
+	for _, rule := range in.Spec.Rules {
+		outRule := networkingv1.IngressRule{Host: rule.Host}
+		if rule.HTTP != nil {
+			outRule.HTTP = &networkingv1.HTTPIngressRuleValue{}
+			for _, p := range rule.HTTP.Paths {
+				outPath := networkingv1.HTTPIngressPath{
+					Path:    p.Path,
+					Backend: convertV1beta1IngressBackend(p.Backend),
+				}
+				if p.PathType != nil {
+					pathType := networkingv1.PathType(*p.PathType)
+					outPath.PathType = &pathType
+				}
+				outRule.HTTP.Paths = append(outRule.HTTP.Paths, outPath)
+			}
+		}
+		out.Spec.Rules = append(out.Spec.Rules, outRule)
+	}
+	return out
+}
+
+func convertV1beta1IngressBackend(in extensionsv1beta1.IngressBackend) networkingv1.IngressBackend {
+	if in.Resource != nil {
+		return networkingv1.IngressBackend{Resource: in.Resource}
+	}
+	backend := networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{Name: in.ServiceName},
+	}
+	if in.ServicePort.StrVal != "" {
+		backend.Service.Port.Name = in.ServicePort.StrVal
+	} else {
+		backend.Service.Port.Number = in.ServicePort.IntVal
+	}
+	return backend
+}
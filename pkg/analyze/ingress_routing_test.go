@@ -0,0 +1,382 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func Test_analyzeIngressRouting(t *testing.T) {
+	exact := networkingv1.PathTypeExact
+	prefix := networkingv1.PathTypePrefix
+	loadBalancer := corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}}
+	className := "nginx"
+
+	files := map[string][]byte{}
+	for name, obj := range map[string]interface{}{
+		"cluster-resources/ingress/default.json": networkingv1.IngressList{
+			TypeMeta: metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "IngressList"},
+			Items: []networkingv1.Ingress{
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+					Spec: networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{
+						{
+							Host: "app.example.com",
+							IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/",
+									PathType: &prefix,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{Name: "web", Port: networkingv1.ServiceBackendPort{Number: 80}},
+									},
+								},
+								{
+									Path:     "/api",
+									PathType: &prefix,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{Name: "api", Port: networkingv1.ServiceBackendPort{Name: "http"}},
+									},
+								},
+								{
+									Path:     "/api/health",
+									PathType: &exact,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{Name: "missing", Port: networkingv1.ServiceBackendPort{Number: 80}},
+									},
+								},
+								{
+									Path:     "/admin",
+									PathType: &prefix,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{Name: "web", Port: networkingv1.ServiceBackendPort{Number: 8443}},
+									},
+								},
+								{
+									Path:     "/metrics",
+									PathType: &prefix,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{Name: "metrics", Port: networkingv1.ServiceBackendPort{Number: 9090}},
+									},
+								},
+							}}},
+						},
+						{
+							Host: "*.docs.example.com",
+							IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/",
+									PathType: &prefix,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{Name: "docs", Port: networkingv1.ServiceBackendPort{Number: 80}},
+									},
+								},
+							}}},
+						},
+						{
+							Host: "static.example.com",
+							IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/assets",
+									PathType: &prefix,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{Name: "web", Port: networkingv1.ServiceBackendPort{Number: 80}},
+									},
+								},
+							}}},
+						},
+					}},
+					Status: networkingv1.IngressStatus{LoadBalancer: loadBalancer},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pending"},
+					Spec: networkingv1.IngressSpec{
+						IngressClassName: &className,
+						Rules: []networkingv1.IngressRule{
+							{
+								Host: "new.example.com",
+								IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{
+									{
+										Path:     "/",
+										PathType: &prefix,
+										Backend: networkingv1.IngressBackend{
+											Service: &networkingv1.IngressServiceBackend{Name: "web", Port: networkingv1.ServiceBackendPort{Number: 80}},
+										},
+									},
+								}}},
+							},
+						},
+					},
+				},
+			},
+		},
+		"cluster-resources/ingress/legacy.json": extensionsv1beta1.IngressList{
+			TypeMeta: metav1.TypeMeta{APIVersion: "extensions/v1beta1", Kind: "IngressList"},
+			Items: []extensionsv1beta1.Ingress{
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "legacy", Name: "old"},
+					Spec: extensionsv1beta1.IngressSpec{Rules: []extensionsv1beta1.IngressRule{
+						{
+							Host: "old.example.com",
+							IngressRuleValue: extensionsv1beta1.IngressRuleValue{HTTP: &extensionsv1beta1.HTTPIngressRuleValue{Paths: []extensionsv1beta1.HTTPIngressPath{
+								{Path: "/", Backend: extensionsv1beta1.IngressBackend{ServiceName: "old", ServicePort: intstr.FromInt(80)}},
+							}}},
+						},
+					}},
+					Status: extensionsv1beta1.IngressStatus{LoadBalancer: loadBalancer},
+				},
+			},
+		},
+		"cluster-resources/services/default.json": corev1.ServiceList{Items: []corev1.Service{
+			{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+				Spec: corev1.ServiceSpec{
+					Selector: map[string]string{"app": "web"},
+					Ports:    []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromString("http")}},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api"},
+				Spec: corev1.ServiceSpec{
+					Selector: map[string]string{"app": "api"},
+					Ports:    []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromInt(8080)}},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "metrics"},
+				Spec: corev1.ServiceSpec{
+					Selector: map[string]string{"app": "web"},
+					Ports:    []corev1.ServicePort{{Port: 9090, TargetPort: intstr.FromString("metrics")}},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "docs"},
+				Spec: corev1.ServiceSpec{
+					Selector: map[string]string{"app": "docs"},
+					Ports:    []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(8080)}},
+				},
+			},
+		}},
+		"cluster-resources/services/legacy.json": corev1.ServiceList{Items: []corev1.Service{
+			{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "legacy", Name: "old"},
+				Spec: corev1.ServiceSpec{
+					Selector: map[string]string{"app": "old"},
+					Ports:    []corev1.ServicePort{{Port: 80}},
+				},
+			},
+		}},
+		"cluster-resources/pods/default.json": corev1.PodList{Items: []corev1.Pod{
+			{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-1", Labels: map[string]string{"app": "web"}},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{
+					{Name: "web", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}}},
+				}},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api-1", Labels: map[string]string{"app": "api"}},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{
+					{Name: "api", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}}},
+				}},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}},
+					ContainerStatuses: []corev1.ContainerStatus{
+						{Name: "api", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+					},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api-2", Labels: map[string]string{"app": "api"}},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{
+					{Name: "api", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}}},
+				}},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}},
+					ContainerStatuses: []corev1.ContainerStatus{
+						{Name: "api", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+					},
+				},
+			},
+		}},
+		"cluster-resources/pods/legacy.json": corev1.PodList{},
+		"service-endpoints/default/docs.json": collect.ServiceEndpointsInfo{
+			Namespace: "default",
+			Name:      "docs",
+			Endpoints: []collect.ServiceEndpoint{{IP: "10.1.0.5", Ready: true, Pod: "docs-1"}},
+		},
+	} {
+		b, err := json.Marshal(obj)
+		require.NoError(t, err)
+		files[name] = b
+	}
+	getFile := func(n string) ([]byte, error) {
+		b, ok := files[n]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return b, nil
+	}
+	findFiles := func(n string) (map[string][]byte, error) {
+		matching := map[string][]byte{}
+		for name, file := range files {
+			if ok, _ := filepath.Match(n, name); ok {
+				matching[name] = file
+			}
+		}
+		return matching, nil
+	}
+
+	result := func(host string, isPass, isWarn, isFail bool, message string) *AnalyzeResult {
+		return &AnalyzeResult{
+			Title:   "Ingress Routing: " + host,
+			IconKey: "kubernetes_ingress",
+			IconURI: "https://troubleshoot.sh/images/analyzer-icons/ingress-controller.svg?w=20&h=13",
+			IsPass:  isPass,
+			IsWarn:  isWarn,
+			IsFail:  isFail,
+			Message: message,
+		}
+	}
+
+	tests := []struct {
+		name         string
+		analyzer     troubleshootv1beta2.IngressRouting
+		expectResult []*AnalyzeResult
+		expectErr    string
+	}{
+		{
+			name: "hosts",
+			analyzer: troubleshootv1beta2.IngressRouting{
+				Hosts: []string{"app.example.com", "v2.docs.example.com", "a.v2.docs.example.com", "static.example.com", "new.example.com", "old.example.com"},
+			},
+			expectResult: []*AnalyzeResult{
+				result("app.example.com", true, false, false, "app.example.com/ is routed by ingress default/web: service web port 80 has 1 ready pods"),
+				result("v2.docs.example.com", true, false, false, "v2.docs.example.com/ is routed by ingress default/web: service docs port 80 has 1 ready endpoints"),
+				result("a.v2.docs.example.com", false, false, true, "No ingress has a rule for a.v2.docs.example.com: none of the 3 ingresses has a rule for the host"),
+				result("static.example.com", false, false, true, "No ingress has a path for static.example.com/: the rules of default/web for the host have no path that matches, and no default backend"),
+				result("new.example.com", false, true, false, "Ingress default/pending has no address: the controller of ingress class nginx has not given the ingress an address, it may not have admitted it"),
+				result("old.example.com", false, false, true, "Service legacy/old of ingress old has no endpoints: no pod matches the selector app=old"),
+			},
+		},
+		{
+			name:     "no ready pods",
+			analyzer: troubleshootv1beta2.IngressRouting{Hosts: []string{"app.example.com"}, Path: "/api/users"},
+			expectResult: []*AnalyzeResult{
+				result("app.example.com", false, false, true, "None of the 2 endpoints of service default/api of ingress web are ready: api-1 is CrashLoopBackOff, api-2 is CrashLoopBackOff"),
+			},
+		},
+		{
+			name:     "exact path to a missing service",
+			analyzer: troubleshootv1beta2.IngressRouting{Hosts: []string{"app.example.com"}, Path: "/api/health"},
+			expectResult: []*AnalyzeResult{
+				result("app.example.com", false, false, true, "Service default/missing of ingress web does not exist: namespace default has no service named missing"),
+			},
+		},
+		{
+			name:     "missing service port",
+			analyzer: troubleshootv1beta2.IngressRouting{Hosts: []string{"app.example.com"}, Path: "/admin"},
+			expectResult: []*AnalyzeResult{
+				result("app.example.com", false, false, true, "Service default/web of ingress web has no port 8443: the service's ports are http (80)"),
+			},
+		},
+		{
+			name:     "missing target port",
+			analyzer: troubleshootv1beta2.IngressRouting{Hosts: []string{"app.example.com"}, Path: "/metrics"},
+			expectResult: []*AnalyzeResult{
+				result("app.example.com", false, false, true, "No ready pod of service default/metrics serves port 9090: the target port is metrics, and none of the 1 ready pods has a container port named metrics"),
+			},
+		},
+		{
+			name: "outcomes",
+			analyzer: troubleshootv1beta2.IngressRouting{
+				AnalyzeMeta: troubleshootv1beta2.AnalyzeMeta{CheckName: "Routes"},
+				Hosts:       []string{"app.example.com", "new.example.com"},
+				Path:        "/api",
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{
+						Warn: &troubleshootv1beta2.SingleOutcome{
+							When:    "NoReadyEndpoints",
+							Message: "{{ .Service }} has {{ .ReadyEndpoints }} of {{ .Endpoints }} ready",
+							URI:     "https://example.com/api",
+						},
+					},
+					{
+						Fail: &troubleshootv1beta2.SingleOutcome{Message: "{{ .Host }}{{ .Path }}: {{ .Reason }}"},
+					},
+					{
+						Pass: &troubleshootv1beta2.SingleOutcome{Message: "{{ .Host }} is routed"},
+					},
+				},
+			},
+			expectResult: []*AnalyzeResult{
+				{
+					Title:   "Routes: app.example.com",
+					IconKey: "kubernetes_ingress",
+					IconURI: "https://troubleshoot.sh/images/analyzer-icons/ingress-controller.svg?w=20&h=13",
+					IsWarn:  true,
+					Message: "api has 0 of 2 ready",
+					URI:     "https://example.com/api",
+				},
+				{
+					Title:   "Routes: new.example.com",
+					IconKey: "kubernetes_ingress",
+					IconURI: "https://troubleshoot.sh/images/analyzer-icons/ingress-controller.svg?w=20&h=13",
+					IsFail:  true,
+					Message: "new.example.com/api: NoAddress",
+				},
+			},
+		},
+		{
+			name: "namespaces",
+			analyzer: troubleshootv1beta2.IngressRouting{
+				Hosts:      []string{"app.example.com"},
+				Namespaces: []string{"legacy"},
+			},
+			expectResult: []*AnalyzeResult{
+				result("app.example.com", false, false, true, "No ingress has a rule for app.example.com: none of the 1 ingresses has a rule for the host"),
+			},
+		},
+		{
+			name:      "no hosts",
+			analyzer:  troubleshootv1beta2.IngressRouting{},
+			expectErr: "at least one host is required",
+		},
+		{
+			name: "unknown reason",
+			analyzer: troubleshootv1beta2.IngressRouting{
+				Hosts: []string{"app.example.com"},
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{Fail: &troubleshootv1beta2.SingleOutcome{When: "Timeout"}},
+				},
+			},
+			expectErr: `unknown ingress routing reason "Timeout"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := analyzeIngressRouting(&tt.analyzer, getFile, findFiles)
+			if tt.expectErr != "" {
+				assert.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectResult, results)
+		})
+	}
+}
@@ -561,3 +561,71 @@ func Test_jsonCompare(t *testing.T) {
 		})
 	}
 }
+
+func Test_jsonCompareOperators(t *testing.T) {
+	fileContents := []byte(`{
+		"replicas": 3,
+		"version": "1.24.6+k3s1",
+		"labels": {"app": "api"},
+		"items": [
+			{"metadata": {"name": "a"}, "status": {"phase": "Running"}},
+			{"metadata": {"name": "b"}, "status": {"phase": "Pending"}}
+		]
+	}`)
+
+	tests := []struct {
+		name     string
+		path     string
+		jsonPath string
+		when     string
+		isPass   bool
+		isError  bool
+	}{
+		{name: "equal number", jsonPath: ".replicas", when: "== 3", isPass: true},
+		{name: "not equal number", jsonPath: ".replicas", when: "!= 3", isPass: false},
+		{name: "greater than", jsonPath: "{.replicas}", when: "> 2", isPass: true},
+		{name: "less than or equal", path: "replicas", when: "<= 2", isPass: false},
+		{name: "string contains", jsonPath: ".version", when: "contains k3s", isPass: true},
+		{name: "list contains", jsonPath: ".items[*].metadata.name", when: `contains "b"`, isPass: true},
+		{name: "list does not contain", jsonPath: ".items[*].metadata.name", when: "contains c", isPass: false},
+		{name: "map contains key", jsonPath: ".labels", when: "contains app", isPass: true},
+		{name: "filter", jsonPath: `.items[?(@.status.phase=="Pending")].metadata.name`, when: "== b", isPass: true},
+		{name: "exists", jsonPath: ".labels.app", when: "exists", isPass: true},
+		{name: "missing key does not exist", jsonPath: ".labels.tier", when: "!exists", isPass: true},
+		{name: "missing key does not equal", jsonPath: ".labels.tier", when: "== api", isPass: false},
+		{name: "missing path does not exist", path: "labels.tier", when: "exists", isPass: false},
+		{name: "not a number", jsonPath: ".version", when: "> 1", isError: true},
+		{name: "unknown comparator", jsonPath: ".replicas", when: "~= 3", isError: true},
+		{name: "invalid jsonPath", jsonPath: ".items[", when: "exists", isError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := require.New(t)
+
+			analyzer := &troubleshootv1beta2.JsonCompare{
+				CollectorName: "json-compare",
+				FileName:      "json-compare.json",
+				Path:          test.path,
+				JsonPath:      test.jsonPath,
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{Pass: &troubleshootv1beta2.SingleOutcome{When: test.when, Message: "pass"}},
+					{Fail: &troubleshootv1beta2.SingleOutcome{Message: "fail"}},
+				},
+			}
+
+			getCollectedFileContents := func(n string) ([]byte, error) {
+				return fileContents, nil
+			}
+
+			actual, err := analyzeJsonCompare(analyzer, getCollectedFileContents)
+			if test.isError {
+				req.Error(err)
+				return
+			}
+			req.NoError(err)
+			req.Equal(test.isPass, actual.IsPass)
+			req.Equal(!test.isPass, actual.IsFail)
+		})
+	}
+}
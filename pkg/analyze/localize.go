@@ -0,0 +1,142 @@
+package analyzer
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"gopkg.in/yaml.v2"
+)
+
+// MessageCatalog has translations of the messages of analyzer outcomes. Messages are translated by the
+// message in the spec, which is usually English, and then by language, such as
+//
+//	messages:
+//	  "At least 3 nodes are required":
+//	    de: "Mindestens 3 Knoten sind erforderlich"
+//	    ja: "少なくとも 3 つのノードが必要です"
+//
+// Translations are templates like the messages they translate, so they can use the same values.
+type MessageCatalog struct {
+	Messages map[string]map[string]string `json:"messages" yaml:"messages"`
+}
+
+// LoadMessageCatalogs reads and merges the catalog files at paths. Translations of later catalogs
+// replace those of earlier ones.
+func LoadMessageCatalogs(paths []string) (*MessageCatalog, error) {
+	catalog := &MessageCatalog{Messages: map[string]map[string]string{}}
+	for _, path := range paths {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read message catalog %s", path)
+		}
+		loaded := MessageCatalog{}
+		if err := yaml.Unmarshal(b, &loaded); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse message catalog %s", path)
+		}
+		for message, translations := range loaded.Messages {
+			if catalog.Messages[message] == nil {
+				catalog.Messages[message] = map[string]string{}
+			}
+			for language, translation := range translations {
+				catalog.Messages[message][normalizeLanguage(language)] = translation
+			}
+		}
+	}
+	return catalog, nil
+}
+
+// Translate returns the translation of message to language, such as de or pt-BR. A regional language
+// falls back to the translation to its base language. The message is returned if it has no translation.
+func (c *MessageCatalog) Translate(message string, language string) string {
+	if c == nil || message == "" {
+		return message
+	}
+	translations, ok := c.Messages[message]
+	if !ok {
+		return message
+	}
+
+	language = normalizeLanguage(language)
+	if translation, ok := translations[language]; ok {
+		return translation
+	}
+	if base := strings.SplitN(language, "-", 2)[0]; base != language {
+		if translation, ok := translations[base]; ok {
+			return translation
+		}
+	}
+	return message
+}
+
+// LanguageFromEnv returns the language of the locale environment variables, such as de-DE for
+// LANG=de_DE.UTF-8, or an empty string if they do not set one
+func LanguageFromEnv() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		value = strings.SplitN(value, ".", 2)[0]
+		value = strings.SplitN(value, "@", 2)[0]
+		if value == "C" || value == "POSIX" {
+			return ""
+		}
+		return normalizeLanguage(value)
+	}
+	return ""
+}
+
+// normalizeLanguage returns language as a lower case tag with a dash, so that pt_BR and pt-br match
+func normalizeLanguage(language string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(language), "_", "-"))
+}
+
+// LocalizeAnalyzers translates the outcome messages of the analyzers to language in place, before they
+// are analyzed so that translated messages are rendered like the messages they replace. Nothing is
+// translated if the catalog is nil or the language is empty.
+func LocalizeAnalyzers(analyzers []*troubleshootv1beta2.Analyze, hostAnalyzers []*troubleshootv1beta2.HostAnalyze, catalog *MessageCatalog, language string) {
+	if catalog == nil || language == "" {
+		return
+	}
+
+	for _, analyzer := range analyzers {
+		localizeOutcomes(reflect.ValueOf(analyzer), catalog, language)
+	}
+	for _, hostAnalyzer := range hostAnalyzers {
+		localizeOutcomes(reflect.ValueOf(hostAnalyzer), catalog, language)
+	}
+}
+
+var singleOutcomeType = reflect.TypeOf(troubleshootv1beta2.SingleOutcome{})
+
+// localizeOutcomes translates the messages of every outcome within v, which are in the Outcomes of each
+// kind of analyzer
+func localizeOutcomes(v reflect.Value, catalog *MessageCatalog, language string) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			localizeOutcomes(v.Elem(), catalog, language)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			localizeOutcomes(v.Index(i), catalog, language)
+		}
+	case reflect.Struct:
+		if v.Type() == singleOutcomeType {
+			message := v.FieldByName("Message")
+			if message.CanSet() {
+				message.SetString(catalog.Translate(message.String(), language))
+			}
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				localizeOutcomes(v.Field(i), catalog, language)
+			}
+		}
+	}
+}
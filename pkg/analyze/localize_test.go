@@ -0,0 +1,108 @@
+package analyzer
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMessageCatalogs(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.yaml")
+	second := filepath.Join(dir, "second.yaml")
+	require.NoError(t, ioutil.WriteFile(first, []byte(`messages:
+  "At least 3 nodes are required":
+    de: "Mindestens 3 Knoten sind erforderlich"
+    pt_BR: "Pelo menos 3 nós são necessários"
+  "Kubernetes {{ .Version }} is supported":
+    de: "Kubernetes {{ .Version }} wird unterstützt"
+`), 0644))
+	require.NoError(t, ioutil.WriteFile(second, []byte(`messages:
+  "At least 3 nodes are required":
+    de: "Es werden mindestens 3 Knoten benötigt"
+`), 0644))
+
+	catalog, err := LoadMessageCatalogs([]string{first, second})
+	require.NoError(t, err)
+
+	tests := []struct {
+		message  string
+		language string
+		want     string
+	}{
+		{"At least 3 nodes are required", "de", "Es werden mindestens 3 Knoten benötigt"},
+		{"At least 3 nodes are required", "de-AT", "Es werden mindestens 3 Knoten benötigt"},
+		{"At least 3 nodes are required", "pt-BR", "Pelo menos 3 nós são necessários"},
+		{"At least 3 nodes are required", "pt", "At least 3 nodes are required"},
+		{"At least 3 nodes are required", "fr", "At least 3 nodes are required"},
+		{"Kubernetes {{ .Version }} is supported", "DE", "Kubernetes {{ .Version }} wird unterstützt"},
+		{"Not in the catalog", "de", "Not in the catalog"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, catalog.Translate(test.message, test.language), "%s in %s", test.message, test.language)
+	}
+
+	_, err = LoadMessageCatalogs([]string{filepath.Join(dir, "missing.yaml")})
+	assert.Error(t, err)
+}
+
+func TestLanguageFromEnv(t *testing.T) {
+	tests := []struct {
+		lcAll string
+		lang  string
+		want  string
+	}{
+		{lang: "de_DE.UTF-8", want: "de-de"},
+		{lang: "ja_JP", want: "ja-jp"},
+		{lcAll: "fr_FR.UTF-8", lang: "de_DE.UTF-8", want: "fr-fr"},
+		{lang: "sr_RS@latin", want: "sr-rs"},
+		{lang: "C.UTF-8", want: ""},
+		{want: ""},
+	}
+	for _, test := range tests {
+		t.Setenv("LC_ALL", test.lcAll)
+		t.Setenv("LC_MESSAGES", "")
+		t.Setenv("LANG", test.lang)
+		assert.Equal(t, test.want, LanguageFromEnv())
+	}
+}
+
+func TestLocalizeAnalyzers(t *testing.T) {
+	catalog := &MessageCatalog{Messages: map[string]map[string]string{
+		"fail": {"de": "Fehler"},
+		"pass": {"de": "Bestanden"},
+	}}
+
+	analyzers := []*troubleshootv1beta2.Analyze{
+		{
+			ClusterVersion: &troubleshootv1beta2.ClusterVersion{
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{Fail: &troubleshootv1beta2.SingleOutcome{When: "< 1.20.0", Message: "fail"}},
+					{Pass: &troubleshootv1beta2.SingleOutcome{Message: "pass"}},
+				},
+			},
+		},
+	}
+	hostAnalyzers := []*troubleshootv1beta2.HostAnalyze{
+		{
+			CPU: &troubleshootv1beta2.CPUAnalyze{
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{Warn: &troubleshootv1beta2.SingleOutcome{Message: "warn"}},
+				},
+			},
+		},
+	}
+
+	LocalizeAnalyzers(analyzers, hostAnalyzers, catalog, "")
+	assert.Equal(t, "fail", analyzers[0].ClusterVersion.Outcomes[0].Fail.Message)
+
+	LocalizeAnalyzers(analyzers, hostAnalyzers, catalog, "de")
+	assert.Equal(t, "Fehler", analyzers[0].ClusterVersion.Outcomes[0].Fail.Message)
+	assert.Equal(t, "< 1.20.0", analyzers[0].ClusterVersion.Outcomes[0].Fail.When)
+	assert.Equal(t, "Bestanden", analyzers[0].ClusterVersion.Outcomes[1].Pass.Message)
+	assert.Equal(t, "warn", hostAnalyzers[0].CPU.Outcomes[0].Warn.Message)
+}
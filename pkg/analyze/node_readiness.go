@@ -0,0 +1,383 @@
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NodeNotReadyCause is why a node is not ready, and is what the when of a nodeReadiness outcome matches
+type NodeNotReadyCause string
+
+const (
+	NodeNotReadyKubeletCertificate NodeNotReadyCause = "kubelet-certificate"
+	NodeNotReadyNetworkPlugin      NodeNotReadyCause = "network-plugin"
+	NodeNotReadyDiskPressure       NodeNotReadyCause = "disk-pressure"
+	NodeNotReadyMemoryPressure     NodeNotReadyCause = "memory-pressure"
+	NodeNotReadyPIDPressure        NodeNotReadyCause = "pid-pressure"
+	NodeNotReadyCloudProvider      NodeNotReadyCause = "cloud-provider"
+	NodeNotReadyKubeletStopped     NodeNotReadyCause = "kubelet-stopped"
+	NodeNotReadyUnknown            NodeNotReadyCause = "unknown"
+)
+
+// nodeNotReadyPatterns match the messages of events and the lines of kubelet logs by cause. The causes
+// are in order of precedence, as an expired kubelet certificate makes the node look like it stopped
+// and a missing network plugin makes its pods fail.
+var nodeNotReadyPatterns = []struct {
+	cause    NodeNotReadyCause
+	patterns []*regexp.Regexp
+}{
+	{
+		cause: NodeNotReadyKubeletCertificate,
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`x509: certificate has expired or is not yet valid`),
+			regexp.MustCompile(`(?i)client certificate .* is expired`),
+			regexp.MustCompile(`(?i)current client certificate is expired`),
+		},
+	},
+	{
+		cause: NodeNotReadyNetworkPlugin,
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`NetworkPluginNotReady`),
+			regexp.MustCompile(`(?i)network plugin is not ready`),
+			regexp.MustCompile(`(?i)cni plugin not initialized`),
+			regexp.MustCompile(`(?i)cni config uninitialized`),
+		},
+	},
+	{
+		cause: NodeNotReadyDiskPressure,
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`NodeHasDiskPressure|FreeDiskSpaceFailed|ImageGCFailed`),
+			regexp.MustCompile(`(?i)attempting to reclaim (ephemeral-storage|nodefs|imagefs)`),
+			regexp.MustCompile(`(?i)no space left on device`),
+		},
+	},
+	{
+		cause: NodeNotReadyMemoryPressure,
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`NodeHasInsufficientMemory|SystemOOM`),
+			regexp.MustCompile(`(?i)attempting to reclaim memory`),
+		},
+	},
+	{
+		cause: NodeNotReadyPIDPressure,
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`NodeHasInsufficientPID`),
+			regexp.MustCompile(`(?i)attempting to reclaim pids`),
+		},
+	},
+	{
+		cause: NodeNotReadyCloudProvider,
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)failed to get (instance|node) (metadata|address|provider)`),
+			regexp.MustCompile(`(?i)error (fetching|getting) instance`),
+			regexp.MustCompile(`(?i)cloud provider (could not|failed|error)`),
+		},
+	},
+}
+
+// NodeNotReady is what the messages of a nodeReadiness analyzer are templated with
+type NodeNotReady struct {
+	Node  string
+	Cause NodeNotReadyCause
+	// Evidence is the condition, event or log line that the cause was found in
+	Evidence string
+}
+
+func analyzeNodeReadiness(analyzer *troubleshootv1beta2.NodeReadiness, getCollectedFileContents func(string) ([]byte, error), findFiles func(string) (map[string][]byte, error)) ([]*AnalyzeResult, error) {
+	title := analyzer.CheckName
+	if title == "" {
+		title = "Node Readiness"
+	}
+
+	for _, outcome := range analyzer.Outcomes {
+		for _, single := range []*troubleshootv1beta2.SingleOutcome{outcome.Fail, outcome.Warn} {
+			if single != nil && single.When != "" && !isNodeNotReadyCause(NodeNotReadyCause(single.When)) {
+				return nil, errors.Errorf("unknown node not ready cause %q", single.When)
+			}
+		}
+	}
+
+	nodesData, err := getCollectedFileContents(filepath.Join("cluster-resources", "nodes.json"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read collected nodes")
+	}
+	var nodes corev1.NodeList
+	if err := json.Unmarshal(nodesData, &nodes); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal node list")
+	}
+
+	events, err := getNodeEvents(findFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	kubeletLogs := map[string][]byte{}
+	for _, glob := range analyzer.KubeletLogs {
+		files, err := findFiles(glob)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to find kubelet logs %s", glob)
+		}
+		for name, file := range files {
+			kubeletLogs[name] = file
+		}
+	}
+
+	notReady := []NodeNotReady{}
+	for _, node := range nodes.Items {
+		if isNodeReady(node) {
+			continue
+		}
+
+		logs := [][]byte{}
+		for name, file := range kubeletLogs {
+			if len(nodes.Items) == 1 || pathHasDir(name, node.Name) {
+				logs = append(logs, file)
+			}
+		}
+		notReady = append(notReady, classifyNodeNotReady(node, events[node.Name], logs))
+	}
+	sort.Slice(notReady, func(i, j int) bool {
+		return notReady[i].Node < notReady[j].Node
+	})
+
+	if len(notReady) == 0 {
+		result := &AnalyzeResult{
+			Title:   title,
+			IconKey: "kubernetes_node_readiness",
+			IsPass:  true,
+			Message: fmt.Sprintf("All %d nodes are ready", len(nodes.Items)),
+		}
+		for _, outcome := range analyzer.Outcomes {
+			if outcome.Pass != nil {
+				result.Message = outcome.Pass.Message
+				result.URI = outcome.Pass.URI
+				break
+			}
+		}
+		return []*AnalyzeResult{result}, nil
+	}
+
+	results := []*AnalyzeResult{}
+	for _, n := range notReady {
+		result, err := nodeNotReadyResult(analyzer, title, n)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func nodeNotReadyResult(analyzer *troubleshootv1beta2.NodeReadiness, title string, n NodeNotReady) (*AnalyzeResult, error) {
+	result := &AnalyzeResult{
+		Title:   fmt.Sprintf("%s: %s", title, n.Node),
+		IconKey: "kubernetes_node_readiness",
+		IsFail:  true,
+		Message: defaultNodeNotReadyMessage(n),
+	}
+
+	// ordering from the spec is important, the first one that matches returns
+	for _, outcome := range analyzer.Outcomes {
+		single := outcome.Fail
+		if single == nil {
+			single = outcome.Warn
+		}
+		if single == nil || (single.When != "" && NodeNotReadyCause(single.When) != n.Cause) {
+			continue
+		}
+
+		tmpl, err := template.New("node").Parse(single.Message)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create new message template")
+		}
+		var m bytes.Buffer
+		if err := tmpl.Execute(&m, n); err != nil {
+			return nil, errors.Wrap(err, "failed to execute template")
+		}
+
+		result.IsFail = outcome.Fail != nil
+		result.IsWarn = outcome.Fail == nil
+		result.Message = m.String()
+		result.URI = single.URI
+		break
+	}
+
+	return result, nil
+}
+
+func defaultNodeNotReadyMessage(n NodeNotReady) string {
+	var cause string
+	switch n.Cause {
+	case NodeNotReadyKubeletCertificate:
+		cause = "the kubelet's client certificate has expired"
+	case NodeNotReadyNetworkPlugin:
+		cause = "the network plugin is not ready"
+	case NodeNotReadyDiskPressure:
+		cause = "it is out of disk space"
+	case NodeNotReadyMemoryPressure:
+		cause = "it is out of memory"
+	case NodeNotReadyPIDPressure:
+		cause = "it is out of process IDs"
+	case NodeNotReadyCloudProvider:
+		cause = "of the cloud provider"
+	case NodeNotReadyKubeletStopped:
+		cause = "the kubelet stopped reporting its status"
+	default:
+		return fmt.Sprintf("Node %s is not ready: %s", n.Node, n.Evidence)
+	}
+	return fmt.Sprintf("Node %s is not ready because %s: %s", n.Node, cause, n.Evidence)
+}
+
+// classifyNodeNotReady finds why the node is not ready. The node's conditions and taints are checked
+// first, as they are current, then its events and kubelet logs.
+func classifyNodeNotReady(node corev1.Node, events []corev1.Event, logs [][]byte) NodeNotReady {
+	n := NodeNotReady{Node: node.Name, Cause: NodeNotReadyUnknown}
+
+	var ready *corev1.NodeCondition
+	for i, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			ready = &node.Status.Conditions[i]
+			continue
+		}
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case corev1.NodeDiskPressure:
+			n.Cause = NodeNotReadyDiskPressure
+		case corev1.NodeMemoryPressure:
+			n.Cause = NodeNotReadyMemoryPressure
+		case corev1.NodePIDPressure:
+			n.Cause = NodeNotReadyPIDPressure
+		case corev1.NodeNetworkUnavailable:
+			n.Cause = NodeNotReadyNetworkPlugin
+		default:
+			continue
+		}
+		n.Evidence = conditionEvidence(condition)
+		return n
+	}
+
+	if ready != nil {
+		if cause := matchNodeNotReadyCause(ready.Reason + " " + ready.Message); cause != "" {
+			n.Cause = cause
+			n.Evidence = conditionEvidence(*ready)
+			return n
+		}
+	}
+
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == "node.cloudprovider.kubernetes.io/uninitialized" {
+			n.Cause = NodeNotReadyCloudProvider
+			n.Evidence = fmt.Sprintf("the node has the taint %s, it has not been initialized by the cloud controller manager", taint.Key)
+			return n
+		}
+	}
+
+	// the causes are in order of precedence over both events and logs
+	evidence := map[NodeNotReadyCause]string{}
+	for _, event := range events {
+		if cause := matchNodeNotReadyCause(event.Reason + " " + event.Message); cause != "" && evidence[cause] == "" {
+			evidence[cause] = fmt.Sprintf("event %s: %s", event.Reason, event.Message)
+		}
+	}
+	for _, log := range logs {
+		scanner := bufio.NewScanner(bytes.NewReader(log))
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if cause := matchNodeNotReadyCause(line); cause != "" && evidence[cause] == "" {
+				evidence[cause] = strings.TrimSpace(line)
+			}
+		}
+	}
+	for _, p := range nodeNotReadyPatterns {
+		if evidence[p.cause] != "" {
+			n.Cause = p.cause
+			n.Evidence = evidence[p.cause]
+			return n
+		}
+	}
+
+	if ready != nil && ready.Status == corev1.ConditionUnknown {
+		n.Cause = NodeNotReadyKubeletStopped
+	}
+	if ready != nil {
+		n.Evidence = conditionEvidence(*ready)
+	} else {
+		n.Evidence = "the node has no Ready condition"
+	}
+	return n
+}
+
+func matchNodeNotReadyCause(s string) NodeNotReadyCause {
+	for _, p := range nodeNotReadyPatterns {
+		for _, pattern := range p.patterns {
+			if pattern.MatchString(s) {
+				return p.cause
+			}
+		}
+	}
+	return ""
+}
+
+func isNodeNotReadyCause(cause NodeNotReadyCause) bool {
+	switch cause {
+	case NodeNotReadyKubeletCertificate, NodeNotReadyNetworkPlugin, NodeNotReadyDiskPressure, NodeNotReadyMemoryPressure,
+		NodeNotReadyPIDPressure, NodeNotReadyCloudProvider, NodeNotReadyKubeletStopped, NodeNotReadyUnknown:
+		return true
+	}
+	return false
+}
+
+func conditionEvidence(condition corev1.NodeCondition) string {
+	return fmt.Sprintf("condition %s is %s: %s", condition.Type, condition.Status, condition.Message)
+}
+
+// getNodeEvents returns the collected events of nodes by node name
+func getNodeEvents(findFiles func(string) (map[string][]byte, error)) (map[string][]corev1.Event, error) {
+	collected, err := getCollectedEvents(findFiles, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	events := map[string][]corev1.Event{}
+	for _, event := range collected {
+		if event.InvolvedObject.Kind == "Node" {
+			events[event.InvolvedObject.Name] = append(events[event.InvolvedObject.Name], event)
+		}
+	}
+
+	return events, nil
+}
+
+func isNodeReady(node corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// pathHasDir returns true if dir is one of the directories of the path
+func pathHasDir(p string, dir string) bool {
+	for _, d := range strings.Split(filepath.ToSlash(filepath.Dir(p)), "/") {
+		if d == dir {
+			return true
+		}
+	}
+	return false
+}
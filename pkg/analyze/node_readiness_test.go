@@ -0,0 +1,323 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_classifyNodeNotReady(t *testing.T) {
+	stopped := "Kubelet stopped posting node status."
+
+	tests := []struct {
+		name         string
+		node         corev1.Node
+		events       []corev1.Event
+		logs         []string
+		wantCause    NodeNotReadyCause
+		wantEvidence string
+	}{
+		{
+			name: "network plugin in ready condition",
+			node: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Status: corev1.NodeStatus{
+					Conditions: []corev1.NodeCondition{
+						{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Message: "container runtime network not ready: NetworkReady=false reason:NetworkPluginNotReady message:Network plugin returns error: cni plugin not initialized"},
+					},
+				},
+			},
+			wantCause:    NodeNotReadyNetworkPlugin,
+			wantEvidence: "condition Ready is False: container runtime network not ready: NetworkReady=false reason:NetworkPluginNotReady message:Network plugin returns error: cni plugin not initialized",
+		},
+		{
+			name: "disk pressure condition",
+			node: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Status: corev1.NodeStatus{
+					Conditions: []corev1.NodeCondition{
+						{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+						{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue, Message: "kubelet has disk pressure"},
+						{Type: corev1.NodeReady, Status: corev1.ConditionFalse},
+					},
+				},
+			},
+			wantCause:    NodeNotReadyDiskPressure,
+			wantEvidence: "condition DiskPressure is True: kubelet has disk pressure",
+		},
+		{
+			name: "uninitialized by the cloud provider",
+			node: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: "node.cloudprovider.kubernetes.io/uninitialized", Effect: corev1.TaintEffectNoSchedule}}},
+				Status: corev1.NodeStatus{
+					Conditions: []corev1.NodeCondition{
+						{Type: corev1.NodeReady, Status: corev1.ConditionFalse},
+					},
+				},
+			},
+			wantCause:    NodeNotReadyCloudProvider,
+			wantEvidence: "the node has the taint node.cloudprovider.kubernetes.io/uninitialized, it has not been initialized by the cloud controller manager",
+		},
+		{
+			name: "expired certificate in kubelet logs before events",
+			node: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Status: corev1.NodeStatus{
+					Conditions: []corev1.NodeCondition{
+						{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Message: stopped},
+					},
+				},
+			},
+			events: []corev1.Event{
+				{Reason: "FreeDiskSpaceFailed", Message: "failed to garbage collect required amount of images"},
+			},
+			logs: []string{
+				"I0101 00:00:00.000000    1234 kubelet.go:100] starting\n" +
+					`E0101 00:00:01.000000    1234 reflector.go:138] failed to list *v1.Node: Get "https://10.0.0.1:6443/api/v1/nodes": x509: certificate has expired or is not yet valid` + "\n",
+			},
+			wantCause:    NodeNotReadyKubeletCertificate,
+			wantEvidence: `E0101 00:00:01.000000    1234 reflector.go:138] failed to list *v1.Node: Get "https://10.0.0.1:6443/api/v1/nodes": x509: certificate has expired or is not yet valid`,
+		},
+		{
+			name: "disk events",
+			node: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Status: corev1.NodeStatus{
+					Conditions: []corev1.NodeCondition{
+						{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Message: stopped},
+					},
+				},
+			},
+			events: []corev1.Event{
+				{Reason: "FreeDiskSpaceFailed", Message: "failed to garbage collect required amount of images"},
+			},
+			wantCause:    NodeNotReadyDiskPressure,
+			wantEvidence: "event FreeDiskSpaceFailed: failed to garbage collect required amount of images",
+		},
+		{
+			name: "kubelet stopped",
+			node: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Status: corev1.NodeStatus{
+					Conditions: []corev1.NodeCondition{
+						{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Message: stopped},
+					},
+				},
+			},
+			wantCause:    NodeNotReadyKubeletStopped,
+			wantEvidence: "condition Ready is Unknown: Kubelet stopped posting node status.",
+		},
+		{
+			name: "unknown",
+			node: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Status: corev1.NodeStatus{
+					Conditions: []corev1.NodeCondition{
+						{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Message: "something else"},
+					},
+				},
+			},
+			wantCause:    NodeNotReadyUnknown,
+			wantEvidence: "condition Ready is False: something else",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := [][]byte{}
+			for _, log := range tt.logs {
+				logs = append(logs, []byte(log))
+			}
+
+			n := classifyNodeNotReady(tt.node, tt.events, logs)
+			assert.Equal(t, "node-1", n.Node)
+			assert.Equal(t, tt.wantCause, n.Cause)
+			assert.Equal(t, tt.wantEvidence, n.Evidence)
+		})
+	}
+}
+
+func Test_analyzeNodeReadiness(t *testing.T) {
+	nodes := corev1.NodeList{Items: []corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Message: "Kubelet stopped posting node status."},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-3"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Message: "Kubelet stopped posting node status."},
+				},
+			},
+		},
+	}}
+	events := corev1.EventList{Items: []corev1.Event{
+		{
+			InvolvedObject: corev1.ObjectReference{Kind: "Node", Name: "node-3"},
+			Reason:         "NodeHasInsufficientPID",
+			Message:        "Node node-3 status is now: NodeHasInsufficientPID",
+		},
+		{
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "node-2"},
+			Reason:         "FreeDiskSpaceFailed",
+		},
+	}}
+
+	files := map[string][]byte{
+		"kubelet/node-2/kubelet.log": []byte("E0101 certificate_manager.go:488] kubernetes.io/kube-apiserver-client-kubelet: current client certificate is expired\n"),
+		"kubelet/node-1/kubelet.log": []byte("E0101 cni config uninitialized\n"),
+	}
+	for name, obj := range map[string]interface{}{
+		"cluster-resources/nodes.json":          nodes,
+		"cluster-resources/events/default.json": events,
+	} {
+		b, err := json.Marshal(obj)
+		require.NoError(t, err)
+		files[name] = b
+	}
+
+	getFile := func(n string) ([]byte, error) {
+		return files[n], nil
+	}
+	findFiles := func(n string) (map[string][]byte, error) {
+		matching := map[string][]byte{}
+		for name, file := range files {
+			if ok, _ := filepath.Match(n, name); ok {
+				matching[name] = file
+			}
+		}
+		return matching, nil
+	}
+
+	tests := []struct {
+		name         string
+		analyzer     troubleshootv1beta2.NodeReadiness
+		expectResult []*AnalyzeResult
+		expectErr    string
+	}{
+		{
+			name: "default messages",
+			analyzer: troubleshootv1beta2.NodeReadiness{
+				KubeletLogs: []string{"kubelet/*/kubelet.log"},
+			},
+			expectResult: []*AnalyzeResult{
+				{
+					Title:   "Node Readiness: node-2",
+					IconKey: "kubernetes_node_readiness",
+					IsFail:  true,
+					Message: "Node node-2 is not ready because the kubelet's client certificate has expired: E0101 certificate_manager.go:488] kubernetes.io/kube-apiserver-client-kubelet: current client certificate is expired",
+				},
+				{
+					Title:   "Node Readiness: node-3",
+					IconKey: "kubernetes_node_readiness",
+					IsFail:  true,
+					Message: "Node node-3 is not ready because it is out of process IDs: event NodeHasInsufficientPID: Node node-3 status is now: NodeHasInsufficientPID",
+				},
+			},
+		},
+		{
+			name: "outcomes",
+			analyzer: troubleshootv1beta2.NodeReadiness{
+				AnalyzeMeta: troubleshootv1beta2.AnalyzeMeta{CheckName: "Nodes"},
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{
+						Fail: &troubleshootv1beta2.SingleOutcome{
+							When:    "kubelet-stopped",
+							Message: "The kubelet on {{ .Node }} is down",
+							URI:     "https://example.com/kubelet",
+						},
+					},
+					{
+						Warn: &troubleshootv1beta2.SingleOutcome{
+							Message: "{{ .Node }} is not ready ({{ .Cause }})",
+						},
+					},
+				},
+			},
+			expectResult: []*AnalyzeResult{
+				{
+					Title:   "Nodes: node-2",
+					IconKey: "kubernetes_node_readiness",
+					IsFail:  true,
+					Message: "The kubelet on node-2 is down",
+					URI:     "https://example.com/kubelet",
+				},
+				{
+					Title:   "Nodes: node-3",
+					IconKey: "kubernetes_node_readiness",
+					IsWarn:  true,
+					Message: "node-3 is not ready (pid-pressure)",
+				},
+			},
+		},
+		{
+			name: "unknown cause",
+			analyzer: troubleshootv1beta2.NodeReadiness{
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{Fail: &troubleshootv1beta2.SingleOutcome{When: "disk-full"}},
+				},
+			},
+			expectErr: `unknown node not ready cause "disk-full"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := analyzeNodeReadiness(&tt.analyzer, getFile, findFiles)
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectResult, actual)
+		})
+	}
+}
+
+func Test_analyzeNodeReadinessAllReady(t *testing.T) {
+	b, err := json.Marshal(corev1.NodeList{Items: []corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		},
+	}})
+	require.NoError(t, err)
+
+	getFile := func(n string) ([]byte, error) {
+		return b, nil
+	}
+	findFiles := func(n string) (map[string][]byte, error) {
+		return map[string][]byte{}, nil
+	}
+
+	actual, err := analyzeNodeReadiness(&troubleshootv1beta2.NodeReadiness{}, getFile, findFiles)
+	require.NoError(t, err)
+	assert.Equal(t, []*AnalyzeResult{
+		{
+			Title:   "Node Readiness",
+			IconKey: "kubernetes_node_readiness",
+			IsPass:  true,
+			Message: "All 1 nodes are ready",
+		},
+	}, actual)
+}
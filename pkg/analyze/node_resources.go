@@ -109,6 +109,11 @@ func compareNodeResourceConditionalToActual(conditional string, matchingNodes []
 		return
 	}
 
+	if match := nodeCountFilterRX.FindStringSubmatch(strings.TrimSpace(conditional)); match != nil {
+		res, err = compareNodeCountWithFilter(match[1], match[2], match[3], matchingNodes)
+		return
+	}
+
 	parts := strings.Fields(strings.TrimSpace(conditional))
 
 	if len(parts) == 2 {
@@ -268,6 +273,85 @@ func compareNodeResourceConditionalToActual(conditional string, matchingNodes []
 	return
 }
 
+// Example: count(memoryCapacity >= 16Gi) >= 3, count(label:node-role.kubernetes.io/worker) > 0
+var nodeCountFilterRX = regexp.MustCompile(`^count\((.+)\)\s*(<=|>=|<|>|={1,3})\s*(\S+)$`)
+
+// Example: memoryCapacity >= 16Gi
+var nodePropertyFilterRX = regexp.MustCompile(`^(\w+)\s*(<=|>=|<|>|={1,3})\s*(\S+)$`)
+
+// compareNodeCountWithFilter compares the number of matching nodes that the filter of a count() is true
+// for. The filter compares a property of each node, such as "cpuAllocatable >= 4", or is a label that the
+// node has, such as "label:disktype" or "label:disktype=ssd".
+func compareNodeCountWithFilter(filter string, operator string, desired string, matchingNodes []corev1.Node) (bool, error) {
+	desiredCount, err := strconv.Atoi(desired)
+	if err != nil {
+		return false, errors.Errorf("count %q is not an integer", desired)
+	}
+
+	var nodeMatches func(node corev1.Node) bool
+
+	if strings.HasPrefix(filter, "label:") {
+		key, value, hasValue := strings.Cut(strings.TrimPrefix(filter, "label:"), "=")
+		nodeMatches = func(node corev1.Node) bool {
+			actual, ok := node.Labels[key]
+			return ok && (!hasValue || actual == value)
+		}
+	} else {
+		match := nodePropertyFilterRX.FindStringSubmatch(strings.TrimSpace(filter))
+		if match == nil {
+			return false, errors.Errorf("failed to parse count filter %q", filter)
+		}
+		property, propertyOperator := match[1], match[2]
+		desiredQuantity, err := resource.ParseQuantity(match[3])
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to parse %q in count filter %q", match[3], filter)
+		}
+		if getQuantity(corev1.Node{}, property) == nil {
+			return false, errors.Errorf("unknown property %q in count filter %q", property, filter)
+		}
+		nodeMatches = func(node corev1.Node) bool {
+			return compareNodeResourceQuantity(propertyOperator, getQuantity(node, property).Cmp(desiredQuantity))
+		}
+	}
+
+	count := 0
+	for _, node := range matchingNodes {
+		if nodeMatches(node) {
+			count++
+		}
+	}
+
+	switch operator {
+	case "<":
+		return count < desiredCount, nil
+	case "<=":
+		return count <= desiredCount, nil
+	case ">":
+		return count > desiredCount, nil
+	case ">=":
+		return count >= desiredCount, nil
+	default:
+		return count == desiredCount, nil
+	}
+}
+
+// compareNodeResourceQuantity returns whether the result of comparing an actual quantity to a desired one
+// satisfies the operator
+func compareNodeResourceQuantity(operator string, cmp int) bool {
+	switch operator {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return cmp == 0
+	}
+}
+
 func getQuantity(node corev1.Node, property string) *resource.Quantity {
 	switch property {
 	case "cpuCapacity":
@@ -384,6 +384,60 @@ func Test_compareNodeResourceConditionalToActual(t *testing.T) {
 	}
 }
 
+func Test_compareNodeCountWithFilter(t *testing.T) {
+	node := func(name string, cpu string, memory string, labels map[string]string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status: corev1.NodeStatus{
+				Capacity: corev1.ResourceList{
+					"cpu":    resource.MustParse(cpu),
+					"memory": resource.MustParse(memory),
+				},
+				Allocatable: corev1.ResourceList{
+					"cpu":    resource.MustParse(cpu),
+					"memory": resource.MustParse(memory),
+				},
+			},
+		}
+	}
+	nodes := []corev1.Node{
+		node("node1", "4", "16Gi", map[string]string{"disktype": "ssd"}),
+		node("node2", "4", "32Gi", map[string]string{"disktype": "hdd"}),
+		node("node3", "2", "8Gi", nil),
+	}
+
+	tests := []struct {
+		conditional string
+		expected    bool
+		isError     bool
+	}{
+		{conditional: "count(memoryCapacity >= 16Gi) >= 2", expected: true},
+		{conditional: "count(memoryCapacity >= 16Gi) >= 3", expected: false},
+		{conditional: "count(cpuAllocatable < 4) == 1", expected: true},
+		{conditional: "count(label:disktype) >= 2", expected: true},
+		{conditional: "count(label:disktype=ssd) > 0", expected: true},
+		{conditional: "count(label:disktype=nvme) > 0", expected: false},
+		{conditional: "count(gpuCapacity > 1) > 0", isError: true},
+		{conditional: "count(memoryCapacity >= lots) > 0", isError: true},
+		{conditional: "count(label:disktype) > some", isError: true},
+		// the existing functions are unchanged
+		{conditional: "sum(cpuAllocatable) >= 8", expected: true},
+		{conditional: "count() == 3", expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.conditional, func(t *testing.T) {
+			actual, err := compareNodeResourceConditionalToActual(test.conditional, nodes)
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
 func Test_nodeMatchesFilters(t *testing.T) {
 	node := corev1.Node{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,330 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodFailureReason is how a container is failing, and is what the when of a podFailures outcome matches
+type PodFailureReason string
+
+const (
+	PodFailureCrashLoopBackOff PodFailureReason = "CrashLoopBackOff"
+	PodFailureImagePullBackOff PodFailureReason = "ImagePullBackOff"
+	PodFailureOOMKilled        PodFailureReason = "OOMKilled"
+)
+
+// containerFieldPathRegex matches the field path of the container that an event is about
+var containerFieldPathRegex = regexp.MustCompile(`^spec\.(?:initContainers|containers|ephemeralContainers)\{(.+)\}$`)
+
+// PodFailure is what the messages of a podFailures analyzer are templated with
+type PodFailure struct {
+	Namespace string
+	Pod       string
+	Container string
+	Reason    PodFailureReason
+	// RestartCount is how many times the container has restarted, if the pod was collected
+	RestartCount int32
+	// Evidence is the container state or event that the failure was found in
+	Evidence string
+}
+
+func analyzePodFailures(analyzer *troubleshootv1beta2.PodFailures, findFiles func(string) (map[string][]byte, error)) ([]*AnalyzeResult, error) {
+	title := analyzer.CheckName
+	if title == "" {
+		title = "Pod Failures"
+	}
+
+	for _, outcome := range analyzer.Outcomes {
+		for _, single := range []*troubleshootv1beta2.SingleOutcome{outcome.Fail, outcome.Warn} {
+			if single != nil && single.When != "" && !isPodFailureReason(PodFailureReason(single.When)) {
+				return nil, errors.Errorf("unknown pod failure reason %q", single.When)
+			}
+		}
+	}
+
+	pods, err := getCollectedPods(findFiles, analyzer.Namespaces)
+	if err != nil {
+		return nil, err
+	}
+	events, err := getCollectedEvents(findFiles, analyzer.Namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	failures := findPodFailures(pods, events)
+
+	if len(failures) == 0 {
+		result := &AnalyzeResult{
+			Title:   title,
+			IconKey: "kubernetes_pod_failures",
+			IsPass:  true,
+			Message: fmt.Sprintf("No containers of the %d pods are crash looping, failing to pull their image or OOMKilled", len(pods)),
+		}
+		for _, outcome := range analyzer.Outcomes {
+			if outcome.Pass != nil {
+				result.Message = outcome.Pass.Message
+				result.URI = outcome.Pass.URI
+				break
+			}
+		}
+		return []*AnalyzeResult{result}, nil
+	}
+
+	results := []*AnalyzeResult{}
+	for _, f := range failures {
+		result, err := podFailureResult(analyzer, title, f)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func podFailureResult(analyzer *troubleshootv1beta2.PodFailures, title string, f PodFailure) (*AnalyzeResult, error) {
+	result := &AnalyzeResult{
+		Title:   fmt.Sprintf("%s: %s/%s", title, f.Namespace, f.Pod),
+		IconKey: "kubernetes_pod_failures",
+		IsFail:  true,
+		Message: defaultPodFailureMessage(f),
+	}
+
+	// ordering from the spec is important, the first one that matches returns
+	for _, outcome := range analyzer.Outcomes {
+		single := outcome.Fail
+		if single == nil {
+			single = outcome.Warn
+		}
+		if single == nil || (single.When != "" && PodFailureReason(single.When) != f.Reason) {
+			continue
+		}
+
+		tmpl, err := template.New("pod").Parse(single.Message)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create new message template")
+		}
+		var m bytes.Buffer
+		if err := tmpl.Execute(&m, f); err != nil {
+			return nil, errors.Wrap(err, "failed to execute template")
+		}
+
+		result.IsFail = outcome.Fail != nil
+		result.IsWarn = outcome.Fail == nil
+		result.Message = m.String()
+		result.URI = single.URI
+		break
+	}
+
+	return result, nil
+}
+
+func defaultPodFailureMessage(f PodFailure) string {
+	var failure string
+	switch f.Reason {
+	case PodFailureCrashLoopBackOff:
+		failure = "is in CrashLoopBackOff"
+	case PodFailureImagePullBackOff:
+		failure = "can not pull its image"
+	case PodFailureOOMKilled:
+		failure = "was OOMKilled"
+	}
+	return fmt.Sprintf("Container %s of pod %s/%s %s, it has restarted %d times: %s", f.Container, f.Namespace, f.Pod, failure, f.RestartCount, f.Evidence)
+}
+
+// findPodFailures returns the failing containers of pods, sorted by pod and container. A container's
+// current state is used before its events. A container that is crash looping because it was OOMKilled
+// is OOMKilled, and events only add the failures of containers whose state does not show them any more,
+// such as those of pods that have since been deleted.
+func findPodFailures(pods []corev1.Pod, events []corev1.Event) []PodFailure {
+	type key struct {
+		namespace string
+		pod       string
+		container string
+	}
+	failures := map[key]PodFailure{}
+	restartCounts := map[key]int32{}
+
+	for _, pod := range pods {
+		statuses := append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...)
+		statuses = append(statuses, pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			k := key{pod.Namespace, pod.Name, status.Name}
+			restartCounts[k] = status.RestartCount
+			if f, ok := containerStatusFailure(status); ok {
+				f.Namespace = pod.Namespace
+				f.Pod = pod.Name
+				failures[k] = f
+			}
+		}
+	}
+
+	for _, event := range events {
+		if event.InvolvedObject.Kind != "Pod" {
+			continue
+		}
+		matches := containerFieldPathRegex.FindStringSubmatch(event.InvolvedObject.FieldPath)
+		if matches == nil {
+			continue
+		}
+		reason, ok := eventPodFailureReason(event)
+		if !ok {
+			continue
+		}
+		k := key{event.InvolvedObject.Namespace, event.InvolvedObject.Name, matches[1]}
+		if k.namespace == "" {
+			k.namespace = event.Namespace
+		}
+		if _, ok := failures[k]; ok {
+			continue
+		}
+		failures[k] = PodFailure{
+			Namespace:    k.namespace,
+			Pod:          k.pod,
+			Container:    k.container,
+			Reason:       reason,
+			RestartCount: restartCounts[k],
+			Evidence:     fmt.Sprintf("event %s: %s", event.Reason, event.Message),
+		}
+	}
+
+	sorted := make([]PodFailure, 0, len(failures))
+	for _, f := range failures {
+		sorted = append(sorted, f)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Namespace != sorted[j].Namespace {
+			return sorted[i].Namespace < sorted[j].Namespace
+		}
+		if sorted[i].Pod != sorted[j].Pod {
+			return sorted[i].Pod < sorted[j].Pod
+		}
+		return sorted[i].Container < sorted[j].Container
+	})
+	return sorted
+}
+
+func containerStatusFailure(status corev1.ContainerStatus) (PodFailure, bool) {
+	f := PodFailure{Container: status.Name, RestartCount: status.RestartCount}
+
+	if waiting := status.State.Waiting; waiting != nil && (waiting.Reason == "ImagePullBackOff" || waiting.Reason == "ErrImagePull") {
+		f.Reason = PodFailureImagePullBackOff
+		f.Evidence = fmt.Sprintf("waiting with %s: %s", waiting.Reason, waiting.Message)
+		return f, true
+	}
+
+	for _, terminated := range []*corev1.ContainerStateTerminated{status.State.Terminated, status.LastTerminationState.Terminated} {
+		if terminated != nil && terminated.Reason == "OOMKilled" {
+			f.Reason = PodFailureOOMKilled
+			f.Evidence = fmt.Sprintf("terminated with OOMKilled, exit code %d", terminated.ExitCode)
+			return f, true
+		}
+	}
+
+	if waiting := status.State.Waiting; waiting != nil && waiting.Reason == "CrashLoopBackOff" {
+		f.Reason = PodFailureCrashLoopBackOff
+		f.Evidence = fmt.Sprintf("waiting with %s: %s", waiting.Reason, waiting.Message)
+		if terminated := status.LastTerminationState.Terminated; terminated != nil {
+			f.Evidence = fmt.Sprintf("%s, last terminated with %s, exit code %d", f.Evidence, terminated.Reason, terminated.ExitCode)
+		}
+		return f, true
+	}
+
+	return f, false
+}
+
+// eventPodFailureReason returns how the container of a pod event is failing, from the events that the
+// kubelet records when it backs off
+func eventPodFailureReason(event corev1.Event) (PodFailureReason, bool) {
+	switch {
+	case event.Reason == "BackOff" && strings.HasPrefix(event.Message, "Back-off restarting failed container"):
+		return PodFailureCrashLoopBackOff, true
+	case event.Reason == "BackOff" && strings.HasPrefix(event.Message, "Back-off pulling image"):
+		return PodFailureImagePullBackOff, true
+	case event.Reason == "Failed" && (strings.Contains(event.Message, "ErrImagePull") || strings.Contains(event.Message, "ImagePullBackOff")):
+		return PodFailureImagePullBackOff, true
+	}
+	return "", false
+}
+
+func isPodFailureReason(reason PodFailureReason) bool {
+	switch reason {
+	case PodFailureCrashLoopBackOff, PodFailureImagePullBackOff, PodFailureOOMKilled:
+		return true
+	}
+	return false
+}
+
+// getCollectedPods returns the collected pods of namespaces, all namespaces if none are given
+func getCollectedPods(findFiles func(string) (map[string][]byte, error), namespaces []string) ([]corev1.Pod, error) {
+	files, err := findFiles(filepath.Join("cluster-resources", "pods", "*.json"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read collected pods")
+	}
+
+	pods := []corev1.Pod{}
+	for name, collected := range files {
+		if !includeCollectedNamespace(name, namespaces) {
+			continue
+		}
+		var podList corev1.PodList
+		if err := json.Unmarshal(collected, &podList); err != nil {
+			var podsArr []corev1.Pod
+			if err := json.Unmarshal(collected, &podsArr); err != nil {
+				return nil, errors.Wrapf(err, "failed to unmarshal pods from %s", name)
+			}
+			pods = append(pods, podsArr...)
+		} else {
+			pods = append(pods, podList.Items...)
+		}
+	}
+
+	return pods, nil
+}
+
+// getCollectedEvents returns the collected events of namespaces, all namespaces if none are given
+func getCollectedEvents(findFiles func(string) (map[string][]byte, error), namespaces []string) ([]corev1.Event, error) {
+	files, err := findFiles(filepath.Join("cluster-resources", "events", "*.json"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read collected events")
+	}
+
+	events := []corev1.Event{}
+	for name, collected := range files {
+		if !includeCollectedNamespace(name, namespaces) {
+			continue
+		}
+		var eventList corev1.EventList
+		if err := json.Unmarshal(collected, &eventList); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal events from %s", name)
+		}
+		events = append(events, eventList.Items...)
+	}
+
+	return events, nil
+}
+
+// includeCollectedNamespace returns true if the namespace the file was collected for is one of namespaces
+func includeCollectedNamespace(name string, namespaces []string) bool {
+	if len(namespaces) == 0 {
+		return true
+	}
+	namespace := strings.TrimSuffix(filepath.Base(name), ".json")
+	for _, ns := range namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
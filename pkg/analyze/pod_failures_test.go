@@ -0,0 +1,272 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_findPodFailures(t *testing.T) {
+	pods := []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:         "app",
+					RestartCount: 7,
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason:  "CrashLoopBackOff",
+						Message: "back-off 5m0s restarting failed container=app pod=web",
+					}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}},
+				},
+				{
+					Name:         "cache",
+					RestartCount: 3,
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason: "CrashLoopBackOff",
+					}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}},
+				},
+				{Name: "sidecar", RestartCount: 1, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api"},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: "api",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason:  "ImagePullBackOff",
+						Message: `Back-off pulling image "example.com/api:1"`,
+					}},
+				},
+			}},
+		},
+	}
+	events := []corev1.Event{
+		{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "web", FieldPath: "spec.containers{app}"},
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+		},
+		{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "web", FieldPath: "spec.containers{sidecar}"},
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+		},
+		{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "jobs"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "migrate-abcde", FieldPath: "spec.initContainers{wait}"},
+			Reason:         "Failed",
+			Message:        `Failed to pull image "example.com/wait:1": rpc error: code = NotFound`,
+		},
+		{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "jobs"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "migrate-abcde", FieldPath: "spec.initContainers{wait}"},
+			Reason:         "Failed",
+			Message:        "Error: ErrImagePull",
+		},
+		{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "api"},
+			Reason:         "FailedScheduling",
+			Message:        "0/3 nodes are available",
+		},
+	}
+
+	assert.Equal(t, []PodFailure{
+		{
+			Namespace: "default",
+			Pod:       "api",
+			Container: "api",
+			Reason:    PodFailureImagePullBackOff,
+			Evidence:  `waiting with ImagePullBackOff: Back-off pulling image "example.com/api:1"`,
+		},
+		{
+			Namespace:    "default",
+			Pod:          "web",
+			Container:    "app",
+			Reason:       PodFailureCrashLoopBackOff,
+			RestartCount: 7,
+			Evidence:     "waiting with CrashLoopBackOff: back-off 5m0s restarting failed container=app pod=web, last terminated with Error, exit code 1",
+		},
+		{
+			Namespace:    "default",
+			Pod:          "web",
+			Container:    "cache",
+			Reason:       PodFailureOOMKilled,
+			RestartCount: 3,
+			Evidence:     "terminated with OOMKilled, exit code 137",
+		},
+		{
+			Namespace:    "default",
+			Pod:          "web",
+			Container:    "sidecar",
+			Reason:       PodFailureCrashLoopBackOff,
+			RestartCount: 1,
+			Evidence:     "event BackOff: Back-off restarting failed container",
+		},
+		{
+			Namespace: "jobs",
+			Pod:       "migrate-abcde",
+			Container: "wait",
+			Reason:    PodFailureImagePullBackOff,
+			Evidence:  "event Failed: Error: ErrImagePull",
+		},
+	}, findPodFailures(pods, events))
+}
+
+func Test_analyzePodFailures(t *testing.T) {
+	defaultPods := corev1.PodList{Items: []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:                 "app",
+					RestartCount:         4,
+					State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}},
+				},
+			}},
+		},
+	}}
+	kubeSystemPods := corev1.PodList{Items: []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "coredns"},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:         "coredns",
+					RestartCount: 12,
+					State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 5m0s"}},
+				},
+			}},
+		},
+	}}
+
+	files := map[string][]byte{}
+	for name, obj := range map[string]interface{}{
+		"cluster-resources/pods/default.json":     defaultPods,
+		"cluster-resources/pods/kube-system.json": kubeSystemPods,
+		"cluster-resources/events/default.json":   corev1.EventList{},
+	} {
+		b, err := json.Marshal(obj)
+		require.NoError(t, err)
+		files[name] = b
+	}
+	findFiles := func(n string) (map[string][]byte, error) {
+		matching := map[string][]byte{}
+		for name, file := range files {
+			if ok, _ := filepath.Match(n, name); ok {
+				matching[name] = file
+			}
+		}
+		return matching, nil
+	}
+
+	tests := []struct {
+		name         string
+		analyzer     troubleshootv1beta2.PodFailures
+		expectResult []*AnalyzeResult
+		expectErr    string
+	}{
+		{
+			name:     "default messages",
+			analyzer: troubleshootv1beta2.PodFailures{},
+			expectResult: []*AnalyzeResult{
+				{
+					Title:   "Pod Failures: default/web",
+					IconKey: "kubernetes_pod_failures",
+					IsFail:  true,
+					Message: "Container app of pod default/web was OOMKilled, it has restarted 4 times: terminated with OOMKilled, exit code 137",
+				},
+				{
+					Title:   "Pod Failures: kube-system/coredns",
+					IconKey: "kubernetes_pod_failures",
+					IsFail:  true,
+					Message: "Container coredns of pod kube-system/coredns is in CrashLoopBackOff, it has restarted 12 times: waiting with CrashLoopBackOff: back-off 5m0s",
+				},
+			},
+		},
+		{
+			name: "outcomes",
+			analyzer: troubleshootv1beta2.PodFailures{
+				AnalyzeMeta: troubleshootv1beta2.AnalyzeMeta{CheckName: "Pods"},
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{
+						Fail: &troubleshootv1beta2.SingleOutcome{
+							When:    "OOMKilled",
+							Message: "{{ .Container }} in {{ .Pod }} needs a higher memory limit",
+							URI:     "https://example.com/memory",
+						},
+					},
+					{
+						Warn: &troubleshootv1beta2.SingleOutcome{
+							Message: "{{ .Container }} in {{ .Pod }} is failing ({{ .Reason }}, {{ .RestartCount }} restarts)",
+						},
+					},
+				},
+			},
+			expectResult: []*AnalyzeResult{
+				{
+					Title:   "Pods: default/web",
+					IconKey: "kubernetes_pod_failures",
+					IsFail:  true,
+					Message: "app in web needs a higher memory limit",
+					URI:     "https://example.com/memory",
+				},
+				{
+					Title:   "Pods: kube-system/coredns",
+					IconKey: "kubernetes_pod_failures",
+					IsWarn:  true,
+					Message: "coredns in coredns is failing (CrashLoopBackOff, 12 restarts)",
+				},
+			},
+		},
+		{
+			name: "namespaces",
+			analyzer: troubleshootv1beta2.PodFailures{
+				Namespaces: []string{"monitoring"},
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{Pass: &troubleshootv1beta2.SingleOutcome{Message: "No failing pods"}},
+				},
+			},
+			expectResult: []*AnalyzeResult{
+				{
+					Title:   "Pod Failures",
+					IconKey: "kubernetes_pod_failures",
+					IsPass:  true,
+					Message: "No failing pods",
+				},
+			},
+		},
+		{
+			name: "unknown reason",
+			analyzer: troubleshootv1beta2.PodFailures{
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{Fail: &troubleshootv1beta2.SingleOutcome{When: "Evicted"}},
+				},
+			},
+			expectErr: `unknown pod failure reason "Evicted"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := analyzePodFailures(&tt.analyzer, findFiles)
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectResult, actual)
+		})
+	}
+}
@@ -17,6 +17,9 @@ func analyzePostgres(analyzer *troubleshootv1beta2.DatabaseAnalyze, getCollected
 	}
 
 	fullPath := path.Join("postgres", fmt.Sprintf("%s.json", collectorName))
+	if analyzer.FileName != "" {
+		fullPath = path.Join("postgres", analyzer.FileName)
+	}
 
 	collected, err := getCollectedFileContents(fullPath)
 	if err != nil {
@@ -0,0 +1,99 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_analyzePostgres(t *testing.T) {
+	outcomes := []*troubleshootv1beta2.Outcome{
+		{
+			Fail: &troubleshootv1beta2.SingleOutcome{
+				When:    "connected == false",
+				Message: "Cannot connect to postgres server",
+			},
+		},
+		{
+			Fail: &troubleshootv1beta2.SingleOutcome{
+				When:    "version < 10.x",
+				Message: "Postgres 10 or later is required",
+			},
+		},
+		{
+			Pass: &troubleshootv1beta2.SingleOutcome{
+				Message: "Postgres server is ready",
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		analyzer   troubleshootv1beta2.DatabaseAnalyze
+		connection collect.DatabaseConnection
+		filename   string
+		want       AnalyzeResult
+	}{
+		{
+			name:       "not connected",
+			analyzer:   troubleshootv1beta2.DatabaseAnalyze{CollectorName: "pg", Outcomes: outcomes},
+			connection: collect.DatabaseConnection{IsConnected: false, Error: "connection refused"},
+			filename:   "postgres/pg.json",
+			want: AnalyzeResult{
+				IsFail:  true,
+				Title:   "pg",
+				Message: "Cannot connect to postgres server connection refused",
+			},
+		},
+		{
+			name:       "old version",
+			analyzer:   troubleshootv1beta2.DatabaseAnalyze{CollectorName: "pg", Outcomes: outcomes},
+			connection: collect.DatabaseConnection{IsConnected: true, Version: "9.6.24"},
+			filename:   "postgres/pg.json",
+			want: AnalyzeResult{
+				IsFail:  true,
+				Title:   "pg",
+				Message: "Postgres 10 or later is required",
+			},
+		},
+		{
+			name: "file name",
+			analyzer: troubleshootv1beta2.DatabaseAnalyze{
+				AnalyzeMeta: troubleshootv1beta2.AnalyzeMeta{CheckName: "Postgres"},
+				FileName:    "primary.json",
+				Outcomes:    outcomes,
+			},
+			connection: collect.DatabaseConnection{IsConnected: true, Version: "14.5"},
+			filename:   "postgres/primary.json",
+			want: AnalyzeResult{
+				IsPass:  true,
+				Title:   "Postgres",
+				Message: "Postgres server is ready",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := require.New(t)
+
+			b, err := json.Marshal(test.connection)
+			req.NoError(err)
+			getFile := func(filename string) ([]byte, error) {
+				req.Equal(test.filename, filename)
+				return b, nil
+			}
+
+			result, err := analyzePostgres(&test.analyzer, getFile)
+			req.NoError(err)
+
+			test.want.IconKey = "kubernetes_postgres_analyze"
+			test.want.IconURI = "https://troubleshoot.sh/images/analyzer-icons/postgres-analyze.svg"
+			assert.Equal(t, test.want, *result)
+		})
+	}
+}
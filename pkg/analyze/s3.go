@@ -0,0 +1,141 @@
+package analyzer
+
+import (
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/pkg/errors"
+)
+
+// s3MaxRetries is how many times in a row a dropped download is resumed before giving up
+const s3MaxRetries = 5
+
+// IsS3URL returns whether bundleURL is an archive in object storage, e.g. s3://bucket/bundle.tar.gz
+func IsS3URL(bundleURL string) bool {
+	return strings.HasPrefix(bundleURL, "s3://")
+}
+
+// OpenS3Bundle streams the archive at an s3://bucket/key url, so that it can be extracted without
+// downloading it first. Credentials are read from the environment or the shared AWS config. For MinIO
+// or other S3 compatible servers, the url can have the server in an endpoint parameter, e.g.
+// s3://bundles/bundle.tar.gz?endpoint=http://minio.minio.svc:9000, and a region parameter sets the region.
+//
+// A download that is dropped is resumed from where it stopped with a range request.
+func OpenS3Bundle(bundleURL string) (io.ReadCloser, error) {
+	u, err := url.Parse(bundleURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse url")
+	}
+	bucket, key := u.Host, strings.TrimPrefix(u.Path, "/")
+	if bucket == "" || key == "" {
+		return nil, errors.Errorf("%s is not an s3://bucket/key url", bundleURL)
+	}
+
+	config := aws.NewConfig()
+	if endpoint := u.Query().Get("endpoint"); endpoint != "" {
+		config = config.WithEndpoint(endpoint).WithS3ForcePathStyle(true)
+		// S3 compatible servers ignore the region, but the signature needs one
+		config = config.WithRegion("us-east-1")
+	}
+	if region := u.Query().Get("region"); region != "" {
+		config = config.WithRegion(region)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *config,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create session")
+	}
+
+	return newS3RangeReader(s3.New(sess), bucket, key), nil
+}
+
+// s3RangeReader reads an object from its start, and reopens it at the current offset when a read fails
+type s3RangeReader struct {
+	client s3iface.S3API
+	bucket string
+	key    string
+
+	body   io.ReadCloser
+	offset int64
+	// etag is of the object when it was first opened, so that an object that was replaced is not resumed
+	etag     string
+	failures int
+}
+
+func newS3RangeReader(client s3iface.S3API, bucket, key string) *s3RangeReader {
+	return &s3RangeReader{
+		client: client,
+		bucket: bucket,
+		key:    key,
+	}
+}
+
+func (r *s3RangeReader) open() error {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(r.key),
+	}
+	if r.offset > 0 {
+		input.Range = aws.String("bytes=" + strconv.FormatInt(r.offset, 10) + "-")
+	}
+	if r.etag != "" {
+		input.IfMatch = aws.String(r.etag)
+	}
+
+	output, err := r.client.GetObject(input)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get s3://%s/%s", r.bucket, r.key)
+	}
+	if r.etag == "" {
+		r.etag = aws.StringValue(output.ETag)
+	}
+	r.body = output.Body
+	return nil
+}
+
+func (r *s3RangeReader) Read(p []byte) (int, error) {
+	for {
+		if r.body == nil {
+			if err := r.open(); err != nil {
+				return 0, err
+			}
+		}
+
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if err == nil || err == io.EOF {
+			if n > 0 {
+				r.failures = 0
+			}
+			return n, err
+		}
+
+		r.body.Close()
+		r.body = nil
+		r.failures++
+		if r.failures > s3MaxRetries {
+			return n, errors.Wrapf(err, "failed to read s3://%s/%s", r.bucket, r.key)
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func (r *s3RangeReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
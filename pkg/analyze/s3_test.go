@@ -0,0 +1,102 @@
+package analyzer
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenS3Bundle(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "id")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	archive := bytes.Buffer{}
+	require.NoError(t, writeTestBundle(&archive, map[string]string{
+		"bundle/version.yaml":                        "apiVersion: troubleshoot.sh/v1beta2\n",
+		"bundle/cluster-resources/pods/default.json": strings.Repeat(`{"items": []}`, 1000),
+	}))
+	contents := archive.Bytes()
+
+	ranges := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/bundles/cluster-1/bundle.tar.gz", r.URL.Path)
+		ranges = append(ranges, r.Header.Get("Range"))
+
+		offset := 0
+		if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+			assert.Equal(t, `"v1"`, r.Header.Get("If-Match"))
+			offset, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rangeHeader, "bytes="), "-"))
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, len(contents)-1, len(contents)))
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Length", strconv.Itoa(len(contents)-offset))
+		if offset > 0 {
+			w.WriteHeader(http.StatusPartialContent)
+		}
+
+		// the first download is dropped half way
+		if len(ranges) == 1 {
+			w.Write(contents[:len(contents)/2])
+			w.(http.Flusher).Flush()
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			conn.Close()
+			return
+		}
+		w.Write(contents[offset:])
+	}))
+	defer server.Close()
+
+	r, err := OpenS3Bundle("s3://bundles/cluster-1/bundle.tar.gz?endpoint=" + server.URL)
+	require.NoError(t, err)
+	defer r.Close()
+
+	destDir := t.TempDir()
+	require.NoError(t, ExtractTroubleshootBundle(r, destDir))
+	assert.Equal(t, []string{"", fmt.Sprintf("bytes=%d-", len(contents)/2)}, ranges)
+
+	b, err := ioutil.ReadFile(filepath.Join(destDir, "bundle/version.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: troubleshoot.sh/v1beta2\n", string(b))
+}
+
+func TestOpenS3Bundle_InvalidURL(t *testing.T) {
+	_, err := OpenS3Bundle("s3://bundles")
+	assert.Error(t, err)
+}
+
+func TestIsS3URL(t *testing.T) {
+	assert.True(t, IsS3URL("s3://bundles/bundle.tar.gz"))
+	assert.False(t, IsS3URL("https://bundles.s3.amazonaws.com/bundle.tar.gz"))
+	assert.False(t, IsS3URL("/tmp/bundle.tar.gz"))
+}
+
+func writeTestBundle(w io.Writer, files map[string]string) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, contents := range files {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(contents)), Typeflag: tar.TypeReg}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tarWriter.Write([]byte(contents)); err != nil {
+			return err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
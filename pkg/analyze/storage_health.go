@@ -0,0 +1,326 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"text/template"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+// StorageIssueReason is what is wrong with storage, and is what the when of a storageHealth outcome matches
+type StorageIssueReason string
+
+const (
+	StorageIssuePendingPVC            StorageIssueReason = "PendingPVC"
+	StorageIssueLostPVC               StorageIssueReason = "LostPVC"
+	StorageIssueNoDefaultStorageClass StorageIssueReason = "NoDefaultStorageClass"
+	StorageIssueProvisionerMismatch   StorageIssueReason = "ProvisionerMismatch"
+)
+
+const (
+	// selectedNodeAnnotation is set on the claims of WaitForFirstConsumer storage classes when a pod that
+	// uses them is scheduled
+	selectedNodeAnnotation = "volume.kubernetes.io/selected-node"
+	// migratedToAnnotation is the csi driver of claims and volumes of in-tree provisioners that were
+	// migrated to csi, which provisions them instead of the provisioner of their storage class
+	migratedToAnnotation    = "pv.kubernetes.io/migrated-to"
+	provisionedByAnnotation = "pv.kubernetes.io/provisioned-by"
+)
+
+// storageProvisionerAnnotations are where the provisioner that provisions a claim is
+var storageProvisionerAnnotations = []string{
+	"volume.kubernetes.io/storage-provisioner",
+	"volume.beta.kubernetes.io/storage-provisioner",
+}
+
+// StorageIssue is what the messages of a storageHealth analyzer are templated with. The claim fields are
+// empty for NoDefaultStorageClass.
+type StorageIssue struct {
+	Reason       StorageIssueReason
+	Namespace    string
+	PVC          string
+	StorageClass string
+	Volume       string
+	// Provisioner is the provisioner of the storage class
+	Provisioner string
+	// Evidence is why the issue was found
+	Evidence string
+}
+
+func analyzeStorageHealth(analyzer *troubleshootv1beta2.StorageHealth, getFile getCollectedFileContents, findFiles getChildCollectedFileContents) ([]*AnalyzeResult, error) {
+	title := analyzer.CheckName
+	if title == "" {
+		title = "Storage Health"
+	}
+
+	for _, outcome := range analyzer.Outcomes {
+		for _, single := range []*troubleshootv1beta2.SingleOutcome{outcome.Fail, outcome.Warn} {
+			if single != nil && single.When != "" && !isStorageIssueReason(StorageIssueReason(single.When)) {
+				return nil, errors.Errorf("unknown storage issue reason %q", single.When)
+			}
+		}
+	}
+
+	dir := "storage"
+	if analyzer.CollectorName != "" {
+		dir = path.Join(dir, analyzer.CollectorName)
+	}
+
+	storageClasses := storagev1.StorageClassList{}
+	if err := unmarshalCollectedFile(getFile, path.Join(dir, collect.StorageClassesFilename), &storageClasses); err != nil {
+		return nil, err
+	}
+	pvs := corev1.PersistentVolumeList{}
+	if err := unmarshalCollectedFile(getFile, path.Join(dir, collect.PersistentVolumesFilename), &pvs); err != nil {
+		return nil, err
+	}
+	pvcs, err := getCollectedPVCs(findFiles, dir, analyzer.Namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := findStorageIssues(storageClasses.Items, pvs.Items, pvcs)
+
+	if len(issues) == 0 {
+		result := &AnalyzeResult{
+			Title:   title,
+			IconKey: "kubernetes_storage_class",
+			IconURI: "https://troubleshoot.sh/images/analyzer-icons/storage-class.svg?w=12&h=12",
+			IsPass:  true,
+			Message: fmt.Sprintf("A storage class is the default, and all %d persistent volume claims are healthy", len(pvcs)),
+		}
+		for _, outcome := range analyzer.Outcomes {
+			if outcome.Pass != nil {
+				result.Message = outcome.Pass.Message
+				result.URI = outcome.Pass.URI
+				break
+			}
+		}
+		return []*AnalyzeResult{result}, nil
+	}
+
+	results := []*AnalyzeResult{}
+	for _, issue := range issues {
+		result, err := storageIssueResult(analyzer, title, issue)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func storageIssueResult(analyzer *troubleshootv1beta2.StorageHealth, title string, issue StorageIssue) (*AnalyzeResult, error) {
+	if issue.PVC != "" {
+		title = fmt.Sprintf("%s: %s/%s", title, issue.Namespace, issue.PVC)
+	}
+	result := &AnalyzeResult{
+		Title:   title,
+		IconKey: "kubernetes_storage_class",
+		IconURI: "https://troubleshoot.sh/images/analyzer-icons/storage-class.svg?w=12&h=12",
+		IsFail:  true,
+		Message: defaultStorageIssueMessage(issue),
+	}
+
+	// ordering from the spec is important, the first one that matches returns
+	for _, outcome := range analyzer.Outcomes {
+		single := outcome.Fail
+		if single == nil {
+			single = outcome.Warn
+		}
+		if single == nil || (single.When != "" && StorageIssueReason(single.When) != issue.Reason) {
+			continue
+		}
+
+		tmpl, err := template.New("storage").Parse(single.Message)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create new message template")
+		}
+		var m bytes.Buffer
+		if err := tmpl.Execute(&m, issue); err != nil {
+			return nil, errors.Wrap(err, "failed to execute template")
+		}
+
+		result.IsFail = outcome.Fail != nil
+		result.IsWarn = outcome.Fail == nil
+		result.Message = m.String()
+		result.URI = single.URI
+		break
+	}
+
+	return result, nil
+}
+
+func defaultStorageIssueMessage(issue StorageIssue) string {
+	switch issue.Reason {
+	case StorageIssueNoDefaultStorageClass:
+		return fmt.Sprintf("No storage class is the default: %s", issue.Evidence)
+	case StorageIssuePendingPVC:
+		return fmt.Sprintf("Persistent volume claim %s/%s is Pending: %s", issue.Namespace, issue.PVC, issue.Evidence)
+	case StorageIssueLostPVC:
+		return fmt.Sprintf("Persistent volume claim %s/%s is Lost: %s", issue.Namespace, issue.PVC, issue.Evidence)
+	default:
+		return fmt.Sprintf("Persistent volume claim %s/%s was not provisioned by %s, the provisioner of storage class %s: %s", issue.Namespace, issue.PVC, issue.Provisioner, issue.StorageClass, issue.Evidence)
+	}
+}
+
+// findStorageIssues returns NoDefaultStorageClass if no storage class is the default, then the issues of
+// the claims, sorted by claim. A claim has at most one issue.
+//
+// Claims of WaitForFirstConsumer storage classes are Pending until a pod that uses them is scheduled, and
+// are only an issue once one has been.
+func findStorageIssues(storageClasses []storagev1.StorageClass, pvs []corev1.PersistentVolume, pvcs []corev1.PersistentVolumeClaim) []StorageIssue {
+	issues := []StorageIssue{}
+
+	classesByName := map[string]storagev1.StorageClass{}
+	hasDefault := false
+	for _, storageClass := range storageClasses {
+		classesByName[storageClass.Name] = storageClass
+		if collect.IsDefaultStorageClass(storageClass.ObjectMeta) {
+			hasDefault = true
+		}
+	}
+	if !hasDefault {
+		evidence := "there are no storage classes"
+		if len(storageClasses) > 0 {
+			evidence = fmt.Sprintf("none of the %d storage classes has the storageclass.kubernetes.io/is-default-class annotation", len(storageClasses))
+		}
+		issues = append(issues, StorageIssue{Reason: StorageIssueNoDefaultStorageClass, Evidence: evidence})
+	}
+
+	pvsByName := map[string]corev1.PersistentVolume{}
+	for _, pv := range pvs {
+		pvsByName[pv.Name] = pv
+	}
+
+	sorted := make([]corev1.PersistentVolumeClaim, len(pvcs))
+	copy(sorted, pvcs)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Namespace != sorted[j].Namespace {
+			return sorted[i].Namespace < sorted[j].Namespace
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	for _, pvc := range sorted {
+		issue := StorageIssue{
+			Namespace: pvc.Namespace,
+			PVC:       pvc.Name,
+			Volume:    pvc.Spec.VolumeName,
+		}
+		if pvc.Spec.StorageClassName != nil {
+			issue.StorageClass = *pvc.Spec.StorageClassName
+		}
+		storageClass, hasClass := classesByName[issue.StorageClass]
+		if hasClass {
+			issue.Provisioner = storageClass.Provisioner
+		}
+
+		switch pvc.Status.Phase {
+		case corev1.ClaimPending:
+			if hasClass && storageClass.VolumeBindingMode != nil && *storageClass.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer &&
+				pvc.Annotations[selectedNodeAnnotation] == "" {
+				continue
+			}
+			issue.Reason = StorageIssuePendingPVC
+			switch {
+			case issue.StorageClass == "":
+				issue.Evidence = "it has no storage class, and no persistent volume matches it"
+			case !hasClass:
+				issue.Evidence = fmt.Sprintf("storage class %s does not exist", issue.StorageClass)
+			default:
+				issue.Evidence = fmt.Sprintf("waiting for %s to provision a volume", storageClass.Provisioner)
+			}
+			issues = append(issues, issue)
+		case corev1.ClaimLost:
+			issue.Reason = StorageIssueLostPVC
+			issue.Evidence = fmt.Sprintf("volume %s does not exist any more", pvc.Spec.VolumeName)
+			issues = append(issues, issue)
+		case corev1.ClaimBound:
+			if !hasClass {
+				continue
+			}
+			if evidence, ok := provisionerMismatch(storageClass, pvc, pvsByName[pvc.Spec.VolumeName]); ok {
+				issue.Reason = StorageIssueProvisionerMismatch
+				issue.Evidence = evidence
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	return issues
+}
+
+// provisionerMismatch returns why a claim, or the volume it is bound to, was provisioned by a different
+// provisioner than the one of its storage class. Volumes that were provisioned by the csi driver that their
+// in-tree provisioner was migrated to are not mismatched.
+func provisionerMismatch(storageClass storagev1.StorageClass, pvc corev1.PersistentVolumeClaim, pv corev1.PersistentVolume) (string, bool) {
+	matches := func(provisioner string, annotations map[string]string) bool {
+		return provisioner == storageClass.Provisioner || provisioner == annotations[migratedToAnnotation]
+	}
+
+	for _, annotation := range storageProvisionerAnnotations {
+		if provisioner := pvc.Annotations[annotation]; provisioner != "" && !matches(provisioner, pvc.Annotations) {
+			return fmt.Sprintf("the claim has annotation %s=%s", annotation, provisioner), true
+		}
+	}
+	if provisioner := pv.Annotations[provisionedByAnnotation]; provisioner != "" && !matches(provisioner, pv.Annotations) {
+		return fmt.Sprintf("volume %s was provisioned by %s", pv.Name, provisioner), true
+	}
+	if pv.Spec.CSI != nil && !matches(pv.Spec.CSI.Driver, pv.Annotations) && pv.Annotations[provisionedByAnnotation] == "" {
+		return fmt.Sprintf("volume %s is a volume of csi driver %s", pv.Name, pv.Spec.CSI.Driver), true
+	}
+	return "", false
+}
+
+func isStorageIssueReason(reason StorageIssueReason) bool {
+	switch reason {
+	case StorageIssuePendingPVC, StorageIssueLostPVC, StorageIssueNoDefaultStorageClass, StorageIssueProvisionerMismatch:
+		return true
+	}
+	return false
+}
+
+func unmarshalCollectedFile(getFile getCollectedFileContents, name string, v interface{}) error {
+	b, err := getFile(name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read collected %s", name)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal %s", name)
+	}
+	return nil
+}
+
+// getCollectedPVCs returns the claims that a storage collector collected into dir, of namespaces, all
+// namespaces if none are given
+func getCollectedPVCs(findFiles getChildCollectedFileContents, dir string, namespaces []string) ([]corev1.PersistentVolumeClaim, error) {
+	files, err := findFiles(filepath.Join(dir, "pvcs", "*.json"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read collected persistent volume claims")
+	}
+
+	pvcs := []corev1.PersistentVolumeClaim{}
+	for name, collected := range files {
+		if !includeCollectedNamespace(name, namespaces) {
+			continue
+		}
+		var pvcList corev1.PersistentVolumeClaimList
+		if err := json.Unmarshal(collected, &pvcList); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal persistent volume claims from %s", name)
+		}
+		pvcs = append(pvcs, pvcList.Items...)
+	}
+
+	return pvcs, nil
+}
@@ -0,0 +1,256 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func Test_findStorageIssues(t *testing.T) {
+	waitForFirstConsumer := storagev1.VolumeBindingWaitForFirstConsumer
+	storageClasses := []storagev1.StorageClass{
+		{ObjectMeta: metav1.ObjectMeta{Name: "standard"}, Provisioner: "kubernetes.io/aws-ebs"},
+		{ObjectMeta: metav1.ObjectMeta{Name: "local"}, Provisioner: "rancher.io/local-path", VolumeBindingMode: &waitForFirstConsumer},
+	}
+	pvs := []corev1.PersistentVolume{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pvc-migrated", Annotations: map[string]string{
+				"pv.kubernetes.io/provisioned-by": "ebs.csi.aws.com",
+				"pv.kubernetes.io/migrated-to":    "ebs.csi.aws.com",
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pvc-restored"},
+			Spec: corev1.PersistentVolumeSpec{PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: "disk.csi.azure.com"},
+			}},
+		},
+	}
+	pvcs := []corev1.PersistentVolumeClaim{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "waiting"},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.String("local"), VolumeName: "pvc-waiting"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "scheduled", Annotations: map[string]string{"volume.kubernetes.io/selected-node": "node-1"}},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.String("local"), VolumeName: "pvc-scheduled"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "missing-class"},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.String("fast"), VolumeName: "pvc-missing-class"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "lost"},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.String("standard"), VolumeName: "pvc-lost"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimLost},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "migrated"},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.String("standard"), VolumeName: "pvc-migrated"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "restored"},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.String("standard"), VolumeName: "pvc-restored"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "annotated", Annotations: map[string]string{"volume.beta.kubernetes.io/storage-provisioner": "example.com/nfs"}},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.String("standard"), VolumeName: "pvc-annotated"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		},
+	}
+
+	issues := findStorageIssues(storageClasses, pvs, pvcs)
+	assert.Equal(t, []StorageIssue{
+		{
+			Reason:   StorageIssueNoDefaultStorageClass,
+			Evidence: "none of the 2 storage classes has the storageclass.kubernetes.io/is-default-class annotation",
+		},
+		{
+			Reason:       StorageIssueProvisionerMismatch,
+			Namespace:    "app",
+			PVC:          "annotated",
+			StorageClass: "standard",
+			Volume:       "pvc-annotated",
+			Provisioner:  "kubernetes.io/aws-ebs",
+			Evidence:     "the claim has annotation volume.beta.kubernetes.io/storage-provisioner=example.com/nfs",
+		},
+		{
+			Reason:       StorageIssueLostPVC,
+			Namespace:    "app",
+			PVC:          "lost",
+			StorageClass: "standard",
+			Volume:       "pvc-lost",
+			Provisioner:  "kubernetes.io/aws-ebs",
+			Evidence:     "volume pvc-lost does not exist any more",
+		},
+		{
+			Reason:       StorageIssueProvisionerMismatch,
+			Namespace:    "app",
+			PVC:          "restored",
+			StorageClass: "standard",
+			Volume:       "pvc-restored",
+			Provisioner:  "kubernetes.io/aws-ebs",
+			Evidence:     "volume pvc-restored is a volume of csi driver disk.csi.azure.com",
+		},
+		{
+			Reason:       StorageIssuePendingPVC,
+			Namespace:    "default",
+			PVC:          "missing-class",
+			StorageClass: "fast",
+			Volume:       "pvc-missing-class",
+			Evidence:     "storage class fast does not exist",
+		},
+		{
+			Reason:       StorageIssuePendingPVC,
+			Namespace:    "default",
+			PVC:          "scheduled",
+			StorageClass: "local",
+			Volume:       "pvc-scheduled",
+			Provisioner:  "rancher.io/local-path",
+			Evidence:     "waiting for rancher.io/local-path to provision a volume",
+		},
+	}, issues)
+}
+
+func Test_analyzeStorageHealth(t *testing.T) {
+	files := map[string][]byte{}
+	for name, obj := range map[string]interface{}{
+		"storage/app/storage-classes.json": storagev1.StorageClassList{Items: []storagev1.StorageClass{
+			{
+				ObjectMeta:  metav1.ObjectMeta{Name: "standard", Annotations: map[string]string{"storageclass.kubernetes.io/is-default-class": "true"}},
+				Provisioner: "ebs.csi.aws.com",
+			},
+		}},
+		"storage/app/persistent-volumes.json": corev1.PersistentVolumeList{},
+		"storage/app/pvcs/default.json": corev1.PersistentVolumeClaimList{Items: []corev1.PersistentVolumeClaim{
+			{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "data-web-0"},
+				Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.String("standard"), VolumeName: "pvc-data-web-0"},
+				Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+			},
+		}},
+		"storage/app/pvcs/monitoring.json": corev1.PersistentVolumeClaimList{Items: []corev1.PersistentVolumeClaim{
+			{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "monitoring", Name: "prometheus"},
+				Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.String("standard"), VolumeName: "pvc-prometheus"},
+				Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+			},
+		}},
+	} {
+		b, err := json.Marshal(obj)
+		require.NoError(t, err)
+		files[name] = b
+	}
+	getFile := func(n string) ([]byte, error) {
+		return files[n], nil
+	}
+	findFiles := func(n string) (map[string][]byte, error) {
+		matching := map[string][]byte{}
+		for name, file := range files {
+			if ok, _ := filepath.Match(n, name); ok {
+				matching[name] = file
+			}
+		}
+		return matching, nil
+	}
+
+	tests := []struct {
+		name         string
+		analyzer     troubleshootv1beta2.StorageHealth
+		expectResult []*AnalyzeResult
+		expectErr    string
+	}{
+		{
+			name:     "default messages",
+			analyzer: troubleshootv1beta2.StorageHealth{CollectorName: "app"},
+			expectResult: []*AnalyzeResult{
+				{
+					Title:   "Storage Health: default/data-web-0",
+					IconKey: "kubernetes_storage_class",
+					IconURI: "https://troubleshoot.sh/images/analyzer-icons/storage-class.svg?w=12&h=12",
+					IsFail:  true,
+					Message: "Persistent volume claim default/data-web-0 is Pending: waiting for ebs.csi.aws.com to provision a volume",
+				},
+			},
+		},
+		{
+			name: "outcomes",
+			analyzer: troubleshootv1beta2.StorageHealth{
+				AnalyzeMeta:   troubleshootv1beta2.AnalyzeMeta{CheckName: "Volumes"},
+				CollectorName: "app",
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{
+						Warn: &troubleshootv1beta2.SingleOutcome{
+							When:    "PendingPVC",
+							Message: "{{ .PVC }} of {{ .StorageClass }} is not bound yet",
+							URI:     "https://example.com/storage",
+						},
+					},
+				},
+			},
+			expectResult: []*AnalyzeResult{
+				{
+					Title:   "Volumes: default/data-web-0",
+					IconKey: "kubernetes_storage_class",
+					IconURI: "https://troubleshoot.sh/images/analyzer-icons/storage-class.svg?w=12&h=12",
+					IsWarn:  true,
+					Message: "data-web-0 of standard is not bound yet",
+					URI:     "https://example.com/storage",
+				},
+			},
+		},
+		{
+			name: "namespaces",
+			analyzer: troubleshootv1beta2.StorageHealth{
+				CollectorName: "app",
+				Namespaces:    []string{"monitoring"},
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{Pass: &troubleshootv1beta2.SingleOutcome{Message: "Storage is healthy"}},
+				},
+			},
+			expectResult: []*AnalyzeResult{
+				{
+					Title:   "Storage Health",
+					IconKey: "kubernetes_storage_class",
+					IconURI: "https://troubleshoot.sh/images/analyzer-icons/storage-class.svg?w=12&h=12",
+					IsPass:  true,
+					Message: "Storage is healthy",
+				},
+			},
+		},
+		{
+			name: "unknown reason",
+			analyzer: troubleshootv1beta2.StorageHealth{
+				CollectorName: "app",
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{Fail: &troubleshootv1beta2.SingleOutcome{When: "Full"}},
+				},
+			},
+			expectErr: `unknown storage issue reason "Full"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := analyzeStorageHealth(&tt.analyzer, getFile, findFiles)
+			if tt.expectErr != "" {
+				assert.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectResult, results)
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -122,35 +123,88 @@ func evalSysctlOutcome(nodeParams map[string]map[string]string, outcome *trouble
 	return result, nil
 }
 
-// Example: net.ipv4.ip_forward = 0
-var sysctlWhenRX = regexp.MustCompile(`([^\s]+)\s+(=+)\s+(.+)`)
+// Example: net.ipv4.ip_forward = 0, vm.max_map_count < 262144
+var sysctlWhenRX = regexp.MustCompile(`^([^\s]+)\s+(=+|!=+|<=|>=|<|>)\s+(.+)$`)
+
+// Example: net.bridge.bridge-nf-call-iptables unset
+var sysctlUnsetRX = regexp.MustCompile(`^([^\s]+)\s+unset$`)
 
 // Returns the list of node names the condition is true for. The condition is not considered true
-// if the parameter is missing for the node.
+// if the parameter is missing for the node, unless it is an unset condition. The <, <=, > and >=
+// operators compare integers.
 func evalSysctlWhen(nodeParams map[string]map[string]string, when string) ([]string, error) {
+	when = strings.TrimSpace(when)
+
+	if matches := sysctlUnsetRX.FindStringSubmatch(when); matches != nil {
+		nodes := []string{}
+		for nodeName, params := range nodeParams {
+			if _, ok := params[matches[1]]; !ok {
+				nodes = append(nodes, nodeName)
+			}
+		}
+
+		sort.Strings(nodes)
+
+		return nodes, nil
+	}
+
 	matches := sysctlWhenRX.FindStringSubmatch(when)
 	if len(matches) != 4 {
 		return nil, fmt.Errorf("Failed to parse when %q", when)
 	}
+	parameter, operator, expected := matches[1], matches[2], strings.TrimSpace(matches[3])
 
-	switch matches[2] {
-	case "=", "==", "===":
-		var nodes []string
+	var compare func(nodeValue string) bool
 
-		for nodeName, params := range nodeParams {
-			nodeValue, ok := params[matches[1]]
-			if !ok {
-				continue
+	switch operator {
+	case "=", "==", "===":
+		compare = func(nodeValue string) bool {
+			return nodeValue == expected
+		}
+	case "!=", "!==":
+		compare = func(nodeValue string) bool {
+			return nodeValue != expected
+		}
+	case "<", "<=", ">", ">=":
+		expectedInt, err := strconv.ParseInt(expected, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse %q in when %q as an integer", expected, when)
+		}
+		compare = func(nodeValue string) bool {
+			// values that are not a single integer, such as net.ipv4.ip_local_port_range, are never
+			// less or greater
+			nodeInt, err := strconv.ParseInt(nodeValue, 10, 64)
+			if err != nil {
+				return false
 			}
-			if nodeValue == strings.TrimSpace(matches[3]) {
-				nodes = append(nodes, nodeName)
+			switch operator {
+			case "<":
+				return nodeInt < expectedInt
+			case "<=":
+				return nodeInt <= expectedInt
+			case ">":
+				return nodeInt > expectedInt
+			default:
+				return nodeInt >= expectedInt
 			}
 		}
+	default:
+		return nil, fmt.Errorf("Unknown operator %q", operator)
+	}
 
-		sort.Strings(nodes)
+	var nodes []string
 
-		return nodes, nil
-	default:
-		return nil, fmt.Errorf("Unknown operator %q", matches[2])
+	for nodeName, params := range nodeParams {
+		nodeValue, ok := params[parameter]
+		if !ok {
+			continue
+		}
+		if compare(nodeValue) {
+			nodes = append(nodes, nodeName)
+		}
 	}
+
+	sort.Strings(nodes)
+
+	return nodes, nil
 }
@@ -69,6 +69,64 @@ func TestEvalSysctlWhen(t *testing.T) {
 			expect:    []string{"node-b"},
 			expectErr: false,
 		},
+		{
+			name: "One node has vm.max_map_count below the minimum",
+			when: "vm.max_map_count < 262144",
+			nodeParams: map[string]map[string]string{
+				"node-a": {"vm.max_map_count": "65530"},
+				"node-b": {"vm.max_map_count": "262144"},
+				"node-c": {},
+			},
+			expect:    []string{"node-a"},
+			expectErr: false,
+		},
+		{
+			name: "Nodes with fs.inotify.max_user_watches at least the minimum",
+			when: "fs.inotify.max_user_watches >= 524288",
+			nodeParams: map[string]map[string]string{
+				"node-a": {"fs.inotify.max_user_watches": "8192"},
+				"node-b": {"fs.inotify.max_user_watches": "524288"},
+			},
+			expect:    []string{"node-b"},
+			expectErr: false,
+		},
+		{
+			name: "Values that are not integers are never less",
+			when: "net.ipv4.ip_local_port_range < 40000",
+			nodeParams: map[string]map[string]string{
+				"node-a": {"net.ipv4.ip_local_port_range": "32768 60999"},
+			},
+			expect:    []string{},
+			expectErr: false,
+		},
+		{
+			name: "One node has IP forwarding not enabled",
+			when: "net.ipv4.ip_forward != 1",
+			nodeParams: map[string]map[string]string{
+				"node-a": {"net.ipv4.ip_forward": "1"},
+				"node-b": {"net.ipv4.ip_forward": "0"},
+			},
+			expect:    []string{"node-b"},
+			expectErr: false,
+		},
+		{
+			name: "One node does not have br_netfilter loaded",
+			when: "net.bridge.bridge-nf-call-iptables unset",
+			nodeParams: map[string]map[string]string{
+				"node-a": {"net.bridge.bridge-nf-call-iptables": "1"},
+				"node-b": {"net.ipv4.ip_forward": "1"},
+			},
+			expect:    []string{"node-b"},
+			expectErr: false,
+		},
+		{
+			name: "Integer comparison with a value that is not an integer",
+			when: "vm.max_map_count > many",
+			nodeParams: map[string]map[string]string{
+				"node-a": {"vm.max_map_count": "65530"},
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, test := range tests {
@@ -11,13 +11,23 @@ import (
 
 	"github.com/pkg/errors"
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
 )
 
 func analyzeTextAnalyze(analyzer *troubleshootv1beta2.TextAnalyze, getCollectedFileContents func(string) (map[string][]byte, error)) ([]*AnalyzeResult, error) {
 	fullPath := filepath.Join(analyzer.CollectorName, analyzer.FileName)
-	collected, err := getCollectedFileContents(fullPath)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to read collected file name: %s", fullPath)
+	var collected map[string][]byte
+	var err error
+	if analyzer.ContentType != "" {
+		collected, err = getContentTypeFileContents(analyzer.ContentType, fullPath, getCollectedFileContents)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read collected files of type %s", analyzer.ContentType)
+		}
+	} else {
+		collected, err = getCollectedFileContents(fullPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read collected file name: %s", fullPath)
+		}
 	}
 
 	checkName := analyzer.CheckName
@@ -82,6 +92,43 @@ func analyzeTextAnalyze(analyzer *troubleshootv1beta2.TextAnalyze, getCollectedF
 	}, nil
 }
 
+// getContentTypeFileContents reads the files that were tagged with contentType when they were collected.
+// If pattern is not empty, only the tagged files that match it are read.
+func getContentTypeFileContents(contentType string, pattern string, getCollectedFileContents func(string) (map[string][]byte, error)) (map[string][]byte, error) {
+	metadata, err := getCollectedFileContents(collect.CollectionMetadataFilename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read collection metadata")
+	}
+
+	// file contents providers key files by their path on disk, which can include the bundle directory
+	contentTypes := collect.ContentTypes{}
+	for _, contents := range metadata {
+		contentTypes = collect.ContentTypesFromMetadata(contents)
+	}
+
+	collected := map[string][]byte{}
+	for path, pathContentType := range contentTypes {
+		if string(pathContentType) != contentType {
+			continue
+		}
+		if pattern != "" && pattern != "." {
+			if matched, _ := filepath.Match(pattern, path); !matched && !strings.HasPrefix(path, pattern+"/") {
+				continue
+			}
+		}
+
+		files, err := getCollectedFileContents(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read collected file name: %s", path)
+		}
+		for _, contents := range files {
+			collected[path] = contents
+		}
+	}
+
+	return collected, nil
+}
+
 func analyzeRegexPattern(pattern string, collected []byte, outcomes []*troubleshootv1beta2.Outcome, checkName string) (*AnalyzeResult, error) {
 	re, err := regexp.Compile(pattern)
 	if err != nil {
@@ -582,6 +582,46 @@ func Test_textAnalyze(t *testing.T) {
 				"text-collector-templated-regex-message/cfile-1.txt": []byte(`{"level":"ERROR","timestamp":"2022-05-17T20:37:41Z","caller":"controller/controller.go:317","message":"Reconciler error","context":{"name":"insert-cr-name-here","namespace":"default","error":"myerror"}}`),
 			},
 		},
+		{
+			name: "content type",
+			analyzer: troubleshootv1beta2.TextAnalyze{
+				AnalyzeMeta: troubleshootv1beta2.AnalyzeMeta{
+					CheckName: "pod logs",
+				},
+				Outcomes: []*troubleshootv1beta2.Outcome{
+					{
+						Pass: &troubleshootv1beta2.SingleOutcome{
+							When:    "false",
+							Message: "no panics",
+						},
+					},
+					{
+						Fail: &troubleshootv1beta2.SingleOutcome{
+							When:    "true",
+							Message: "panic in logs",
+						},
+					},
+				},
+				CollectorName: "app",
+				ContentType:   "pod-log",
+				RegexPattern:  "panic",
+			},
+			expectResult: []AnalyzeResult{
+				{
+					IsFail:  true,
+					Title:   "pod logs",
+					Message: "panic in logs",
+					IconKey: "kubernetes_text_analyze",
+					IconURI: "https://troubleshoot.sh/images/analyzer-icons/text-analyze.svg",
+				},
+			},
+			files: map[string][]byte{
+				"collection-metadata.json": []byte(`{"contentTypes": {"app/web.log": "pod-log", "app/web.json": "k8s-json", "other/db.log": "pod-log"}}`),
+				"app/web.log":              []byte("panic: runtime error"),
+				"app/web.json":             []byte(`{"status": "ok"}`),
+				"other/db.log":             []byte("ready"),
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -2,12 +2,9 @@ package analyzer
 
 import (
 	"path/filepath"
-	"reflect"
-	"strconv"
 
 	"github.com/pkg/errors"
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
-	iutils "github.com/replicatedhq/troubleshoot/pkg/interfaceutils"
 	"gopkg.in/yaml.v2"
 )
 
@@ -24,17 +21,17 @@ func analyzeYamlCompare(analyzer *troubleshootv1beta2.YamlCompare, getCollectedF
 		return nil, errors.Wrap(err, "failed to parse collected data as yaml doc")
 	}
 
-	if analyzer.Path != "" {
-		actual, err = iutils.GetAtPath(actual, analyzer.Path)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to get object at path: %s", analyzer.Path)
-		}
+	selected, err := selectComparedValue(actual, analyzer.Path, analyzer.JsonPath)
+	if err != nil {
+		return nil, err
 	}
 
-	var expected interface{}
-	err = yaml.Unmarshal([]byte(analyzer.Value), &expected)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to parse expected value as yaml doc")
+	expected := func() (interface{}, error) {
+		var expected interface{}
+		if err := yaml.Unmarshal([]byte(analyzer.Value), &expected); err != nil {
+			return nil, errors.Wrap(err, "failed to parse expected value as yaml doc")
+		}
+		return expected, nil
 	}
 
 	title := analyzer.CheckName
@@ -48,62 +45,5 @@ func analyzeYamlCompare(analyzer *troubleshootv1beta2.YamlCompare, getCollectedF
 		IconURI: "https://troubleshoot.sh/images/analyzer-icons/text-analyze.svg",
 	}
 
-	equal := reflect.DeepEqual(actual, expected)
-
-	for _, outcome := range analyzer.Outcomes {
-		if outcome.Fail != nil {
-			when := false
-			if outcome.Fail.When != "" {
-				when, err = strconv.ParseBool(outcome.Fail.When)
-				if err != nil {
-					return nil, errors.Wrapf(err, "failed to process when statement: %s", outcome.Fail.When)
-				}
-			}
-
-			if when == equal {
-				result.IsFail = true
-				result.Message = outcome.Fail.Message
-				result.URI = outcome.Fail.URI
-				return result, nil
-			}
-		} else if outcome.Warn != nil {
-			when := false
-			if outcome.Warn.When != "" {
-				when, err = strconv.ParseBool(outcome.Warn.When)
-				if err != nil {
-					return nil, errors.Wrapf(err, "failed to process when statement: %s", outcome.Warn.When)
-				}
-			}
-
-			if when == equal {
-				result.IsWarn = true
-				result.Message = outcome.Warn.Message
-				result.URI = outcome.Warn.URI
-				return result, nil
-			}
-		} else if outcome.Pass != nil {
-			when := true // default to passing when values are equal
-			if outcome.Pass.When != "" {
-				when, err = strconv.ParseBool(outcome.Pass.When)
-				if err != nil {
-					return nil, errors.Wrapf(err, "failed to process when statement: %s", outcome.Pass.When)
-				}
-			}
-
-			if when == equal {
-				result.IsPass = true
-				result.Message = outcome.Pass.Message
-				result.URI = outcome.Pass.URI
-				return result, nil
-			}
-		}
-	}
-
-	return &AnalyzeResult{
-		Title:   title,
-		IconKey: "kubernetes_text_analyze",
-		IconURI: "https://troubleshoot.sh/images/analyzer-icons/text-analyze.svg",
-		IsFail:  true,
-		Message: "Invalid analyzer",
-	}, nil
+	return evaluateCompareOutcomes(result, analyzer.Outcomes, selected, analyzer.Value != "", expected)
 }
@@ -450,3 +450,26 @@ otherstuff:
 		})
 	}
 }
+
+func Test_yamlCompareJsonPath(t *testing.T) {
+	req := require.New(t)
+
+	analyzer := &troubleshootv1beta2.YamlCompare{
+		CollectorName: "yaml-compare",
+		FileName:      "yaml-compare.yaml",
+		JsonPath:      "{.spec.replicas}",
+		Outcomes: []*troubleshootv1beta2.Outcome{
+			{Fail: &troubleshootv1beta2.SingleOutcome{When: "< 2", Message: "not highly available"}},
+			{Pass: &troubleshootv1beta2.SingleOutcome{When: "exists", Message: "highly available"}},
+		},
+	}
+
+	getCollectedFileContents := func(n string) ([]byte, error) {
+		return []byte("spec:\n  replicas: 3\n"), nil
+	}
+
+	actual, err := analyzeYamlCompare(analyzer, getCollectedFileContents)
+	req.NoError(err)
+	req.True(actual.IsPass)
+	req.Equal("highly available", actual.Message)
+}
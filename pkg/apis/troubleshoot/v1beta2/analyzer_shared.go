@@ -66,6 +66,86 @@ type StatefulsetStatus struct {
 	Name        string     `json:"name" yaml:"name"`
 }
 
+type DaemonSetCoverage struct {
+	AnalyzeMeta `json:",inline" yaml:",inline"`
+	Outcomes    []*Outcome `json:"outcomes" yaml:"outcomes"`
+	Namespace   string     `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Namespaces  []string   `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+	Name        string     `json:"name,omitempty" yaml:"name,omitempty"`
+}
+
+// NodeReadiness classifies why nodes are not ready from their conditions, taints and events, and from
+// collected kubelet logs. The when of an outcome is the cause that it matches.
+type NodeReadiness struct {
+	AnalyzeMeta `json:",inline" yaml:",inline"`
+	Outcomes    []*Outcome `json:"outcomes" yaml:"outcomes"`
+	// KubeletLogs are globs of collected kubelet logs. A file is used for a node if the node's name is
+	// a directory in its path, or if the cluster has one node.
+	KubeletLogs []string `json:"kubeletLogs,omitempty" yaml:"kubeletLogs,omitempty"`
+}
+
+// PodFailures finds the containers of pods that are in CrashLoopBackOff, can not pull their image or were
+// OOMKilled, from the collected pods and events. The when of an outcome is the reason that it matches.
+type PodFailures struct {
+	AnalyzeMeta `json:",inline" yaml:",inline"`
+	Outcomes    []*Outcome `json:"outcomes" yaml:"outcomes"`
+	// Namespaces are the namespaces of the pods, all namespaces if it is not set
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+}
+
+// StorageHealth finds persistent volume claims that are Pending or Lost, and volumes that were provisioned
+// by a different provisioner than their storage class has, from the files of a storage collector. It also
+// fails when no storage class is the default. The when of an outcome is the reason that it matches.
+type StorageHealth struct {
+	AnalyzeMeta `json:",inline" yaml:",inline"`
+	Outcomes    []*Outcome `json:"outcomes" yaml:"outcomes"`
+	// CollectorName is the collector name of the storage collector
+	CollectorName string `json:"collectorName,omitempty" yaml:"collectorName,omitempty"`
+	// Namespaces are the namespaces of the persistent volume claims, all namespaces if it is not set
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+}
+
+// IngressRouting follows the route of requests for each host from the ingresses with a rule for it, to their
+// backend services, to the services' endpoints and the readiness of the pods behind them, from the files of
+// the clusterResources collector. It reports the first link of the route that is broken. The when of an
+// outcome is the reason that it matches.
+type IngressRouting struct {
+	AnalyzeMeta `json:",inline" yaml:",inline"`
+	Outcomes    []*Outcome `json:"outcomes" yaml:"outcomes"`
+	// Hosts are the hostnames to follow the routes of, such as app.example.com
+	Hosts []string `json:"hosts" yaml:"hosts"`
+	// Path is the path of the requests to follow, / if it is not set
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Namespaces are the namespaces of the ingresses, all namespaces if it is not set
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+	// EndpointsCollectorName is the collector name of a serviceEndpoints collector. The endpoints it
+	// collected are used for services that it collected, rather than the ready pods that services select.
+	EndpointsCollectorName string `json:"endpointsCollectorName,omitempty" yaml:"endpointsCollectorName,omitempty"`
+}
+
+// CompoundAnalyze combines the results of the analyzers before it. The when of an outcome is an expression
+// of their outcomes, such as "analyzer(Ceph Status) == fail && analyzer(Node Count) != pass", where an
+// analyzer is referred to by its checkName or title. An outcome with no when always matches.
+//
+// Only outcomes are compared: pass, warn, fail and missing. To check a count, such as the number of nodes,
+// use an analyzer whose outcomes check it, such as nodeResources with "count() < 3", and compare its outcome.
+type CompoundAnalyze struct {
+	AnalyzeMeta `json:",inline" yaml:",inline"`
+	Outcomes    []*Outcome `json:"outcomes" yaml:"outcomes"`
+}
+
+// GoProfile finds leaks in the pprof profiles of Go programs, such as goroutine and heap profiles that were
+// collected from /debug/pprof. The when of an outcome compares goroutines, inuseBytes or topStackPercent
+// of each profile to a number, such as "goroutines > 10000" or "topStackPercent > 50". topStackPercent is
+// the share of the goroutines, or of the in-use heap, of the stack that has the most.
+type GoProfile struct {
+	AnalyzeMeta `json:",inline" yaml:",inline"`
+	Outcomes    []*Outcome `json:"outcomes" yaml:"outcomes"`
+	// FileName is a glob of the collected profiles, which can be gzipped protobuf or the text format of
+	// debug=1
+	FileName string `json:"fileName" yaml:"fileName"`
+}
+
 type JobStatus struct {
 	AnalyzeMeta `json:",inline" yaml:",inline"`
 	Outcomes    []*Outcome `json:"outcomes" yaml:"outcomes"`
@@ -129,24 +209,35 @@ type TextAnalyze struct {
 	RegexGroups     string     `json:"regexGroups,omitempty" yaml:"regexGroups,omitempty"`
 	IgnoreIfNoFiles bool       `json:"ignoreIfNoFiles,omitempty" yaml:"ignoreIfNoFiles,omitempty"`
 	Outcomes        []*Outcome `json:"outcomes" yaml:"outcomes"`
+	// ContentType analyzes the files that collectors tagged with this type, such as pod-log. If
+	// collectorName or fileName are set, only the tagged files under them are analyzed.
+	ContentType string `json:"contentType,omitempty" yaml:"contentType,omitempty"`
 }
 
 type YamlCompare struct {
 	AnalyzeMeta   `json:",inline" yaml:",inline"`
-	CollectorName string     `json:"collectorName,omitempty" yaml:"collectorName,omitempty"`
-	FileName      string     `json:"fileName,omitempty" yaml:"fileName,omitempty"`
-	Path          string     `json:"path,omitempty" yaml:"path,omitempty"`
-	Value         string     `json:"value,omitempty" yaml:"value,omitempty"`
-	Outcomes      []*Outcome `json:"outcomes" yaml:"outcomes"`
+	CollectorName string `json:"collectorName,omitempty" yaml:"collectorName,omitempty"`
+	FileName      string `json:"fileName,omitempty" yaml:"fileName,omitempty"`
+	Path          string `json:"path,omitempty" yaml:"path,omitempty"`
+	// JsonPath is a JSONPath expression, such as {.items[*].metadata.name}, that selects the value to
+	// compare instead of path. The "when" of outcomes can compare the value with an operator, such as
+	// "> 3", "contains foo" or "exists".
+	JsonPath string     `json:"jsonPath,omitempty" yaml:"jsonPath,omitempty"`
+	Value    string     `json:"value,omitempty" yaml:"value,omitempty"`
+	Outcomes []*Outcome `json:"outcomes" yaml:"outcomes"`
 }
 
 type JsonCompare struct {
 	AnalyzeMeta   `json:",inline" yaml:",inline"`
-	CollectorName string     `json:"collectorName,omitempty" yaml:"collectorName,omitempty"`
-	FileName      string     `json:"fileName,omitempty" yaml:"fileName,omitempty"`
-	Path          string     `json:"path,omitempty" yaml:"path,omitempty"`
-	Value         string     `json:"value,omitempty" yaml:"value,omitempty"`
-	Outcomes      []*Outcome `json:"outcomes" yaml:"outcomes"`
+	CollectorName string `json:"collectorName,omitempty" yaml:"collectorName,omitempty"`
+	FileName      string `json:"fileName,omitempty" yaml:"fileName,omitempty"`
+	Path          string `json:"path,omitempty" yaml:"path,omitempty"`
+	// JsonPath is a JSONPath expression, such as {.items[*].metadata.name}, that selects the value to
+	// compare instead of path. The "when" of outcomes can compare the value with an operator, such as
+	// "> 3", "contains foo" or "exists".
+	JsonPath string     `json:"jsonPath,omitempty" yaml:"jsonPath,omitempty"`
+	Value    string     `json:"value,omitempty" yaml:"value,omitempty"`
+	Outcomes []*Outcome `json:"outcomes" yaml:"outcomes"`
 }
 
 type DatabaseAnalyze struct {
@@ -197,6 +288,13 @@ type AnalyzeMeta struct {
 	Exclude     *multitype.BoolOrString `json:"exclude,omitempty" yaml:"exclude,omitempty"`
 	Strict      *multitype.BoolOrString `json:"strict,omitempty" yaml:"strict,omitempty"`
 	Annotations map[string]string       `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	// DocString describes what the analyzer checks and why
+	DocString string `json:"docString,omitempty" yaml:"docString,omitempty"`
+	// Category and Tags are used to group and filter analyzer results in reports
+	Category string   `json:"category,omitempty" yaml:"category,omitempty"`
+	Tags     []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// RemediationURI points to documentation on how to resolve a failing check
+	RemediationURI string `json:"remediationUri,omitempty" yaml:"remediationUri,omitempty"`
 }
 
 type Analyze struct {
@@ -226,4 +324,11 @@ type Analyze struct {
 	RegistryImages           *RegistryImagesAnalyze    `json:"registryImages,omitempty" yaml:"registryImages,omitempty"`
 	WeaveReport              *WeaveReportAnalyze       `json:"weaveReport,omitempty" yaml:"weaveReport,omitempty"`
 	Sysctl                   *SysctlAnalyze            `json:"sysctl,omitempty" yaml:"sysctl,omitempty"`
+	DaemonSetCoverage        *DaemonSetCoverage        `json:"daemonSetCoverage,omitempty" yaml:"daemonSetCoverage,omitempty"`
+	NodeReadiness            *NodeReadiness            `json:"nodeReadiness,omitempty" yaml:"nodeReadiness,omitempty"`
+	PodFailures              *PodFailures              `json:"podFailures,omitempty" yaml:"podFailures,omitempty"`
+	StorageHealth            *StorageHealth            `json:"storageHealth,omitempty" yaml:"storageHealth,omitempty"`
+	GoProfile                *GoProfile                `json:"goProfile,omitempty" yaml:"goProfile,omitempty"`
+	Compound                 *CompoundAnalyze          `json:"compound,omitempty" yaml:"compound,omitempty"`
+	IngressRouting           *IngressRouting           `json:"ingressRouting,omitempty" yaml:"ingressRouting,omitempty"`
 }
@@ -9,22 +9,39 @@ import (
 	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 type CollectorMeta struct {
 	CollectorName string `json:"collectorName,omitempty" yaml:"collectorName,omitempty"`
 	// +optional
 	Exclude *multitype.BoolOrString `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	// When is the opposite of Exclude: the collector is only run if it is true. Like exclude, it can be a
+	// template of values and cluster facts, such as '{{ .Values.postgres.enabled }}'.
+	// +optional
+	When *multitype.BoolOrString `json:"when,omitempty" yaml:"when,omitempty"`
+	// Priority is high, normal or low. While the API server is throttling requests, high priority
+	// collectors run first and low priority collectors are deferred, and skipped if it doesn't stop.
+	// +optional
+	Priority string `json:"priority,omitempty" yaml:"priority,omitempty"`
 }
 
 type ClusterInfo struct {
 	CollectorMeta `json:",inline" yaml:",inline"`
 }
 
+// ClusterSummary writes summary.json, with the version and distribution of the cluster, its nodes, and
+// the network plugins, CSI drivers, ingress controllers and operators that are installed in it
+type ClusterSummary struct {
+	CollectorMeta `json:",inline" yaml:",inline"`
+	Timeout       string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
 type ClusterResources struct {
 	CollectorMeta `json:",inline" yaml:",inline"`
 	Namespaces    []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
 	IgnoreRBAC    bool     `json:"ignoreRBAC,omitempty" yaml:"ignoreRBAC"`
+	Timeout       string   `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 }
 
 type Secret struct {
@@ -34,6 +51,7 @@ type Secret struct {
 	Namespace     string   `json:"namespace,omitempty" yaml:"namespace,omitempty"`
 	Key           string   `json:"key,omitempty" yaml:"key,omitempty"`
 	IncludeValue  bool     `json:"includeValue,omitempty" yaml:"includeValue,omitempty"`
+	Timeout       string   `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 }
 
 type ConfigMap struct {
@@ -44,12 +62,20 @@ type ConfigMap struct {
 	Key            string   `json:"key,omitempty" yaml:"key,omitempty"`
 	IncludeValue   bool     `json:"includeValue,omitempty" yaml:"includeValue,omitempty"`
 	IncludeAllData bool     `json:"includeAllData,omitempty" yaml:"includeAllData,omitempty"`
+	Timeout        string   `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 }
 
 type LogLimits struct {
-	MaxAge    string      `json:"maxAge,omitempty" yaml:"maxAge,omitempty"`
-	MaxLines  int64       `json:"maxLines,omitempty" yaml:"maxLines,omitempty"`
+	MaxAge   string `json:"maxAge,omitempty" yaml:"maxAge,omitempty"`
+	MaxLines int64  `json:"maxLines,omitempty" yaml:"maxLines,omitempty"`
+	// MaxBytes is the size past which a log is truncated
+	MaxBytes  int64       `json:"maxBytes,omitempty" yaml:"maxBytes,omitempty"`
 	SinceTime metav1.Time `json:"sinceTime,omitempty" yaml:"sinceTime,omitempty"`
+	// Truncation is which end of a log is kept when it is over maxLines or maxBytes, head or tail.
+	// Logs are truncated as they are read when this or maxBytes is set, and a file recording how much
+	// was dropped is written next to each log that was truncated. The default is tail. From the head,
+	// reading stops at the limits, so how much was dropped past them is not known.
+	Truncation string `json:"truncation,omitempty" yaml:"truncation,omitempty"`
 }
 
 type Logs struct {
@@ -59,6 +85,10 @@ type Logs struct {
 	Namespace      string     `json:"namespace,omitempty" yaml:"namespace,omitempty"`
 	ContainerNames []string   `json:"containerNames,omitempty" yaml:"containerNames,omitempty"`
 	Limits         *LogLimits `json:"limits,omitempty" yaml:"omitempty"`
+	Timeout        string     `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// Previous is true to collect the logs of the previous terminated containers only, false to collect
+	// the current logs only, or "all" to collect both. Both are collected if it is not set.
+	Previous *multitype.BoolOrString `json:"previous,omitempty" yaml:"previous,omitempty"`
 }
 
 type Data struct {
@@ -114,6 +144,14 @@ type Copy struct {
 	ContainerPath  string   `json:"containerPath" yaml:"containerPath"`
 	ContainerName  string   `json:"containerName,omitempty" yaml:"containerName,omitempty"`
 	ExtractArchive bool     `json:"extractArchive,omitempty" yaml:"extractArchive,omitempty"`
+	// MaxFileBytes is the size past which a file is not copied
+	MaxFileBytes int64 `json:"maxFileBytes,omitempty" yaml:"maxFileBytes,omitempty"`
+	// MaxBytes is the size of the files copied from each pod, past which the rest are not copied
+	MaxBytes int64 `json:"maxBytes,omitempty" yaml:"maxBytes,omitempty"`
+	// FollowSymlinks copies the files that symlinks point to. Symlinks are recorded rather than copied
+	// when it is not set.
+	FollowSymlinks bool   `json:"followSymlinks,omitempty" yaml:"followSymlinks,omitempty"`
+	Timeout        string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 }
 
 type CopyFromHost struct {
@@ -126,6 +164,8 @@ type CopyFromHost struct {
 	Timeout         string            `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 	HostPath        string            `json:"hostPath" yaml:"hostPath"`
 	ExtractArchive  bool              `json:"extractArchive,omitempty" yaml:"extractArchive,omitempty"`
+	// NodeSelector selects the nodes to copy from by label, all nodes if it is not set
+	NodeSelector map[string]string `json:"nodeSelector,omitempty" yaml:"nodeSelector,omitempty"`
 }
 
 type Sysctl struct {
@@ -136,6 +176,11 @@ type Sysctl struct {
 	ImagePullPolicy string            `json:"imagePullPolicy,omitempty" yaml:"imagePullPolicy,omitempty"`
 	ImagePullSecret *ImagePullSecrets `json:"imagePullSecret,omitempty" yaml:"imagePullSecret,omitempty"`
 	Timeout         string            `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// NodeSelector selects the ready nodes to collect from by label, all ready nodes if it is not set
+	NodeSelector map[string]string `json:"nodeSelector,omitempty" yaml:"nodeSelector,omitempty"`
+	// Parameters are kernel parameters to collect in addition to net.ipv4, net.bridge and the default
+	// vm, fs and kernel parameters, such as net.core.somaxconn
+	Parameters []string `json:"parameters,omitempty" yaml:"parameters,omitempty"`
 }
 
 type HTTP struct {
@@ -144,6 +189,8 @@ type HTTP struct {
 	Get           *Get   `json:"get,omitempty" yaml:"get,omitempty"`
 	Post          *Post  `json:"post,omitempty" yaml:"post,omitempty"`
 	Put           *Put   `json:"put,omitempty" yaml:"put,omitempty"`
+	Head          *Head  `json:"head,omitempty" yaml:"head,omitempty"`
+	Timeout       string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 }
 
 type Get struct {
@@ -166,10 +213,29 @@ type Put struct {
 	Body               string            `json:"body,omitempty" yaml:"body,omitempty"`
 }
 
+type Head struct {
+	URL                string            `json:"url" yaml:"url"`
+	InsecureSkipVerify bool              `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
+	Headers            map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+}
+
 type Database struct {
 	CollectorMeta `json:",inline" yaml:",inline"`
 	URI           string   `json:"uri" yaml:"uri"`
 	Parameters    []string `json:"parameters,omitempty"`
+	// Diagnostics are read-only queries to run, only supported for postgres: settings, activity,
+	// database-sizes and replication
+	Diagnostics []string `json:"diagnostics,omitempty" yaml:"diagnostics,omitempty"`
+	// Topology collects the replication topology of the database, only supported for postgres and mysql
+	Topology *DatabaseTopology `json:"topology,omitempty" yaml:"topology,omitempty"`
+	Timeout  string            `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// DatabaseTopology collects the role of each member of a database and how far its replicas are behind
+type DatabaseTopology struct {
+	// URIs are the other members of the database, such as its replicas. When it is not set, the replicas
+	// are discovered from the replication status of the primary at uri.
+	URIs []string `json:"uris,omitempty" yaml:"uris,omitempty"`
 }
 
 type Collectd struct {
@@ -194,34 +260,148 @@ type Longhorn struct {
 	Timeout       string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 }
 
+// ClusterAutoscaler collects the status and logs of the cluster autoscaler, recent scaling and node
+// lifecycle events, and the node groups and machines that it scales
+type ClusterAutoscaler struct {
+	CollectorMeta `json:",inline" yaml:",inline"`
+	// Namespace is where the cluster autoscaler runs, kube-system if it is not set
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// Selector selects the cluster autoscaler pods. If it is not set, pods with a cluster-autoscaler image
+	// are selected.
+	Selector []string   `json:"selector,omitempty" yaml:"selector,omitempty"`
+	Limits   *LogLimits `json:"limits,omitempty" yaml:"limits,omitempty"`
+	Timeout  string     `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// ServiceEndpoints collects the endpoints of services, with the readiness and ports of the pods that the
+// services select, so that services without endpoints can be diagnosed
+type ServiceEndpoints struct {
+	CollectorMeta `json:",inline" yaml:",inline"`
+	// Namespace is where the services are, all namespaces if it is not set
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// Selector selects the services by label, all services if it is not set
+	Selector []string `json:"selector,omitempty" yaml:"selector,omitempty"`
+	Timeout  string   `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// NodeStats collects the kubelet's stats summary of nodes, with the usage of their filesystems, so that
+// nodes nearing disk, inode or ephemeral storage exhaustion can be found
+type NodeStats struct {
+	CollectorMeta `json:",inline" yaml:",inline"`
+	// Selector selects the nodes by label, all nodes if it is not set
+	Selector []string `json:"selector,omitempty" yaml:"selector,omitempty"`
+	Timeout  string   `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// Events collects the events of namespaces, as json and as a timeline of when they last happened, as
+// events are usually the first thing to look at
+type Events struct {
+	CollectorMeta `json:",inline" yaml:",inline"`
+	// Namespaces are where the events are, all namespaces if it is not set
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+	// FieldSelector selects the events by field, e.g. type=Warning or reason=BackOff
+	FieldSelector string `json:"fieldSelector,omitempty" yaml:"fieldSelector,omitempty"`
+	// InvolvedObject selects the events of objects, by the fields that are set
+	InvolvedObject *EventInvolvedObject `json:"involvedObject,omitempty" yaml:"involvedObject,omitempty"`
+	Timeout        string               `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+type EventInvolvedObject struct {
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+}
+
+// Storage collects the storage classes, persistent volumes and persistent volume claims, with a table of
+// the phase of each claim and the volume it is bound to
+type Storage struct {
+	CollectorMeta `json:",inline" yaml:",inline"`
+	// Namespaces are where the persistent volume claims are, all namespaces if it is not set
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+	Timeout    string   `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// Vault checks that a Vault server can be reached and is unsealed, and that a role can log in with the
+// Kubernetes auth method
+type Vault struct {
+	CollectorMeta `json:",inline" yaml:",inline"`
+	// Address is the url of the server, such as https://vault.vault.svc:8200
+	Address            string `json:"address" yaml:"address"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
+	// KubernetesAuth logs in with the Kubernetes auth method, if it is set
+	KubernetesAuth *VaultKubernetesAuth `json:"kubernetesAuth,omitempty" yaml:"kubernetesAuth,omitempty"`
+	Timeout        string               `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+type VaultKubernetesAuth struct {
+	// Role is the role to log in as
+	Role string `json:"role" yaml:"role"`
+	// MountPath is where the auth method is enabled, kubernetes if it is not set
+	MountPath string `json:"mountPath,omitempty" yaml:"mountPath,omitempty"`
+	// ServiceAccount logs in with a token of the service account, which is requested for it. The token of
+	// the pod that the collector runs in is used if it is not set.
+	ServiceAccount string `json:"serviceAccount,omitempty" yaml:"serviceAccount,omitempty"`
+	// Namespace is the namespace of the service account
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+}
+
+// OIDC fetches the discovery document and the signing keys of an OpenID Connect issuer, such as a
+// Keycloak realm
+type OIDC struct {
+	CollectorMeta `json:",inline" yaml:",inline"`
+	// IssuerURL is the issuer, which the discovery document is fetched from at
+	// /.well-known/openid-configuration, such as https://keycloak.example.com/realms/app
+	IssuerURL          string `json:"issuerUrl" yaml:"issuerUrl"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
+	Timeout            string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
 type RegistryImages struct {
 	CollectorMeta    `json:",inline" yaml:",inline"`
 	Images           []string          `json:"images" yaml:"images"`
 	Namespace        string            `json:"namespace" yaml:"namespace"`
 	ImagePullSecrets *ImagePullSecrets `json:"imagePullSecret,omitempty" yaml:"imagePullSecret,omitempty"`
+	Timeout          string            `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// Custom is a collector that is registered by a program embedding troubleshoot, Spec is passed to it
+// as is
+type Custom struct {
+	CollectorMeta `json:",inline" yaml:",inline"`
+	Type          string               `json:"type" yaml:"type"`
+	Spec          runtime.RawExtension `json:"spec,omitempty" yaml:"spec,omitempty"`
+	Timeout       string               `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 }
 
 type Collect struct {
-	ClusterInfo      *ClusterInfo      `json:"clusterInfo,omitempty" yaml:"clusterInfo,omitempty"`
-	ClusterResources *ClusterResources `json:"clusterResources,omitempty" yaml:"clusterResources,omitempty"`
-	Secret           *Secret           `json:"secret,omitempty" yaml:"secret,omitempty"`
-	ConfigMap        *ConfigMap        `json:"configMap,omitempty" yaml:"configMap,omitempty"`
-	Logs             *Logs             `json:"logs,omitempty" yaml:"logs,omitempty"`
-	Run              *Run              `json:"run,omitempty" yaml:"run,omitempty"`
-	RunPod           *RunPod           `json:"runPod,omitempty" yaml:"runPod,omitempty"`
-	Exec             *Exec             `json:"exec,omitempty" yaml:"exec,omitempty"`
-	Data             *Data             `json:"data,omitempty" yaml:"data,omitempty"`
-	Copy             *Copy             `json:"copy,omitempty" yaml:"copy,omitempty"`
-	CopyFromHost     *CopyFromHost     `json:"copyFromHost,omitempty" yaml:"copyFromHost,omitempty"`
-	HTTP             *HTTP             `json:"http,omitempty" yaml:"http,omitempty"`
-	Postgres         *Database         `json:"postgres,omitempty" yaml:"postgres,omitempty"`
-	Mysql            *Database         `json:"mysql,omitempty" yaml:"mysql,omitempty"`
-	Redis            *Database         `json:"redis,omitempty" yaml:"redis,omitempty"`
-	Collectd         *Collectd         `json:"collectd,omitempty" yaml:"collectd,omitempty"`
-	Ceph             *Ceph             `json:"ceph,omitempty" yaml:"ceph,omitempty"`
-	Longhorn         *Longhorn         `json:"longhorn,omitempty" yaml:"longhorn,omitempty"`
-	RegistryImages   *RegistryImages   `json:"registryImages,omitempty" yaml:"registryImages,omitempty"`
-	Sysctl           *Sysctl           `json:"sysctl,omitempty" yaml:"sysctl,omitempty"`
+	ClusterInfo       *ClusterInfo       `json:"clusterInfo,omitempty" yaml:"clusterInfo,omitempty"`
+	ClusterResources  *ClusterResources  `json:"clusterResources,omitempty" yaml:"clusterResources,omitempty"`
+	Secret            *Secret            `json:"secret,omitempty" yaml:"secret,omitempty"`
+	ConfigMap         *ConfigMap         `json:"configMap,omitempty" yaml:"configMap,omitempty"`
+	Logs              *Logs              `json:"logs,omitempty" yaml:"logs,omitempty"`
+	Run               *Run               `json:"run,omitempty" yaml:"run,omitempty"`
+	RunPod            *RunPod            `json:"runPod,omitempty" yaml:"runPod,omitempty"`
+	Exec              *Exec              `json:"exec,omitempty" yaml:"exec,omitempty"`
+	Data              *Data              `json:"data,omitempty" yaml:"data,omitempty"`
+	Copy              *Copy              `json:"copy,omitempty" yaml:"copy,omitempty"`
+	CopyFromHost      *CopyFromHost      `json:"copyFromHost,omitempty" yaml:"copyFromHost,omitempty"`
+	HTTP              *HTTP              `json:"http,omitempty" yaml:"http,omitempty"`
+	Postgres          *Database          `json:"postgres,omitempty" yaml:"postgres,omitempty"`
+	Mysql             *Database          `json:"mysql,omitempty" yaml:"mysql,omitempty"`
+	Redis             *Database          `json:"redis,omitempty" yaml:"redis,omitempty"`
+	Collectd          *Collectd          `json:"collectd,omitempty" yaml:"collectd,omitempty"`
+	Ceph              *Ceph              `json:"ceph,omitempty" yaml:"ceph,omitempty"`
+	Longhorn          *Longhorn          `json:"longhorn,omitempty" yaml:"longhorn,omitempty"`
+	RegistryImages    *RegistryImages    `json:"registryImages,omitempty" yaml:"registryImages,omitempty"`
+	Sysctl            *Sysctl            `json:"sysctl,omitempty" yaml:"sysctl,omitempty"`
+	Custom            *Custom            `json:"custom,omitempty" yaml:"custom,omitempty"`
+	ClusterAutoscaler *ClusterAutoscaler `json:"clusterAutoscaler,omitempty" yaml:"clusterAutoscaler,omitempty"`
+	ServiceEndpoints  *ServiceEndpoints  `json:"serviceEndpoints,omitempty" yaml:"serviceEndpoints,omitempty"`
+	NodeStats         *NodeStats         `json:"nodeStats,omitempty" yaml:"nodeStats,omitempty"`
+	Events            *Events            `json:"events,omitempty" yaml:"events,omitempty"`
+	Storage           *Storage           `json:"storage,omitempty" yaml:"storage,omitempty"`
+	Vault             *Vault             `json:"vault,omitempty" yaml:"vault,omitempty"`
+	OIDC              *OIDC              `json:"oidc,omitempty" yaml:"oidc,omitempty"`
+	ClusterSummary    *ClusterSummary    `json:"clusterSummary,omitempty" yaml:"clusterSummary,omitempty"`
 }
 
 func (c *Collect) AccessReviewSpecs(overrideNS string) []authorizationv1.SelfSubjectAccessReviewSpec {
@@ -428,6 +608,97 @@ func (c *Collect) AccessReviewSpecs(overrideNS string) []authorizationv1.SelfSub
 		})
 	} else if c.Sysctl != nil {
 		// TODO
+	} else if c.Events != nil {
+		namespaces := c.Events.Namespaces
+		if len(namespaces) == 0 {
+			namespaces = []string{""}
+		}
+		for _, namespace := range namespaces {
+			result = append(result, authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   namespace,
+					Verb:        "list",
+					Group:       "",
+					Version:     "",
+					Resource:    "events",
+					Subresource: "",
+					Name:        "",
+				},
+				NonResourceAttributes: nil,
+			})
+		}
+	} else if c.Storage != nil {
+		for _, resource := range []struct{ group, resource string }{
+			{"storage.k8s.io", "storageclasses"},
+			{"", "persistentvolumes"},
+		} {
+			result = append(result, authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   "",
+					Verb:        "list",
+					Group:       resource.group,
+					Version:     "",
+					Resource:    resource.resource,
+					Subresource: "",
+					Name:        "",
+				},
+				NonResourceAttributes: nil,
+			})
+		}
+		namespaces := c.Storage.Namespaces
+		if len(namespaces) == 0 {
+			namespaces = []string{""}
+		}
+		for _, namespace := range namespaces {
+			result = append(result, authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   namespace,
+					Verb:        "list",
+					Group:       "",
+					Version:     "",
+					Resource:    "persistentvolumeclaims",
+					Subresource: "",
+					Name:        "",
+				},
+				NonResourceAttributes: nil,
+			})
+		}
+	} else if c.ClusterSummary != nil {
+		for _, resource := range []struct{ group, resource string }{
+			{"", "nodes"},
+			{"apps", "daemonsets"},
+			{"storage.k8s.io", "csidrivers"},
+			{"storage.k8s.io", "storageclasses"},
+			{"networking.k8s.io", "ingressclasses"},
+		} {
+			result = append(result, authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   "",
+					Verb:        "list",
+					Group:       resource.group,
+					Version:     "",
+					Resource:    resource.resource,
+					Subresource: "",
+					Name:        "",
+				},
+				NonResourceAttributes: nil,
+			})
+		}
+	} else if c.Vault != nil {
+		if c.Vault.KubernetesAuth != nil && c.Vault.KubernetesAuth.ServiceAccount != "" {
+			result = append(result, authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   pickNamespaceOrDefault(c.Vault.KubernetesAuth.Namespace, overrideNS),
+					Verb:        "create",
+					Group:       "",
+					Version:     "",
+					Resource:    "serviceaccounts",
+					Subresource: "token",
+					Name:        c.Vault.KubernetesAuth.ServiceAccount,
+				},
+				NonResourceAttributes: nil,
+			})
+		}
 	}
 
 	return result
@@ -518,6 +789,44 @@ func (c *Collect) GetName() string {
 		collector = "sysctl"
 		name = c.Sysctl.Name
 	}
+	if c.Custom != nil {
+		collector = c.Custom.Type
+		name = c.Custom.CollectorName
+	}
+	if c.ClusterAutoscaler != nil {
+		collector = "cluster-autoscaler"
+		name = c.ClusterAutoscaler.CollectorName
+	}
+	if c.ServiceEndpoints != nil {
+		collector = "service-endpoints"
+		name = c.ServiceEndpoints.CollectorName
+		selector = strings.Join(c.ServiceEndpoints.Selector, ",")
+	}
+	if c.NodeStats != nil {
+		collector = "node-stats"
+		name = c.NodeStats.CollectorName
+		selector = strings.Join(c.NodeStats.Selector, ",")
+	}
+	if c.Events != nil {
+		collector = "events"
+		name = c.Events.CollectorName
+	}
+	if c.Storage != nil {
+		collector = "storage"
+		name = c.Storage.CollectorName
+	}
+	if c.Vault != nil {
+		collector = "vault"
+		name = c.Vault.CollectorName
+	}
+	if c.OIDC != nil {
+		collector = "oidc"
+		name = c.OIDC.CollectorName
+	}
+	if c.ClusterSummary != nil {
+		collector = "cluster-summary"
+		name = c.ClusterSummary.CollectorName
+	}
 
 	if collector == "" {
 		return "<none>"
@@ -8,6 +8,9 @@ type HostCollectorMeta struct {
 	CollectorName string `json:"collectorName,omitempty" yaml:"collectorName,omitempty"`
 	// +optional
 	Exclude *multitype.BoolOrString `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	// When is the opposite of Exclude: the collector is only run if it is true
+	// +optional
+	When *multitype.BoolOrString `json:"when,omitempty" yaml:"when,omitempty"`
 }
 
 type CPU struct {
@@ -39,6 +42,17 @@ type TCPPortStatus struct {
 	Port              int    `json:"port"`
 }
 
+type PortAvailability struct {
+	HostCollectorMeta `json:",inline" yaml:",inline"`
+	// Ports are the ports that must be free, such as 6443, 2379-2380 or 8472/udp. Ports are tcp unless
+	// they say udp.
+	Ports     []string `json:"ports" yaml:"ports"`
+	Interface string   `json:"interface,omitempty" yaml:"interface,omitempty"`
+	// Timeout is how long to wait to connect to each tcp port, to check that it is not blocked by a
+	// firewall. The default is 1s.
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
 type Kubernetes struct {
 	HostCollectorMeta `json:",inline" yaml:",inline"`
 }
@@ -93,6 +107,10 @@ type HostKernelModules struct {
 type HostOS struct {
 	HostCollectorMeta `json:",inline" yaml:",inline"`
 }
+
+type HostWindowsFeatures struct {
+	HostCollectorMeta `json:",inline" yaml:",inline"`
+}
 type TCPConnect struct {
 	HostCollectorMeta `json:",inline" yaml:",inline"`
 	Address           string `json:"address"`
@@ -156,6 +174,28 @@ type HostRun struct {
 	Args              []string `json:"args"`
 }
 
+// HostK8sDistribution collects the service logs, config files and embedded etcd and containerd state of
+// distributions that keep them in their own locations, such as k3s, RKE2 and embedded-cluster (k0s)
+type HostK8sDistribution struct {
+	HostCollectorMeta `json:",inline" yaml:",inline"`
+	// Distribution is k3s, rke2 or k0s. It is detected from the host when it is not set.
+	Distribution string `json:"distribution,omitempty" yaml:"distribution,omitempty"`
+}
+
+// HostHardwareHealth reads the health of the systems, temperatures, fans and power supplies of a server
+// from the Redfish API of its baseboard management controller (BMC), such as iDRAC, iLO or an IPMI BMC
+type HostHardwareHealth struct {
+	HostCollectorMeta `json:",inline" yaml:",inline"`
+	// Address is the url of the BMC, such as https://10.0.0.10
+	Address            string `json:"address" yaml:"address"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
+	// UsernameFrom and PasswordFrom are where the credentials of the BMC are read from, so that they don't
+	// have to be written into the spec
+	UsernameFrom *ValueFrom `json:"usernameFrom,omitempty" yaml:"usernameFrom,omitempty"`
+	PasswordFrom *ValueFrom `json:"passwordFrom,omitempty" yaml:"passwordFrom,omitempty"`
+	Timeout      string     `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
 type HostCollect struct {
 	CPU                   *CPU                   `json:"cpu,omitempty" yaml:"cpu,omitempty"`
 	Memory                *Memory                `json:"memory,omitempty" yaml:"memory,omitempty"`
@@ -176,6 +216,10 @@ type HostCollect struct {
 	HostServices          *HostServices          `json:"hostServices,omitempty" yaml:"hostServices,omitempty"`
 	HostOS                *HostOS                `json:"hostOS,omitempty" yaml:"hostOS,omitempty"`
 	HostRun               *HostRun               `json:"run,omitempty" yaml:"run,omitempty"`
+	K8sDistribution       *HostK8sDistribution   `json:"k8sDistribution,omitempty" yaml:"k8sDistribution,omitempty"`
+	HardwareHealth        *HostHardwareHealth    `json:"hardwareHealth,omitempty" yaml:"hardwareHealth,omitempty"`
+	WindowsFeatures       *HostWindowsFeatures   `json:"windowsFeatures,omitempty" yaml:"windowsFeatures,omitempty"`
+	PortAvailability      *PortAvailability      `json:"portAvailability,omitempty" yaml:"portAvailability,omitempty"`
 }
 
 func (c *HostCollect) GetName() string {
@@ -8,16 +8,41 @@ type Regex struct {
 type FileSelector struct {
 	File  string   `json:"file,omitempty" yaml:"file,omitempty"`
 	Files []string `json:"files,omitempty" yaml:"files,omitempty"`
+	// ContentTypes limits the redactor to files that collectors tagged with one of these types, such as
+	// k8s-json, pod-log, pem, binary or sql-result
+	ContentTypes []string `json:"contentTypes,omitempty" yaml:"contentTypes,omitempty"`
 }
 
 type Removals struct {
 	Values   []string `json:"values,omitempty" yaml:"values,omitempty"`
 	Regex    []Regex  `json:"regex,omitempty" yaml:"regex,omitempty"`
 	YamlPath []string `json:"yamlPath,omitempty" yaml:"yamlPath,omitempty"`
+	JSONPath []string `json:"jsonPath,omitempty" yaml:"jsonPath,omitempty"`
+	// ValuesFrom are values to remove that are read when the bundle is collected, so that known secrets
+	// don't have to be written into the spec
+	ValuesFrom []ValueFrom `json:"valuesFrom,omitempty" yaml:"valuesFrom,omitempty"`
+}
+
+// ValueFrom is where a value is read from. Only one of EnvVar, File and SecretKeyRef is set.
+type ValueFrom struct {
+	EnvVar       string        `json:"envVar,omitempty" yaml:"envVar,omitempty"`
+	File         string        `json:"file,omitempty" yaml:"file,omitempty"`
+	SecretKeyRef *SecretKeyRef `json:"secretKeyRef,omitempty" yaml:"secretKeyRef,omitempty"`
+}
+
+type SecretKeyRef struct {
+	Namespace string `json:"namespace" yaml:"namespace"`
+	Name      string `json:"name" yaml:"name"`
+	Key       string `json:"key" yaml:"key"`
 }
 
 type Redact struct {
 	Name         string       `json:"name,omitempty" yaml:"name,omitempty"`
 	FileSelector FileSelector `json:"fileSelector,omitempty" yaml:"fileSelector,omitempty"`
 	Removals     Removals     `json:"removals,omitempty" yaml:"removals,omitempty"`
+	// MaskText replaces redacted values, ***HIDDEN*** by default
+	MaskText string `json:"maskText,omitempty" yaml:"maskText,omitempty"`
+	// Tokenize replaces redacted values with a token derived from the value instead of MaskText, such as
+	// ***HIDDEN-ab12f3c4d5e6***, so the same value can be correlated across files
+	Tokenize bool `json:"tokenize,omitempty" yaml:"tokenize,omitempty"`
 }
@@ -23,6 +23,9 @@ import (
 // RedactorSpec defines the desired state of Redactor
 type RedactorSpec struct {
 	Redactors []*Redact `json:"redactors,omitempty"`
+	// ExcludeDefaultRedactors are groups of the default redactors that are not applied, such as ipv4 or
+	// aws-keys, for default redactors that mask values which are not sensitive
+	ExcludeDefaultRedactors []string `json:"excludeDefaultRedactors,omitempty"`
 }
 
 // RedactorStatus defines the observed state of Redactor
@@ -11,6 +11,9 @@ type RemoteCollectorMeta struct {
 	CollectorName string `json:"collectorName,omitempty" yaml:"collectorName,omitempty"`
 	// +optional
 	Exclude *multitype.BoolOrString `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	// When is the opposite of Exclude: the collector is only run if it is true
+	// +optional
+	When *multitype.BoolOrString `json:"when,omitempty" yaml:"when,omitempty"`
 }
 
 type RemoteCPU struct {
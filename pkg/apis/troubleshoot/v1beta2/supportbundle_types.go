@@ -29,6 +29,15 @@ type SupportBundleSpec struct {
 	HostAnalyzers   []*HostAnalyze     `json:"hostAnalyzers,omitempty" yaml:"hostAnalyzers,omitempty"`
 	// URI optionally defines a location which is the source of this spec to allow updating of the spec at runtime
 	Uri string `json:"uri,omitempty" yaml:"uri,omitempty"`
+	// Imports optionally lists other specs (file paths, URLs or oci:// references) that are merged
+	// into this spec before it is run. Imported specs are merged first, so this spec acts as an overlay,
+	// and its settings take precedence over those of its imports.
+	Imports []string `json:"imports,omitempty" yaml:"imports,omitempty"`
+	// CollectConcurrency is how many collectors can run at the same time, by default they run one at a time
+	CollectConcurrency int `json:"collectConcurrency,omitempty" yaml:"collectConcurrency,omitempty"`
+	// NodeSelector restricts the collectors that collect from each node (sysctl, copyFromHost and
+	// nodeStats) to the nodes with these labels, to sample a large cluster or look at a single node pool
+	NodeSelector map[string]string `json:"nodeSelector,omitempty" yaml:"nodeSelector,omitempty"`
 }
 
 // SupportBundleStatus defines the observed state of SupportBundle
@@ -121,7 +121,7 @@ func (c *CollectCeph) Title() string {
 }
 
 func (c *CollectCeph) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 func (c *CollectCeph) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
@@ -181,6 +181,8 @@ func cephCommandExec(ctx context.Context, progressChan chan<- interface{}, c *Co
 			dstFileName = path.Join(pathPrefix, fmt.Sprintf("%s-stderr.txt", command.ID))
 		case strings.HasSuffix(srcFilename, "-errors.json"):
 			dstFileName = path.Join(pathPrefix, fmt.Sprintf("%s-errors.json", command.ID))
+		case strings.HasSuffix(srcFilename, "-"+ExecMetadataFilename):
+			dstFileName = path.Join(pathPrefix, fmt.Sprintf("%s-%s", command.ID, ExecMetadataFilename))
 		default:
 			continue
 		}
@@ -195,7 +197,7 @@ func cephCommandExec(ctx context.Context, progressChan chan<- interface{}, c *Co
 }
 
 func findRookCephToolsPod(ctx context.Context, c *CollectCeph, namespace string) (*corev1.Pod, error) {
-	client, err := kubernetes.NewForConfig(c.ClientConfig)
+	client, err := kubernetesClientOrDefault(c.Client, c.ClientConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create kubernetes client")
 	}
@@ -0,0 +1,162 @@
+package collect
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/troubleshoot/pkg/k8sutil"
+	"github.com/replicatedhq/troubleshoot/pkg/version"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// ClientFactory creates the clients that collectors make requests to the API server with. The clients
+// share one http client, so that collectors running at the same time reuse connections rather than each
+// opening their own, and all send the same user agent and use the same timeouts, which are the Timeout of
+// the config. Requests made by the clients are counted, for the collection metadata.
+type ClientFactory struct {
+	config     *rest.Config
+	httpClient *http.Client
+	kubernetes kubernetes.Interface
+	protobuf   kubernetes.Interface
+	dynamic    dynamic.Interface
+	stats      *clientStats
+}
+
+// ClientStats are the requests made by the clients of a ClientFactory
+type ClientStats struct {
+	Requests int
+	// Errors are the requests that failed to get a response, or got a 5xx response
+	Errors int
+}
+
+type clientStats struct {
+	requests int64
+	errors   int64
+}
+
+// NewClientFactory creates the clients from a copy of config. The user agent defaults to the one of
+// troubleshoot, with its version.
+func NewClientFactory(config *rest.Config) (*ClientFactory, error) {
+	stats := &clientStats{}
+
+	config = rest.CopyConfig(config)
+	if config.UserAgent == "" {
+		config.UserAgent = version.GetUserAgent()
+	}
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &statsRoundTripper{stats: stats, rt: rt}
+	})
+
+	httpClient, err := rest.HTTPClientFor(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create http client")
+	}
+
+	// built-in resources can be listed as protobuf, custom resources only as json
+	client, err := kubernetes.NewForConfigAndClient(config, httpClient)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create kubernetes clientset")
+	}
+	protobufClient, err := kubernetes.NewForConfigAndClient(k8sutil.ProtobufConfig(config), httpClient)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create kubernetes protobuf clientset")
+	}
+	dynamicClient, err := dynamic.NewForConfigAndClient(config, httpClient)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create dynamic client")
+	}
+
+	return &ClientFactory{
+		config:     config,
+		httpClient: httpClient,
+		kubernetes: client,
+		protobuf:   protobufClient,
+		dynamic:    dynamicClient,
+		stats:      stats,
+	}, nil
+}
+
+// clientsOrDefault returns clients, or creates them from config for collectors that were not given any
+func clientsOrDefault(clients *ClientFactory, config *rest.Config) (*ClientFactory, error) {
+	if clients != nil {
+		return clients, nil
+	}
+	return NewClientFactory(config)
+}
+
+// Config returns the config of the clients, which must not be modified. It is nil for a nil factory.
+func (f *ClientFactory) Config() *rest.Config {
+	if f == nil {
+		return nil
+	}
+	return f.config
+}
+
+// HTTPClient returns the http client that the clients share, for creating other clientsets that use the
+// same connections with NewForConfigAndClient
+func (f *ClientFactory) HTTPClient() *http.Client {
+	if f == nil {
+		return nil
+	}
+	return f.httpClient
+}
+
+// Kubernetes returns the clientset of the built-in resources. It is nil for a nil factory.
+func (f *ClientFactory) Kubernetes() kubernetes.Interface {
+	if f == nil {
+		return nil
+	}
+	return f.kubernetes
+}
+
+// ProtobufKubernetes returns a clientset of the built-in resources that requests them as protobuf,
+// which is faster to list many resources with
+func (f *ClientFactory) ProtobufKubernetes() kubernetes.Interface {
+	if f == nil {
+		return nil
+	}
+	return f.protobuf
+}
+
+func (f *ClientFactory) Dynamic() dynamic.Interface {
+	if f == nil {
+		return nil
+	}
+	return f.dynamic
+}
+
+// Stats returns the requests the clients have made so far
+func (f *ClientFactory) Stats() ClientStats {
+	if f == nil {
+		return ClientStats{}
+	}
+	return ClientStats{
+		Requests: int(atomic.LoadInt64(&f.stats.requests)),
+		Errors:   int(atomic.LoadInt64(&f.stats.errors)),
+	}
+}
+
+type statsRoundTripper struct {
+	stats *clientStats
+	rt    http.RoundTripper
+}
+
+func (rt *statsRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt64(&rt.stats.requests, 1)
+	resp, err := rt.rt.RoundTrip(req)
+	if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+		atomic.AddInt64(&rt.stats.errors, 1)
+	}
+	return resp, err
+}
+
+// kubernetesClientOrDefault returns client, or creates one from config for collectors that were not given one
+func kubernetesClientOrDefault(client kubernetes.Interface, config *rest.Config) (kubernetes.Interface, error) {
+	if client != nil {
+		return client, nil
+	}
+	return kubernetes.NewForConfig(config)
+}
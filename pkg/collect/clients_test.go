@@ -0,0 +1,82 @@
+package collect
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+func TestClientFactory(t *testing.T) {
+	var connections int32
+	userAgents := make(chan string, 10)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents <- r.UserAgent()
+		if r.URL.Path == "/apis/example.com/v1/widgets" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"kind": "List", "apiVersion": "v1", "metadata": {}, "items": []}`))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	config := &rest.Config{Host: server.URL}
+	clients, err := NewClientFactory(config)
+	require.NoError(t, err)
+	assert.Nil(t, config.WrapTransport)
+
+	ctx := context.Background()
+	_, err = clients.Kubernetes().CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	_, err = clients.ProtobufKubernetes().CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	_, err = clients.Dynamic().Resource(schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}).List(ctx, metav1.ListOptions{})
+	require.Error(t, err)
+
+	close(userAgents)
+	for userAgent := range userAgents {
+		assert.Equal(t, version.GetUserAgent(), userAgent)
+	}
+
+	// the clients share their connection
+	assert.Equal(t, int32(1), atomic.LoadInt32(&connections))
+	assert.Equal(t, ClientStats{Requests: 3, Errors: 1}, clients.Stats())
+}
+
+func TestClientFactory_nil(t *testing.T) {
+	var clients *ClientFactory
+	assert.Nil(t, clients.Config())
+	assert.Nil(t, clients.Kubernetes())
+	assert.Equal(t, ClientStats{}, clients.Stats())
+}
+
+func TestGetCollectorWithClients(t *testing.T) {
+	clients, err := NewClientFactory(&rest.Config{Host: "https://localhost:6443"})
+	require.NoError(t, err)
+
+	c, ok := GetCollectorWithClients(&troubleshootv1beta2.Collect{ClusterResources: &troubleshootv1beta2.ClusterResources{}}, "", "", clients, nil)
+	require.True(t, ok)
+	assert.Same(t, clients, c.(*CollectClusterResources).Clients)
+
+	c, ok = GetCollectorWithClients(&troubleshootv1beta2.Collect{Logs: &troubleshootv1beta2.Logs{}}, "", "", clients, nil)
+	require.True(t, ok)
+	assert.Same(t, clients.Config(), c.(*CollectLogs).ClientConfig)
+	assert.Equal(t, clients.Kubernetes(), c.(*CollectLogs).Client)
+}
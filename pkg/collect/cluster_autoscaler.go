@@ -0,0 +1,332 @@
+package collect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	DefaultClusterAutoscalerNamespace = "kube-system"
+	// ClusterAutoscalerStatusConfigMap is where the cluster autoscaler writes its status, unless it is
+	// run with another --status-config-map-name
+	ClusterAutoscalerStatusConfigMap = "cluster-autoscaler-status"
+)
+
+// nodeGroupLabels are the labels that put nodes in a node group that is scaled together, by provider
+var nodeGroupLabels = []string{
+	"eks.amazonaws.com/nodegroup",
+	"alpha.eksctl.io/nodegroup-name",
+	"cloud.google.com/gke-nodepool",
+	"kubernetes.azure.com/agentpool",
+	"karpenter.sh/nodepool",
+	"karpenter.sh/provisioner-name",
+}
+
+// nodeLifecycleEventReasons are the reasons of events that the node controller and kubelet record when
+// nodes are added, removed or become unavailable
+var nodeLifecycleEventReasons = map[string]bool{
+	"RegisteredNode":     true,
+	"RemovingNode":       true,
+	"DeletingNode":       true,
+	"NodeNotReady":       true,
+	"NodeReady":          true,
+	"NodeNotSchedulable": true,
+	"NodeSchedulable":    true,
+}
+
+// machineResources are the resources of the machine APIs that scale node groups, which are saved when
+// the cluster serves them and has any
+var machineResources = []schema.GroupVersionResource{
+	{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "machinedeployments"},
+	{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "machinesets"},
+	{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "machines"},
+	{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "machinehealthchecks"},
+	{Group: "machine.openshift.io", Version: "v1beta1", Resource: "machinesets"},
+	{Group: "machine.openshift.io", Version: "v1beta1", Resource: "machines"},
+	{Group: "karpenter.sh", Version: "v1", Resource: "nodepools"},
+	{Group: "karpenter.sh", Version: "v1", Resource: "nodeclaims"},
+}
+
+type NodeGroup struct {
+	Label         string   `json:"label"`
+	Name          string   `json:"name"`
+	Nodes         []string `json:"nodes"`
+	Ready         int      `json:"ready"`
+	Unschedulable int      `json:"unschedulable"`
+	InstanceTypes []string `json:"instanceTypes,omitempty"`
+	Zones         []string `json:"zones,omitempty"`
+}
+
+type CollectClusterAutoscaler struct {
+	Collector    *troubleshootv1beta2.ClusterAutoscaler
+	BundlePath   string
+	Namespace    string
+	ClientConfig *rest.Config
+	Client       kubernetes.Interface
+	Clients      *ClientFactory
+	Context      context.Context
+	SinceTime    *time.Time
+	RBACErrors
+}
+
+func (c *CollectClusterAutoscaler) Title() string {
+	return getCollectorName(c)
+}
+
+func (c *CollectClusterAutoscaler) IsExcluded() (bool, error) {
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
+}
+
+func (c *CollectClusterAutoscaler) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
+	clients, err := clientsOrDefault(c.Clients, c.ClientConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create dynamic client")
+	}
+
+	return c.collect(contextOrBackground(c.Context), clients.Kubernetes(), clients.Dynamic()), nil
+}
+
+func (c *CollectClusterAutoscaler) collect(ctx context.Context, client kubernetes.Interface, dynamicClient dynamic.Interface) CollectorResult {
+	ns := DefaultClusterAutoscalerNamespace
+	if c.Collector.Namespace != "" {
+		ns = c.Collector.Namespace
+	}
+
+	dir := "cluster-autoscaler"
+	if c.Collector.CollectorName != "" {
+		dir = path.Join(dir, c.Collector.CollectorName)
+	}
+
+	output := NewResult()
+	errorList := map[string]string{}
+
+	// status
+	configMap, err := client.CoreV1().ConfigMaps(ns).Get(ctx, ClusterAutoscalerStatusConfigMap, metav1.GetOptions{})
+	if err != nil {
+		errorList["status"] = err.Error()
+	} else if status, ok := configMap.Data["status"]; ok {
+		output.SaveResult(c.BundlePath, path.Join(dir, "status.txt"), bytes.NewBufferString(status))
+	}
+
+	// logs
+	if c.SinceTime != nil {
+		if c.Collector.Limits == nil {
+			c.Collector.Limits = new(troubleshootv1beta2.LogLimits)
+		}
+		c.Collector.Limits.SinceTime = metav1.NewTime(*c.SinceTime)
+	}
+	pods, podsErrors := c.listPods(ctx, client, ns)
+	if len(podsErrors) > 0 {
+		errorList["pods"] = strings.Join(podsErrors, ", ")
+	}
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			podLogs, err := savePodLogs(ctx, c.BundlePath, client, pod, path.Join(dir, "logs"), container.Name, c.Collector.Limits, logsAll, false)
+			if err != nil {
+				errorList["logs/"+pod.Name+"/"+container.Name] = err.Error()
+				continue
+			}
+			for k, v := range podLogs {
+				output[k] = v
+			}
+		}
+	}
+
+	// events
+	events, err := autoscalerEvents(ctx, client)
+	if err != nil {
+		errorList["events"] = err.Error()
+	} else if b, err := json.MarshalIndent(eventsSince(events, c.SinceTime), "", "  "); err != nil {
+		errorList["events"] = err.Error()
+	} else {
+		output.SaveResult(c.BundlePath, path.Join(dir, "events.json"), bytes.NewBuffer(b))
+	}
+
+	// node groups
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		errorList["nodes"] = err.Error()
+	} else if b, err := json.MarshalIndent(nodeGroups(nodes.Items), "", "  "); err != nil {
+		errorList["nodes"] = err.Error()
+	} else {
+		output.SaveResult(c.BundlePath, path.Join(dir, "node-groups.json"), bytes.NewBuffer(b))
+	}
+
+	// machines
+	for _, gvr := range machineResources {
+		list, err := dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if kuberneteserrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			errorList[gvr.GroupResource().String()] = err.Error()
+			continue
+		}
+		if len(list.Items) == 0 {
+			continue
+		}
+
+		b, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			errorList[gvr.GroupResource().String()] = err.Error()
+			continue
+		}
+		output.SaveResult(c.BundlePath, path.Join(dir, "machines", gvr.GroupResource().String()+".json"), bytes.NewBuffer(b))
+	}
+
+	output.SaveResult(c.BundlePath, path.Join(dir, "errors.json"), marshalErrors(errorList))
+
+	return output
+}
+
+// listPods lists the pods selected by the collector, or the pods with a cluster-autoscaler image, as the
+// labels of the cluster autoscaler differ between its manifests and charts
+func (c *CollectClusterAutoscaler) listPods(ctx context.Context, client kubernetes.Interface, namespace string) ([]corev1.Pod, []string) {
+	if len(c.Collector.Selector) > 0 {
+		return listPodsInSelectors(ctx, client, namespace, c.Collector.Selector)
+	}
+
+	pods, errs := listPodsInSelectors(ctx, client, namespace, nil)
+	autoscalerPods := []corev1.Pod{}
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			if strings.Contains(container.Image, "cluster-autoscaler") {
+				autoscalerPods = append(autoscalerPods, pod)
+				break
+			}
+		}
+	}
+	return autoscalerPods, errs
+}
+
+// autoscalerEvents returns the events of the cluster autoscaler and the node lifecycle events in all
+// namespaces, oldest first
+func autoscalerEvents(ctx context.Context, client kubernetes.Interface) ([]corev1.Event, error) {
+	events := []corev1.Event{}
+	seen := map[types.UID]bool{}
+
+	for _, fieldSelector := range []string{"source=cluster-autoscaler", "involvedObject.kind=Node"} {
+		list, err := client.CoreV1().Events("").List(ctx, metav1.ListOptions{FieldSelector: fieldSelector})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list events with %s", fieldSelector)
+		}
+
+		for _, event := range list.Items {
+			if seen[event.UID] {
+				continue
+			}
+			if event.Source.Component != "cluster-autoscaler" && event.ReportingController != "cluster-autoscaler" &&
+				!(event.InvolvedObject.Kind == "Node" && nodeLifecycleEventReasons[event.Reason]) {
+				continue
+			}
+			seen[event.UID] = true
+			events = append(events, event)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+
+	return events, nil
+}
+
+func eventTime(event corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	return event.EventTime.Time
+}
+
+// nodeGroups groups nodes by the labels of the providers' node groups. Nodes that are in no node group are
+// not included.
+func nodeGroups(nodes []corev1.Node) []NodeGroup {
+	groups := map[string]*NodeGroup{}
+	instanceTypes := map[string]map[string]bool{}
+	zones := map[string]map[string]bool{}
+
+	for _, node := range nodes {
+		for _, label := range nodeGroupLabels {
+			name, ok := node.Labels[label]
+			if !ok {
+				continue
+			}
+
+			key := label + "=" + name
+			group, ok := groups[key]
+			if !ok {
+				group = &NodeGroup{Label: label, Name: name, Nodes: []string{}}
+				groups[key] = group
+				instanceTypes[key] = map[string]bool{}
+				zones[key] = map[string]bool{}
+			}
+
+			group.Nodes = append(group.Nodes, node.Name)
+			if isNodeReady(node) {
+				group.Ready++
+			}
+			if node.Spec.Unschedulable {
+				group.Unschedulable++
+			}
+			if instanceType := node.Labels[corev1.LabelInstanceTypeStable]; instanceType != "" {
+				instanceTypes[key][instanceType] = true
+			}
+			if zone := node.Labels[corev1.LabelTopologyZone]; zone != "" {
+				zones[key][zone] = true
+			}
+			break
+		}
+	}
+
+	result := []NodeGroup{}
+	for key, group := range groups {
+		group.InstanceTypes = sortedKeys(instanceTypes[key])
+		group.Zones = sortedKeys(zones[key])
+		sort.Strings(group.Nodes)
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Label != result[j].Label {
+			return result[i].Label < result[j].Label
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+func isNodeReady(node corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]bool) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
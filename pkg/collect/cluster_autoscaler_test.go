@@ -0,0 +1,190 @@
+package collect
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCollectClusterAutoscaler_collect(t *testing.T) {
+	now := time.Now()
+	pod := corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "web"}
+	node := corev1.ObjectReference{Kind: "Node", Name: "node-3"}
+
+	client := fake.NewSimpleClientset(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: ClusterAutoscalerStatusConfigMap, Namespace: "kube-system"},
+			Data:       map[string]string{"status": "Cluster-wide:\n  Health: Healthy\n"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-autoscaler-abc", Namespace: "kube-system"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "aws-cluster-autoscaler", Image: "registry.k8s.io/autoscaling/cluster-autoscaler:v1.27.2"},
+			}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "coredns-abc", Namespace: "kube-system"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "coredns", Image: "registry.k8s.io/coredns/coredns:v1.10.1"},
+			}},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"eks.amazonaws.com/nodegroup": "workers", corev1.LabelInstanceTypeStable: "m5.large", corev1.LabelTopologyZone: "us-east-1a"}},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-2", Labels: map[string]string{"eks.amazonaws.com/nodegroup": "workers", corev1.LabelInstanceTypeStable: "m5.xlarge", corev1.LabelTopologyZone: "us-east-1a"}},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionFalse}},
+			},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-3"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "scale-up", Namespace: "default", UID: "scale-up"},
+			InvolvedObject: pod,
+			Reason:         "TriggeredScaleUp",
+			Source:         corev1.EventSource{Component: "cluster-autoscaler"},
+			LastTimestamp:  metav1.NewTime(now.Add(-time.Minute)),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "registered", Namespace: "default", UID: "registered"},
+			InvolvedObject: node,
+			Reason:         "RegisteredNode",
+			Source:         corev1.EventSource{Component: "node-controller"},
+			LastTimestamp:  metav1.NewTime(now.Add(-2 * time.Minute)),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "scheduled", Namespace: "default", UID: "scheduled"},
+			InvolvedObject: pod,
+			Reason:         "Scheduled",
+			Source:         corev1.EventSource{Component: "default-scheduler"},
+			LastTimestamp:  metav1.NewTime(now),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "pulled", Namespace: "default", UID: "pulled"},
+			InvolvedObject: node,
+			Reason:         "Pulled",
+			Source:         corev1.EventSource{Component: "kubelet"},
+			LastTimestamp:  metav1.NewTime(now),
+		},
+	)
+
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, gvr := range machineResources {
+		listKinds[gvr] = "List"
+	}
+	machineSet := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cluster.x-k8s.io/v1beta1",
+		"kind":       "MachineSet",
+		"metadata":   map[string]interface{}{"name": "workers", "namespace": "default"},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, machineSet)
+
+	c := &CollectClusterAutoscaler{
+		Collector:  &troubleshootv1beta2.ClusterAutoscaler{},
+		BundlePath: t.TempDir(),
+	}
+	result := c.collect(context.Background(), client, dynamicClient)
+
+	assert.ElementsMatch(t, []string{
+		"cluster-autoscaler/status.txt",
+		"cluster-autoscaler/logs/cluster-autoscaler-abc/aws-cluster-autoscaler.log",
+		"cluster-autoscaler/logs/cluster-autoscaler-abc/aws-cluster-autoscaler-previous.log",
+		"cluster-autoscaler/events.json",
+		"cluster-autoscaler/node-groups.json",
+		"cluster-autoscaler/machines/machinesets.cluster.x-k8s.io.json",
+	}, resultPaths(result))
+
+	b, err := os.ReadFile(filepath.Join(c.BundlePath, "cluster-autoscaler/status.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "Cluster-wide:\n  Health: Healthy\n", string(b))
+
+	b, err = os.ReadFile(filepath.Join(c.BundlePath, "cluster-autoscaler/events.json"))
+	require.NoError(t, err)
+	var events []corev1.Event
+	require.NoError(t, json.Unmarshal(b, &events))
+	reasons := []string{}
+	for _, event := range events {
+		reasons = append(reasons, event.Reason)
+	}
+	assert.Equal(t, []string{"RegisteredNode", "TriggeredScaleUp"}, reasons)
+
+	b, err = os.ReadFile(filepath.Join(c.BundlePath, "cluster-autoscaler/node-groups.json"))
+	require.NoError(t, err)
+	var groups []NodeGroup
+	require.NoError(t, json.Unmarshal(b, &groups))
+	assert.Equal(t, []NodeGroup{
+		{
+			Label:         "eks.amazonaws.com/nodegroup",
+			Name:          "workers",
+			Nodes:         []string{"node-1", "node-2"},
+			Ready:         1,
+			InstanceTypes: []string{"m5.large", "m5.xlarge"},
+			Zones:         []string{"us-east-1a"},
+		},
+	}, groups)
+}
+
+func TestCollectClusterAutoscaler_listPods(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "autoscaler", Namespace: "kube-system", Labels: map[string]string{"app": "autoscaler"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "example.com/autoscaler:1"}}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-autoscaler", Namespace: "kube-system"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "registry.k8s.io/autoscaling/cluster-autoscaler:v1.27.2"}}},
+		},
+	)
+
+	tests := []struct {
+		name     string
+		selector []string
+		want     []string
+	}{
+		{
+			name: "image",
+			want: []string{"cluster-autoscaler"},
+		},
+		{
+			name:     "selector",
+			selector: []string{"app=autoscaler"},
+			want:     []string{"autoscaler"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &CollectClusterAutoscaler{Collector: &troubleshootv1beta2.ClusterAutoscaler{Selector: tt.selector}}
+
+			pods, errs := c.listPods(context.Background(), client, "kube-system")
+			require.Empty(t, errs)
+
+			names := []string{}
+			for _, pod := range pods {
+				names = append(names, pod.Name)
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
@@ -22,6 +22,7 @@ type CollectClusterInfo struct {
 	BundlePath   string
 	Namespace    string
 	ClientConfig *rest.Config
+	Client       kubernetes.Interface
 	RBACErrors
 }
 
@@ -30,11 +31,11 @@ func (c *CollectClusterInfo) Title() string {
 }
 
 func (c *CollectClusterInfo) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 func (c *CollectClusterInfo) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
-	client, err := kubernetes.NewForConfig(c.ClientConfig)
+	client, err := kubernetesClientOrDefault(c.Client, c.ClientConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to create kubernetes clientset")
 	}
@@ -49,8 +50,8 @@ func (c *CollectClusterInfo) Collect(progressChan chan<- interface{}) (Collector
 	return output, nil
 }
 
-func clusterVersion(client *kubernetes.Clientset) ([]byte, []string) {
-	k8sVersion, err := client.ServerVersion()
+func clusterVersion(client kubernetes.Interface) ([]byte, []string) {
+	k8sVersion, err := client.Discovery().ServerVersion()
 	if err != nil {
 		return nil, []string{err.Error()}
 	}
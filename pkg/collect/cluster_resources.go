@@ -10,19 +10,31 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
 	"github.com/replicatedhq/troubleshoot/pkg/k8sutil"
 	"gopkg.in/yaml.v2"
+	appsv1 "k8s.io/api/apps/v1"
 	authorizationv1 "k8s.io/api/authorization/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	storagev1beta1 "k8s.io/api/storage/v1beta1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	apiextensionsv1clientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
 	apiextensionsv1beta1clientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1beta1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/dynamic"
@@ -39,6 +51,9 @@ type CollectClusterResources struct {
 	BundlePath   string
 	Namespace    string
 	ClientConfig *rest.Config
+	Clients      *ClientFactory
+	Context      context.Context
+	SinceTime    *time.Time
 	RBACErrors
 }
 
@@ -47,7 +62,7 @@ func (c *CollectClusterResources) Title() string {
 }
 
 func (c *CollectClusterResources) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 func (c *CollectClusterResources) Merge(allCollectors []Collector) ([]Collector, error) {
@@ -56,17 +71,16 @@ func (c *CollectClusterResources) Merge(allCollectors []Collector) ([]Collector,
 }
 
 func (c *CollectClusterResources) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
-	client, err := kubernetes.NewForConfig(c.ClientConfig)
+	clients, err := clientsOrDefault(c.Clients, c.ClientConfig)
 	if err != nil {
 		return nil, err
 	}
 
-	dynamicClient, err := dynamic.NewForConfig(c.ClientConfig)
-	if err != nil {
-		return nil, err
-	}
+	// built-in resources are listed as protobuf, custom resources can only be listed as json
+	client := clients.ProtobufKubernetes()
+	dynamicClient := clients.Dynamic()
 
-	ctx := context.Background()
+	ctx := contextOrBackground(c.Context)
 	output := NewResult()
 
 	// namespaces
@@ -124,20 +138,17 @@ func (c *CollectClusterResources) Collect(progressChan chan<- interface{}) (Coll
 	for _, pod := range unhealthyPods {
 		allContainers := append(pod.Spec.InitContainers, pod.Spec.Containers...)
 		for _, container := range allContainers {
-			logsRoot := ""
-			if c.BundlePath != "" {
-				logsRoot = path.Join(c.BundlePath, "cluster-resources", "pods", "logs", pod.Namespace)
-			}
+			logsDir := path.Join("cluster-resources", "pods", "logs", pod.Namespace)
 			limits := &troubleshootv1beta2.LogLimits{
 				MaxLines: 500,
 			}
-			podLogs, err := savePodLogs(ctx, logsRoot, client, pod, "", container.Name, limits, false)
+			podLogs, err := savePodLogs(ctx, c.BundlePath, client, pod, logsDir, container.Name, limits, logsAll, false)
 			if err != nil {
 				errPath := filepath.Join("cluster-resources", "pods", "logs", pod.Namespace, pod.Name, fmt.Sprintf("%s-logs-errors.log", container.Name))
 				output.SaveResult(c.BundlePath, errPath, bytes.NewBuffer([]byte(err.Error())))
 			}
 			for k, v := range podLogs {
-				output[filepath.Join("cluster-resources", "pods", "logs", pod.Namespace, k)] = v
+				output[k] = v
 			}
 		}
 	}
@@ -171,6 +182,13 @@ func (c *CollectClusterResources) Collect(progressChan chan<- interface{}) (Coll
 	}
 	output.SaveResult(c.BundlePath, "cluster-resources/statefulsets-errors.json", marshalErrors(statefulsetsErrors))
 
+	// daemonsets
+	daemonsets, daemonsetsErrors := daemonsets(ctx, client, namespaceNames)
+	for k, v := range daemonsets {
+		output.SaveResult(c.BundlePath, path.Join("cluster-resources/daemonsets", k), bytes.NewBuffer(v))
+	}
+	output.SaveResult(c.BundlePath, "cluster-resources/daemonsets-errors.json", marshalErrors(daemonsetsErrors))
+
 	// replicasets
 	replicasets, replicasetsErrors := replicasets(ctx, client, namespaceNames)
 	for k, v := range replicasets {
@@ -219,17 +237,29 @@ func (c *CollectClusterResources) Collect(progressChan chan<- interface{}) (Coll
 	output.SaveResult(c.BundlePath, "cluster-resources/storage-errors.json", marshalErrors(storageErrors))
 
 	// crds
-	customResourceDefinitions, crdErrors := crds(ctx, client, c.ClientConfig)
+	customResourceDefinitions, crdErrors := crds(ctx, client, clients)
 	output.SaveResult(c.BundlePath, "cluster-resources/custom-resource-definitions.json", bytes.NewBuffer(customResourceDefinitions))
 	output.SaveResult(c.BundlePath, "cluster-resources/custom-resource-definitions-errors.json", marshalErrors(crdErrors))
 
 	// crs
-	customResources, crErrors := crs(ctx, dynamicClient, client, c.ClientConfig, namespaceNames)
+	customResources, crErrors := crs(ctx, dynamicClient, client, clients, namespaceNames)
 	for k, v := range customResources {
 		output.SaveResult(c.BundlePath, fmt.Sprintf("cluster-resources/custom-resources/%v", k), bytes.NewBuffer(v))
 	}
 	output.SaveResult(c.BundlePath, "cluster-resources/custom-resources/custom-resources-errors.json", marshalErrors(crErrors))
 
+	// openshift
+	openshift, err := isOpenShift(client.Discovery())
+	if err != nil {
+		output.SaveResult(c.BundlePath, "cluster-resources/openshift-errors.json", marshalErrors(map[string]string{"discover config.openshift.io/v1": err.Error()}))
+	} else if openshift {
+		openshiftResources, openshiftErrors := openshiftResources(ctx, dynamicClient, namespaceNames)
+		for k, v := range openshiftResources {
+			output.SaveResult(c.BundlePath, path.Join("cluster-resources/openshift", k), bytes.NewBuffer(v))
+		}
+		output.SaveResult(c.BundlePath, "cluster-resources/openshift-errors.json", marshalErrors(openshiftErrors))
+	}
+
 	// imagepullsecrets
 	imagePullSecrets, pullSecretsErrors := imagePullSecrets(ctx, client, namespaceNames)
 	for k, v := range imagePullSecrets {
@@ -255,7 +285,7 @@ func (c *CollectClusterResources) Collect(progressChan chan<- interface{}) (Coll
 	output.SaveResult(c.BundlePath, "cluster-resources/limitranges-errors.json", marshalErrors(limitRangesErrors))
 
 	//Events
-	events, eventsErrors := events(ctx, client, namespaceNames)
+	events, eventsErrors := events(ctx, client, namespaceNames, c.SinceTime)
 	for k, v := range events {
 		output.SaveResult(c.BundlePath, path.Join("cluster-resources/events", k), bytes.NewBuffer(v))
 	}
@@ -300,8 +330,11 @@ func (c *CollectClusterResources) Collect(progressChan chan<- interface{}) (Coll
 	return output, nil
 }
 
-func getAllNamespaces(ctx context.Context, client *kubernetes.Clientset) ([]byte, *corev1.NamespaceList, []string) {
-	namespaces, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+func getAllNamespaces(ctx context.Context, client kubernetes.Interface) ([]byte, *corev1.NamespaceList, []string) {
+	namespaces := &corev1.NamespaceList{}
+	err := k8sutil.ListAll(ctx, namespaces, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return client.CoreV1().Namespaces().List(ctx, opts)
+	})
 	if err != nil {
 		return nil, nil, []string{err.Error()}
 	}
@@ -326,7 +359,7 @@ func getAllNamespaces(ctx context.Context, client *kubernetes.Clientset) ([]byte
 	return b, namespaces, nil
 }
 
-func getNamespaces(ctx context.Context, client *kubernetes.Clientset, namespaces []string) ([]byte, []string) {
+func getNamespaces(ctx context.Context, client kubernetes.Interface, namespaces []string) ([]byte, []string) {
 	namespacesArr := []*corev1.Namespace{}
 	errorsArr := []string{}
 
@@ -348,7 +381,7 @@ func getNamespaces(ctx context.Context, client *kubernetes.Clientset, namespaces
 	return b, errorsArr
 }
 
-func getNamespace(ctx context.Context, client *kubernetes.Clientset, namespace string) ([]byte, []string) {
+func getNamespace(ctx context.Context, client kubernetes.Interface, namespace string) ([]byte, []string) {
 	ns, err := client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
 	if err != nil {
 		return nil, []string{err.Error()}
@@ -367,13 +400,16 @@ func getNamespace(ctx context.Context, client *kubernetes.Clientset, namespace s
 	return b, nil
 }
 
-func pods(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string, []corev1.Pod) {
+func pods(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string, []corev1.Pod) {
 	podsByNamespace := make(map[string][]byte)
 	errorsByNamespace := make(map[string]string)
 	unhealthyPods := []corev1.Pod{}
 
 	for _, namespace := range namespaces {
-		pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		pods := &corev1.PodList{}
+		err := k8sutil.ListAll(ctx, pods, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.CoreV1().Pods(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errorsByNamespace[namespace] = err.Error()
 			continue
@@ -409,8 +445,8 @@ func pods(ctx context.Context, client *kubernetes.Clientset, namespaces []string
 	return podsByNamespace, errorsByNamespace, unhealthyPods
 }
 
-func getPodDisruptionBudgets(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
-	ok, err := discovery.HasResource(client, "policy.k8s.io/v1", "PodDisruptionBudgets")
+func getPodDisruptionBudgets(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
+	ok, err := discovery.HasResource(client.Discovery(), "policy.k8s.io/v1", "PodDisruptionBudgets")
 	if err != nil {
 		return nil, map[string]string{"": err.Error()}
 	}
@@ -422,12 +458,15 @@ func getPodDisruptionBudgets(ctx context.Context, client *kubernetes.Clientset,
 }
 
 // TODO: The below function (`pdbV1`) needs to be DRY'd and moved into the main `getPodDisruptionBudgets` function.
-func pdbV1(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
+func pdbV1(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
 	pdbByNamespace := make(map[string][]byte)
 	errorsByNamespace := make(map[string]string)
 
 	for _, namespace := range namespaces {
-		PodDisruptionBudgets, err := client.PolicyV1().PodDisruptionBudgets(namespace).List(ctx, metav1.ListOptions{})
+		PodDisruptionBudgets := &policyv1.PodDisruptionBudgetList{}
+		err := k8sutil.ListAll(ctx, PodDisruptionBudgets, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.PolicyV1().PodDisruptionBudgets(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errorsByNamespace[namespace] = err.Error()
 			continue
@@ -458,12 +497,15 @@ func pdbV1(ctx context.Context, client *kubernetes.Clientset, namespaces []strin
 }
 
 // This block/function can remain as is
-func pdbV1beta(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
+func pdbV1beta(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
 	pdbByNamespace := make(map[string][]byte)
 	errorsByNamespace := make(map[string]string)
 
 	for _, namespace := range namespaces {
-		PodDisruptionBudgets, err := client.PolicyV1beta1().PodDisruptionBudgets(namespace).List(ctx, metav1.ListOptions{})
+		PodDisruptionBudgets := &policyv1beta1.PodDisruptionBudgetList{}
+		err := k8sutil.ListAll(ctx, PodDisruptionBudgets, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.PolicyV1beta1().PodDisruptionBudgets(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errorsByNamespace[namespace] = err.Error()
 			continue
@@ -493,12 +535,15 @@ func pdbV1beta(ctx context.Context, client *kubernetes.Clientset, namespaces []s
 	return pdbByNamespace, errorsByNamespace
 }
 
-func services(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
+func services(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
 	servicesByNamespace := make(map[string][]byte)
 	errorsByNamespace := make(map[string]string)
 
 	for _, namespace := range namespaces {
-		services, err := client.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+		services := &corev1.ServiceList{}
+		err := k8sutil.ListAll(ctx, services, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.CoreV1().Services(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errorsByNamespace[namespace] = err.Error()
 			continue
@@ -528,12 +573,15 @@ func services(ctx context.Context, client *kubernetes.Clientset, namespaces []st
 	return servicesByNamespace, errorsByNamespace
 }
 
-func deployments(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
+func deployments(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
 	deploymentsByNamespace := make(map[string][]byte)
 	errorsByNamespace := make(map[string]string)
 
 	for _, namespace := range namespaces {
-		deployments, err := client.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+		deployments := &appsv1.DeploymentList{}
+		err := k8sutil.ListAll(ctx, deployments, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.AppsV1().Deployments(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errorsByNamespace[namespace] = err.Error()
 			continue
@@ -563,12 +611,15 @@ func deployments(ctx context.Context, client *kubernetes.Clientset, namespaces [
 	return deploymentsByNamespace, errorsByNamespace
 }
 
-func statefulsets(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
+func statefulsets(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
 	statefulsetsByNamespace := make(map[string][]byte)
 	errorsByNamespace := make(map[string]string)
 
 	for _, namespace := range namespaces {
-		statefulsets, err := client.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+		statefulsets := &appsv1.StatefulSetList{}
+		err := k8sutil.ListAll(ctx, statefulsets, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.AppsV1().StatefulSets(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errorsByNamespace[namespace] = err.Error()
 			continue
@@ -598,12 +649,53 @@ func statefulsets(ctx context.Context, client *kubernetes.Clientset, namespaces
 	return statefulsetsByNamespace, errorsByNamespace
 }
 
-func replicasets(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
+func daemonsets(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
+	daemonsetsByNamespace := make(map[string][]byte)
+	errorsByNamespace := make(map[string]string)
+
+	for _, namespace := range namespaces {
+		daemonsets := &appsv1.DaemonSetList{}
+		err := k8sutil.ListAll(ctx, daemonsets, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.AppsV1().DaemonSets(namespace).List(ctx, opts)
+		})
+		if err != nil {
+			errorsByNamespace[namespace] = err.Error()
+			continue
+		}
+
+		gvk, err := apiutil.GVKForObject(daemonsets, scheme.Scheme)
+		if err == nil {
+			daemonsets.GetObjectKind().SetGroupVersionKind(gvk)
+		}
+
+		for i, o := range daemonsets.Items {
+			gvk, err := apiutil.GVKForObject(&o, scheme.Scheme)
+			if err == nil {
+				daemonsets.Items[i].GetObjectKind().SetGroupVersionKind(gvk)
+			}
+		}
+
+		b, err := json.MarshalIndent(daemonsets, "", "  ")
+		if err != nil {
+			errorsByNamespace[namespace] = err.Error()
+			continue
+		}
+
+		daemonsetsByNamespace[namespace+".json"] = b
+	}
+
+	return daemonsetsByNamespace, errorsByNamespace
+}
+
+func replicasets(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
 	replicasetsByNamespace := make(map[string][]byte)
 	errorsByNamespace := make(map[string]string)
 
 	for _, namespace := range namespaces {
-		replicasets, err := client.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+		replicasets := &appsv1.ReplicaSetList{}
+		err := k8sutil.ListAll(ctx, replicasets, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.AppsV1().ReplicaSets(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errorsByNamespace[namespace] = err.Error()
 			continue
@@ -633,12 +725,15 @@ func replicasets(ctx context.Context, client *kubernetes.Clientset, namespaces [
 	return replicasetsByNamespace, errorsByNamespace
 }
 
-func jobs(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
+func jobs(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
 	jobsByNamespace := make(map[string][]byte)
 	errorsByNamespace := make(map[string]string)
 
 	for _, namespace := range namespaces {
-		nsJobs, err := client.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+		nsJobs := &batchv1.JobList{}
+		err := k8sutil.ListAll(ctx, nsJobs, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.BatchV1().Jobs(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errorsByNamespace[namespace] = err.Error()
 			continue
@@ -668,8 +763,8 @@ func jobs(ctx context.Context, client *kubernetes.Clientset, namespaces []string
 	return jobsByNamespace, errorsByNamespace
 }
 
-func cronJobs(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
-	ok, err := discovery.HasResource(client, "batch.k8s.io/v1", "CronJobs")
+func cronJobs(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
+	ok, err := discovery.HasResource(client.Discovery(), "batch.k8s.io/v1", "CronJobs")
 	if err != nil {
 		return nil, map[string]string{"": err.Error()}
 	}
@@ -680,12 +775,15 @@ func cronJobs(ctx context.Context, client *kubernetes.Clientset, namespaces []st
 	return cronJobsV1beta(ctx, client, namespaces)
 }
 
-func cronJobsV1(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
+func cronJobsV1(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
 	cronJobsByNamespace := make(map[string][]byte)
 	errorsByNamespace := make(map[string]string)
 
 	for _, namespace := range namespaces {
-		cronJobs, err := client.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+		cronJobs := &batchv1.CronJobList{}
+		err := k8sutil.ListAll(ctx, cronJobs, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.BatchV1().CronJobs(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errorsByNamespace[namespace] = err.Error()
 			continue
@@ -715,12 +813,15 @@ func cronJobsV1(ctx context.Context, client *kubernetes.Clientset, namespaces []
 	return cronJobsByNamespace, errorsByNamespace
 }
 
-func cronJobsV1beta(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
+func cronJobsV1beta(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
 	cronJobsByNamespace := make(map[string][]byte)
 	errorsByNamespace := make(map[string]string)
 
 	for _, namespace := range namespaces {
-		cronJobs, err := client.BatchV1beta1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+		cronJobs := &batchv1beta1.CronJobList{}
+		err := k8sutil.ListAll(ctx, cronJobs, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.BatchV1beta1().CronJobs(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errorsByNamespace[namespace] = err.Error()
 			continue
@@ -750,8 +851,8 @@ func cronJobsV1beta(ctx context.Context, client *kubernetes.Clientset, namespace
 	return cronJobsByNamespace, errorsByNamespace
 }
 
-func ingress(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
-	ok, err := discovery.HasResource(client, "networking.k8s.io/v1", "Ingress")
+func ingress(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
+	ok, err := discovery.HasResource(client.Discovery(), "networking.k8s.io/v1", "Ingress")
 	if err != nil {
 		return nil, map[string]string{"": err.Error()}
 	}
@@ -762,12 +863,15 @@ func ingress(ctx context.Context, client *kubernetes.Clientset, namespaces []str
 	return ingressV1beta(ctx, client, namespaces)
 }
 
-func ingressV1(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
+func ingressV1(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
 	ingressByNamespace := make(map[string][]byte)
 	errorsByNamespace := make(map[string]string)
 
 	for _, namespace := range namespaces {
-		ingress, err := client.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+		ingress := &networkingv1.IngressList{}
+		err := k8sutil.ListAll(ctx, ingress, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.NetworkingV1().Ingresses(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errorsByNamespace[namespace] = err.Error()
 			continue
@@ -797,12 +901,15 @@ func ingressV1(ctx context.Context, client *kubernetes.Clientset, namespaces []s
 	return ingressByNamespace, errorsByNamespace
 }
 
-func ingressV1beta(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
+func ingressV1beta(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
 	ingressByNamespace := make(map[string][]byte)
 	errorsByNamespace := make(map[string]string)
 
 	for _, namespace := range namespaces {
-		ingress, err := client.ExtensionsV1beta1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+		ingress := &extensionsv1beta1.IngressList{}
+		err := k8sutil.ListAll(ctx, ingress, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.ExtensionsV1beta1().Ingresses(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errorsByNamespace[namespace] = err.Error()
 			continue
@@ -832,12 +939,15 @@ func ingressV1beta(ctx context.Context, client *kubernetes.Clientset, namespaces
 	return ingressByNamespace, errorsByNamespace
 }
 
-func networkPolicy(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
+func networkPolicy(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
 	networkPolicyByNamespace := make(map[string][]byte)
 	errorsByNamespace := make(map[string]string)
 
 	for _, namespace := range namespaces {
-		networkPolicy, err := client.NetworkingV1().NetworkPolicies(namespace).List(ctx, metav1.ListOptions{})
+		networkPolicy := &networkingv1.NetworkPolicyList{}
+		err := k8sutil.ListAll(ctx, networkPolicy, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.NetworkingV1().NetworkPolicies(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errorsByNamespace[namespace] = err.Error()
 			continue
@@ -867,12 +977,15 @@ func networkPolicy(ctx context.Context, client *kubernetes.Clientset, namespaces
 	return networkPolicyByNamespace, errorsByNamespace
 }
 
-func resourceQuota(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
+func resourceQuota(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
 	resourceQuotaByNamespace := make(map[string][]byte)
 	errorsByNamespace := make(map[string]string)
 
 	for _, namespace := range namespaces {
-		resourceQuota, err := client.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+		resourceQuota := &corev1.ResourceQuotaList{}
+		err := k8sutil.ListAll(ctx, resourceQuota, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.CoreV1().ResourceQuotas(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errorsByNamespace[namespace] = err.Error()
 			continue
@@ -902,8 +1015,8 @@ func resourceQuota(ctx context.Context, client *kubernetes.Clientset, namespaces
 	return resourceQuotaByNamespace, errorsByNamespace
 }
 
-func storageClasses(ctx context.Context, client *kubernetes.Clientset) ([]byte, []string) {
-	ok, err := discovery.HasResource(client, "storage.k8s.io/v1", "StorageClass")
+func storageClasses(ctx context.Context, client kubernetes.Interface) ([]byte, []string) {
+	ok, err := discovery.HasResource(client.Discovery(), "storage.k8s.io/v1", "StorageClass")
 	if err != nil {
 		return nil, []string{err.Error()}
 	}
@@ -914,8 +1027,11 @@ func storageClasses(ctx context.Context, client *kubernetes.Clientset) ([]byte,
 	return storageClassesV1beta(ctx, client)
 }
 
-func storageClassesV1(ctx context.Context, client *kubernetes.Clientset) ([]byte, []string) {
-	storageClasses, err := client.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+func storageClassesV1(ctx context.Context, client kubernetes.Interface) ([]byte, []string) {
+	storageClasses := &storagev1.StorageClassList{}
+	err := k8sutil.ListAll(ctx, storageClasses, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return client.StorageV1().StorageClasses().List(ctx, opts)
+	})
 	if err != nil {
 		return nil, []string{err.Error()}
 	}
@@ -940,8 +1056,11 @@ func storageClassesV1(ctx context.Context, client *kubernetes.Clientset) ([]byte
 	return b, nil
 }
 
-func storageClassesV1beta(ctx context.Context, client *kubernetes.Clientset) ([]byte, []string) {
-	storageClasses, err := client.StorageV1beta1().StorageClasses().List(ctx, metav1.ListOptions{})
+func storageClassesV1beta(ctx context.Context, client kubernetes.Interface) ([]byte, []string) {
+	storageClasses := &storagev1beta1.StorageClassList{}
+	err := k8sutil.ListAll(ctx, storageClasses, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return client.StorageV1beta1().StorageClasses().List(ctx, opts)
+	})
 	if err != nil {
 		return nil, []string{err.Error()}
 	}
@@ -966,25 +1085,28 @@ func storageClassesV1beta(ctx context.Context, client *kubernetes.Clientset) ([]
 	return b, nil
 }
 
-func crds(ctx context.Context, client *kubernetes.Clientset, config *rest.Config) ([]byte, []string) {
-	ok, err := discovery.HasResource(client, "apiextensions.k8s.io/v1", "CustomResourceDefinition")
+func crds(ctx context.Context, client kubernetes.Interface, clients *ClientFactory) ([]byte, []string) {
+	ok, err := discovery.HasResource(client.Discovery(), "apiextensions.k8s.io/v1", "CustomResourceDefinition")
 	if err != nil {
 		return nil, []string{err.Error()}
 	}
 	if ok {
-		return crdsV1(ctx, config)
+		return crdsV1(ctx, clients)
 	}
 
-	return crdsV1beta(ctx, config)
+	return crdsV1beta(ctx, clients)
 }
 
-func crdsV1(ctx context.Context, config *rest.Config) ([]byte, []string) {
-	client, err := apiextensionsv1clientset.NewForConfig(config)
+func crdsV1(ctx context.Context, clients *ClientFactory) ([]byte, []string) {
+	client, err := apiextensionsv1clientset.NewForConfigAndClient(clients.Config(), clients.HTTPClient())
 	if err != nil {
 		return nil, []string{err.Error()}
 	}
 
-	crds, err := client.CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
+	crds := &apiextensionsv1.CustomResourceDefinitionList{}
+	err = k8sutil.ListAll(ctx, crds, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return client.CustomResourceDefinitions().List(ctx, opts)
+	})
 	if err != nil {
 		return nil, []string{err.Error()}
 	}
@@ -1009,13 +1131,16 @@ func crdsV1(ctx context.Context, config *rest.Config) ([]byte, []string) {
 	return b, nil
 }
 
-func crdsV1beta(ctx context.Context, config *rest.Config) ([]byte, []string) {
-	client, err := apiextensionsv1beta1clientset.NewForConfig(config)
+func crdsV1beta(ctx context.Context, clients *ClientFactory) ([]byte, []string) {
+	client, err := apiextensionsv1beta1clientset.NewForConfigAndClient(clients.Config(), clients.HTTPClient())
 	if err != nil {
 		return nil, []string{err.Error()}
 	}
 
-	crds, err := client.CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
+	crds := &apiextensionsv1beta1.CustomResourceDefinitionList{}
+	err = k8sutil.ListAll(ctx, crds, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return client.CustomResourceDefinitions().List(ctx, opts)
+	})
 	if err != nil {
 		return nil, []string{err.Error()}
 	}
@@ -1040,16 +1165,16 @@ func crdsV1beta(ctx context.Context, config *rest.Config) ([]byte, []string) {
 	return b, nil
 }
 
-func crs(ctx context.Context, dyn dynamic.Interface, client *kubernetes.Clientset, config *rest.Config, namespaces []string) (map[string][]byte, map[string]string) {
-	ok, err := discovery.HasResource(client, "apiextensions.k8s.io/v1", "CustomResourceDefinition")
+func crs(ctx context.Context, dyn dynamic.Interface, client kubernetes.Interface, clients *ClientFactory, namespaces []string) (map[string][]byte, map[string]string) {
+	ok, err := discovery.HasResource(client.Discovery(), "apiextensions.k8s.io/v1", "CustomResourceDefinition")
 	if err != nil {
 		return nil, map[string]string{"discover apiextensions.k8s.io/v1": err.Error()}
 	}
 	if ok {
-		return crsV1(ctx, dyn, config, namespaces)
+		return crsV1(ctx, dyn, clients, namespaces)
 	}
 
-	return crsV1beta(ctx, dyn, config, namespaces)
+	return crsV1beta(ctx, dyn, clients, namespaces)
 }
 
 // Selects the newest version by kube-aware priority.
@@ -1064,17 +1189,20 @@ func selectCRDVersionByPriority(versions []string) string {
 	return versions[len(versions)-1]
 }
 
-func crsV1(ctx context.Context, client dynamic.Interface, config *rest.Config, namespaces []string) (map[string][]byte, map[string]string) {
+func crsV1(ctx context.Context, client dynamic.Interface, clients *ClientFactory, namespaces []string) (map[string][]byte, map[string]string) {
 	customResources := make(map[string][]byte)
 	errorList := make(map[string]string)
 
-	crdClient, err := apiextensionsv1clientset.NewForConfig(config)
+	crdClient, err := apiextensionsv1clientset.NewForConfigAndClient(clients.Config(), clients.HTTPClient())
 	if err != nil {
 		errorList["crdClient"] = err.Error()
 		return customResources, errorList
 	}
 
-	crds, err := crdClient.CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
+	crds := &apiextensionsv1.CustomResourceDefinitionList{}
+	err = k8sutil.ListAll(ctx, crds, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return crdClient.CustomResourceDefinitions().List(ctx, opts)
+	})
 	if err != nil {
 		errorList["crdList"] = err.Error()
 		return customResources, errorList
@@ -1110,7 +1238,10 @@ func crsV1(ctx context.Context, client dynamic.Interface, config *rest.Config, n
 		isNamespacedResource := crd.Spec.Scope == apiextensionsv1.NamespaceScoped
 
 		// Fetch all resources of given type
-		customResourceList, err := client.Resource(gvr).List(ctx, metav1.ListOptions{})
+		customResourceList := &unstructured.UnstructuredList{}
+		err := k8sutil.ListAll(ctx, customResourceList, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.Resource(gvr).List(ctx, opts)
+		})
 		if err != nil {
 			errorList[crd.Name] = err.Error()
 			continue
@@ -1173,17 +1304,20 @@ func crsV1(ctx context.Context, client dynamic.Interface, config *rest.Config, n
 	return customResources, errorList
 }
 
-func crsV1beta(ctx context.Context, client dynamic.Interface, config *rest.Config, namespaces []string) (map[string][]byte, map[string]string) {
+func crsV1beta(ctx context.Context, client dynamic.Interface, clients *ClientFactory, namespaces []string) (map[string][]byte, map[string]string) {
 	customResources := make(map[string][]byte)
 	errorList := make(map[string]string)
 
-	crdClient, err := apiextensionsv1beta1clientset.NewForConfig(config)
+	crdClient, err := apiextensionsv1beta1clientset.NewForConfigAndClient(clients.Config(), clients.HTTPClient())
 	if err != nil {
 		errorList["crdClient"] = err.Error()
 		return customResources, errorList
 	}
 
-	crds, err := crdClient.CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
+	crds := &apiextensionsv1beta1.CustomResourceDefinitionList{}
+	err = k8sutil.ListAll(ctx, crds, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return crdClient.CustomResourceDefinitions().List(ctx, opts)
+	})
 	if err != nil {
 		errorList["crdList"] = err.Error()
 		return customResources, errorList
@@ -1221,7 +1355,10 @@ func crsV1beta(ctx context.Context, client dynamic.Interface, config *rest.Confi
 		isNamespacedResource := crd.Spec.Scope == apiextensionsv1beta1.NamespaceScoped
 
 		// Fetch all resources of given type
-		customResourceList, err := client.Resource(gvr).List(ctx, metav1.ListOptions{})
+		customResourceList := &unstructured.UnstructuredList{}
+		err := k8sutil.ListAll(ctx, customResourceList, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.Resource(gvr).List(ctx, opts)
+		})
 		if err != nil {
 			errorList[crd.Name] = err.Error()
 			continue
@@ -1284,7 +1421,7 @@ func crsV1beta(ctx context.Context, client dynamic.Interface, config *rest.Confi
 	return customResources, errorList
 }
 
-func imagePullSecrets(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
+func imagePullSecrets(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
 	imagePullSecrets := make(map[string][]byte)
 	errors := make(map[string]string)
 
@@ -1297,7 +1434,10 @@ func imagePullSecrets(ctx context.Context, client *kubernetes.Clientset, namespa
 	}
 
 	for _, namespace := range namespaces {
-		secrets, err := client.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{})
+		secrets := &corev1.SecretList{}
+		err := k8sutil.ListAll(ctx, secrets, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.CoreV1().Secrets(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errors[namespace] = err.Error()
 			continue
@@ -1336,12 +1476,15 @@ func imagePullSecrets(ctx context.Context, client *kubernetes.Clientset, namespa
 	return imagePullSecrets, errors
 }
 
-func limitRanges(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
+func limitRanges(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
 	limitRangesByNamespace := make(map[string][]byte)
 	errorsByNamespace := make(map[string]string)
 
 	for _, namespace := range namespaces {
-		limitRanges, err := client.CoreV1().LimitRanges(namespace).List(ctx, metav1.ListOptions{})
+		limitRanges := &corev1.LimitRangeList{}
+		err := k8sutil.ListAll(ctx, limitRanges, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.CoreV1().LimitRanges(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errorsByNamespace[namespace] = err.Error()
 			continue
@@ -1371,8 +1514,11 @@ func limitRanges(ctx context.Context, client *kubernetes.Clientset, namespaces [
 	return limitRangesByNamespace, errorsByNamespace
 }
 
-func nodes(ctx context.Context, client *kubernetes.Clientset) ([]byte, []string) {
-	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+func nodes(ctx context.Context, client kubernetes.Interface) ([]byte, []string) {
+	nodes := &corev1.NodeList{}
+	err := k8sutil.ListAll(ctx, nodes, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return client.CoreV1().Nodes().List(ctx, opts)
+	})
 	if err != nil {
 		return nil, []string{err.Error()}
 	}
@@ -1398,7 +1544,7 @@ func nodes(ctx context.Context, client *kubernetes.Clientset) ([]byte, []string)
 }
 
 // get the list of API resources, similar to 'kubectl api-resources'
-func apiResources(ctx context.Context, client *kubernetes.Clientset) ([]byte, []byte, []string) {
+func apiResources(ctx context.Context, client kubernetes.Interface) ([]byte, []byte, []string) {
 	var errorArray []string
 	groups, resources, err := client.Discovery().ServerGroupsAndResources()
 	if err != nil {
@@ -1418,7 +1564,7 @@ func apiResources(ctx context.Context, client *kubernetes.Clientset) ([]byte, []
 	return groupBytes, resourcesBytes, errorArray
 }
 
-func getSelfSubjectRulesReviews(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string]*authorizationv1.SubjectRulesReviewStatus, map[string]string) {
+func getSelfSubjectRulesReviews(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string]*authorizationv1.SubjectRulesReviewStatus, map[string]string) {
 	// https://github.com/kubernetes/kubernetes/blob/master/pkg/kubectl/cmd/auth/cani.go
 
 	statusByNamespace := make(map[string]*authorizationv1.SubjectRulesReviewStatus)
@@ -1461,16 +1607,20 @@ func authCanI(accessStatuses map[string]*authorizationv1.SubjectRulesReviewStatu
 	return authListByNamespace
 }
 
-func events(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
+func events(ctx context.Context, client kubernetes.Interface, namespaces []string, sinceTime *time.Time) (map[string][]byte, map[string]string) {
 	eventsByNamespace := make(map[string][]byte)
 	errorsByNamespace := make(map[string]string)
 
 	for _, namespace := range namespaces {
-		events, err := client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+		events := &corev1.EventList{}
+		err := k8sutil.ListAll(ctx, events, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.CoreV1().Events(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errorsByNamespace[namespace] = err.Error()
 			continue
 		}
+		events.Items = eventsSince(events.Items, sinceTime)
 
 		gvk, err := apiutil.GVKForObject(events, scheme.Scheme)
 		if err == nil {
@@ -1496,6 +1646,23 @@ func events(ctx context.Context, client *kubernetes.Clientset, namespaces []stri
 	return eventsByNamespace, errorsByNamespace
 }
 
+// eventsSince returns the events that last happened at or after sinceTime. Events without a time are
+// kept, as it can't be told when they happened.
+func eventsSince(events []corev1.Event, sinceTime *time.Time) []corev1.Event {
+	if sinceTime == nil {
+		return events
+	}
+
+	filtered := []corev1.Event{}
+	for _, event := range events {
+		t := eventTime(event)
+		if t.IsZero() || !t.Before(*sinceTime) {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
+
 func canCollectNamespaceResources(status *authorizationv1.SubjectRulesReviewStatus) bool {
 	// This is all very approximate
 
@@ -1554,8 +1721,11 @@ func convertToPolicyRule(status *authorizationv1.SubjectRulesReviewStatus) []rba
 	return ret
 }
 
-func pvs(ctx context.Context, client *kubernetes.Clientset) ([]byte, []string) {
-	pv, err := client.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+func pvs(ctx context.Context, client kubernetes.Interface) ([]byte, []string) {
+	pv := &corev1.PersistentVolumeList{}
+	err := k8sutil.ListAll(ctx, pv, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return client.CoreV1().PersistentVolumes().List(ctx, opts)
+	})
 	if err != nil {
 		return nil, []string{err.Error()}
 	}
@@ -1579,12 +1749,15 @@ func pvs(ctx context.Context, client *kubernetes.Clientset) ([]byte, []string) {
 	return b, nil
 }
 
-func pvcs(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
+func pvcs(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
 	pvcsByNamespace := make(map[string][]byte)
 	errorsByNamespace := make(map[string]string)
 
 	for _, namespace := range namespaces {
-		pvcs, err := client.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+		pvcs := &corev1.PersistentVolumeClaimList{}
+		err := k8sutil.ListAll(ctx, pvcs, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.CoreV1().PersistentVolumeClaims(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errorsByNamespace[namespace] = err.Error()
 			continue
@@ -1614,12 +1787,15 @@ func pvcs(ctx context.Context, client *kubernetes.Clientset, namespaces []string
 	return pvcsByNamespace, errorsByNamespace
 }
 
-func roles(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
+func roles(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
 	rolesByNamespace := make(map[string][]byte)
 	errorsByNamespace := make(map[string]string)
 
 	for _, namespace := range namespaces {
-		roles, err := client.RbacV1().Roles(namespace).List(ctx, metav1.ListOptions{})
+		roles := &rbacv1.RoleList{}
+		err := k8sutil.ListAll(ctx, roles, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.RbacV1().Roles(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errorsByNamespace[namespace] = err.Error()
 			continue
@@ -1649,12 +1825,15 @@ func roles(ctx context.Context, client *kubernetes.Clientset, namespaces []strin
 	return rolesByNamespace, errorsByNamespace
 }
 
-func roleBindings(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string][]byte, map[string]string) {
+func roleBindings(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string][]byte, map[string]string) {
 	roleBindingsByNamespace := make(map[string][]byte)
 	errorsByNamespace := make(map[string]string)
 
 	for _, namespace := range namespaces {
-		roleBindings, err := client.RbacV1().RoleBindings(namespace).List(ctx, metav1.ListOptions{})
+		roleBindings := &rbacv1.RoleBindingList{}
+		err := k8sutil.ListAll(ctx, roleBindings, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.RbacV1().RoleBindings(namespace).List(ctx, opts)
+		})
 		if err != nil {
 			errorsByNamespace[namespace] = err.Error()
 			continue
@@ -1684,8 +1863,11 @@ func roleBindings(ctx context.Context, client *kubernetes.Clientset, namespaces
 	return roleBindingsByNamespace, errorsByNamespace
 }
 
-func clusterRoles(ctx context.Context, client *kubernetes.Clientset) ([]byte, []string) {
-	clusterRoles, err := client.RbacV1().ClusterRoles().List(ctx, metav1.ListOptions{})
+func clusterRoles(ctx context.Context, client kubernetes.Interface) ([]byte, []string) {
+	clusterRoles := &rbacv1.ClusterRoleList{}
+	err := k8sutil.ListAll(ctx, clusterRoles, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return client.RbacV1().ClusterRoles().List(ctx, opts)
+	})
 	if err != nil {
 		return nil, []string{err.Error()}
 	}
@@ -1709,8 +1891,11 @@ func clusterRoles(ctx context.Context, client *kubernetes.Clientset) ([]byte, []
 	return b, nil
 }
 
-func clusterRoleBindings(ctx context.Context, client *kubernetes.Clientset) ([]byte, []string) {
-	clusterRoleBindings, err := client.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+func clusterRoleBindings(ctx context.Context, client kubernetes.Interface) ([]byte, []string) {
+	clusterRoleBindings := &rbacv1.ClusterRoleBindingList{}
+	err := k8sutil.ListAll(ctx, clusterRoleBindings, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return client.RbacV1().ClusterRoleBindings().List(ctx, opts)
+	})
 	if err != nil {
 		return nil, []string{err.Error()}
 	}
@@ -2,8 +2,11 @@ package collect
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func Test_SelectCRDVersionByPriority(t *testing.T) {
@@ -12,3 +15,21 @@ func Test_SelectCRDVersionByPriority(t *testing.T) {
 	assert.Equal(t, "v1", selectCRDVersionByPriority([]string{"v1alpha2", "v1alpha3", "v1"}))
 	assert.Equal(t, "v1", selectCRDVersionByPriority([]string{"v1", "v1alpha2", "v1alpha3"}))
 }
+
+func Test_eventsSince(t *testing.T) {
+	sinceTime := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	events := []corev1.Event{
+		{ObjectMeta: metav1.ObjectMeta{Name: "old"}, LastTimestamp: metav1.NewTime(sinceTime.Add(-time.Minute))},
+		{ObjectMeta: metav1.ObjectMeta{Name: "recent"}, LastTimestamp: metav1.NewTime(sinceTime.Add(time.Minute))},
+		{ObjectMeta: metav1.ObjectMeta{Name: "recent-event-time"}, EventTime: metav1.NewMicroTime(sinceTime)},
+		{ObjectMeta: metav1.ObjectMeta{Name: "no-time"}},
+	}
+
+	assert.Equal(t, events, eventsSince(events, nil))
+
+	names := []string{}
+	for _, event := range eventsSince(events, &sinceTime) {
+		names = append(names, event.Name)
+	}
+	assert.Equal(t, []string{"recent", "recent-event-time", "no-time"}, names)
+}
@@ -0,0 +1,401 @@
+package collect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/k8sutil"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// ClusterSummaryFilename is at the root of the bundle, as it is the first thing to read
+const ClusterSummaryFilename = "summary.json"
+
+// ClusterSummary is what the cluster is, in one place: its version and distribution, its nodes, and the
+// networking, storage and ingress that are installed in it
+type ClusterSummary struct {
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	Platform          string `json:"platform,omitempty"`
+	// Distribution is named the same as in the distribution analyzer, such as eks or openShift
+	Distribution string              `json:"distribution,omitempty"`
+	Nodes        ClusterSummaryNodes `json:"nodes"`
+	// CNI are the network plugins that have a daemonset in the cluster
+	CNI                 []string `json:"cni"`
+	CSIDrivers          []string `json:"csiDrivers"`
+	DefaultStorageClass string   `json:"defaultStorageClass"`
+	// IngressControllers are the controllers of the ingress classes
+	IngressControllers []string `json:"ingressControllers"`
+	// Operators are the API groups that are not built into Kubernetes, which are added by operators with
+	// custom resource definitions or by aggregated API servers
+	Operators []ClusterSummaryOperator `json:"operators"`
+	Errors    map[string]string        `json:"errors,omitempty"`
+}
+
+// ClusterSummaryNodes has the number of nodes of each instance type, architecture, OS image, kubelet
+// version and container runtime
+type ClusterSummaryNodes struct {
+	Count             int            `json:"count"`
+	Ready             int            `json:"ready"`
+	ControlPlane      int            `json:"controlPlane"`
+	InstanceTypes     map[string]int `json:"instanceTypes,omitempty"`
+	Architectures     map[string]int `json:"architectures,omitempty"`
+	OSImages          map[string]int `json:"osImages,omitempty"`
+	KubeletVersions   map[string]int `json:"kubeletVersions,omitempty"`
+	ContainerRuntimes map[string]int `json:"containerRuntimes,omitempty"`
+}
+
+type ClusterSummaryOperator struct {
+	Group string   `json:"group"`
+	Kinds []string `json:"kinds"`
+}
+
+// clusterSummaryCNI is a network plugin, which is found by the names of its daemonsets or by a part of the
+// images of their containers
+type clusterSummaryCNI struct {
+	name       string
+	daemonSets []string
+	images     []string
+}
+
+var clusterSummaryCNIs = []clusterSummaryCNI{
+	{name: "calico", daemonSets: []string{"calico-node"}, images: []string{"calico/node"}},
+	{name: "cilium", daemonSets: []string{"cilium"}, images: []string{"cilium/cilium"}},
+	{name: "flannel", daemonSets: []string{"kube-flannel-ds"}, images: []string{"flannel"}},
+	{name: "weave", daemonSets: []string{"weave-net"}, images: []string{"weaveworks/weave-kube"}},
+	{name: "antrea", daemonSets: []string{"antrea-agent"}, images: []string{"antrea/antrea"}},
+	{name: "kube-router", daemonSets: []string{"kube-router"}, images: []string{"kube-router"}},
+	{name: "kube-ovn", daemonSets: []string{"kube-ovn-cni"}, images: []string{"kubeovn/kube-ovn"}},
+	{name: "ovn-kubernetes", daemonSets: []string{"ovnkube-node"}},
+	{name: "openshift-sdn", daemonSets: []string{"sdn"}},
+	{name: "aws-vpc-cni", daemonSets: []string{"aws-node"}, images: []string{"amazon-k8s-cni"}},
+	{name: "azure-cni", daemonSets: []string{"azure-cns"}, images: []string{"azure-cns"}},
+	{name: "kindnet", daemonSets: []string{"kindnet"}, images: []string{"kindnetd"}},
+}
+
+type CollectClusterSummary struct {
+	Collector    *troubleshootv1beta2.ClusterSummary
+	BundlePath   string
+	Namespace    string
+	ClientConfig *rest.Config
+	Client       kubernetes.Interface
+	Context      context.Context
+	RBACErrors
+}
+
+func (c *CollectClusterSummary) Title() string {
+	return getCollectorName(c)
+}
+
+func (c *CollectClusterSummary) IsExcluded() (bool, error) {
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
+}
+
+// Collect doesn't fail when a part of the summary can't be read, the error is in the summary instead
+func (c *CollectClusterSummary) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
+	ctx := contextOrBackground(c.Context)
+	output := NewResult()
+
+	summary := ClusterSummary{
+		CNI:                []string{},
+		CSIDrivers:         []string{},
+		IngressControllers: []string{},
+		Operators:          []ClusterSummaryOperator{},
+		Errors:             map[string]string{},
+	}
+
+	serverVersion, err := c.Client.Discovery().ServerVersion()
+	if err != nil {
+		summary.Errors["version"] = err.Error()
+	} else {
+		summary.KubernetesVersion = serverVersion.GitVersion
+		summary.Platform = serverVersion.Platform
+	}
+
+	// the groups that were discovered are used even if others failed, such as those of an aggregated API
+	// server that is down
+	groupVersions := []string{}
+	_, resourceLists, err := c.Client.Discovery().ServerGroupsAndResources()
+	if err != nil {
+		summary.Errors["discovery"] = err.Error()
+	}
+	for _, resourceList := range resourceLists {
+		groupVersions = append(groupVersions, resourceList.GroupVersion)
+	}
+	summary.Operators = clusterSummaryOperators(resourceLists)
+
+	nodes := &corev1.NodeList{}
+	err = k8sutil.ListAll(ctx, nodes, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return c.Client.CoreV1().Nodes().List(ctx, opts)
+	})
+	if err != nil {
+		summary.Errors["nodes"] = err.Error()
+	} else {
+		summary.Nodes = clusterSummaryNodes(nodes.Items)
+	}
+	summary.Distribution = clusterDistribution(nodes.Items, groupVersions)
+
+	daemonSets := &appsv1.DaemonSetList{}
+	err = k8sutil.ListAll(ctx, daemonSets, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return c.Client.AppsV1().DaemonSets(metav1.NamespaceAll).List(ctx, opts)
+	})
+	if err != nil {
+		summary.Errors["daemonsets"] = err.Error()
+	} else {
+		summary.CNI = append(summary.CNI, clusterSummaryCNIsOf(daemonSets.Items)...)
+	}
+
+	csiDrivers := &storagev1.CSIDriverList{}
+	err = k8sutil.ListAll(ctx, csiDrivers, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return c.Client.StorageV1().CSIDrivers().List(ctx, opts)
+	})
+	if err != nil {
+		summary.Errors["csidrivers"] = err.Error()
+	} else {
+		for _, csiDriver := range csiDrivers.Items {
+			summary.CSIDrivers = append(summary.CSIDrivers, csiDriver.Name)
+		}
+		sort.Strings(summary.CSIDrivers)
+	}
+
+	storageClasses := &storagev1.StorageClassList{}
+	err = k8sutil.ListAll(ctx, storageClasses, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return c.Client.StorageV1().StorageClasses().List(ctx, opts)
+	})
+	if err != nil {
+		summary.Errors["storageclasses"] = err.Error()
+	} else {
+		for _, storageClass := range storageClasses.Items {
+			if storageClass.Annotations["storageclass.kubernetes.io/is-default-class"] == "true" {
+				summary.DefaultStorageClass = storageClass.Name
+			}
+		}
+	}
+
+	ingressClasses := &networkingv1.IngressClassList{}
+	err = k8sutil.ListAll(ctx, ingressClasses, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return c.Client.NetworkingV1().IngressClasses().List(ctx, opts)
+	})
+	if err != nil {
+		summary.Errors["ingressclasses"] = err.Error()
+	} else {
+		controllers := map[string]bool{}
+		for _, ingressClass := range ingressClasses.Items {
+			controllers[ingressClass.Spec.Controller] = true
+		}
+		summary.IngressControllers = append(summary.IngressControllers, sortedKeys(controllers)...)
+	}
+
+	if len(summary.Errors) == 0 {
+		summary.Errors = nil
+	}
+
+	b, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	output.SaveResult(c.BundlePath, ClusterSummaryFilename, bytes.NewBuffer(b))
+
+	return output, nil
+}
+
+func clusterSummaryNodes(nodes []corev1.Node) ClusterSummaryNodes {
+	summary := ClusterSummaryNodes{
+		Count:             len(nodes),
+		InstanceTypes:     map[string]int{},
+		Architectures:     map[string]int{},
+		OSImages:          map[string]int{},
+		KubeletVersions:   map[string]int{},
+		ContainerRuntimes: map[string]int{},
+	}
+
+	for _, node := range nodes {
+		if isNodeReady(node) {
+			summary.Ready++
+		}
+		if isControlPlaneNode(node) {
+			summary.ControlPlane++
+		}
+
+		instanceType := node.Labels[corev1.LabelInstanceTypeStable]
+		if instanceType == "" {
+			instanceType = node.Labels[corev1.LabelInstanceType]
+		}
+		if instanceType != "" {
+			summary.InstanceTypes[instanceType]++
+		}
+
+		nodeInfo := node.Status.NodeInfo
+		if nodeInfo.Architecture != "" {
+			summary.Architectures[nodeInfo.Architecture]++
+		}
+		if nodeInfo.OSImage != "" {
+			summary.OSImages[nodeInfo.OSImage]++
+		}
+		if nodeInfo.KubeletVersion != "" {
+			summary.KubeletVersions[nodeInfo.KubeletVersion]++
+		}
+		if nodeInfo.ContainerRuntimeVersion != "" {
+			summary.ContainerRuntimes[nodeInfo.ContainerRuntimeVersion]++
+		}
+	}
+
+	return summary
+}
+
+func isControlPlaneNode(node corev1.Node) bool {
+	_, master := node.Labels["node-role.kubernetes.io/master"]
+	_, controlPlane := node.Labels["node-role.kubernetes.io/control-plane"]
+	return master || controlPlane
+}
+
+// clusterDistribution finds the distribution the same way as the distribution analyzer, from the API
+// groups that only some distributions serve and from the labels, annotations and provider IDs of nodes
+func clusterDistribution(nodes []corev1.Node, groupVersions []string) string {
+	for _, groupVersion := range groupVersions {
+		if strings.HasPrefix(groupVersion, "apps.openshift.io/") {
+			return "openShift"
+		}
+		if strings.HasPrefix(groupVersion, "run.tanzu.vmware.com/") {
+			return "tanzu"
+		}
+	}
+
+	distribution := ""
+	hasControlPlane := false
+	for _, node := range nodes {
+		if isControlPlaneNode(node) {
+			hasControlPlane = true
+		}
+
+		switch {
+		case node.Labels["kurl.sh/cluster"] == "true":
+			distribution = "kurl"
+		case node.Labels["microk8s.io/cluster"] == "true":
+			distribution = "microk8s"
+		}
+		if _, ok := node.Labels["kubernetes.azure.com/role"]; ok {
+			distribution = "aks"
+		}
+		if _, ok := node.Labels["minikube.k8s.io/version"]; ok {
+			distribution = "minikube"
+		}
+		if node.Labels[corev1.LabelInstanceTypeStable] == "k3s" || node.Labels[corev1.LabelInstanceType] == "k3s" {
+			distribution = "k3s"
+		}
+		if _, ok := node.Annotations["rke2.io/node-args"]; ok {
+			distribution = "rke2"
+		}
+		if strings.Contains(node.Status.NodeInfo.KubeletVersion, "+k3s") {
+			distribution = "k3s"
+		}
+		if strings.Contains(node.Status.NodeInfo.KubeletVersion, "+rke2") {
+			distribution = "rke2"
+		}
+		if node.Status.NodeInfo.OSImage == "Docker Desktop" {
+			distribution = "dockerDesktop"
+		}
+
+		switch {
+		case strings.HasPrefix(node.Spec.ProviderID, "digitalocean:"):
+			distribution = "digitalOcean"
+		case strings.HasPrefix(node.Spec.ProviderID, "aws:"):
+			distribution = "eks"
+		case strings.HasPrefix(node.Spec.ProviderID, "gce:"):
+			distribution = "gke"
+		case strings.HasPrefix(node.Spec.ProviderID, "ibm:"):
+			distribution = "ibm"
+		}
+	}
+
+	// eks does not have control plane nodes in the node list, so a cluster on aws that does isn't eks
+	if hasControlPlane && distribution == "eks" {
+		distribution = ""
+	}
+
+	return distribution
+}
+
+func clusterSummaryCNIsOf(daemonSets []appsv1.DaemonSet) []string {
+	found := map[string]bool{}
+	for _, daemonSet := range daemonSets {
+		for _, cni := range clusterSummaryCNIs {
+			if daemonSetIsCNI(daemonSet, cni) {
+				found[cni.name] = true
+			}
+		}
+	}
+	return sortedKeys(found)
+}
+
+func daemonSetIsCNI(daemonSet appsv1.DaemonSet, cni clusterSummaryCNI) bool {
+	for _, name := range cni.daemonSets {
+		if daemonSet.Name == name {
+			return true
+		}
+	}
+
+	containers := append(daemonSet.Spec.Template.Spec.InitContainers, daemonSet.Spec.Template.Spec.Containers...)
+	for _, container := range containers {
+		for _, image := range cni.images {
+			if strings.Contains(container.Image, image) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// clusterSummaryOperators are the groups of the resource lists that are not built into Kubernetes, with
+// the kinds of their resources. The resources of a group are listed once for each of its versions.
+func clusterSummaryOperators(resourceLists []*metav1.APIResourceList) []ClusterSummaryOperator {
+	kindsByGroup := map[string]map[string]bool{}
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil || isBuiltInGroup(gv.Group) {
+			continue
+		}
+		if kindsByGroup[gv.Group] == nil {
+			kindsByGroup[gv.Group] = map[string]bool{}
+		}
+		for _, resource := range resourceList.APIResources {
+			// subresources, such as status, have the kind of their resource
+			if !strings.Contains(resource.Name, "/") {
+				kindsByGroup[gv.Group][resource.Kind] = true
+			}
+		}
+	}
+
+	groups := make([]string, 0, len(kindsByGroup))
+	for group := range kindsByGroup {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	operators := []ClusterSummaryOperator{}
+	for _, group := range groups {
+		operators = append(operators, ClusterSummaryOperator{
+			Group: group,
+			Kinds: sortedKeys(kindsByGroup[group]),
+		})
+	}
+	return operators
+}
+
+// isBuiltInGroup is true for the core group, the groups without a domain, such as apps and batch, and the
+// groups of the k8s.io domain
+func isBuiltInGroup(group string) bool {
+	return !strings.Contains(group, ".") || group == "k8s.io" || strings.HasSuffix(group, ".k8s.io")
+}
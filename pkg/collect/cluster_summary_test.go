@@ -0,0 +1,181 @@
+package collect
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCollectClusterSummary(t *testing.T) {
+	client := testclient.NewSimpleClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{corev1.LabelInstanceTypeStable: "m5.xlarge"}},
+			Spec:       corev1.NodeSpec{ProviderID: "aws:///us-east-1a/i-1"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+				NodeInfo: corev1.NodeSystemInfo{
+					Architecture:            "amd64",
+					OSImage:                 "Amazon Linux 2",
+					KubeletVersion:          "v1.27.4-eks-8ccc7ba",
+					ContainerRuntimeVersion: "containerd://1.6.19",
+				},
+			},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-2", Labels: map[string]string{corev1.LabelInstanceTypeStable: "m5.xlarge"}},
+			Spec:       corev1.NodeSpec{ProviderID: "aws:///us-east-1b/i-2"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+				NodeInfo: corev1.NodeSystemInfo{
+					Architecture:            "amd64",
+					OSImage:                 "Amazon Linux 2",
+					KubeletVersion:          "v1.27.4-eks-8ccc7ba",
+					ContainerRuntimeVersion: "containerd://1.6.19",
+				},
+			},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-3", Labels: map[string]string{corev1.LabelInstanceTypeStable: "r5.2xlarge"}},
+			Spec:       corev1.NodeSpec{ProviderID: "aws:///us-east-1c/i-3"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionFalse}},
+				NodeInfo: corev1.NodeSystemInfo{
+					Architecture:            "amd64",
+					OSImage:                 "Amazon Linux 2",
+					KubeletVersion:          "v1.27.4-eks-8ccc7ba",
+					ContainerRuntimeVersion: "containerd://1.6.19",
+				},
+			},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "aws-node", Namespace: "kube-system"},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-agent", Namespace: "monitoring"},
+			Spec: appsv1.DaemonSetSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Image: "quay.io/cilium/cilium:v1.14.1"}}},
+				},
+			},
+		},
+		&storagev1.CSIDriver{ObjectMeta: metav1.ObjectMeta{Name: "ebs.csi.aws.com"}},
+		&storagev1.StorageClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "gp3",
+				Annotations: map[string]string{"storageclass.kubernetes.io/is-default-class": "true"},
+			},
+			Provisioner: "ebs.csi.aws.com",
+		},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "gp2"}, Provisioner: "kubernetes.io/aws-ebs"},
+		&networkingv1.IngressClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+			Spec:       networkingv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx"},
+		},
+	)
+
+	discovery := client.Discovery().(*fakediscovery.FakeDiscovery)
+	discovery.FakedServerVersion = &version.Info{GitVersion: "v1.27.4-eks-2d98532", Platform: "linux/amd64"}
+	discovery.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod"}}},
+		{GroupVersion: "storage.k8s.io/v1", APIResources: []metav1.APIResource{{Name: "csidrivers", Kind: "CSIDriver"}}},
+		{GroupVersion: "cert-manager.io/v1", APIResources: []metav1.APIResource{
+			{Name: "certificates", Kind: "Certificate"},
+			{Name: "certificates/status", Kind: "Certificate"},
+			{Name: "issuers", Kind: "Issuer"},
+		}},
+		{GroupVersion: "monitoring.coreos.com/v1", APIResources: []metav1.APIResource{{Name: "prometheuses", Kind: "Prometheus"}}},
+		{GroupVersion: "monitoring.coreos.com/v1alpha1", APIResources: []metav1.APIResource{{Name: "alertmanagerconfigs", Kind: "AlertmanagerConfig"}}},
+	}
+
+	c := &CollectClusterSummary{
+		Collector: &troubleshootv1beta2.ClusterSummary{},
+		Client:    client,
+		Context:   context.Background(),
+	}
+	result, err := c.Collect(nil)
+	require.NoError(t, err)
+
+	var summary ClusterSummary
+	require.NoError(t, json.Unmarshal(result[ClusterSummaryFilename], &summary))
+
+	assert.Equal(t, ClusterSummary{
+		KubernetesVersion: "v1.27.4-eks-2d98532",
+		Platform:          "linux/amd64",
+		Distribution:      "eks",
+		Nodes: ClusterSummaryNodes{
+			Count:             3,
+			Ready:             2,
+			InstanceTypes:     map[string]int{"m5.xlarge": 2, "r5.2xlarge": 1},
+			Architectures:     map[string]int{"amd64": 3},
+			OSImages:          map[string]int{"Amazon Linux 2": 3},
+			KubeletVersions:   map[string]int{"v1.27.4-eks-8ccc7ba": 3},
+			ContainerRuntimes: map[string]int{"containerd://1.6.19": 3},
+		},
+		CNI:                 []string{"aws-vpc-cni", "cilium"},
+		CSIDrivers:          []string{"ebs.csi.aws.com"},
+		DefaultStorageClass: "gp3",
+		IngressControllers:  []string{"k8s.io/ingress-nginx"},
+		Operators: []ClusterSummaryOperator{
+			{Group: "cert-manager.io", Kinds: []string{"Certificate", "Issuer"}},
+			{Group: "monitoring.coreos.com", Kinds: []string{"AlertmanagerConfig", "Prometheus"}},
+		},
+	}, summary)
+}
+
+func Test_clusterDistribution(t *testing.T) {
+	tests := []struct {
+		name          string
+		nodes         []corev1.Node
+		groupVersions []string
+		want          string
+	}{
+		{
+			name:          "openshift by api group",
+			nodes:         []corev1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: corev1.NodeSpec{ProviderID: "aws:///us-east-1a/i-1"}}},
+			groupVersions: []string{"v1", "apps.openshift.io/v1"},
+			want:          "openShift",
+		},
+		{
+			name: "k3s by instance type",
+			nodes: []corev1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{corev1.LabelInstanceTypeStable: "k3s"}},
+					Spec:       corev1.NodeSpec{ProviderID: "k3s://node-1"},
+				},
+			},
+			want: "k3s",
+		},
+		{
+			name: "aws with a control plane is not eks",
+			nodes: []corev1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"node-role.kubernetes.io/control-plane": ""}},
+					Spec:       corev1.NodeSpec{ProviderID: "aws:///us-east-1a/i-1"},
+				},
+			},
+			want: "",
+		},
+		{
+			name:  "gke",
+			nodes: []corev1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: corev1.NodeSpec{ProviderID: "gce://project/us-central1-a/node-1"}}},
+			want:  "gke",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, clusterDistribution(tt.nodes, tt.groupVersions))
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/pkg/errors"
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/rest"
 )
 
@@ -30,11 +31,18 @@ type CollectorRunOpts struct {
 	ProgressChan              chan interface{}
 }
 
+// CollectProgress is sent on the progress channel when a collector starts running and when it has
+// completed or failed, so that callers can show the progress of each collector
 type CollectProgress struct {
 	CurrentName    string
 	CurrentStatus  string
 	CompletedCount int
 	TotalCount     int
+	// BytesCollected is the size of the collector's result, once it has completed. It is only set by
+	// support bundle collection.
+	BytesCollected int64
+	// Err is why the collector failed
+	Err error
 }
 
 type HostCollectResult struct {
@@ -218,3 +226,44 @@ func EnsureClusterResourcesFirst(list []*troubleshootv1beta2.Collect) []*trouble
 	}
 	return append(sliceOfClusterResources, sliceOfOtherCollectors...)
 }
+
+// ScopeCollectorsToNodes returns the collectors with the ones that collect from each node restricted to
+// the nodes with the labels of nodeSelector. Where a collector selects nodes by a label of nodeSelector
+// itself, nodeSelector's value is used. The collectors in list are not changed.
+func ScopeCollectorsToNodes(list []*troubleshootv1beta2.Collect, nodeSelector map[string]string) []*troubleshootv1beta2.Collect {
+	if len(nodeSelector) == 0 {
+		return list
+	}
+
+	scoped := make([]*troubleshootv1beta2.Collect, 0, len(list))
+	for _, collector := range list {
+		if collector == nil {
+			scoped = append(scoped, collector)
+			continue
+		}
+
+		collector = collector.DeepCopy()
+		if collector.Sysctl != nil {
+			collector.Sysctl.NodeSelector = mergeNodeSelector(collector.Sysctl.NodeSelector, nodeSelector)
+		}
+		if collector.CopyFromHost != nil {
+			collector.CopyFromHost.NodeSelector = mergeNodeSelector(collector.CopyFromHost.NodeSelector, nodeSelector)
+		}
+		if collector.NodeStats != nil {
+			collector.NodeStats.Selector = append(collector.NodeStats.Selector, labels.SelectorFromSet(nodeSelector).String())
+		}
+		scoped = append(scoped, collector)
+	}
+	return scoped
+}
+
+func mergeNodeSelector(selector map[string]string, nodeSelector map[string]string) map[string]string {
+	merged := make(map[string]string, len(selector)+len(nodeSelector))
+	for k, v := range selector {
+		merged[k] = v
+	}
+	for k, v := range nodeSelector {
+		merged[k] = v
+	}
+	return merged
+}
@@ -41,3 +41,54 @@ func Test_ensureClusterResourcesFirst(t *testing.T) {
 		})
 	}
 }
+
+func Test_ScopeCollectorsToNodes(t *testing.T) {
+	list := []*troubleshootv1beta2.Collect{
+		{
+			Sysctl: &troubleshootv1beta2.Sysctl{Image: "busybox"},
+		},
+		{
+			CopyFromHost: &troubleshootv1beta2.CopyFromHost{
+				HostPath:     "/var/log",
+				NodeSelector: map[string]string{"pool": "default", "kubernetes.io/os": "linux"},
+			},
+		},
+		{
+			NodeStats: &troubleshootv1beta2.NodeStats{Selector: []string{"kubernetes.io/os=linux"}},
+		},
+		{
+			Data: &troubleshootv1beta2.Data{Name: "data"},
+		},
+	}
+	nodeSelector := map[string]string{"pool": "gpu", "zone": "a"}
+
+	got := ScopeCollectorsToNodes(list, nodeSelector)
+
+	assert.Equal(t, []*troubleshootv1beta2.Collect{
+		{
+			Sysctl: &troubleshootv1beta2.Sysctl{
+				Image:        "busybox",
+				NodeSelector: map[string]string{"pool": "gpu", "zone": "a"},
+			},
+		},
+		{
+			CopyFromHost: &troubleshootv1beta2.CopyFromHost{
+				HostPath:     "/var/log",
+				NodeSelector: map[string]string{"pool": "gpu", "zone": "a", "kubernetes.io/os": "linux"},
+			},
+		},
+		{
+			NodeStats: &troubleshootv1beta2.NodeStats{Selector: []string{"kubernetes.io/os=linux", "pool=gpu,zone=a"}},
+		},
+		{
+			Data: &troubleshootv1beta2.Data{Name: "data"},
+		},
+	}, got)
+
+	// the spec's collectors are not changed
+	assert.Nil(t, list[0].Sysctl.NodeSelector)
+	assert.Equal(t, "default", list[1].CopyFromHost.NodeSelector["pool"])
+	assert.Equal(t, []string{"kubernetes.io/os=linux"}, list[2].NodeStats.Selector)
+
+	assert.Equal(t, list, ScopeCollectorsToNodes(list, nil))
+}
@@ -23,7 +23,7 @@ func (c *CollectCollectd) Title() string {
 }
 
 func (c *CollectCollectd) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 func (c *CollectCollectd) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
@@ -0,0 +1,196 @@
+package collect
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// CollectionErrorsFilename is the error manifest of a support bundle. It has the errors of the collectors
+// that failed or collected only some of their data, so that files that are missing because collection
+// failed can be told apart from files that are missing because there was nothing to collect.
+const CollectionErrorsFilename = "errors.json"
+
+// ErrorCode is the kind of error that a collector had, so that failed collections can be triaged
+// without reading the messages
+type ErrorCode string
+
+const (
+	// ErrorCodePermissionDenied is a request that the API server, or another server, did not allow
+	ErrorCodePermissionDenied ErrorCode = "permission-denied"
+	// ErrorCodeTimeout is a collector or request that did not finish in time
+	ErrorCodeTimeout ErrorCode = "timeout"
+	// ErrorCodeNotFound is a resource, pod or file that does not exist
+	ErrorCodeNotFound ErrorCode = "not-found"
+	// ErrorCodeConnectionRefused is a server that could not be connected to
+	ErrorCodeConnectionRefused ErrorCode = "connection-refused"
+	// ErrorCodeThrottled is a collector that was not run, or a request that was rejected, as the API
+	// server was throttling requests
+	ErrorCodeThrottled ErrorCode = "throttled"
+	// ErrorCodeInsufficientDiskSpace is a collector that was not run, or a file that was not written, as
+	// the disk was full or its free space was below the reserve
+	ErrorCodeInsufficientDiskSpace ErrorCode = "insufficient-disk-space"
+	// ErrorCodeUnknown is any other error
+	ErrorCodeUnknown ErrorCode = "unknown"
+)
+
+// CollectionError is an error of a collector
+type CollectionError struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+// CollectionErrors are the errors of collectors by collector name. Collectors without errors are not
+// included, so a bundle with an empty manifest was collected in full.
+type CollectionErrors map[string][]CollectionError
+
+// Add records the errors of the collector with title. err is the error the collector returned, if it
+// failed, and the errors that it wrote to errors files in result are added after it.
+func (e CollectionErrors) Add(title string, bundlePath string, result CollectorResult, err error) {
+	collectionErrors := []CollectionError{}
+	if err != nil {
+		collectionErrors = append(collectionErrors, CollectionError{Code: ClassifyError(err), Message: err.Error()})
+	}
+	for _, message := range readResultErrors(bundlePath, result) {
+		collectionErrors = append(collectionErrors, CollectionError{Code: classifyErrorMessage(message), Message: message})
+	}
+	if len(collectionErrors) == 0 {
+		return
+	}
+
+	// collectors can have the same title, e.g. two logs collectors without a name
+	e[title] = append(e[title], collectionErrors...)
+}
+
+// Summary returns the names of the collectors that had an error of each code, in order
+func (e CollectionErrors) Summary() map[ErrorCode][]string {
+	summary := map[ErrorCode][]string{}
+	for title, collectionErrors := range e {
+		codes := map[ErrorCode]bool{}
+		for _, collectionError := range collectionErrors {
+			if !codes[collectionError.Code] {
+				codes[collectionError.Code] = true
+				summary[collectionError.Code] = append(summary[collectionError.Code], title)
+			}
+		}
+	}
+	for _, titles := range summary {
+		sort.Strings(titles)
+	}
+	return summary
+}
+
+// Marshal returns the manifest as it is written to a bundle
+func (e CollectionErrors) Marshal() ([]byte, error) {
+	b, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal collection errors")
+	}
+	return b, nil
+}
+
+// ParseCollectionErrors parses the contents of an error manifest. Manifests that have a message for
+// each collector, rather than a list of coded errors, are classified as they are read.
+func ParseCollectionErrors(b []byte) (CollectionErrors, error) {
+	collectionErrors := CollectionErrors{}
+	if err := json.Unmarshal(b, &collectionErrors); err == nil {
+		return collectionErrors, nil
+	}
+
+	messages := map[string]string{}
+	if err := json.Unmarshal(b, &messages); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal collection errors")
+	}
+	for title, message := range messages {
+		collectionErrors[title] = []CollectionError{{Code: classifyErrorMessage(message), Message: message}}
+	}
+	return collectionErrors, nil
+}
+
+// ClassifyError returns the code of an error that a collector returned
+func ClassifyError(err error) ErrorCode {
+	var netErr net.Error
+	switch {
+	case IsRBACError(err), kuberneteserrors.IsForbidden(err), kuberneteserrors.IsUnauthorized(err), errors.Is(err, os.ErrPermission):
+		return ErrorCodePermissionDenied
+	case errors.Is(err, ErrThrottled), kuberneteserrors.IsTooManyRequests(err):
+		return ErrorCodeThrottled
+	case errors.Is(err, ErrInsufficientDiskSpace), errors.Is(err, syscall.ENOSPC):
+		return ErrorCodeInsufficientDiskSpace
+	case errors.Is(err, context.DeadlineExceeded), kuberneteserrors.IsTimeout(err), kuberneteserrors.IsServerTimeout(err):
+		return ErrorCodeTimeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorCodeTimeout
+	case kuberneteserrors.IsNotFound(err), errors.Is(err, os.ErrNotExist):
+		return ErrorCodeNotFound
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorCodeConnectionRefused
+	}
+	return classifyErrorMessage(err.Error())
+}
+
+// errorMessageCodes are the codes of errors from their messages, for errors that were only kept as a
+// message, such as those in errors files, or that were wrapped without their cause
+var errorMessageCodes = []struct {
+	code      ErrorCode
+	fragments []string
+}{
+	{ErrorCodePermissionDenied, []string{"forbidden", "permission denied", "unauthorized", "insufficient rbac permissions", "access denied"}},
+	{ErrorCodeThrottled, []string{"throttling", "too many requests", "rate limit"}},
+	{ErrorCodeInsufficientDiskSpace, []string{"no space left on device", "free disk space is below the reserve"}},
+	{ErrorCodeTimeout, []string{"timed out", "timeout", "deadline exceeded"}},
+	{ErrorCodeConnectionRefused, []string{"connection refused"}},
+	{ErrorCodeNotFound, []string{"not found", "no such file or directory", "does not exist"}},
+}
+
+func classifyErrorMessage(message string) ErrorCode {
+	message = strings.ToLower(message)
+	for _, messageCode := range errorMessageCodes {
+		for _, fragment := range messageCode.fragments {
+			if strings.Contains(message, fragment) {
+				return messageCode.code
+			}
+		}
+	}
+	return ErrorCodeUnknown
+}
+
+// isErrorsFile returns whether a collected file is one that a collector wrote its errors to
+func isErrorsFile(relativePath string) bool {
+	name := path.Base(relativePath)
+	return name == "errors.json" || strings.HasSuffix(name, "-errors.json")
+}
+
+// readResultErrors returns the errors in the errors files of result, prefixed with the file they are in.
+// Errors files are lists of errors, and files that are not are added as they are.
+func readResultErrors(bundlePath string, result CollectorResult) []string {
+	messages := []string{}
+	for _, relativePath := range result.sortedNames() {
+		if relativePath == CollectionErrorsFilename || !isErrorsFile(relativePath) {
+			continue
+		}
+
+		b, err := result.ReadResult(bundlePath, relativePath)
+		if err != nil || len(b) == 0 {
+			continue
+		}
+
+		fileErrors := []string{}
+		if err := json.Unmarshal(b, &fileErrors); err != nil {
+			fileErrors = []string{strings.TrimSpace(string(b))}
+		}
+		for _, fileError := range fileErrors {
+			messages = append(messages, fmt.Sprintf("%s: %s", relativePath, fileError))
+		}
+	}
+	return messages
+}
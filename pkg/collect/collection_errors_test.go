@@ -0,0 +1,108 @@
+package collect
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestCollectionErrors_Add(t *testing.T) {
+	bundlePath := t.TempDir()
+
+	result := NewResult()
+	files := map[string]string{
+		"cluster-resources/pods/default.json":    `{"items": []}`,
+		"cluster-resources/pods-errors.json":     `["pods is forbidden", "namespace app not found"]`,
+		"cluster-resources/events-errors.json":   `{"default": "events is forbidden"}`,
+		"cluster-resources/services-errors.json": ``,
+	}
+	for name, contents := range files {
+		require.NoError(t, result.SaveResult(bundlePath, name, bytes.NewBufferString(contents)))
+	}
+	// held in memory rather than on disk
+	result["node-stats/errors.json"] = []byte(`["node-1: timed out"]`)
+
+	collectionErrors := CollectionErrors{}
+	collectionErrors.Add("cluster-resources", bundlePath, result, nil)
+	collectionErrors.Add("cluster-info", bundlePath, NewResult(), nil)
+	collectionErrors.Add("logs/web", bundlePath, nil, errors.New("failed to list pods"))
+	collectionErrors.Add("logs/web", bundlePath, nil, errors.Wrap(ErrThrottled, "skipped"))
+
+	assert.Equal(t, CollectionErrors{
+		"cluster-resources": {
+			{Code: ErrorCodePermissionDenied, Message: `cluster-resources/events-errors.json: {"default": "events is forbidden"}`},
+			{Code: ErrorCodePermissionDenied, Message: "cluster-resources/pods-errors.json: pods is forbidden"},
+			{Code: ErrorCodeNotFound, Message: "cluster-resources/pods-errors.json: namespace app not found"},
+			{Code: ErrorCodeTimeout, Message: "node-stats/errors.json: node-1: timed out"},
+		},
+		"logs/web": {
+			{Code: ErrorCodeUnknown, Message: "failed to list pods"},
+			{Code: ErrorCodeThrottled, Message: "skipped: deferred while the API server was throttling requests"},
+		},
+	}, collectionErrors)
+
+	assert.Equal(t, map[ErrorCode][]string{
+		ErrorCodePermissionDenied: {"cluster-resources"},
+		ErrorCodeNotFound:         {"cluster-resources"},
+		ErrorCodeTimeout:          {"cluster-resources"},
+		ErrorCodeUnknown:          {"logs/web"},
+		ErrorCodeThrottled:        {"logs/web"},
+	}, collectionErrors.Summary())
+
+	b, err := collectionErrors.Marshal()
+	require.NoError(t, err)
+	parsed, err := ParseCollectionErrors(b)
+	require.NoError(t, err)
+	assert.Equal(t, collectionErrors, parsed)
+}
+
+func TestParseCollectionErrors_Messages(t *testing.T) {
+	// manifests with a message for each collector
+	collectionErrors, err := ParseCollectionErrors([]byte(`{"logs/web": "failed to list pods: connection refused"}`))
+	require.NoError(t, err)
+	assert.Equal(t, CollectionErrors{
+		"logs/web": {{Code: ErrorCodeConnectionRefused, Message: "failed to list pods: connection refused"}},
+	}, collectionErrors)
+
+	_, err = ParseCollectionErrors([]byte(`["failed"]`))
+	assert.Error(t, err)
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ErrorCode
+	}{
+		{err: RBACError{DisplayName: "logs", Verb: "list", Resource: "pods"}, want: ErrorCodePermissionDenied},
+		{err: kuberneteserrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "web", errors.New("no")), want: ErrorCodePermissionDenied},
+		{err: errors.Wrap(kuberneteserrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "web"), "failed to get pod"), want: ErrorCodeNotFound},
+		{err: kuberneteserrors.NewTooManyRequests("slow down", 1), want: ErrorCodeThrottled},
+		{err: errors.Wrap(context.DeadlineExceeded, "collector did not finish"), want: ErrorCodeTimeout},
+		{err: errors.Errorf("timed out after %s", "30s"), want: ErrorCodeTimeout},
+		{err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, want: ErrorCodeConnectionRefused},
+		{err: errors.Wrap(os.ErrNotExist, "failed to open file"), want: ErrorCodeNotFound},
+		{err: errors.New("failed to parse output"), want: ErrorCodeUnknown},
+	}
+	for _, test := range tests {
+		t.Run(test.err.Error(), func(t *testing.T) {
+			assert.Equal(t, test.want, ClassifyError(test.err))
+		})
+	}
+}
+
+func Test_isErrorsFile(t *testing.T) {
+	assert.True(t, isErrorsFile("cluster-resources/pods-errors.json"))
+	assert.True(t, isErrorsFile("node-stats/errors.json"))
+	assert.True(t, isErrorsFile("errors.json"))
+	assert.False(t, isErrorsFile("cluster-resources/pods/default.json"))
+	assert.False(t, isErrorsFile("app/errors.log"))
+}
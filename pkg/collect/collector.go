@@ -30,6 +30,22 @@ type MergeableCollector interface {
 
 //type Collectors []*Collector
 
+// isExcludedWhen returns true if a collector is excluded, or has a when that is false
+func isExcludedWhen(excludeVal *multitype.BoolOrString, whenVal *multitype.BoolOrString) (bool, error) {
+	excluded, err := isExcluded(excludeVal)
+	if err != nil || excluded {
+		return excluded, err
+	}
+	if whenVal == nil || whenVal.Type == multitype.String && whenVal.StrVal == "" {
+		return false, nil
+	}
+	when, err := isExcluded(whenVal)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to parse when")
+	}
+	return !when, nil
+}
+
 func isExcluded(excludeVal *multitype.BoolOrString) (bool, error) {
 	if excludeVal == nil {
 		return false, nil
@@ -51,6 +67,24 @@ func isExcluded(excludeVal *multitype.BoolOrString) (bool, error) {
 	return parsed, nil
 }
 
+// GetCollectorWithClients is GetCollector for collectors that make their requests with the clients of a
+// ClientFactory, so that all the collectors share their connections to the API server
+func GetCollectorWithClients(collector *troubleshootv1beta2.Collect, bundlePath string, namespace string, clients *ClientFactory, sinceTime *time.Time) (interface{}, bool) {
+	c, ok := GetCollector(collector, bundlePath, namespace, clients.Config(), clients.Kubernetes(), sinceTime)
+	if !ok {
+		return nil, false
+	}
+
+	switch v := c.(type) {
+	case *CollectClusterResources:
+		v.Clients = clients
+	case *CollectClusterAutoscaler:
+		v.Clients = clients
+	}
+
+	return c, true
+}
+
 func GetCollector(collector *troubleshootv1beta2.Collect, bundlePath string, namespace string, clientConfig *rest.Config, client kubernetes.Interface, sinceTime *time.Time) (interface{}, bool) {
 
 	ctx := context.TODO()
@@ -59,9 +93,9 @@ func GetCollector(collector *troubleshootv1beta2.Collect, bundlePath string, nam
 
 	switch {
 	case collector.ClusterInfo != nil:
-		return &CollectClusterInfo{collector.ClusterInfo, bundlePath, namespace, clientConfig, RBACErrors}, true
+		return &CollectClusterInfo{collector.ClusterInfo, bundlePath, namespace, clientConfig, client, RBACErrors}, true
 	case collector.ClusterResources != nil:
-		return &CollectClusterResources{collector.ClusterResources, bundlePath, namespace, clientConfig, RBACErrors}, true
+		return &CollectClusterResources{collector.ClusterResources, bundlePath, namespace, clientConfig, nil, ctx, sinceTime, RBACErrors}, true
 	case collector.Secret != nil:
 		return &CollectSecret{collector.Secret, bundlePath, namespace, clientConfig, client, ctx, RBACErrors}, true
 	case collector.ConfigMap != nil:
@@ -81,7 +115,7 @@ func GetCollector(collector *troubleshootv1beta2.Collect, bundlePath string, nam
 	case collector.CopyFromHost != nil:
 		return &CollectCopyFromHost{collector.CopyFromHost, bundlePath, namespace, clientConfig, client, ctx, RBACErrors}, true
 	case collector.HTTP != nil:
-		return &CollectHTTP{collector.HTTP, bundlePath, namespace, clientConfig, client, RBACErrors}, true
+		return &CollectHTTP{collector.HTTP, bundlePath, namespace, clientConfig, client, ctx, RBACErrors}, true
 	case collector.Postgres != nil:
 		return &CollectPostgres{collector.Postgres, bundlePath, namespace, clientConfig, client, ctx, RBACErrors}, true
 	case collector.Mysql != nil:
@@ -98,6 +132,24 @@ func GetCollector(collector *troubleshootv1beta2.Collect, bundlePath string, nam
 		return &CollectRegistry{collector.RegistryImages, bundlePath, namespace, clientConfig, client, ctx, RBACErrors}, true
 	case collector.Sysctl != nil:
 		return &CollectSysctl{collector.Sysctl, bundlePath, namespace, clientConfig, client, ctx, RBACErrors}, true
+	case collector.Custom != nil:
+		return &CollectCustom{collector.Custom, bundlePath, namespace, clientConfig, client, ctx, sinceTime, RBACErrors}, true
+	case collector.ClusterAutoscaler != nil:
+		return &CollectClusterAutoscaler{collector.ClusterAutoscaler, bundlePath, namespace, clientConfig, client, nil, ctx, sinceTime, RBACErrors}, true
+	case collector.ServiceEndpoints != nil:
+		return &CollectServiceEndpoints{collector.ServiceEndpoints, bundlePath, namespace, clientConfig, client, ctx, RBACErrors}, true
+	case collector.NodeStats != nil:
+		return &CollectNodeStats{collector.NodeStats, bundlePath, namespace, clientConfig, client, ctx, RBACErrors}, true
+	case collector.Events != nil:
+		return &CollectEvents{collector.Events, bundlePath, namespace, clientConfig, client, ctx, sinceTime, RBACErrors}, true
+	case collector.Storage != nil:
+		return &CollectStorage{collector.Storage, bundlePath, namespace, clientConfig, client, ctx, RBACErrors}, true
+	case collector.Vault != nil:
+		return &CollectVault{collector.Vault, bundlePath, namespace, clientConfig, client, ctx, RBACErrors}, true
+	case collector.OIDC != nil:
+		return &CollectOIDC{collector.OIDC, bundlePath, namespace, clientConfig, client, ctx, RBACErrors}, true
+	case collector.ClusterSummary != nil:
+		return &CollectClusterSummary{collector.ClusterSummary, bundlePath, namespace, clientConfig, client, ctx, RBACErrors}, true
 	default:
 		return nil, false
 	}
@@ -177,6 +229,35 @@ func getCollectorName(c interface{}) string {
 	case *CollectSysctl:
 		collector = "sysctl"
 		name = v.Collector.Name
+	case *CollectCustom:
+		collector = v.Collector.Type
+		name = v.Collector.CollectorName
+	case *CollectClusterAutoscaler:
+		collector = "cluster-autoscaler"
+		name = v.Collector.CollectorName
+	case *CollectServiceEndpoints:
+		collector = "service-endpoints"
+		name = v.Collector.CollectorName
+		selector = strings.Join(v.Collector.Selector, ",")
+	case *CollectNodeStats:
+		collector = "node-stats"
+		name = v.Collector.CollectorName
+		selector = strings.Join(v.Collector.Selector, ",")
+	case *CollectEvents:
+		collector = "events"
+		name = v.Collector.CollectorName
+	case *CollectStorage:
+		collector = "storage"
+		name = v.Collector.CollectorName
+	case *CollectVault:
+		collector = "vault"
+		name = v.Collector.CollectorName
+	case *CollectOIDC:
+		collector = "oidc"
+		name = v.Collector.CollectorName
+	case *CollectClusterSummary:
+		collector = "cluster-summary"
+		name = v.Collector.CollectorName
 	default:
 		collector = "<none>"
 	}
@@ -189,3 +270,176 @@ func getCollectorName(c interface{}) string {
 	}
 	return collector
 }
+
+// RunCollector runs a collector with the timeout from its spec, if it has one. A collector that is
+// still running when ctx is done is abandoned and an error is returned, so that the caller can record
+// it and move on to the next collector instead of hanging.
+func RunCollector(ctx context.Context, c Collector, progressChan chan<- interface{}) (CollectorResult, error) {
+	timeout, err := getCollectorTimeout(c)
+	if err != nil {
+		return nil, err
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	setCollectorContext(c, ctx)
+
+	type collectResult struct {
+		result CollectorResult
+		err    error
+	}
+	done := make(chan collectResult, 1)
+	collectorProgressChan := make(chan interface{})
+	go func() {
+		defer close(collectorProgressChan)
+		result, err := c.Collect(collectorProgressChan)
+		done <- collectResult{result, err}
+	}()
+
+	for {
+		select {
+		case msg, ok := <-collectorProgressChan:
+			if ok {
+				progressChan <- msg
+			}
+		case r := <-done:
+			return r.result, r.err
+		case <-ctx.Done():
+			// an abandoned collector can still send progress until Collect returns
+			go func() {
+				for range collectorProgressChan {
+				}
+			}()
+			if timeout > 0 && ctx.Err() == context.DeadlineExceeded {
+				return nil, errors.Errorf("timed out after %s", timeout)
+			}
+			return nil, errors.Wrap(ctx.Err(), "collector did not finish")
+		}
+	}
+}
+
+// contextOrBackground is used by collectors that can be created without a context
+func contextOrBackground(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
+
+func getCollectorTimeout(c interface{}) (time.Duration, error) {
+	var timeout string
+
+	switch v := c.(type) {
+	case *CollectClusterResources:
+		timeout = v.Collector.Timeout
+	case *CollectSecret:
+		timeout = v.Collector.Timeout
+	case *CollectConfigMap:
+		timeout = v.Collector.Timeout
+	case *CollectLogs:
+		timeout = v.Collector.Timeout
+	case *CollectCopy:
+		timeout = v.Collector.Timeout
+	case *CollectHTTP:
+		timeout = v.Collector.Timeout
+	case *CollectPostgres:
+		timeout = v.Collector.Timeout
+	case *CollectMysql:
+		timeout = v.Collector.Timeout
+	case *CollectRedis:
+		timeout = v.Collector.Timeout
+	case *CollectRegistry:
+		timeout = v.Collector.Timeout
+	case *CollectCustom:
+		timeout = v.Collector.Timeout
+	case *CollectClusterAutoscaler:
+		timeout = v.Collector.Timeout
+	case *CollectServiceEndpoints:
+		timeout = v.Collector.Timeout
+	case *CollectNodeStats:
+		timeout = v.Collector.Timeout
+	case *CollectEvents:
+		timeout = v.Collector.Timeout
+	case *CollectStorage:
+		timeout = v.Collector.Timeout
+	case *CollectVault:
+		timeout = v.Collector.Timeout
+	case *CollectOIDC:
+		timeout = v.Collector.Timeout
+	case *CollectClusterSummary:
+		timeout = v.Collector.Timeout
+	}
+
+	if timeout == "" {
+		return 0, nil
+	}
+
+	duration, err := time.ParseDuration(timeout)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse timeout %q", timeout)
+	}
+	return duration, nil
+}
+
+func setCollectorContext(c interface{}, ctx context.Context) {
+	switch v := c.(type) {
+	case *CollectClusterResources:
+		v.Context = ctx
+	case *CollectSecret:
+		v.Context = ctx
+	case *CollectConfigMap:
+		v.Context = ctx
+	case *CollectLogs:
+		v.Context = ctx
+	case *CollectRun:
+		v.Context = ctx
+	case *CollectRunPod:
+		v.Context = ctx
+	case *CollectExec:
+		v.Context = ctx
+	case *CollectData:
+		v.Context = ctx
+	case *CollectCopy:
+		v.Context = ctx
+	case *CollectCopyFromHost:
+		v.Context = ctx
+	case *CollectHTTP:
+		v.Context = ctx
+	case *CollectPostgres:
+		v.Context = ctx
+	case *CollectMysql:
+		v.Context = ctx
+	case *CollectRedis:
+		v.Context = ctx
+	case *CollectCollectd:
+		v.Context = ctx
+	case *CollectCeph:
+		v.Context = ctx
+	case *CollectLonghorn:
+		v.Context = ctx
+	case *CollectRegistry:
+		v.Context = ctx
+	case *CollectSysctl:
+		v.Context = ctx
+	case *CollectCustom:
+		v.Context = ctx
+	case *CollectClusterAutoscaler:
+		v.Context = ctx
+	case *CollectServiceEndpoints:
+		v.Context = ctx
+	case *CollectNodeStats:
+		v.Context = ctx
+	case *CollectEvents:
+		v.Context = ctx
+	case *CollectStorage:
+		v.Context = ctx
+	case *CollectVault:
+		v.Context = ctx
+	case *CollectOIDC:
+		v.Context = ctx
+	case *CollectClusterSummary:
+		v.Context = ctx
+	}
+}
@@ -1,10 +1,13 @@
 package collect
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
 	"github.com/replicatedhq/troubleshoot/pkg/multitype"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -285,7 +288,7 @@ pwd=somethinggoeshere;`,
 				result, err = regCollector.Collect(nil)
 				req.NoError(err)
 
-				err = RedactResult("", result, tt.Redactors)
+				err = RedactResult("", result, nil, tt.Redactors)
 
 				req.NoError(err)
 			}
@@ -364,3 +367,146 @@ pwd=somethinggoeshere;`,
 		})
 	}
 }
+
+type blockingCollector struct {
+	RBACErrors
+	release chan struct{}
+}
+
+func (c *blockingCollector) Title() string {
+	return "blocking"
+}
+
+func (c *blockingCollector) IsExcluded() (bool, error) {
+	return false, nil
+}
+
+func (c *blockingCollector) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
+	progressChan <- "started"
+	<-c.release
+	progressChan <- "finished"
+	return CollectorResult{"blocking.txt": []byte("done")}, nil
+}
+
+func TestRunCollector(t *testing.T) {
+	req := require.New(t)
+
+	progressChan := make(chan interface{}, 10)
+	c := &blockingCollector{release: make(chan struct{})}
+	close(c.release)
+
+	result, err := RunCollector(context.Background(), c, progressChan)
+	req.NoError(err)
+	req.Equal(CollectorResult{"blocking.txt": []byte("done")}, result)
+	req.Equal("started", <-progressChan)
+	req.Equal("finished", <-progressChan)
+}
+
+func TestRunCollector_Timeout(t *testing.T) {
+	req := require.New(t)
+
+	progressChan := make(chan interface{}, 10)
+	c := &blockingCollector{release: make(chan struct{})}
+	defer close(c.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result, err := RunCollector(ctx, c, progressChan)
+	req.Error(err)
+	req.Contains(err.Error(), "context deadline exceeded")
+	req.Nil(result)
+
+	// collectors with an invalid timeout are not run
+	_, err = RunCollector(context.Background(), &CollectHTTP{
+		Collector: &troubleshootv1beta2.HTTP{Timeout: "soon"},
+	}, progressChan)
+	req.Error(err)
+	req.Contains(err.Error(), `failed to parse timeout "soon"`)
+}
+
+func Test_getCollectorTimeout(t *testing.T) {
+	tests := []struct {
+		name      string
+		collector interface{}
+		want      time.Duration
+	}{
+		{
+			name:      "no timeout",
+			collector: &CollectSecret{Collector: &troubleshootv1beta2.Secret{}},
+		},
+		{
+			name:      "secret",
+			collector: &CollectSecret{Collector: &troubleshootv1beta2.Secret{Timeout: "30s"}},
+			want:      30 * time.Second,
+		},
+		{
+			name:      "postgres",
+			collector: &CollectPostgres{Collector: &troubleshootv1beta2.Database{Timeout: "1m"}},
+			want:      time.Minute,
+		},
+		{
+			name:      "collectors without a timeout field",
+			collector: &CollectClusterInfo{Collector: &troubleshootv1beta2.ClusterInfo{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getCollectorTimeout(tt.collector)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_isExcludedWhen(t *testing.T) {
+	tests := []struct {
+		name    string
+		exclude *multitype.BoolOrString
+		when    *multitype.BoolOrString
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "neither",
+			want: false,
+		},
+		{
+			name: "when true",
+			when: multitype.FromBool(true),
+			want: false,
+		},
+		{
+			name: "when false",
+			when: multitype.FromString("false"),
+			want: true,
+		},
+		{
+			name:    "excluded when true",
+			exclude: multitype.FromBool(true),
+			when:    multitype.FromBool(true),
+			want:    true,
+		},
+		{
+			name: "empty when",
+			when: multitype.FromString(""),
+			want: false,
+		},
+		{
+			name:    "invalid when",
+			when:    multitype.FromString("maybe"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := isExcludedWhen(tt.exclude, tt.when)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -42,7 +42,7 @@ func (c *CollectConfigMap) Title() string {
 }
 
 func (c *CollectConfigMap) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 func (c *CollectConfigMap) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
@@ -0,0 +1,129 @@
+package collect
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// ContentType tags a collected file with the kind of data in it, so that redactors and analyzers can
+// select files by what they contain instead of by path
+type ContentType string
+
+const (
+	ContentTypeK8sJSON   ContentType = "k8s-json"
+	ContentTypePodLog    ContentType = "pod-log"
+	ContentTypePEM       ContentType = "pem"
+	ContentTypeBinary    ContentType = "binary"
+	ContentTypeSQLResult ContentType = "sql-result"
+)
+
+// sniffLength is how much of a file is read to detect pem and binary files
+const sniffLength = 512
+
+// ContentTypes are the content types of collected files by path. Files without a known type are not
+// included.
+type ContentTypes map[string]ContentType
+
+// Add tags the files collected by c. Files are tagged by the collector that wrote them, and files with
+// no type from their collector are tagged as pem or binary if their contents are.
+func (t ContentTypes) Add(c interface{}, bundlePath string, result CollectorResult) {
+	for relativePath := range result {
+		contentType := collectorContentType(c, relativePath)
+		if contentType == "" {
+			contentType = sniffContentType(bundlePath, result, relativePath)
+		}
+		if contentType != "" {
+			t[relativePath] = contentType
+		}
+	}
+}
+
+func collectorContentType(c interface{}, relativePath string) ContentType {
+	ext := filepath.Ext(relativePath)
+
+	switch c.(type) {
+	case *CollectClusterResources:
+		if ext == ".log" {
+			return ContentTypePodLog
+		}
+		// errors files and the openshift summary are not API objects
+		if ext == ".json" && !strings.HasSuffix(relativePath, "-errors.json") && relativePath != path.Join("cluster-resources/openshift", OpenShiftSummaryFilename) {
+			return ContentTypeK8sJSON
+		}
+	case *CollectLogs, *CollectRun, *CollectRunPod:
+		if ext == ".log" {
+			return ContentTypePodLog
+		}
+	case *CollectPostgres, *CollectMysql:
+		return ContentTypeSQLResult
+	case *CollectClusterAutoscaler:
+		if ext == ".log" {
+			return ContentTypePodLog
+		}
+		// the status and node groups are not API objects
+		if path.Base(relativePath) == "events.json" || path.Base(path.Dir(relativePath)) == "machines" {
+			return ContentTypeK8sJSON
+		}
+	}
+
+	return ""
+}
+
+func sniffContentType(bundlePath string, result CollectorResult, relativePath string) ContentType {
+	reader, err := result.GetReader(bundlePath, relativePath)
+	if err != nil {
+		return ""
+	}
+	defer reader.Close()
+
+	b := make([]byte, sniffLength)
+	n, err := io.ReadFull(reader, b)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return ""
+	}
+	b = b[:n]
+
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("-----BEGIN ")) {
+		return ContentTypePEM
+	}
+	// a multi-byte character can be cut off at the end of what was read
+	if n == sniffLength {
+		for i := 0; i < utf8.UTFMax-1 && !utf8.Valid(b); i++ {
+			b = b[:len(b)-1]
+		}
+	}
+	if !utf8.Valid(b) || bytes.IndexByte(b, 0) != -1 {
+		return ContentTypeBinary
+	}
+	return ""
+}
+
+// readContentTypes reads the content types of a bundle's files from its collection metadata. Bundles
+// collected by older versions have no content types.
+func readContentTypes(bundlePath string, result CollectorResult) ContentTypes {
+	reader, err := result.GetReader(bundlePath, CollectionMetadataFilename)
+	if err != nil {
+		return ContentTypes{}
+	}
+	defer reader.Close()
+
+	return decodeContentTypes(reader)
+}
+
+// ContentTypesFromMetadata reads the content types from the contents of a collection metadata file
+func ContentTypesFromMetadata(metadata []byte) ContentTypes {
+	return decodeContentTypes(bytes.NewReader(metadata))
+}
+
+func decodeContentTypes(r io.Reader) ContentTypes {
+	metadata := CollectionMetadata{}
+	if err := json.NewDecoder(r).Decode(&metadata); err != nil || metadata.ContentTypes == nil {
+		return ContentTypes{}
+	}
+	return metadata.ContentTypes
+}
@@ -0,0 +1,74 @@
+package collect
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentTypes_Add(t *testing.T) {
+	req := require.New(t)
+
+	bundlePath := t.TempDir()
+	result := NewResult()
+	files := map[string]string{
+		"cluster-resources/pods/default.json": `{"items": []}`,
+		"cluster-resources/pods-errors.json":  `["forbidden"]`,
+		"cluster-resources/pods/logs/web.log": "started\n",
+		"cluster-resources/nodes.yaml":        "items: []\n",
+		"certs/ca.crt":                        "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n",
+		"dumps/core":                          "\x7fELF\x00\x00",
+		// a multi-byte character is cut off by the sniff length
+		"notes/long.txt": strings.Repeat("a", sniffLength-1) + "é",
+	}
+	for name, contents := range files {
+		req.NoError(result.SaveResult(bundlePath, name, bytes.NewBufferString(contents)))
+	}
+
+	contentTypes := ContentTypes{}
+	contentTypes.Add(&CollectClusterResources{}, bundlePath, result)
+
+	assert.Equal(t, ContentTypes{
+		"cluster-resources/pods/default.json": ContentTypeK8sJSON,
+		"cluster-resources/pods/logs/web.log": ContentTypePodLog,
+		"certs/ca.crt":                        ContentTypePEM,
+		"dumps/core":                          ContentTypeBinary,
+	}, contentTypes)
+
+	postgresResult := CollectorResult{filepath.Join("postgres", "db.json"): []byte(`{"isConnected": true}`)}
+	contentTypes.Add(&CollectPostgres{}, bundlePath, postgresResult)
+	assert.Equal(t, ContentTypeSQLResult, contentTypes["postgres/db.json"])
+
+	autoscalerResult := CollectorResult{
+		"cluster-autoscaler/events.json":                                []byte(`[]`),
+		"cluster-autoscaler/node-groups.json":                           []byte(`[]`),
+		"cluster-autoscaler/machines/machinesets.cluster.x-k8s.io.json": []byte(`{"items": []}`),
+	}
+	contentTypes.Add(&CollectClusterAutoscaler{}, bundlePath, autoscalerResult)
+	assert.Equal(t, ContentTypeK8sJSON, contentTypes["cluster-autoscaler/events.json"])
+	assert.Equal(t, ContentTypeK8sJSON, contentTypes["cluster-autoscaler/machines/machinesets.cluster.x-k8s.io.json"])
+	assert.NotContains(t, contentTypes, "cluster-autoscaler/node-groups.json")
+}
+
+func TestReadContentTypes(t *testing.T) {
+	req := require.New(t)
+
+	metadata := NewCollectionMetadata(time.Now())
+	metadata.ContentTypes["app/web.log"] = ContentTypePodLog
+	b, err := json.Marshal(metadata)
+	req.NoError(err)
+
+	result := CollectorResult{CollectionMetadataFilename: b}
+	assert.Equal(t, ContentTypes{"app/web.log": ContentTypePodLog}, readContentTypes("", result))
+	assert.Equal(t, ContentTypes{"app/web.log": ContentTypePodLog}, ContentTypesFromMetadata(b))
+
+	// bundles collected by older versions have no content types
+	assert.Equal(t, ContentTypes{}, readContentTypes("", NewResult()))
+	assert.Equal(t, ContentTypes{}, ContentTypesFromMetadata([]byte(`{"version": "v0.40.0"}`)))
+}
@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
@@ -35,25 +36,36 @@ func (c *CollectCopy) Title() string {
 }
 
 func (c *CollectCopy) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 // Copy function gets a file or folder from a container specified in the specs.
 func (c *CollectCopy) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
-	client, err := kubernetes.NewForConfig(c.ClientConfig)
+	client, err := kubernetesClientOrDefault(c.Client, c.ClientConfig)
 	if err != nil {
 		return nil, err
 	}
 
 	output := NewResult()
 
-	ctx := context.Background()
+	ctx := contextOrBackground(c.Context)
 
 	pods, podsErrors := listPodsInSelectors(ctx, client, c.Collector.Namespace, c.Collector.Selector)
 	if len(podsErrors) > 0 {
 		output.SaveResult(c.BundlePath, getCopyErrosFileName(c.Collector), marshalErrors(podsErrors))
 	}
 
+	dir := c.Collector.Name
+	if dir == "" {
+		dir = CopiedFilesDir
+	}
+
+	limits := copyLimits{
+		MaxFileBytes:   c.Collector.MaxFileBytes,
+		MaxBytes:       c.Collector.MaxBytes,
+		FollowSymlinks: c.Collector.FollowSymlinks,
+	}
+
 	if len(pods) > 0 {
 		for _, pod := range pods {
 
@@ -62,14 +74,14 @@ func (c *CollectCopy) Collect(progressChan chan<- interface{}) (CollectorResult,
 				containerName = c.Collector.ContainerName
 			}
 
-			subPath := filepath.Join(c.Collector.Name, pod.Namespace, pod.Name, c.Collector.ContainerName)
+			subPath := filepath.Join(dir, pod.Namespace, pod.Name, c.Collector.ContainerName)
 
 			c.Collector.ExtractArchive = true // TODO: existing regression. this flag is always ignored and this matches current behaviour
 
 			copyErrors := map[string]string{}
 
-			dstPath := filepath.Join(c.BundlePath, subPath, filepath.Dir(c.Collector.ContainerPath))
-			files, stderr, err := copyFilesFromPod(ctx, dstPath, c.ClientConfig, client, pod.Name, containerName, pod.Namespace, c.Collector.ContainerPath, c.Collector.ExtractArchive)
+			relativeDir := filepath.Join(subPath, filepath.Dir(c.Collector.ContainerPath))
+			files, stderr, err := copyFilesFromPod(ctx, c.BundlePath, relativeDir, c.ClientConfig, client, pod.Name, containerName, pod.Namespace, c.Collector.ContainerPath, c.Collector.ExtractArchive, limits)
 			if err != nil {
 				copyErrors[filepath.Join(c.Collector.ContainerPath, "error")] = err.Error()
 				if len(stderr) > 0 {
@@ -78,11 +90,11 @@ func (c *CollectCopy) Collect(progressChan chan<- interface{}) (CollectorResult,
 
 				key := filepath.Join(subPath, c.Collector.ContainerPath+"-errors.json")
 				output.SaveResult(c.BundlePath, key, marshalErrors(copyErrors))
-				continue
 			}
 
+			// the files that were copied before an error are kept
 			for k, v := range files {
-				output[filepath.Join(subPath, filepath.Dir(c.Collector.ContainerPath), k)] = v
+				output[k] = v
 			}
 		}
 	}
@@ -90,8 +102,28 @@ func (c *CollectCopy) Collect(progressChan chan<- interface{}) (CollectorResult,
 	return output, nil
 }
 
-func copyFilesFromPod(ctx context.Context, dstPath string, clientConfig *restclient.Config, client kubernetes.Interface, podName string, containerName string, namespace string, containerPath string, extract bool) (CollectorResult, []byte, error) {
-	command := []string{"tar", "-C", filepath.Dir(containerPath), "-cf", "-", filepath.Base(containerPath)}
+// CopiedFilesDir is where the files of copy collectors without a name are saved
+const CopiedFilesDir = "copied-files"
+
+// copyLimits are what is copied out of a container when the archive is extracted
+type copyLimits struct {
+	// MaxFileBytes is the size past which a file is skipped
+	MaxFileBytes int64
+	// MaxBytes is the size of all the files, past which the rest are skipped
+	MaxBytes       int64
+	FollowSymlinks bool
+}
+
+// copyFilesFromPod copies containerPath out of the container into relativeDir. Without a bundle path, the
+// files are kept in the result, with large files spilled to temp files rather than held in memory. The
+// files that are skipped because of the limits, and the links that are not copied, are saved next to them.
+func copyFilesFromPod(ctx context.Context, bundlePath string, relativeDir string, clientConfig *restclient.Config, client kubernetes.Interface, podName string, containerName string, namespace string, containerPath string, extract bool, limits copyLimits) (CollectorResult, []byte, error) {
+	tarFlags := "-cf"
+	if limits.FollowSymlinks && extract {
+		// -h archives the files that symlinks point to, busybox tar has it as well
+		tarFlags = "-chf"
+	}
+	command := []string{"tar", "-C", filepath.Dir(containerPath), tarFlags, "-", filepath.Base(containerPath)}
 	req := client.CoreV1().RESTClient().Post().Resource("pods").Name(podName).Namespace(namespace).SubResource("exec")
 	scheme := runtime.NewScheme()
 	if err := corev1.AddToScheme(scheme); err != nil {
@@ -102,8 +134,8 @@ func copyFilesFromPod(ctx context.Context, dstPath string, clientConfig *restcli
 	req.VersionedParams(&corev1.PodExecOptions{
 		Command:   command,
 		Container: containerName,
-		Stdin:     true,
-		Stdout:    false,
+		Stdin:     false,
+		Stdout:    true,
 		Stderr:    true,
 		TTY:       false,
 	}, parameterCodec)
@@ -113,62 +145,58 @@ func copyFilesFromPod(ctx context.Context, dstPath string, clientConfig *restcli
 		return nil, nil, errors.Wrap(err, "failed to create SPDY executor")
 	}
 
-	result := NewResult()
-
 	var stdoutWriter io.Writer
-	var copyError error
+	var extracted chan copyExtraction
+	var result CollectorResult
 	if extract {
 		pipeReader, pipeWriter := io.Pipe()
-		tarReader := tar.NewReader(pipeReader)
 		stdoutWriter = pipeWriter
+		extracted = make(chan copyExtraction, 1)
 
 		go func() {
-			// this can cause "read/write on closed pipe" error, but without this exec.Stream blocks
-			defer pipeWriter.Close()
-
-			for {
-				header, err := tarReader.Next()
-				if err == io.EOF {
-					return
-				}
-				if err != nil {
-					pipeWriter.CloseWithError(errors.Wrap(err, "failed to read header from tar"))
-					return
-				}
-
-				switch header.Typeflag {
-				case tar.TypeDir:
-					name := filepath.Join(dstPath, header.Name)
-					if err := os.MkdirAll(name, os.FileMode(header.Mode)); err != nil {
-						pipeWriter.CloseWithError(errors.Wrap(err, "failed to mkdir"))
-						return
-					}
-				case tar.TypeReg:
-					err := result.SaveResult(dstPath, header.Name, tarReader)
-					if err != nil {
-						pipeWriter.CloseWithError(errors.Wrapf(err, "failed to save result for file %s", header.Name))
-						return
-					}
-				}
+			files, skipped, err := extractCopiedFiles(bundlePath, relativeDir, pipeReader, limits)
+			if err != nil {
+				// the error is returned to the writer, which stops the stream
+				pipeReader.CloseWithError(err)
+			} else {
+				// tar pads the archive past the end of it
+				io.Copy(ioutil.Discard, pipeReader)
 			}
+			extracted <- copyExtraction{files, skipped, err}
 		}()
 	} else {
-		w, err := result.GetWriter(dstPath, filepath.Base(containerPath)+".tar")
+		result = NewResult()
+		key := filepath.Join(relativeDir, filepath.Base(containerPath)+".tar")
+		w, err := result.GetWriter(bundlePath, key)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "failed to craete dest file")
 		}
-		defer result.CloseWriter(dstPath, filepath.Base(containerPath)+".tar", w)
+		defer result.CloseWriter(bundlePath, key, w)
 
 		stdoutWriter = w
 	}
 
 	var stderr bytes.Buffer
-	copyError = exec.Stream(remotecommand.StreamOptions{
+	copyError := exec.Stream(remotecommand.StreamOptions{
 		Stdin:  nil,
 		Stdout: stdoutWriter,
 		Stderr: &stderr,
 		Tty:    false,
 	})
+
+	if extract {
+		stdoutWriter.(*io.PipeWriter).CloseWithError(copyError)
+		extraction := <-extracted
+		result = extraction.files
+		if len(extraction.skipped) > 0 {
+			key := filepath.Join(relativeDir, filepath.Base(containerPath)+"-skipped.json")
+			result.SaveResult(bundlePath, key, marshalErrors(extraction.skipped))
+		}
+		if copyError == nil && extraction.err != nil {
+			copyError = extraction.err
+		}
+	}
+
 	if copyError != nil {
 		return result, stderr.Bytes(), errors.Wrap(copyError, "failed to stream command output")
 	}
@@ -176,6 +204,69 @@ func copyFilesFromPod(ctx context.Context, dstPath string, clientConfig *restcli
 	return result, stderr.Bytes(), nil
 }
 
+type copyExtraction struct {
+	files   CollectorResult
+	skipped map[string]string
+	err     error
+}
+
+// extractCopiedFiles saves the files of the archive under relativeDir, and returns the reason that each
+// of the entries that were not saved was skipped
+func extractCopiedFiles(bundlePath string, relativeDir string, reader io.Reader, limits copyLimits) (CollectorResult, map[string]string, error) {
+	result := NewResult()
+	skipped := map[string]string{}
+
+	copiedBytes := int64(0)
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return result, skipped, nil
+		}
+		if err != nil {
+			return result, skipped, errors.Wrap(err, "failed to read header from tar")
+		}
+
+		// names are relative to the parent of the copied path, and must not leave it
+		name := filepath.Clean(header.Name)
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			skipped[header.Name] = "outside of the copied path"
+			continue
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if bundlePath == "" {
+				continue
+			}
+			dir := filepath.Join(bundlePath, relativeDir, name)
+			if err := os.MkdirAll(dir, os.FileMode(header.Mode)|0700); err != nil {
+				return result, skipped, errors.Wrap(err, "failed to mkdir")
+			}
+		case tar.TypeReg:
+			if limits.MaxFileBytes > 0 && header.Size > limits.MaxFileBytes {
+				skipped[name] = fmt.Sprintf("%d bytes is larger than the max of %d bytes for a file", header.Size, limits.MaxFileBytes)
+				continue
+			}
+			if limits.MaxBytes > 0 && copiedBytes+header.Size > limits.MaxBytes {
+				skipped[name] = fmt.Sprintf("%d bytes were copied, and the max is %d bytes", copiedBytes, limits.MaxBytes)
+				continue
+			}
+			if err := result.SaveResult(bundlePath, filepath.Join(relativeDir, name), tarReader); err != nil {
+				return result, skipped, errors.Wrapf(err, "failed to save result for file %s", name)
+			}
+			copiedBytes += header.Size
+		case tar.TypeSymlink:
+			// links are not created in the bundle, where they could point anywhere on the host
+			skipped[name] = fmt.Sprintf("symlink to %s", header.Linkname)
+		case tar.TypeLink:
+			skipped[name] = fmt.Sprintf("hard link to %s", header.Linkname)
+		default:
+			skipped[name] = "not a regular file"
+		}
+	}
+}
+
 func getCopyErrosFileName(copyCollector *troubleshootv1beta2.Copy) string {
 	if len(copyCollector.Name) > 0 {
 		return fmt.Sprintf("%s-errors.json", copyCollector.Name)
@@ -184,7 +275,8 @@ func getCopyErrosFileName(copyCollector *troubleshootv1beta2.Copy) string {
 		return fmt.Sprintf("%s-errors.json", copyCollector.CollectorName)
 	}
 	// TODO: random part
-	return "errors.json"
+	// not errors.json, which is the error manifest at the root of the bundle
+	return "copy-errors.json"
 }
 
 func extractTar(reader io.Reader) (map[string][]byte, error) {
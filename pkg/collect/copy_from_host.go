@@ -41,7 +41,7 @@ func (c *CollectCopyFromHost) Title() string {
 }
 
 func (c *CollectCopyFromHost) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 // copies a file or directory from a host or hosts to include in the bundle.
@@ -143,6 +143,7 @@ func copyFromHostCreateDaemonSet(ctx context.Context, client kubernetes.Interfac
 					Labels: labels,
 				},
 				Spec: corev1.PodSpec{
+					NodeSelector:  collector.NodeSelector,
 					RestartPolicy: corev1.RestartPolicyAlways,
 					Containers: []corev1.Container{
 						{
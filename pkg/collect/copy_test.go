@@ -0,0 +1,69 @@
+package collect
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func copyTestArchive(t *testing.T, headers ...tar.Header) *bytes.Buffer {
+	var b bytes.Buffer
+	w := tar.NewWriter(&b)
+	for _, header := range headers {
+		header := header
+		contents := bytes.Repeat([]byte("a"), int(header.Size))
+		require.NoError(t, w.WriteHeader(&header))
+		_, err := w.Write(contents)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return &b
+}
+
+func Test_extractCopiedFiles(t *testing.T) {
+	archive := copyTestArchive(t,
+		tar.Header{Typeflag: tar.TypeDir, Name: "diagnostics/", Mode: 0755},
+		tar.Header{Typeflag: tar.TypeReg, Name: "diagnostics/a.log", Mode: 0644, Size: 10},
+		tar.Header{Typeflag: tar.TypeReg, Name: "diagnostics/heap.dump", Mode: 0644, Size: 100},
+		tar.Header{Typeflag: tar.TypeReg, Name: "diagnostics/b.log", Mode: 0644, Size: 20},
+		tar.Header{Typeflag: tar.TypeReg, Name: "diagnostics/c.log", Mode: 0644, Size: 20},
+		tar.Header{Typeflag: tar.TypeSymlink, Name: "diagnostics/latest.log", Linkname: "/var/log/app.log"},
+		tar.Header{Typeflag: tar.TypeReg, Name: "../etc/passwd", Mode: 0644, Size: 1},
+	)
+
+	bundlePath := t.TempDir()
+	relativeDir := filepath.Join(CopiedFilesDir, "default", "app-0", "var")
+	result, skipped, err := extractCopiedFiles(bundlePath, relativeDir, archive, copyLimits{MaxFileBytes: 50, MaxBytes: 40})
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{
+		filepath.Join(relativeDir, "diagnostics/a.log"),
+		filepath.Join(relativeDir, "diagnostics/b.log"),
+	}, resultFiles(result))
+	assert.Equal(t, map[string]string{
+		"diagnostics/heap.dump":  "100 bytes is larger than the max of 50 bytes for a file",
+		"diagnostics/c.log":      "30 bytes were copied, and the max is 40 bytes",
+		"diagnostics/latest.log": "symlink to /var/log/app.log",
+		"../etc/passwd":          "outside of the copied path",
+	}, skipped)
+
+	b, err := ioutil.ReadFile(filepath.Join(bundlePath, relativeDir, "diagnostics/b.log"))
+	require.NoError(t, err)
+	assert.Len(t, b, 20)
+}
+
+func Test_extractCopiedFiles_noLimits(t *testing.T) {
+	archive := copyTestArchive(t,
+		tar.Header{Typeflag: tar.TypeReg, Name: "app.log", Mode: 0644, Size: 1000},
+	)
+
+	result, skipped, err := extractCopiedFiles("", "copied", archive, copyLimits{})
+	require.NoError(t, err)
+	assert.Empty(t, skipped)
+	assert.Len(t, result["copied/app.log"], 1000)
+}
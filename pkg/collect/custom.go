@@ -0,0 +1,105 @@
+package collect
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// CustomCollector is a collector added by a program that embeds troubleshoot. Its results are redacted
+// and archived with the results of the built-in collectors.
+type CustomCollector interface {
+	Collect(progressChan chan<- interface{}) (CollectorResult, error)
+}
+
+// CustomCollectorOptions are passed to a CollectorFactory. Context is cancelled when the collector's
+// timeout is reached, and files should be saved under BundlePath with CollectorResult.SaveResult.
+type CustomCollectorOptions struct {
+	Context      context.Context
+	BundlePath   string
+	Namespace    string
+	ClientConfig *rest.Config
+	Client       kubernetes.Interface
+	SinceTime    *time.Time
+}
+
+// CollectorFactory creates the collector for a custom collector spec. It is called each time the
+// collector runs.
+type CollectorFactory func(collector *troubleshootv1beta2.Custom, opts CustomCollectorOptions) (CustomCollector, error)
+
+var (
+	customCollectorsMu sync.RWMutex
+	customCollectors   = map[string]CollectorFactory{}
+)
+
+// Register makes a collector available to specs as a custom collector with this type. Like
+// database/sql.Register, it panics if the type is registered twice or factory is nil, so it is meant to
+// be called from init.
+func Register(collectorType string, factory CollectorFactory) {
+	customCollectorsMu.Lock()
+	defer customCollectorsMu.Unlock()
+
+	if collectorType == "" {
+		panic("collect: Register collector type is empty")
+	}
+	if factory == nil {
+		panic("collect: Register factory is nil for " + collectorType)
+	}
+	if _, ok := customCollectors[collectorType]; ok {
+		panic("collect: Register called twice for " + collectorType)
+	}
+	customCollectors[collectorType] = factory
+}
+
+func getCollectorFactory(collectorType string) (CollectorFactory, bool) {
+	customCollectorsMu.RLock()
+	defer customCollectorsMu.RUnlock()
+
+	factory, ok := customCollectors[collectorType]
+	return factory, ok
+}
+
+type CollectCustom struct {
+	Collector    *troubleshootv1beta2.Custom
+	BundlePath   string
+	Namespace    string
+	ClientConfig *rest.Config
+	Client       kubernetes.Interface
+	Context      context.Context
+	SinceTime    *time.Time
+	RBACErrors
+}
+
+func (c *CollectCustom) Title() string {
+	return getCollectorName(c)
+}
+
+func (c *CollectCustom) IsExcluded() (bool, error) {
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
+}
+
+func (c *CollectCustom) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
+	factory, ok := getCollectorFactory(c.Collector.Type)
+	if !ok {
+		return nil, errors.Errorf("no collector is registered for type %q", c.Collector.Type)
+	}
+
+	collector, err := factory(c.Collector, CustomCollectorOptions{
+		Context:      contextOrBackground(c.Context),
+		BundlePath:   c.BundlePath,
+		Namespace:    c.Namespace,
+		ClientConfig: c.ClientConfig,
+		Client:       c.Client,
+		SinceTime:    c.SinceTime,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create %s collector", c.Collector.Type)
+	}
+
+	return collector.Collect(progressChan)
+}
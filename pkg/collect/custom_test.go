@@ -0,0 +1,73 @@
+package collect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type echoCollector struct {
+	spec *troubleshootv1beta2.Custom
+	opts CustomCollectorOptions
+}
+
+func (c *echoCollector) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
+	if _, ok := c.opts.Context.Deadline(); !ok {
+		progressChan <- "no deadline"
+	}
+
+	output := NewResult()
+	output.SaveResult(c.opts.BundlePath, filepath.Join("echo", c.spec.CollectorName+".json"), bytes.NewBuffer(c.spec.Spec.Raw))
+	return output, nil
+}
+
+func init() {
+	Register("test-echo", func(collector *troubleshootv1beta2.Custom, opts CustomCollectorOptions) (CustomCollector, error) {
+		return &echoCollector{spec: collector, opts: opts}, nil
+	})
+}
+
+func TestCollectCustom(t *testing.T) {
+	req := require.New(t)
+
+	var spec troubleshootv1beta2.Collect
+	req.NoError(json.Unmarshal([]byte(`{
+  "custom": {
+    "type": "test-echo",
+    "collectorName": "license",
+    "timeout": "30s",
+    "spec": {"key": "value"}
+  }
+}`), &spec))
+
+	collector, ok := GetCollector(&spec, "", "", nil, nil, nil)
+	req.True(ok)
+	customCollector := collector.(Collector)
+	assert.Equal(t, "test-echo/license", customCollector.Title())
+
+	progressChan := make(chan interface{}, 10)
+	result, err := RunCollector(context.Background(), customCollector, progressChan)
+	req.NoError(err)
+	req.Equal(CollectorResult{"echo/license.json": []byte(`{"key": "value"}`)}, result)
+	req.Empty(progressChan)
+
+	_, err = RunCollector(context.Background(), &CollectCustom{
+		Collector: &troubleshootv1beta2.Custom{Type: "not-registered"},
+	}, progressChan)
+	req.Error(err)
+	assert.Contains(t, err.Error(), `no collector is registered for type "not-registered"`)
+}
+
+func TestRegister_Twice(t *testing.T) {
+	assert.Panics(t, func() {
+		Register("test-echo", func(collector *troubleshootv1beta2.Custom, opts CustomCollectorOptions) (CustomCollector, error) {
+			return nil, nil
+		})
+	})
+}
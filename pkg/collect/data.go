@@ -25,7 +25,7 @@ func (c *CollectData) Title() string {
 }
 
 func (c *CollectData) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 func (c *CollectData) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
@@ -5,4 +5,13 @@ type DatabaseConnection struct {
 	Error       string            `json:"error,omitempty"`
 	Version     string            `json:"version,omitempty"`
 	Variables   map[string]string `json:"variables,omitempty"`
+	// Topology is the replication topology, if the collector collects it
+	Topology *DatabaseTopology `json:"topology,omitempty"`
+}
+
+// DatabaseQueryResult is the output of a diagnostic query, with a map of column to value for each row
+type DatabaseQueryResult struct {
+	Query string                   `json:"query"`
+	Rows  []map[string]interface{} `json:"rows"`
+	Error string                   `json:"error,omitempty"`
 }
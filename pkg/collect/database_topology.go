@@ -0,0 +1,288 @@
+package collect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+)
+
+type DatabaseRole string
+
+const (
+	DatabaseRolePrimary DatabaseRole = "primary"
+	DatabaseRoleReplica DatabaseRole = "replica"
+	DatabaseRoleUnknown DatabaseRole = "unknown"
+)
+
+// DatabaseTopology is the replication topology of a database, from the members that were connected to
+// and the replicas that the primary reported
+type DatabaseTopology struct {
+	Members []DatabaseMember `json:"members"`
+}
+
+type DatabaseMember struct {
+	// Address is the host and port of the member, without credentials
+	Address     string       `json:"address"`
+	Role        DatabaseRole `json:"role"`
+	IsConnected bool         `json:"isConnected"`
+	// Discovered is true for replicas that the primary reported, which were not connected to
+	Discovered bool `json:"discovered,omitempty"`
+	// LagSeconds is how far a replica is behind its primary, if it is known
+	LagSeconds *float64 `json:"lagSeconds,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// Replicas returns the number of members that are replicas
+func (t *DatabaseTopology) Replicas() int {
+	replicas := 0
+	for _, member := range t.Members {
+		if member.Role == DatabaseRoleReplica {
+			replicas++
+		}
+	}
+	return replicas
+}
+
+// MaxLagSeconds returns how far the replica that is furthest behind is, and false if the lag of no
+// replica is known
+func (t *DatabaseTopology) MaxLagSeconds() (float64, bool) {
+	max, ok := 0.0, false
+	for _, member := range t.Members {
+		if member.Role != DatabaseRoleReplica || member.LagSeconds == nil {
+			continue
+		}
+		if !ok || *member.LagSeconds > max {
+			max, ok = *member.LagSeconds, true
+		}
+	}
+	return max, ok
+}
+
+// databaseTopologyDriver is how the topology of a kind of database is collected
+type databaseTopologyDriver struct {
+	// name is the name of the sql driver
+	name string
+	// address returns the address of the member of uri
+	address func(uri string) string
+	// member returns the role of the member that db is connected to, and its lag if it is a replica
+	member func(ctx context.Context, db *sql.DB) DatabaseMember
+	// replicas returns the replicas that the primary that db is connected to reports
+	replicas func(ctx context.Context, db *sql.DB) ([]DatabaseMember, error)
+}
+
+// collectDatabaseTopology returns the topology of the member at uri, which db is connected to, and the
+// members at the uris of the topology. When there are no uris, the replicas are discovered from the member
+// at uri if it is the primary.
+func collectDatabaseTopology(ctx context.Context, driver databaseTopologyDriver, uri string, db *sql.DB, topology *troubleshootv1beta2.DatabaseTopology) *DatabaseTopology {
+	member := driver.member(ctx, db)
+	member.Address = driver.address(uri)
+	result := &DatabaseTopology{Members: []DatabaseMember{member}}
+
+	for _, memberURI := range topology.URIs {
+		result.Members = append(result.Members, collectDatabaseMember(ctx, driver, memberURI))
+	}
+
+	if len(topology.URIs) == 0 && member.Role == DatabaseRolePrimary {
+		replicas, err := driver.replicas(ctx, db)
+		if err != nil {
+			result.Members[0].Error = fmt.Sprintf("failed to discover replicas: %v", err)
+		}
+		result.Members = append(result.Members, replicas...)
+	}
+
+	return result
+}
+
+func collectDatabaseMember(ctx context.Context, driver databaseTopologyDriver, uri string) DatabaseMember {
+	failed := func(err error) DatabaseMember {
+		return DatabaseMember{Address: driver.address(uri), Role: DatabaseRoleUnknown, Error: err.Error()}
+	}
+
+	db, err := sql.Open(driver.name, uri)
+	if err != nil {
+		return failed(err)
+	}
+	defer db.Close()
+
+	if err := db.PingContext(ctx); err != nil {
+		return failed(err)
+	}
+
+	member := driver.member(ctx, db)
+	member.Address = driver.address(uri)
+	return member
+}
+
+var postgresTopologyDriver = databaseTopologyDriver{
+	name:    "postgres",
+	address: postgresAddress,
+	member: func(ctx context.Context, db *sql.DB) DatabaseMember {
+		member := DatabaseMember{Role: DatabaseRoleUnknown, IsConnected: true}
+
+		var inRecovery bool
+		var lag sql.NullFloat64
+		row := db.QueryRowContext(ctx, `select pg_is_in_recovery(), extract(epoch from now() - pg_last_xact_replay_timestamp())::float8`)
+		if err := row.Scan(&inRecovery, &lag); err != nil {
+			member.Error = err.Error()
+			return member
+		}
+
+		member.Role = DatabaseRolePrimary
+		if inRecovery {
+			member.Role = DatabaseRoleReplica
+			if lag.Valid {
+				member.LagSeconds = &lag.Float64
+			}
+		}
+		return member
+	},
+	replicas: func(ctx context.Context, db *sql.DB) ([]DatabaseMember, error) {
+		// replicas that connect over a unix socket have no address
+		result := runPostgresQuery(ctx, db, `select coalesce(host(client_addr), application_name) as address, extract(epoch from replay_lag)::float8 as lag_seconds from pg_stat_replication`)
+		if result.Error != "" {
+			return nil, errors.New(result.Error)
+		}
+		return discoveredReplicas(result.Rows, func(row map[string]interface{}) string {
+			return fmt.Sprint(row["address"])
+		}, "lag_seconds"), nil
+	},
+}
+
+var mysqlTopologyDriver = databaseTopologyDriver{
+	name:    "mysql",
+	address: mysqlAddress,
+	member: func(ctx context.Context, db *sql.DB) DatabaseMember {
+		member := DatabaseMember{Role: DatabaseRoleUnknown, IsConnected: true}
+
+		// SHOW SLAVE STATUS was renamed in 8.0.22
+		rows, err := queryDatabaseRows(ctx, db, "SHOW REPLICA STATUS", "SHOW SLAVE STATUS")
+		if err != nil {
+			member.Error = err.Error()
+			return member
+		}
+
+		member.Role, member.LagSeconds = mysqlReplicaStatus(rows)
+		return member
+	},
+	replicas: func(ctx context.Context, db *sql.DB) ([]DatabaseMember, error) {
+		rows, err := queryDatabaseRows(ctx, db, "SHOW REPLICAS", "SHOW SLAVE HOSTS")
+		if err != nil {
+			return nil, err
+		}
+		// the lag of the replicas is only known to the replicas
+		return discoveredReplicas(rows, func(row map[string]interface{}) string {
+			host, _ := row["Host"].(string)
+			if host == "" {
+				// replicas only report a host if they have report_host set
+				return fmt.Sprintf("server id %v", row["Server_id"])
+			}
+			return net.JoinHostPort(host, fmt.Sprint(row["Port"]))
+		}, ""), nil
+	},
+}
+
+// mysqlReplicaStatus returns the role and lag of a server from the rows of SHOW REPLICA STATUS, which
+// has a row if the server is a replica. The lag is NULL when replication is not running.
+func mysqlReplicaStatus(rows []map[string]interface{}) (DatabaseRole, *float64) {
+	if len(rows) == 0 {
+		return DatabaseRolePrimary, nil
+	}
+
+	for _, column := range []string{"Seconds_Behind_Source", "Seconds_Behind_Master"} {
+		if lag, ok := databaseFloat(rows[0][column]); ok {
+			return DatabaseRoleReplica, &lag
+		}
+	}
+	return DatabaseRoleReplica, nil
+}
+
+func discoveredReplicas(rows []map[string]interface{}, address func(map[string]interface{}) string, lagColumn string) []DatabaseMember {
+	replicas := []DatabaseMember{}
+	for _, row := range rows {
+		replica := DatabaseMember{
+			Address:    address(row),
+			Role:       DatabaseRoleReplica,
+			Discovered: true,
+		}
+		if lag, ok := databaseFloat(row[lagColumn]); ok {
+			replica.LagSeconds = &lag
+		}
+		replicas = append(replicas, replica)
+	}
+	return replicas
+}
+
+// queryDatabaseRows runs the first of the queries that the server supports
+func queryDatabaseRows(ctx context.Context, db *sql.DB, queries ...string) ([]map[string]interface{}, error) {
+	var err error
+	for _, query := range queries {
+		var rows *sql.Rows
+		rows, err = db.QueryContext(ctx, query)
+		if err != nil {
+			continue
+		}
+		defer rows.Close()
+		return scanDatabaseRows(rows)
+	}
+	return nil, err
+}
+
+// databaseFloat returns a number that a driver scanned, which can be text for drivers that return
+// the text protocol's bytes
+func databaseFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// postgresAddress returns the host and port of a postgres url or key=value connection string
+func postgresAddress(uri string) string {
+	host, port := "localhost", "5432"
+	if u, err := url.Parse(uri); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		if u.Hostname() != "" {
+			host = u.Hostname()
+		}
+		if u.Port() != "" {
+			port = u.Port()
+		}
+		return net.JoinHostPort(host, port)
+	}
+
+	for _, field := range strings.Fields(uri) {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		switch parts[0] {
+		case "host":
+			host = strings.Trim(parts[1], "'")
+		case "port":
+			port = strings.Trim(parts[1], "'")
+		}
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// mysqlAddress returns the address of a mysql dsn
+func mysqlAddress(uri string) string {
+	config, err := mysql.ParseDSN(uri)
+	if err != nil {
+		return "<invalid dsn>"
+	}
+	return config.Addr
+}
@@ -0,0 +1,252 @@
+package collect
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shirou/gopsutil/disk"
+	"golang.org/x/time/rate"
+)
+
+// ErrInsufficientDiskSpace is the error of a write, or of a collector that was not run, while the free
+// disk space was below the reserve of the disk guard
+var ErrInsufficientDiskSpace = errors.New("free disk space is below the reserve")
+
+// diskGuardCheckBytes is how many bytes can be written between checks of the free disk space
+var diskGuardCheckBytes int64 = 4 * 1024 * 1024
+
+// diskGuardPollInterval is how often paused writes check whether disk space has been freed
+var diskGuardPollInterval = time.Second
+
+// diskFreeSpace returns the free space of the filesystem of path, in bytes
+var diskFreeSpace = func(path string) (uint64, error) {
+	usage, err := disk.Usage(path)
+	if err != nil {
+		return 0, err
+	}
+	return usage.Free, nil
+}
+
+// DiskGuardOptions are how collected files are written to disk
+type DiskGuardOptions struct {
+	// ReserveBytes is how much disk space has to be left free. It should leave room for the bundle's
+	// metadata and archive, which are written after collection. 0 is no reserve.
+	ReserveBytes uint64
+	// WriteBytesPerSecond limits how fast collected files are written, with bursts of up to a second of
+	// writes. 0 is no limit.
+	WriteBytesPerSecond int
+	// PauseTimeout is how long writes wait for disk space to be freed once the free space is below the
+	// reserve, before collection is stopped
+	PauseTimeout time.Duration
+}
+
+// DiskGuard keeps collectors from filling the disks that a bundle is written to. Writes are limited by
+// a token bucket, and the free space of the disks is checked before collection and every few megabytes
+// written. When it falls below the reserve, writes pause until space is freed. If it is not freed within
+// the pause timeout, the file being written is removed and the collectors that have not started are not
+// run, so that the bundle has the files that were collected in full.
+type DiskGuard struct {
+	opts    DiskGuardOptions
+	paths   []string
+	limiter *rate.Limiter
+
+	mu        sync.Mutex
+	unchecked int64
+	exhausted bool
+}
+
+// NewDiskGuard returns a guard for the disks of paths, or nil if opts neither reserve space nor limit
+// writes. Paths that do not exist yet are checked on the disk of their nearest existing parent.
+func NewDiskGuard(opts DiskGuardOptions, paths ...string) *DiskGuard {
+	if opts.ReserveBytes == 0 && opts.WriteBytesPerSecond <= 0 {
+		return nil
+	}
+
+	g := &DiskGuard{
+		opts:  opts,
+		paths: paths,
+	}
+	if opts.WriteBytesPerSecond > 0 {
+		g.limiter = rate.NewLimiter(rate.Limit(opts.WriteBytesPerSecond), opts.WriteBytesPerSecond)
+	}
+	return g
+}
+
+// Check returns ErrInsufficientDiskSpace if the free space of any of the disks is below the reserve
+func (g *DiskGuard) Check() error {
+	if g == nil || g.opts.ReserveBytes == 0 {
+		return nil
+	}
+
+	for _, path := range g.paths {
+		free, err := diskFreeSpace(existingParent(path))
+		if err != nil {
+			// a disk whose free space can't be read is not guarded, rather than failing collection
+			continue
+		}
+		if free < g.opts.ReserveBytes {
+			return errors.Wrapf(ErrInsufficientDiskSpace, "%d bytes free on the disk of %s", free, path)
+		}
+	}
+	return nil
+}
+
+// Exhausted returns whether collection was stopped as disk space was not freed in time
+func (g *DiskGuard) Exhausted() bool {
+	if g == nil {
+		return false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.exhausted
+}
+
+// waitForSpace pauses until the free space is above the reserve, for up to the pause timeout. It must be
+// called with mu held, so that the other writes pause as well.
+func (g *DiskGuard) waitForSpace() error {
+	if g.exhausted {
+		return ErrInsufficientDiskSpace
+	}
+
+	err := g.Check()
+	deadline := time.Now().Add(g.opts.PauseTimeout)
+	for err != nil && time.Now().Before(deadline) {
+		time.Sleep(diskGuardPollInterval)
+		err = g.Check()
+	}
+	if err != nil {
+		g.exhausted = true
+	}
+	return err
+}
+
+// BeforeRun returns ErrInsufficientDiskSpace if a collector should not be run, as the free disk space is
+// below the reserve and was not freed within the pause timeout
+func (g *DiskGuard) BeforeRun() error {
+	if g == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.unchecked = 0
+	return g.waitForSpace()
+}
+
+// beforeWrite waits for the limiter to allow n bytes to be written, and checks the free space once
+// enough bytes have been written since it was last checked
+func (g *DiskGuard) beforeWrite(n int) error {
+	if g == nil || n == 0 {
+		return nil
+	}
+
+	if g.limiter != nil {
+		for remaining := n; remaining > 0; {
+			chunk := remaining
+			if chunk > g.limiter.Burst() {
+				chunk = g.limiter.Burst()
+			}
+			if err := g.limiter.WaitN(context.Background(), chunk); err != nil {
+				return errors.Wrap(err, "failed to wait for write limit")
+			}
+			remaining -= chunk
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.exhausted {
+		return ErrInsufficientDiskSpace
+	}
+	g.unchecked += int64(n)
+	if g.unchecked < diskGuardCheckBytes {
+		return nil
+	}
+	g.unchecked = 0
+	return g.waitForSpace()
+}
+
+// diskGuards are the guards of the bundle directories that collectors are writing to, by bundle path
+var diskGuards sync.Map
+
+// GuardWrites guards the files that collectors write to bundlePath until the returned function is called.
+// It should be called once collection has finished, so that the bundle's metadata can be written to the
+// reserve. Each collection has its own bundle path, so collections that run at the same time have their
+// own guards.
+func (g *DiskGuard) GuardWrites(bundlePath string) func() {
+	if g == nil {
+		return func() {}
+	}
+
+	diskGuards.Store(bundlePath, g)
+	return func() {
+		diskGuards.Delete(bundlePath)
+	}
+}
+
+// diskGuardFor returns the guard of the files written to bundlePath, or nil if they are not guarded
+func diskGuardFor(bundlePath string) *DiskGuard {
+	if bundlePath == "" {
+		return nil
+	}
+	if g, ok := diskGuards.Load(bundlePath); ok {
+		return g.(*DiskGuard)
+	}
+	return nil
+}
+
+// guardedWriter writes to a file of a result through the disk guard
+type guardedWriter struct {
+	guard *DiskGuard
+	file  *os.File
+	// err is the error of the write that the guard stopped, if it did
+	err error
+}
+
+func (w *guardedWriter) Write(p []byte) (int, error) {
+	if err := w.guard.beforeWrite(len(p)); err != nil {
+		w.err = err
+		return 0, err
+	}
+	return w.file.Write(p)
+}
+
+func (w *guardedWriter) Close() error {
+	return w.file.Close()
+}
+
+// guardWriter returns f, or a writer to it through the disk guard of bundlePath if there is one
+func guardWriter(bundlePath string, f *os.File) io.WriteCloser {
+	guard := diskGuardFor(bundlePath)
+	if guard == nil {
+		return f
+	}
+	return &guardedWriter{guard: guard, file: f}
+}
+
+// existingParent returns path, or its nearest parent that exists
+func existingParent(path string) string {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
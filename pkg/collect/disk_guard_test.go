@@ -0,0 +1,124 @@
+package collect
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDiskFreeSpace makes the free disk space of every path the value of free, until the test ends
+func fakeDiskFreeSpace(t *testing.T, free *uint64) {
+	origFreeSpace, origCheckBytes, origPollInterval := diskFreeSpace, diskGuardCheckBytes, diskGuardPollInterval
+	diskFreeSpace = func(string) (uint64, error) {
+		return atomic.LoadUint64(free), nil
+	}
+	diskGuardCheckBytes = 10
+	diskGuardPollInterval = time.Millisecond
+	t.Cleanup(func() {
+		diskFreeSpace, diskGuardCheckBytes, diskGuardPollInterval = origFreeSpace, origCheckBytes, origPollInterval
+	})
+}
+
+func TestNewDiskGuard_disabled(t *testing.T) {
+	guard := NewDiskGuard(DiskGuardOptions{}, t.TempDir())
+	assert.Nil(t, guard)
+	assert.NoError(t, guard.Check())
+	assert.False(t, guard.Exhausted())
+	assert.NoError(t, guard.beforeWrite(100))
+}
+
+func TestDiskGuard_Check(t *testing.T) {
+	free := uint64(1000)
+	fakeDiskFreeSpace(t, &free)
+
+	guard := NewDiskGuard(DiskGuardOptions{ReserveBytes: 500}, filepath.Join(t.TempDir(), "not", "created"))
+	assert.NoError(t, guard.Check())
+
+	atomic.StoreUint64(&free, 100)
+	err := guard.Check()
+	assert.True(t, errors.Is(err, ErrInsufficientDiskSpace))
+	assert.False(t, guard.Exhausted())
+}
+
+func TestDiskGuard_pauseUntilSpaceIsFreed(t *testing.T) {
+	free := uint64(100)
+	fakeDiskFreeSpace(t, &free)
+
+	guard := NewDiskGuard(DiskGuardOptions{ReserveBytes: 500, PauseTimeout: time.Minute}, t.TempDir())
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		atomic.StoreUint64(&free, 1000)
+	}()
+
+	assert.NoError(t, guard.beforeWrite(20))
+	assert.False(t, guard.Exhausted())
+}
+
+func TestDiskGuard_writeLimit(t *testing.T) {
+	guard := NewDiskGuard(DiskGuardOptions{WriteBytesPerSecond: 1000}, t.TempDir())
+
+	start := time.Now()
+	// the first second of writes is a burst, and the next 500 bytes wait for half a second
+	require.NoError(t, guard.beforeWrite(1500))
+	assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+}
+
+func TestSaveResult_insufficientDiskSpace(t *testing.T) {
+	free := uint64(1000)
+	fakeDiskFreeSpace(t, &free)
+
+	bundlePath := t.TempDir()
+	release := NewDiskGuard(DiskGuardOptions{ReserveBytes: 500}, bundlePath).GuardWrites(bundlePath)
+	defer release()
+
+	// the files of other bundles are not guarded
+	otherPath := t.TempDir()
+	atomic.StoreUint64(&free, 100)
+	require.NoError(t, NewResult().SaveResult(otherPath, "large.txt", bytes.NewReader(make([]byte, 64*1024))))
+	atomic.StoreUint64(&free, 1000)
+
+	result := NewResult()
+	require.NoError(t, result.SaveResult(bundlePath, "small.txt", strings.NewReader("small")))
+
+	atomic.StoreUint64(&free, 100)
+	err := result.SaveResult(bundlePath, "large.txt", bytes.NewReader(make([]byte, 64*1024)))
+	assert.True(t, errors.Is(err, ErrInsufficientDiskSpace))
+	assert.Equal(t, ErrorCodeInsufficientDiskSpace, ClassifyError(err))
+
+	// the partial file is removed, and the file that was written in full is kept
+	assert.Equal(t, CollectorResult{"small.txt": nil}, result)
+	_, err = os.Stat(filepath.Join(bundlePath, "large.txt"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(bundlePath, "small.txt"))
+	assert.NoError(t, err)
+}
+
+func TestRunCollectors_insufficientDiskSpace(t *testing.T) {
+	free := uint64(100)
+	fakeDiskFreeSpace(t, &free)
+	guard := NewDiskGuard(DiskGuardOptions{ReserveBytes: 500}, t.TempDir())
+
+	var running, maxSeen int32
+	collectors := []Collector{
+		&sleepCollector{name: "first", running: &running, maxSeen: &maxSeen},
+		&sleepCollector{name: "second", running: &running, maxSeen: &maxSeen},
+	}
+
+	runs := RunCollectorsWithOptions(context.Background(), collectors, nil, RunOptions{Concurrency: 2, DiskGuard: guard})
+	require.Len(t, runs, 2)
+	for _, run := range runs {
+		assert.Equal(t, ErrInsufficientDiskSpace, run.Err)
+		assert.Nil(t, run.Result)
+	}
+	assert.Equal(t, int32(0), maxSeen)
+}
@@ -0,0 +1,478 @@
+package collect
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+)
+
+// estimatedLogLineBytes is the size of a line of a log that is assumed for logs limited to a number of lines
+const estimatedLogLineBytes = 200
+
+// estimateSampleSize is how many objects of a resource are listed to estimate the size of each
+const estimateSampleSize = 20
+
+// CollectorEstimate is the projected size of what a collector will collect
+type CollectorEstimate struct {
+	Collector string `json:"collector"`
+	// Estimated is false for the collectors whose size is only known once they have run
+	Estimated bool `json:"estimated"`
+	// Objects is how many objects, container logs or pods the estimate is of
+	Objects int `json:"objects"`
+	// Bytes is the projected size before compression
+	Bytes int64 `json:"bytes"`
+	// Note is what the estimate is of, or what could not be estimated
+	Note string `json:"note,omitempty"`
+}
+
+// BundleEstimator estimates the size of what collectors will collect without running them, from object
+// counts, the sizes of container logs in the kubelets' stats summaries and the sizes of PVCs
+type BundleEstimator struct {
+	client kubernetes.Interface
+	// sinceTime limits the logs that are collected, the same as it does when collecting
+	sinceTime  *time.Time
+	getSummary func(ctx context.Context, nodeName string) ([]byte, error)
+
+	statsLoaded bool
+	statsErrors []string
+	// logBytes are the sizes of the logs of containers, by namespace/pod/container
+	logBytes map[string]uint64
+	// volumeBytes are the used bytes of PVCs, by namespace/name
+	volumeBytes map[string]uint64
+}
+
+func NewBundleEstimator(client kubernetes.Interface, sinceTime *time.Time) *BundleEstimator {
+	return &BundleEstimator{
+		client:    client,
+		sinceTime: sinceTime,
+		getSummary: func(ctx context.Context, nodeName string) ([]byte, error) {
+			return client.CoreV1().RESTClient().Get().
+				AbsPath("/api/v1/nodes", nodeName, "proxy", "stats", "summary").
+				DoRaw(ctx)
+		},
+	}
+}
+
+// estimateStatsSummary is the part of the kubelet's stats summary with the sizes of logs and volumes
+type estimateStatsSummary struct {
+	Pods []struct {
+		PodRef     NodeStatsPodReference `json:"podRef"`
+		Containers []struct {
+			Name string       `json:"name"`
+			Logs *NodeStatsFs `json:"logs,omitempty"`
+		} `json:"containers"`
+		Volumes []NodeStatsVolume `json:"volume,omitempty"`
+	} `json:"pods"`
+}
+
+// Estimate returns the estimate of a collector, with title as the name of the collector
+func (e *BundleEstimator) Estimate(ctx context.Context, title string, collector *troubleshootv1beta2.Collect) CollectorEstimate {
+	estimate := CollectorEstimate{Collector: title, Estimated: true}
+
+	var notes []string
+	switch {
+	case collector.ClusterResources != nil:
+		notes = e.estimateClusterResources(ctx, collector.ClusterResources, &estimate)
+	case collector.Logs != nil:
+		notes = e.estimateLogs(ctx, collector.Logs, &estimate)
+	case collector.Copy != nil:
+		notes = e.estimateCopy(ctx, collector.Copy, &estimate)
+	case collector.Events != nil:
+		notes = e.estimateEvents(ctx, collector.Events, &estimate)
+	case collector.Secret != nil:
+		notes = e.estimateObjects(ctx, "secrets", func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return e.client.CoreV1().Secrets(collector.Secret.Namespace).List(ctx, opts)
+		}, collector.Secret.Name, collector.Secret.Selector, &estimate)
+	case collector.ConfigMap != nil:
+		notes = e.estimateObjects(ctx, "configmaps", func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return e.client.CoreV1().ConfigMaps(collector.ConfigMap.Namespace).List(ctx, opts)
+		}, collector.ConfigMap.Name, collector.ConfigMap.Selector, &estimate)
+	default:
+		estimate.Estimated = false
+		notes = []string{"not estimated, its size is only known once it has run"}
+	}
+
+	estimate.Note = strings.Join(notes, "; ")
+	return estimate
+}
+
+type estimateLister struct {
+	resource   string
+	namespaced bool
+	list       func(ctx context.Context, namespace string, opts metav1.ListOptions) (runtime.Object, error)
+}
+
+// clusterResourcesListers list the resources that make up most of what the clusterResources collector collects
+func (e *BundleEstimator) clusterResourcesListers() []estimateLister {
+	c := e.client
+	return []estimateLister{
+		{"pods", true, func(ctx context.Context, ns string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.CoreV1().Pods(ns).List(ctx, opts)
+		}},
+		{"services", true, func(ctx context.Context, ns string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.CoreV1().Services(ns).List(ctx, opts)
+		}},
+		{"deployments", true, func(ctx context.Context, ns string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.AppsV1().Deployments(ns).List(ctx, opts)
+		}},
+		{"statefulsets", true, func(ctx context.Context, ns string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.AppsV1().StatefulSets(ns).List(ctx, opts)
+		}},
+		{"daemonsets", true, func(ctx context.Context, ns string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.AppsV1().DaemonSets(ns).List(ctx, opts)
+		}},
+		{"replicasets", true, func(ctx context.Context, ns string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.AppsV1().ReplicaSets(ns).List(ctx, opts)
+		}},
+		{"jobs", true, func(ctx context.Context, ns string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.BatchV1().Jobs(ns).List(ctx, opts)
+		}},
+		{"ingresses", true, func(ctx context.Context, ns string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.NetworkingV1().Ingresses(ns).List(ctx, opts)
+		}},
+		{"events", true, func(ctx context.Context, ns string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.CoreV1().Events(ns).List(ctx, opts)
+		}},
+		{"persistentvolumeclaims", true, func(ctx context.Context, ns string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.CoreV1().PersistentVolumeClaims(ns).List(ctx, opts)
+		}},
+		{"nodes", false, func(ctx context.Context, _ string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.CoreV1().Nodes().List(ctx, opts)
+		}},
+		{"persistentvolumes", false, func(ctx context.Context, _ string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.CoreV1().PersistentVolumes().List(ctx, opts)
+		}},
+		{"namespaces", false, func(ctx context.Context, _ string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.CoreV1().Namespaces().List(ctx, opts)
+		}},
+	}
+}
+
+func (e *BundleEstimator) estimateClusterResources(ctx context.Context, collector *troubleshootv1beta2.ClusterResources, estimate *CollectorEstimate) []string {
+	namespaces := collector.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	notes := []string{}
+	for _, lister := range e.clusterResourcesListers() {
+		listNamespaces := namespaces
+		if !lister.namespaced {
+			listNamespaces = []string{metav1.NamespaceAll}
+		}
+		for _, namespace := range listNamespaces {
+			count, size, err := countObjects(ctx, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return lister.list(ctx, namespace, opts)
+			}, metav1.ListOptions{})
+			if err != nil {
+				notes = append(notes, fmt.Sprintf("failed to count %s: %v", lister.resource, err))
+				continue
+			}
+			estimate.Objects += count
+			estimate.Bytes += size
+		}
+	}
+
+	return notes
+}
+
+func (e *BundleEstimator) estimateEvents(ctx context.Context, collector *troubleshootv1beta2.Events, estimate *CollectorEstimate) []string {
+	fieldSelector, err := eventsFieldSelector(collector)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	namespaces := collector.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	notes := []string{}
+	for _, namespace := range namespaces {
+		count, size, err := countObjects(ctx, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return e.client.CoreV1().Events(namespace).List(ctx, opts)
+		}, metav1.ListOptions{FieldSelector: fieldSelector})
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("failed to count events: %v", err))
+			continue
+		}
+		estimate.Objects += count
+		estimate.Bytes += size
+	}
+
+	return notes
+}
+
+func (e *BundleEstimator) estimateObjects(ctx context.Context, resource string, list func(context.Context, metav1.ListOptions) (runtime.Object, error), name string, selector []string, estimate *CollectorEstimate) []string {
+	opts := metav1.ListOptions{LabelSelector: strings.Join(selector, ",")}
+	if name != "" {
+		opts = metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String()}
+	}
+
+	count, size, err := countObjects(ctx, list, opts)
+	if err != nil {
+		return []string{fmt.Sprintf("failed to count %s: %v", resource, err)}
+	}
+	estimate.Objects = count
+	estimate.Bytes = size
+	return nil
+}
+
+func (e *BundleEstimator) estimateLogs(ctx context.Context, collector *troubleshootv1beta2.Logs, estimate *CollectorEstimate) []string {
+	pods, podsErrors := listPodsInSelectors(ctx, e.client, collector.Namespace, collector.Selector)
+	notes := podsErrors
+
+	limits := collector.Limits
+	if e.sinceTime != nil {
+		limits = &troubleshootv1beta2.LogLimits{}
+		if collector.Limits != nil {
+			*limits = *collector.Limits
+		}
+		limits.SinceTime = metav1.NewTime(*e.sinceTime)
+	}
+	limit := estimateLogLimit(limits)
+
+	e.loadStats(ctx)
+
+	missing := 0
+	for _, pod := range pods {
+		containers := collector.ContainerNames
+		if len(containers) == 0 {
+			for _, container := range pod.Spec.Containers {
+				containers = append(containers, container.Name)
+			}
+			for _, container := range pod.Spec.InitContainers {
+				containers = append(containers, container.Name)
+			}
+		}
+
+		for _, container := range containers {
+			estimate.Objects++
+			size, ok := e.logBytes[path.Join(pod.Namespace, pod.Name, container)]
+			if !ok {
+				missing++
+				continue
+			}
+			if limit > 0 && int64(size) > limit {
+				estimate.Bytes += limit
+			} else {
+				estimate.Bytes += int64(size)
+			}
+		}
+	}
+
+	if missing > 0 {
+		notes = append(notes, fmt.Sprintf("the log sizes of %d containers are not in the kubelets' stats", missing))
+	}
+	return append(notes, e.statsErrors...)
+}
+
+// estimateLogLimit returns the most of a log that is collected with limits, or 0 if the whole log is
+// collected. Logs limited by lines are assumed to have lines of estimatedLogLineBytes.
+func estimateLogLimit(limits *troubleshootv1beta2.LogLimits) int64 {
+	if limits == nil {
+		return defaultLogMaxLines * estimatedLogLineBytes
+	}
+	if (!limits.SinceTime.IsZero() || limits.MaxAge != "") && !truncatesLogs(limits) {
+		return 0
+	}
+
+	maxLines := limits.MaxLines
+	if maxLines == 0 && limits.MaxBytes == 0 && limits.SinceTime.IsZero() && limits.MaxAge == "" {
+		maxLines = defaultLogMaxLines
+	}
+	limit := maxLines * estimatedLogLineBytes
+	if limits.MaxBytes > 0 && (limit == 0 || limits.MaxBytes < limit) {
+		limit = limits.MaxBytes
+	}
+	return limit
+}
+
+func (e *BundleEstimator) estimateCopy(ctx context.Context, collector *troubleshootv1beta2.Copy, estimate *CollectorEstimate) []string {
+	pods, podsErrors := listPodsInSelectors(ctx, e.client, collector.Namespace, collector.Selector)
+	notes := podsErrors
+
+	e.loadStats(ctx)
+
+	volumes := 0
+	for _, pod := range pods {
+		estimate.Objects++
+		podBytes := int64(0)
+		for _, claim := range copiedClaims(pod, collector.ContainerName, collector.ContainerPath) {
+			volumes++
+			if used, ok := e.volumeBytes[path.Join(pod.Namespace, claim)]; ok {
+				podBytes += int64(used)
+				continue
+			}
+
+			pvc, err := e.client.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(ctx, claim, metav1.GetOptions{})
+			if err != nil {
+				notes = append(notes, fmt.Sprintf("failed to get persistent volume claim %s/%s: %v", pod.Namespace, claim, err))
+				continue
+			}
+			if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+				podBytes += capacity.Value()
+			}
+		}
+		if collector.MaxBytes > 0 && podBytes > collector.MaxBytes {
+			podBytes = collector.MaxBytes
+		}
+		estimate.Bytes += podBytes
+	}
+
+	if volumes == 0 {
+		notes = append(notes, "only files in persistent volume claims are estimated")
+	} else {
+		notes = append(notes, fmt.Sprintf("the usage of %d persistent volume claims, files outside them are not estimated", volumes))
+	}
+	return notes
+}
+
+// copiedClaims returns the persistent volume claims that hold files under containerPath in the container of
+// the pod, the first container if containerName is not set
+func copiedClaims(pod corev1.Pod, containerName string, containerPath string) []string {
+	var container *corev1.Container
+	for i := range pod.Spec.Containers {
+		if containerName == "" || pod.Spec.Containers[i].Name == containerName {
+			container = &pod.Spec.Containers[i]
+			break
+		}
+	}
+	if container == nil {
+		return nil
+	}
+
+	claims := []string{}
+	for _, mount := range container.VolumeMounts {
+		if !pathsOverlap(mount.MountPath, containerPath) {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.Name == mount.Name && volume.PersistentVolumeClaim != nil {
+				claims = append(claims, volume.PersistentVolumeClaim.ClaimName)
+			}
+		}
+	}
+	return claims
+}
+
+// pathsOverlap returns true if one of the paths is in the other
+func pathsOverlap(a string, b string) bool {
+	a, b = path.Clean(a), path.Clean(b)
+	within := func(p, dir string) bool {
+		return p == dir || dir == "/" || strings.HasPrefix(p, dir+"/")
+	}
+	return within(a, b) || within(b, a)
+}
+
+// loadStats gets the stats summaries of the nodes once, for the sizes of logs and volumes
+func (e *BundleEstimator) loadStats(ctx context.Context) {
+	if e.statsLoaded {
+		return
+	}
+	e.statsLoaded = true
+	e.logBytes = map[string]uint64{}
+	e.volumeBytes = map[string]uint64{}
+
+	nodes, err := e.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		e.statsErrors = []string{fmt.Sprintf("failed to list nodes: %v", err)}
+		return
+	}
+
+	failed := 0
+	var firstErr error
+	for _, node := range nodes.Items {
+		b, err := e.getSummary(ctx, node.Name)
+		if err == nil {
+			err = e.addStats(b)
+		}
+		if err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = errors.Wrapf(err, "node %s", node.Name)
+			}
+		}
+	}
+	if failed > 0 {
+		e.statsErrors = []string{fmt.Sprintf("failed to get the stats of %d nodes: %v", failed, firstErr)}
+	}
+}
+
+func (e *BundleEstimator) addStats(b []byte) error {
+	summary := estimateStatsSummary{}
+	if err := json.Unmarshal(b, &summary); err != nil {
+		return errors.Wrap(err, "failed to unmarshal stats summary")
+	}
+
+	for _, pod := range summary.Pods {
+		for _, container := range pod.Containers {
+			if container.Logs != nil && container.Logs.UsedBytes != nil {
+				e.logBytes[path.Join(pod.PodRef.Namespace, pod.PodRef.Name, container.Name)] = *container.Logs.UsedBytes
+			}
+		}
+		for _, volume := range pod.Volumes {
+			if volume.PVCRef != nil && volume.UsedBytes != nil {
+				e.volumeBytes[path.Join(volume.PVCRef.Namespace, volume.PVCRef.Name)] = *volume.UsedBytes
+			}
+		}
+	}
+	return nil
+}
+
+// countObjects returns how many objects list lists, and their projected size from a sample of them. The
+// count is from the remaining item count of the first page of the list when the API server has one.
+func countObjects(ctx context.Context, list func(context.Context, metav1.ListOptions) (runtime.Object, error), opts metav1.ListOptions) (int, int64, error) {
+	opts.Limit = estimateSampleSize
+	obj, err := list(ctx, opts)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	listMeta, err := meta.ListAccessor(obj)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to get list metadata")
+	}
+	items, err := meta.ExtractList(obj)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to extract list")
+	}
+
+	count := len(items)
+	if remaining := listMeta.GetRemainingItemCount(); remaining != nil {
+		count += int(*remaining)
+	} else if listMeta.GetContinue() != "" {
+		// the API server can't tell how many more there are, e.g. with a selector
+		opts.Limit = 0
+		all, err := list(ctx, opts)
+		if err != nil {
+			return 0, 0, err
+		}
+		count = meta.LenList(all)
+	}
+	if len(items) == 0 {
+		return count, 0, nil
+	}
+
+	var sampled int64
+	for _, item := range items {
+		b, err := json.MarshalIndent(item, "", "  ")
+		if err != nil {
+			return 0, 0, errors.Wrap(err, "failed to marshal object")
+		}
+		sampled += int64(len(b))
+	}
+	return count, sampled * int64(count) / int64(len(items)), nil
+}
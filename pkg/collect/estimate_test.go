@@ -0,0 +1,262 @@
+package collect
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestBundleEstimator_logs(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-0", Labels: map[string]string{"app": "web"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}, {Name: "proxy"}}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-1", Labels: map[string]string{"app": "web"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}, {Name: "proxy"}}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db-0", Labels: map[string]string{"app": "db"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "db"}}},
+		},
+	)
+
+	estimator := NewBundleEstimator(client, nil)
+	estimator.getSummary = func(ctx context.Context, nodeName string) ([]byte, error) {
+		if nodeName == "node-2" {
+			return nil, errors.New("the server is currently unable to handle the request")
+		}
+		return []byte(`{"pods": [
+			{"podRef": {"namespace": "default", "name": "web-0"}, "containers": [
+				{"name": "app", "logs": {"usedBytes": 5000000}},
+				{"name": "proxy", "logs": {"usedBytes": 1000}}
+			]},
+			{"podRef": {"namespace": "default", "name": "web-1"}, "containers": [
+				{"name": "app", "logs": {"usedBytes": 3000}}
+			]}
+		]}`), nil
+	}
+
+	tests := []struct {
+		name   string
+		logs   *troubleshootv1beta2.Logs
+		expect CollectorEstimate
+	}{
+		{
+			name: "default limits",
+			logs: &troubleshootv1beta2.Logs{Namespace: "default", Selector: []string{"app=web"}},
+			expect: CollectorEstimate{
+				Collector: "logs/web",
+				Estimated: true,
+				Objects:   4,
+				Bytes:     defaultLogMaxLines*estimatedLogLineBytes + 1000 + 3000,
+				Note:      "the log sizes of 1 containers are not in the kubelets' stats; failed to get the stats of 1 nodes: node node-2: the server is currently unable to handle the request",
+			},
+		},
+		{
+			name: "max bytes",
+			logs: &troubleshootv1beta2.Logs{
+				Namespace:      "default",
+				Selector:       []string{"app=web"},
+				ContainerNames: []string{"app"},
+				Limits:         &troubleshootv1beta2.LogLimits{MaxBytes: 2000},
+			},
+			expect: CollectorEstimate{
+				Collector: "logs/web",
+				Estimated: true,
+				Objects:   2,
+				Bytes:     2000 + 2000,
+				Note:      "failed to get the stats of 1 nodes: node node-2: the server is currently unable to handle the request",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := estimator.Estimate(context.Background(), "logs/web", &troubleshootv1beta2.Collect{Logs: tt.logs})
+			assert.Equal(t, tt.expect, actual)
+		})
+	}
+}
+
+func TestBundleEstimator_copy(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db-0", Labels: map[string]string{"app": "db"}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "db",
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "data", MountPath: "/var/lib/postgresql"},
+						{Name: "wal", MountPath: "/var/lib/postgresql/wal"},
+						{Name: "config", MountPath: "/etc/postgresql"},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-db-0"}}},
+				{Name: "wal", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "wal-db-0"}}},
+				{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{}}},
+			},
+		},
+	}
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		pod,
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "wal-db-0"},
+			Status: corev1.PersistentVolumeClaimStatus{
+				Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		},
+	)
+
+	estimator := NewBundleEstimator(client, nil)
+	estimator.getSummary = func(ctx context.Context, nodeName string) ([]byte, error) {
+		return []byte(`{"pods": [{"podRef": {"namespace": "default", "name": "db-0"}, "volume": [
+			{"name": "data", "usedBytes": 4096, "pvcRef": {"namespace": "default", "name": "data-db-0"}}
+		]}]}`), nil
+	}
+
+	actual := estimator.Estimate(context.Background(), "copy/db", &troubleshootv1beta2.Collect{Copy: &troubleshootv1beta2.Copy{
+		Namespace:     "default",
+		Selector:      []string{"app=db"},
+		ContainerPath: "/var/lib/postgresql/",
+	}})
+	assert.Equal(t, CollectorEstimate{
+		Collector: "copy/db",
+		Estimated: true,
+		Objects:   1,
+		Bytes:     4096 + 1024*1024*1024,
+		Note:      "the usage of 2 persistent volume claims, files outside them are not estimated",
+	}, actual)
+
+	actual = estimator.Estimate(context.Background(), "copy/db", &troubleshootv1beta2.Collect{Copy: &troubleshootv1beta2.Copy{
+		Namespace:     "default",
+		Selector:      []string{"app=db"},
+		ContainerPath: "/var/lib/postgresql/",
+		MaxBytes:      1024,
+	}})
+	assert.Equal(t, int64(1024), actual.Bytes)
+}
+
+func TestBundleEstimator_objects(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app"}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-0"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "api-0"},
+		},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "tls", Labels: map[string]string{"app": "api"}}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "token"}},
+	)
+	estimator := NewBundleEstimator(client, nil)
+
+	// the pods and namespaces of all namespaces
+	actual := estimator.Estimate(context.Background(), "cluster-resources", &troubleshootv1beta2.Collect{ClusterResources: &troubleshootv1beta2.ClusterResources{}})
+	assert.True(t, actual.Estimated)
+	assert.Equal(t, 4, actual.Objects)
+	assert.Greater(t, actual.Bytes, int64(0))
+	assert.Empty(t, actual.Note)
+
+	// the namespaces and the pods of one
+	actual = estimator.Estimate(context.Background(), "cluster-resources", &troubleshootv1beta2.Collect{ClusterResources: &troubleshootv1beta2.ClusterResources{Namespaces: []string{"app"}}})
+	assert.Equal(t, 3, actual.Objects)
+
+	actual = estimator.Estimate(context.Background(), "secret", &troubleshootv1beta2.Collect{Secret: &troubleshootv1beta2.Secret{Namespace: "app", Selector: []string{"app=api"}}})
+	assert.Equal(t, 1, actual.Objects)
+
+	actual = estimator.Estimate(context.Background(), "run-pod", &troubleshootv1beta2.Collect{RunPod: &troubleshootv1beta2.RunPod{}})
+	assert.Equal(t, CollectorEstimate{
+		Collector: "run-pod",
+		Note:      "not estimated, its size is only known once it has run",
+	}, actual)
+}
+
+func Test_estimateLogLimit(t *testing.T) {
+	sinceTime := metav1.NewTime(time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name   string
+		limits *troubleshootv1beta2.LogLimits
+		want   int64
+	}{
+		{
+			name: "no limits",
+			want: defaultLogMaxLines * estimatedLogLineBytes,
+		},
+		{
+			name:   "max lines",
+			limits: &troubleshootv1beta2.LogLimits{MaxLines: 100},
+			want:   100 * estimatedLogLineBytes,
+		},
+		{
+			name:   "max bytes under max lines",
+			limits: &troubleshootv1beta2.LogLimits{MaxLines: 100, MaxBytes: 1000},
+			want:   1000,
+		},
+		{
+			name:   "max bytes",
+			limits: &troubleshootv1beta2.LogLimits{MaxBytes: 1 << 30},
+			want:   1 << 30,
+		},
+		{
+			name:   "since time",
+			limits: &troubleshootv1beta2.LogLimits{SinceTime: sinceTime, MaxLines: 100},
+			want:   0,
+		},
+		{
+			name:   "since time truncated",
+			limits: &troubleshootv1beta2.LogLimits{SinceTime: sinceTime, MaxBytes: 1000},
+			want:   1000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, estimateLogLimit(tt.limits))
+		})
+	}
+}
+
+func Test_pathsOverlap(t *testing.T) {
+	assert.True(t, pathsOverlap("/var/lib/data", "/var/lib/data/"))
+	assert.True(t, pathsOverlap("/var/lib", "/var/lib/data/db"))
+	assert.True(t, pathsOverlap("/var/lib/data/db", "/var/lib"))
+	assert.True(t, pathsOverlap("/", "/etc"))
+	assert.False(t, pathsOverlap("/var/lib/data", "/var/lib/database"))
+	assert.False(t, pathsOverlap("/etc", "/var"))
+}
+
+func Test_countObjects(t *testing.T) {
+	remaining := int64(98)
+	list := func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		assert.Equal(t, int64(estimateSampleSize), opts.Limit)
+		return &corev1.ConfigMapList{
+			ListMeta: metav1.ListMeta{Continue: "next", RemainingItemCount: &remaining},
+			Items: []corev1.ConfigMap{
+				{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+			},
+		}, nil
+	}
+
+	count, size, err := countObjects(context.Background(), list, metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 100, count)
+	assert.Greater(t, size, int64(0))
+	assert.Equal(t, int64(0), size%50)
+}
@@ -0,0 +1,181 @@
+package collect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/k8sutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// EventsTimelineFilename is the events of all the namespaces, oldest first, for reading
+const EventsTimelineFilename = "timeline.txt"
+
+type CollectEvents struct {
+	Collector    *troubleshootv1beta2.Events
+	BundlePath   string
+	Namespace    string
+	ClientConfig *rest.Config
+	Client       kubernetes.Interface
+	Context      context.Context
+	SinceTime    *time.Time
+	RBACErrors
+}
+
+func (c *CollectEvents) Title() string {
+	return getCollectorName(c)
+}
+
+func (c *CollectEvents) IsExcluded() (bool, error) {
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
+}
+
+func (c *CollectEvents) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
+	ctx := contextOrBackground(c.Context)
+	output := NewResult()
+
+	dir := "events"
+	if c.Collector.CollectorName != "" {
+		dir = path.Join(dir, c.Collector.CollectorName)
+	}
+
+	fieldSelector, err := eventsFieldSelector(c.Collector)
+	if err != nil {
+		return nil, err
+	}
+
+	// events of all namespaces are listed at once, and saved by namespace like the others
+	namespaces := c.Collector.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	eventsByNamespace := map[string][]corev1.Event{}
+	errorList := map[string]string{}
+	for _, namespace := range namespaces {
+		events := &corev1.EventList{}
+		err := k8sutil.ListAll(ctx, events, func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.FieldSelector = fieldSelector
+			return c.Client.CoreV1().Events(namespace).List(ctx, opts)
+		})
+		if err != nil {
+			if namespace == metav1.NamespaceAll {
+				namespace = "all namespaces"
+			}
+			errorList[namespace] = err.Error()
+			continue
+		}
+
+		for _, event := range eventsSince(events.Items, c.SinceTime) {
+			eventsByNamespace[event.Namespace] = append(eventsByNamespace[event.Namespace], event)
+		}
+	}
+
+	all := []corev1.Event{}
+	for namespace, events := range eventsByNamespace {
+		b, err := marshalEventList(events)
+		if err != nil {
+			errorList[namespace] = err.Error()
+			continue
+		}
+		output.SaveResult(c.BundlePath, path.Join(dir, namespace+".json"), bytes.NewBuffer(b))
+		all = append(all, events...)
+	}
+
+	output.SaveResult(c.BundlePath, path.Join(dir, EventsTimelineFilename), bytes.NewBufferString(eventsTimeline(all)))
+	output.SaveResult(c.BundlePath, path.Join(dir, "errors.json"), marshalErrors(errorList))
+
+	return output, nil
+}
+
+// eventsFieldSelector returns the field selector of the events that the collector selects
+func eventsFieldSelector(collector *troubleshootv1beta2.Events) (string, error) {
+	selectors := []string{}
+	if collector.FieldSelector != "" {
+		selector, err := fields.ParseSelector(collector.FieldSelector)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to parse field selector %q", collector.FieldSelector)
+		}
+		selectors = append(selectors, selector.String())
+	}
+	if collector.InvolvedObject != nil {
+		if collector.InvolvedObject.Kind != "" {
+			selectors = append(selectors, fields.OneTermEqualSelector("involvedObject.kind", collector.InvolvedObject.Kind).String())
+		}
+		if collector.InvolvedObject.Name != "" {
+			selectors = append(selectors, fields.OneTermEqualSelector("involvedObject.name", collector.InvolvedObject.Name).String())
+		}
+	}
+	return strings.Join(selectors, ","), nil
+}
+
+// marshalEventList marshals events as an EventList, the same as the events of cluster resources
+func marshalEventList(events []corev1.Event) ([]byte, error) {
+	list := &corev1.EventList{Items: events}
+
+	gvk, err := apiutil.GVKForObject(list, scheme.Scheme)
+	if err == nil {
+		list.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+	for i := range list.Items {
+		gvk, err := apiutil.GVKForObject(&list.Items[i], scheme.Scheme)
+		if err == nil {
+			list.Items[i].GetObjectKind().SetGroupVersionKind(gvk)
+		}
+	}
+
+	return json.MarshalIndent(list, "", "  ")
+}
+
+// eventsTimeline returns events as a table, oldest first, like kubectl get events sorts them with
+// --sort-by=.lastTimestamp. Events without a time are first.
+func eventsTimeline(events []corev1.Event) string {
+	sorted := make([]corev1.Event, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ti, tj := eventTime(sorted[i]), eventTime(sorted[j])
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		if sorted[i].Namespace != sorted[j].Namespace {
+			return sorted[i].Namespace < sorted[j].Namespace
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tNAMESPACE\tTYPE\tREASON\tOBJECT\tCOUNT\tMESSAGE")
+	for _, event := range sorted {
+		t := "<unknown>"
+		if eventTime := eventTime(event); !eventTime.IsZero() {
+			t = eventTime.UTC().Format(time.RFC3339)
+		}
+		count := event.Count
+		if count == 0 {
+			count = 1
+		}
+		object := strings.ToLower(event.InvolvedObject.Kind) + "/" + event.InvolvedObject.Name
+		message := strings.Join(strings.Fields(event.Message), " ")
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n", t, event.Namespace, event.Type, event.Reason, object, count, message)
+	}
+	w.Flush()
+
+	return b.String()
+}
@@ -0,0 +1,160 @@
+package collect
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCollectEvents(t *testing.T) {
+	start := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	client := fake.NewSimpleClientset(
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web.2", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web", Namespace: "default"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting\nfailed container",
+			Count:          3,
+			LastTimestamp:  metav1.NewTime(start.Add(2 * time.Minute)),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "dns.1", Namespace: "kube-system"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web", Namespace: "kube-system"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "Unhealthy",
+			Message:        "Back-off restarting\nfailed container",
+			Count:          3,
+			LastTimestamp:  metav1.NewTime(start.Add(time.Minute)),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web.1", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web", Namespace: "default"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "Pulled",
+			Message:        "Back-off restarting\nfailed container",
+			Count:          3,
+			LastTimestamp:  metav1.NewTime(start.Add(-time.Hour)),
+		},
+	)
+
+	sinceTime := start
+	collector := &CollectEvents{
+		Collector: &troubleshootv1beta2.Events{},
+		Client:    client,
+		SinceTime: &sinceTime,
+	}
+	result, err := collector.Collect(nil)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"events/default.json", "events/kube-system.json", "events/timeline.txt"}, resultFiles(result))
+
+	list := corev1.EventList{}
+	require.NoError(t, json.Unmarshal(result["events/default.json"], &list))
+	assert.Equal(t, "EventList", list.Kind)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "web.2", list.Items[0].Name)
+	assert.Equal(t, "Event", list.Items[0].Kind)
+
+	assert.Equal(t, `TIME                  NAMESPACE    TYPE     REASON     OBJECT   COUNT  MESSAGE
+2022-11-01T12:01:00Z  kube-system  Warning  Unhealthy  pod/web  3      Back-off restarting failed container
+2022-11-01T12:02:00Z  default      Warning  BackOff    pod/web  3      Back-off restarting failed container
+`, string(result["events/timeline.txt"]))
+}
+
+func TestCollectEvents_namespaces(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web.1", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web", Namespace: "default"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting\nfailed container",
+			Count:          3,
+			LastTimestamp:  metav1.NewTime(time.Now()),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "dns.1", Namespace: "kube-system"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web", Namespace: "kube-system"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "Unhealthy",
+			Message:        "Back-off restarting\nfailed container",
+			Count:          3,
+			LastTimestamp:  metav1.NewTime(time.Now()),
+		},
+	)
+
+	collector := &CollectEvents{
+		Collector: &troubleshootv1beta2.Events{
+			CollectorMeta: troubleshootv1beta2.CollectorMeta{CollectorName: "app"},
+			Namespaces:    []string{"default"},
+		},
+		Client: client,
+	}
+	result, err := collector.Collect(nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"events/app/default.json", "events/app/timeline.txt"}, resultFiles(result))
+
+	collector.Collector.FieldSelector = "type"
+	_, err = collector.Collect(nil)
+	assert.Error(t, err)
+}
+
+func Test_eventsFieldSelector(t *testing.T) {
+	tests := []struct {
+		name      string
+		collector *troubleshootv1beta2.Events
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "none",
+			collector: &troubleshootv1beta2.Events{},
+			want:      "",
+		},
+		{
+			name:      "field selector",
+			collector: &troubleshootv1beta2.Events{FieldSelector: "type=Warning,reason!=Pulled"},
+			want:      "reason!=Pulled,type=Warning",
+		},
+		{
+			name: "involved object",
+			collector: &troubleshootv1beta2.Events{
+				FieldSelector:  "type=Warning",
+				InvolvedObject: &troubleshootv1beta2.EventInvolvedObject{Kind: "Pod", Name: "web-0"},
+			},
+			want: "type=Warning,involvedObject.kind=Pod,involvedObject.name=web-0",
+		},
+		{
+			name:      "invalid",
+			collector: &troubleshootv1beta2.Events{FieldSelector: "type"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := eventsFieldSelector(tt.collector)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func resultFiles(result CollectorResult) []string {
+	files := []string{}
+	for k := range result {
+		files = append(files, k)
+	}
+	return files
+}
@@ -3,6 +3,7 @@ package collect
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
 )
 
 type CollectExec struct {
@@ -31,12 +33,12 @@ func (c *CollectExec) Title() string {
 }
 
 func (c *CollectExec) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 func (c *CollectExec) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
 	if c.Collector.Timeout == "" {
-		return execWithoutTimeout(c.ClientConfig, c.BundlePath, c.Collector)
+		return execWithoutTimeout(c.ClientConfig, c.Client, c.BundlePath, c.Collector)
 	}
 
 	timeout, err := time.ParseDuration(c.Collector.Timeout)
@@ -48,7 +50,7 @@ func (c *CollectExec) Collect(progressChan chan<- interface{}) (CollectorResult,
 	resultCh := make(chan CollectorResult, 1)
 
 	go func() {
-		b, err := execWithoutTimeout(c.ClientConfig, c.BundlePath, c.Collector)
+		b, err := execWithoutTimeout(c.ClientConfig, c.Client, c.BundlePath, c.Collector)
 		if err != nil {
 			errCh <- err
 		} else {
@@ -66,8 +68,8 @@ func (c *CollectExec) Collect(progressChan chan<- interface{}) (CollectorResult,
 	}
 }
 
-func execWithoutTimeout(clientConfig *rest.Config, bundlePath string, execCollector *troubleshootv1beta2.Exec) (CollectorResult, error) {
-	client, err := kubernetes.NewForConfig(clientConfig)
+func execWithoutTimeout(clientConfig *rest.Config, client kubernetes.Interface, bundlePath string, execCollector *troubleshootv1beta2.Exec) (CollectorResult, error) {
+	client, err := kubernetesClientOrDefault(client, clientConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -83,18 +85,25 @@ func execWithoutTimeout(clientConfig *rest.Config, bundlePath string, execCollec
 
 	if len(pods) > 0 {
 		for _, pod := range pods {
-			stdout, stderr, execErrors := getExecOutputs(clientConfig, client, pod, execCollector)
+			podOutput := getExecOutputs(clientConfig, client, pod, execCollector)
 
 			path := filepath.Join(execCollector.Name, pod.Namespace, pod.Name)
-			if len(stdout) > 0 {
-				output.SaveResult(bundlePath, filepath.Join(path, execCollector.CollectorName+"-stdout.txt"), bytes.NewBuffer(stdout))
+			if len(podOutput.stdout) > 0 {
+				output.SaveResult(bundlePath, filepath.Join(path, execCollector.CollectorName+"-stdout.txt"), bytes.NewBuffer(podOutput.stdout))
 			}
-			if len(stderr) > 0 {
-				output.SaveResult(bundlePath, filepath.Join(path, execCollector.CollectorName+"-stderr.txt"), bytes.NewBuffer(stderr))
+			if len(podOutput.stderr) > 0 {
+				output.SaveResult(bundlePath, filepath.Join(path, execCollector.CollectorName+"-stderr.txt"), bytes.NewBuffer(podOutput.stderr))
 			}
 
-			if len(execErrors) > 0 {
-				output.SaveResult(bundlePath, filepath.Join(path, execCollector.CollectorName+"-errors.json"), marshalErrors(execErrors))
+			b, err := json.MarshalIndent(podOutput.metadata, "", "  ")
+			if err != nil {
+				podOutput.errors = append(podOutput.errors, errors.Wrap(err, "failed to marshal exec metadata").Error())
+			} else {
+				output.SaveResult(bundlePath, filepath.Join(path, execCollector.CollectorName+"-"+ExecMetadataFilename), bytes.NewBuffer(b))
+			}
+
+			if len(podOutput.errors) > 0 {
+				output.SaveResult(bundlePath, filepath.Join(path, execCollector.CollectorName+"-errors.json"), marshalErrors(podOutput.errors))
 				continue
 			}
 		}
@@ -103,31 +112,68 @@ func execWithoutTimeout(clientConfig *rest.Config, bundlePath string, execCollec
 	return output, nil
 }
 
-func getExecOutputs(clientConfig *rest.Config, client *kubernetes.Clientset, pod corev1.Pod, execCollector *troubleshootv1beta2.Exec) ([]byte, []byte, []string) {
+// ExecMetadataFilename is the suffix of the file with the ExecMetadata of the command in each pod
+const ExecMetadataFilename = "metadata.json"
+
+// ExecMetadata is how the command of an exec collector ran in a pod, so that analyzers can use its exit
+// code instead of its output
+type ExecMetadata struct {
+	Command   []string `json:"command"`
+	Args      []string `json:"args,omitempty"`
+	Container string   `json:"container"`
+	// ExitCode is not set if the command could not be run, or did not finish
+	ExitCode  *int      `json:"exitCode,omitempty"`
+	StartTime time.Time `json:"startTime"`
+	// Duration is how long the command ran for, such as 1.5s
+	Duration string `json:"duration"`
+}
+
+type execOutput struct {
+	stdout   []byte
+	stderr   []byte
+	metadata ExecMetadata
+	errors   []string
+}
+
+func getExecOutputs(clientConfig *rest.Config, client kubernetes.Interface, pod corev1.Pod, execCollector *troubleshootv1beta2.Exec) (result execOutput) {
 	container := pod.Spec.Containers[0].Name
 	if execCollector.ContainerName != "" {
 		container = execCollector.ContainerName
 	}
 
+	result = execOutput{
+		metadata: ExecMetadata{
+			Command:   execCollector.Command,
+			Args:      execCollector.Args,
+			Container: container,
+			StartTime: time.Now(),
+		},
+	}
+	defer func() {
+		result.metadata.Duration = time.Since(result.metadata.StartTime).String()
+	}()
+
 	req := client.CoreV1().RESTClient().Post().Resource("pods").Name(pod.Name).Namespace(pod.Namespace).SubResource("exec")
 	scheme := runtime.NewScheme()
 	if err := corev1.AddToScheme(scheme); err != nil {
-		return nil, nil, []string{err.Error()}
+		result.errors = []string{err.Error()}
+		return result
 	}
 
 	parameterCodec := runtime.NewParameterCodec(scheme)
 	req.VersionedParams(&corev1.PodExecOptions{
 		Command:   append(execCollector.Command, execCollector.Args...),
 		Container: container,
-		Stdin:     true,
-		Stdout:    false,
+		Stdin:     false,
+		Stdout:    true,
 		Stderr:    true,
 		TTY:       false,
 	}, parameterCodec)
 
 	exec, err := remotecommand.NewSPDYExecutor(clientConfig, "POST", req.URL())
 	if err != nil {
-		return nil, nil, []string{err.Error()}
+		result.errors = []string{err.Error()}
+		return result
 	}
 
 	stdout := new(bytes.Buffer)
@@ -139,12 +185,32 @@ func getExecOutputs(clientConfig *rest.Config, client *kubernetes.Clientset, pod
 		Stderr: stderr,
 		Tty:    false,
 	})
+	result.stdout, result.stderr = stdout.Bytes(), stderr.Bytes()
 
+	exitCode, err := execExitCode(err)
+	result.metadata.ExitCode = exitCode
 	if err != nil {
-		return stdout.Bytes(), stderr.Bytes(), []string{err.Error()}
+		result.errors = []string{err.Error()}
+	}
+
+	return result
+}
+
+// execExitCode returns the exit code of a command from the error of its stream. A command that exits
+// with a code other than 0 is not an error, as its exit code is recorded.
+func execExitCode(err error) (*int, error) {
+	if err == nil {
+		exitCode := 0
+		return &exitCode, nil
+	}
+
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) && exitErr.Exited() {
+		exitCode := exitErr.ExitStatus()
+		return &exitCode, nil
 	}
 
-	return stdout.Bytes(), stderr.Bytes(), nil
+	return nil, err
 }
 
 func getExecErrosFileName(execCollector *troubleshootv1beta2.Exec) string {
@@ -155,5 +221,6 @@ func getExecErrosFileName(execCollector *troubleshootv1beta2.Exec) string {
 		return fmt.Sprintf("%s-errors.json", execCollector.CollectorName)
 	}
 	// TODO: random part
-	return "errors.json"
+	// not errors.json, which is the error manifest at the root of the bundle
+	return "exec-errors.json"
 }
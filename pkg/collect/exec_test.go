@@ -0,0 +1,24 @@
+package collect
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+func Test_execExitCode(t *testing.T) {
+	exitCode, err := execExitCode(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, *exitCode)
+
+	exitCode, err = execExitCode(utilexec.CodeExitError{Err: errors.New("command terminated with exit code 2"), Code: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 2, *exitCode)
+
+	exitCode, err = execExitCode(errors.New("container not found"))
+	assert.EqualError(t, err, "container not found")
+	assert.Nil(t, exitCode)
+}
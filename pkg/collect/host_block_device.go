@@ -40,7 +40,7 @@ func (c *CollectHostBlockDevices) Title() string {
 }
 
 func (c *CollectHostBlockDevices) IsExcluded() (bool, error) {
-	return isExcluded(c.hostCollector.Exclude)
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
 }
 
 func (c *CollectHostBlockDevices) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
@@ -27,7 +27,7 @@ func (c *CollectHostCertificate) Title() string {
 }
 
 func (c *CollectHostCertificate) IsExcluded() (bool, error) {
-	return isExcluded(c.hostCollector.Exclude)
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
 }
 
 func (c *CollectHostCertificate) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
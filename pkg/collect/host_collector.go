@@ -53,6 +53,23 @@ func GetHostCollector(collector *troubleshootv1beta2.HostCollect, bundlePath str
 		return &CollectHostOS{collector.HostOS, bundlePath}, true
 	case collector.HostRun != nil:
 		return &CollectHostRun{collector.HostRun, bundlePath}, true
+	case collector.K8sDistribution != nil:
+		return &CollectHostK8sDistribution{
+			hostCollector: collector.K8sDistribution,
+			BundlePath:    bundlePath,
+			rootDir:       "/",
+			journal:       journalctl{},
+		}, true
+	case collector.HardwareHealth != nil:
+		return &CollectHostHardwareHealth{hostCollector: collector.HardwareHealth, BundlePath: bundlePath}, true
+	case collector.PortAvailability != nil:
+		return &CollectHostPortAvailability{collector.PortAvailability, bundlePath}, true
+	case collector.WindowsFeatures != nil:
+		return &CollectHostWindowsFeatures{
+			hostCollector: collector.WindowsFeatures,
+			BundlePath:    bundlePath,
+			features:      windowsFeaturesPowerShell{},
+		}, true
 	default:
 		return nil, false
 	}
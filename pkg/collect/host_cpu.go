@@ -26,7 +26,7 @@ func (c *CollectHostCPU) Title() string {
 }
 
 func (c *CollectHostCPU) IsExcluded() (bool, error) {
-	return isExcluded(c.hostCollector.Exclude)
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
 }
 
 func (c *CollectHostCPU) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
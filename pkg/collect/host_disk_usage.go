@@ -27,7 +27,7 @@ func (c *CollectHostDiskUsage) Title() string {
 }
 
 func (c *CollectHostDiskUsage) IsExcluded() (bool, error) {
-	return isExcluded(c.hostCollector.Exclude)
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
 }
 
 func (c *CollectHostDiskUsage) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
@@ -76,10 +76,12 @@ func traverseFiletreeDirExists(filename string) (string, error) {
 		if err == nil {
 			return filename, nil
 		} else if os.IsNotExist(err) {
-			filename = filepath.Dir(filename)
-			if filename == "/" {
+			parent := filepath.Dir(filename)
+			// the root is "/", or the volume such as C:\ on windows
+			if parent == filename {
 				return filename, nil
 			}
+			filename = parent
 		} else {
 			return "", err
 		}
@@ -0,0 +1,23 @@
+package collect
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_traverseFiletreeDirExists(t *testing.T) {
+	dir := t.TempDir()
+
+	got, err := traverseFiletreeDirExists(filepath.Join(dir, "data", "registry"))
+	require.NoError(t, err)
+	assert.Equal(t, dir, got)
+
+	root, err := filepath.Abs(string(filepath.Separator))
+	require.NoError(t, err)
+	got, err = traverseFiletreeDirExists(filepath.Join(root, "does-not-exist", "data"))
+	require.NoError(t, err)
+	assert.Equal(t, root, got)
+}
@@ -24,7 +24,7 @@ func (c *CollectHostFilesystemPerformance) Title() string {
 }
 
 func (c *CollectHostFilesystemPerformance) IsExcluded() (bool, error) {
-	return isExcluded(c.hostCollector.Exclude)
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
 }
 
 func (c *CollectHostFilesystemPerformance) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
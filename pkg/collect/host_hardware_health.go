@@ -0,0 +1,362 @@
+package collect
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/k8sutil"
+	"github.com/replicatedhq/troubleshoot/pkg/redact"
+	"k8s.io/client-go/kubernetes"
+)
+
+const HostHardwareHealthPath = "host-collectors/hardware-health"
+
+// HardwareHealthResult is what the hardwareHealth collector read from the BMC
+type HardwareHealthResult struct {
+	Address string            `json:"address"`
+	Systems []HardwareSystem  `json:"systems"`
+	Chassis []HardwareChassis `json:"chassis"`
+	// Error is why the systems or chassis of the BMC could not be listed
+	Error string `json:"error,omitempty"`
+}
+
+// HardwareStatus is the Redfish status of a resource. Health is OK, Warning or Critical.
+type HardwareStatus struct {
+	State  string `json:"state,omitempty"`
+	Health string `json:"health,omitempty"`
+}
+
+type HardwareSystem struct {
+	ID           string         `json:"id"`
+	Name         string         `json:"name,omitempty"`
+	Manufacturer string         `json:"manufacturer,omitempty"`
+	Model        string         `json:"model,omitempty"`
+	PowerState   string         `json:"powerState,omitempty"`
+	Status       HardwareStatus `json:"status"`
+}
+
+type HardwareChassis struct {
+	ID            string                `json:"id"`
+	Name          string                `json:"name,omitempty"`
+	Status        HardwareStatus        `json:"status"`
+	Temperatures  []HardwareTemperature `json:"temperatures"`
+	Fans          []HardwareFan         `json:"fans"`
+	PowerSupplies []HardwarePowerSupply `json:"powerSupplies"`
+	Errors        []string              `json:"errors,omitempty"`
+}
+
+type HardwareTemperature struct {
+	Name           string         `json:"name"`
+	ReadingCelsius *float64       `json:"readingCelsius,omitempty"`
+	UpperCritical  *float64       `json:"upperCritical,omitempty"`
+	Status         HardwareStatus `json:"status"`
+}
+
+type HardwareFan struct {
+	Name         string         `json:"name"`
+	Reading      *float64       `json:"reading,omitempty"`
+	ReadingUnits string         `json:"readingUnits,omitempty"`
+	Status       HardwareStatus `json:"status"`
+}
+
+type HardwarePowerSupply struct {
+	Name               string         `json:"name"`
+	PowerCapacityWatts *float64       `json:"powerCapacityWatts,omitempty"`
+	Status             HardwareStatus `json:"status"`
+}
+
+type redfishLink struct {
+	ID string `json:"@odata.id"`
+}
+
+type redfishCollection struct {
+	Members []redfishLink `json:"Members"`
+}
+
+type redfishStatus struct {
+	State  string `json:"State"`
+	Health string `json:"Health"`
+}
+
+func (s redfishStatus) toStatus() HardwareStatus {
+	return HardwareStatus{State: s.State, Health: s.Health}
+}
+
+type CollectHostHardwareHealth struct {
+	hostCollector *troubleshootv1beta2.HostHardwareHealth
+	BundlePath    string
+	// client reads the credentials of secretKeyRef sources. It is created from the kubeconfig when it is
+	// needed if it is not set.
+	client kubernetes.Interface
+}
+
+func (c *CollectHostHardwareHealth) Title() string {
+	return hostCollectorTitleOrDefault(c.hostCollector.HostCollectorMeta, "Hardware Health")
+}
+
+func (c *CollectHostHardwareHealth) IsExcluded() (bool, error) {
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
+}
+
+func (c *CollectHostHardwareHealth) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
+	timeout := 30 * time.Second
+	if c.hostCollector.Timeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(c.hostCollector.Timeout)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse timeout %q", c.hostCollector.Timeout)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	headers, err := c.headers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	redfish := &redfishClient{
+		address:            strings.TrimSuffix(c.hostCollector.Address, "/"),
+		headers:            headers,
+		insecureSkipVerify: c.hostCollector.InsecureSkipVerify,
+	}
+
+	result := HardwareHealthResult{
+		Address: c.hostCollector.Address,
+		Systems: []HardwareSystem{},
+		Chassis: []HardwareChassis{},
+	}
+	if err := collectHardwareHealth(ctx, redfish, &result); err != nil {
+		result.Error = err.Error()
+	}
+
+	b, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal hardware health")
+	}
+
+	collectorName := c.hostCollector.CollectorName
+	if collectorName == "" {
+		collectorName = "hardware-health"
+	}
+	name := filepath.Join(HostHardwareHealthPath, collectorName+".json")
+
+	output := NewResult()
+	output.SaveResult(c.BundlePath, name, bytes.NewBuffer(b))
+
+	return map[string][]byte{
+		name: b,
+	}, nil
+}
+
+// headers returns the basic auth header of the credentials, if they are set. The credentials are never
+// included in errors.
+func (c *CollectHostHardwareHealth) headers(ctx context.Context) (map[string]string, error) {
+	headers := map[string]string{"Accept": "application/json"}
+	if c.hostCollector.UsernameFrom == nil && c.hostCollector.PasswordFrom == nil {
+		return headers, nil
+	}
+
+	var username, password string
+	var err error
+	if c.hostCollector.UsernameFrom != nil {
+		username, err = c.resolve(ctx, *c.hostCollector.UsernameFrom)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read username")
+		}
+	}
+	if c.hostCollector.PasswordFrom != nil {
+		password, err = c.resolve(ctx, *c.hostCollector.PasswordFrom)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read password")
+		}
+	}
+
+	headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	return headers, nil
+}
+
+func (c *CollectHostHardwareHealth) resolve(ctx context.Context, valueFrom troubleshootv1beta2.ValueFrom) (string, error) {
+	if valueFrom.SecretKeyRef != nil && c.client == nil {
+		restConfig, err := k8sutil.GetRESTConfig()
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get kubernetes config")
+		}
+		c.client, err = kubernetes.NewForConfig(restConfig)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to create kubernetes client")
+		}
+	}
+	return redact.ResolveValueFrom(ctx, c.client, valueFrom)
+}
+
+// collectHardwareHealth reads the systems and chassis of the BMC into result. Errors reading the sensors
+// of a chassis are recorded in the chassis.
+func collectHardwareHealth(ctx context.Context, redfish *redfishClient, result *HardwareHealthResult) error {
+	systems := redfishCollection{}
+	if err := redfish.get(ctx, "/redfish/v1/Systems", &systems); err != nil {
+		return errors.Wrap(err, "failed to list systems")
+	}
+	for _, member := range systems.Members {
+		system := struct {
+			ID           string        `json:"Id"`
+			Name         string        `json:"Name"`
+			Manufacturer string        `json:"Manufacturer"`
+			Model        string        `json:"Model"`
+			PowerState   string        `json:"PowerState"`
+			Status       redfishStatus `json:"Status"`
+		}{}
+		if err := redfish.get(ctx, member.ID, &system); err != nil {
+			return errors.Wrapf(err, "failed to get system %s", member.ID)
+		}
+		result.Systems = append(result.Systems, HardwareSystem{
+			ID:           system.ID,
+			Name:         system.Name,
+			Manufacturer: system.Manufacturer,
+			Model:        system.Model,
+			PowerState:   system.PowerState,
+			Status:       system.Status.toStatus(),
+		})
+	}
+
+	chassisList := redfishCollection{}
+	if err := redfish.get(ctx, "/redfish/v1/Chassis", &chassisList); err != nil {
+		return errors.Wrap(err, "failed to list chassis")
+	}
+	for _, member := range chassisList.Members {
+		chassis := struct {
+			ID      string        `json:"Id"`
+			Name    string        `json:"Name"`
+			Status  redfishStatus `json:"Status"`
+			Thermal *redfishLink  `json:"Thermal"`
+			Power   *redfishLink  `json:"Power"`
+		}{}
+		if err := redfish.get(ctx, member.ID, &chassis); err != nil {
+			return errors.Wrapf(err, "failed to get chassis %s", member.ID)
+		}
+
+		hardwareChassis := HardwareChassis{
+			ID:            chassis.ID,
+			Name:          chassis.Name,
+			Status:        chassis.Status.toStatus(),
+			Temperatures:  []HardwareTemperature{},
+			Fans:          []HardwareFan{},
+			PowerSupplies: []HardwarePowerSupply{},
+		}
+		if chassis.Thermal != nil {
+			if err := collectRedfishThermal(ctx, redfish, chassis.Thermal.ID, &hardwareChassis); err != nil {
+				hardwareChassis.Errors = append(hardwareChassis.Errors, err.Error())
+			}
+		}
+		if chassis.Power != nil {
+			if err := collectRedfishPower(ctx, redfish, chassis.Power.ID, &hardwareChassis); err != nil {
+				hardwareChassis.Errors = append(hardwareChassis.Errors, err.Error())
+			}
+		}
+		result.Chassis = append(result.Chassis, hardwareChassis)
+	}
+
+	return nil
+}
+
+func collectRedfishThermal(ctx context.Context, redfish *redfishClient, path string, chassis *HardwareChassis) error {
+	thermal := struct {
+		Temperatures []struct {
+			Name                   string        `json:"Name"`
+			ReadingCelsius         *float64      `json:"ReadingCelsius"`
+			UpperThresholdCritical *float64      `json:"UpperThresholdCritical"`
+			Status                 redfishStatus `json:"Status"`
+		} `json:"Temperatures"`
+		Fans []struct {
+			Name string `json:"Name"`
+			// FanName is the name of fans in schemas before Thermal 1.1
+			FanName      string        `json:"FanName"`
+			Reading      *float64      `json:"Reading"`
+			ReadingUnits string        `json:"ReadingUnits"`
+			Status       redfishStatus `json:"Status"`
+		} `json:"Fans"`
+	}{}
+	if err := redfish.get(ctx, path, &thermal); err != nil {
+		return errors.Wrap(err, "failed to get thermal")
+	}
+
+	for _, temperature := range thermal.Temperatures {
+		chassis.Temperatures = append(chassis.Temperatures, HardwareTemperature{
+			Name:           temperature.Name,
+			ReadingCelsius: temperature.ReadingCelsius,
+			UpperCritical:  temperature.UpperThresholdCritical,
+			Status:         temperature.Status.toStatus(),
+		})
+	}
+	for _, fan := range thermal.Fans {
+		name := fan.Name
+		if name == "" {
+			name = fan.FanName
+		}
+		chassis.Fans = append(chassis.Fans, HardwareFan{
+			Name:         name,
+			Reading:      fan.Reading,
+			ReadingUnits: fan.ReadingUnits,
+			Status:       fan.Status.toStatus(),
+		})
+	}
+
+	return nil
+}
+
+func collectRedfishPower(ctx context.Context, redfish *redfishClient, path string, chassis *HardwareChassis) error {
+	power := struct {
+		PowerSupplies []struct {
+			Name               string        `json:"Name"`
+			PowerCapacityWatts *float64      `json:"PowerCapacityWatts"`
+			Status             redfishStatus `json:"Status"`
+		} `json:"PowerSupplies"`
+	}{}
+	if err := redfish.get(ctx, path, &power); err != nil {
+		return errors.Wrap(err, "failed to get power")
+	}
+
+	for _, supply := range power.PowerSupplies {
+		chassis.PowerSupplies = append(chassis.PowerSupplies, HardwarePowerSupply{
+			Name:               supply.Name,
+			PowerCapacityWatts: supply.PowerCapacityWatts,
+			Status:             supply.Status.toStatus(),
+		})
+	}
+
+	return nil
+}
+
+type redfishClient struct {
+	address            string
+	headers            map[string]string
+	insecureSkipVerify bool
+}
+
+// get decodes the resource at path, which is relative to the address of the BMC
+func (r *redfishClient) get(ctx context.Context, path string, v interface{}) error {
+	result := doRequest(ctx, http.MethodGet, r.address+path, nil, r.headers, r.insecureSkipVerify)
+	if result.err != nil {
+		return result.err
+	}
+	defer result.response.Body.Close()
+
+	if result.response.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status code %d", result.response.StatusCode)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(result.response.Body, maxHTTPBodySize)).Decode(v); err != nil {
+		return errors.Wrap(err, "failed to decode response")
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package collect
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+var redfishTestResources = map[string]string{
+	"/redfish/v1/Systems":   `{"Members": [{"@odata.id": "/redfish/v1/Systems/1"}]}`,
+	"/redfish/v1/Systems/1": `{"Id": "1", "Model": "PowerEdge R650", "PowerState": "On", "Status": {"State": "Enabled", "Health": "Warning"}}`,
+	"/redfish/v1/Chassis":   `{"Members": [{"@odata.id": "/redfish/v1/Chassis/1"}]}`,
+	"/redfish/v1/Chassis/1": `{
+		"Id": "1",
+		"Status": {"State": "Enabled", "Health": "OK"},
+		"Thermal": {"@odata.id": "/redfish/v1/Chassis/1/Thermal"},
+		"Power": {"@odata.id": "/redfish/v1/Chassis/1/Power"}
+	}`,
+	"/redfish/v1/Chassis/1/Thermal": `{
+		"Temperatures": [{"Name": "CPU1 Temp", "ReadingCelsius": 91, "UpperThresholdCritical": 95, "Status": {"Health": "Warning"}}],
+		"Fans": [{"FanName": "Fan1", "Reading": 6000, "ReadingUnits": "RPM", "Status": {"Health": "OK"}}]
+	}`,
+}
+
+func redfishTestServer(t *testing.T, username string, password string) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != username || p != password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, ok := redfishTestResources[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+}
+
+func collectHardwareHealthResult(t *testing.T, c *CollectHostHardwareHealth) HardwareHealthResult {
+	result, err := c.Collect(nil)
+	require.NoError(t, err)
+
+	b, ok := result[filepath.Join(HostHardwareHealthPath, "hardware-health.json")]
+	require.True(t, ok)
+
+	var health HardwareHealthResult
+	require.NoError(t, json.Unmarshal(b, &health))
+	return health
+}
+
+func TestCollectHostHardwareHealth(t *testing.T) {
+	server := redfishTestServer(t, "root", "calvin")
+	defer server.Close()
+
+	t.Setenv("BMC_USERNAME", "root")
+	client := testclient.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "bmc", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("calvin\n")},
+	})
+
+	health := collectHardwareHealthResult(t, &CollectHostHardwareHealth{
+		hostCollector: &troubleshootv1beta2.HostHardwareHealth{
+			Address:            server.URL,
+			InsecureSkipVerify: true,
+			UsernameFrom:       &troubleshootv1beta2.ValueFrom{EnvVar: "BMC_USERNAME"},
+			PasswordFrom: &troubleshootv1beta2.ValueFrom{
+				SecretKeyRef: &troubleshootv1beta2.SecretKeyRef{Namespace: "default", Name: "bmc", Key: "password"},
+			},
+		},
+		BundlePath: t.TempDir(),
+		client:     client,
+	})
+
+	assert.Empty(t, health.Error)
+	require.Len(t, health.Systems, 1)
+	assert.Equal(t, "PowerEdge R650", health.Systems[0].Model)
+	assert.Equal(t, "Warning", health.Systems[0].Status.Health)
+
+	require.Len(t, health.Chassis, 1)
+	chassis := health.Chassis[0]
+	require.Len(t, chassis.Temperatures, 1)
+	assert.Equal(t, 91.0, *chassis.Temperatures[0].ReadingCelsius)
+	assert.Equal(t, 95.0, *chassis.Temperatures[0].UpperCritical)
+	require.Len(t, chassis.Fans, 1)
+	assert.Equal(t, "Fan1", chassis.Fans[0].Name)
+	// the power resource of the chassis is missing
+	assert.Empty(t, chassis.PowerSupplies)
+	require.Len(t, chassis.Errors, 1)
+	assert.Contains(t, chassis.Errors[0], "failed to get power")
+}
+
+func TestCollectHostHardwareHealth_Unauthorized(t *testing.T) {
+	server := redfishTestServer(t, "root", "calvin")
+	defer server.Close()
+
+	health := collectHardwareHealthResult(t, &CollectHostHardwareHealth{
+		hostCollector: &troubleshootv1beta2.HostHardwareHealth{
+			Address:            server.URL,
+			InsecureSkipVerify: true,
+		},
+		BundlePath: t.TempDir(),
+	})
+
+	assert.Equal(t, "failed to list systems: unexpected status code 401", health.Error)
+	assert.Empty(t, health.Systems)
+}
+
+func TestCollectHostHardwareHealth_MissingCredentials(t *testing.T) {
+	_, err := (&CollectHostHardwareHealth{
+		hostCollector: &troubleshootv1beta2.HostHardwareHealth{
+			Address:      "https://10.0.0.10",
+			PasswordFrom: &troubleshootv1beta2.ValueFrom{EnvVar: "BMC_PASSWORD_NOT_SET"},
+		},
+		BundlePath: t.TempDir(),
+	}).Collect(nil)
+
+	assert.EqualError(t, err, "failed to read password: environment variable BMC_PASSWORD_NOT_SET is not set")
+}
@@ -2,7 +2,7 @@ package collect
 
 import (
 	"bytes"
-	"net/http"
+	"context"
 	"path/filepath"
 
 	"github.com/pkg/errors"
@@ -19,26 +19,25 @@ func (c *CollectHostHTTP) Title() string {
 }
 
 func (c *CollectHostHTTP) IsExcluded() (bool, error) {
-	return isExcluded(c.hostCollector.Exclude)
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
 }
 
 func (c *CollectHostHTTP) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
 	httpCollector := c.hostCollector
 
-	var response *http.Response
-	var err error
+	var result *httpResult
 
 	if httpCollector.Get != nil {
-		response, err = doGet(httpCollector.Get)
+		result = doGet(context.Background(), httpCollector.Get)
 	} else if httpCollector.Post != nil {
-		response, err = doPost(httpCollector.Post)
+		result = doPost(context.Background(), httpCollector.Post)
 	} else if httpCollector.Put != nil {
-		response, err = doPut(httpCollector.Put)
+		result = doPut(context.Background(), httpCollector.Put)
 	} else {
 		return nil, errors.New("no supported http request type")
 	}
 
-	responseOutput, err := responseToOutput(response, err)
+	responseOutput, err := responseToOutput(result)
 	if err != nil {
 		return nil, err
 	}
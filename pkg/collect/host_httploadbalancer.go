@@ -28,7 +28,7 @@ func (c *CollectHostHTTPLoadBalancer) Title() string {
 }
 
 func (c *CollectHostHTTPLoadBalancer) IsExcluded() (bool, error) {
-	return isExcluded(c.hostCollector.Exclude)
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
 }
 
 func (c *CollectHostHTTPLoadBalancer) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
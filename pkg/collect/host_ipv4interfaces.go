@@ -21,7 +21,7 @@ func (c *CollectHostIPV4Interfaces) Title() string {
 }
 
 func (c *CollectHostIPV4Interfaces) IsExcluded() (bool, error) {
-	return isExcluded(c.hostCollector.Exclude)
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
 }
 
 func (c *CollectHostIPV4Interfaces) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
@@ -0,0 +1,261 @@
+package collect
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/redact"
+)
+
+const HostK8sDistributionPath = "host-collectors/k8s-distribution"
+
+// journalLines is how many lines of each service's journal are collected
+const journalLines = 10000
+
+// k8sDistributionLayout is where a distribution keeps its services, config and state on the host.
+// Paths can be globs.
+type k8sDistributionLayout struct {
+	name string
+	// detect is a path that only exists on hosts running the distribution
+	detect   string
+	services []string
+	// configFiles have secret values, such as the cluster token, masked before they are saved
+	configFiles []string
+	stateFiles  []string
+	// stateDirs are listed rather than copied, as they hold databases and images
+	stateDirs []string
+}
+
+var k8sDistributionLayouts = []k8sDistributionLayout{
+	{
+		name:     "k3s",
+		detect:   "/etc/rancher/k3s",
+		services: []string{"k3s", "k3s-agent"},
+		configFiles: []string{
+			"/etc/rancher/k3s/config.yaml",
+			"/etc/rancher/k3s/config.yaml.d/*.yaml",
+			"/etc/rancher/k3s/registries.yaml",
+		},
+		stateFiles: []string{
+			"/var/lib/rancher/k3s/server/db/etcd/config",
+			"/var/lib/rancher/k3s/agent/etc/containerd/config.toml",
+			"/var/lib/rancher/k3s/agent/containerd/containerd.log",
+		},
+		stateDirs: []string{
+			"/var/lib/rancher/k3s/server/db",
+			"/var/lib/rancher/k3s/server/db/etcd/member",
+			"/var/lib/rancher/k3s/server/db/snapshots",
+			"/var/lib/rancher/k3s/server/manifests",
+		},
+	},
+	{
+		name:     "rke2",
+		detect:   "/etc/rancher/rke2",
+		services: []string{"rke2-server", "rke2-agent"},
+		configFiles: []string{
+			"/etc/rancher/rke2/config.yaml",
+			"/etc/rancher/rke2/config.yaml.d/*.yaml",
+			"/etc/rancher/rke2/registries.yaml",
+		},
+		stateFiles: []string{
+			"/var/lib/rancher/rke2/server/db/etcd/config",
+			"/var/lib/rancher/rke2/agent/etc/containerd/config.toml",
+			"/var/lib/rancher/rke2/agent/containerd/containerd.log",
+			"/var/lib/rancher/rke2/agent/logs/kubelet.log",
+		},
+		stateDirs: []string{
+			"/var/lib/rancher/rke2/server/db/etcd/member",
+			"/var/lib/rancher/rke2/server/db/snapshots",
+			"/var/lib/rancher/rke2/server/manifests",
+			"/var/lib/rancher/rke2/agent/pod-manifests",
+		},
+	},
+	{
+		name:     "k0s",
+		detect:   "/var/lib/k0s",
+		services: []string{"k0scontroller", "k0sworker", "embedded-cluster"},
+		configFiles: []string{
+			"/etc/k0s/k0s.yaml",
+		},
+		stateFiles: []string{
+			"/etc/k0s/containerd.toml",
+			"/var/log/embedded-cluster/*.log",
+		},
+		stateDirs: []string{
+			"/var/lib/k0s/etcd/member",
+			"/var/lib/k0s/manifests",
+			"/var/lib/embedded-cluster",
+		},
+	},
+}
+
+// secretConfigLine matches config values that are secrets, such as token, agent-token and the password and
+// datastore-endpoint, which has credentials, of registries and external datastores
+var secretConfigLine = regexp.MustCompile(`(?im)^(\s*-?\s*"?[a-z0-9_-]*(token|password|secret|datastore-endpoint)[a-z0-9_-]*"?\s*:\s*)(\S.*)$`)
+
+type K8sDistributionInfo struct {
+	Distribution string `json:"distribution"`
+	// Files are the paths on the host of the files that were collected
+	Files []string `json:"files"`
+	// Dirs are the contents of state directories, by path on the host
+	Dirs   map[string][]K8sDistributionFile `json:"dirs"`
+	Errors map[string]string                `json:"errors,omitempty"`
+}
+
+type K8sDistributionFile struct {
+	Name  string `json:"name"`
+	Size  int64  `json:"size"`
+	IsDir bool   `json:"isDir,omitempty"`
+}
+
+// journalReader reads the journal of a systemd unit
+type journalReader interface {
+	read(unit string) ([]byte, error)
+}
+
+type journalctl struct{}
+
+func (journalctl) read(unit string) ([]byte, error) {
+	return exec.Command("journalctl", "-u", unit, "--no-pager", "-n", strconv.Itoa(journalLines)).Output()
+}
+
+type CollectHostK8sDistribution struct {
+	hostCollector *troubleshootv1beta2.HostK8sDistribution
+	BundlePath    string
+	// rootDir is prepended to the paths of the distribution's files
+	rootDir string
+	journal journalReader
+}
+
+func (c *CollectHostK8sDistribution) Title() string {
+	return hostCollectorTitleOrDefault(c.hostCollector.HostCollectorMeta, "Kubernetes Distribution")
+}
+
+func (c *CollectHostK8sDistribution) IsExcluded() (bool, error) {
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
+}
+
+func (c *CollectHostK8sDistribution) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
+	layout, err := c.getLayout()
+	if err != nil {
+		return nil, err
+	}
+
+	collectorName := c.hostCollector.CollectorName
+	if collectorName == "" {
+		collectorName = layout.name
+	}
+	outputDir := filepath.Join(HostK8sDistributionPath, collectorName)
+
+	info := K8sDistributionInfo{
+		Distribution: layout.name,
+		Files:        []string{},
+		Dirs:         map[string][]K8sDistributionFile{},
+		Errors:       map[string]string{},
+	}
+	output := NewResult()
+
+	for _, service := range layout.services {
+		logs, err := c.journal.read(service)
+		if err != nil {
+			info.Errors[service] = err.Error()
+			continue
+		}
+		// services that aren't installed have no entries
+		if len(bytes.TrimSpace(logs)) == 0 || bytes.HasPrefix(logs, []byte("-- No entries --")) {
+			continue
+		}
+		output.SaveResult(c.BundlePath, filepath.Join(outputDir, "logs", service+".log"), bytes.NewBuffer(logs))
+	}
+
+	for _, pattern := range layout.configFiles {
+		c.collectFiles(output, outputDir, pattern, true, &info)
+	}
+	for _, pattern := range layout.stateFiles {
+		c.collectFiles(output, outputDir, pattern, false, &info)
+	}
+
+	for _, dir := range layout.stateDirs {
+		entries, err := ioutil.ReadDir(filepath.Join(c.rootDir, dir))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			info.Errors[dir] = err.Error()
+			continue
+		}
+
+		files := []K8sDistributionFile{}
+		for _, entry := range entries {
+			files = append(files, K8sDistributionFile{Name: entry.Name(), Size: entry.Size(), IsDir: entry.IsDir()})
+		}
+		info.Dirs[dir] = files
+	}
+
+	sort.Strings(info.Files)
+	b, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal distribution info")
+	}
+	output.SaveResult(c.BundlePath, filepath.Join(outputDir, "info.json"), bytes.NewBuffer(b))
+
+	return output, nil
+}
+
+func (c *CollectHostK8sDistribution) getLayout() (*k8sDistributionLayout, error) {
+	for i, layout := range k8sDistributionLayouts {
+		if c.hostCollector.Distribution != "" {
+			if layout.name == c.hostCollector.Distribution {
+				return &k8sDistributionLayouts[i], nil
+			}
+			continue
+		}
+
+		if _, err := os.Stat(filepath.Join(c.rootDir, layout.detect)); err == nil {
+			return &k8sDistributionLayouts[i], nil
+		}
+	}
+
+	if c.hostCollector.Distribution != "" {
+		return nil, errors.Errorf("unsupported distribution %q", c.hostCollector.Distribution)
+	}
+	return nil, errors.New("no supported distribution was detected on the host")
+}
+
+// collectFiles saves the files matching pattern under outputDir, keeping their path on the host
+func (c *CollectHostK8sDistribution) collectFiles(output CollectorResult, outputDir string, pattern string, maskSecrets bool, info *K8sDistributionInfo) {
+	matches, err := filepath.Glob(filepath.Join(c.rootDir, pattern))
+	if err != nil {
+		info.Errors[pattern] = err.Error()
+		return
+	}
+
+	for _, match := range matches {
+		hostPath, err := filepath.Rel(c.rootDir, match)
+		if err != nil {
+			info.Errors[match] = err.Error()
+			continue
+		}
+		hostPath = filepath.Join("/", hostPath)
+
+		b, err := ioutil.ReadFile(match)
+		if err != nil {
+			info.Errors[hostPath] = err.Error()
+			continue
+		}
+		if maskSecrets {
+			b = secretConfigLine.ReplaceAll(b, []byte("${1}"+redact.MASK_TEXT))
+		}
+
+		output.SaveResult(c.BundlePath, filepath.Join(outputDir, "files", hostPath), bytes.NewBuffer(b))
+		info.Files = append(info.Files, hostPath)
+	}
+}
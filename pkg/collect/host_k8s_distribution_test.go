@@ -0,0 +1,151 @@
+package collect
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockJournalReader map[string]string
+
+func (m mockJournalReader) read(unit string) ([]byte, error) {
+	logs, ok := m[unit]
+	if !ok {
+		return nil, errors.New("exit status 1")
+	}
+	return []byte(logs), nil
+}
+
+func writeTestFiles(t *testing.T, rootDir string, files map[string]string) {
+	for name, contents := range files {
+		filename := filepath.Join(rootDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755))
+		require.NoError(t, os.WriteFile(filename, []byte(contents), 0644))
+	}
+}
+
+func TestCollectHostK8sDistribution_Collect(t *testing.T) {
+	rootDir := t.TempDir()
+	writeTestFiles(t, rootDir, map[string]string{
+		"/etc/rancher/k3s/config.yaml":                          "write-kubeconfig-mode: \"0644\"\ntoken: abc123\nagent-token: \"def456\"\n",
+		"/etc/rancher/k3s/registries.yaml":                      "configs:\n  registry.example.com:\n    auth:\n      username: admin\n      password: hunter2\n",
+		"/var/lib/rancher/k3s/agent/etc/containerd/config.toml": "version = 2\n",
+		"/var/lib/rancher/k3s/server/token":                     "K10abc::server:def",
+		"/var/lib/rancher/k3s/server/db/etcd/member/snap/db":    "data",
+	})
+
+	c := &CollectHostK8sDistribution{
+		hostCollector: &troubleshootv1beta2.HostK8sDistribution{},
+		BundlePath:    t.TempDir(),
+		rootDir:       rootDir,
+		journal: mockJournalReader{
+			"k3s":       "Started Lightweight Kubernetes.\n",
+			"k3s-agent": "-- No entries --\n",
+		},
+	}
+
+	result, err := c.Collect(nil)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{
+		"host-collectors/k8s-distribution/k3s/info.json",
+		"host-collectors/k8s-distribution/k3s/logs/k3s.log",
+		"host-collectors/k8s-distribution/k3s/files/etc/rancher/k3s/config.yaml",
+		"host-collectors/k8s-distribution/k3s/files/etc/rancher/k3s/registries.yaml",
+		"host-collectors/k8s-distribution/k3s/files/var/lib/rancher/k3s/agent/etc/containerd/config.toml",
+	}, resultPaths(result))
+
+	b, err := os.ReadFile(filepath.Join(c.BundlePath, "host-collectors/k8s-distribution/k3s/files/etc/rancher/k3s/config.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "write-kubeconfig-mode: \"0644\"\ntoken: ***HIDDEN***\nagent-token: ***HIDDEN***\n", string(b))
+
+	b, err = os.ReadFile(filepath.Join(c.BundlePath, "host-collectors/k8s-distribution/k3s/files/etc/rancher/k3s/registries.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "username: admin")
+	assert.Contains(t, string(b), "password: ***HIDDEN***")
+
+	b, err = os.ReadFile(filepath.Join(c.BundlePath, "host-collectors/k8s-distribution/k3s/info.json"))
+	require.NoError(t, err)
+	var info K8sDistributionInfo
+	require.NoError(t, json.Unmarshal(b, &info))
+	assert.Equal(t, "k3s", info.Distribution)
+	assert.Equal(t, []K8sDistributionFile{{Name: "snap", IsDir: true}}, resetSizes(info.Dirs["/var/lib/rancher/k3s/server/db/etcd/member"]))
+	assert.Empty(t, info.Errors)
+}
+
+func TestCollectHostK8sDistribution_Distribution(t *testing.T) {
+	rootDir := t.TempDir()
+	writeTestFiles(t, rootDir, map[string]string{
+		"/etc/rancher/k3s/config.yaml": "token: abc123\n",
+		"/etc/k0s/k0s.yaml":            "apiVersion: k0s.k0sproject.io/v1beta1\n",
+	})
+
+	tests := []struct {
+		name         string
+		distribution string
+		rootDir      string
+		want         string
+		wantErr      bool
+	}{
+		{
+			name:    "detected",
+			rootDir: rootDir,
+			want:    "k3s",
+		},
+		{
+			name:         "set",
+			distribution: "k0s",
+			rootDir:      rootDir,
+			want:         "k0s",
+		},
+		{
+			name:         "unsupported",
+			distribution: "microk8s",
+			rootDir:      rootDir,
+			wantErr:      true,
+		},
+		{
+			name:    "not detected",
+			rootDir: t.TempDir(),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &CollectHostK8sDistribution{
+				hostCollector: &troubleshootv1beta2.HostK8sDistribution{Distribution: tt.distribution},
+				rootDir:       tt.rootDir,
+			}
+
+			layout, err := c.getLayout()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, layout.name)
+		})
+	}
+}
+
+func resultPaths(result CollectorResult) []string {
+	names := []string{}
+	for name := range result {
+		names = append(names, name)
+	}
+	return names
+}
+
+// resetSizes clears sizes of directories, which depend on the filesystem
+func resetSizes(files []K8sDistributionFile) []K8sDistributionFile {
+	for i := range files {
+		files[i].Size = 0
+	}
+	return files
+}
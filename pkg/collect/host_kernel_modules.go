@@ -54,7 +54,7 @@ func (c *CollectHostKernelModules) Title() string {
 
 // IsExcluded returns true if the collector has been excluded from the results.
 func (c *CollectHostKernelModules) IsExcluded() (bool, error) {
-	return isExcluded(c.hostCollector.Exclude)
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
 }
 
 // Collect the kernel module status from the host.   Modules are returned as a
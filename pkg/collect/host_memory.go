@@ -25,7 +25,7 @@ func (c *CollectHostMemory) Title() string {
 }
 
 func (c *CollectHostMemory) IsExcluded() (bool, error) {
-	return isExcluded(c.hostCollector.Exclude)
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
 }
 
 func (c *CollectHostMemory) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
@@ -28,7 +28,7 @@ func (c *CollectHostOS) Title() string {
 }
 
 func (c *CollectHostOS) IsExcluded() (bool, error) {
-	return isExcluded(c.hostCollector.Exclude)
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
 }
 
 func (c *CollectHostOS) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
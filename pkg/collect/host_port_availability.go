@@ -0,0 +1,290 @@
+package collect
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+)
+
+const (
+	PortStatusAvailable = "available"
+	// PortStatusBlocked is a tcp port that could be listened on, but not connected to, such as because of
+	// a firewall
+	PortStatusBlocked = "blocked"
+)
+
+// portAvailabilityConcurrency is how many ports are checked at once, so that ranges of ports don't run out
+// of file descriptors
+const portAvailabilityConcurrency = 32
+
+type PortAvailabilityResult struct {
+	Ports []PortStatus `json:"ports"`
+}
+
+type PortStatus struct {
+	Port     int           `json:"port"`
+	Protocol string        `json:"protocol"`
+	Status   NetworkStatus `json:"status"`
+	// Process and PID are the process that is listening on a port that is in use, when it can be found
+	Process string `json:"process,omitempty"`
+	PID     int    `json:"pid,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// String describes the port and the process that has it, such as 10250/tcp (kubelet, pid 1042)
+func (p PortStatus) String() string {
+	s := fmt.Sprintf("%d/%s", p.Port, p.Protocol)
+	if p.Process != "" {
+		s += fmt.Sprintf(" (%s, pid %d)", p.Process, p.PID)
+	}
+	return s
+}
+
+type CollectHostPortAvailability struct {
+	hostCollector *troubleshootv1beta2.PortAvailability
+	BundlePath    string
+}
+
+func (c *CollectHostPortAvailability) Title() string {
+	return hostCollectorTitleOrDefault(c.hostCollector.HostCollectorMeta, "Port Availability")
+}
+
+func (c *CollectHostPortAvailability) IsExcluded() (bool, error) {
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
+}
+
+// Collect checks that each port can be listened on, and that tcp ports can then be connected to on an
+// address of the host, which fails if a firewall blocks them
+func (c *CollectHostPortAvailability) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
+	ports, err := parsePorts(c.hostCollector.Ports)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := time.Second
+	if c.hostCollector.Timeout != "" {
+		timeout, err = time.ParseDuration(c.hostCollector.Timeout)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse timeout %q", c.hostCollector.Timeout)
+		}
+	}
+
+	listenIP := net.IPv4zero
+	var dialIP net.IP
+	if c.hostCollector.Interface != "" {
+		iface, err := net.InterfaceByName(c.hostCollector.Interface)
+		if err != nil {
+			return nil, errors.Wrapf(err, "lookup interface %s", c.hostCollector.Interface)
+		}
+		listenIP, err = getIPv4FromInterface(iface)
+		if err != nil {
+			return nil, errors.Wrapf(err, "get ipv4 address for interface %s", c.hostCollector.Interface)
+		}
+		dialIP = listenIP
+	} else if ip, err := getLocalIPv4(); err == nil {
+		dialIP = ip
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, portAvailabilityConcurrency)
+	for i := range ports {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(port *PortStatus) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			checkPortAvailability(port, listenIP, dialIP, timeout)
+		}(&ports[i])
+	}
+	wg.Wait()
+
+	for i := range ports {
+		if ports[i].Status == NetworkStatusAddressInUse {
+			ports[i].PID, ports[i].Process = findPortOwner("/proc", ports[i].Protocol, ports[i].Port)
+		}
+	}
+
+	b, err := json.Marshal(PortAvailabilityResult{Ports: ports})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal result")
+	}
+
+	collectorName := c.hostCollector.CollectorName
+	if collectorName == "" {
+		collectorName = "portAvailability"
+	}
+	name := filepath.Join("host-collectors/portAvailability", collectorName+".json")
+
+	output := NewResult()
+	output.SaveResult(c.BundlePath, name, bytes.NewBuffer(b))
+
+	return map[string][]byte{
+		name: b,
+	}, nil
+}
+
+// parsePorts expands ports such as 6443, 2379-2380 and 8472/udp
+func parsePorts(specs []string) ([]PortStatus, error) {
+	ports := []PortStatus{}
+	for _, spec := range specs {
+		protocol := "tcp"
+		portRange := strings.TrimSpace(spec)
+		if i := strings.Index(portRange, "/"); i != -1 {
+			protocol = strings.ToLower(portRange[i+1:])
+			portRange = portRange[:i]
+		}
+		if protocol != "tcp" && protocol != "udp" {
+			return nil, errors.Errorf("unsupported protocol %q of port %q", protocol, spec)
+		}
+
+		first, last := portRange, portRange
+		if i := strings.Index(portRange, "-"); i != -1 {
+			first, last = portRange[:i], portRange[i+1:]
+		}
+		from, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, errors.Errorf("invalid port %q", spec)
+		}
+		to, err := strconv.Atoi(last)
+		if err != nil {
+			return nil, errors.Errorf("invalid port %q", spec)
+		}
+		if from < 1 || to > 65535 || from > to {
+			return nil, errors.Errorf("invalid port %q", spec)
+		}
+
+		for port := from; port <= to; port++ {
+			ports = append(ports, PortStatus{Port: port, Protocol: protocol})
+		}
+	}
+	return ports, nil
+}
+
+func checkPortAvailability(port *PortStatus, listenIP net.IP, dialIP net.IP, timeout time.Duration) {
+	address := net.JoinHostPort(listenIP.String(), strconv.Itoa(port.Port))
+
+	var closer io.Closer
+	var err error
+	if port.Protocol == "udp" {
+		closer, err = net.ListenPacket("udp", address)
+	} else {
+		closer, err = net.Listen("tcp", address)
+	}
+	if err != nil {
+		port.Status = listenErrorStatus(err)
+		port.Message = err.Error()
+		return
+	}
+	defer closer.Close()
+
+	// udp has no connection to check
+	if port.Protocol == "udp" || dialIP == nil {
+		port.Status = PortStatusAvailable
+		return
+	}
+
+	// the listener does not have to accept the connection, as the kernel completes it
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(dialIP.String(), strconv.Itoa(port.Port)), timeout)
+	if err != nil {
+		port.Status = PortStatusBlocked
+		port.Message = err.Error()
+		return
+	}
+	conn.Close()
+	port.Status = PortStatusAvailable
+}
+
+func listenErrorStatus(err error) NetworkStatus {
+	switch {
+	// windows says "Only one usage of each socket address (protocol/network address/port) is normally permitted"
+	case strings.Contains(err.Error(), "address already in use"), strings.Contains(err.Error(), "Only one usage of each socket address"):
+		return NetworkStatusAddressInUse
+	case strings.Contains(err.Error(), "permission denied"):
+		return NetworkStatusBindPermissionDenied
+	default:
+		return NetworkStatusErrorOther
+	}
+}
+
+// findPortOwner returns the process that is listening on a port from the sockets in /proc, which is only
+// possible on linux, and for the processes of other users only when running as root
+func findPortOwner(procDir string, protocol string, port int) (int, string) {
+	inodes := map[string]bool{}
+	for _, name := range []string{protocol, protocol + "6"} {
+		f, err := os.Open(filepath.Join(procDir, "net", name))
+		if err != nil {
+			continue
+		}
+		for inode := range findSocketInodes(f, protocol, port) {
+			inodes[inode] = true
+		}
+		f.Close()
+	}
+	if len(inodes) == 0 {
+		return 0, ""
+	}
+
+	fds, _ := filepath.Glob(filepath.Join(procDir, "[0-9]*", "fd", "*"))
+	for _, fd := range fds {
+		link, err := os.Readlink(fd)
+		if err != nil || !strings.HasPrefix(link, "socket:[") {
+			continue
+		}
+		if !inodes[strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")] {
+			continue
+		}
+
+		pidDir := filepath.Dir(filepath.Dir(fd))
+		pid, _ := strconv.Atoi(filepath.Base(pidDir))
+		comm, err := ioutil.ReadFile(filepath.Join(pidDir, "comm"))
+		if err != nil {
+			return pid, ""
+		}
+		return pid, strings.TrimSpace(string(comm))
+	}
+
+	return 0, ""
+}
+
+// findSocketInodes returns the inodes of the sockets bound to a local port in /proc/net/tcp, tcp6, udp or
+// udp6, which look like
+//
+//	sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+//	 0: 00000000:192B 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 21405 1 ...
+func findSocketInodes(r io.Reader, protocol string, port int) map[string]bool {
+	inodes := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[0] == "sl" {
+			continue
+		}
+		// only listening tcp sockets, rather than connections from the port
+		if protocol == "tcp" && fields[3] != "0A" {
+			continue
+		}
+		i := strings.LastIndex(fields[1], ":")
+		if i == -1 {
+			continue
+		}
+		localPort, err := strconv.ParseInt(fields[1][i+1:], 16, 32)
+		if err != nil || int(localPort) != port {
+			continue
+		}
+		inodes[fields[9]] = true
+	}
+	return inodes
+}
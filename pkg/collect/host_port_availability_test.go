@@ -0,0 +1,84 @@
+package collect
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parsePorts(t *testing.T) {
+	ports, err := parsePorts([]string{"6443", "2379-2380", "8472/udp", " 10250/TCP"})
+	require.NoError(t, err)
+	assert.Equal(t, []PortStatus{
+		{Port: 6443, Protocol: "tcp"},
+		{Port: 2379, Protocol: "tcp"},
+		{Port: 2380, Protocol: "tcp"},
+		{Port: 8472, Protocol: "udp"},
+		{Port: 10250, Protocol: "tcp"},
+	}, ports)
+
+	for _, spec := range []string{"", "abc", "0", "65536", "2380-2379", "53/sctp", "1-"} {
+		_, err := parsePorts([]string{spec})
+		assert.Error(t, err, spec)
+	}
+}
+
+func Test_checkPortAvailability(t *testing.T) {
+	listener, err := net.Listen("tcp", "0.0.0.0:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	inUse := &PortStatus{Port: listener.Addr().(*net.TCPAddr).Port, Protocol: "tcp"}
+	checkPortAvailability(inUse, net.IPv4zero, net.IPv4(127, 0, 0, 1), time.Second)
+	assert.Equal(t, NetworkStatus(NetworkStatusAddressInUse), inUse.Status)
+
+	// the port is free once the listener is closed
+	port := inUse.Port
+	listener.Close()
+	available := &PortStatus{Port: port, Protocol: "tcp"}
+	checkPortAvailability(available, net.IPv4zero, net.IPv4(127, 0, 0, 1), time.Second)
+	assert.Equal(t, NetworkStatus(PortStatusAvailable), available.Status)
+}
+
+func Test_findPortOwner(t *testing.T) {
+	procDir := t.TempDir()
+	files := map[string]string{
+		"net/tcp": `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:2710 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1111 1 0000000000000000 100 0 0 10 0
+   1: 0A000001:280A 0A000002:D2F0 01 00000000:00000000 00:00000000 00000000     0        0 2222 1 0000000000000000 20 4 30 10 -1
+`,
+		"net/tcp6": `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:280A 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 3333 1 0000000000000000 100 0 0 10 0
+`,
+		"100/comm":  "etcd\n",
+		"4242/comm": "kubelet\n",
+	}
+	for name, contents := range files {
+		filename := filepath.Join(procDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755))
+		require.NoError(t, ioutil.WriteFile(filename, []byte(contents), 0644))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(procDir, "100", "fd"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(procDir, "4242", "fd"), 0755))
+	require.NoError(t, os.Symlink("socket:[2222]", filepath.Join(procDir, "100", "fd", "3")))
+	require.NoError(t, os.Symlink("/dev/null", filepath.Join(procDir, "4242", "fd", "0")))
+	require.NoError(t, os.Symlink("socket:[3333]", filepath.Join(procDir, "4242", "fd", "7")))
+
+	// 10250 is listened on by kubelet in tcp6, rather than connected to by etcd
+	pid, process := findPortOwner(procDir, "tcp", 10250)
+	assert.Equal(t, 4242, pid)
+	assert.Equal(t, "kubelet", process)
+
+	// no process has the socket of 10000
+	pid, process = findPortOwner(procDir, "tcp", 10000)
+	assert.Equal(t, 0, pid)
+	assert.Equal(t, "", process)
+
+	assert.Equal(t, map[string]bool{"1111": true}, findSocketInodes(strings.NewReader(files["net/tcp"]), "tcp", 10000))
+}
@@ -27,11 +27,11 @@ type CollectHostServices struct {
 }
 
 func (c *CollectHostServices) Title() string {
-	return hostCollectorTitleOrDefault(c.hostCollector.HostCollectorMeta, "Block Devices")
+	return hostCollectorTitleOrDefault(c.hostCollector.HostCollectorMeta, "Host Services")
 }
 
 func (c *CollectHostServices) IsExcluded() (bool, error) {
-	return isExcluded(c.hostCollector.Exclude)
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
 }
 
 func (c *CollectHostServices) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
@@ -37,7 +37,7 @@ func (c *CollectHostSystemPackages) Title() string {
 }
 
 func (c *CollectHostSystemPackages) IsExcluded() (bool, error) {
-	return isExcluded(c.hostCollector.Exclude)
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
 }
 
 func (c *CollectHostSystemPackages) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
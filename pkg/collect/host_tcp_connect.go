@@ -22,7 +22,7 @@ func (c *CollectHostTCPConnect) Title() string {
 }
 
 func (c *CollectHostTCPConnect) IsExcluded() (bool, error) {
-	return isExcluded(c.hostCollector.Exclude)
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
 }
 
 func (c *CollectHostTCPConnect) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
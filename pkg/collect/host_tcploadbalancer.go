@@ -21,7 +21,7 @@ func (c *CollectHostTCPLoadBalancer) Title() string {
 }
 
 func (c *CollectHostTCPLoadBalancer) IsExcluded() (bool, error) {
-	return isExcluded(c.hostCollector.Exclude)
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
 }
 
 func (c *CollectHostTCPLoadBalancer) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
@@ -22,7 +22,7 @@ func (c *CollectHostTCPPortStatus) Title() string {
 }
 
 func (c *CollectHostTCPPortStatus) IsExcluded() (bool, error) {
-	return isExcluded(c.hostCollector.Exclude)
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
 }
 
 func (c *CollectHostTCPPortStatus) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
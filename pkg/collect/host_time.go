@@ -32,7 +32,7 @@ func (c *CollectHostTime) Title() string {
 }
 
 func (c *CollectHostTime) IsExcluded() (bool, error) {
-	return isExcluded(c.hostCollector.Exclude)
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
 }
 
 func (c *CollectHostTime) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
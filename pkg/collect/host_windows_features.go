@@ -0,0 +1,137 @@
+package collect
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+)
+
+const (
+	WindowsFeatureUnknown        = "unknown"
+	WindowsFeatureEnabled        = "enabled"
+	WindowsFeatureDisabled       = "disabled"
+	WindowsFeatureEnablePending  = "enablePending"
+	WindowsFeatureDisablePending = "disablePending"
+	WindowsFeatureRemoved        = "removed"
+)
+
+type WindowsFeatureState string
+
+type WindowsFeatureInfo struct {
+	State WindowsFeatureState `json:"state"`
+}
+
+const HostWindowsFeaturesPath = `host-collectors/system/windows_features.json`
+
+// windowsFeatureCollector defines the interface used to list the features of
+// a Windows host.
+type windowsFeatureCollector interface {
+	collect() (map[string]WindowsFeatureInfo, error)
+}
+
+// CollectHostWindowsFeatures is responsible for collecting the state of the
+// optional features and server roles of a Windows host, such as Hyper-V and
+// Containers.
+type CollectHostWindowsFeatures struct {
+	hostCollector *troubleshootv1beta2.HostWindowsFeatures
+	BundlePath    string
+	features      windowsFeatureCollector
+}
+
+// Title is the name of the collector.
+func (c *CollectHostWindowsFeatures) Title() string {
+	return hostCollectorTitleOrDefault(c.hostCollector.HostCollectorMeta, "Windows Features")
+}
+
+// IsExcluded returns true if the collector has been excluded from the results.
+func (c *CollectHostWindowsFeatures) IsExcluded() (bool, error) {
+	return isExcludedWhen(c.hostCollector.Exclude, c.hostCollector.When)
+}
+
+// Collect the feature states from the host.  Features are returned as a map
+// keyed on the feature name, e.g:
+//
+//	{
+//	  "system/windows_features.json": {
+//	    ...
+//	    "Containers": {
+//	      "state": "enabled"
+//	    },
+//	    "Microsoft-Hyper-V": {
+//	      "state": "disabled"
+//	    },
+//	    ...
+//	  },
+//	}
+//
+// Feature state may be: enabled, disabled, enablePending, disablePending,
+// removed or unknown.  Optional features are named as Get-WindowsOptionalFeature
+// names them, and server roles and features as Get-WindowsFeature does.
+func (c *CollectHostWindowsFeatures) Collect(progressChan chan<- interface{}) (map[string][]byte, error) {
+	features, err := c.features.collect()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read windows features")
+	}
+
+	b, err := json.Marshal(features)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal windows features")
+	}
+
+	output := NewResult()
+	output.SaveResult(c.BundlePath, HostWindowsFeaturesPath, bytes.NewBuffer(b))
+
+	return map[string][]byte{
+		HostWindowsFeaturesPath: b,
+	}, nil
+}
+
+// windowsFeature is a feature as PowerShell writes it with ConvertTo-Json, with
+// the state converted to a string.
+type windowsFeature struct {
+	Name  string `json:"Name"`
+	State string `json:"State"`
+}
+
+// parseWindowsFeatures adds the features from the output of PowerShell to
+// features.
+func parseWindowsFeatures(b []byte, features map[string]WindowsFeatureInfo) error {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return nil
+	}
+
+	var list []windowsFeature
+	if err := json.Unmarshal(b, &list); err != nil {
+		return errors.Wrap(err, "failed to parse features")
+	}
+	for _, feature := range list {
+		if feature.Name == "" {
+			continue
+		}
+		features[feature.Name] = WindowsFeatureInfo{State: windowsFeatureState(feature.State)}
+	}
+	return nil
+}
+
+// windowsFeatureState normalizes the FeatureState of Get-WindowsOptionalFeature
+// and the InstallState of Get-WindowsFeature.
+func windowsFeatureState(state string) WindowsFeatureState {
+	switch strings.ToLower(state) {
+	case "enabled", "installed":
+		return WindowsFeatureEnabled
+	case "disabled", "available":
+		return WindowsFeatureDisabled
+	case "enablepending", "installpending":
+		return WindowsFeatureEnablePending
+	case "disablepending", "uninstallpending":
+		return WindowsFeatureDisablePending
+	case "removed", "disabledwithpayloadremoved", "enabledwithpayloadremoved":
+		return WindowsFeatureRemoved
+	default:
+		return WindowsFeatureUnknown
+	}
+}
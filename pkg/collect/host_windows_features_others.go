@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package collect
+
+import (
+	"github.com/pkg/errors"
+)
+
+// windowsFeaturesPowerShell lists the features of the host with PowerShell.
+type windowsFeaturesPowerShell struct{}
+
+func (p windowsFeaturesPowerShell) collect() (map[string]WindowsFeatureInfo, error) {
+	return nil, errors.New("Windows features collector is only implemented for Windows")
+}
@@ -0,0 +1,63 @@
+package collect
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWindowsFeaturesCollector struct {
+	result map[string]WindowsFeatureInfo
+	err    error
+}
+
+func (m mockWindowsFeaturesCollector) collect() (map[string]WindowsFeatureInfo, error) {
+	return m.result, m.err
+}
+
+func TestCollectHostWindowsFeatures_Collect(t *testing.T) {
+	c := &CollectHostWindowsFeatures{
+		hostCollector: &troubleshootv1beta2.HostWindowsFeatures{},
+		features: mockWindowsFeaturesCollector{
+			result: map[string]WindowsFeatureInfo{
+				"Containers":        {State: WindowsFeatureEnabled},
+				"Microsoft-Hyper-V": {State: WindowsFeatureDisabled},
+			},
+		},
+	}
+	got, err := c.Collect(nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"host-collectors/system/windows_features.json": []byte(`{"Containers":{"state":"enabled"},"Microsoft-Hyper-V":{"state":"disabled"}}`),
+	}, got)
+
+	c.features = mockWindowsFeaturesCollector{err: errors.New("powershell not found")}
+	_, err = c.Collect(nil)
+	assert.Error(t, err)
+}
+
+func Test_parseWindowsFeatures(t *testing.T) {
+	features := map[string]WindowsFeatureInfo{}
+
+	optional := `[{"Name":"Microsoft-Hyper-V","State":"EnablePending"},{"Name":"Containers","State":"Disabled"},{"Name":"SMB1Protocol","State":"DisabledWithPayloadRemoved"}]`
+	require.NoError(t, parseWindowsFeatures([]byte(optional), features))
+
+	server := "[{\"Name\":\"Hyper-V\",\"State\":\"Installed\"},{\"Name\":\"Containers\",\"State\":\"Installed\"},{\"Name\":\"Web-Server\",\"State\":\"Available\"}]\r\n"
+	require.NoError(t, parseWindowsFeatures([]byte(server), features))
+
+	// client editions don't list server features
+	require.NoError(t, parseWindowsFeatures([]byte("\r\n"), features))
+
+	assert.Equal(t, map[string]WindowsFeatureInfo{
+		"Microsoft-Hyper-V": {State: WindowsFeatureEnablePending},
+		"Containers":        {State: WindowsFeatureEnabled},
+		"SMB1Protocol":      {State: WindowsFeatureRemoved},
+		"Hyper-V":           {State: WindowsFeatureEnabled},
+		"Web-Server":        {State: WindowsFeatureDisabled},
+	}, features)
+
+	assert.Error(t, parseWindowsFeatures([]byte("Get-WindowsOptionalFeature : The requested operation requires elevation."), features))
+}
@@ -0,0 +1,41 @@
+package collect
+
+import (
+	"os/exec"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// windowsOptionalFeaturesScript lists the optional features of the host, which are the features of
+	// Windows client editions, and also Microsoft-Hyper-V and Containers on Windows Server.
+	windowsOptionalFeaturesScript = `ConvertTo-Json -Compress -InputObject @(Get-WindowsOptionalFeature -Online | Select-Object @{n='Name';e={$_.FeatureName}},@{n='State';e={$_.State.ToString()}})`
+	// windowsServerFeaturesScript lists the roles and features of Windows Server, and nothing on client
+	// editions where Get-WindowsFeature does not exist.
+	windowsServerFeaturesScript = `if (Get-Command Get-WindowsFeature -ErrorAction SilentlyContinue) { ConvertTo-Json -Compress -InputObject @(Get-WindowsFeature | Select-Object Name,@{n='State';e={$_.InstallState.ToString()}}) }`
+)
+
+// windowsFeaturesPowerShell lists the features of the host with PowerShell.
+type windowsFeaturesPowerShell struct{}
+
+// collect the optional features and the server roles and features of the host.
+// Either list is enough, as listing optional features needs an elevated shell.
+func (p windowsFeaturesPowerShell) collect() (map[string]WindowsFeatureInfo, error) {
+	features := make(map[string]WindowsFeatureInfo)
+
+	optionalErr := p.run(windowsOptionalFeaturesScript, features)
+	serverErr := p.run(windowsServerFeaturesScript, features)
+	if optionalErr != nil && serverErr != nil {
+		return nil, errors.Wrapf(optionalErr, "failed to list server features: %v; failed to list optional features", serverErr)
+	}
+
+	return features, nil
+}
+
+func (p windowsFeaturesPowerShell) run(script string, features map[string]WindowsFeatureInfo) error {
+	out, err := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-Command", script).Output()
+	if err != nil {
+		return errors.Wrap(err, "failed to run powershell")
+	}
+	return parseWindowsFeatures(out, features)
+}
@@ -2,12 +2,20 @@ package collect
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
@@ -15,25 +23,66 @@ import (
 	"k8s.io/client-go/rest"
 )
 
+// maxHTTPBodySize is how much of a response body is collected
+const maxHTTPBodySize = 1024 * 1024
+
 type HTTPResponse struct {
 	Status  int               `json:"status"`
 	Body    string            `json:"body"`
 	Headers map[string]string `json:"headers"`
+	// BodyTruncated is true when the body is larger than the 1MB that is collected
+	BodyTruncated bool `json:"bodyTruncated,omitempty"`
+	// LatencyMs is the time from sending the request until the response headers were received
+	LatencyMs int64    `json:"latencyMs"`
+	TLS       *HTTPTLS `json:"tls,omitempty"`
 }
 
 type HTTPError struct {
 	Message string `json:"message"`
+	// TLS is set when the request failed after the handshake, such as when the certificate is not trusted
+	TLS *HTTPTLS `json:"tls,omitempty"`
 }
 
-var (
-	httpInsecureClient = &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		},
-	}
-)
+type HTTPTLS struct {
+	Version     string `json:"version"`
+	CipherSuite string `json:"cipherSuite"`
+	ServerName  string `json:"serverName,omitempty"`
+	// PeerCertificates is the chain sent by the server, leaf first
+	PeerCertificates []HTTPCertificate `json:"peerCertificates"`
+	// VerificationError is why the chain is not trusted for the server name. It is recorded when
+	// verification is skipped too.
+	VerificationError string `json:"verificationError,omitempty"`
+}
+
+type HTTPCertificate struct {
+	Subject      string    `json:"subject"`
+	Issuer       string    `json:"issuer"`
+	SerialNumber string    `json:"serialNumber"`
+	NotBefore    time.Time `json:"notBefore"`
+	NotAfter     time.Time `json:"notAfter"`
+	DNSNames     []string  `json:"dnsNames,omitempty"`
+	IPAddresses  []string  `json:"ipAddresses,omitempty"`
+	IsCA         bool      `json:"isCA,omitempty"`
+	SHA256       string    `json:"sha256"`
+}
+
+// httpResult is the response to a request and the details of the connection it was sent over. The tls
+// details are recorded during the handshake, so that they are known when the request fails.
+type httpResult struct {
+	response *http.Response
+	latency  time.Duration
+	err      error
+
+	mtx sync.Mutex
+	tls *HTTPTLS
+}
+
+var tlsVersions = map[uint16]string{
+	tls.VersionTLS10: "TLS 1.0",
+	tls.VersionTLS11: "TLS 1.1",
+	tls.VersionTLS12: "TLS 1.2",
+	tls.VersionTLS13: "TLS 1.3",
+}
 
 type CollectHTTP struct {
 	Collector    *troubleshootv1beta2.HTTP
@@ -41,6 +90,7 @@ type CollectHTTP struct {
 	Namespace    string
 	ClientConfig *rest.Config
 	Client       kubernetes.Interface
+	Context      context.Context
 	RBACErrors
 }
 
@@ -49,24 +99,26 @@ func (c *CollectHTTP) Title() string {
 }
 
 func (c *CollectHTTP) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 func (c *CollectHTTP) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
-	var response *http.Response
-	var err error
+	var result *httpResult
 
+	ctx := contextOrBackground(c.Context)
 	if c.Collector.Get != nil {
-		response, err = doGet(c.Collector.Get)
+		result = doGet(ctx, c.Collector.Get)
 	} else if c.Collector.Post != nil {
-		response, err = doPost(c.Collector.Post)
+		result = doPost(ctx, c.Collector.Post)
 	} else if c.Collector.Put != nil {
-		response, err = doPut(c.Collector.Put)
+		result = doPut(ctx, c.Collector.Put)
+	} else if c.Collector.Head != nil {
+		result = doHead(ctx, c.Collector.Head)
 	} else {
 		return nil, errors.New("no supported http request type")
 	}
 
-	o, err := responseToOutput(response, err)
+	o, err := responseToOutput(result)
 	if err != nil {
 		return nil, err
 	}
@@ -82,81 +134,153 @@ func (c *CollectHTTP) Collect(progressChan chan<- interface{}) (CollectorResult,
 	return output, nil
 }
 
-func doGet(get *troubleshootv1beta2.Get) (*http.Response, error) {
-	httpClient := http.DefaultClient
-	if get.InsecureSkipVerify {
-		httpClient = httpInsecureClient
-	}
+func doGet(ctx context.Context, get *troubleshootv1beta2.Get) *httpResult {
+	return doRequest(ctx, "GET", get.URL, nil, get.Headers, get.InsecureSkipVerify)
+}
+
+func doPost(ctx context.Context, post *troubleshootv1beta2.Post) *httpResult {
+	return doRequest(ctx, "POST", post.URL, strings.NewReader(post.Body), post.Headers, post.InsecureSkipVerify)
+}
+
+func doPut(ctx context.Context, put *troubleshootv1beta2.Put) *httpResult {
+	return doRequest(ctx, "PUT", put.URL, strings.NewReader(put.Body), put.Headers, put.InsecureSkipVerify)
+}
+
+func doHead(ctx context.Context, head *troubleshootv1beta2.Head) *httpResult {
+	return doRequest(ctx, "HEAD", head.URL, nil, head.Headers, head.InsecureSkipVerify)
+}
 
-	req, err := http.NewRequest("GET", get.URL, nil)
+func doRequest(ctx context.Context, method string, url string, body io.Reader, headers map[string]string, insecureSkipVerify bool) *httpResult {
+	result := &httpResult{}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
-		return nil, err
+		result.err = err
+		return result
 	}
 
-	for k, v := range get.Headers {
+	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
 
-	return httpClient.Do(req)
-}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		// the chain is verified in VerifyConnection instead, so that it is recorded when it is not trusted
+		InsecureSkipVerify: true,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			tlsOutput := tlsToOutput(state)
+			verifyErr := verifyPeerCertificates(state)
+			if verifyErr != nil {
+				tlsOutput.VerificationError = verifyErr.Error()
+			}
 
-func doPost(post *troubleshootv1beta2.Post) (*http.Response, error) {
-	httpClient := http.DefaultClient
-	if post.InsecureSkipVerify {
-		httpClient = httpInsecureClient
+			result.mtx.Lock()
+			result.tls = tlsOutput
+			result.mtx.Unlock()
+
+			if verifyErr != nil && !insecureSkipVerify {
+				return verifyErr
+			}
+			return nil
+		},
 	}
+	defer transport.CloseIdleConnections()
 
-	req, err := http.NewRequest("POST", post.URL, strings.NewReader(post.Body))
-	if err != nil {
-		return nil, err
+	start := time.Now()
+	result.response, result.err = (&http.Client{Transport: transport}).Do(req)
+	result.latency = time.Since(start)
+
+	return result
+}
+
+func verifyPeerCertificates(state tls.ConnectionState) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("no peer certificates")
 	}
 
-	for k, v := range post.Headers {
-		req.Header.Set(k, v)
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
 	}
 
-	return httpClient.Do(req)
+	_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       state.ServerName,
+		Intermediates: intermediates,
+	})
+	return err
 }
 
-func doPut(put *troubleshootv1beta2.Put) (*http.Response, error) {
-	httpClient := http.DefaultClient
-	if put.InsecureSkipVerify {
-		httpClient = httpInsecureClient
+func tlsToOutput(state tls.ConnectionState) *HTTPTLS {
+	version, ok := tlsVersions[state.Version]
+	if !ok {
+		version = fmt.Sprintf("0x%04x", state.Version)
 	}
 
-	req, err := http.NewRequest("PUT", put.URL, strings.NewReader(put.Body))
-	if err != nil {
-		return nil, err
+	output := &HTTPTLS{
+		Version:          version,
+		CipherSuite:      tls.CipherSuiteName(state.CipherSuite),
+		ServerName:       state.ServerName,
+		PeerCertificates: []HTTPCertificate{},
 	}
 
-	for k, v := range put.Headers {
-		req.Header.Set(k, v)
+	for _, cert := range state.PeerCertificates {
+		ipAddresses := []string{}
+		for _, ip := range cert.IPAddresses {
+			ipAddresses = append(ipAddresses, ip.String())
+		}
+		fingerprint := sha256.Sum256(cert.Raw)
+
+		output.PeerCertificates = append(output.PeerCertificates, HTTPCertificate{
+			Subject:      cert.Subject.String(),
+			Issuer:       cert.Issuer.String(),
+			SerialNumber: cert.SerialNumber.String(),
+			NotBefore:    cert.NotBefore,
+			NotAfter:     cert.NotAfter,
+			DNSNames:     cert.DNSNames,
+			IPAddresses:  ipAddresses,
+			IsCA:         cert.IsCA,
+			SHA256:       hex.EncodeToString(fingerprint[:]),
+		})
 	}
 
-	return httpClient.Do(req)
+	return output
 }
 
-func responseToOutput(response *http.Response, err error) ([]byte, error) {
+func responseToOutput(result *httpResult) ([]byte, error) {
+	result.mtx.Lock()
+	tlsOutput := result.tls
+	result.mtx.Unlock()
+
 	output := make(map[string]interface{})
-	if err != nil {
+	if result.err != nil {
 		output["error"] = HTTPError{
-			Message: err.Error(),
+			Message: result.err.Error(),
+			TLS:     tlsOutput,
 		}
 	} else {
-		body, err := ioutil.ReadAll(response.Body)
+		defer result.response.Body.Close()
+
+		body, err := ioutil.ReadAll(io.LimitReader(result.response.Body, maxHTTPBodySize+1))
 		if err != nil {
 			return nil, err
 		}
+		truncated := len(body) > maxHTTPBodySize
+		if truncated {
+			body = body[:maxHTTPBodySize]
+		}
 
 		headers := make(map[string]string)
-		for k, v := range response.Header {
+		for k, v := range result.response.Header {
 			headers[k] = strings.Join(v, ",")
 		}
 
 		output["response"] = HTTPResponse{
-			Status:  response.StatusCode,
-			Body:    string(body),
-			Headers: headers,
+			Status:        result.response.StatusCode,
+			Body:          string(body),
+			Headers:       headers,
+			BodyTruncated: truncated,
+			LatencyMs:     result.latency.Milliseconds(),
+			TLS:           tlsOutput,
 		}
 	}
 
@@ -0,0 +1,136 @@
+package collect
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testHTTPOutput struct {
+	Error    *HTTPError    `json:"error"`
+	Response *HTTPResponse `json:"response"`
+}
+
+func TestCollectHTTP_Collect(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Method", r.Method)
+		if r.URL.Path == "/large" {
+			w.Write([]byte(strings.Repeat("a", maxHTTPBodySize+10)))
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		collector *troubleshootv1beta2.HTTP
+		wantErr   string
+		wantBody  string
+		truncated bool
+	}{
+		{
+			name: "get without verification",
+			collector: &troubleshootv1beta2.HTTP{
+				Get: &troubleshootv1beta2.Get{URL: server.URL, InsecureSkipVerify: true},
+			},
+			wantBody: "ok",
+		},
+		{
+			name: "head without verification",
+			collector: &troubleshootv1beta2.HTTP{
+				Head: &troubleshootv1beta2.Head{URL: server.URL, InsecureSkipVerify: true},
+			},
+			wantBody: "",
+		},
+		{
+			name: "truncated body",
+			collector: &troubleshootv1beta2.HTTP{
+				Post: &troubleshootv1beta2.Post{URL: server.URL + "/large", InsecureSkipVerify: true},
+			},
+			wantBody:  strings.Repeat("a", maxHTTPBodySize),
+			truncated: true,
+		},
+		{
+			name: "untrusted certificate",
+			collector: &troubleshootv1beta2.HTTP{
+				Get: &troubleshootv1beta2.Get{URL: server.URL},
+			},
+			wantErr: "x509",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &CollectHTTP{
+				Collector:  tt.collector,
+				BundlePath: t.TempDir(),
+				Context:    context.Background(),
+			}
+
+			result, err := c.Collect(nil)
+			require.NoError(t, err)
+
+			b, err := result.ReadResult(c.BundlePath, "result.json")
+			require.NoError(t, err)
+			var output testHTTPOutput
+			require.NoError(t, json.Unmarshal(b, &output))
+
+			var tlsOutput *HTTPTLS
+			if tt.wantErr != "" {
+				require.NotNil(t, output.Error)
+				assert.Contains(t, output.Error.Message, tt.wantErr)
+				tlsOutput = output.Error.TLS
+			} else {
+				require.NotNil(t, output.Response)
+				assert.Equal(t, http.StatusOK, output.Response.Status)
+				assert.Equal(t, tt.wantBody, output.Response.Body)
+				assert.Equal(t, tt.truncated, output.Response.BodyTruncated)
+				tlsOutput = output.Response.TLS
+			}
+
+			// the chain is recorded whether or not it is trusted
+			require.NotNil(t, tlsOutput)
+			assert.Contains(t, tlsOutput.VerificationError, "x509")
+			require.Len(t, tlsOutput.PeerCertificates, 1)
+			cert := server.Certificate()
+			assert.Equal(t, cert.Subject.String(), tlsOutput.PeerCertificates[0].Subject)
+			assert.True(t, cert.NotAfter.Equal(tlsOutput.PeerCertificates[0].NotAfter))
+			assert.Contains(t, tlsOutput.PeerCertificates[0].IPAddresses, "127.0.0.1")
+			assert.Len(t, tlsOutput.PeerCertificates[0].SHA256, 64)
+		})
+	}
+}
+
+func TestCollectHTTP_CollectPlainHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	c := &CollectHTTP{
+		Collector: &troubleshootv1beta2.HTTP{
+			CollectorMeta: troubleshootv1beta2.CollectorMeta{CollectorName: "api"},
+			Name:          "http",
+			Get:           &troubleshootv1beta2.Get{URL: server.URL},
+		},
+		BundlePath: t.TempDir(),
+	}
+
+	result, err := c.Collect(nil)
+	require.NoError(t, err)
+
+	b, err := result.ReadResult(c.BundlePath, "http/api.json")
+	require.NoError(t, err)
+	var output testHTTPOutput
+	require.NoError(t, json.Unmarshal(b, &output))
+	require.NotNil(t, output.Response)
+	assert.Equal(t, http.StatusNoContent, output.Response.Status)
+	assert.Nil(t, output.Response.TLS)
+}
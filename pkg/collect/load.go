@@ -6,6 +6,7 @@ import (
 	"github.com/replicatedhq/troubleshoot/pkg/client/troubleshootclientset/scheme"
 	troubleshootclientsetscheme "github.com/replicatedhq/troubleshoot/pkg/client/troubleshootclientset/scheme"
 	"github.com/replicatedhq/troubleshoot/pkg/docrewrite"
+	"github.com/replicatedhq/troubleshoot/pkg/strictdecode"
 )
 
 func ParseCollectorFromDoc(doc []byte) (*troubleshootv1beta2.Collector, error) {
@@ -15,7 +16,7 @@ func ParseCollectorFromDoc(doc []byte) (*troubleshootv1beta2.Collector, error) {
 	}
 
 	troubleshootclientsetscheme.AddToScheme(scheme.Scheme)
-	decode := scheme.Codecs.UniversalDeserializer().Decode
+	decode := strictdecode.NewDecoder(scheme.Codecs.UniversalDeserializer()).Decode
 
 	obj, _, err := decode(doc, nil, nil)
 	if err != nil {
@@ -37,7 +38,7 @@ func ParseHostCollectorFromDoc(doc []byte) (*troubleshootv1beta2.HostCollector,
 	}
 
 	troubleshootclientsetscheme.AddToScheme(scheme.Scheme)
-	decode := scheme.Codecs.UniversalDeserializer().Decode
+	decode := strictdecode.NewDecoder(scheme.Codecs.UniversalDeserializer()).Decode
 
 	obj, _, err := decode(doc, nil, nil)
 	if err != nil {
@@ -59,7 +60,7 @@ func ParseRemoteCollectorFromDoc(doc []byte) (*troubleshootv1beta2.RemoteCollect
 	}
 
 	troubleshootclientsetscheme.AddToScheme(scheme.Scheme)
-	decode := scheme.Codecs.UniversalDeserializer().Decode
+	decode := strictdecode.NewDecoder(scheme.Codecs.UniversalDeserializer()).Decode
 
 	obj, _, err := decode(doc, nil, nil)
 	if err != nil {
@@ -1,21 +1,46 @@
 package collect
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
 	"github.com/replicatedhq/troubleshoot/pkg/logger"
+	"github.com/replicatedhq/troubleshoot/pkg/multitype"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
+// defaultLogMaxLines is how many lines of a log are collected when the limits don't say otherwise
+const defaultLogMaxLines = 10000
+
+// maxConcurrentLogStreams limits how many container logs are streamed at once
+var maxConcurrentLogStreams = 10
+
+// logStreamBytesPerSecond limits how fast each container log is read, so that streaming many logs at
+// once does not overload the kubelets serving them
+var logStreamBytesPerSecond = 20 * 1024 * 1024
+
+// The logs of a container that are collected: its current logs, the logs of its previous terminated
+// container, which is what a crash looping container last logged before crashing, or both
+const (
+	logsCurrent  = "current"
+	logsPrevious = "previous"
+	logsAll      = "all"
+)
+
 type CollectLogs struct {
 	Collector    *troubleshootv1beta2.Logs
 	BundlePath   string
@@ -32,18 +57,18 @@ func (c *CollectLogs) Title() string {
 }
 
 func (c *CollectLogs) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 func (c *CollectLogs) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
-	client, err := kubernetes.NewForConfig(c.ClientConfig)
+	client, err := kubernetesClientOrDefault(c.Client, c.ClientConfig)
 	if err != nil {
 		return nil, err
 	}
 
 	output := NewResult()
 
-	ctx := context.Background()
+	ctx := contextOrBackground(c.Context)
 
 	if c.SinceTime != nil {
 		if c.Collector.Limits == nil {
@@ -52,66 +77,144 @@ func (c *CollectLogs) Collect(progressChan chan<- interface{}) (CollectorResult,
 		c.Collector.Limits.SinceTime = metav1.NewTime(*c.SinceTime)
 	}
 
+	logs, err := logsToCollect(c.Collector.Previous)
+	if err != nil {
+		return nil, err
+	}
+
 	pods, podsErrors := listPodsInSelectors(ctx, client, c.Collector.Namespace, c.Collector.Selector)
 	if len(podsErrors) > 0 {
 		output.SaveResult(c.BundlePath, getLogsErrorsFileName(c.Collector), marshalErrors(podsErrors))
 	}
 
-	if len(pods) > 0 {
-		for _, pod := range pods {
-			if len(c.Collector.ContainerNames) == 0 {
-				// make a list of all the containers in the pod, so that we can get logs from all of them
-				containerNames := []string{}
-				for _, container := range pod.Spec.Containers {
-					containerNames = append(containerNames, container.Name)
-				}
-				for _, container := range pod.Spec.InitContainers {
-					containerNames = append(containerNames, container.Name)
-				}
+	streams := []logStream{}
+	for _, pod := range pods {
+		if len(c.Collector.ContainerNames) == 0 {
+			// make a list of all the containers in the pod, so that we can get logs from all of them
+			containerNames := []string{}
+			for _, container := range pod.Spec.Containers {
+				containerNames = append(containerNames, container.Name)
+			}
+			for _, container := range pod.Spec.InitContainers {
+				containerNames = append(containerNames, container.Name)
+			}
 
-				for _, containerName := range containerNames {
-					if len(containerNames) == 1 {
-						containerName = "" // if there was only one container, use the old behavior of not including the container name in the path
-					}
-					podLogs, err := savePodLogs(ctx, c.BundlePath, client, pod, c.Collector.Name, containerName, c.Collector.Limits, false)
-					if err != nil {
-						key := fmt.Sprintf("%s/%s-errors.json", c.Collector.Name, pod.Name)
-						if containerName != "" {
-							key = fmt.Sprintf("%s/%s/%s-errors.json", c.Collector.Name, pod.Name, containerName)
-						}
-						err := output.SaveResult(c.BundlePath, key, marshalErrors([]string{err.Error()}))
-						if err != nil {
-							return nil, err
-						}
-						continue
-					}
-					for k, v := range podLogs {
-						output[k] = v
-					}
+			for _, containerName := range containerNames {
+				if len(containerNames) == 1 {
+					containerName = "" // if there was only one container, use the old behavior of not including the container name in the path
 				}
-			} else {
-				for _, container := range c.Collector.ContainerNames {
-					containerLogs, err := savePodLogs(ctx, c.BundlePath, client, pod, c.Collector.Name, container, c.Collector.Limits, false)
-					if err != nil {
-						key := fmt.Sprintf("%s/%s/%s-errors.json", c.Collector.Name, pod.Name, container)
-						err := output.SaveResult(c.BundlePath, key, marshalErrors([]string{err.Error()}))
-						if err != nil {
-							return nil, err
-						}
-						continue
-					}
-					for k, v := range containerLogs {
-						output[k] = v
-					}
+				key := fmt.Sprintf("%s/%s-errors.json", c.Collector.Name, pod.Name)
+				if containerName != "" {
+					key = fmt.Sprintf("%s/%s/%s-errors.json", c.Collector.Name, pod.Name, containerName)
 				}
+				streams = append(streams, logStream{pod: pod, container: containerName, errorsKey: key})
+			}
+		} else {
+			for _, container := range c.Collector.ContainerNames {
+				key := fmt.Sprintf("%s/%s/%s-errors.json", c.Collector.Name, pod.Name, container)
+				streams = append(streams, logStream{pod: pod, container: container, errorsKey: key})
 			}
 		}
 	}
 
+	err = saveLogStreams(c.BundlePath, output, streams, func(stream logStream) (CollectorResult, error) {
+		return savePodLogs(ctx, c.BundlePath, client, stream.pod, c.Collector.Name, stream.container, c.Collector.Limits, logs, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return output, nil
 }
 
-func listPodsInSelectors(ctx context.Context, client *kubernetes.Clientset, namespace string, selector []string) ([]corev1.Pod, []string) {
+// logsToCollect returns which logs the previous option of a Logs collector collects
+func logsToCollect(previous *multitype.BoolOrString) (string, error) {
+	if previous == nil {
+		return logsAll, nil
+	}
+	if previous.Type == multitype.String && previous.StrVal == logsAll {
+		return logsAll, nil
+	}
+
+	collectPrevious, err := previous.Bool()
+	if err != nil {
+		return "", errors.Errorf("invalid previous %q, must be true, false or %s", previous.String(), logsAll)
+	}
+	if collectPrevious {
+		return logsPrevious, nil
+	}
+	return logsCurrent, nil
+}
+
+type logStream struct {
+	pod       corev1.Pod
+	container string
+	// errorsKey is where errors getting the logs are saved
+	errorsKey string
+}
+
+// saveLogStreams runs saveLogs for each stream concurrently and merges the results into output. Errors
+// getting logs are saved to the stream's errorsKey rather than stopping the others.
+func saveLogStreams(bundlePath string, output CollectorResult, streams []logStream, saveLogs func(logStream) (CollectorResult, error)) error {
+	var mtx sync.Mutex
+	g := errgroup.Group{}
+	g.SetLimit(maxConcurrentLogStreams)
+
+	for _, stream := range streams {
+		stream := stream
+		g.Go(func() error {
+			logs, err := saveLogs(stream)
+
+			mtx.Lock()
+			defer mtx.Unlock()
+
+			if err != nil {
+				return output.SaveResult(bundlePath, stream.errorsKey, marshalErrors([]string{err.Error()}))
+			}
+			for k, v := range logs {
+				output[k] = v
+			}
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
+
+// rateLimitedReader limits how fast r is read
+type rateLimitedReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *rate.Limiter
+}
+
+func newRateLimitedReader(ctx context.Context, r io.Reader, bytesPerSecond int) io.Reader {
+	if bytesPerSecond <= 0 {
+		return r
+	}
+	return &rateLimitedReader{
+		ctx: ctx,
+		r:   r,
+		// allow bursts of up to a second of data
+		limiter: rate.NewLimiter(rate.Limit(bytesPerSecond), bytesPerSecond),
+	}
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	if len(p) > r.limiter.Burst() {
+		p = p[:r.limiter.Burst()]
+	}
+
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+func listPodsInSelectors(ctx context.Context, client kubernetes.Interface, namespace string, selector []string) ([]corev1.Pod, []string) {
 	serializedLabelSelector := strings.Join(selector, ",")
 
 	listOptions := metav1.ListOptions{
@@ -126,7 +229,9 @@ func listPodsInSelectors(ctx context.Context, client *kubernetes.Clientset, name
 	return pods.Items, nil
 }
 
-func savePodLogs(ctx context.Context, bundlePath string, client *kubernetes.Clientset, pod corev1.Pod, name, container string, limits *troubleshootv1beta2.LogLimits, follow bool) (CollectorResult, error) {
+// savePodLogs saves the current logs of a container, the logs of its previous terminated container, or
+// both. When both are collected, the previous logs are only saved if there are any.
+func savePodLogs(ctx context.Context, bundlePath string, client kubernetes.Interface, pod corev1.Pod, name, container string, limits *troubleshootv1beta2.LogLimits, logs string, follow bool) (CollectorResult, error) {
 	podLogOpts := corev1.PodLogOptions{
 		Follow:    follow,
 		Container: container,
@@ -141,45 +246,51 @@ func savePodLogs(ctx context.Context, bundlePath string, client *kubernetes.Clie
 
 	result := NewResult()
 
-	req := client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &podLogOpts)
-	podLogs, err := req.Stream(ctx)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get log stream")
-	}
-	defer podLogs.Close()
+	if logs != logsPrevious {
+		podLogs, err := client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &podLogOpts).Stream(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get log stream")
+		}
+		defer podLogs.Close()
 
-	logWriter, err := result.GetWriter(bundlePath, fileKey+".log")
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get log writer")
+		if err := savePodLog(ctx, result, bundlePath, podLogs, limits, fileKey); err != nil {
+			return nil, err
+		}
 	}
-	defer result.CloseWriter(bundlePath, fileKey+".log", logWriter)
 
-	_, err = io.Copy(logWriter, podLogs)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to copy log")
-	}
+	if logs != logsCurrent {
+		podLogOpts.Previous = true
+		podLogs, err := client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &podLogOpts).Stream(ctx)
+		if err != nil {
+			if logs == logsPrevious {
+				return nil, errors.Wrap(err, "failed to get previous log stream")
+			}
+			// maybe fail on !kuberneteserrors.IsNotFound(err)?
+			return result, nil
+		}
+		defer podLogs.Close()
 
-	podLogOpts.Previous = true
-	req = client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &podLogOpts)
-	podLogs, err = req.Stream(ctx)
-	if err != nil {
-		// maybe fail on !kuberneteserrors.IsNotFound(err)?
-		return result, nil
+		if err := savePodLog(ctx, result, bundlePath, podLogs, limits, fileKey+"-previous"); err != nil {
+			return nil, err
+		}
 	}
-	defer podLogs.Close()
 
-	prevLogWriter, err := result.GetWriter(bundlePath, fileKey+"-previous.log")
+	return result, nil
+}
+
+// savePodLog saves a container log to fileKey.log, and how it was truncated to fileKey-truncated.json
+func savePodLog(ctx context.Context, result CollectorResult, bundlePath string, podLogs io.Reader, limits *troubleshootv1beta2.LogLimits, fileKey string) error {
+	logWriter, err := result.GetWriter(bundlePath, fileKey+".log")
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get previous log writer")
+		return errors.Wrap(err, "failed to get log writer")
 	}
-	defer result.CloseWriter(bundlePath, fileKey+"-previous.log", logWriter)
+	defer result.CloseWriter(bundlePath, fileKey+".log", logWriter)
 
-	_, err = io.Copy(prevLogWriter, podLogs)
+	truncated, err := copyLog(logWriter, newRateLimitedReader(ctx, podLogs, logStreamBytesPerSecond), limits)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to copy previous log")
+		return errors.Wrap(err, "failed to copy log")
 	}
-
-	return result, nil
+	return saveLogTruncated(result, bundlePath, fileKey+"-truncated.json", truncated)
 }
 
 func convertMaxAgeToTime(maxAge string) *metav1.Time {
@@ -201,7 +312,7 @@ func setLogLimits(podLogOpts *corev1.PodLogOptions, limits *troubleshootv1beta2.
 		return
 	}
 
-	defaultMaxLines := int64(10000)
+	defaultMaxLines := int64(defaultLogMaxLines)
 	if limits == nil {
 		podLogOpts.TailLines = &defaultMaxLines
 		return
@@ -209,11 +320,26 @@ func setLogLimits(podLogOpts *corev1.PodLogOptions, limits *troubleshootv1beta2.
 
 	if !limits.SinceTime.IsZero() {
 		podLogOpts.SinceTime = &limits.SinceTime
+	} else if limits.MaxAge != "" {
+		podLogOpts.SinceTime = maxAgeParser(limits.MaxAge)
+	}
+
+	if truncatesLogs(limits) {
+		// lines are counted by copyLog, and the kubelet sends one more line or byte than is kept so that
+		// it knows when a log was truncated
+		maxLines := truncatedLogMaxLines(limits)
+		switch {
+		case logTruncation(limits) == logTruncationHead && limits.MaxBytes > 0:
+			limitBytes := limits.MaxBytes + 1
+			podLogOpts.LimitBytes = &limitBytes
+		case logTruncation(limits) == logTruncationTail && maxLines > 0:
+			tailLines := maxLines + 1
+			podLogOpts.TailLines = &tailLines
+		}
 		return
 	}
 
-	if limits.MaxAge != "" {
-		podLogOpts.SinceTime = maxAgeParser(limits.MaxAge)
+	if !limits.SinceTime.IsZero() || limits.MaxAge != "" {
 		return
 	}
 
@@ -224,6 +350,146 @@ func setLogLimits(podLogOpts *corev1.PodLogOptions, limits *troubleshootv1beta2.
 	}
 }
 
+const (
+	logTruncationHead = "head"
+	logTruncationTail = "tail"
+)
+
+// logTruncated is saved next to a log that was truncated to its limits
+type logTruncated struct {
+	Truncation   string `json:"truncation"`
+	MaxLines     int64  `json:"maxLines,omitempty"`
+	MaxBytes     int64  `json:"maxBytes,omitempty"`
+	DroppedLines int64  `json:"droppedLines"`
+	DroppedBytes int64  `json:"droppedBytes"`
+	// DroppedUnknown is true when more than DroppedLines and DroppedBytes may have been dropped, as
+	// reading stopped at the limits or the kubelet only sent the end of the log
+	DroppedUnknown bool `json:"droppedUnknown,omitempty"`
+}
+
+// truncatesLogs returns true if logs are truncated as they are read, rather than by the kubelet with
+// tailLines, so that what was dropped can be counted
+func truncatesLogs(limits *troubleshootv1beta2.LogLimits) bool {
+	return limits != nil && (limits.MaxBytes > 0 || limits.Truncation != "")
+}
+
+// logTruncation is which end of a log is kept by limits, tail by default
+func logTruncation(limits *troubleshootv1beta2.LogLimits) string {
+	if limits.Truncation == "" {
+		return logTruncationTail
+	}
+	return limits.Truncation
+}
+
+// truncatedLogMaxLines is how many lines of a log that is truncated as it is read are kept, or 0 for
+// no limit. The default limit applies unless the log is limited by time, or from its head by size.
+func truncatedLogMaxLines(limits *troubleshootv1beta2.LogLimits) int64 {
+	switch {
+	case limits.MaxLines > 0:
+		return limits.MaxLines
+	case !limits.SinceTime.IsZero() || limits.MaxAge != "":
+		return 0
+	case logTruncation(limits) == logTruncationHead && limits.MaxBytes > 0:
+		return 0
+	}
+	return defaultLogMaxLines
+}
+
+// copyLog copies the log in r to w. When logs are truncated as they are read, only the whole lines
+// within the limits from the start or the end of the log are copied, and what was dropped is returned.
+// From the head of a log, reading stops at the first line that is dropped. Nothing is returned for
+// logs that were not truncated.
+func copyLog(w io.Writer, r io.Reader, limits *troubleshootv1beta2.LogLimits) (*logTruncated, error) {
+	if !truncatesLogs(limits) {
+		_, err := io.Copy(w, r)
+		return nil, err
+	}
+
+	truncation := logTruncation(limits)
+	if truncation != logTruncationHead && truncation != logTruncationTail {
+		return nil, errors.Errorf("invalid truncation %q, must be %s or %s", truncation, logTruncationHead, logTruncationTail)
+	}
+
+	maxLines := truncatedLogMaxLines(limits)
+	truncated := &logTruncated{
+		Truncation: truncation,
+		MaxLines:   maxLines,
+		MaxBytes:   limits.MaxBytes,
+	}
+	fits := func(lines int64, size int64) bool {
+		return (maxLines == 0 || lines <= maxLines) && (limits.MaxBytes == 0 || size <= limits.MaxBytes)
+	}
+
+	var kept [][]byte
+	var readLines, keptLines, keptBytes int64
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			readLines++
+			switch truncation {
+			case logTruncationHead:
+				if !fits(keptLines+1, keptBytes+int64(len(line))) {
+					truncated.DroppedLines++
+					truncated.DroppedBytes += int64(len(line))
+					truncated.DroppedUnknown = true
+					return truncated, nil
+				}
+				if _, err := w.Write(line); err != nil {
+					return nil, err
+				}
+				keptLines++
+				keptBytes += int64(len(line))
+			case logTruncationTail:
+				kept = append(kept, line)
+				keptLines++
+				keptBytes += int64(len(line))
+				for len(kept) > 0 && !fits(keptLines, keptBytes) {
+					truncated.DroppedLines++
+					truncated.DroppedBytes += int64(len(kept[0]))
+					keptLines--
+					keptBytes -= int64(len(kept[0]))
+					kept = kept[1:]
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, line := range kept {
+		if _, err := w.Write(line); err != nil {
+			return nil, err
+		}
+	}
+
+	if truncated.DroppedLines == 0 {
+		return nil, nil
+	}
+	// the kubelet sends one more line than is kept, and drops the lines before them
+	truncated.DroppedUnknown = maxLines > 0 && readLines > maxLines
+	return truncated, nil
+}
+
+func saveLogTruncated(result CollectorResult, bundlePath string, relativePath string, truncated *logTruncated) error {
+	if truncated == nil {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(truncated, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal truncation")
+	}
+	if err := result.SaveResult(bundlePath, relativePath, bytes.NewBuffer(b)); err != nil {
+		return errors.Wrap(err, "failed to save truncation")
+	}
+	return nil
+}
+
 func getLogsErrorsFileName(logsCollector *troubleshootv1beta2.Logs) string {
 	if len(logsCollector.Name) > 0 {
 		return fmt.Sprintf("%s/errors.json", logsCollector.Name)
@@ -231,5 +497,6 @@ func getLogsErrorsFileName(logsCollector *troubleshootv1beta2.Logs) string {
 		return fmt.Sprintf("%s/errors.json", logsCollector.CollectorName)
 	}
 	// TODO: random part
-	return "errors.json"
+	// not errors.json, which is the error manifest at the root of the bundle
+	return "logs-errors.json"
 }
@@ -1,14 +1,26 @@
 package collect
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
 	"time"
 
+	"github.com/pkg/errors"
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/multitype"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
 )
 
 func Test_setLogLimits(t *testing.T) {
@@ -52,6 +64,45 @@ func Test_setLogLimits(t *testing.T) {
 				SinceTime: &sinceWhen,
 			},
 		},
+		{
+			name: "truncated while read",
+			limits: &troubleshootv1beta2.LogLimits{
+				MaxLines:   customLines,
+				Truncation: "head",
+			},
+			expected: corev1.PodLogOptions{},
+		},
+		{
+			name: "head by bytes",
+			limits: &troubleshootv1beta2.LogLimits{
+				MaxBytes:   1000,
+				Truncation: "head",
+			},
+			expected: corev1.PodLogOptions{
+				LimitBytes: pointer.Int64(1001),
+			},
+		},
+		{
+			name: "tail by bytes",
+			limits: &troubleshootv1beta2.LogLimits{
+				MaxBytes: 1000,
+			},
+			expected: corev1.PodLogOptions{
+				TailLines: pointer.Int64(10001),
+			},
+		},
+		{
+			name: "tail by lines since",
+			limits: &troubleshootv1beta2.LogLimits{
+				MaxLines:   customLines,
+				MaxAge:     maxAge,
+				Truncation: "tail",
+			},
+			expected: corev1.PodLogOptions{
+				SinceTime: &sinceWhen,
+				TailLines: pointer.Int64(21),
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -74,6 +125,202 @@ func Test_setLogLimits(t *testing.T) {
 			} else {
 				req.Nil(actual.SinceTime)
 			}
+
+			if test.expected.LimitBytes != nil {
+				req.NotNil(actual.LimitBytes)
+				assert.Equal(t, *test.expected.LimitBytes, *actual.LimitBytes)
+			} else {
+				req.Nil(actual.LimitBytes)
+			}
+		})
+	}
+}
+
+func Test_copyLog(t *testing.T) {
+	log := "line 1\nline 2\nline 3\nline 4\n"
+
+	tests := []struct {
+		name          string
+		limits        *troubleshootv1beta2.LogLimits
+		stopsReading  bool
+		wantLog       string
+		wantTruncated *logTruncated
+		wantErr       bool
+	}{
+		{
+			name:    "not truncated while read",
+			limits:  &troubleshootv1beta2.LogLimits{MaxLines: 2},
+			wantLog: log,
+		},
+		{
+			name:    "within limits",
+			limits:  &troubleshootv1beta2.LogLimits{MaxBytes: 100},
+			wantLog: log,
+		},
+		{
+			name:          "tail by lines",
+			limits:        &troubleshootv1beta2.LogLimits{MaxLines: 3, Truncation: "tail"},
+			wantLog:       "line 2\nline 3\nline 4\n",
+			wantTruncated: &logTruncated{Truncation: "tail", MaxLines: 3, DroppedLines: 1, DroppedBytes: 7, DroppedUnknown: true},
+		},
+		{
+			name:          "tail by bytes",
+			limits:        &troubleshootv1beta2.LogLimits{MaxBytes: 16},
+			wantLog:       "line 3\nline 4\n",
+			wantTruncated: &logTruncated{Truncation: "tail", MaxLines: 10000, MaxBytes: 16, DroppedLines: 2, DroppedBytes: 14},
+		},
+		{
+			name:          "head by lines",
+			limits:        &troubleshootv1beta2.LogLimits{MaxLines: 1, Truncation: "head"},
+			stopsReading:  true,
+			wantLog:       "line 1\n",
+			wantTruncated: &logTruncated{Truncation: "head", MaxLines: 1, DroppedLines: 1, DroppedBytes: 7, DroppedUnknown: true},
+		},
+		{
+			name:          "head by bytes",
+			limits:        &troubleshootv1beta2.LogLimits{MaxBytes: 20, Truncation: "head"},
+			stopsReading:  true,
+			wantLog:       "line 1\nline 2\n",
+			wantTruncated: &logTruncated{Truncation: "head", MaxBytes: 20, DroppedLines: 1, DroppedBytes: 7, DroppedUnknown: true},
+		},
+		{
+			name:    "invalid truncation",
+			limits:  &troubleshootv1beta2.LogLimits{Truncation: "middle"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r io.Reader = strings.NewReader(log)
+			if tt.stopsReading {
+				r = io.MultiReader(r, iotest.ErrReader(errors.New("read past the limits")))
+			}
+
+			var w bytes.Buffer
+			truncated, err := copyLog(&w, r, tt.limits)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLog, w.String())
+			assert.Equal(t, tt.wantTruncated, truncated)
+		})
+	}
+}
+
+func Test_saveLogStreams(t *testing.T) {
+	streams := []logStream{}
+	for i := 0; i < 25; i++ {
+		pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i)}}
+		streams = append(streams, logStream{pod: pod, errorsKey: fmt.Sprintf("logs/%s-errors.json", pod.Name)})
+	}
+
+	var mtx sync.Mutex
+	running, maxRunning := 0, 0
+
+	output := NewResult()
+	err := saveLogStreams("", output, streams, func(stream logStream) (CollectorResult, error) {
+		mtx.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mtx.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mtx.Lock()
+		running--
+		mtx.Unlock()
+
+		if stream.pod.Name == "pod-3" {
+			return nil, errors.New("container is waiting to start")
+		}
+
+		result := NewResult()
+		result.SaveResult("", fmt.Sprintf("logs/%s.log", stream.pod.Name), bytes.NewBufferString(stream.pod.Name))
+		return result, nil
+	})
+	require.NoError(t, err)
+
+	assert.Greater(t, maxRunning, 1)
+	assert.LessOrEqual(t, maxRunning, maxConcurrentLogStreams)
+
+	assert.Len(t, output, 25)
+	assert.Equal(t, "pod-0", string(output["logs/pod-0.log"]))
+	assert.Contains(t, string(output["logs/pod-3-errors.json"]), "container is waiting to start")
+}
+
+func Test_newRateLimitedReader(t *testing.T) {
+	input := strings.Repeat("a", 1500)
+
+	// the first second of data is allowed as a burst, the rest is limited
+	start := time.Now()
+	b, err := ioutil.ReadAll(newRateLimitedReader(context.Background(), strings.NewReader(input), 1000))
+	require.NoError(t, err)
+	assert.Equal(t, input, string(b))
+	assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+
+	r := strings.NewReader(input)
+	assert.Equal(t, r, newRateLimitedReader(context.Background(), r, 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ioutil.ReadAll(newRateLimitedReader(ctx, strings.NewReader(input), 1000))
+	assert.Error(t, err)
+}
+
+func Test_logsToCollect(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous *multitype.BoolOrString
+		want     string
+		wantErr  bool
+	}{
+		{name: "not set", previous: nil, want: logsAll},
+		{name: "true", previous: multitype.FromBool(true), want: logsPrevious},
+		{name: "false", previous: multitype.FromBool(false), want: logsCurrent},
+		{name: "true string", previous: multitype.FromString("true"), want: logsPrevious},
+		{name: "all", previous: multitype.FromString("all"), want: logsAll},
+		{name: "invalid", previous: multitype.FromString("some"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := logsToCollect(tt.previous)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_savePodLogs(t *testing.T) {
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+
+	tests := []struct {
+		logs      string
+		wantFiles []string
+	}{
+		{logs: logsCurrent, wantFiles: []string{"logs/web/app.log"}},
+		{logs: logsPrevious, wantFiles: []string{"logs/web/app-previous.log"}},
+		{logs: logsAll, wantFiles: []string{"logs/web/app-previous.log", "logs/web/app.log"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.logs, func(t *testing.T) {
+			client := fake.NewSimpleClientset(&pod)
+
+			result, err := savePodLogs(context.Background(), "", client, pod, "logs", "app", nil, tt.logs, false)
+			require.NoError(t, err)
+
+			files := []string{}
+			for file := range result {
+				files = append(files, file)
+			}
+			assert.ElementsMatch(t, tt.wantFiles, files)
 		})
 	}
 }
@@ -44,7 +44,7 @@ func (c *CollectLonghorn) Title() string {
 }
 
 func (c *CollectLonghorn) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 func (c *CollectLonghorn) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
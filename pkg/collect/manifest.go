@@ -0,0 +1,109 @@
+package collect
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ManifestFilename is the integrity manifest of a support bundle archive. It is written last, so it has
+// the digest of every other file in the archive.
+const ManifestFilename = "manifest.json"
+
+type BundleManifest struct {
+	Files []ManifestFile `json:"files"`
+}
+
+type ManifestFile struct {
+	Path        string      `json:"path"`
+	Size        int64       `json:"size"`
+	SHA256      string      `json:"sha256"`
+	ContentType ContentType `json:"contentType,omitempty"`
+}
+
+// manifestWriter hashes files as they are written to an archive
+type manifestWriter struct {
+	files        []ManifestFile
+	contentTypes ContentTypes
+}
+
+func (m *manifestWriter) add(relativePath string, size int64, h hash.Hash) {
+	m.files = append(m.files, ManifestFile{
+		Path:        filepath.ToSlash(relativePath),
+		Size:        size,
+		SHA256:      hex.EncodeToString(h.Sum(nil)),
+		ContentType: m.contentTypes[relativePath],
+	})
+}
+
+func (m *manifestWriter) marshal() ([]byte, error) {
+	sort.Slice(m.files, func(i, j int) bool {
+		return m.files[i].Path < m.files[j].Path
+	})
+
+	b, err := json.MarshalIndent(BundleManifest{Files: m.files}, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal manifest")
+	}
+	return b, nil
+}
+
+// VerifyBundleManifest checks the files of an extracted support bundle against its manifest, so that
+// archives that were corrupted or modified after collection are detected. Bundles without a manifest,
+// which were collected by older versions, are not checked.
+func VerifyBundleManifest(bundleDir string) error {
+	b, err := ioutil.ReadFile(filepath.Join(bundleDir, ManifestFilename))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to read manifest")
+	}
+
+	var manifest BundleManifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return errors.Wrap(err, "failed to unmarshal manifest")
+	}
+
+	invalidFiles := []string{}
+	for _, file := range manifest.Files {
+		digest, err := fileSHA256(filepath.Join(bundleDir, filepath.FromSlash(file.Path)))
+		if os.IsNotExist(errors.Cause(err)) {
+			invalidFiles = append(invalidFiles, file.Path+" is missing")
+			continue
+		} else if err != nil {
+			return errors.Wrapf(err, "failed to hash %s", file.Path)
+		}
+
+		if digest != file.SHA256 {
+			invalidFiles = append(invalidFiles, file.Path+" does not match its checksum")
+		}
+	}
+
+	if len(invalidFiles) > 0 {
+		return errors.Errorf("support bundle failed integrity check: %s", strings.Join(invalidFiles, ", "))
+	}
+	return nil
+}
+
+func fileSHA256(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open file")
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", errors.Wrap(err, "failed to read file")
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
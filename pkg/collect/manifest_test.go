@@ -0,0 +1,212 @@
+package collect
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func extractArchive(t *testing.T, r io.Reader, dir string) {
+	gzReader, err := gzip.NewReader(r)
+	require.NoError(t, err)
+
+	tarReader := tar.NewReader(gzReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return
+		}
+		require.NoError(t, err)
+
+		filename := filepath.Join(dir, header.Name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755))
+		b, err := ioutil.ReadAll(tarReader)
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(filename, b, 0644))
+	}
+}
+
+func TestWriteSupportBundleArchive_Manifest(t *testing.T) {
+	req := require.New(t)
+
+	bundlePath := filepath.Join(t.TempDir(), "support-bundle")
+	result := NewResult()
+	files := map[string]string{
+		"version.yaml":                 "apiVersion: troubleshoot.sh/v1beta2\n",
+		"cluster-resources/nodes.json": `{"items": []}`,
+		ManifestFilename:               "stale",
+	}
+	for name, contents := range files {
+		req.NoError(result.SaveResult(bundlePath, name, bytes.NewBufferString(contents)))
+	}
+
+	metadata := NewCollectionMetadata(time.Now())
+	metadata.ContentTypes["cluster-resources/nodes.json"] = ContentTypeK8sJSON
+	b, err := json.Marshal(metadata)
+	req.NoError(err)
+	req.NoError(result.SaveResult(bundlePath, CollectionMetadataFilename, bytes.NewBuffer(b)))
+
+	var archive bytes.Buffer
+	req.NoError(WriteSupportBundleArchive(bundlePath, result, &archive))
+
+	extractDir := t.TempDir()
+	extractArchive(t, &archive, extractDir)
+	rootDir := filepath.Join(extractDir, "support-bundle")
+
+	b, err = ioutil.ReadFile(filepath.Join(rootDir, ManifestFilename))
+	req.NoError(err)
+	var manifest BundleManifest
+	req.NoError(json.Unmarshal(b, &manifest))
+
+	digest := sha256.Sum256([]byte(`{"items": []}`))
+	req.Len(manifest.Files, 3)
+	assert.Equal(t, ManifestFile{
+		Path:        "cluster-resources/nodes.json",
+		Size:        13,
+		SHA256:      hex.EncodeToString(digest[:]),
+		ContentType: ContentTypeK8sJSON,
+	}, manifest.Files[0])
+	assert.Equal(t, CollectionMetadataFilename, manifest.Files[1].Path)
+	assert.Equal(t, "version.yaml", manifest.Files[2].Path)
+
+	req.NoError(VerifyBundleManifest(rootDir))
+
+	req.NoError(ioutil.WriteFile(filepath.Join(rootDir, "cluster-resources/nodes.json"), []byte(`{"items": [{}]}`), 0644))
+	err = VerifyBundleManifest(rootDir)
+	req.Error(err)
+	assert.Contains(t, err.Error(), "cluster-resources/nodes.json does not match its checksum")
+
+	// bundles without a manifest are not checked
+	req.NoError(VerifyBundleManifest(t.TempDir()))
+}
+
+func TestWriteSupportBundleDir(t *testing.T) {
+	req := require.New(t)
+
+	bundlePath := filepath.Join(t.TempDir(), "support-bundle")
+	result := NewResult()
+	files := map[string]string{
+		"version.yaml":                 "apiVersion: troubleshoot.sh/v1beta2\n",
+		"cluster-resources/nodes.json": `{"items": []}`,
+		ManifestFilename:               "stale",
+	}
+	for name, contents := range files {
+		req.NoError(result.SaveResult(bundlePath, name, bytes.NewBufferString(contents)))
+	}
+
+	outputDir := filepath.Join(t.TempDir(), "bundle")
+	req.NoError(WriteSupportBundleDir(bundlePath, result, outputDir))
+
+	b, err := ioutil.ReadFile(filepath.Join(outputDir, "cluster-resources/nodes.json"))
+	req.NoError(err)
+	assert.Equal(t, `{"items": []}`, string(b))
+
+	b, err = ioutil.ReadFile(filepath.Join(outputDir, ManifestFilename))
+	req.NoError(err)
+	var manifest BundleManifest
+	req.NoError(json.Unmarshal(b, &manifest))
+	req.Len(manifest.Files, 2)
+	assert.Equal(t, "cluster-resources/nodes.json", manifest.Files[0].Path)
+	assert.Equal(t, "version.yaml", manifest.Files[1].Path)
+
+	req.NoError(VerifyBundleManifest(outputDir))
+
+	// files of an earlier bundle are not overwritten or mixed in
+	err = WriteSupportBundleDir(bundlePath, result, outputDir)
+	req.Error(err)
+	assert.Contains(t, err.Error(), "is not empty")
+}
+
+func TestWriteSupportBundleZip(t *testing.T) {
+	req := require.New(t)
+
+	bundlePath := filepath.Join(t.TempDir(), "support-bundle")
+	result := NewResult()
+	files := map[string]string{
+		"version.yaml":                 "apiVersion: troubleshoot.sh/v1beta2\n",
+		"cluster-resources/nodes.json": `{"items": []}`,
+		ManifestFilename:               "stale",
+	}
+	for name, contents := range files {
+		req.NoError(result.SaveResult(bundlePath, name, bytes.NewBufferString(contents)))
+	}
+
+	var archive bytes.Buffer
+	req.NoError(WriteSupportBundleZip(bundlePath, result, &archive, ArchiveOptions{}))
+
+	zipReader, err := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	req.NoError(err)
+	extractDir := t.TempDir()
+	names := []string{}
+	for _, f := range zipReader.File {
+		names = append(names, f.Name)
+
+		r, err := f.Open()
+		req.NoError(err)
+		b, err := ioutil.ReadAll(r)
+		req.NoError(err)
+		r.Close()
+
+		filename := filepath.Join(extractDir, filepath.FromSlash(f.Name))
+		req.NoError(os.MkdirAll(filepath.Dir(filename), 0755))
+		req.NoError(ioutil.WriteFile(filename, b, 0644))
+	}
+	assert.Equal(t, []string{
+		"support-bundle/cluster-resources/nodes.json",
+		"support-bundle/version.yaml",
+		"support-bundle/" + ManifestFilename,
+	}, names)
+
+	req.NoError(VerifyBundleManifest(filepath.Join(extractDir, "support-bundle")))
+}
+
+func TestWriteSupportBundleArchive_Reproducible(t *testing.T) {
+	req := require.New(t)
+
+	write := func(name string, modTime time.Time, mode os.FileMode) ([]byte, []byte) {
+		bundlePath := filepath.Join(t.TempDir(), name)
+		result := NewResult()
+		files := map[string]string{
+			"version.yaml":                 "apiVersion: troubleshoot.sh/v1beta2\n",
+			"cluster-resources/nodes.json": `{"items": []}`,
+		}
+		for name, contents := range files {
+			req.NoError(result.SaveResult(bundlePath, name, bytes.NewBufferString(contents)))
+			req.NoError(os.Chtimes(filepath.Join(bundlePath, name), modTime, modTime))
+			req.NoError(os.Chmod(filepath.Join(bundlePath, name), mode))
+		}
+
+		var archive, zipArchive bytes.Buffer
+		req.NoError(WriteSupportBundleArchiveWithOptions(bundlePath, result, &archive, ArchiveOptions{Reproducible: true}))
+		req.NoError(WriteSupportBundleZip(bundlePath, result, &zipArchive, ArchiveOptions{Reproducible: true}))
+		return archive.Bytes(), zipArchive.Bytes()
+	}
+
+	archive, zipArchive := write("support-bundle-2022-10-01T12_00_00", time.Now(), 0644)
+	laterArchive, laterZipArchive := write("support-bundle-2022-10-01T13_00_00", time.Now().Add(time.Hour), 0600)
+	assert.Equal(t, archive, laterArchive)
+	assert.Equal(t, zipArchive, laterZipArchive)
+
+	gzReader, err := gzip.NewReader(bytes.NewReader(archive))
+	req.NoError(err)
+	tarReader := tar.NewReader(gzReader)
+	header, err := tarReader.Next()
+	req.NoError(err)
+	assert.Equal(t, "support-bundle/cluster-resources/nodes.json", header.Name)
+	assert.True(t, header.ModTime.Equal(time.Unix(0, 0)))
+	assert.Equal(t, 0, header.Uid)
+	assert.Equal(t, "", header.Uname)
+}
@@ -0,0 +1,198 @@
+package collect
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+const CollectionMetadataFilename = "collection-metadata.json"
+
+// CollectionMetadata records when a bundle was collected. Collection can take a long time on large
+// clusters, so each collector's results are stamped with when it ran relative to CollectionEpoch.
+type CollectionMetadata struct {
+	// CollectionEpoch is when collection started, and is the same for every collector in the bundle
+	CollectionEpoch time.Time           `json:"collectionEpoch"`
+	Collectors      []CollectorMetadata `json:"collectors"`
+	// ContentTypes are the content types that collectors tagged their files with, by file
+	ContentTypes ContentTypes `json:"contentTypes,omitempty"`
+	// Impersonation is the identity the bundle was collected as, when collection impersonated a user
+	// with --as
+	Impersonation *CollectionImpersonation `json:"impersonation,omitempty"`
+	// Throttling is how the API server throttled collection, when it did. A bundle with skipped
+	// collectors is partial.
+	Throttling *CollectionThrottling `json:"throttling,omitempty"`
+	// APIRequests are the requests that collectors made to the API server
+	APIRequests *CollectionAPIRequests `json:"apiRequests,omitempty"`
+}
+
+type CollectionImpersonation struct {
+	UserName string   `json:"userName"`
+	UID      string   `json:"uid,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+}
+
+type CollectionThrottling struct {
+	ThrottledRequests int `json:"throttledRequests"`
+	// SkippedCollectors are the low priority collectors that were deferred and not run
+	SkippedCollectors []string `json:"skippedCollectors,omitempty"`
+}
+
+type CollectionAPIRequests struct {
+	Requests int `json:"requests"`
+	// Errors are the requests that failed to get a response, or got a 5xx response
+	Errors int `json:"errors,omitempty"`
+}
+
+type CollectorMetadata struct {
+	Title           string    `json:"title"`
+	CollectionEpoch time.Time `json:"collectionEpoch"`
+	StartTime       time.Time `json:"startTime"`
+	EndTime         time.Time `json:"endTime"`
+	// ResourceVersions are the resourceVersions of the lists returned by the API server, by file. They
+	// are captured on a best-effort basis from the collected files.
+	ResourceVersions map[string]string `json:"resourceVersions,omitempty"`
+	// Files are the files the collector collected, so that they can be replaced when it is run again
+	Files []string `json:"files,omitempty"`
+}
+
+func NewCollectionMetadata(epoch time.Time) *CollectionMetadata {
+	return &CollectionMetadata{
+		CollectionEpoch: epoch,
+		Collectors:      []CollectorMetadata{},
+		ContentTypes:    ContentTypes{},
+	}
+}
+
+// AddCollector stamps the results of a collector that ran from startTime until endTime
+func (m *CollectionMetadata) AddCollector(title string, startTime time.Time, endTime time.Time, bundlePath string, result CollectorResult) {
+	m.Collectors = append(m.Collectors, CollectorMetadata{
+		Title:            title,
+		CollectionEpoch:  m.CollectionEpoch,
+		StartTime:        startTime,
+		EndTime:          endTime,
+		ResourceVersions: listResourceVersions(bundlePath, result),
+		Files:            result.sortedNames(),
+	})
+}
+
+// ReplaceCollector stamps the results of a collector that was run again, in place of those of the times
+// it ran before
+func (m *CollectionMetadata) ReplaceCollector(title string, startTime time.Time, endTime time.Time, bundlePath string, result CollectorResult) {
+	collectors := []CollectorMetadata{}
+	for _, collector := range m.Collectors {
+		if collector.Title != title {
+			collectors = append(collectors, collector)
+		}
+	}
+	m.Collectors = collectors
+	m.AddCollector(title, startTime, endTime, bundlePath, result)
+}
+
+// ClearTimes zeroes when collection and each collector ran, and orders the collectors by title rather
+// than by when they finished, so that the metadata of bundles collected from the same cluster state is
+// the same for reproducible archives
+func (m *CollectionMetadata) ClearTimes() {
+	m.CollectionEpoch = time.Time{}
+	for i := range m.Collectors {
+		m.Collectors[i].CollectionEpoch = time.Time{}
+		m.Collectors[i].StartTime = time.Time{}
+		m.Collectors[i].EndTime = time.Time{}
+	}
+	sort.SliceStable(m.Collectors, func(i, j int) bool {
+		return m.Collectors[i].Title < m.Collectors[j].Title
+	})
+}
+
+// CollectorFiles returns the files that the collectors with title collected. It is empty for bundles
+// collected by versions that did not record them.
+func (m *CollectionMetadata) CollectorFiles(title string) []string {
+	files := []string{}
+	for _, collector := range m.Collectors {
+		if collector.Title == title {
+			files = append(files, collector.Files...)
+		}
+	}
+	return files
+}
+
+// SetImpersonation records the identity of an impersonating client config, so that a bundle collected
+// with restricted permissions shows which identity collected it
+func (m *CollectionMetadata) SetImpersonation(impersonate rest.ImpersonationConfig) {
+	if impersonate.UserName == "" {
+		m.Impersonation = nil
+		return
+	}
+
+	m.Impersonation = &CollectionImpersonation{
+		UserName: impersonate.UserName,
+		UID:      impersonate.UID,
+		Groups:   impersonate.Groups,
+	}
+}
+
+// SetThrottling records how many requests the API server throttled during collection, and the
+// collectors that were skipped because of it
+func (m *CollectionMetadata) SetThrottling(throttledRequests int, skippedCollectors []string) {
+	if throttledRequests == 0 && len(skippedCollectors) == 0 {
+		m.Throttling = nil
+		return
+	}
+
+	m.Throttling = &CollectionThrottling{
+		ThrottledRequests: throttledRequests,
+		SkippedCollectors: skippedCollectors,
+	}
+}
+
+// SetAPIRequests records the requests that the clients of collectors made to the API server
+func (m *CollectionMetadata) SetAPIRequests(stats ClientStats) {
+	if stats.Requests == 0 {
+		m.APIRequests = nil
+		return
+	}
+
+	m.APIRequests = &CollectionAPIRequests{
+		Requests: stats.Requests,
+		Errors:   stats.Errors,
+	}
+}
+
+// listResourceVersions returns the resourceVersion of every json file in result that is a list from the
+// API server. Files that can't be read or aren't lists are skipped.
+func listResourceVersions(bundlePath string, result CollectorResult) map[string]string {
+	resourceVersions := map[string]string{}
+	for relativePath := range result {
+		if filepath.Ext(relativePath) != ".json" {
+			continue
+		}
+
+		reader, err := result.GetReader(bundlePath, relativePath)
+		if err != nil {
+			continue
+		}
+
+		var list struct {
+			Metadata struct {
+				ResourceVersion string `json:"resourceVersion"`
+			} `json:"metadata"`
+			// items are not kept, only whether the file has them
+			Items *[]struct{} `json:"items"`
+		}
+		err = json.NewDecoder(reader).Decode(&list)
+		reader.Close()
+		if err != nil || list.Items == nil || list.Metadata.ResourceVersion == "" {
+			continue
+		}
+
+		resourceVersions[relativePath] = list.Metadata.ResourceVersion
+	}
+
+	if len(resourceVersions) == 0 {
+		return nil
+	}
+	return resourceVersions
+}
@@ -0,0 +1,130 @@
+package collect
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+func TestCollectionMetadata_AddCollector(t *testing.T) {
+	bundlePath := t.TempDir()
+
+	result := NewResult()
+	files := map[string]string{
+		"cluster-resources/pods/default.json":        `{"kind": "PodList", "apiVersion": "v1", "metadata": {"resourceVersion": "1234"}, "items": []}`,
+		"cluster-resources/pods-errors.json":         `["forbidden"]`,
+		"cluster-resources/namespaces.json":          `{"kind": "NamespaceList", "metadata": {}, "items": [{"metadata": {"name": "default"}}]}`,
+		"cluster-resources/custom-resources/a.yaml":  "metadata:\n  resourceVersion: \"1\"\nitems: []\n",
+		"cluster-resources/pods/logs/default/a.json": `{"metadata": {"resourceVersion": "1"}}`,
+		"cluster-resources/nodes.json":               "not json",
+	}
+	for name, contents := range files {
+		require.NoError(t, result.SaveResult(bundlePath, name, bytes.NewBufferString(contents)))
+	}
+	// held in memory rather than on disk
+	result["cluster-resources/services/default.json"] = []byte(`{"metadata": {"resourceVersion": "5678"}, "items": []}`)
+
+	epoch := time.Now().Add(-time.Minute)
+	startTime := time.Now()
+	endTime := startTime.Add(time.Second)
+
+	metadata := NewCollectionMetadata(epoch)
+	metadata.AddCollector("cluster-resources", startTime, endTime, bundlePath, result)
+	metadata.AddCollector("cluster-info", startTime, endTime, bundlePath, NewResult())
+
+	require.Len(t, metadata.Collectors, 2)
+
+	got := metadata.Collectors[0]
+	assert.Equal(t, "cluster-resources", got.Title)
+	assert.Equal(t, epoch, got.CollectionEpoch)
+	assert.Equal(t, startTime, got.StartTime)
+	assert.Equal(t, endTime, got.EndTime)
+	assert.Equal(t, map[string]string{
+		"cluster-resources/pods/default.json":     "1234",
+		"cluster-resources/services/default.json": "5678",
+	}, got.ResourceVersions)
+
+	assert.Equal(t, epoch, metadata.Collectors[1].CollectionEpoch)
+	assert.Nil(t, metadata.Collectors[1].ResourceVersions)
+}
+
+func TestCollectionMetadata_SetImpersonation(t *testing.T) {
+	metadata := NewCollectionMetadata(time.Now())
+
+	metadata.SetImpersonation(rest.ImpersonationConfig{})
+	assert.Nil(t, metadata.Impersonation)
+
+	metadata.SetImpersonation(rest.ImpersonationConfig{
+		UserName: "system:serviceaccount:default:support-bundle",
+		Groups:   []string{"system:authenticated"},
+	})
+	assert.Equal(t, &CollectionImpersonation{
+		UserName: "system:serviceaccount:default:support-bundle",
+		Groups:   []string{"system:authenticated"},
+	}, metadata.Impersonation)
+}
+
+func TestCollectionMetadata_SetThrottling(t *testing.T) {
+	metadata := NewCollectionMetadata(time.Now())
+
+	metadata.SetThrottling(0, []string{})
+	assert.Nil(t, metadata.Throttling)
+
+	metadata.SetThrottling(12, []string{"logs/web"})
+	assert.Equal(t, &CollectionThrottling{
+		ThrottledRequests: 12,
+		SkippedCollectors: []string{"logs/web"},
+	}, metadata.Throttling)
+}
+
+func TestCollectionMetadata_SetAPIRequests(t *testing.T) {
+	metadata := NewCollectionMetadata(time.Now())
+
+	metadata.SetAPIRequests(ClientStats{})
+	assert.Nil(t, metadata.APIRequests)
+
+	metadata.SetAPIRequests(ClientStats{Requests: 240, Errors: 2})
+	assert.Equal(t, &CollectionAPIRequests{Requests: 240, Errors: 2}, metadata.APIRequests)
+}
+
+func TestCollectionMetadata_ReplaceCollector(t *testing.T) {
+	bundlePath := t.TempDir()
+	epoch := time.Now().Add(-time.Minute)
+
+	metadata := NewCollectionMetadata(epoch)
+	metadata.AddCollector("logs/app", epoch, epoch, bundlePath, CollectorResult{"app/a.log": nil, "app/b.log": nil})
+	metadata.AddCollector("cluster-info", epoch, epoch, bundlePath, CollectorResult{"cluster-info/cluster_version.json": nil})
+	assert.Equal(t, []string{"app/a.log", "app/b.log"}, metadata.CollectorFiles("logs/app"))
+
+	startTime := time.Now()
+	metadata.ReplaceCollector("logs/app", startTime, startTime, bundlePath, CollectorResult{"app/a.log": nil})
+
+	require.Len(t, metadata.Collectors, 2)
+	assert.Equal(t, "cluster-info", metadata.Collectors[0].Title)
+	assert.Equal(t, startTime, metadata.Collectors[1].StartTime)
+	assert.Equal(t, []string{"app/a.log"}, metadata.CollectorFiles("logs/app"))
+	assert.Empty(t, metadata.CollectorFiles("logs/other"))
+}
+
+func TestCollectionMetadata_ClearTimes(t *testing.T) {
+	epoch := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	metadata := NewCollectionMetadata(epoch)
+	metadata.AddCollector("pods", epoch.Add(time.Second), epoch.Add(2*time.Second), "", NewResult())
+	metadata.AddCollector("cluster-info", epoch, epoch.Add(time.Second), "", NewResult())
+
+	metadata.ClearTimes()
+
+	assert.True(t, metadata.CollectionEpoch.IsZero())
+	require.Len(t, metadata.Collectors, 2)
+	assert.Equal(t, "cluster-info", metadata.Collectors[0].Title)
+	assert.Equal(t, "pods", metadata.Collectors[1].Title)
+	for _, collector := range metadata.Collectors {
+		assert.True(t, collector.CollectionEpoch.IsZero())
+		assert.True(t, collector.StartTime.IsZero())
+		assert.True(t, collector.EndTime.IsZero())
+	}
+}
@@ -29,7 +29,7 @@ func (c *CollectMysql) Title() string {
 }
 
 func (c *CollectMysql) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 func (c *CollectMysql) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
@@ -40,8 +40,9 @@ func (c *CollectMysql) Collect(progressChan chan<- interface{}) (CollectorResult
 		databaseConnection.Error = err.Error()
 	} else {
 		defer db.Close()
+		ctx := contextOrBackground(c.Context)
 		query := `select version()`
-		row := db.QueryRow(query)
+		row := db.QueryRowContext(ctx, query)
 
 		version := ""
 		if err := row.Scan(&version); err != nil {
@@ -49,11 +50,15 @@ func (c *CollectMysql) Collect(progressChan chan<- interface{}) (CollectorResult
 		} else {
 			databaseConnection.IsConnected = true
 			databaseConnection.Version = version
+
+			if c.Collector.Topology != nil {
+				databaseConnection.Topology = collectDatabaseTopology(ctx, mysqlTopologyDriver, c.Collector.URI, db, c.Collector.Topology)
+			}
 		}
 
 		requestedParameters := c.Collector.Parameters
 		if len(requestedParameters) > 0 {
-			rows, err := db.Query("SHOW VARIABLES")
+			rows, err := db.QueryContext(ctx, "SHOW VARIABLES")
 
 			if err != nil {
 				databaseConnection.Error = err.Error()
@@ -0,0 +1,154 @@
+package collect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// NodeStatsSummary is the part of the kubelet's stats summary with the usage of filesystems, in the
+// format of the summary API
+type NodeStatsSummary struct {
+	Node NodeStatsNode  `json:"node"`
+	Pods []NodeStatsPod `json:"pods"`
+}
+
+type NodeStatsNode struct {
+	NodeName string `json:"nodeName"`
+	// Fs is the filesystem of the kubelet's root directory, which holds emptyDir volumes and logs
+	Fs      *NodeStatsFs      `json:"fs,omitempty"`
+	Runtime *NodeStatsRuntime `json:"runtime,omitempty"`
+}
+
+type NodeStatsRuntime struct {
+	// ImageFs is the filesystem of the container runtime's images
+	ImageFs *NodeStatsFs `json:"imageFs,omitempty"`
+	// ContainerFs is the filesystem of the writable layers of containers, when it is not ImageFs
+	ContainerFs *NodeStatsFs `json:"containerFs,omitempty"`
+}
+
+type NodeStatsFs struct {
+	Time           metav1.Time `json:"time"`
+	AvailableBytes *uint64     `json:"availableBytes,omitempty"`
+	CapacityBytes  *uint64     `json:"capacityBytes,omitempty"`
+	UsedBytes      *uint64     `json:"usedBytes,omitempty"`
+	InodesFree     *uint64     `json:"inodesFree,omitempty"`
+	Inodes         *uint64     `json:"inodes,omitempty"`
+	InodesUsed     *uint64     `json:"inodesUsed,omitempty"`
+}
+
+type NodeStatsPod struct {
+	PodRef NodeStatsPodReference `json:"podRef"`
+	// EphemeralStorage is the usage of the pod's emptyDir volumes, logs and writable layers, which is
+	// what the kubelet evicts pods for exceeding their ephemeral-storage limits by
+	EphemeralStorage *NodeStatsFs      `json:"ephemeral-storage,omitempty"`
+	Volumes          []NodeStatsVolume `json:"volume,omitempty"`
+}
+
+type NodeStatsPodReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	UID       string `json:"uid"`
+}
+
+type NodeStatsVolume struct {
+	NodeStatsFs `json:",inline"`
+	Name        string                 `json:"name"`
+	PVCRef      *NodeStatsPVCReference `json:"pvcRef,omitempty"`
+}
+
+type NodeStatsPVCReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+type CollectNodeStats struct {
+	Collector    *troubleshootv1beta2.NodeStats
+	BundlePath   string
+	Namespace    string
+	ClientConfig *rest.Config
+	Client       kubernetes.Interface
+	Context      context.Context
+	RBACErrors
+}
+
+func (c *CollectNodeStats) Title() string {
+	return getCollectorName(c)
+}
+
+func (c *CollectNodeStats) IsExcluded() (bool, error) {
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
+}
+
+func (c *CollectNodeStats) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
+	getSummary := func(ctx context.Context, nodeName string) ([]byte, error) {
+		return c.Client.CoreV1().RESTClient().Get().
+			AbsPath("/api/v1/nodes", nodeName, "proxy", "stats", "summary").
+			DoRaw(ctx)
+	}
+
+	return c.collect(contextOrBackground(c.Context), c.Client, getSummary), nil
+}
+
+func (c *CollectNodeStats) collect(ctx context.Context, client kubernetes.Interface, getSummary func(context.Context, string) ([]byte, error)) CollectorResult {
+	output := NewResult()
+
+	dir := "node-stats"
+	if c.Collector.CollectorName != "" {
+		dir = path.Join(dir, c.Collector.CollectorName)
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{
+		LabelSelector: strings.Join(c.Collector.Selector, ","),
+	})
+	if err != nil {
+		output.SaveResult(c.BundlePath, path.Join(dir, "errors.json"), marshalErrors([]string{err.Error()}))
+		return output
+	}
+
+	errorList := map[string]string{}
+	for _, node := range nodes.Items {
+		summary, err := nodeStatsSummary(ctx, node.Name, getSummary)
+		if err != nil {
+			errorList[node.Name] = err.Error()
+			continue
+		}
+
+		b, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			errorList[node.Name] = err.Error()
+			continue
+		}
+		output.SaveResult(c.BundlePath, path.Join(dir, node.Name+".json"), bytes.NewBuffer(b))
+	}
+	output.SaveResult(c.BundlePath, path.Join(dir, "errors.json"), marshalErrors(errorList))
+
+	return output
+}
+
+// nodeStatsSummary gets the stats summary of the node from its kubelet, without the cpu, memory and
+// network stats of the node and its pods
+func nodeStatsSummary(ctx context.Context, nodeName string, getSummary func(context.Context, string) ([]byte, error)) (*NodeStatsSummary, error) {
+	b, err := getSummary(ctx, nodeName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get stats summary")
+	}
+
+	summary := &NodeStatsSummary{}
+	if err := json.Unmarshal(b, summary); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal stats summary")
+	}
+	if summary.Pods == nil {
+		summary.Pods = []NodeStatsPod{}
+	}
+
+	return summary, nil
+}
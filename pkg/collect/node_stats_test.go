@@ -0,0 +1,82 @@
+package collect
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testNodeStatsSummary = `{
+  "node": {
+    "nodeName": "node-1",
+    "cpu": {"time": "2023-01-01T00:00:00Z", "usageNanoCores": 100},
+    "fs": {"time": "2023-01-01T00:00:00Z", "availableBytes": 100, "capacityBytes": 1000, "usedBytes": 900, "inodesFree": 10, "inodes": 100, "inodesUsed": 90},
+    "runtime": {"imageFs": {"time": "2023-01-01T00:00:00Z", "availableBytes": 500, "capacityBytes": 1000, "usedBytes": 500}}
+  },
+  "pods": [
+    {
+      "podRef": {"name": "web", "namespace": "default", "uid": "abc"},
+      "memory": {"time": "2023-01-01T00:00:00Z", "workingSetBytes": 100},
+      "ephemeral-storage": {"time": "2023-01-01T00:00:00Z", "usedBytes": 300},
+      "volume": [{"time": "2023-01-01T00:00:00Z", "usedBytes": 200, "name": "data", "pvcRef": {"name": "data-web", "namespace": "default"}}]
+    }
+  ]
+}`
+
+func TestCollectNodeStats_collect(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"pool": "workers"}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2", Labels: map[string]string{"pool": "workers"}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-3"}},
+	)
+	getSummary := func(ctx context.Context, nodeName string) ([]byte, error) {
+		if nodeName == "node-1" {
+			return []byte(testNodeStatsSummary), nil
+		}
+		return nil, errors.New("the server is currently unable to handle the request")
+	}
+
+	c := &CollectNodeStats{
+		Collector:  &troubleshootv1beta2.NodeStats{Selector: []string{"pool=workers"}},
+		BundlePath: t.TempDir(),
+	}
+	result := c.collect(context.Background(), client, getSummary)
+	assert.ElementsMatch(t, []string{"node-stats/node-1.json", "node-stats/errors.json"}, resultPaths(result))
+
+	b, err := result.ReadResult(c.BundlePath, "node-stats/node-1.json")
+	require.NoError(t, err)
+	var summary map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &summary))
+
+	// only filesystem stats are kept
+	assert.Equal(t, map[string]interface{}{
+		"nodeName": "node-1",
+		"fs":       map[string]interface{}{"time": "2023-01-01T00:00:00Z", "availableBytes": 100.0, "capacityBytes": 1000.0, "usedBytes": 900.0, "inodesFree": 10.0, "inodes": 100.0, "inodesUsed": 90.0},
+		"runtime": map[string]interface{}{
+			"imageFs": map[string]interface{}{"time": "2023-01-01T00:00:00Z", "availableBytes": 500.0, "capacityBytes": 1000.0, "usedBytes": 500.0},
+		},
+	}, summary["node"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"podRef":            map[string]interface{}{"name": "web", "namespace": "default", "uid": "abc"},
+			"ephemeral-storage": map[string]interface{}{"time": "2023-01-01T00:00:00Z", "usedBytes": 300.0},
+			"volume": []interface{}{
+				map[string]interface{}{"time": "2023-01-01T00:00:00Z", "usedBytes": 200.0, "name": "data", "pvcRef": map[string]interface{}{"name": "data-web", "namespace": "default"}},
+			},
+		},
+	}, summary["pods"])
+
+	b, err = result.ReadResult(c.BundlePath, "node-stats/errors.json")
+	require.NoError(t, err)
+	var errs map[string]string
+	require.NoError(t, json.Unmarshal(b, &errs))
+	assert.Equal(t, map[string]string{"node-2": "failed to get stats summary: the server is currently unable to handle the request"}, errs)
+}
@@ -0,0 +1,228 @@
+package collect
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// oidcDiscoveryPath is where the discovery document of an issuer is, relative to the issuer
+const oidcDiscoveryPath = "/.well-known/openid-configuration"
+
+// OIDCResult is what the oidc collector found
+type OIDCResult struct {
+	IssuerURL string         `json:"issuerUrl"`
+	Discovery *OIDCDiscovery `json:"discovery,omitempty"`
+	JWKS      *OIDCJWKS      `json:"jwks,omitempty"`
+	// Clock compares the time of the collector to the time of the issuer, which tokens are validated
+	// against
+	Clock OIDCClock `json:"clock"`
+	Error string    `json:"error,omitempty"`
+}
+
+type OIDCDiscovery struct {
+	URL    string `json:"url"`
+	Status int    `json:"status"`
+	// TLS is the connection to the issuer, with why its certificate is not trusted if it is not
+	TLS    *HTTPTLS `json:"tls,omitempty"`
+	Issuer string   `json:"issuer,omitempty"`
+	// IssuerMatches is true if the issuer of the document is the issuer url, which clients require
+	IssuerMatches                    bool     `json:"issuerMatches"`
+	AuthorizationEndpoint            string   `json:"authorizationEndpoint,omitempty"`
+	TokenEndpoint                    string   `json:"tokenEndpoint,omitempty"`
+	UserinfoEndpoint                 string   `json:"userinfoEndpoint,omitempty"`
+	JWKSURI                          string   `json:"jwksUri,omitempty"`
+	IDTokenSigningAlgValuesSupported []string `json:"idTokenSigningAlgValuesSupported,omitempty"`
+}
+
+type OIDCJWKS struct {
+	URL    string    `json:"url"`
+	Status int       `json:"status"`
+	Keys   []OIDCKey `json:"keys"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// OIDCKey is a public signing key of the issuer
+type OIDCKey struct {
+	KeyID     string `json:"kid,omitempty"`
+	KeyType   string `json:"kty"`
+	Algorithm string `json:"alg,omitempty"`
+	Use       string `json:"use,omitempty"`
+	// NotBefore and NotAfter are the validity of the first certificate of the key, if it has one
+	NotBefore *time.Time `json:"notBefore,omitempty"`
+	NotAfter  *time.Time `json:"notAfter,omitempty"`
+}
+
+type OIDCClock struct {
+	CollectedAt time.Time `json:"collectedAt"`
+	// ServerTime is the Date header of the issuer's response
+	ServerTime *time.Time `json:"serverTime,omitempty"`
+	// SkewSeconds is how far the issuer's clock is ahead of the collector's, to the second that the Date
+	// header has
+	SkewSeconds *float64 `json:"skewSeconds,omitempty"`
+}
+
+type CollectOIDC struct {
+	Collector    *troubleshootv1beta2.OIDC
+	BundlePath   string
+	Namespace    string
+	ClientConfig *rest.Config
+	Client       kubernetes.Interface
+	Context      context.Context
+	RBACErrors
+}
+
+func (c *CollectOIDC) Title() string {
+	return getCollectorName(c)
+}
+
+func (c *CollectOIDC) IsExcluded() (bool, error) {
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
+}
+
+func (c *CollectOIDC) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
+	ctx := contextOrBackground(c.Context)
+
+	result := OIDCResult{
+		IssuerURL: c.Collector.IssuerURL,
+		Clock:     OIDCClock{CollectedAt: time.Now().UTC()},
+	}
+
+	discovery, jwksURI, err := c.discover(ctx, &result.Clock)
+	result.Discovery = discovery
+	if err != nil {
+		result.Error = err.Error()
+	} else if jwksURI == "" {
+		result.Error = "the discovery document has no jwks_uri"
+	} else {
+		result.JWKS = c.jwks(ctx, jwksURI)
+	}
+
+	b, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal oidc result")
+	}
+
+	collectorName := c.Collector.CollectorName
+	if collectorName == "" {
+		collectorName = "oidc"
+	}
+
+	output := NewResult()
+	output.SaveResult(c.BundlePath, path.Join("oidc", collectorName+".json"), bytes.NewBuffer(b))
+
+	return output, nil
+}
+
+// discover fetches the discovery document of the issuer, and returns the url of its keys. The time of the
+// issuer is recorded in clock.
+func (c *CollectOIDC) discover(ctx context.Context, clock *OIDCClock) (*OIDCDiscovery, string, error) {
+	discovery := &OIDCDiscovery{URL: strings.TrimSuffix(c.Collector.IssuerURL, "/") + oidcDiscoveryPath}
+
+	result := doRequest(ctx, http.MethodGet, discovery.URL, nil, nil, c.Collector.InsecureSkipVerify)
+	result.mtx.Lock()
+	discovery.TLS = result.tls
+	result.mtx.Unlock()
+	if result.err != nil {
+		return discovery, "", errors.Wrap(result.err, "failed to get discovery document")
+	}
+	defer result.response.Body.Close()
+
+	discovery.Status = result.response.StatusCode
+	if serverTime, err := http.ParseTime(result.response.Header.Get("Date")); err == nil {
+		serverTime = serverTime.UTC()
+		skew := serverTime.Sub(clock.CollectedAt.Truncate(time.Second)).Seconds()
+		clock.ServerTime, clock.SkewSeconds = &serverTime, &skew
+	}
+
+	if result.response.StatusCode != http.StatusOK {
+		return discovery, "", errors.Errorf("unexpected status code %d for discovery document", result.response.StatusCode)
+	}
+
+	document := struct {
+		Issuer                           string   `json:"issuer"`
+		AuthorizationEndpoint            string   `json:"authorization_endpoint"`
+		TokenEndpoint                    string   `json:"token_endpoint"`
+		UserinfoEndpoint                 string   `json:"userinfo_endpoint"`
+		JWKSURI                          string   `json:"jwks_uri"`
+		IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+	}{}
+	if err := json.NewDecoder(io.LimitReader(result.response.Body, maxHTTPBodySize)).Decode(&document); err != nil {
+		return discovery, "", errors.Wrap(err, "failed to decode discovery document")
+	}
+
+	discovery.Issuer = document.Issuer
+	discovery.IssuerMatches = document.Issuer == c.Collector.IssuerURL
+	discovery.AuthorizationEndpoint = document.AuthorizationEndpoint
+	discovery.TokenEndpoint = document.TokenEndpoint
+	discovery.UserinfoEndpoint = document.UserinfoEndpoint
+	discovery.JWKSURI = document.JWKSURI
+	discovery.IDTokenSigningAlgValuesSupported = document.IDTokenSigningAlgValuesSupported
+
+	return discovery, document.JWKSURI, nil
+}
+
+// jwks fetches the public keys of the issuer. Errors are recorded in the keys rather than returned.
+func (c *CollectOIDC) jwks(ctx context.Context, url string) *OIDCJWKS {
+	jwks := &OIDCJWKS{URL: url, Keys: []OIDCKey{}}
+
+	result := doRequest(ctx, http.MethodGet, url, nil, nil, c.Collector.InsecureSkipVerify)
+	if result.err != nil {
+		jwks.Error = fmt.Sprintf("failed to get keys: %v", result.err)
+		return jwks
+	}
+	defer result.response.Body.Close()
+
+	jwks.Status = result.response.StatusCode
+	if result.response.StatusCode != http.StatusOK {
+		jwks.Error = fmt.Sprintf("unexpected status code %d for keys", result.response.StatusCode)
+		return jwks
+	}
+
+	keySet := struct {
+		Keys []struct {
+			KeyID     string   `json:"kid"`
+			KeyType   string   `json:"kty"`
+			Algorithm string   `json:"alg"`
+			Use       string   `json:"use"`
+			X5C       []string `json:"x5c"`
+		} `json:"keys"`
+	}{}
+	if err := json.NewDecoder(io.LimitReader(result.response.Body, maxHTTPBodySize)).Decode(&keySet); err != nil {
+		jwks.Error = fmt.Sprintf("failed to decode keys: %v", err)
+		return jwks
+	}
+
+	for _, key := range keySet.Keys {
+		oidcKey := OIDCKey{
+			KeyID:     key.KeyID,
+			KeyType:   key.KeyType,
+			Algorithm: key.Algorithm,
+			Use:       key.Use,
+		}
+		// x5c is standard base64 of the DER certificates, leaf first
+		if len(key.X5C) > 0 {
+			if der, err := base64.StdEncoding.DecodeString(key.X5C[0]); err == nil {
+				if cert, err := x509.ParseCertificate(der); err == nil {
+					oidcKey.NotBefore, oidcKey.NotAfter = &cert.NotBefore, &cert.NotAfter
+				}
+			}
+		}
+		jwks.Keys = append(jwks.Keys, oidcKey)
+	}
+
+	return jwks
+}
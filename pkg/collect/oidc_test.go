@@ -0,0 +1,110 @@
+package collect
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func oidcTestServer(t *testing.T, issuer func(serverURL string) string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/realms/app/.well-known/openid-configuration":
+			fmt.Fprintf(w, `{
+				"issuer": %q,
+				"authorization_endpoint": "%[2]s/realms/app/protocol/openid-connect/auth",
+				"token_endpoint": "%[2]s/realms/app/protocol/openid-connect/token",
+				"jwks_uri": "%[2]s/realms/app/protocol/openid-connect/certs",
+				"id_token_signing_alg_values_supported": ["RS256"]
+			}`, issuer(server.URL), server.URL)
+		case "/realms/app/protocol/openid-connect/certs":
+			x5c := base64.StdEncoding.EncodeToString(server.Certificate().Raw)
+			fmt.Fprintf(w, `{"keys": [{"kid": "abc", "kty": "RSA", "alg": "RS256", "use": "sig", "x5c": [%q]}]}`, x5c)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func collectOIDCResult(t *testing.T, collector *troubleshootv1beta2.OIDC) OIDCResult {
+	bundlePath := t.TempDir()
+	result, err := (&CollectOIDC{Collector: collector, BundlePath: bundlePath, Context: context.Background()}).Collect(nil)
+	require.NoError(t, err)
+
+	key := filepath.Join("oidc", "oidc.json")
+	require.Contains(t, result, key)
+	b, err := result.GetReader(bundlePath, key)
+	require.NoError(t, err)
+	defer b.Close()
+
+	var oidc OIDCResult
+	require.NoError(t, json.NewDecoder(b).Decode(&oidc))
+	return oidc
+}
+
+func TestCollectOIDC(t *testing.T) {
+	server := oidcTestServer(t, func(serverURL string) string { return serverURL + "/realms/app" })
+	defer server.Close()
+
+	oidc := collectOIDCResult(t, &troubleshootv1beta2.OIDC{
+		IssuerURL:          server.URL + "/realms/app",
+		InsecureSkipVerify: true,
+	})
+
+	assert.Empty(t, oidc.Error)
+	require.NotNil(t, oidc.Discovery)
+	assert.Equal(t, http.StatusOK, oidc.Discovery.Status)
+	assert.True(t, oidc.Discovery.IssuerMatches)
+	assert.Equal(t, server.URL+"/realms/app/protocol/openid-connect/token", oidc.Discovery.TokenEndpoint)
+	assert.Equal(t, []string{"RS256"}, oidc.Discovery.IDTokenSigningAlgValuesSupported)
+	// the certificate of the test server is not trusted
+	require.NotNil(t, oidc.Discovery.TLS)
+	assert.NotEmpty(t, oidc.Discovery.TLS.VerificationError)
+
+	require.NotNil(t, oidc.JWKS)
+	require.Len(t, oidc.JWKS.Keys, 1)
+	assert.Equal(t, "abc", oidc.JWKS.Keys[0].KeyID)
+	require.NotNil(t, oidc.JWKS.Keys[0].NotAfter)
+	assert.Equal(t, server.Certificate().NotAfter.UTC(), oidc.JWKS.Keys[0].NotAfter.UTC())
+
+	require.NotNil(t, oidc.Clock.SkewSeconds)
+	assert.InDelta(t, 0, *oidc.Clock.SkewSeconds, 2)
+}
+
+func TestCollectOIDC_IssuerMismatch(t *testing.T) {
+	server := oidcTestServer(t, func(string) string { return "https://keycloak.example.com/realms/app" })
+	defer server.Close()
+
+	oidc := collectOIDCResult(t, &troubleshootv1beta2.OIDC{
+		IssuerURL:          server.URL + "/realms/app",
+		InsecureSkipVerify: true,
+	})
+
+	require.NotNil(t, oidc.Discovery)
+	assert.False(t, oidc.Discovery.IssuerMatches)
+	assert.Equal(t, "https://keycloak.example.com/realms/app", oidc.Discovery.Issuer)
+}
+
+func TestCollectOIDC_UntrustedCertificate(t *testing.T) {
+	server := oidcTestServer(t, func(serverURL string) string { return serverURL + "/realms/app" })
+	defer server.Close()
+
+	oidc := collectOIDCResult(t, &troubleshootv1beta2.OIDC{IssuerURL: server.URL + "/realms/app"})
+
+	assert.Contains(t, oidc.Error, "failed to get discovery document")
+	assert.Nil(t, oidc.JWKS)
+	require.NotNil(t, oidc.Discovery)
+	require.NotNil(t, oidc.Discovery.TLS)
+	assert.NotEmpty(t, oidc.Discovery.TLS.PeerCertificates)
+}
@@ -0,0 +1,183 @@
+package collect
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/replicatedhq/troubleshoot/pkg/k8sutil"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+
+	k8sdiscovery "github.com/replicatedhq/troubleshoot/pkg/k8sutil/discovery"
+)
+
+// OpenShiftSummaryFilename is the summary of the health of an OpenShift cluster, relative to the
+// openshift directory of cluster resources
+const OpenShiftSummaryFilename = "summary.json"
+
+var (
+	openshiftRoutesGVR              = schema.GroupVersionResource{Group: "route.openshift.io", Version: "v1", Resource: "routes"}
+	openshiftClusterOperatorsGVR    = schema.GroupVersionResource{Group: "config.openshift.io", Version: "v1", Resource: "clusteroperators"}
+	openshiftClusterVersionsGVR     = schema.GroupVersionResource{Group: "config.openshift.io", Version: "v1", Resource: "clusterversions"}
+	openshiftSCCsGVR                = schema.GroupVersionResource{Group: "security.openshift.io", Version: "v1", Resource: "securitycontextconstraints"}
+	openshiftMachineConfigPoolsGVR  = schema.GroupVersionResource{Group: "machineconfiguration.openshift.io", Version: "v1", Resource: "machineconfigpools"}
+	openshiftClusterScopedResources = []schema.GroupVersionResource{
+		openshiftClusterOperatorsGVR,
+		openshiftClusterVersionsGVR,
+		openshiftSCCsGVR,
+		openshiftMachineConfigPoolsGVR,
+	}
+)
+
+// OpenShiftSummary is the state of the OpenShift install and upgrade, which fails in ways that aren't
+// visible in the built-in resources
+type OpenShiftSummary struct {
+	Version string `json:"version,omitempty"`
+	// ClusterVersionConditions are the statuses of the ClusterVersion conditions, by type
+	ClusterVersionConditions   map[string]string `json:"clusterVersionConditions,omitempty"`
+	UnavailableOperators       []string          `json:"unavailableOperators"`
+	DegradedOperators          []string          `json:"degradedOperators"`
+	ProgressingOperators       []string          `json:"progressingOperators"`
+	DegradedMachineConfigPools []string          `json:"degradedMachineConfigPools"`
+}
+
+func isOpenShift(dc discovery.DiscoveryInterface) (bool, error) {
+	return k8sdiscovery.HasResource(dc, "config.openshift.io/v1", "ClusterVersion")
+}
+
+// openshiftResources lists the OpenShift resources and writes a summary of them. Routes are listed in
+// namespaces, and resources that the cluster doesn't serve are skipped.
+func openshiftResources(ctx context.Context, client dynamic.Interface, namespaces []string) (map[string][]byte, map[string]string) {
+	resources := make(map[string][]byte)
+	errorList := make(map[string]string)
+
+	for _, namespace := range namespaces {
+		routes, err := listOpenShiftResource(ctx, client.Resource(openshiftRoutesGVR).Namespace(namespace))
+		if kuberneteserrors.IsNotFound(err) {
+			break
+		} else if err != nil {
+			errorList["routes/"+namespace] = err.Error()
+			continue
+		}
+
+		b, err := json.MarshalIndent(routes, "", "  ")
+		if err != nil {
+			errorList["routes/"+namespace] = err.Error()
+			continue
+		}
+		resources["routes/"+namespace+".json"] = b
+	}
+
+	lists := map[schema.GroupResource]*unstructured.UnstructuredList{}
+	for _, gvr := range openshiftClusterScopedResources {
+		list, err := listOpenShiftResource(ctx, client.Resource(gvr))
+		if kuberneteserrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			errorList[gvr.Resource] = err.Error()
+			continue
+		}
+		lists[gvr.GroupResource()] = list
+
+		b, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			errorList[gvr.Resource] = err.Error()
+			continue
+		}
+		resources[gvr.Resource+".json"] = b
+	}
+
+	summary := summarizeOpenShift(
+		lists[openshiftClusterVersionsGVR.GroupResource()],
+		lists[openshiftClusterOperatorsGVR.GroupResource()],
+		lists[openshiftMachineConfigPoolsGVR.GroupResource()],
+	)
+	b, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		errorList["summary"] = err.Error()
+	} else {
+		resources[OpenShiftSummaryFilename] = b
+	}
+
+	return resources, errorList
+}
+
+func listOpenShiftResource(ctx context.Context, client dynamic.ResourceInterface) (*unstructured.UnstructuredList, error) {
+	list := &unstructured.UnstructuredList{}
+	err := k8sutil.ListAll(ctx, list, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return client.List(ctx, opts)
+	})
+	return list, err
+}
+
+func summarizeOpenShift(clusterVersions, clusterOperators, machineConfigPools *unstructured.UnstructuredList) OpenShiftSummary {
+	summary := OpenShiftSummary{
+		UnavailableOperators:       []string{},
+		DegradedOperators:          []string{},
+		ProgressingOperators:       []string{},
+		DegradedMachineConfigPools: []string{},
+	}
+
+	// there is one ClusterVersion, named version
+	if clusterVersions != nil && len(clusterVersions.Items) > 0 {
+		clusterVersion := clusterVersions.Items[0]
+		summary.Version, _, _ = unstructured.NestedString(clusterVersion.Object, "status", "desired", "version")
+		summary.ClusterVersionConditions = openshiftConditions(clusterVersion)
+	}
+
+	if clusterOperators != nil {
+		for _, operator := range clusterOperators.Items {
+			conditions := openshiftConditions(operator)
+			if conditions["Available"] != "True" {
+				summary.UnavailableOperators = append(summary.UnavailableOperators, operator.GetName())
+			}
+			if conditions["Degraded"] == "True" {
+				summary.DegradedOperators = append(summary.DegradedOperators, operator.GetName())
+			}
+			if conditions["Progressing"] == "True" {
+				summary.ProgressingOperators = append(summary.ProgressingOperators, operator.GetName())
+			}
+		}
+	}
+
+	if machineConfigPools != nil {
+		for _, pool := range machineConfigPools.Items {
+			if openshiftConditions(pool)["Degraded"] == "True" {
+				summary.DegradedMachineConfigPools = append(summary.DegradedMachineConfigPools, pool.GetName())
+			}
+		}
+	}
+
+	sort.Strings(summary.UnavailableOperators)
+	sort.Strings(summary.DegradedOperators)
+	sort.Strings(summary.ProgressingOperators)
+	sort.Strings(summary.DegradedMachineConfigPools)
+
+	return summary
+}
+
+// openshiftConditions returns the statuses of an object's status conditions, by type
+func openshiftConditions(obj unstructured.Unstructured) map[string]string {
+	conditions := map[string]string{}
+
+	items, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, item := range items {
+		condition, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		status, _, _ := unstructured.NestedString(condition, "status")
+		if conditionType != "" {
+			conditions[conditionType] = status
+		}
+	}
+
+	return conditions
+}
@@ -0,0 +1,89 @@
+package collect
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func openshiftObject(apiVersion, kind, namespace, name string, status map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name": name,
+		},
+		"status": status,
+	}}
+	obj.SetNamespace(namespace)
+	return obj
+}
+
+func openshiftStatusConditions(conditions map[string]string) map[string]interface{} {
+	items := []interface{}{}
+	for conditionType, status := range conditions {
+		items = append(items, map[string]interface{}{"type": conditionType, "status": status})
+	}
+	return map[string]interface{}{"conditions": items}
+}
+
+func Test_openshiftResources(t *testing.T) {
+	req := require.New(t)
+
+	clusterVersionStatus := openshiftStatusConditions(map[string]string{"Available": "True", "Failing": "False", "Progressing": "True"})
+	clusterVersionStatus["desired"] = map[string]interface{}{"version": "4.12.3"}
+
+	objects := []runtime.Object{
+		openshiftObject("route.openshift.io/v1", "Route", "default", "console", nil),
+		openshiftObject("config.openshift.io/v1", "ClusterVersion", "", "version", clusterVersionStatus),
+		openshiftObject("config.openshift.io/v1", "ClusterOperator", "", "dns", openshiftStatusConditions(map[string]string{"Available": "True", "Degraded": "False"})),
+		openshiftObject("config.openshift.io/v1", "ClusterOperator", "", "ingress", openshiftStatusConditions(map[string]string{"Available": "False", "Degraded": "True"})),
+		openshiftObject("config.openshift.io/v1", "ClusterOperator", "", "network", openshiftStatusConditions(map[string]string{"Available": "True", "Progressing": "True"})),
+		openshiftObject("security.openshift.io/v1", "SecurityContextConstraints", "", "restricted", nil),
+		openshiftObject("machineconfiguration.openshift.io/v1", "MachineConfigPool", "", "worker", openshiftStatusConditions(map[string]string{"Degraded": "True"})),
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		openshiftRoutesGVR:             "RouteList",
+		openshiftClusterOperatorsGVR:   "ClusterOperatorList",
+		openshiftClusterVersionsGVR:    "ClusterVersionList",
+		openshiftSCCsGVR:               "SecurityContextConstraintsList",
+		openshiftMachineConfigPoolsGVR: "MachineConfigPoolList",
+	}, objects...)
+
+	resources, errorList := openshiftResources(context.Background(), client, []string{"default", "kube-system"})
+	req.Empty(errorList)
+
+	for _, filename := range []string{
+		"routes/default.json",
+		"routes/kube-system.json",
+		"clusteroperators.json",
+		"clusterversions.json",
+		"securitycontextconstraints.json",
+		"machineconfigpools.json",
+	} {
+		assert.Contains(t, resources, filename)
+	}
+
+	var routes unstructured.UnstructuredList
+	req.NoError(json.Unmarshal(resources["routes/default.json"], &routes))
+	req.Len(routes.Items, 1)
+	assert.Equal(t, "console", routes.Items[0].GetName())
+
+	var summary OpenShiftSummary
+	req.NoError(json.Unmarshal(resources[OpenShiftSummaryFilename], &summary))
+	assert.Equal(t, OpenShiftSummary{
+		Version:                    "4.12.3",
+		ClusterVersionConditions:   map[string]string{"Available": "True", "Failing": "False", "Progressing": "True"},
+		UnavailableOperators:       []string{"ingress"},
+		DegradedOperators:          []string{"ingress"},
+		ProgressingOperators:       []string{"network"},
+		DegradedMachineConfigPools: []string{"worker"},
+	}, summary)
+}
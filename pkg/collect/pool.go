@@ -0,0 +1,226 @@
+package collect
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CollectorRun is the outcome of a collector run by RunCollectors
+type CollectorRun struct {
+	Collector Collector
+	StartTime time.Time
+	EndTime   time.Time
+	Result    CollectorResult
+	Err       error
+}
+
+// RunOptions are how RunCollectorsWithOptions runs collectors
+type RunOptions struct {
+	// Concurrency is how many collectors run at a time
+	Concurrency int
+	// BeforeRun, if set, is called from the goroutine that runs each collector before it starts
+	BeforeRun func(Collector)
+	// AfterRun, if set, is called from the goroutine that ran each collector as soon as it has finished
+	AfterRun func(CollectorRun)
+	// Throttle, if set, tells when the API server is throttling requests. While it is, fewer collectors
+	// run at a time, high priority collectors start first and low priority collectors are deferred.
+	Throttle *APIThrottle
+	// DiskGuard, if set, keeps collectors from being started once the free disk space is below its reserve
+	DiskGuard *DiskGuard
+}
+
+// RunCollectors runs collectors with up to concurrency of them at a time. The runs are returned in the
+// order of collectors, so merging their results gives the same bundle as running them serially.
+// ClusterResources collectors run on their own, after the collectors before them have finished and
+// before any collector after them starts, so the pod list does not include pods started by
+// collectors. beforeRun, if set, is called from the goroutine that runs the collector.
+func RunCollectors(ctx context.Context, collectors []Collector, concurrency int, progressChan chan<- interface{}, beforeRun func(Collector)) []CollectorRun {
+	return RunCollectorsWithCallback(ctx, collectors, concurrency, progressChan, beforeRun, nil)
+}
+
+// RunCollectorsWithCallback is RunCollectors with afterRun, if set, called with each run as soon as its
+// collector has finished. Like beforeRun, it is called from the goroutine that ran the collector.
+func RunCollectorsWithCallback(ctx context.Context, collectors []Collector, concurrency int, progressChan chan<- interface{}, beforeRun func(Collector), afterRun func(CollectorRun)) []CollectorRun {
+	return RunCollectorsWithOptions(ctx, collectors, progressChan, RunOptions{
+		Concurrency: concurrency,
+		BeforeRun:   beforeRun,
+		AfterRun:    afterRun,
+	})
+}
+
+// RunCollectorsWithOptions is RunCollectors, degrading gracefully when opts.Throttle tells that the API
+// server is throttling requests. The concurrency is halved each time requests are throttled, and is
+// doubled again once they haven't been for a while. Low priority collectors are deferred until the
+// others have started, and the ones that still can't run without being throttled are not run, with
+// ErrThrottled, so that the bundle has what could be collected rather than the collection timing out.
+// Collectors that have not started when ctx is done are not run, and have its error.
+func RunCollectorsWithOptions(ctx context.Context, collectors []Collector, progressChan chan<- interface{}, opts RunOptions) []CollectorRun {
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	runs := make([]CollectorRun, len(collectors))
+	run := func(i int) {
+		c := collectors[i]
+		// collectors are not started once ctx is done
+		if err := ctx.Err(); err != nil {
+			now := time.Now()
+			runs[i] = CollectorRun{
+				Collector: c,
+				StartTime: now,
+				EndTime:   now,
+				Err:       err,
+			}
+			if opts.AfterRun != nil {
+				opts.AfterRun(runs[i])
+			}
+			return
+		}
+		// collectors are not started once the free disk space is below the reserve, and the runs of
+		// those that are not have exactly ErrInsufficientDiskSpace
+		if err := opts.DiskGuard.BeforeRun(); err != nil {
+			now := time.Now()
+			runs[i] = CollectorRun{
+				Collector: c,
+				StartTime: now,
+				EndTime:   now,
+				Err:       ErrInsufficientDiskSpace,
+			}
+			if opts.AfterRun != nil {
+				opts.AfterRun(runs[i])
+			}
+			return
+		}
+		if opts.BeforeRun != nil {
+			opts.BeforeRun(c)
+		}
+		startTime := time.Now()
+		result, err := RunCollector(ctx, c, progressChan)
+		opts.Throttle.ObserveError(err)
+		runs[i] = CollectorRun{
+			Collector: c,
+			StartTime: startTime,
+			EndTime:   time.Now(),
+			Result:    result,
+			Err:       err,
+		}
+		if opts.AfterRun != nil {
+			opts.AfterRun(runs[i])
+		}
+	}
+
+	var mu sync.Mutex
+	finished := sync.NewCond(&mu)
+	running := 0
+	limit := concurrency
+
+	// start runs a collector as soon as fewer than the concurrency limit are running
+	start := func(i int) {
+		mu.Lock()
+		for {
+			if current := opts.Throttle.Concurrency(concurrency); current != limit {
+				limit = current
+				if progressChan != nil {
+					// progress is not sent with mu held, so that the collectors that are running can finish
+					mu.Unlock()
+					progressChan <- fmt.Sprintf("API server is throttling requests, running %d of %d collectors at a time", current, concurrency)
+					mu.Lock()
+					continue
+				}
+			}
+			if running < limit {
+				break
+			}
+			finished.Wait()
+		}
+		running++
+		mu.Unlock()
+
+		go func() {
+			run(i)
+
+			mu.Lock()
+			running--
+			finished.Broadcast()
+			mu.Unlock()
+		}()
+	}
+	wait := func() {
+		mu.Lock()
+		for running > 0 {
+			finished.Wait()
+		}
+		mu.Unlock()
+	}
+
+	deferred := []int{}
+	startPending := func(pending []int) {
+		for len(pending) > 0 {
+			next := 0
+			if opts.Throttle.Throttled() {
+				next = nextWhileThrottled(collectors, pending)
+				if next < 0 {
+					deferred = append(deferred, pending...)
+					return
+				}
+			}
+			i := pending[next]
+			pending = append(pending[:next], pending[next+1:]...)
+			start(i)
+		}
+	}
+
+	pending := []int{}
+	for i, c := range collectors {
+		if _, ok := c.(*CollectClusterResources); ok {
+			startPending(pending)
+			pending = []int{}
+			wait()
+			run(i)
+			continue
+		}
+		pending = append(pending, i)
+	}
+	startPending(pending)
+
+	deadline := time.Now().Add(throttleDeferralTimeout)
+	for _, i := range deferred {
+		if !opts.Throttle.waitUntilNotThrottled(ctx, deadline) {
+			now := time.Now()
+			runs[i] = CollectorRun{
+				Collector: collectors[i],
+				StartTime: now,
+				EndTime:   now,
+				Err:       ErrThrottled,
+			}
+			if opts.AfterRun != nil {
+				opts.AfterRun(runs[i])
+			}
+			continue
+		}
+		start(i)
+	}
+	wait()
+
+	return runs
+}
+
+// nextWhileThrottled returns the index in pending of the first high priority collector, or else of the
+// first normal priority one. It returns -1 if only low priority collectors are pending.
+func nextWhileThrottled(collectors []Collector, pending []int) int {
+	next := -1
+	for j, i := range pending {
+		switch getCollectorPriority(collectors[i]) {
+		case CollectorPriorityHigh:
+			return j
+		case CollectorPriorityNormal:
+			if next < 0 {
+				next = j
+			}
+		}
+	}
+	return next
+}
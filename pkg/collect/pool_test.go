@@ -0,0 +1,224 @@
+package collect
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sleepCollector struct {
+	RBACErrors
+	name    string
+	sleep   time.Duration
+	running *int32
+	maxSeen *int32
+}
+
+func (c *sleepCollector) Title() string {
+	return c.name
+}
+
+func (c *sleepCollector) IsExcluded() (bool, error) {
+	return false, nil
+}
+
+func (c *sleepCollector) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
+	running := atomic.AddInt32(c.running, 1)
+	defer atomic.AddInt32(c.running, -1)
+	for {
+		maxSeen := atomic.LoadInt32(c.maxSeen)
+		if running <= maxSeen || atomic.CompareAndSwapInt32(c.maxSeen, maxSeen, running) {
+			break
+		}
+	}
+
+	time.Sleep(c.sleep)
+	return CollectorResult{c.name: []byte(c.name)}, nil
+}
+
+func TestRunCollectors(t *testing.T) {
+	tests := []struct {
+		concurrency int
+		wantMax     int32
+	}{
+		{concurrency: 0, wantMax: 1},
+		{concurrency: 1, wantMax: 1},
+		{concurrency: 3, wantMax: 3},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("concurrency %d", tt.concurrency), func(t *testing.T) {
+			var running, maxSeen int32
+			collectors := []Collector{}
+			for i := 0; i < 6; i++ {
+				collectors = append(collectors, &sleepCollector{
+					name:    fmt.Sprintf("collector-%d", i),
+					sleep:   time.Duration(6-i) * 10 * time.Millisecond,
+					running: &running,
+					maxSeen: &maxSeen,
+				})
+			}
+
+			started := int32(0)
+			runs := RunCollectors(context.Background(), collectors, tt.concurrency, make(chan interface{}), func(Collector) {
+				atomic.AddInt32(&started, 1)
+			})
+
+			require.Len(t, runs, len(collectors))
+			for i, run := range runs {
+				name := fmt.Sprintf("collector-%d", i)
+				assert.Equal(t, name, run.Collector.Title())
+				assert.NoError(t, run.Err)
+				assert.Equal(t, CollectorResult{name: []byte(name)}, run.Result)
+				assert.False(t, run.EndTime.Before(run.StartTime))
+			}
+			assert.Equal(t, int32(len(collectors)), started)
+			assert.Equal(t, tt.wantMax, maxSeen)
+		})
+	}
+}
+
+func TestRunCollectorsWithCallback(t *testing.T) {
+	var running, maxSeen int32
+	collectors := []Collector{}
+	for i := 0; i < 3; i++ {
+		collectors = append(collectors, &sleepCollector{
+			name:    fmt.Sprintf("collector-%d", i),
+			running: &running,
+			maxSeen: &maxSeen,
+		})
+	}
+
+	finished := make(chan CollectorRun, len(collectors))
+	runs := RunCollectorsWithCallback(context.Background(), collectors, 2, make(chan interface{}), nil, func(run CollectorRun) {
+		finished <- run
+	})
+	close(finished)
+
+	require.Len(t, runs, len(collectors))
+	names := []string{}
+	for run := range finished {
+		assert.NoError(t, run.Err)
+		names = append(names, run.Collector.Title())
+	}
+	assert.ElementsMatch(t, []string{"collector-0", "collector-1", "collector-2"}, names)
+}
+
+type priorityCollector struct {
+	*sleepCollector
+	Collector *troubleshootv1beta2.Data
+	started   *[]string
+	mtx       *sync.Mutex
+}
+
+func (c *priorityCollector) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
+	c.mtx.Lock()
+	*c.started = append(*c.started, c.name)
+	c.mtx.Unlock()
+	return c.sleepCollector.Collect(progressChan)
+}
+
+func TestRunCollectorsWithOptions_throttled(t *testing.T) {
+	defer func(cooldown, timeout, interval time.Duration) {
+		throttleCooldown, throttleDeferralTimeout, throttlePollInterval = cooldown, timeout, interval
+	}(throttleCooldown, throttleDeferralTimeout, throttlePollInterval)
+	throttleCooldown = time.Hour
+	throttleDeferralTimeout = 50 * time.Millisecond
+	throttlePollInterval = 10 * time.Millisecond
+
+	var running, maxSeen int32
+	var mtx sync.Mutex
+	started := []string{}
+	newCollector := func(name string, priority string) Collector {
+		return &priorityCollector{
+			sleepCollector: &sleepCollector{name: name, sleep: 10 * time.Millisecond, running: &running, maxSeen: &maxSeen},
+			Collector:      &troubleshootv1beta2.Data{CollectorMeta: troubleshootv1beta2.CollectorMeta{Priority: priority}},
+			started:        &started,
+			mtx:            &mtx,
+		}
+	}
+	collectors := []Collector{
+		newCollector("normal-0", ""),
+		newCollector("low-1", CollectorPriorityLow),
+		newCollector("high-2", CollectorPriorityHigh),
+		newCollector("normal-3", CollectorPriorityNormal),
+	}
+
+	throttle := NewAPIThrottle()
+	throttle.observe()
+
+	progressChan := make(chan interface{}, 10)
+	runs := RunCollectorsWithOptions(context.Background(), collectors, progressChan, RunOptions{
+		Concurrency: 2,
+		Throttle:    throttle,
+	})
+
+	require.Len(t, runs, len(collectors))
+	for i, run := range runs {
+		assert.Equal(t, collectors[i].Title(), run.Collector.Title())
+		if i == 1 {
+			assert.Equal(t, ErrThrottled, run.Err)
+			assert.Nil(t, run.Result)
+			continue
+		}
+		assert.NoError(t, run.Err)
+	}
+
+	// the concurrency is halved, high priority collectors start first and low priority ones are deferred
+	assert.Equal(t, int32(1), maxSeen)
+	assert.Equal(t, []string{"high-2", "normal-0", "normal-3"}, started)
+	require.Len(t, progressChan, 1)
+	assert.Equal(t, "API server is throttling requests, running 1 of 2 collectors at a time", <-progressChan)
+}
+
+func TestRunCollectorsWithOptions_deferredRecovers(t *testing.T) {
+	defer func(timeout, interval time.Duration) {
+		throttleDeferralTimeout, throttlePollInterval = timeout, interval
+	}(throttleDeferralTimeout, throttlePollInterval)
+	throttleDeferralTimeout = time.Minute
+	throttlePollInterval = 10 * time.Millisecond
+
+	now := time.Now()
+	var nowMtx sync.Mutex
+	throttle := NewAPIThrottle()
+	throttle.now = func() time.Time {
+		nowMtx.Lock()
+		defer nowMtx.Unlock()
+		return now
+	}
+	throttle.observe()
+
+	var running, maxSeen int32
+	var mtx sync.Mutex
+	started := []string{}
+	collectors := []Collector{
+		&priorityCollector{
+			sleepCollector: &sleepCollector{name: "low-0", running: &running, maxSeen: &maxSeen},
+			Collector:      &troubleshootv1beta2.Data{CollectorMeta: troubleshootv1beta2.CollectorMeta{Priority: CollectorPriorityLow}},
+			started:        &started,
+			mtx:            &mtx,
+		},
+	}
+
+	// the API server stops throttling requests while the collector is deferred
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		nowMtx.Lock()
+		now = now.Add(throttleCooldown)
+		nowMtx.Unlock()
+	}()
+
+	runs := RunCollectorsWithOptions(context.Background(), collectors, make(chan interface{}, 10), RunOptions{
+		Concurrency: 1,
+		Throttle:    throttle,
+	})
+	require.Len(t, runs, 1)
+	assert.NoError(t, runs[0].Err)
+	assert.Equal(t, []string{"low-0"}, started)
+}
@@ -15,6 +15,14 @@ import (
 	"k8s.io/client-go/rest"
 )
 
+// postgresDiagnostics are the read-only queries that can be run with the diagnostics of the collector
+var postgresDiagnostics = map[string]string{
+	"settings":       `select name, setting, unit, source from pg_settings order by name`,
+	"activity":       `select datname, usename, state, count(*) as connections, max(now() - query_start) as longest_query_duration from pg_stat_activity group by datname, usename, state order by datname, usename, state`,
+	"database-sizes": `select datname, pg_database_size(datname) as size_bytes from pg_database where datallowconn order by datname`,
+	"replication":    `select * from pg_stat_replication`,
+}
+
 type CollectPostgres struct {
 	Collector    *troubleshootv1beta2.Database
 	BundlePath   string
@@ -30,18 +38,27 @@ func (c *CollectPostgres) Title() string {
 }
 
 func (c *CollectPostgres) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 func (c *CollectPostgres) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
 	databaseConnection := DatabaseConnection{}
 
+	output := NewResult()
+
+	collectorName := c.Collector.CollectorName
+	if collectorName == "" {
+		collectorName = "postgres"
+	}
+
 	db, err := sql.Open("postgres", c.Collector.URI)
 	if err != nil {
 		databaseConnection.Error = err.Error()
 	} else {
+		defer db.Close()
+		ctx := contextOrBackground(c.Context)
 		query := `select version()`
-		row := db.QueryRow(query)
+		row := db.QueryRowContext(ctx, query)
 		version := ""
 		if err := row.Scan(&version); err != nil {
 			databaseConnection.Error = err.Error()
@@ -55,6 +72,24 @@ func (c *CollectPostgres) Collect(progressChan chan<- interface{}) (CollectorRes
 			} else {
 				databaseConnection.Version = postgresVersion
 			}
+
+			for _, diagnostic := range c.Collector.Diagnostics {
+				query, ok := postgresDiagnostics[diagnostic]
+				if !ok {
+					progressChan <- errors.Errorf("unknown postgres diagnostic %q", diagnostic)
+					continue
+				}
+
+				b, err := json.MarshalIndent(runPostgresQuery(ctx, db, query), "", "  ")
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to marshal %s diagnostic", diagnostic)
+				}
+				output.SaveResult(c.BundlePath, fmt.Sprintf("postgres/%s/%s.json", collectorName, diagnostic), bytes.NewBuffer(b))
+			}
+
+			if c.Collector.Topology != nil {
+				databaseConnection.Topology = collectDatabaseTopology(ctx, postgresTopologyDriver, c.Collector.URI, db, c.Collector.Topology)
+			}
 		}
 	}
 
@@ -63,17 +98,73 @@ func (c *CollectPostgres) Collect(progressChan chan<- interface{}) (CollectorRes
 		return nil, errors.Wrap(err, "failed to marshal database connection")
 	}
 
-	collectorName := c.Collector.CollectorName
-	if collectorName == "" {
-		collectorName = "postgres"
-	}
-
-	output := NewResult()
 	output.SaveResult(c.BundlePath, fmt.Sprintf("postgres/%s.json", collectorName), bytes.NewBuffer(b))
 
 	return output, nil
 }
 
+// runPostgresQuery runs a diagnostic query in a read-only transaction. Each query has its own
+// transaction as postgres aborts a transaction when a query in it fails.
+func runPostgresQuery(ctx context.Context, db *sql.DB, query string) DatabaseQueryResult {
+	result := DatabaseQueryResult{Query: query}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer rows.Close()
+
+	result.Rows, err = scanDatabaseRows(rows)
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// scanDatabaseRows returns a map of column to value for each row
+func scanDatabaseRows(rows *sql.Rows) ([]map[string]interface{}, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get columns")
+	}
+
+	scannedRows := []map[string]interface{}{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return scannedRows, errors.Wrap(err, "failed to scan row")
+		}
+
+		row := map[string]interface{}{}
+		for i, column := range columns {
+			row[column] = databaseValue(values[i])
+		}
+		scannedRows = append(scannedRows, row)
+	}
+
+	return scannedRows, rows.Err()
+}
+
+// databaseValue converts values that don't marshal to readable json, such as text returned as bytes
+func databaseValue(value interface{}) interface{} {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return value
+}
+
 func parsePostgresVersion(postgresVersion string) (string, error) {
 	re := regexp.MustCompile("PostgreSQL ([0-9.]*)")
 	matches := re.FindStringSubmatch(postgresVersion)
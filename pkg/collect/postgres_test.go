@@ -44,3 +44,17 @@ func Test_parsePostgresVersion(t *testing.T) {
 		})
 	}
 }
+
+func Test_databaseValue(t *testing.T) {
+	assert.Equal(t, "max_connections", databaseValue([]byte("max_connections")))
+	assert.Equal(t, int64(100), databaseValue(int64(100)))
+	assert.Nil(t, databaseValue(nil))
+}
+
+func Test_postgresDiagnostics(t *testing.T) {
+	for _, diagnostic := range []string{"settings", "activity", "database-sizes", "replication"} {
+		query, ok := postgresDiagnostics[diagnostic]
+		require.True(t, ok, diagnostic)
+		assert.Regexp(t, "^select ", query, diagnostic)
+	}
+}
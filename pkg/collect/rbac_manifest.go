@@ -0,0 +1,344 @@
+package collect
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// RBACManifestOptions are the names of the objects written by RBACManifests
+type RBACManifestOptions struct {
+	// Name is the name of the roles and their bindings
+	Name string
+	// Namespace is the namespace of collectors that do not set one, and of the service account
+	Namespace string
+	// ServiceAccount is bound to the roles
+	ServiceAccount string
+}
+
+// clusterRuleKey is the namespace of rules that are for cluster scoped resources, or for resources in
+// all namespaces, which need a ClusterRole
+const clusterRuleKey = ""
+
+// policyRules are the rules that collectors need, by namespace
+type policyRules map[string][]rbacv1.PolicyRule
+
+func (r policyRules) add(namespace string, group string, resource string, verbs ...string) {
+	r[namespace] = append(r[namespace], rbacv1.PolicyRule{
+		APIGroups: []string{group},
+		Resources: []string{resource},
+		Verbs:     verbs,
+	})
+}
+
+// CollectorPolicyRules returns the rules that collectors need to collect everything they collect, by
+// namespace. Rules that are for cluster scoped resources, or for resources in all namespaces, are at
+// "". namespace is the namespace of collectors that do not set one.
+//
+// The custom resources that clusterResources collects are not known until it runs, and are not included.
+// The OpenShift resources it collects are, and their rules grant nothing on clusters that don't serve
+// them. Custom collectors are not included either.
+func CollectorPolicyRules(collectors []*troubleshootv1beta2.Collect, namespace string) map[string][]rbacv1.PolicyRule {
+	rules := policyRules{}
+	for _, collector := range collectors {
+		addCollectorPolicyRules(rules, collector, namespace)
+	}
+
+	merged := map[string][]rbacv1.PolicyRule{}
+	for ns, nsRules := range rules {
+		merged[ns] = mergePolicyRules(nsRules)
+	}
+	return merged
+}
+
+func addCollectorPolicyRules(rules policyRules, c *troubleshootv1beta2.Collect, namespace string) {
+	pick := func(collectorNamespace string) string {
+		if collectorNamespace != "" {
+			return collectorNamespace
+		}
+		return namespace
+	}
+	podExec := func(ns string) {
+		rules.add(ns, "", "pods", "list", "get")
+		rules.add(ns, "", "pods/exec", "create")
+	}
+	runPod := func(ns string) {
+		rules.add(ns, "", "pods", "create", "get", "delete")
+		rules.add(ns, "", "pods/log", "get")
+		rules.add(ns, "", "secrets", "create", "delete")
+	}
+
+	switch {
+	case c.ClusterResources != nil:
+		if len(c.ClusterResources.Namespaces) == 0 {
+			rules.add(clusterRuleKey, "", "namespaces", "list")
+		} else {
+			rules.add(clusterRuleKey, "", "namespaces", "get")
+		}
+		for _, resource := range []string{"nodes", "persistentvolumes"} {
+			rules.add(clusterRuleKey, "", resource, "list")
+		}
+		rules.add(clusterRuleKey, "storage.k8s.io", "storageclasses", "list")
+		rules.add(clusterRuleKey, "apiextensions.k8s.io", "customresourcedefinitions", "list")
+		rules.add(clusterRuleKey, "rbac.authorization.k8s.io", "clusterroles", "list")
+		rules.add(clusterRuleKey, "rbac.authorization.k8s.io", "clusterrolebindings", "list")
+		// the OpenShift resources, which are only listed on OpenShift clusters
+		for _, gvr := range openshiftClusterScopedResources {
+			rules.add(clusterRuleKey, gvr.Group, gvr.Resource, "list")
+		}
+
+		namespaces := c.ClusterResources.Namespaces
+		if len(namespaces) == 0 {
+			namespaces = []string{clusterRuleKey}
+		}
+		for _, ns := range namespaces {
+			for _, resource := range []string{"pods", "services", "events", "limitranges", "persistentvolumeclaims", "resourcequotas", "secrets"} {
+				rules.add(ns, "", resource, "list")
+			}
+			// the logs of unhealthy pods
+			rules.add(ns, "", "pods/log", "get")
+			for _, resource := range []string{"deployments", "statefulsets", "daemonsets", "replicasets"} {
+				rules.add(ns, "apps", resource, "list")
+			}
+			rules.add(ns, "batch", "jobs", "list")
+			rules.add(ns, "batch", "cronjobs", "list")
+			rules.add(ns, "networking.k8s.io", "ingresses", "list")
+			rules.add(ns, "networking.k8s.io", "networkpolicies", "list")
+			rules.add(ns, "policy", "poddisruptionbudgets", "list")
+			rules.add(ns, "rbac.authorization.k8s.io", "roles", "list")
+			rules.add(ns, "rbac.authorization.k8s.io", "rolebindings", "list")
+			rules.add(ns, openshiftRoutesGVR.Group, openshiftRoutesGVR.Resource, "list")
+		}
+	case c.Secret != nil:
+		if c.Secret.Name != "" {
+			rules.add(pick(c.Secret.Namespace), "", "secrets", "get")
+		} else {
+			rules.add(pick(c.Secret.Namespace), "", "secrets", "list")
+		}
+	case c.ConfigMap != nil:
+		if c.ConfigMap.Name != "" {
+			rules.add(pick(c.ConfigMap.Namespace), "", "configmaps", "get")
+		} else {
+			rules.add(pick(c.ConfigMap.Namespace), "", "configmaps", "list")
+		}
+	case c.Logs != nil:
+		rules.add(pick(c.Logs.Namespace), "", "pods", "list")
+		rules.add(pick(c.Logs.Namespace), "", "pods/log", "get")
+	case c.Run != nil:
+		runPod(pick(c.Run.Namespace))
+	case c.RunPod != nil:
+		runPod(pick(c.RunPod.Namespace))
+	case c.Sysctl != nil:
+		runPod(pick(c.Sysctl.Namespace))
+	case c.Exec != nil:
+		podExec(pick(c.Exec.Namespace))
+	case c.Copy != nil:
+		podExec(pick(c.Copy.Namespace))
+	case c.Ceph != nil:
+		podExec(pick(c.Ceph.Namespace))
+	case c.CopyFromHost != nil || c.Collectd != nil:
+		ns := ""
+		if c.CopyFromHost != nil {
+			ns = pick(c.CopyFromHost.Namespace)
+		} else {
+			ns = pick(c.Collectd.Namespace)
+		}
+		rules.add(ns, "apps", "daemonsets", "create", "get", "delete")
+		rules.add(ns, "", "secrets", "create", "delete")
+		podExec(ns)
+	case c.Longhorn != nil:
+		ns := DefaultLonghornNamespace
+		if c.Longhorn.Namespace != "" {
+			ns = c.Longhorn.Namespace
+		}
+		for _, resource := range []string{"nodes", "volumes", "replicas", "engines", "engineimages", "instancemanagers", "backingimagemanagers", "backingimages", "sharemanagers", "settings"} {
+			rules.add(ns, "longhorn.io", resource, "list")
+		}
+		podExec(ns)
+	case c.RegistryImages != nil:
+		if c.RegistryImages.ImagePullSecrets != nil && c.RegistryImages.ImagePullSecrets.Data == nil {
+			rules.add(pick(c.RegistryImages.Namespace), "", "secrets", "get")
+		}
+	case c.ClusterAutoscaler != nil:
+		ns := DefaultClusterAutoscalerNamespace
+		if c.ClusterAutoscaler.Namespace != "" {
+			ns = c.ClusterAutoscaler.Namespace
+		}
+		rules.add(ns, "", "pods", "list")
+		rules.add(ns, "", "pods/log", "get")
+		rules.add(ns, "", "configmaps", "get")
+		rules.add(clusterRuleKey, "", "nodes", "list")
+		rules.add(clusterRuleKey, "", "events", "list")
+	case c.ServiceEndpoints != nil:
+		ns := c.ServiceEndpoints.Namespace
+		rules.add(ns, "", "services", "list")
+		rules.add(ns, "", "endpoints", "get")
+		rules.add(ns, "", "pods", "list")
+	case c.NodeStats != nil:
+		rules.add(clusterRuleKey, "", "nodes", "list")
+		rules.add(clusterRuleKey, "", "nodes/proxy", "get")
+	case c.Events != nil:
+		namespaces := c.Events.Namespaces
+		if len(namespaces) == 0 {
+			namespaces = []string{clusterRuleKey}
+		}
+		for _, ns := range namespaces {
+			rules.add(ns, "", "events", "list")
+		}
+	case c.Storage != nil:
+		rules.add(clusterRuleKey, "storage.k8s.io", "storageclasses", "list")
+		rules.add(clusterRuleKey, "", "persistentvolumes", "list")
+		namespaces := c.Storage.Namespaces
+		if len(namespaces) == 0 {
+			namespaces = []string{clusterRuleKey}
+		}
+		for _, ns := range namespaces {
+			rules.add(ns, "", "persistentvolumeclaims", "list")
+		}
+	case c.ClusterSummary != nil:
+		rules.add(clusterRuleKey, "", "nodes", "list")
+		rules.add(clusterRuleKey, "apps", "daemonsets", "list")
+		rules.add(clusterRuleKey, "storage.k8s.io", "csidrivers", "list")
+		rules.add(clusterRuleKey, "storage.k8s.io", "storageclasses", "list")
+		rules.add(clusterRuleKey, "networking.k8s.io", "ingressclasses", "list")
+	case c.Vault != nil:
+		if auth := c.Vault.KubernetesAuth; auth != nil && auth.ServiceAccount != "" {
+			rules.add(pick(auth.Namespace), "", "serviceaccounts/token", "create")
+		}
+	}
+}
+
+// mergePolicyRules merges the verbs of rules for the same resource, and sorts them so that the manifest
+// is the same for the same spec
+func mergePolicyRules(rules []rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	type groupResource struct {
+		group    string
+		resource string
+	}
+
+	verbs := map[groupResource]map[string]bool{}
+	for _, rule := range rules {
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				key := groupResource{group: group, resource: resource}
+				if verbs[key] == nil {
+					verbs[key] = map[string]bool{}
+				}
+				for _, verb := range rule.Verbs {
+					verbs[key][verb] = true
+				}
+			}
+		}
+	}
+
+	// resources of a group with the same verbs share a rule
+	byVerbs := map[string]*rbacv1.PolicyRule{}
+	for key, resourceVerbs := range verbs {
+		sortedVerbs := []string{}
+		for verb := range resourceVerbs {
+			sortedVerbs = append(sortedVerbs, verb)
+		}
+		sort.Strings(sortedVerbs)
+
+		ruleKey := key.group + "/" + strings.Join(sortedVerbs, ",")
+		if rule, ok := byVerbs[ruleKey]; ok {
+			rule.Resources = append(rule.Resources, key.resource)
+			continue
+		}
+		byVerbs[ruleKey] = &rbacv1.PolicyRule{
+			APIGroups: []string{key.group},
+			Resources: []string{key.resource},
+			Verbs:     sortedVerbs,
+		}
+	}
+
+	merged := []rbacv1.PolicyRule{}
+	for _, rule := range byVerbs {
+		sort.Strings(rule.Resources)
+		merged = append(merged, *rule)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].APIGroups[0] != merged[j].APIGroups[0] {
+			return merged[i].APIGroups[0] < merged[j].APIGroups[0]
+		}
+		return merged[i].Resources[0] < merged[j].Resources[0]
+	})
+	return merged
+}
+
+// RBACManifests returns the ServiceAccount, roles and role bindings that the collectors need, as yaml
+// documents that can be applied with kubectl. Rules in all namespaces or for cluster scoped resources are
+// in a ClusterRole, and the rules of each namespace are in a Role.
+func RBACManifests(collectors []*troubleshootv1beta2.Collect, opts RBACManifestOptions) ([]byte, error) {
+	rules := CollectorPolicyRules(collectors, opts.Namespace)
+
+	subjects := []rbacv1.Subject{{
+		Kind:      rbacv1.ServiceAccountKind,
+		Name:      opts.ServiceAccount,
+		Namespace: opts.Namespace,
+	}}
+
+	objects := []interface{}{
+		&corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: metav1.ObjectMeta{Name: opts.ServiceAccount, Namespace: opts.Namespace},
+		},
+	}
+
+	if clusterRules, ok := rules[clusterRuleKey]; ok {
+		objects = append(objects,
+			&rbacv1.ClusterRole{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+				ObjectMeta: metav1.ObjectMeta{Name: opts.Name},
+				Rules:      clusterRules,
+			},
+			&rbacv1.ClusterRoleBinding{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+				ObjectMeta: metav1.ObjectMeta{Name: opts.Name},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: opts.Name},
+				Subjects:   subjects,
+			},
+		)
+	}
+
+	namespaces := []string{}
+	for ns := range rules {
+		if ns != clusterRuleKey {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Strings(namespaces)
+
+	for _, ns := range namespaces {
+		objects = append(objects,
+			&rbacv1.Role{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+				ObjectMeta: metav1.ObjectMeta{Name: opts.Name, Namespace: ns},
+				Rules:      rules[ns],
+			},
+			&rbacv1.RoleBinding{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+				ObjectMeta: metav1.ObjectMeta{Name: opts.Name, Namespace: ns},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: opts.Name},
+				Subjects:   subjects,
+			},
+		)
+	}
+
+	docs := []string{}
+	for _, object := range objects {
+		b, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal manifest")
+		}
+		docs = append(docs, string(b))
+	}
+
+	return []byte(strings.Join(docs, "---\n")), nil
+}
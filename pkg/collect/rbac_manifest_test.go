@@ -0,0 +1,85 @@
+package collect
+
+import (
+	"strings"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func TestCollectorPolicyRules(t *testing.T) {
+	collectors := []*troubleshootv1beta2.Collect{
+		{Logs: &troubleshootv1beta2.Logs{Selector: []string{"app=web"}}},
+		{Secret: &troubleshootv1beta2.Secret{Name: "db", Namespace: "app"}},
+		{Exec: &troubleshootv1beta2.Exec{Namespace: "app", Selector: []string{"app=web"}}},
+		{NodeStats: &troubleshootv1beta2.NodeStats{}},
+		{Events: &troubleshootv1beta2.Events{}},
+		{HTTP: &troubleshootv1beta2.HTTP{}},
+	}
+
+	rules := CollectorPolicyRules(collectors, "default")
+	assert.Equal(t, map[string][]rbacv1.PolicyRule{
+		"": {
+			{APIGroups: []string{""}, Resources: []string{"events", "nodes"}, Verbs: []string{"list"}},
+			{APIGroups: []string{""}, Resources: []string{"nodes/proxy"}, Verbs: []string{"get"}},
+		},
+		"default": {
+			{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"list"}},
+			{APIGroups: []string{""}, Resources: []string{"pods/log"}, Verbs: []string{"get"}},
+		},
+		"app": {
+			{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}},
+			{APIGroups: []string{""}, Resources: []string{"pods/exec"}, Verbs: []string{"create"}},
+			{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}},
+		},
+	}, rules)
+}
+
+func TestCollectorPolicyRules_ClusterResources(t *testing.T) {
+	collectors := []*troubleshootv1beta2.Collect{
+		{ClusterResources: &troubleshootv1beta2.ClusterResources{Namespaces: []string{"app"}}},
+	}
+
+	rules := CollectorPolicyRules(collectors, "default")
+	assert.Contains(t, rules[""], rbacv1.PolicyRule{APIGroups: []string{"config.openshift.io"}, Resources: []string{"clusteroperators", "clusterversions"}, Verbs: []string{"list"}})
+	assert.Contains(t, rules[""], rbacv1.PolicyRule{APIGroups: []string{"security.openshift.io"}, Resources: []string{"securitycontextconstraints"}, Verbs: []string{"list"}})
+	assert.Contains(t, rules[""], rbacv1.PolicyRule{APIGroups: []string{"machineconfiguration.openshift.io"}, Resources: []string{"machineconfigpools"}, Verbs: []string{"list"}})
+	assert.Contains(t, rules["app"], rbacv1.PolicyRule{APIGroups: []string{"route.openshift.io"}, Resources: []string{"routes"}, Verbs: []string{"list"}})
+}
+
+func TestRBACManifests(t *testing.T) {
+	collectors := []*troubleshootv1beta2.Collect{
+		{ClusterResources: &troubleshootv1beta2.ClusterResources{Namespaces: []string{"app"}}},
+		{Logs: &troubleshootv1beta2.Logs{Namespace: "monitoring"}},
+	}
+
+	b, err := RBACManifests(collectors, RBACManifestOptions{
+		Name:           "support",
+		Namespace:      "app",
+		ServiceAccount: "support-bundle",
+	})
+	require.NoError(t, err)
+
+	kinds := []string{}
+	for _, doc := range strings.Split(string(b), "---\n") {
+		object := struct {
+			Kind     string            `json:"kind"`
+			Metadata map[string]string `json:"metadata"`
+		}{}
+		require.NoError(t, yaml.Unmarshal([]byte(doc), &object))
+		kinds = append(kinds, object.Kind+"/"+object.Metadata["namespace"]+"/"+object.Metadata["name"])
+	}
+	assert.Equal(t, []string{
+		"ServiceAccount/app/support-bundle",
+		"ClusterRole//support",
+		"ClusterRoleBinding//support",
+		"Role/app/support",
+		"RoleBinding/app/support",
+		"Role/monitoring/support",
+		"RoleBinding/monitoring/support",
+	}, kinds)
+}
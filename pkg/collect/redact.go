@@ -15,7 +15,14 @@ import (
 	"github.com/replicatedhq/troubleshoot/pkg/redact"
 )
 
-func RedactResult(bundlePath string, input CollectorResult, additionalRedactors []*troubleshootv1beta2.Redact) error {
+// RedactResult redacts the files of a collector result in place. contentTypes are the types the files
+// were tagged with, for redactors that select files by content type, and can be nil.
+func RedactResult(bundlePath string, input CollectorResult, contentTypes ContentTypes, additionalRedactors []*troubleshootv1beta2.Redact) error {
+	return RedactResultWithOptions(bundlePath, input, contentTypes, additionalRedactors, redact.Options{})
+}
+
+// RedactResultWithOptions is RedactResult without the default redactors that opts exclude
+func RedactResultWithOptions(bundlePath string, input CollectorResult, contentTypes ContentTypes, additionalRedactors []*troubleshootv1beta2.Redact, opts redact.Options) error {
 	for k, v := range input {
 		var reader io.Reader
 		if v == nil {
@@ -46,7 +53,7 @@ func RedactResult(bundlePath string, input CollectorResult, additionalRedactors
 			if err != nil {
 				return errors.Wrap(err, "failed to decompress file")
 			}
-			err = RedactResult(tmpDir, subResult, additionalRedactors)
+			err = RedactResultWithOptions(tmpDir, subResult, nil, additionalRedactors, opts)
 			if err != nil {
 				return errors.Wrap(err, "failed to redact file")
 			}
@@ -63,7 +70,7 @@ func RedactResult(bundlePath string, input CollectorResult, additionalRedactors
 			continue
 		}
 
-		redacted, err := redact.Redact(reader, k, additionalRedactors)
+		redacted, err := redact.RedactWithOptions(reader, k, string(contentTypes[k]), additionalRedactors, opts)
 		if err != nil {
 			return errors.Wrap(err, "failed to redact")
 		}
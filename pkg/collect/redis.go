@@ -14,6 +14,9 @@ import (
 	"k8s.io/client-go/rest"
 )
 
+// redisInfoSections are the sections of INFO that are collected
+var redisInfoSections = []string{"server", "memory", "replication"}
+
 type CollectRedis struct {
 	Collector    *troubleshootv1beta2.Database
 	BundlePath   string
@@ -29,35 +32,50 @@ func (c *CollectRedis) Title() string {
 }
 
 func (c *CollectRedis) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 func (c *CollectRedis) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
 	databaseConnection := DatabaseConnection{}
 
+	// rediss:// uris connect with tls, and credentials are read from the uri
 	opt, err := redis.ParseURL(c.Collector.URI)
 	if err != nil {
 		databaseConnection.Error = err.Error()
 	} else {
-		client := redis.NewClient(opt)
-		stringResult := client.Info("server")
+		client := redis.NewClient(opt).WithContext(contextOrBackground(c.Context))
+		defer client.Close()
+
+		variables := map[string]string{}
+		for _, section := range redisInfoSections {
+			info, err := client.Info(section).Result()
+			if err != nil {
+				databaseConnection.Error = err.Error()
+				break
+			}
 
-		if stringResult.Err() != nil {
-			databaseConnection.Error = stringResult.Err().Error()
+			// the first section tells us if we could connect
+			databaseConnection.IsConnected = true
+			for key, value := range parseRedisInfo(info) {
+				variables[key] = value
+			}
 		}
 
-		databaseConnection.IsConnected = stringResult.Err() == nil
+		databaseConnection.Version = variables["redis_version"]
 
-		if databaseConnection.Error == "" {
-			lines := strings.Split(stringResult.Val(), "\n")
-			for _, line := range lines {
-				lineParts := strings.Split(line, ":")
-				if len(lineParts) == 2 {
-					if lineParts[0] == "redis_version" {
-						databaseConnection.Version = strings.TrimSpace(lineParts[1])
-					}
+		requestedParameters := c.Collector.Parameters
+		if len(requestedParameters) > 0 {
+			filteredVariables := map[string]string{}
+			for _, key := range requestedParameters {
+				if value, ok := variables[key]; ok {
+					filteredVariables[key] = value
 				}
 			}
+			variables = filteredVariables
+		}
+
+		if len(variables) > 0 {
+			databaseConnection.Variables = variables
 		}
 	}
 
@@ -76,3 +94,20 @@ func (c *CollectRedis) Collect(progressChan chan<- interface{}) (CollectorResult
 
 	return output, nil
 }
+
+// parseRedisInfo parses the key:value fields of INFO output, skipping the # section headers
+func parseRedisInfo(info string) map[string]string {
+	fields := map[string]string{}
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		lineParts := strings.SplitN(line, ":", 2)
+		if len(lineParts) == 2 {
+			fields[lineParts[0]] = lineParts[1]
+		}
+	}
+	return fields
+}
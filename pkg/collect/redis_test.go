@@ -0,0 +1,20 @@
+package collect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseRedisInfo(t *testing.T) {
+	info := "# Server\r\nredis_version:6.2.6\r\nredis_mode:standalone\r\nexecutable:/data/redis-server\r\n\r\n# Replication\r\nrole:master\r\nconnected_slaves:1\r\nslave0:ip=fd00::10,port=6379,state=online,offset=42,lag=0\r\n"
+
+	assert.Equal(t, map[string]string{
+		"redis_version":    "6.2.6",
+		"redis_mode":       "standalone",
+		"executable":       "/data/redis-server",
+		"role":             "master",
+		"connected_slaves": "1",
+		"slave0":           "ip=fd00::10,port=6379,state=online,offset=42,lag=0",
+	}, parseRedisInfo(info))
+}
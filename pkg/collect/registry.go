@@ -52,7 +52,7 @@ func (c *CollectRegistry) Title() string {
 }
 
 func (c *CollectRegistry) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 func (c *CollectRegistry) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
@@ -61,7 +61,7 @@ func (c *CollectRegistry) Collect(progressChan chan<- interface{}) (CollectorRes
 	}
 
 	for _, image := range c.Collector.Images {
-		exists, err := imageExists(c.Namespace, c.ClientConfig, c.Collector, image)
+		exists, err := imageExists(contextOrBackground(c.Context), c.Namespace, c.ClientConfig, c.Collector, image)
 		if err != nil {
 			registryInfo.Images[image] = RegistryImage{
 				Error: err.Error(),
@@ -89,7 +89,7 @@ func (c *CollectRegistry) Collect(progressChan chan<- interface{}) (CollectorRes
 	return output, nil
 }
 
-func imageExists(namespace string, clientConfig *rest.Config, registryCollector *troubleshootv1beta2.RegistryImages, image string) (bool, error) {
+func imageExists(ctx context.Context, namespace string, clientConfig *rest.Config, registryCollector *troubleshootv1beta2.RegistryImages, image string) (bool, error) {
 	imageRef, err := alltransports.ParseImageName(fmt.Sprintf("docker://%s", image))
 	if err != nil {
 		return false, errors.Wrapf(err, "failed to parse image name %s", image)
@@ -113,7 +113,7 @@ func imageExists(namespace string, clientConfig *rest.Config, registryCollector
 			}
 		}
 
-		remoteImage, err := imageRef.NewImage(context.Background(), &sysCtx)
+		remoteImage, err := imageRef.NewImage(ctx, &sysCtx)
 		if err == nil {
 			remoteImage.Close()
 			return true, nil
@@ -43,7 +43,7 @@ type runner interface {
 // checks if a given collector has a spec with 'exclude' that evaluates to true.
 func (c *RemoteCollector) IsExcluded() bool {
 	if c.Collect.KernelModules != nil {
-		isExcludedResult, err := isExcluded(c.Collect.KernelModules.Exclude)
+		isExcludedResult, err := isExcludedWhen(c.Collect.KernelModules.Exclude, c.Collect.KernelModules.When)
 		if err != nil {
 			return true
 		}
@@ -107,7 +107,7 @@ func (c *RemoteCollector) RunCollectorSync(globalRedactors []*troubleshootv1beta
 		return result, nil
 	}
 
-	if err = RedactResult("", result, globalRedactors); err != nil {
+	if err = RedactResult("", result, nil, globalRedactors); err != nil {
 		// Returning result on error to be consistent with local collector.
 		return result, errors.Wrap(err, "failed to redact")
 	}
@@ -159,6 +159,7 @@ func (c *RemoteCollector) toHostCollector() (*troubleshootv1beta2.HostCollect, e
 			HostCollectorMeta: troubleshootv1beta2.HostCollectorMeta{
 				CollectorName: c.Collect.CPU.CollectorName,
 				Exclude:       c.Collect.CPU.Exclude,
+				When:          c.Collect.CPU.When,
 			},
 		}
 	case c.Collect.Memory != nil:
@@ -166,6 +167,7 @@ func (c *RemoteCollector) toHostCollector() (*troubleshootv1beta2.HostCollect, e
 			HostCollectorMeta: troubleshootv1beta2.HostCollectorMeta{
 				CollectorName: c.Collect.Memory.CollectorName,
 				Exclude:       c.Collect.Memory.Exclude,
+				When:          c.Collect.Memory.When,
 			},
 		}
 	case c.Collect.TCPLoadBalancer != nil:
@@ -173,6 +175,7 @@ func (c *RemoteCollector) toHostCollector() (*troubleshootv1beta2.HostCollect, e
 			HostCollectorMeta: troubleshootv1beta2.HostCollectorMeta{
 				CollectorName: c.Collect.TCPLoadBalancer.CollectorName,
 				Exclude:       c.Collect.TCPLoadBalancer.Exclude,
+				When:          c.Collect.TCPLoadBalancer.When,
 			},
 			Address: c.Collect.TCPLoadBalancer.Address,
 			Port:    c.Collect.TCPLoadBalancer.Port,
@@ -183,6 +186,7 @@ func (c *RemoteCollector) toHostCollector() (*troubleshootv1beta2.HostCollect, e
 			HostCollectorMeta: troubleshootv1beta2.HostCollectorMeta{
 				CollectorName: c.Collect.HTTPLoadBalancer.CollectorName,
 				Exclude:       c.Collect.HTTPLoadBalancer.Exclude,
+				When:          c.Collect.HTTPLoadBalancer.When,
 			},
 			Address: c.Collect.TCPLoadBalancer.Address,
 			Port:    c.Collect.TCPLoadBalancer.Port,
@@ -193,6 +197,7 @@ func (c *RemoteCollector) toHostCollector() (*troubleshootv1beta2.HostCollect, e
 			HostCollectorMeta: troubleshootv1beta2.HostCollectorMeta{
 				CollectorName: c.Collect.DiskUsage.CollectorName,
 				Exclude:       c.Collect.DiskUsage.Exclude,
+				When:          c.Collect.DiskUsage.When,
 			},
 			Path: c.Collect.DiskUsage.Path,
 		}
@@ -201,6 +206,7 @@ func (c *RemoteCollector) toHostCollector() (*troubleshootv1beta2.HostCollect, e
 			HostCollectorMeta: troubleshootv1beta2.HostCollectorMeta{
 				CollectorName: c.Collect.TCPPortStatus.CollectorName,
 				Exclude:       c.Collect.TCPPortStatus.Exclude,
+				When:          c.Collect.TCPPortStatus.When,
 			},
 			Interface: c.Collect.TCPPortStatus.Interface,
 			Port:      c.Collect.TCPPortStatus.Port,
@@ -210,6 +216,7 @@ func (c *RemoteCollector) toHostCollector() (*troubleshootv1beta2.HostCollect, e
 			HostCollectorMeta: troubleshootv1beta2.HostCollectorMeta{
 				CollectorName: c.Collect.HTTP.CollectorName,
 				Exclude:       c.Collect.HTTP.Exclude,
+				When:          c.Collect.HTTP.When,
 			},
 			Get:  c.Collect.HTTP.Get,
 			Post: c.Collect.HTTP.Post,
@@ -220,6 +227,7 @@ func (c *RemoteCollector) toHostCollector() (*troubleshootv1beta2.HostCollect, e
 			HostCollectorMeta: troubleshootv1beta2.HostCollectorMeta{
 				CollectorName: c.Collect.Time.CollectorName,
 				Exclude:       c.Collect.Time.Exclude,
+				When:          c.Collect.Time.When,
 			},
 		}
 	case c.Collect.BlockDevices != nil:
@@ -227,6 +235,7 @@ func (c *RemoteCollector) toHostCollector() (*troubleshootv1beta2.HostCollect, e
 			HostCollectorMeta: troubleshootv1beta2.HostCollectorMeta{
 				CollectorName: c.Collect.BlockDevices.CollectorName,
 				Exclude:       c.Collect.BlockDevices.Exclude,
+				When:          c.Collect.BlockDevices.When,
 			},
 		}
 	case c.Collect.SystemPackages != nil:
@@ -234,6 +243,7 @@ func (c *RemoteCollector) toHostCollector() (*troubleshootv1beta2.HostCollect, e
 			HostCollectorMeta: troubleshootv1beta2.HostCollectorMeta{
 				CollectorName: c.Collect.SystemPackages.CollectorName,
 				Exclude:       c.Collect.SystemPackages.Exclude,
+				When:          c.Collect.SystemPackages.When,
 			},
 		}
 	case c.Collect.KernelModules != nil:
@@ -241,6 +251,7 @@ func (c *RemoteCollector) toHostCollector() (*troubleshootv1beta2.HostCollect, e
 			HostCollectorMeta: troubleshootv1beta2.HostCollectorMeta{
 				CollectorName: c.Collect.KernelModules.CollectorName,
 				Exclude:       c.Collect.KernelModules.Exclude,
+				When:          c.Collect.KernelModules.When,
 			},
 		}
 	case c.Collect.TCPConnect != nil:
@@ -248,6 +259,7 @@ func (c *RemoteCollector) toHostCollector() (*troubleshootv1beta2.HostCollect, e
 			HostCollectorMeta: troubleshootv1beta2.HostCollectorMeta{
 				CollectorName: c.Collect.TCPConnect.CollectorName,
 				Exclude:       c.Collect.TCPConnect.Exclude,
+				When:          c.Collect.TCPConnect.When,
 			},
 			Address: c.Collect.TCPConnect.Address,
 			Timeout: c.Collect.TCPConnect.Timeout,
@@ -257,6 +269,7 @@ func (c *RemoteCollector) toHostCollector() (*troubleshootv1beta2.HostCollect, e
 			HostCollectorMeta: troubleshootv1beta2.HostCollectorMeta{
 				CollectorName: c.Collect.IPV4Interfaces.CollectorName,
 				Exclude:       c.Collect.IPV4Interfaces.Exclude,
+				When:          c.Collect.IPV4Interfaces.When,
 			},
 		}
 	case c.Collect.FilesystemPerformance != nil:
@@ -264,6 +277,7 @@ func (c *RemoteCollector) toHostCollector() (*troubleshootv1beta2.HostCollect, e
 			HostCollectorMeta: troubleshootv1beta2.HostCollectorMeta{
 				CollectorName: c.Collect.FilesystemPerformance.CollectorName,
 				Exclude:       c.Collect.FilesystemPerformance.Exclude,
+				When:          c.Collect.FilesystemPerformance.When,
 			},
 			OperationSizeBytes:          c.Collect.FilesystemPerformance.OperationSizeBytes,
 			Directory:                   c.Collect.FilesystemPerformance.Directory,
@@ -283,6 +297,7 @@ func (c *RemoteCollector) toHostCollector() (*troubleshootv1beta2.HostCollect, e
 			HostCollectorMeta: troubleshootv1beta2.HostCollectorMeta{
 				CollectorName: c.Collect.Certificate.CollectorName,
 				Exclude:       c.Collect.Certificate.Exclude,
+				When:          c.Collect.Certificate.When,
 			},
 			CertificatePath: c.Collect.Certificate.CertificatePath,
 			KeyPath:         c.Collect.Certificate.KeyPath,
@@ -292,6 +307,7 @@ func (c *RemoteCollector) toHostCollector() (*troubleshootv1beta2.HostCollect, e
 			HostCollectorMeta: troubleshootv1beta2.HostCollectorMeta{
 				CollectorName: c.Collect.HostServices.CollectorName,
 				Exclude:       c.Collect.HostServices.Exclude,
+				When:          c.Collect.HostServices.When,
 			},
 		}
 	default:
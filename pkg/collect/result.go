@@ -2,36 +2,180 @@ package collect
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
+// CollectorResult maps the relative path of each collected file to its contents. A nil value means
+// the file is on disk, in the bundle directory or the spill directory that the result was collected to.
 type CollectorResult map[string][]byte
 
-func NewResult() CollectorResult {
-	return map[string][]byte{}
+// maxInMemoryResultSize is the size past which a result collected to a spill directory is moved out
+// of memory and into a file, so that large logs and copied files are not held in memory
+var maxInMemoryResultSize = 1024 * 1024
+
+// spillDirs are the spill directories of the collections that are running
+var spillDirs sync.Map
+
+// NewSpillDir creates a directory for one collection to spill its large results to. Results collected with
+// the directory as their bundle path are kept in memory unless they grow too large, and are then moved to
+// files in it. The directory must be removed with RemoveSpillDir once the results have been read.
+func NewSpillDir() (string, error) {
+	dir, err := ioutil.TempDir("", "troubleshoot-results-")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create spill dir")
+	}
+	spillDirs.Store(dir, struct{}{})
+
+	return dir, nil
 }
 
-func (r CollectorResult) SaveResult(bundlePath string, relativePath string, reader io.Reader) error {
-	if reader == nil {
+// RemoveSpillDir deletes a spill directory and the results that were spilled to it, which can no longer be read
+func RemoveSpillDir(dir string) error {
+	if dir == "" {
 		return nil
 	}
+	spillDirs.Delete(dir)
+
+	if err := os.RemoveAll(dir); err != nil {
+		return errors.Wrap(err, "failed to remove spill dir")
+	}
+
+	return nil
+}
 
+// spillDirFor returns the spill directory of a bundle path, and whether results saved to the path are kept
+// in memory. Without a bundle path, results are kept in memory and never spilled.
+func spillDirFor(bundlePath string) (string, bool) {
 	if bundlePath == "" {
-		data, err := ioutil.ReadAll(reader)
+		return "", true
+	}
+	if _, ok := spillDirs.Load(bundlePath); ok {
+		return bundlePath, true
+	}
+	return "", false
+}
+
+// spillWriter holds a result in memory until it grows past maxInMemoryResultSize, then writes it to a temp
+// file in the spill directory. The file is moved to the result's relative path when the writer is closed.
+// Without a spill directory, the result is only held in memory.
+type spillWriter struct {
+	dir          string
+	relativePath string
+	buf          bytes.Buffer
+	file         *os.File
+}
+
+func (w *spillWriter) Write(p []byte) (int, error) {
+	if w.file == nil && (w.dir == "" || w.buf.Len()+len(p) <= maxInMemoryResultSize) {
+		return w.buf.Write(p)
+	}
+
+	if w.file == nil {
+		f, err := ioutil.TempFile(w.dir, "spill-")
 		if err != nil {
-			return errors.Wrap(err, "failed to read data")
+			return 0, errors.Wrap(err, "failed to create spill file")
 		}
-		r[relativePath] = data
+		w.file = f
+
+		if err := diskGuardFor(w.dir).beforeWrite(w.buf.Len()); err != nil {
+			return 0, err
+		}
+		if _, err := w.buf.WriteTo(f); err != nil {
+			return 0, errors.Wrap(err, "failed to write spill file")
+		}
+		w.buf = bytes.Buffer{}
+	}
+
+	if err := diskGuardFor(w.dir).beforeWrite(len(p)); err != nil {
+		return 0, err
+	}
+	return w.file.Write(p)
+}
+
+// close returns the contents of the result if it was kept in memory, or nil if it was spilled to disk
+func (w *spillWriter) close() ([]byte, error) {
+	if w.file == nil {
+		// a result that used to be on disk has been replaced with one that fits in memory
+		if w.dir != "" {
+			os.Remove(filepath.Join(w.dir, w.relativePath))
+		}
+
+		b := w.buf.Bytes()
+		if b == nil {
+			// nil means data is on disk, so make it an empty array
+			b = []byte{}
+		}
+		return b, nil
+	}
+
+	tmpName := w.file.Name()
+	if err := w.file.Close(); err != nil {
+		os.Remove(tmpName)
+		return nil, errors.Wrap(err, "failed to close spill file")
+	}
+
+	outPath := filepath.Join(w.dir, w.relativePath)
+	if err := os.MkdirAll(filepath.Dir(outPath), 0777); err != nil {
+		os.Remove(tmpName)
+		return nil, errors.Wrap(err, "failed to create spill file dir")
+	}
+
+	if err := os.Rename(tmpName, outPath); err != nil {
+		os.Remove(tmpName)
+		return nil, errors.Wrap(err, "failed to rename spill file")
+	}
+
+	return nil, nil
+}
+
+func (w *spillWriter) discard() {
+	if w.file != nil {
+		w.file.Close()
+		os.Remove(w.file.Name())
+	}
+}
+
+func (r CollectorResult) saveSpilled(dir string, relativePath string, reader io.Reader) error {
+	w := &spillWriter{dir: dir, relativePath: relativePath}
+	if _, err := io.Copy(w, reader); err != nil {
+		w.discard()
+		return errors.Wrap(err, "failed to read data")
+	}
+
+	data, err := w.close()
+	if err != nil {
+		return err
+	}
+	r[relativePath] = data
+
+	return nil
+}
+
+func NewResult() CollectorResult {
+	return map[string][]byte{}
+}
+
+func (r CollectorResult) SaveResult(bundlePath string, relativePath string, reader io.Reader) error {
+	if reader == nil {
 		return nil
 	}
 
+	if dir, ok := spillDirFor(bundlePath); ok {
+		return r.saveSpilled(dir, relativePath, reader)
+	}
+
 	r[relativePath] = nil // save the file name referencing the file on disk
 
 	fileDir, fileName := filepath.Split(relativePath)
@@ -47,8 +191,14 @@ func (r CollectorResult) SaveResult(bundlePath string, relativePath string, read
 	}
 	defer f.Close()
 
-	_, err = io.Copy(f, reader)
+	_, err = io.Copy(guardWriter(bundlePath, f), reader)
 	if err != nil {
+		if errors.Is(err, ErrInsufficientDiskSpace) {
+			// a partial file is removed, so that the bundle only has files that were collected in full
+			f.Close()
+			os.Remove(f.Name())
+			delete(r, relativePath)
+		}
 		return errors.Wrap(err, "failed to copy data")
 	}
 
@@ -56,13 +206,8 @@ func (r CollectorResult) SaveResult(bundlePath string, relativePath string, read
 }
 
 func (r CollectorResult) ReplaceResult(bundlePath string, relativePath string, reader io.Reader) error {
-	if bundlePath == "" {
-		data, err := ioutil.ReadAll(reader)
-		if err != nil {
-			return errors.Wrap(err, "failed to read data")
-		}
-		r[relativePath] = data
-		return nil
+	if dir, ok := spillDirFor(bundlePath); ok {
+		return r.saveSpilled(dir, relativePath, reader)
 	}
 
 	tmpFile, err := ioutil.TempFile("", "replace-")
@@ -103,10 +248,32 @@ func (r CollectorResult) GetReader(bundlePath string, relativePath string) (io.R
 	return f, nil
 }
 
+// ReadResult returns the contents of a result, reading it from disk if it is not held in memory
+func (r CollectorResult) ReadResult(bundlePath string, relativePath string) ([]byte, error) {
+	if data := r[relativePath]; data != nil {
+		return data, nil
+	}
+
+	reader, err := r.GetReader(bundlePath, relativePath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read result")
+	}
+
+	return data, nil
+}
+
+// GetWriter returns a writer for streaming a result. Without a bundle path, the result is kept in memory,
+// and with a spill directory it is kept in memory unless it grows large enough to be spilled to a file.
+// The writer must be closed with CloseWriter.
 func (r CollectorResult) GetWriter(bundlePath string, relativePath string) (io.Writer, error) {
-	if bundlePath == "" {
-		var b bytes.Buffer
-		return &b, nil
+	if dir, ok := spillDirFor(bundlePath); ok {
+		return &spillWriter{dir: dir, relativePath: relativePath}, nil
 	}
 
 	fileDir, _ := filepath.Split(relativePath)
@@ -123,10 +290,27 @@ func (r CollectorResult) GetWriter(bundlePath string, relativePath string) (io.W
 
 	r[relativePath] = nil // save the the file name referencing the file on disk
 
-	return f, nil
+	return guardWriter(bundlePath, f), nil
 }
 
 func (r CollectorResult) CloseWriter(bundlePath string, relativePath string, writer interface{}) error {
+	if w, ok := writer.(*spillWriter); ok {
+		data, err := w.close()
+		if err != nil {
+			return err
+		}
+		r[relativePath] = data
+		return nil
+	}
+
+	if w, ok := writer.(*guardedWriter); ok && w.err != nil {
+		// a partial file is removed, so that the bundle only has files that were collected in full
+		w.Close()
+		os.Remove(w.file.Name())
+		delete(r, relativePath)
+		return errors.Wrap(w.err, "failed to write file")
+	}
+
 	if c, ok := writer.(io.Closer); ok {
 		return errors.Wrap(c.Close(), "failed to close writer")
 	}
@@ -144,20 +328,171 @@ func (r CollectorResult) CloseWriter(bundlePath string, relativePath string, wri
 	return errors.Errorf("cannot close writer of type %T", writer)
 }
 
+// Size returns the number of bytes in a result, including the files that are on disk. Files that can't
+// be found are not counted.
+func (r CollectorResult) Size(bundlePath string) int64 {
+	var size int64
+	for relativePath, data := range r {
+		if data != nil {
+			size += int64(len(data))
+			continue
+		}
+
+		if bundlePath == "" {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(bundlePath, relativePath)); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
+// ArchiveOptions are how the archive of a support bundle is written
+type ArchiveOptions struct {
+	// Reproducible archives are the same bytes for the same files, so that archives of bundles collected
+	// from the same cluster state can be diffed and deduplicated. The files are written with zeroed
+	// timestamps and owners and the same permissions, so those of the collected files are not kept, and
+	// in a directory named ReproducibleArchiveDir rather than after the bundle.
+	Reproducible bool
+}
+
+// ReproducibleArchiveDir is the directory the files of a reproducible archive are in
+const ReproducibleArchiveDir = "support-bundle"
+
+// reproducibleModTime is the modification time of the files of a reproducible archive
+var reproducibleModTime = time.Unix(0, 0)
+
+// archiveDir returns the directory in the archive that the files of the bundle at bundlePath are in
+func (o ArchiveOptions) archiveDir(bundlePath string) string {
+	if o.Reproducible {
+		return ReproducibleArchiveDir
+	}
+	return filepath.Base(bundlePath)
+}
+
 func TarSupportBundleDir(bundlePath string, input CollectorResult, outputFilename string) error {
+	return TarSupportBundleDirWithOptions(bundlePath, input, outputFilename, ArchiveOptions{})
+}
+
+func TarSupportBundleDirWithOptions(bundlePath string, input CollectorResult, outputFilename string, opts ArchiveOptions) error {
 	fileWriter, err := os.Create(outputFilename)
 	if err != nil {
 		return errors.Wrap(err, "failed to create output file")
 	}
 	defer fileWriter.Close()
 
-	gzipWriter := gzip.NewWriter(fileWriter)
+	if err := WriteSupportBundleArchiveWithOptions(bundlePath, input, fileWriter, opts); err != nil {
+		return err
+	}
+
+	return fileWriter.Close()
+}
+
+// sortedNames returns the relative paths of the files of a result in order, so that bundles are written
+// the same way every time
+func (r CollectorResult) sortedNames() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteSupportBundleDir copies the files of a support bundle to outputDir instead of archiving them,
+// followed by the same manifest an archive has. outputDir must be empty or not exist, so that files of
+// an earlier bundle are not mixed in.
+func WriteSupportBundleDir(bundlePath string, input CollectorResult, outputDir string) error {
+	entries, err := ioutil.ReadDir(outputDir)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to read output dir")
+	}
+	if len(entries) > 0 {
+		return errors.Errorf("output dir %s is not empty", outputDir)
+	}
+
+	manifest := &manifestWriter{contentTypes: readContentTypes(bundlePath, input)}
+
+	for _, relativeName := range input.sortedNames() {
+		if relativeName == ManifestFilename {
+			// the manifest is written for the files in this directory
+			continue
+		}
+
+		filename := filepath.Join(bundlePath, relativeName)
+		info, err := os.Stat(filename)
+		if err != nil {
+			return errors.Wrap(err, "failed to stat file")
+		}
+		if !info.Mode().IsRegular() { // support bundle can have only files
+			continue
+		}
+
+		err = func() error {
+			fileReader, err := os.Open(filename)
+			if err != nil {
+				return errors.Wrap(err, "failed to open source file")
+			}
+			defer fileReader.Close()
+
+			outputFilename := filepath.Join(outputDir, relativeName)
+			if err := os.MkdirAll(filepath.Dir(outputFilename), 0755); err != nil {
+				return errors.Wrap(err, "failed to create output dir")
+			}
+			fileWriter, err := os.OpenFile(outputFilename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+			if err != nil {
+				return errors.Wrap(err, "failed to create output file")
+			}
+			defer fileWriter.Close()
+
+			h := sha256.New()
+			size, err := io.Copy(io.MultiWriter(fileWriter, h), fileReader)
+			if err != nil {
+				return errors.Wrap(err, "failed to copy file to output dir")
+			}
+			manifest.add(relativeName, size, h)
+
+			return fileWriter.Close()
+		}()
+		if err != nil {
+			return err
+		}
+	}
+
+	manifestData, err := manifest.marshal()
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(outputDir, ManifestFilename), manifestData, 0644); err != nil {
+		return errors.Wrap(err, "failed to write manifest")
+	}
+
+	return nil
+}
+
+// WriteSupportBundleArchive writes the files of a support bundle to w as a .tar.gz archive, followed by a
+// manifest with the SHA-256 and content type of each file
+func WriteSupportBundleArchive(bundlePath string, input CollectorResult, w io.Writer) error {
+	return WriteSupportBundleArchiveWithOptions(bundlePath, input, w, ArchiveOptions{})
+}
+
+func WriteSupportBundleArchiveWithOptions(bundlePath string, input CollectorResult, w io.Writer, opts ArchiveOptions) error {
+	gzipWriter := gzip.NewWriter(w)
 	defer gzipWriter.Close()
 
 	tarWriter := tar.NewWriter(gzipWriter)
 	defer tarWriter.Close()
 
-	for relativeName := range input {
+	archiveDir := opts.archiveDir(bundlePath) // this is to have the files inside a subdirectory
+	manifest := &manifestWriter{contentTypes: readContentTypes(bundlePath, input)}
+
+	for _, relativeName := range input.sortedNames() {
+		if relativeName == ManifestFilename {
+			// the manifest is written for the files in this archive
+			continue
+		}
+
 		filename := filepath.Join(bundlePath, relativeName)
 		info, err := os.Stat(filename)
 		if err != nil {
@@ -169,11 +504,7 @@ func TarSupportBundleDir(bundlePath string, input CollectorResult, outputFilenam
 			continue
 		}
 
-		parentDirName := filepath.Dir(bundlePath) // this is to have the files inside a subdirectory
-		nameInArchive, err := filepath.Rel(parentDirName, filename)
-		if err != nil {
-			return errors.Wrap(err, "failed to create relative file name")
-		}
+		nameInArchive := filepath.Join(archiveDir, relativeName)
 
 		// tar.FileInfoHeader call causes a crash in static builds
 		// https://github.com/golang/go/issues/24787
@@ -184,6 +515,10 @@ func TarSupportBundleDir(bundlePath string, input CollectorResult, outputFilenam
 			Typeflag: tar.TypeReg,
 			Size:     info.Size(),
 		}
+		if opts.Reproducible {
+			hdr.ModTime = reproducibleModTime
+			hdr.Mode = 0644
+		}
 
 		err = tarWriter.WriteHeader(hdr)
 		if err != nil {
@@ -197,10 +532,126 @@ func TarSupportBundleDir(bundlePath string, input CollectorResult, outputFilenam
 			}
 			defer fileReader.Close()
 
-			_, err = io.Copy(tarWriter, fileReader)
+			h := sha256.New()
+			size, err := io.Copy(io.MultiWriter(tarWriter, h), fileReader)
+			if err != nil {
+				return errors.Wrap(err, "failed to copy file into archive")
+			}
+			manifest.add(relativeName, size, h)
+
+			return nil
+		}()
+		if err != nil {
+			return err
+		}
+	}
+
+	manifestData, err := manifest.marshal()
+	if err != nil {
+		return err
+	}
+
+	nameInArchive := filepath.Join(archiveDir, ManifestFilename)
+
+	hdr := &tar.Header{
+		Name:     nameInArchive,
+		ModTime:  time.Now(),
+		Mode:     0644,
+		Typeflag: tar.TypeReg,
+		Size:     int64(len(manifestData)),
+	}
+	if opts.Reproducible {
+		hdr.ModTime = reproducibleModTime
+	}
+	if err := tarWriter.WriteHeader(hdr); err != nil {
+		return errors.Wrap(err, "failed to write manifest tar header")
+	}
+	if _, err := tarWriter.Write(manifestData); err != nil {
+		return errors.Wrap(err, "failed to write manifest into archive")
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return errors.Wrap(err, "failed to close tar writer")
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return errors.Wrap(err, "failed to close gzip writer")
+	}
+
+	return nil
+}
+
+// ZipSupportBundleDir writes the files of a support bundle to outputFilename as a .zip archive, for
+// workflows that can't open a .tar.gz
+func ZipSupportBundleDir(bundlePath string, input CollectorResult, outputFilename string, opts ArchiveOptions) error {
+	fileWriter, err := os.Create(outputFilename)
+	if err != nil {
+		return errors.Wrap(err, "failed to create output file")
+	}
+	defer fileWriter.Close()
+
+	if err := WriteSupportBundleZip(bundlePath, input, fileWriter, opts); err != nil {
+		return err
+	}
+
+	return fileWriter.Close()
+}
+
+// WriteSupportBundleZip writes the files of a support bundle to w as a .zip archive, with the same layout
+// and manifest as WriteSupportBundleArchive
+func WriteSupportBundleZip(bundlePath string, input CollectorResult, w io.Writer, opts ArchiveOptions) error {
+	zipWriter := zip.NewWriter(w)
+	defer zipWriter.Close()
+
+	archiveDir := opts.archiveDir(bundlePath) // this is to have the files inside a subdirectory
+	manifest := &manifestWriter{contentTypes: readContentTypes(bundlePath, input)}
+
+	for _, relativeName := range input.sortedNames() {
+		if relativeName == ManifestFilename {
+			// the manifest is written for the files in this archive
+			continue
+		}
+
+		filename := filepath.Join(bundlePath, relativeName)
+		info, err := os.Stat(filename)
+		if err != nil {
+			return errors.Wrap(err, "failed to stat file")
+		}
+		if !info.Mode().IsRegular() { // support bundle can have only files
+			continue
+		}
+
+		nameInArchive := filepath.Join(archiveDir, relativeName)
+
+		hdr := &zip.FileHeader{
+			Name:     filepath.ToSlash(nameInArchive),
+			Modified: info.ModTime(),
+			Method:   zip.Deflate,
+		}
+		hdr.SetMode(info.Mode().Perm())
+		if opts.Reproducible {
+			// zip timestamps can't be before 1980, so they are left out
+			hdr.Modified = time.Time{}
+			hdr.SetMode(0644)
+		}
+
+		fileWriter, err := zipWriter.CreateHeader(hdr)
+		if err != nil {
+			return errors.Wrap(err, "failed to write zip header")
+		}
+
+		err = func() error {
+			fileReader, err := os.Open(filename)
+			if err != nil {
+				return errors.Wrap(err, "failed to open source file")
+			}
+			defer fileReader.Close()
+
+			h := sha256.New()
+			size, err := io.Copy(io.MultiWriter(fileWriter, h), fileReader)
 			if err != nil {
 				return errors.Wrap(err, "failed to copy file into archive")
 			}
+			manifest.add(relativeName, size, h)
 
 			return nil
 		}()
@@ -209,5 +660,33 @@ func TarSupportBundleDir(bundlePath string, input CollectorResult, outputFilenam
 		}
 	}
 
+	manifestData, err := manifest.marshal()
+	if err != nil {
+		return err
+	}
+
+	nameInArchive := filepath.Join(archiveDir, ManifestFilename)
+
+	hdr := &zip.FileHeader{
+		Name:     filepath.ToSlash(nameInArchive),
+		Modified: time.Now(),
+		Method:   zip.Deflate,
+	}
+	if opts.Reproducible {
+		hdr.Modified = time.Time{}
+	}
+	hdr.SetMode(0644)
+	manifestFile, err := zipWriter.CreateHeader(hdr)
+	if err != nil {
+		return errors.Wrap(err, "failed to write manifest zip header")
+	}
+	if _, err := manifestFile.Write(manifestData); err != nil {
+		return errors.Wrap(err, "failed to write manifest into archive")
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return errors.Wrap(err, "failed to close zip writer")
+	}
+
 	return nil
 }
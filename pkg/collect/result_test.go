@@ -0,0 +1,98 @@
+package collect
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CollectorResultSpill(t *testing.T) {
+	defer func(size int) { maxInMemoryResultSize = size }(maxInMemoryResultSize)
+	maxInMemoryResultSize = 10
+
+	dir, err := NewSpillDir()
+	require.NoError(t, err)
+	defer RemoveSpillDir(dir)
+
+	result := NewResult()
+
+	// results that fit stay in memory
+	err = result.SaveResult(dir, "small.txt", strings.NewReader("small"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("small"), result["small.txt"])
+
+	// larger results are spilled to disk and read back when needed
+	large := strings.Repeat("0123456789", 100)
+	err = result.SaveResult(dir, "dir/large.txt", strings.NewReader(large))
+	require.NoError(t, err)
+	assert.Contains(t, result, "dir/large.txt")
+	assert.Nil(t, result["dir/large.txt"])
+
+	data, err := result.ReadResult(dir, "dir/large.txt")
+	require.NoError(t, err)
+	assert.Equal(t, large, string(data))
+
+	// streamed results
+	w, err := result.GetWriter(dir, "streamed.log")
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err := w.Write([]byte("line\n"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, result.CloseWriter(dir, "streamed.log", w))
+	assert.Nil(t, result["streamed.log"])
+
+	reader, err := result.GetReader(dir, "streamed.log")
+	require.NoError(t, err)
+	defer reader.Close()
+	data, err = ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("line\n", 10), string(data))
+
+	// an empty stream is not mistaken for a file on disk
+	w, err = result.GetWriter(dir, "empty.log")
+	require.NoError(t, err)
+	require.NoError(t, result.CloseWriter(dir, "empty.log", w))
+	assert.Equal(t, []byte{}, result["empty.log"])
+
+	// a spilled result can be replaced from its own contents, and moves back into memory if it shrinks
+	reader, err = result.GetReader(dir, "dir/large.txt")
+	require.NoError(t, err)
+	err = result.ReplaceResult(dir, "dir/large.txt", io.LimitReader(reader, 8))
+	reader.Close()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("01234567"), result["dir/large.txt"])
+
+	_, err = result.GetReader(dir, "missing.txt")
+	assert.Error(t, err)
+
+	// spilled results are counted from their files
+	assert.Equal(t, int64(len("small")+8+len(strings.Repeat("line\n", 10))), result.Size(dir))
+
+	require.NoError(t, RemoveSpillDir(dir))
+	_, err = result.ReadResult(dir, "streamed.log")
+	assert.Error(t, err)
+}
+
+func Test_CollectorResultInMemory(t *testing.T) {
+	defer func(size int) { maxInMemoryResultSize = size }(maxInMemoryResultSize)
+	maxInMemoryResultSize = 10
+
+	// without a spill dir, large results are kept in memory rather than written to a shared temp dir
+	result := NewResult()
+	large := strings.Repeat("0123456789", 100)
+	err := result.SaveResult("", "large.txt", strings.NewReader(large))
+	require.NoError(t, err)
+	assert.Equal(t, []byte(large), result["large.txt"])
+
+	w, err := result.GetWriter("", "streamed.log")
+	require.NoError(t, err)
+	_, err = w.Write([]byte(large))
+	require.NoError(t, err)
+	require.NoError(t, result.CloseWriter("", "streamed.log", w))
+	assert.Equal(t, []byte(large), result["streamed.log"])
+}
@@ -24,7 +24,7 @@ func (c *CollectRun) Title() string {
 }
 
 func (c *CollectRun) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 func (c *CollectRun) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
@@ -20,6 +20,7 @@ import (
 
 	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 type CollectRunPod struct {
@@ -37,13 +38,13 @@ func (c *CollectRunPod) Title() string {
 }
 
 func (c *CollectRunPod) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 func (c *CollectRunPod) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
-	ctx := context.Background()
+	ctx := contextOrBackground(c.Context)
 
-	client, err := kubernetes.NewForConfig(c.ClientConfig)
+	client, err := kubernetesClientOrDefault(c.Client, c.ClientConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create client from config")
 	}
@@ -68,7 +69,7 @@ func (c *CollectRunPod) Collect(progressChan chan<- interface{}) (CollectorResul
 		}()
 	}
 	if c.Collector.Timeout == "" {
-		return runWithoutTimeout(ctx, c.BundlePath, c.ClientConfig, pod, c.Collector)
+		return runWithoutTimeout(ctx, c.BundlePath, client, pod, c.Collector)
 	}
 
 	timeout, err := time.ParseDuration(c.Collector.Timeout)
@@ -83,7 +84,7 @@ func (c *CollectRunPod) Collect(progressChan chan<- interface{}) (CollectorResul
 	defer cancel()
 
 	go func() {
-		b, err := runWithoutTimeout(timeoutCtx, c.BundlePath, c.ClientConfig, pod, c.Collector)
+		b, err := runWithoutTimeout(timeoutCtx, c.BundlePath, client, pod, c.Collector)
 		if err != nil {
 			errCh <- err
 		} else {
@@ -101,7 +102,7 @@ func (c *CollectRunPod) Collect(progressChan chan<- interface{}) (CollectorResul
 	}
 }
 
-func runPodWithSpec(ctx context.Context, client *kubernetes.Clientset, runPodCollector *troubleshootv1beta2.RunPod) (*corev1.Pod, error) {
+func runPodWithSpec(ctx context.Context, client kubernetes.Interface, runPodCollector *troubleshootv1beta2.RunPod) (*corev1.Pod, error) {
 	podLabels := make(map[string]string)
 	podLabels["troubleshoot-role"] = "run-collector"
 
@@ -146,12 +147,7 @@ func runPodWithSpec(ctx context.Context, client *kubernetes.Clientset, runPodCol
 	return created, nil
 }
 
-func runWithoutTimeout(ctx context.Context, bundlePath string, clientConfig *rest.Config, pod *corev1.Pod, runPodCollector *troubleshootv1beta2.RunPod) (CollectorResult, error) {
-	client, err := kubernetes.NewForConfig(clientConfig)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed create client from config")
-	}
-
+func runWithoutTimeout(ctx context.Context, bundlePath string, client kubernetes.Interface, pod *corev1.Pod, runPodCollector *troubleshootv1beta2.RunPod) (CollectorResult, error) {
 	for {
 		status, err := client.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
 		if err != nil {
@@ -179,7 +175,7 @@ func runWithoutTimeout(ctx context.Context, bundlePath string, clientConfig *res
 	limits := troubleshootv1beta2.LogLimits{
 		MaxLines: 10000,
 	}
-	podLogs, err := savePodLogs(ctx, bundlePath, client, *pod, collectorName, "", &limits, true)
+	podLogs, err := savePodLogs(ctx, bundlePath, client, *pod, collectorName, "", &limits, logsAll, true)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get pod logs")
 	}
@@ -255,6 +251,8 @@ type RunPodOptions struct {
 	Command             []string
 	ImagePullSecretName string
 	HostNetwork         bool
+	// NodeSelector selects the nodes to run pods on by label, all ready nodes if it is not set
+	NodeSelector map[string]string
 }
 
 func RunPodsReadyNodes(ctx context.Context, client v1.CoreV1Interface, opts RunPodOptions) (map[string][]byte, error) {
@@ -262,7 +260,9 @@ func RunPodsReadyNodes(ctx context.Context, client v1.CoreV1Interface, opts RunP
 	mtx := sync.Mutex{}
 	nodeLogs := map[string][]byte{}
 
-	nodes, err := client.Nodes().List(ctx, metav1.ListOptions{})
+	nodes, err := client.Nodes().List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(opts.NodeSelector).String(),
+	})
 	if err != nil {
 		return nil, errors.Wrapf(err, "list nodes")
 	}
@@ -41,7 +41,7 @@ func (c *CollectSecret) Title() string {
 }
 
 func (c *CollectSecret) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 func (c *CollectSecret) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
@@ -0,0 +1,342 @@
+package collect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+type ServiceEndpointsInfo struct {
+	Namespace string             `json:"namespace"`
+	Name      string             `json:"name"`
+	Type      corev1.ServiceType `json:"type"`
+	Selector  map[string]string  `json:"selector,omitempty"`
+	Ports     []ServicePortInfo  `json:"ports"`
+	// Endpoints are the addresses in the service's Endpoints
+	Endpoints      []ServiceEndpoint          `json:"endpoints"`
+	EndpointSlices []ServiceEndpointSliceInfo `json:"endpointSlices"`
+	// Pods are the pods that the service selects
+	Pods []ServicePod `json:"pods"`
+	// Issues are why the service has no ready endpoints, or has ports that no pod serves
+	Issues []string `json:"issues"`
+}
+
+type ServicePortInfo struct {
+	Name       string          `json:"name,omitempty"`
+	Protocol   corev1.Protocol `json:"protocol"`
+	Port       int32           `json:"port"`
+	TargetPort string          `json:"targetPort"`
+	// MatchingPods are the selected pods with a container port for the target port. Declaring container
+	// ports is optional, so numbered target ports can be served by pods that are not included.
+	MatchingPods []string `json:"matchingPods"`
+}
+
+type ServiceEndpoint struct {
+	IP       string `json:"ip"`
+	Ready    bool   `json:"ready"`
+	Pod      string `json:"pod,omitempty"`
+	NodeName string `json:"nodeName,omitempty"`
+}
+
+type ServiceEndpointSliceInfo struct {
+	Name        string                  `json:"name"`
+	AddressType discoveryv1.AddressType `json:"addressType"`
+	Endpoints   []ServiceEndpoint       `json:"endpoints"`
+}
+
+type ServicePod struct {
+	Name     string          `json:"name"`
+	NodeName string          `json:"nodeName,omitempty"`
+	PodIP    string          `json:"podIP,omitempty"`
+	Phase    corev1.PodPhase `json:"phase"`
+	Ready    bool            `json:"ready"`
+	// Ports are the container ports of the pod, as name:port/protocol
+	Ports []string `json:"ports"`
+}
+
+type CollectServiceEndpoints struct {
+	Collector    *troubleshootv1beta2.ServiceEndpoints
+	BundlePath   string
+	Namespace    string
+	ClientConfig *rest.Config
+	Client       kubernetes.Interface
+	Context      context.Context
+	RBACErrors
+}
+
+func (c *CollectServiceEndpoints) Title() string {
+	return getCollectorName(c)
+}
+
+func (c *CollectServiceEndpoints) IsExcluded() (bool, error) {
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
+}
+
+func (c *CollectServiceEndpoints) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
+	ctx := contextOrBackground(c.Context)
+	output := NewResult()
+
+	dir := "service-endpoints"
+	if c.Collector.CollectorName != "" {
+		dir = path.Join(dir, c.Collector.CollectorName)
+	}
+
+	services, err := c.Client.CoreV1().Services(c.Collector.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: strings.Join(c.Collector.Selector, ","),
+	})
+	if err != nil {
+		output.SaveResult(c.BundlePath, path.Join(dir, "errors.json"), marshalErrors([]string{err.Error()}))
+		return output, nil
+	}
+
+	errorList := map[string]string{}
+	for _, service := range services.Items {
+		info, err := serviceEndpointsInfo(ctx, c.Client, service)
+		if err != nil {
+			errorList[service.Namespace+"/"+service.Name] = err.Error()
+			continue
+		}
+
+		b, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			errorList[service.Namespace+"/"+service.Name] = err.Error()
+			continue
+		}
+		output.SaveResult(c.BundlePath, path.Join(dir, service.Namespace, service.Name+".json"), bytes.NewBuffer(b))
+	}
+	output.SaveResult(c.BundlePath, path.Join(dir, "errors.json"), marshalErrors(errorList))
+
+	return output, nil
+}
+
+func serviceEndpointsInfo(ctx context.Context, client kubernetes.Interface, service corev1.Service) (*ServiceEndpointsInfo, error) {
+	info := &ServiceEndpointsInfo{
+		Namespace:      service.Namespace,
+		Name:           service.Name,
+		Type:           service.Spec.Type,
+		Selector:       service.Spec.Selector,
+		Ports:          []ServicePortInfo{},
+		Endpoints:      []ServiceEndpoint{},
+		EndpointSlices: []ServiceEndpointSliceInfo{},
+		Pods:           []ServicePod{},
+		Issues:         []string{},
+	}
+
+	// endpoints
+	endpoints, err := client.CoreV1().Endpoints(service.Namespace).Get(ctx, service.Name, metav1.GetOptions{})
+	if err != nil && !kuberneteserrors.IsNotFound(err) {
+		return nil, err
+	} else if err == nil {
+		for _, subset := range endpoints.Subsets {
+			for _, address := range subset.Addresses {
+				info.Endpoints = append(info.Endpoints, endpointAddressToOutput(address, true))
+			}
+			for _, address := range subset.NotReadyAddresses {
+				info.Endpoints = append(info.Endpoints, endpointAddressToOutput(address, false))
+			}
+		}
+	}
+
+	slices, err := client.DiscoveryV1().EndpointSlices(service.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: service.Name}).String(),
+	})
+	if err != nil && !kuberneteserrors.IsNotFound(err) {
+		return nil, err
+	} else if err == nil {
+		for _, slice := range slices.Items {
+			info.EndpointSlices = append(info.EndpointSlices, endpointSliceToOutput(slice))
+		}
+	}
+
+	// ExternalName services and services without a selector have no pods, their endpoints are managed
+	// outside of kubernetes
+	if service.Spec.Type == corev1.ServiceTypeExternalName || len(service.Spec.Selector) == 0 {
+		for _, port := range service.Spec.Ports {
+			info.Ports = append(info.Ports, servicePortToOutput(port, nil))
+		}
+		return info, nil
+	}
+
+	pods, err := client.CoreV1().Pods(service.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(service.Spec.Selector).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	readyPods := 0
+	for _, pod := range pods.Items {
+		servicePod := podToServicePod(pod)
+		if servicePod.Ready {
+			readyPods++
+		}
+		info.Pods = append(info.Pods, servicePod)
+	}
+
+	for _, port := range service.Spec.Ports {
+		portInfo := servicePortToOutput(port, pods.Items)
+		info.Ports = append(info.Ports, portInfo)
+
+		if port.TargetPort.StrVal != "" && len(portInfo.MatchingPods) == 0 && len(pods.Items) > 0 {
+			info.Issues = append(info.Issues, fmt.Sprintf("no selected pod has a container port named %s for port %d", port.TargetPort.StrVal, port.Port))
+		}
+	}
+
+	readyEndpoints := 0
+	for _, endpoint := range info.Endpoints {
+		if endpoint.Ready {
+			readyEndpoints++
+		}
+	}
+	for _, slice := range info.EndpointSlices {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Ready {
+				readyEndpoints++
+			}
+		}
+	}
+
+	switch {
+	case len(pods.Items) == 0:
+		info.Issues = append(info.Issues, fmt.Sprintf("no pods match the selector %s", labels.SelectorFromSet(service.Spec.Selector)))
+	case readyPods == 0:
+		info.Issues = append(info.Issues, fmt.Sprintf("none of the %d selected pods are ready", len(pods.Items)))
+	case readyEndpoints == 0:
+		info.Issues = append(info.Issues, fmt.Sprintf("the service has no ready endpoints, but %d selected pods are ready", readyPods))
+	}
+
+	return info, nil
+}
+
+func endpointAddressToOutput(address corev1.EndpointAddress, ready bool) ServiceEndpoint {
+	endpoint := ServiceEndpoint{
+		IP:    address.IP,
+		Ready: ready,
+	}
+	if address.NodeName != nil {
+		endpoint.NodeName = *address.NodeName
+	}
+	if address.TargetRef != nil && address.TargetRef.Kind == "Pod" {
+		endpoint.Pod = address.TargetRef.Name
+	}
+	return endpoint
+}
+
+func endpointSliceToOutput(slice discoveryv1.EndpointSlice) ServiceEndpointSliceInfo {
+	info := ServiceEndpointSliceInfo{
+		Name:        slice.Name,
+		AddressType: slice.AddressType,
+		Endpoints:   []ServiceEndpoint{},
+	}
+
+	for _, endpoint := range slice.Endpoints {
+		// endpoints with an unknown condition are ready
+		ready := endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+
+		for _, address := range endpoint.Addresses {
+			output := ServiceEndpoint{
+				IP:    address,
+				Ready: ready,
+			}
+			if endpoint.NodeName != nil {
+				output.NodeName = *endpoint.NodeName
+			}
+			if endpoint.TargetRef != nil && endpoint.TargetRef.Kind == "Pod" {
+				output.Pod = endpoint.TargetRef.Name
+			}
+			info.Endpoints = append(info.Endpoints, output)
+		}
+	}
+
+	return info
+}
+
+func servicePortToOutput(port corev1.ServicePort, pods []corev1.Pod) ServicePortInfo {
+	protocol := port.Protocol
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+
+	// the target port is the port when it is not set
+	targetPort := port.TargetPort.String()
+	if port.TargetPort.IntVal == 0 && port.TargetPort.StrVal == "" {
+		targetPort = fmt.Sprintf("%d", port.Port)
+	}
+
+	info := ServicePortInfo{
+		Name:         port.Name,
+		Protocol:     protocol,
+		Port:         port.Port,
+		TargetPort:   targetPort,
+		MatchingPods: []string{},
+	}
+
+	for _, pod := range pods {
+		if podHasPort(pod, targetPort, protocol) {
+			info.MatchingPods = append(info.MatchingPods, pod.Name)
+		}
+	}
+	sort.Strings(info.MatchingPods)
+
+	return info
+}
+
+// podHasPort returns true if a container of pod has a port with the name or number of targetPort
+func podHasPort(pod corev1.Pod, targetPort string, protocol corev1.Protocol) bool {
+	for _, container := range pod.Spec.Containers {
+		for _, containerPort := range container.Ports {
+			containerProtocol := containerPort.Protocol
+			if containerProtocol == "" {
+				containerProtocol = corev1.ProtocolTCP
+			}
+			if containerProtocol != protocol {
+				continue
+			}
+			if containerPort.Name == targetPort || fmt.Sprintf("%d", containerPort.ContainerPort) == targetPort {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func podToServicePod(pod corev1.Pod) ServicePod {
+	servicePod := ServicePod{
+		Name:     pod.Name,
+		NodeName: pod.Spec.NodeName,
+		PodIP:    pod.Status.PodIP,
+		Phase:    pod.Status.Phase,
+		Ports:    []string{},
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			servicePod.Ready = condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			protocol := port.Protocol
+			if protocol == "" {
+				protocol = corev1.ProtocolTCP
+			}
+			servicePod.Ports = append(servicePod.Ports, fmt.Sprintf("%s:%d/%s", port.Name, port.ContainerPort, protocol))
+		}
+	}
+
+	return servicePod
+}
@@ -0,0 +1,234 @@
+package collect
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_serviceEndpointsInfo(t *testing.T) {
+	ready := true
+
+	tests := []struct {
+		name         string
+		service      *corev1.Service
+		pods         []*corev1.Pod
+		endpoints    *corev1.Endpoints
+		slice        *discoveryv1.EndpointSlice
+		wantIssues   []string
+		wantMatching []string
+	}{
+		{
+			name: "ready endpoints",
+			service: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Labels: map[string]string{"tier": "frontend"}},
+				Spec: corev1.ServiceSpec{
+					Type:     corev1.ServiceTypeClusterIP,
+					Selector: map[string]string{"app": "web"},
+					Ports:    []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromString("http")}},
+				},
+			},
+			pods: []*corev1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: map[string]string{"app": "web"}},
+					Spec: corev1.PodSpec{
+						NodeName:   "node-1",
+						Containers: []corev1.Container{{Name: "web", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}}}},
+					},
+					Status: corev1.PodStatus{
+						Phase:      corev1.PodRunning,
+						PodIP:      "10.0.0.1",
+						Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+					},
+				},
+			},
+			slice: &discoveryv1.EndpointSlice{
+				ObjectMeta:  metav1.ObjectMeta{Name: "web-abc", Namespace: "default", Labels: map[string]string{discoveryv1.LabelServiceName: "web"}},
+				AddressType: discoveryv1.AddressTypeIPv4,
+				Endpoints: []discoveryv1.Endpoint{{
+					Addresses:  []string{"10.0.0.1"},
+					Conditions: discoveryv1.EndpointConditions{Ready: &ready},
+					TargetRef:  &corev1.ObjectReference{Kind: "Pod", Name: "web-1"},
+				}},
+			},
+			wantIssues:   []string{},
+			wantMatching: []string{"web-1"},
+		},
+		{
+			name: "no pods",
+			service: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Labels: map[string]string{"tier": "frontend"}},
+				Spec: corev1.ServiceSpec{
+					Type:     corev1.ServiceTypeClusterIP,
+					Selector: map[string]string{"app": "api"},
+					Ports:    []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromInt(8080)}},
+				},
+			},
+			pods: []*corev1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: map[string]string{"app": "web"}},
+					Spec: corev1.PodSpec{
+						NodeName:   "node-1",
+						Containers: []corev1.Container{{Name: "web", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}}}},
+					},
+					Status: corev1.PodStatus{
+						Phase:      corev1.PodRunning,
+						PodIP:      "10.0.0.1",
+						Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+					},
+				},
+			},
+			wantIssues:   []string{"no pods match the selector app=api"},
+			wantMatching: []string{},
+		},
+		{
+			name: "pods not ready",
+			service: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Labels: map[string]string{"tier": "frontend"}},
+				Spec: corev1.ServiceSpec{
+					Type:     corev1.ServiceTypeClusterIP,
+					Selector: map[string]string{"app": "web"},
+					Ports:    []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromInt(8080)}},
+				},
+			},
+			pods: []*corev1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: map[string]string{"app": "web"}},
+					Spec: corev1.PodSpec{
+						NodeName:   "node-1",
+						Containers: []corev1.Container{{Name: "web", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}}}},
+					},
+					Status: corev1.PodStatus{
+						Phase:      corev1.PodRunning,
+						PodIP:      "10.0.0.1",
+						Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}},
+					},
+				},
+			},
+			endpoints: &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Subsets: []corev1.EndpointSubset{{
+					NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.1", TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "web-1"}}},
+				}},
+			},
+			wantIssues:   []string{"none of the 1 selected pods are ready"},
+			wantMatching: []string{"web-1"},
+		},
+		{
+			name: "named port not found",
+			service: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Labels: map[string]string{"tier": "frontend"}},
+				Spec: corev1.ServiceSpec{
+					Type:     corev1.ServiceTypeClusterIP,
+					Selector: map[string]string{"app": "web"},
+					Ports:    []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromString("metrics")}},
+				},
+			},
+			pods: []*corev1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: map[string]string{"app": "web"}},
+					Spec: corev1.PodSpec{
+						NodeName:   "node-1",
+						Containers: []corev1.Container{{Name: "web", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}}}},
+					},
+					Status: corev1.PodStatus{
+						Phase:      corev1.PodRunning,
+						PodIP:      "10.0.0.1",
+						Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+					},
+				},
+			},
+			wantIssues: []string{
+				"no selected pod has a container port named metrics for port 80",
+				"the service has no ready endpoints, but 1 selected pods are ready",
+			},
+			wantMatching: []string{},
+		},
+		{
+			name: "no selector",
+			service: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "default", Labels: map[string]string{"tier": "frontend"}},
+				Spec: corev1.ServiceSpec{
+					Type:  corev1.ServiceTypeClusterIP,
+					Ports: []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromInt(5432)}},
+				},
+			},
+			wantIssues:   []string{},
+			wantMatching: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(tt.service)
+			for _, pod := range tt.pods {
+				_, err := client.CoreV1().Pods(pod.Namespace).Create(context.Background(), pod, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+			if tt.endpoints != nil {
+				_, err := client.CoreV1().Endpoints("default").Create(context.Background(), tt.endpoints, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+			if tt.slice != nil {
+				_, err := client.DiscoveryV1().EndpointSlices("default").Create(context.Background(), tt.slice, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+
+			info, err := serviceEndpointsInfo(context.Background(), client, *tt.service)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantIssues, info.Issues)
+			require.Len(t, info.Ports, 1)
+			assert.Equal(t, tt.wantMatching, info.Ports[0].MatchingPods)
+		})
+	}
+}
+
+func TestCollectServiceEndpoints_Collect(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Labels: map[string]string{"tier": "frontend"}},
+			Spec: corev1.ServiceSpec{
+				Type:     corev1.ServiceTypeClusterIP,
+				Selector: map[string]string{"app": "web"},
+				Ports:    []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromInt(8080)}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: map[string]string{"app": "web"}},
+			Spec: corev1.PodSpec{
+				NodeName:   "node-1",
+				Containers: []corev1.Container{{Name: "web", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}}},
+			},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				PodIP:      "10.0.0.1",
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		},
+	)
+
+	c := &CollectServiceEndpoints{
+		Collector:  &troubleshootv1beta2.ServiceEndpoints{Selector: []string{"tier=frontend"}},
+		BundlePath: t.TempDir(),
+		Client:     client,
+	}
+	result, err := c.Collect(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"service-endpoints/default/web.json"}, resultPaths(result))
+
+	b, err := result.ReadResult(c.BundlePath, "service-endpoints/default/web.json")
+	require.NoError(t, err)
+	var info ServiceEndpointsInfo
+	require.NoError(t, json.Unmarshal(b, &info))
+	assert.Equal(t, "8080", info.Ports[0].TargetPort)
+	assert.Equal(t, []ServicePod{{Name: "web-1", NodeName: "node-1", PodIP: "10.0.0.1", Phase: corev1.PodRunning, Ready: true, Ports: []string{":8080/TCP"}}}, info.Pods)
+}
@@ -0,0 +1,210 @@
+package collect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"text/tabwriter"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/k8sutil"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	StorageClassesFilename    = "storage-classes.json"
+	PersistentVolumesFilename = "persistent-volumes.json"
+	// StorageStatusFilename is the phase of each persistent volume claim and of its volume, for reading
+	StorageStatusFilename = "status.txt"
+)
+
+type CollectStorage struct {
+	Collector    *troubleshootv1beta2.Storage
+	BundlePath   string
+	Namespace    string
+	ClientConfig *rest.Config
+	Client       kubernetes.Interface
+	Context      context.Context
+	RBACErrors
+}
+
+func (c *CollectStorage) Title() string {
+	return getCollectorName(c)
+}
+
+func (c *CollectStorage) IsExcluded() (bool, error) {
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
+}
+
+func (c *CollectStorage) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
+	ctx := contextOrBackground(c.Context)
+	output := NewResult()
+
+	dir := "storage"
+	if c.Collector.CollectorName != "" {
+		dir = path.Join(dir, c.Collector.CollectorName)
+	}
+
+	errorList := map[string]string{}
+
+	storageClasses := &storagev1.StorageClassList{}
+	err := k8sutil.ListAll(ctx, storageClasses, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return c.Client.StorageV1().StorageClasses().List(ctx, opts)
+	})
+	if err != nil {
+		errorList["storageclasses"] = err.Error()
+	} else if b, err := marshalStorageList(storageClasses); err != nil {
+		errorList["storageclasses"] = err.Error()
+	} else {
+		output.SaveResult(c.BundlePath, path.Join(dir, StorageClassesFilename), bytes.NewBuffer(b))
+	}
+
+	pvs := &corev1.PersistentVolumeList{}
+	err = k8sutil.ListAll(ctx, pvs, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return c.Client.CoreV1().PersistentVolumes().List(ctx, opts)
+	})
+	if err != nil {
+		errorList["persistentvolumes"] = err.Error()
+	} else if b, err := marshalStorageList(pvs); err != nil {
+		errorList["persistentvolumes"] = err.Error()
+	} else {
+		output.SaveResult(c.BundlePath, path.Join(dir, PersistentVolumesFilename), bytes.NewBuffer(b))
+	}
+
+	// claims of all namespaces are listed at once, and saved by namespace like the others
+	namespaces := c.Collector.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	pvcsByNamespace := map[string][]corev1.PersistentVolumeClaim{}
+	for _, namespace := range namespaces {
+		pvcs := &corev1.PersistentVolumeClaimList{}
+		err := k8sutil.ListAll(ctx, pvcs, func(opts metav1.ListOptions) (runtime.Object, error) {
+			return c.Client.CoreV1().PersistentVolumeClaims(namespace).List(ctx, opts)
+		})
+		if err != nil {
+			if namespace == metav1.NamespaceAll {
+				namespace = "all namespaces"
+			}
+			errorList[namespace] = err.Error()
+			continue
+		}
+		for _, pvc := range pvcs.Items {
+			pvcsByNamespace[pvc.Namespace] = append(pvcsByNamespace[pvc.Namespace], pvc)
+		}
+	}
+
+	all := []corev1.PersistentVolumeClaim{}
+	for namespace, pvcs := range pvcsByNamespace {
+		b, err := marshalStorageList(&corev1.PersistentVolumeClaimList{Items: pvcs})
+		if err != nil {
+			errorList[namespace] = err.Error()
+			continue
+		}
+		output.SaveResult(c.BundlePath, path.Join(dir, "pvcs", namespace+".json"), bytes.NewBuffer(b))
+		all = append(all, pvcs...)
+	}
+
+	output.SaveResult(c.BundlePath, path.Join(dir, StorageStatusFilename), bytes.NewBufferString(storageStatus(all, pvs.Items, storageClasses.Items)))
+	output.SaveResult(c.BundlePath, path.Join(dir, "errors.json"), marshalErrors(errorList))
+
+	return output, nil
+}
+
+// marshalStorageList marshals a list with the kinds of the list and its items set, the same as the
+// lists of cluster resources
+func marshalStorageList(list runtime.Object) ([]byte, error) {
+	gvk, err := apiutil.GVKForObject(list, scheme.Scheme)
+	if err == nil {
+		list.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+
+	err = meta.EachListItem(list, func(item runtime.Object) error {
+		gvk, err := apiutil.GVKForObject(item, scheme.Scheme)
+		if err == nil {
+			item.GetObjectKind().SetGroupVersionKind(gvk)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(list, "", "  ")
+}
+
+// storageStatus returns a table of the claims, sorted by namespace and name, with the phase of the volume
+// they are bound to and the provisioner of their storage class. Claims without a storage class use the
+// default one.
+func storageStatus(pvcs []corev1.PersistentVolumeClaim, pvs []corev1.PersistentVolume, storageClasses []storagev1.StorageClass) string {
+	pvsByName := map[string]corev1.PersistentVolume{}
+	for _, pv := range pvs {
+		pvsByName[pv.Name] = pv
+	}
+	provisioners := map[string]string{}
+	defaultClass := ""
+	for _, storageClass := range storageClasses {
+		provisioners[storageClass.Name] = storageClass.Provisioner
+		if IsDefaultStorageClass(storageClass.ObjectMeta) {
+			defaultClass = storageClass.Name
+		}
+	}
+
+	sorted := make([]corev1.PersistentVolumeClaim, len(pvcs))
+	copy(sorted, pvcs)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Namespace != sorted[j].Namespace {
+			return sorted[i].Namespace < sorted[j].Namespace
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tSTATUS\tVOLUME\tVOLUME STATUS\tSTORAGECLASS\tPROVISIONER")
+	for _, pvc := range sorted {
+		storageClass := defaultClass
+		if pvc.Spec.StorageClassName != nil {
+			storageClass = *pvc.Spec.StorageClassName
+		}
+
+		volume, volumeStatus := "<none>", "<none>"
+		if pvc.Spec.VolumeName != "" {
+			volume, volumeStatus = pvc.Spec.VolumeName, "<not found>"
+			if pv, ok := pvsByName[pvc.Spec.VolumeName]; ok {
+				volumeStatus = string(pv.Status.Phase)
+			}
+		}
+
+		provisioner, ok := provisioners[storageClass]
+		switch {
+		case storageClass == "":
+			storageClass, provisioner = "<none>", "<none>"
+		case !ok:
+			provisioner = "<not found>"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", pvc.Namespace, pvc.Name, pvc.Status.Phase, volume, volumeStatus, storageClass, provisioner)
+	}
+	w.Flush()
+
+	return b.String()
+}
+
+// IsDefaultStorageClass returns true if the annotations of a storage class make it the default
+func IsDefaultStorageClass(objectMeta metav1.ObjectMeta) bool {
+	return objectMeta.Annotations["storageclass.kubernetes.io/is-default-class"] == "true" ||
+		objectMeta.Annotations["storageclass.beta.kubernetes.io/is-default-class"] == "true"
+}
@@ -0,0 +1,108 @@
+package collect
+
+import (
+	"encoding/json"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+)
+
+func TestCollectStorage(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&storagev1.StorageClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "standard",
+				Annotations: map[string]string{"storageclass.kubernetes.io/is-default-class": "true"},
+			},
+			Provisioner: "ebs.csi.aws.com",
+		},
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pvc-1"},
+			Status:     corev1.PersistentVolumeStatus{Phase: corev1.VolumeBound},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "data-web-0"},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.String("standard"), VolumeName: "pvc-1"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cache"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "monitoring", Name: "prometheus"},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.String("fast")},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		},
+	)
+
+	collector := &CollectStorage{
+		Collector: &troubleshootv1beta2.Storage{},
+		Client:    client,
+	}
+	result, err := collector.Collect(nil)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{
+		"storage/storage-classes.json",
+		"storage/persistent-volumes.json",
+		"storage/pvcs/default.json",
+		"storage/pvcs/monitoring.json",
+		"storage/status.txt",
+	}, resultFiles(result))
+
+	storageClasses := storagev1.StorageClassList{}
+	require.NoError(t, json.Unmarshal(result["storage/storage-classes.json"], &storageClasses))
+	assert.Equal(t, "StorageClassList", storageClasses.Kind)
+	require.Len(t, storageClasses.Items, 1)
+	assert.Equal(t, "StorageClass", storageClasses.Items[0].Kind)
+
+	pvcs := corev1.PersistentVolumeClaimList{}
+	require.NoError(t, json.Unmarshal(result["storage/pvcs/default.json"], &pvcs))
+	assert.Len(t, pvcs.Items, 2)
+
+	assert.Equal(t, `NAMESPACE   NAME        STATUS   VOLUME  VOLUME STATUS  STORAGECLASS  PROVISIONER
+default     cache       Pending  <none>  <none>         standard      ebs.csi.aws.com
+default     data-web-0  Bound    pvc-1   Bound          standard      ebs.csi.aws.com
+monitoring  prometheus  Pending  <none>  <none>         fast          <not found>
+`, string(result["storage/status.txt"]))
+}
+
+func TestCollectStorage_namespaces(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "data-web-0"},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.String("standard")},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "monitoring", Name: "prometheus"},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.String("standard")},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		},
+	)
+
+	collector := &CollectStorage{
+		Collector: &troubleshootv1beta2.Storage{
+			CollectorMeta: troubleshootv1beta2.CollectorMeta{CollectorName: "app"},
+			Namespaces:    []string{"monitoring"},
+		},
+		Client: client,
+	}
+	result, err := collector.Collect(nil)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{
+		"storage/app/storage-classes.json",
+		"storage/app/persistent-volumes.json",
+		"storage/app/pvcs/monitoring.json",
+		"storage/app/status.txt",
+	}, resultFiles(result))
+}
@@ -3,7 +3,10 @@ package collect
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -16,6 +19,22 @@ import (
 	"k8s.io/client-go/rest"
 )
 
+// defaultSysctlParameters are collected in addition to all of net.ipv4 and net.bridge, as they are
+// commonly required by databases, search engines and file watchers
+var defaultSysctlParameters = []string{
+	"vm.max_map_count",
+	"vm.swappiness",
+	"vm.overcommit_memory",
+	"fs.file-max",
+	"fs.inotify.max_user_instances",
+	"fs.inotify.max_user_watches",
+	"kernel.pid_max",
+	"net.core.somaxconn",
+}
+
+// sysctlParameterRX matches parameter names, which are written into the command of the pod
+var sysctlParameterRX = regexp.MustCompile(`^[a-zA-Z0-9_-]+(\.[a-zA-Z0-9_-]+)*$`)
+
 type CollectSysctl struct {
 	Collector    *troubleshootv1beta2.Sysctl
 	BundlePath   string
@@ -31,7 +50,7 @@ func (c *CollectSysctl) Title() string {
 }
 
 func (c *CollectSysctl) IsExcluded() (bool, error) {
-	return isExcluded(c.Collector.Exclude)
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
 }
 
 func (c *CollectSysctl) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
@@ -62,12 +81,13 @@ func (c *CollectSysctl) Collect(progressChan chan<- interface{}) (CollectorResul
 		ImagePullPolicy: c.Collector.ImagePullPolicy,
 		Namespace:       c.Collector.Namespace,
 		HostNetwork:     true,
+		NodeSelector:    c.Collector.NodeSelector,
 	}
 
-	command := `
-find /proc/sys/net/ipv4 -type f | while read f; do v=$(cat $f 2>/dev/null); echo "$f = $v"; done
-find /proc/sys/net/bridge -type f | while read f; do v=$(cat $f 2>/dev/null); echo "$f = $v"; done
-`
+	command, err := sysctlCommand(c.Collector.Parameters)
+	if err != nil {
+		return nil, err
+	}
 	runPodOptions.Command = []string{"sh", "-c", command}
 
 	if c.Collector.ImagePullSecret != nil {
@@ -102,3 +122,22 @@ find /proc/sys/net/bridge -type f | while read f; do v=$(cat $f 2>/dev/null); ec
 
 	return output, nil
 }
+
+// sysctlCommand returns a shell command that prints each parameter as "/proc/sys/net/ipv4/ip_forward = 1".
+// Parameters that don't exist on the node, such as net.bridge parameters when br_netfilter is not loaded,
+// are not printed.
+func sysctlCommand(parameters []string) (string, error) {
+	paths := []string{}
+	for _, parameter := range append(defaultSysctlParameters, parameters...) {
+		if !sysctlParameterRX.MatchString(parameter) {
+			return "", errors.Errorf("invalid sysctl parameter %q", parameter)
+		}
+		paths = append(paths, "/proc/sys/"+strings.ReplaceAll(parameter, ".", "/"))
+	}
+
+	return fmt.Sprintf(`
+find /proc/sys/net/ipv4 -type f | while read f; do v=$(cat $f 2>/dev/null); echo "$f = $v"; done
+find /proc/sys/net/bridge -type f | while read f; do v=$(cat $f 2>/dev/null); echo "$f = $v"; done
+for f in %s; do if [ -f $f ]; then v=$(cat $f 2>/dev/null); echo "$f = $v"; fi; done
+`, strings.Join(paths, " ")), nil
+}
@@ -0,0 +1,24 @@
+package collect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSysctlCommand(t *testing.T) {
+	command, err := sysctlCommand([]string{"net.core.rmem_max", "net.ipv4.conf.all.rp_filter"})
+	require.NoError(t, err)
+
+	assert.Contains(t, command, "find /proc/sys/net/ipv4 -type f")
+	assert.Contains(t, command, "/proc/sys/vm/max_map_count")
+	assert.Contains(t, command, "/proc/sys/fs/inotify/max_user_watches")
+	assert.Contains(t, command, "/proc/sys/net/core/rmem_max")
+	assert.Contains(t, command, "/proc/sys/net/ipv4/conf/all/rp_filter")
+}
+
+func TestSysctlCommand_InvalidParameter(t *testing.T) {
+	_, err := sysctlCommand([]string{"vm.max_map_count; rm -rf /"})
+	assert.EqualError(t, err, `invalid sysctl parameter "vm.max_map_count; rm -rf /"`)
+}
@@ -0,0 +1,228 @@
+package collect
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// Collector priorities. While the API server is throttling requests, high priority collectors are
+// started before the others and low priority collectors are deferred until the others have started.
+// Collectors without a priority, or with one that isn't known, are normal priority.
+const (
+	CollectorPriorityHigh   = "high"
+	CollectorPriorityNormal = "normal"
+	CollectorPriorityLow    = "low"
+)
+
+// ErrThrottled is the error of a deferred collector that was not run, as the API server was still
+// throttling requests throttleDeferralTimeout after the other collectors had started
+var ErrThrottled = errors.New("deferred while the API server was throttling requests")
+
+// throttleCooldown is how long the API server has to stop throttling requests before the collector
+// concurrency is doubled again, back up to what it was
+var throttleCooldown = 30 * time.Second
+
+// throttleDeferralTimeout is how long deferred collectors wait for the API server to stop throttling
+// requests, once the other collectors have started
+var throttleDeferralTimeout = 2 * time.Minute
+
+// throttlePollInterval is how often deferred collectors check whether the API server is still
+// throttling requests
+var throttlePollInterval = time.Second
+
+// clientThrottleThreshold is how long a request has to wait for the client rate limiter to be counted as
+// throttled, the same as when client-go logs that it is throttling
+const clientThrottleThreshold = time.Second
+
+// maxThrottleLevel is how many times the collector concurrency can be halved
+const maxThrottleLevel = 8
+
+// APIThrottle detects the API server throttling the requests made with the client configs it has
+// wrapped, from 429 Too Many Requests responses and requests that waited on the client rate limiter.
+// Each time requests are throttled the collector concurrency is halved, at most once a second so
+// that a burst of throttled requests only counts once.
+type APIThrottle struct {
+	mu  sync.Mutex
+	now func() time.Time
+	// level is how many times the concurrency has been halved
+	level int
+	// changed is when level last changed
+	changed   time.Time
+	throttled int
+}
+
+func NewAPIThrottle() *APIThrottle {
+	return &APIThrottle{
+		now: time.Now,
+	}
+}
+
+// WrapConfig returns a copy of config whose requests are watched for throttling
+func (t *APIThrottle) WrapConfig(config *rest.Config) *rest.Config {
+	config = rest.CopyConfig(config)
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &throttleRoundTripper{throttle: t, rt: rt}
+	})
+	if config.RateLimiter != nil {
+		config.RateLimiter = &throttleRateLimiter{RateLimiter: config.RateLimiter, throttle: t}
+	}
+	return config
+}
+
+// ThrottledRequests returns how many requests have been throttled
+func (t *APIThrottle) ThrottledRequests() int {
+	if t == nil {
+		return 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.throttled
+}
+
+// Throttled returns whether requests have been throttled within throttleCooldown
+func (t *APIThrottle) Throttled() bool {
+	if t == nil {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.recover()
+	return t.level > 0
+}
+
+// Concurrency returns how many collectors can run at a time, out of max if requests were not throttled
+func (t *APIThrottle) Concurrency(max int) int {
+	if t == nil {
+		return max
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.recover()
+	concurrency := max >> t.level
+	if concurrency < 1 {
+		return 1
+	}
+	return concurrency
+}
+
+// ObserveError counts err as a throttled request when the API server was still throttling it once the
+// client had given up retrying
+func (t *APIThrottle) ObserveError(err error) {
+	if err != nil && kuberneteserrors.IsTooManyRequests(errors.Cause(err)) {
+		t.observe()
+	}
+}
+
+func (t *APIThrottle) observe() {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.recover()
+
+	now := t.now()
+	t.throttled++
+	if t.level == 0 || (t.level < maxThrottleLevel && now.Sub(t.changed) >= time.Second) {
+		t.level++
+	}
+	t.changed = now
+}
+
+// recover undoes a halving of the concurrency for each throttleCooldown without throttled requests
+func (t *APIThrottle) recover() {
+	now := t.now()
+	for t.level > 0 && now.Sub(t.changed) >= throttleCooldown {
+		t.level--
+		t.changed = t.changed.Add(throttleCooldown)
+	}
+}
+
+// waitUntilNotThrottled waits for requests to stop being throttled, and returns false if they still are
+// at deadline or ctx is done first
+func (t *APIThrottle) waitUntilNotThrottled(ctx context.Context, deadline time.Time) bool {
+	for t.Throttled() {
+		if !t.now().Before(deadline) {
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(throttlePollInterval):
+		}
+	}
+	return true
+}
+
+type throttleRoundTripper struct {
+	throttle *APIThrottle
+	rt       http.RoundTripper
+}
+
+func (rt *throttleRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.rt.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		rt.throttle.observe()
+	}
+	return resp, err
+}
+
+type throttleRateLimiter struct {
+	flowcontrol.RateLimiter
+	throttle *APIThrottle
+}
+
+func (l *throttleRateLimiter) Wait(ctx context.Context) error {
+	start := time.Now()
+	err := l.RateLimiter.Wait(ctx)
+	if time.Since(start) >= clientThrottleThreshold {
+		l.throttle.observe()
+	}
+	return err
+}
+
+func (l *throttleRateLimiter) Accept() {
+	start := time.Now()
+	l.RateLimiter.Accept()
+	if time.Since(start) >= clientThrottleThreshold {
+		l.throttle.observe()
+	}
+}
+
+// getCollectorPriority returns the priority in the spec of a collector, which the collectors have in
+// their Collector field
+func getCollectorPriority(c Collector) string {
+	collector := reflect.Indirect(reflect.ValueOf(c))
+	if collector.Kind() != reflect.Struct {
+		return CollectorPriorityNormal
+	}
+
+	spec := collector.FieldByName("Collector")
+	if !spec.IsValid() || spec.Kind() != reflect.Ptr || spec.IsNil() {
+		return CollectorPriorityNormal
+	}
+	meta := spec.Elem().FieldByName("CollectorMeta")
+	if !meta.IsValid() || !meta.CanInterface() {
+		return CollectorPriorityNormal
+	}
+
+	switch priority := meta.Interface().(troubleshootv1beta2.CollectorMeta).Priority; priority {
+	case CollectorPriorityHigh, CollectorPriorityLow:
+		return priority
+	default:
+		return CollectorPriorityNormal
+	}
+}
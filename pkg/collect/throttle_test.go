@@ -0,0 +1,140 @@
+package collect
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+func TestAPIThrottle(t *testing.T) {
+	now := time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)
+	throttle := NewAPIThrottle()
+	throttle.now = func() time.Time { return now }
+
+	assert.False(t, throttle.Throttled())
+	assert.Equal(t, 8, throttle.Concurrency(8))
+
+	throttle.observe()
+	assert.True(t, throttle.Throttled())
+	assert.Equal(t, 4, throttle.Concurrency(8))
+
+	// a burst of throttled requests only halves the concurrency once
+	throttle.observe()
+	assert.Equal(t, 4, throttle.Concurrency(8))
+
+	now = now.Add(time.Second)
+	throttle.observe()
+	assert.Equal(t, 2, throttle.Concurrency(8))
+	assert.Equal(t, 1, throttle.Concurrency(2))
+
+	now = now.Add(throttleCooldown)
+	assert.True(t, throttle.Throttled())
+	assert.Equal(t, 4, throttle.Concurrency(8))
+
+	now = now.Add(throttleCooldown)
+	assert.False(t, throttle.Throttled())
+	assert.Equal(t, 8, throttle.Concurrency(8))
+
+	assert.Equal(t, 3, throttle.ThrottledRequests())
+}
+
+func TestAPIThrottle_nil(t *testing.T) {
+	var throttle *APIThrottle
+	throttle.ObserveError(kuberneteserrors.NewTooManyRequests("", 1))
+	assert.False(t, throttle.Throttled())
+	assert.Equal(t, 3, throttle.Concurrency(3))
+	assert.Equal(t, 0, throttle.ThrottledRequests())
+}
+
+func TestAPIThrottle_WrapConfig(t *testing.T) {
+	tooManyRequests := int32(1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&tooManyRequests) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	throttle := NewAPIThrottle()
+	config := &rest.Config{Host: server.URL}
+	wrapped := throttle.WrapConfig(config)
+	assert.Nil(t, config.WrapTransport)
+
+	transport, err := rest.TransportFor(wrapped)
+	require.NoError(t, err)
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 1, throttle.ThrottledRequests())
+	assert.True(t, throttle.Throttled())
+
+	atomic.StoreInt32(&tooManyRequests, 0)
+	resp, err = client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 1, throttle.ThrottledRequests())
+}
+
+func TestAPIThrottle_ObserveError(t *testing.T) {
+	throttle := NewAPIThrottle()
+
+	throttle.ObserveError(nil)
+	throttle.ObserveError(kuberneteserrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "web"))
+	assert.Equal(t, 0, throttle.ThrottledRequests())
+
+	throttle.ObserveError(errors.Wrap(kuberneteserrors.NewTooManyRequests("the server is busy", 1), "failed to list pods"))
+	assert.Equal(t, 1, throttle.ThrottledRequests())
+}
+
+func Test_getCollectorPriority(t *testing.T) {
+	tests := []struct {
+		name      string
+		collector Collector
+		want      string
+	}{
+		{
+			name:      "not set",
+			collector: &CollectLogs{Collector: &troubleshootv1beta2.Logs{}},
+			want:      CollectorPriorityNormal,
+		},
+		{
+			name:      "low",
+			collector: &CollectLogs{Collector: &troubleshootv1beta2.Logs{CollectorMeta: troubleshootv1beta2.CollectorMeta{Priority: "low"}}},
+			want:      CollectorPriorityLow,
+		},
+		{
+			name:      "high",
+			collector: &CollectSecret{Collector: &troubleshootv1beta2.Secret{CollectorMeta: troubleshootv1beta2.CollectorMeta{Priority: "high"}}},
+			want:      CollectorPriorityHigh,
+		},
+		{
+			name:      "unknown",
+			collector: &CollectLogs{Collector: &troubleshootv1beta2.Logs{CollectorMeta: troubleshootv1beta2.CollectorMeta{Priority: "urgent"}}},
+			want:      CollectorPriorityNormal,
+		},
+		{
+			name:      "no spec",
+			collector: &sleepCollector{},
+			want:      CollectorPriorityNormal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getCollectorPriority(tt.collector))
+		})
+	}
+}
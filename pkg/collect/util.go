@@ -114,7 +114,7 @@ func marshalErrors(errors interface{}) io.Reader {
 
 // listNodesNamesInSelector returns a list of node names matching the label
 // selector,
-func listNodesNamesInSelector(ctx context.Context, client *kubernetes.Clientset, selector string) ([]string, error) {
+func listNodesNamesInSelector(ctx context.Context, client kubernetes.Interface, selector string) ([]string, error) {
 	var names []string
 	nodes, err := listNodesInSelector(ctx, client, selector)
 	if err != nil {
@@ -128,7 +128,7 @@ func listNodesNamesInSelector(ctx context.Context, client *kubernetes.Clientset,
 
 // listNodesInSelector returns a list of node names matching the label
 // selector,
-func listNodesInSelector(ctx context.Context, client *kubernetes.Clientset, selector string) ([]corev1.Node, error) {
+func listNodesInSelector(ctx context.Context, client kubernetes.Interface, selector string) ([]corev1.Node, error) {
 	listOptions := metav1.ListOptions{
 		LabelSelector: selector,
 	}
@@ -0,0 +1,231 @@
+package collect
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// serviceAccountTokenPath is the token of the pod's service account, which logs in to vault when the
+// collector does not have a service account
+var serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// vaultTokenExpirationSeconds is how long the tokens that are requested to log in are valid for
+const vaultTokenExpirationSeconds = 600
+
+// VaultResult is what the vault collector found
+type VaultResult struct {
+	Address string `json:"address"`
+	// IsReachable is true if the server responded to the health check
+	IsReachable bool         `json:"isReachable"`
+	Health      *VaultHealth `json:"health,omitempty"`
+	// Auth is the result of logging in, if the collector logs in
+	Auth  *VaultAuthResult `json:"auth,omitempty"`
+	Error string           `json:"error,omitempty"`
+}
+
+// VaultHealth is the response of /v1/sys/health
+type VaultHealth struct {
+	Initialized bool   `json:"initialized"`
+	Sealed      bool   `json:"sealed"`
+	Standby     bool   `json:"standby"`
+	Version     string `json:"version"`
+	ClusterName string `json:"cluster_name,omitempty"`
+}
+
+type VaultAuthResult struct {
+	Role      string `json:"role"`
+	MountPath string `json:"mountPath"`
+	// ServiceAccount is the service account that logged in, the pod's if it is not set
+	ServiceAccount  string `json:"serviceAccount,omitempty"`
+	IsAuthenticated bool   `json:"isAuthenticated"`
+	// Policies are the policies of the token that vault issued, which is not collected
+	Policies []string `json:"policies,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+type CollectVault struct {
+	Collector    *troubleshootv1beta2.Vault
+	BundlePath   string
+	Namespace    string
+	ClientConfig *rest.Config
+	Client       kubernetes.Interface
+	Context      context.Context
+	RBACErrors
+}
+
+func (c *CollectVault) Title() string {
+	return getCollectorName(c)
+}
+
+func (c *CollectVault) IsExcluded() (bool, error) {
+	return isExcludedWhen(c.Collector.Exclude, c.Collector.When)
+}
+
+func (c *CollectVault) Collect(progressChan chan<- interface{}) (CollectorResult, error) {
+	ctx := contextOrBackground(c.Context)
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: c.Collector.InsecureSkipVerify}
+	client := &http.Client{Transport: transport}
+
+	result := VaultResult{Address: c.Collector.Address}
+
+	health, err := vaultHealth(ctx, client, c.Collector.Address)
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.IsReachable = true
+		result.Health = health
+	}
+
+	// a sealed server can not authenticate, but the error says so
+	if result.IsReachable && c.Collector.KubernetesAuth != nil {
+		result.Auth = c.login(ctx, client)
+	}
+
+	b, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal vault result")
+	}
+
+	collectorName := c.Collector.CollectorName
+	if collectorName == "" {
+		collectorName = "vault"
+	}
+
+	output := NewResult()
+	output.SaveResult(c.BundlePath, path.Join("vault", collectorName+".json"), bytes.NewBuffer(b))
+
+	return output, nil
+}
+
+func (c *CollectVault) login(ctx context.Context, client *http.Client) *VaultAuthResult {
+	auth := c.Collector.KubernetesAuth
+	result := &VaultAuthResult{
+		Role:           auth.Role,
+		MountPath:      strings.Trim(auth.MountPath, "/"),
+		ServiceAccount: auth.ServiceAccount,
+	}
+	if result.MountPath == "" {
+		result.MountPath = "kubernetes"
+	}
+
+	jwt, err := c.serviceAccountToken(ctx)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	policies, err := vaultKubernetesLogin(ctx, client, c.Collector.Address, result.MountPath, auth.Role, jwt)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.IsAuthenticated = true
+	result.Policies = policies
+	return result
+}
+
+// serviceAccountToken returns a token of the service account of the collector, or of the pod the
+// collector runs in
+func (c *CollectVault) serviceAccountToken(ctx context.Context) (string, error) {
+	auth := c.Collector.KubernetesAuth
+	if auth.ServiceAccount == "" {
+		b, err := ioutil.ReadFile(serviceAccountTokenPath)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read the service account token of the pod")
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+
+	namespace := auth.Namespace
+	if namespace == "" {
+		namespace = c.Namespace
+	}
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	expirationSeconds := int64(vaultTokenExpirationSeconds)
+	tokenRequest, err := c.Client.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, auth.ServiceAccount, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expirationSeconds},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to request a token for service account %s/%s", namespace, auth.ServiceAccount)
+	}
+	return tokenRequest.Status.Token, nil
+}
+
+// vaultHealth gets the health of the server. The status code of the response is the state of the
+// server, such as 503 when it is sealed, and the body has the state for all of them.
+func vaultHealth(ctx context.Context, client *http.Client, address string) (*VaultHealth, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(address, "/")+"/v1/sys/health", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get health")
+	}
+	defer resp.Body.Close()
+
+	health := &VaultHealth{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxHTTPBodySize)).Decode(health); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode health, status code %d", resp.StatusCode)
+	}
+	return health, nil
+}
+
+// vaultKubernetesLogin logs in with the kubernetes auth method, and returns the policies of the token
+// that vault issued
+func vaultKubernetesLogin(ctx context.Context, client *http.Client, address string, mountPath string, role string, jwt string) ([]string, error) {
+	body, err := json.Marshal(map[string]string{"role": role, "jwt": jwt})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal login")
+	}
+
+	url := fmt.Sprintf("%s/v1/auth/%s/login", strings.TrimSuffix(address, "/"), mountPath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to log in")
+	}
+	defer resp.Body.Close()
+
+	login := struct {
+		Errors []string `json:"errors"`
+		Auth   *struct {
+			Policies []string `json:"policies"`
+		} `json:"auth"`
+	}{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxHTTPBodySize)).Decode(&login); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode login, status code %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK || login.Auth == nil {
+		return nil, errors.Errorf("failed to log in as role %s, status code %d: %s", role, resp.StatusCode, strings.Join(login.Errors, ", "))
+	}
+
+	return login.Auth.Policies, nil
+}
@@ -0,0 +1,141 @@
+package collect
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func vaultTestServer(t *testing.T, sealed bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/sys/health":
+			if sealed {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"initialized": true, "sealed": sealed, "version": "1.12.2"})
+		case "/v1/auth/k8s/login":
+			login := map[string]string{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&login))
+			if sealed {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(`{"errors":["Vault is sealed"]}`))
+				return
+			}
+			if login["role"] != "app" || login["jwt"] != "token-1" {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"errors":["permission denied"]}`))
+				return
+			}
+			w.Write([]byte(`{"auth":{"client_token":"s.secret","policies":["default","app"]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func vaultTestClient() *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		tokenRequest := &authenticationv1.TokenRequest{}
+		if action.GetNamespace() == "app" && action.GetSubresource() == "token" {
+			tokenRequest.Status.Token = "token-1"
+		}
+		return true, tokenRequest, nil
+	})
+	return client
+}
+
+func TestCollectVault(t *testing.T) {
+	server := vaultTestServer(t, false)
+	defer server.Close()
+
+	collector := &CollectVault{
+		Collector: &troubleshootv1beta2.Vault{
+			Address: server.URL,
+			KubernetesAuth: &troubleshootv1beta2.VaultKubernetesAuth{
+				Role:           "app",
+				MountPath:      "/k8s/",
+				ServiceAccount: "app",
+				Namespace:      "app",
+			},
+		},
+		Client: vaultTestClient(),
+	}
+	output, err := collector.Collect(nil)
+	require.NoError(t, err)
+
+	result := VaultResult{}
+	require.NoError(t, json.Unmarshal(output["vault/vault.json"], &result))
+	assert.Equal(t, VaultResult{
+		Address:     server.URL,
+		IsReachable: true,
+		Health:      &VaultHealth{Initialized: true, Version: "1.12.2"},
+		Auth: &VaultAuthResult{
+			Role:            "app",
+			MountPath:       "k8s",
+			ServiceAccount:  "app",
+			IsAuthenticated: true,
+			Policies:        []string{"default", "app"},
+		},
+	}, result)
+	assert.NotContains(t, string(output["vault/vault.json"]), "s.secret")
+}
+
+func TestCollectVault_Sealed(t *testing.T) {
+	server := vaultTestServer(t, true)
+	defer server.Close()
+
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, ioutil.WriteFile(tokenPath, []byte("token-1\n"), 0644))
+	defer func(path string) { serviceAccountTokenPath = path }(serviceAccountTokenPath)
+	serviceAccountTokenPath = tokenPath
+
+	collector := &CollectVault{
+		Collector: &troubleshootv1beta2.Vault{
+			CollectorMeta:  troubleshootv1beta2.CollectorMeta{CollectorName: "prod"},
+			Address:        server.URL,
+			KubernetesAuth: &troubleshootv1beta2.VaultKubernetesAuth{Role: "app", MountPath: "k8s"},
+		},
+	}
+	output, err := collector.Collect(nil)
+	require.NoError(t, err)
+
+	result := VaultResult{}
+	require.NoError(t, json.Unmarshal(output["vault/prod.json"], &result))
+	assert.True(t, result.IsReachable)
+	assert.True(t, result.Health.Sealed)
+	assert.False(t, result.Auth.IsAuthenticated)
+	assert.Equal(t, "failed to log in as role app, status code 503: Vault is sealed", result.Auth.Error)
+}
+
+func TestCollectVault_Unreachable(t *testing.T) {
+	server := vaultTestServer(t, false)
+	server.Close()
+
+	collector := &CollectVault{
+		Collector: &troubleshootv1beta2.Vault{
+			Address:        server.URL,
+			KubernetesAuth: &troubleshootv1beta2.VaultKubernetesAuth{Role: "app"},
+		},
+	}
+	output, err := collector.Collect(nil)
+	require.NoError(t, err)
+
+	result := VaultResult{}
+	require.NoError(t, json.Unmarshal(output["vault/vault.json"], &result))
+	assert.False(t, result.IsReachable)
+	assert.Contains(t, result.Error, "failed to get health")
+	assert.Nil(t, result.Auth)
+}
@@ -0,0 +1,73 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	analyze "github.com/replicatedhq/troubleshoot/pkg/analyze"
+)
+
+const (
+	// AnalysisAPIVersion is the version of the analysis results document. Fields may be added within a
+	// version, but existing fields will not be removed or change meaning without a new version.
+	AnalysisAPIVersion = "troubleshoot.sh/v1beta2"
+	AnalysisKind       = "AnalysisResults"
+)
+
+// AnalysisResults is the document written to analysis-v1beta2.json in support bundles and emitted by
+// preflight with --format=analysis. analysis.json keeps the bare list of results it has always had. It is described by schemas/analysisresults-troubleshoot-v1beta2.json.
+type AnalysisResults struct {
+	APIVersion string    `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string    `json:"kind" yaml:"kind"`
+	Results    []*Result `json:"results" yaml:"results"`
+}
+
+// NewAnalysisResults converts analyzer results into a versioned analysis results document
+func NewAnalysisResults(input []*analyze.AnalyzeResult) *AnalysisResults {
+	return &AnalysisResults{
+		APIVersion: AnalysisAPIVersion,
+		Kind:       AnalysisKind,
+		Results:    FromAnalyzerResult(input),
+	}
+}
+
+// MarshalAnalysisResults returns the indented json for a versioned analysis results document
+func MarshalAnalysisResults(input []*analyze.AnalyzeResult) ([]byte, error) {
+	b, err := json.MarshalIndent(NewAnalysisResults(input), "", "    ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal analysis results")
+	}
+	return b, nil
+}
+
+// ParseAnalysisResults parses an analysis results document. The bare list of results in analysis.json
+// is also accepted.
+func ParseAnalysisResults(b []byte) (*AnalysisResults, error) {
+	trimmed := bytes.TrimSpace(b)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		results := []*Result{}
+		if err := json.Unmarshal(trimmed, &results); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal analysis results")
+		}
+		return &AnalysisResults{
+			APIVersion: AnalysisAPIVersion,
+			Kind:       AnalysisKind,
+			Results:    results,
+		}, nil
+	}
+
+	analysisResults := &AnalysisResults{}
+	if err := json.Unmarshal(trimmed, analysisResults); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal analysis results")
+	}
+
+	if analysisResults.Kind != AnalysisKind {
+		return nil, errors.Errorf("unexpected kind %q", analysisResults.Kind)
+	}
+	if analysisResults.APIVersion != AnalysisAPIVersion {
+		return nil, errors.Errorf("unsupported apiVersion %q", analysisResults.APIVersion)
+	}
+
+	return analysisResults, nil
+}
@@ -0,0 +1,103 @@
+package convert
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	analyze "github.com/replicatedhq/troubleshoot/pkg/analyze"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalysisResults(t *testing.T) {
+	b, err := MarshalAnalysisResults([]*analyze.AnalyzeResult{
+		{
+			IsFail:   true,
+			Title:    "Kubernetes Version",
+			Message:  "Kubernetes 1.20 or later is required",
+			URI:      "https://kubernetes.io",
+			Category: "Cluster",
+		},
+		{
+			IsPass:  true,
+			Title:   "Node Count",
+			Message: "There are enough nodes",
+		},
+	})
+	require.NoError(t, err)
+
+	parsed, err := ParseAnalysisResults(b)
+	require.NoError(t, err)
+
+	assert.Equal(t, AnalysisAPIVersion, parsed.APIVersion)
+	assert.Equal(t, AnalysisKind, parsed.Kind)
+	require.Len(t, parsed.Results, 2)
+	assert.Equal(t, "kubernetes.version", parsed.Results[0].Name)
+	assert.Equal(t, SeverityError, parsed.Results[0].Severity)
+	assert.Equal(t, "Kubernetes 1.20 or later is required", parsed.Results[0].Insight.Detail)
+	assert.Equal(t, "https://kubernetes.io", parsed.Results[0].URI)
+	assert.Equal(t, "Cluster", parsed.Results[0].Category)
+	assert.Equal(t, SeverityDebug, parsed.Results[1].Severity)
+}
+
+func TestParseAnalysisResults(t *testing.T) {
+	legacy, err := json.Marshal(FromAnalyzerResult([]*analyze.AnalyzeResult{
+		{IsWarn: true, Title: "Disk Usage", Message: "Disk is almost full"},
+	}))
+	require.NoError(t, err)
+
+	parsed, err := ParseAnalysisResults(legacy)
+	require.NoError(t, err)
+	require.Len(t, parsed.Results, 1)
+	assert.Equal(t, SeverityWarn, parsed.Results[0].Severity)
+
+	_, err = ParseAnalysisResults([]byte(`{"apiVersion": "troubleshoot.sh/v2", "kind": "AnalysisResults", "results": []}`))
+	assert.Error(t, err)
+
+	_, err = ParseAnalysisResults([]byte(`{"apiVersion": "troubleshoot.sh/v1beta2", "kind": "SupportBundle"}`))
+	assert.Error(t, err)
+}
+
+// TestAnalysisResultsSchema makes sure every field that can be written to analysis.json is documented in the published schema
+func TestAnalysisResultsSchema(t *testing.T) {
+	b, err := ioutil.ReadFile("../../schemas/analysisresults-troubleshoot-v1beta2.json")
+	require.NoError(t, err)
+
+	schema := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(b, &schema))
+
+	properties := func(path ...string) map[string]interface{} {
+		current := schema
+		for _, p := range path {
+			current = current[p].(map[string]interface{})
+		}
+		return current["properties"].(map[string]interface{})
+	}
+
+	assertDocumented := func(typ reflect.Type, documented map[string]interface{}) {
+		for _, name := range jsonFieldNames(typ) {
+			assert.Contains(t, documented, name, "%s.%s is not in the schema", typ.Name(), name)
+		}
+	}
+
+	assertDocumented(reflect.TypeOf(AnalysisResults{}), properties())
+	assertDocumented(reflect.TypeOf(Result{}), properties("properties", "results", "items"))
+	assertDocumented(reflect.TypeOf(Insight{}), properties("properties", "results", "items", "properties", "insight"))
+}
+
+func jsonFieldNames(t reflect.Type) []string {
+	names := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" && field.Anonymous {
+			names = append(names, jsonFieldNames(field.Type)...)
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
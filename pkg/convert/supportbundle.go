@@ -41,6 +41,12 @@ type Result struct {
 	Variables      map[string]interface{}  `json:"variables,omitempty" yaml:"variables,omitempty" hcl:"variables,omitempty"`
 	Error          string                  `json:"error,omitempty" yaml:"error,omitempty" hcl:"error,omitempty"`
 	InvolvedObject *corev1.ObjectReference `json:"involvedObject,omitempty" yaml:"involvedObject,omitempty" hcl:"involvedObject,omitempty"`
+	DocString      string                  `json:"docString,omitempty" yaml:"docString,omitempty" hcl:"docString,omitempty"`
+	Category       string                  `json:"category,omitempty" yaml:"category,omitempty" hcl:"category,omitempty"`
+	Tags           []string                `json:"tags,omitempty" yaml:"tags,omitempty" hcl:"tags,omitempty"`
+	RemediationURI string                  `json:"remediationUri,omitempty" yaml:"remediationUri,omitempty" hcl:"remediationUri,omitempty"`
+	URI            string                  `json:"uri,omitempty" yaml:"uri,omitempty" hcl:"uri,omitempty"`
+	Strict         bool                    `json:"strict,omitempty" yaml:"strict,omitempty" hcl:"strict,omitempty"`
 }
 
 func (m *Insight) Render(data interface{}) (*Insight, error) {
@@ -97,6 +103,12 @@ func FromAnalyzerResult(input []*analyze.AnalyzeResult) []*Result {
 			AnalyzerSpec:   "",
 			Variables:      map[string]interface{}{},
 			InvolvedObject: i.InvolvedObject,
+			DocString:      i.DocString,
+			Category:       i.Category,
+			Tags:           i.Tags,
+			RemediationURI: i.RemediationURI,
+			URI:            i.URI,
+			Strict:         i.Strict,
 		}
 		if i.IsFail {
 			r.Severity = SeverityError
@@ -0,0 +1,384 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/troubleshoot/pkg/convert"
+	"github.com/replicatedhq/troubleshoot/pkg/supportbundle"
+)
+
+type Change string
+
+const (
+	ChangeAdded   Change = "added"
+	ChangeRemoved Change = "removed"
+	ChangeChanged Change = "changed"
+)
+
+// Result is the difference between two support bundles, from the first bundle to the second
+type Result struct {
+	AddedFiles   []string       `json:"addedFiles" yaml:"addedFiles"`
+	RemovedFiles []string       `json:"removedFiles" yaml:"removedFiles"`
+	ChangedFiles []string       `json:"changedFiles" yaml:"changedFiles"`
+	Resources    []ResourceDiff `json:"resources" yaml:"resources"`
+	Analysis     []AnalysisDiff `json:"analysis" yaml:"analysis"`
+}
+
+// ResourceDiff is an object in cluster-resources that was added, removed or changed
+type ResourceDiff struct {
+	Change    Change `json:"change" yaml:"change"`
+	File      string `json:"file" yaml:"file"`
+	Kind      string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Name      string `json:"name" yaml:"name"`
+	// Fields are the paths of the fields that changed, e.g. spec.replicas
+	Fields []string `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// AnalysisDiff is an analyzer result that was added, removed or has a different severity or message
+type AnalysisDiff struct {
+	Change Change         `json:"change" yaml:"change"`
+	Title  string         `json:"title" yaml:"title"`
+	Before *AnalysisState `json:"before,omitempty" yaml:"before,omitempty"`
+	After  *AnalysisState `json:"after,omitempty" yaml:"after,omitempty"`
+}
+
+type AnalysisState struct {
+	Severity convert.Severity `json:"severity" yaml:"severity"`
+	Message  string           `json:"message" yaml:"message"`
+}
+
+// ignoredMetadataFields differ between any two clusters, or every time an object is written, so they
+// are not compared
+var ignoredMetadataFields = []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "selfLink"}
+
+// ignoredFields are timestamps in the status of objects that are updated without anything else changing
+var ignoredFields = map[string]bool{
+	"lastHeartbeatTime": true,
+	"lastProbeTime":     true,
+	"lastUpdateTime":    true,
+}
+
+// Bundles compares the extracted support bundles in dirA and dirB
+func Bundles(dirA, dirB string) (*Result, error) {
+	filesA, err := listFiles(dirA)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list files of %s", dirA)
+	}
+	filesB, err := listFiles(dirB)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list files of %s", dirB)
+	}
+
+	result := &Result{
+		AddedFiles:   []string{},
+		RemovedFiles: []string{},
+		ChangedFiles: []string{},
+		Resources:    []ResourceDiff{},
+		Analysis:     []AnalysisDiff{},
+	}
+
+	for relPath := range filesA {
+		if !filesB[relPath] {
+			result.RemovedFiles = append(result.RemovedFiles, relPath)
+		}
+	}
+	for relPath := range filesB {
+		if !filesA[relPath] {
+			result.AddedFiles = append(result.AddedFiles, relPath)
+			continue
+		}
+
+		a, err := ioutil.ReadFile(filepath.Join(dirA, relPath))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", relPath)
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dirB, relPath))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", relPath)
+		}
+		if bytes.Equal(a, b) {
+			continue
+		}
+		result.ChangedFiles = append(result.ChangedFiles, relPath)
+
+		if isResourceList(relPath) {
+			result.Resources = append(result.Resources, diffResourceLists(relPath, a, b)...)
+		}
+	}
+	sort.Strings(result.AddedFiles)
+	sort.Strings(result.RemovedFiles)
+	sort.Strings(result.ChangedFiles)
+
+	// objects in files that only one of the bundles has are not listed, as the file already is
+	sort.Slice(result.Resources, func(i, j int) bool {
+		if result.Resources[i].File != result.Resources[j].File {
+			return result.Resources[i].File < result.Resources[j].File
+		}
+		return resourceKey(result.Resources[i].Kind, result.Resources[i].Namespace, result.Resources[i].Name) <
+			resourceKey(result.Resources[j].Kind, result.Resources[j].Namespace, result.Resources[j].Name)
+	})
+
+	if filesA[supportbundle.AnalysisFilename] && filesB[supportbundle.AnalysisFilename] {
+		analysis, err := diffAnalysis(filepath.Join(dirA, supportbundle.AnalysisFilename), filepath.Join(dirB, supportbundle.AnalysisFilename))
+		if err != nil {
+			return nil, err
+		}
+		result.Analysis = analysis
+	}
+
+	return result, nil
+}
+
+func listFiles(dir string) (map[string]bool, error) {
+	files := map[string]bool{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return errors.Wrap(err, "failed to get relative path")
+		}
+		files[filepath.ToSlash(relPath)] = true
+		return nil
+	})
+	return files, err
+}
+
+// isResourceList returns true for the files of cluster-resources with a list of objects. Events are
+// not included, as they are different in every bundle.
+func isResourceList(relPath string) bool {
+	return strings.HasPrefix(relPath, "cluster-resources/") &&
+		!strings.HasPrefix(relPath, "cluster-resources/events/") &&
+		filepath.Ext(relPath) == ".json" &&
+		!strings.HasSuffix(relPath, "-errors.json")
+}
+
+type resourceList struct {
+	Items []map[string]interface{} `json:"items"`
+}
+
+// diffResourceLists compares the objects of two lists by kind, namespace and name. Files that are not
+// lists have no objects to compare.
+func diffResourceLists(relPath string, a, b []byte) []ResourceDiff {
+	var listA, listB resourceList
+	if err := json.Unmarshal(a, &listA); err != nil {
+		return nil
+	}
+	if err := json.Unmarshal(b, &listB); err != nil {
+		return nil
+	}
+
+	objectsA := objectsByKey(listA.Items)
+	objectsB := objectsByKey(listB.Items)
+
+	diffs := []ResourceDiff{}
+	for key, objA := range objectsA {
+		if _, ok := objectsB[key]; !ok {
+			diffs = append(diffs, newResourceDiff(ChangeRemoved, relPath, objA))
+		}
+	}
+	for key, objB := range objectsB {
+		objA, ok := objectsA[key]
+		if !ok {
+			diffs = append(diffs, newResourceDiff(ChangeAdded, relPath, objB))
+			continue
+		}
+
+		fields := changedFields("", normalizeObject(objA), normalizeObject(objB))
+		if len(fields) == 0 {
+			continue
+		}
+		sort.Strings(fields)
+		diff := newResourceDiff(ChangeChanged, relPath, objB)
+		diff.Fields = fields
+		diffs = append(diffs, diff)
+	}
+
+	return diffs
+}
+
+func objectsByKey(items []map[string]interface{}) map[string]map[string]interface{} {
+	objects := map[string]map[string]interface{}{}
+	for _, item := range items {
+		kind, namespace, name := objectMeta(item)
+		objects[resourceKey(kind, namespace, name)] = item
+	}
+	return objects
+}
+
+func newResourceDiff(change Change, relPath string, obj map[string]interface{}) ResourceDiff {
+	kind, namespace, name := objectMeta(obj)
+	return ResourceDiff{
+		Change:    change,
+		File:      relPath,
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+	}
+}
+
+func objectMeta(obj map[string]interface{}) (kind string, namespace string, name string) {
+	kind, _ = obj["kind"].(string)
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		namespace, _ = metadata["namespace"].(string)
+		name, _ = metadata["name"].(string)
+	}
+	return kind, namespace, name
+}
+
+func resourceKey(kind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+// normalizeObject returns a copy of obj without the metadata that is different in every cluster
+func normalizeObject(obj map[string]interface{}) map[string]interface{} {
+	normalized := map[string]interface{}{}
+	for k, v := range obj {
+		normalized[k] = v
+	}
+
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		return normalized
+	}
+	normalizedMetadata := map[string]interface{}{}
+	for k, v := range metadata {
+		normalizedMetadata[k] = v
+	}
+	for _, field := range ignoredMetadataFields {
+		delete(normalizedMetadata, field)
+	}
+	normalized["metadata"] = normalizedMetadata
+
+	return normalized
+}
+
+// changedFields returns the paths of the fields that are different in a and b. Lists with a different
+// number of items are reported as a whole.
+func changedFields(path string, a, b interface{}) []string {
+	mapA, okA := a.(map[string]interface{})
+	mapB, okB := b.(map[string]interface{})
+	if okA && okB {
+		fields := []string{}
+		keys := map[string]bool{}
+		for k := range mapA {
+			keys[k] = true
+		}
+		for k := range mapB {
+			keys[k] = true
+		}
+		for k := range keys {
+			if ignoredFields[k] {
+				continue
+			}
+			fields = append(fields, changedFields(joinPath(path, k), mapA[k], mapB[k])...)
+		}
+		return fields
+	}
+
+	listA, okA := a.([]interface{})
+	listB, okB := b.([]interface{})
+	if okA && okB && len(listA) == len(listB) {
+		fields := []string{}
+		for i := range listA {
+			fields = append(fields, changedFields(fmt.Sprintf("%s[%d]", path, i), listA[i], listB[i])...)
+		}
+		return fields
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return nil
+	}
+	return []string{path}
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// diffAnalysis compares analyzer results by title. Results with the same title are compared in order.
+func diffAnalysis(pathA, pathB string) ([]AnalysisDiff, error) {
+	resultsA, err := readAnalysis(pathA)
+	if err != nil {
+		return nil, err
+	}
+	resultsB, err := readAnalysis(pathB)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := []AnalysisDiff{}
+	for _, key := range resultsA.keys {
+		if _, ok := resultsB.states[key]; !ok {
+			diffs = append(diffs, AnalysisDiff{Change: ChangeRemoved, Title: resultsA.titles[key], Before: resultsA.states[key]})
+		}
+	}
+	for _, key := range resultsB.keys {
+		before, ok := resultsA.states[key]
+		after := resultsB.states[key]
+		if !ok {
+			diffs = append(diffs, AnalysisDiff{Change: ChangeAdded, Title: resultsB.titles[key], After: after})
+		} else if *before != *after {
+			diffs = append(diffs, AnalysisDiff{Change: ChangeChanged, Title: resultsB.titles[key], Before: before, After: after})
+		}
+	}
+
+	return diffs, nil
+}
+
+type analysisStates struct {
+	keys   []string
+	titles map[string]string
+	states map[string]*AnalysisState
+}
+
+func readAnalysis(path string) (*analysisStates, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read analysis")
+	}
+	analysis, err := convert.ParseAnalysisResults(b)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", path)
+	}
+
+	states := &analysisStates{
+		keys:   []string{},
+		titles: map[string]string{},
+		states: map[string]*AnalysisState{},
+	}
+	count := map[string]int{}
+	for _, result := range analysis.Results {
+		if result == nil || result.Insight == nil {
+			continue
+		}
+
+		title := result.Insight.Primary
+		key := fmt.Sprintf("%s#%d", title, count[title])
+		count[title]++
+
+		states.keys = append(states.keys, key)
+		states.titles[key] = title
+		states.states[key] = &AnalysisState{Severity: result.Severity, Message: result.Insight.Detail}
+	}
+
+	return states, nil
+}
@@ -0,0 +1,186 @@
+package diff
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/replicatedhq/troubleshoot/pkg/convert"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+	}
+}
+
+func TestBundles(t *testing.T) {
+	dirA := t.TempDir()
+	writeFiles(t, dirA, map[string]string{
+		"version.yaml":                      "apiVersion: troubleshoot.sh/v1beta2\n",
+		"cluster-info/cluster_version.json": `{"string": "v1.25.3"}`,
+		"cluster-resources/deployments/default.json": `{"kind": "DeploymentList", "items": [
+  {"kind": "Deployment", "metadata": {"name": "web", "namespace": "default", "uid": "a", "resourceVersion": "1"}, "spec": {"replicas": 2, "template": {"spec": {"containers": [{"name": "web", "image": "web:1"}]}}}, "status": {"readyReplicas": 2}},
+  {"kind": "Deployment", "metadata": {"name": "worker", "namespace": "default", "uid": "b"}, "spec": {"replicas": 1}},
+  {"kind": "Deployment", "metadata": {"name": "api", "namespace": "default", "uid": "c"}, "spec": {"replicas": 1}}
+]}`,
+		"cluster-resources/events/default.json":     `{"items": [{"kind": "Event", "metadata": {"name": "a"}}]}`,
+		"cluster-resources/deployments-errors.json": `[]`,
+		"removed.txt": "removed",
+		"analysis.json": `{"apiVersion": "troubleshoot.sh/v1beta2", "kind": "AnalysisResults", "results": [
+  {"insight": {"primary": "Kubernetes Version", "detail": "v1.25 is supported"}, "severity": "debug"},
+  {"insight": {"primary": "Pod Status", "detail": "ok"}, "severity": "debug"},
+  {"insight": {"primary": "Node Count", "detail": "3 nodes"}, "severity": "debug"}
+]}`,
+	})
+
+	dirB := t.TempDir()
+	writeFiles(t, dirB, map[string]string{
+		"version.yaml":                      "apiVersion: troubleshoot.sh/v1beta2\n",
+		"cluster-info/cluster_version.json": `{"string": "v1.26.0"}`,
+		"cluster-resources/deployments/default.json": `{"kind": "DeploymentList", "items": [
+  {"kind": "Deployment", "metadata": {"name": "web", "namespace": "default", "uid": "d", "resourceVersion": "9"}, "spec": {"replicas": 2, "template": {"spec": {"containers": [{"name": "web", "image": "web:2"}]}}}, "status": {"readyReplicas": 0}},
+  {"kind": "Deployment", "metadata": {"name": "worker", "namespace": "default", "uid": "e"}, "spec": {"replicas": 1}},
+  {"kind": "Deployment", "metadata": {"name": "cache", "namespace": "default", "uid": "f"}, "spec": {"replicas": 1}}
+]}`,
+		"cluster-resources/events/default.json":     `{"items": [{"kind": "Event", "metadata": {"name": "b"}}]}`,
+		"cluster-resources/deployments-errors.json": `["forbidden"]`,
+		"added.txt": "added",
+		"analysis.json": `{"apiVersion": "troubleshoot.sh/v1beta2", "kind": "AnalysisResults", "results": [
+  {"insight": {"primary": "Kubernetes Version", "detail": "v1.26 is supported"}, "severity": "debug"},
+  {"insight": {"primary": "Pod Status", "detail": "web is crashing"}, "severity": "error"},
+  {"insight": {"primary": "Storage Class", "detail": "no default storage class"}, "severity": "warn"}
+]}`,
+	})
+
+	result, err := Bundles(dirA, dirB)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"added.txt"}, result.AddedFiles)
+	assert.Equal(t, []string{"removed.txt"}, result.RemovedFiles)
+	assert.Equal(t, []string{
+		"analysis.json",
+		"cluster-info/cluster_version.json",
+		"cluster-resources/deployments-errors.json",
+		"cluster-resources/deployments/default.json",
+		"cluster-resources/events/default.json",
+	}, result.ChangedFiles)
+
+	assert.Equal(t, []ResourceDiff{
+		{Change: ChangeRemoved, File: "cluster-resources/deployments/default.json", Kind: "Deployment", Namespace: "default", Name: "api"},
+		{Change: ChangeAdded, File: "cluster-resources/deployments/default.json", Kind: "Deployment", Namespace: "default", Name: "cache"},
+		{
+			Change:    ChangeChanged,
+			File:      "cluster-resources/deployments/default.json",
+			Kind:      "Deployment",
+			Namespace: "default",
+			Name:      "web",
+			Fields:    []string{"spec.template.spec.containers[0].image", "status.readyReplicas"},
+		},
+	}, result.Resources)
+
+	assert.Equal(t, []AnalysisDiff{
+		{
+			Change: ChangeRemoved,
+			Title:  "Node Count",
+			Before: &AnalysisState{Severity: convert.SeverityDebug, Message: "3 nodes"},
+		},
+		{
+			Change: ChangeChanged,
+			Title:  "Kubernetes Version",
+			Before: &AnalysisState{Severity: convert.SeverityDebug, Message: "v1.25 is supported"},
+			After:  &AnalysisState{Severity: convert.SeverityDebug, Message: "v1.26 is supported"},
+		},
+		{
+			Change: ChangeChanged,
+			Title:  "Pod Status",
+			Before: &AnalysisState{Severity: convert.SeverityDebug, Message: "ok"},
+			After:  &AnalysisState{Severity: convert.SeverityError, Message: "web is crashing"},
+		},
+		{
+			Change: ChangeAdded,
+			Title:  "Storage Class",
+			After:  &AnalysisState{Severity: convert.SeverityWarn, Message: "no default storage class"},
+		},
+	}, result.Analysis)
+
+	var out bytes.Buffer
+	require.NoError(t, result.WriteText(&out))
+	assert.Equal(t, `Files:
+  + added.txt
+  - removed.txt
+  ~ analysis.json
+  ~ cluster-info/cluster_version.json
+  ~ cluster-resources/deployments-errors.json
+  ~ cluster-resources/deployments/default.json
+  ~ cluster-resources/events/default.json
+Resources:
+  - Deployment default/api
+  + Deployment default/cache
+  ~ Deployment default/web: spec.template.spec.containers[0].image, status.readyReplicas
+Analysis:
+  - Node Count: debug: 3 nodes
+  ~ Kubernetes Version: debug -> debug: v1.26 is supported
+  ~ Pod Status: debug -> error: web is crashing
+  + Storage Class: warn: no default storage class
+`, out.String())
+}
+
+func TestBundlesSame(t *testing.T) {
+	files := map[string]string{
+		"version.yaml":                 "apiVersion: troubleshoot.sh/v1beta2\n",
+		"cluster-resources/nodes.json": `{"items": [{"kind": "Node", "metadata": {"name": "node-1"}}]}`,
+	}
+	dirA := t.TempDir()
+	writeFiles(t, dirA, files)
+	dirB := t.TempDir()
+	writeFiles(t, dirB, files)
+
+	result, err := Bundles(dirA, dirB)
+	require.NoError(t, err)
+	assert.Equal(t, &Result{
+		AddedFiles:   []string{},
+		RemovedFiles: []string{},
+		ChangedFiles: []string{},
+		Resources:    []ResourceDiff{},
+		Analysis:     []AnalysisDiff{},
+	}, result)
+
+	var out bytes.Buffer
+	require.NoError(t, result.WriteText(&out))
+	assert.Equal(t, "The bundles are the same\n", out.String())
+}
+
+func Test_changedFields(t *testing.T) {
+	a := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"ports": []interface{}{"80"},
+			"type":  "ClusterIP",
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True", "lastHeartbeatTime": "1"},
+			},
+		},
+	}
+	b := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"ports":    []interface{}{"80", "443"},
+			"type":     "ClusterIP",
+			"selector": map[string]interface{}{"app": "web"},
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True", "lastHeartbeatTime": "2"},
+			},
+		},
+	}
+
+	fields := changedFields("", a, b)
+	assert.ElementsMatch(t, []string{"spec.ports", "spec.selector"}, fields)
+}
@@ -0,0 +1,213 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	iutils "github.com/replicatedhq/troubleshoot/pkg/interfaceutils"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultProfileFile is the file that values of a profile are in if they don't say, which is written by the
+// clusterSummary collector
+const DefaultProfileFile = "summary.json"
+
+// Profile is a reference configuration that a vendor supports, as the values that json and yaml files of
+// a bundle are expected to have
+type Profile struct {
+	Files []ProfileFile `json:"files" yaml:"files"`
+}
+
+type ProfileFile struct {
+	// Path is the file in the bundle, summary.json if it is not set
+	Path   string         `json:"path,omitempty" yaml:"path,omitempty"`
+	Values []ProfileValue `json:"values" yaml:"values"`
+}
+
+// ProfileValue is the value at a path in a file, such as nodes.count or items.[0].spec.replicas, which is
+// compared with Equals, OneOf or Version. A value with none of them only has to be there.
+type ProfileValue struct {
+	Path   string        `json:"path" yaml:"path"`
+	Equals interface{}   `json:"equals,omitempty" yaml:"equals,omitempty"`
+	OneOf  []interface{} `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+	// Version is a semver range, such as >=1.24.0 <1.29.0. Pre-releases are compared as their release, as
+	// distributions add to the version, such as v1.27.4-eks-2d98532.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// ProfileResult is the drift of a bundle from a profile
+type ProfileResult struct {
+	Drift []Drift `json:"drift" yaml:"drift"`
+}
+
+// Drift is a value of a bundle that is not what the profile expects
+type Drift struct {
+	File     string      `json:"file" yaml:"file"`
+	Path     string      `json:"path" yaml:"path"`
+	Expected string      `json:"expected" yaml:"expected"`
+	Actual   interface{} `json:"actual,omitempty" yaml:"actual,omitempty"`
+	// Missing is true when the file, or the path in it, is not in the bundle
+	Missing bool `json:"missing,omitempty" yaml:"missing,omitempty"`
+}
+
+// LoadProfile parses a profile, which can be yaml or json
+func LoadProfile(b []byte) (*Profile, error) {
+	profile := &Profile{}
+	// yaml is converted to json, so that numbers are float64 the same as in the files of the bundle
+	if err := yaml.Unmarshal(b, profile); err != nil {
+		return nil, errors.Wrap(err, "failed to parse profile")
+	}
+
+	for i, file := range profile.Files {
+		for _, value := range file.Values {
+			if value.Path == "" {
+				return nil, errors.Errorf("a value of file %d of the profile has no path", i)
+			}
+			if value.Version != "" {
+				if _, err := semver.ParseRange(value.Version); err != nil {
+					return nil, errors.Wrapf(err, "failed to parse version %q of %s", value.Version, value.Path)
+				}
+			}
+		}
+	}
+
+	return profile, nil
+}
+
+// AssertProfile compares the extracted support bundle in dir with the profile
+func AssertProfile(dir string, profile *Profile) (*ProfileResult, error) {
+	result := &ProfileResult{Drift: []Drift{}}
+
+	for _, file := range profile.Files {
+		name := file.Path
+		if name == "" {
+			name = DefaultProfileFile
+		}
+
+		contents, err := readProfileFile(filepath.Join(dir, name))
+		if os.IsNotExist(errors.Cause(err)) {
+			for _, value := range file.Values {
+				result.Drift = append(result.Drift, Drift{File: name, Path: value.Path, Expected: value.expected(), Missing: true})
+			}
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", name)
+		}
+
+		for _, value := range file.Values {
+			actual, err := iutils.GetAtPath(contents, value.Path)
+			if err != nil || actual == nil {
+				result.Drift = append(result.Drift, Drift{File: name, Path: value.Path, Expected: value.expected(), Missing: true})
+				continue
+			}
+			if !value.matches(actual) {
+				result.Drift = append(result.Drift, Drift{File: name, Path: value.Path, Expected: value.expected(), Actual: actual})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// readProfileFile parses a json or yaml file of the bundle
+func readProfileFile(path string) (interface{}, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var contents interface{}
+	if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
+		err = yaml.Unmarshal(b, &contents)
+	} else {
+		err = json.Unmarshal(b, &contents)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse file")
+	}
+	return contents, nil
+}
+
+func (v ProfileValue) matches(actual interface{}) bool {
+	switch {
+	case v.Version != "":
+		s, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		version, err := semver.ParseTolerant(s)
+		if err != nil {
+			return false
+		}
+		version.Pre = nil
+		version.Build = nil
+		// the range was parsed when the profile was loaded
+		return semver.MustParseRange(v.Version)(version)
+	case v.OneOf != nil:
+		for _, expected := range v.OneOf {
+			if reflect.DeepEqual(actual, expected) {
+				return true
+			}
+		}
+		return false
+	case v.Equals != nil:
+		return reflect.DeepEqual(actual, v.Equals)
+	default:
+		return true
+	}
+}
+
+// expected describes what the value should be, for reports
+func (v ProfileValue) expected() string {
+	switch {
+	case v.Version != "":
+		return fmt.Sprintf("version %s", v.Version)
+	case v.OneOf != nil:
+		values := []string{}
+		for _, expected := range v.OneOf {
+			values = append(values, formatProfileValue(expected))
+		}
+		return fmt.Sprintf("one of %s", strings.Join(values, ", "))
+	case v.Equals != nil:
+		return formatProfileValue(v.Equals)
+	default:
+		return "to be set"
+	}
+}
+
+func formatProfileValue(value interface{}) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(b)
+}
+
+// WriteText writes a line for each value that drifted from the profile
+func (r *ProfileResult) WriteText(w io.Writer) error {
+	if len(r.Drift) == 0 {
+		_, err := fmt.Fprintln(w, "The bundle matches the profile")
+		return err
+	}
+
+	lines := []string{"Drift:"}
+	for _, drift := range r.Drift {
+		actual := "missing"
+		if !drift.Missing {
+			actual = formatProfileValue(drift.Actual)
+		}
+		lines = append(lines, fmt.Sprintf("  %s %s: expected %s, got %s", drift.File, drift.Path, drift.Expected, actual))
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
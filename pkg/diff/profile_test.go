@@ -0,0 +1,100 @@
+package diff
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testProfile = `
+files:
+  - values:
+      - path: kubernetesVersion
+        version: ">=1.24.0 <1.28.0"
+      - path: distribution
+        oneOf: [eks, gke]
+      - path: nodes.count
+        equals: 3
+      - path: cni
+        equals: [calico]
+      - path: defaultStorageClass
+  - path: cluster-resources/configmaps/app.json
+    values:
+      - path: items.[0].data.LOG_LEVEL
+        equals: info
+  - path: app/config.yaml
+    values:
+      - path: features.sso
+        equals: true
+`
+
+func TestAssertProfile(t *testing.T) {
+	profile, err := LoadProfile([]byte(testProfile))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"summary.json": `{
+  "kubernetesVersion": "v1.27.4-eks-2d98532",
+  "distribution": "aks",
+  "nodes": {"count": 3},
+  "cni": ["calico", "cilium"],
+  "defaultStorageClass": "gp3"
+}`,
+		"cluster-resources/configmaps/app.json": `{"items": [{"data": {"LOG_LEVEL": "debug"}}]}`,
+	})
+
+	result, err := AssertProfile(dir, profile)
+	require.NoError(t, err)
+	assert.Equal(t, []Drift{
+		{File: "summary.json", Path: "distribution", Expected: `one of "eks", "gke"`, Actual: "aks"},
+		{File: "summary.json", Path: "cni", Expected: `["calico"]`, Actual: []interface{}{"calico", "cilium"}},
+		{File: "cluster-resources/configmaps/app.json", Path: "items.[0].data.LOG_LEVEL", Expected: `"info"`, Actual: "debug"},
+		{File: "app/config.yaml", Path: "features.sso", Expected: "true", Missing: true},
+	}, result.Drift)
+
+	var b bytes.Buffer
+	require.NoError(t, result.WriteText(&b))
+	assert.Equal(t, `Drift:
+  summary.json distribution: expected one of "eks", "gke", got "aks"
+  summary.json cni: expected ["calico"], got ["calico","cilium"]
+  cluster-resources/configmaps/app.json items.[0].data.LOG_LEVEL: expected "info", got "debug"
+  app/config.yaml features.sso: expected true, got missing
+`, b.String())
+}
+
+func TestAssertProfile_Matches(t *testing.T) {
+	profile, err := LoadProfile([]byte(`
+files:
+  - values:
+      - path: kubernetesVersion
+        version: ">=1.24.0 <1.28.0"
+  - path: app/config.yaml
+    values:
+      - path: features.sso
+        equals: true
+`))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"summary.json":    `{"kubernetesVersion": "v1.24.0+k3s1"}`,
+		"app/config.yaml": "features:\n  sso: true\n",
+	})
+
+	result, err := AssertProfile(dir, profile)
+	require.NoError(t, err)
+	assert.Empty(t, result.Drift)
+}
+
+func TestLoadProfile_InvalidVersion(t *testing.T) {
+	_, err := LoadProfile([]byte(`
+files:
+  - values:
+      - path: kubernetesVersion
+        version: "1.24+"
+`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,68 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+var changeSymbols = map[Change]string{
+	ChangeAdded:   "+",
+	ChangeRemoved: "-",
+	ChangeChanged: "~",
+}
+
+// WriteText writes the result for reading in a terminal, with a + for what was added to the second
+// bundle, a - for what was removed from it and a ~ for what changed
+func (r *Result) WriteText(w io.Writer) error {
+	lines := []string{}
+
+	if len(r.AddedFiles)+len(r.RemovedFiles)+len(r.ChangedFiles) > 0 {
+		lines = append(lines, "Files:")
+		for _, f := range r.AddedFiles {
+			lines = append(lines, fmt.Sprintf("  %s %s", changeSymbols[ChangeAdded], f))
+		}
+		for _, f := range r.RemovedFiles {
+			lines = append(lines, fmt.Sprintf("  %s %s", changeSymbols[ChangeRemoved], f))
+		}
+		for _, f := range r.ChangedFiles {
+			lines = append(lines, fmt.Sprintf("  %s %s", changeSymbols[ChangeChanged], f))
+		}
+	}
+
+	if len(r.Resources) > 0 {
+		lines = append(lines, "Resources:")
+		for _, resource := range r.Resources {
+			name := resource.Name
+			if resource.Namespace != "" {
+				name = resource.Namespace + "/" + name
+			}
+			line := fmt.Sprintf("  %s %s %s", changeSymbols[resource.Change], resource.Kind, name)
+			if len(resource.Fields) > 0 {
+				line += ": " + strings.Join(resource.Fields, ", ")
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	if len(r.Analysis) > 0 {
+		lines = append(lines, "Analysis:")
+		for _, analysis := range r.Analysis {
+			switch analysis.Change {
+			case ChangeAdded:
+				lines = append(lines, fmt.Sprintf("  %s %s: %s: %s", changeSymbols[analysis.Change], analysis.Title, analysis.After.Severity, analysis.After.Message))
+			case ChangeRemoved:
+				lines = append(lines, fmt.Sprintf("  %s %s: %s: %s", changeSymbols[analysis.Change], analysis.Title, analysis.Before.Severity, analysis.Before.Message))
+			case ChangeChanged:
+				lines = append(lines, fmt.Sprintf("  %s %s: %s -> %s: %s", changeSymbols[analysis.Change], analysis.Title, analysis.Before.Severity, analysis.After.Severity, analysis.After.Message))
+			}
+		}
+	}
+
+	if len(lines) == 0 {
+		lines = append(lines, "The bundles are the same")
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
@@ -0,0 +1,414 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// groupResource is where a kind is written in a must-gather, e.g. apps/deployments
+type groupResource struct {
+	group    string
+	resource string
+}
+
+// namespacedResources maps the directories of cluster-resources that hold one list per namespace
+var namespacedResources = map[string]groupResource{
+	"pods":                   {"core", "pods"},
+	"services":               {"core", "services"},
+	"events":                 {"core", "events"},
+	"pvcs":                   {"core", "persistentvolumeclaims"},
+	"limitranges":            {"core", "limitranges"},
+	"resource-quotas":        {"core", "resourcequotas"},
+	"deployments":            {"apps", "deployments"},
+	"statefulsets":           {"apps", "statefulsets"},
+	"daemonsets":             {"apps", "daemonsets"},
+	"replicasets":            {"apps", "replicasets"},
+	"jobs":                   {"batch", "jobs"},
+	"cronjobs":               {"batch", "cronjobs"},
+	"ingress":                {"networking.k8s.io", "ingresses"},
+	"network-policy":         {"networking.k8s.io", "networkpolicies"},
+	"pod-disruption-budgets": {"policy", "poddisruptionbudgets"},
+	"roles":                  {"rbac.authorization.k8s.io", "roles"},
+	"rolebindings":           {"rbac.authorization.k8s.io", "rolebindings"},
+}
+
+// clusterResources maps the files of cluster-resources that hold a list of cluster scoped objects
+var clusterResources = map[string]groupResource{
+	"namespaces.json":                  {"core", "namespaces"},
+	"nodes.json":                       {"core", "nodes"},
+	"pvs.json":                         {"core", "persistentvolumes"},
+	"storage-classes.json":             {"storage.k8s.io", "storageclasses"},
+	"custom-resource-definitions.json": {"apiextensions.k8s.io", "customresourcedefinitions"},
+	"clusterroles.json":                {"rbac.authorization.k8s.io", "clusterroles"},
+	"clusterRoleBindings.json":         {"rbac.authorization.k8s.io", "clusterrolebindings"},
+}
+
+// unmappedDir holds the files that have no equivalent in a must-gather, so that nothing is lost
+const unmappedDir = "troubleshoot"
+
+type object struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Spec struct {
+		Containers []struct {
+			Name string `json:"name"`
+		} `json:"containers"`
+		InitContainers []struct {
+			Name string `json:"name"`
+		} `json:"initContainers"`
+	} `json:"spec"`
+}
+
+type list struct {
+	Items []json.RawMessage `json:"items"`
+}
+
+type pod struct {
+	namespace  string
+	containers []string
+}
+
+type mustGatherExporter struct {
+	bundleDir string
+	dstDir    string
+
+	// pods by name, used to find the namespace of pod logs collected by logs collectors
+	pods map[string][]pod
+}
+
+// MustGather writes the contents of an extracted support bundle to dstDir in the directory layout of
+// `oc adm must-gather`, so tools that read must-gather output can read support bundles. Resources are
+// written under namespaces/ and cluster-scoped-resources/, and pod logs under namespaces/<ns>/pods/.
+// Files with no must-gather equivalent are copied to troubleshoot/ with their original paths.
+func MustGather(bundleDir, dstDir string) error {
+	e := &mustGatherExporter{
+		bundleDir: bundleDir,
+		dstDir:    dstDir,
+		pods:      map[string][]pod{},
+	}
+
+	files := []string{}
+	err := filepath.Walk(bundleDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(bundleDir, path)
+		if err != nil {
+			return errors.Wrap(err, "failed to get relative path")
+		}
+		files = append(files, filepath.ToSlash(relPath))
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list bundle files")
+	}
+
+	// pods are exported first so that logs can be matched to them
+	for _, relPath := range files {
+		if dir, file := filepath.Split(relPath); dir == "cluster-resources/pods/" && filepath.Ext(file) == ".json" {
+			if err := e.exportPods(relPath); err != nil {
+				return errors.Wrapf(err, "failed to export %s", relPath)
+			}
+		}
+	}
+
+	for _, relPath := range files {
+		if err := e.exportFile(relPath); err != nil {
+			return errors.Wrapf(err, "failed to export %s", relPath)
+		}
+	}
+
+	return nil
+}
+
+func (e *mustGatherExporter) exportFile(relPath string) error {
+	parts := strings.Split(relPath, "/")
+
+	if parts[0] == "cluster-resources" {
+		switch {
+		case len(parts) == 2 && clusterResources[parts[1]] != (groupResource{}):
+			return e.exportClusterList(relPath, clusterResources[parts[1]])
+
+		case len(parts) == 3 && parts[1] == "pods" && strings.HasSuffix(parts[2], ".json"):
+			return nil // exported before everything else
+
+		case len(parts) == 3 && namespacedResources[parts[1]] != (groupResource{}) && strings.HasSuffix(parts[2], ".json"):
+			namespace := strings.TrimSuffix(parts[2], ".json")
+			return e.exportNamespacedList(relPath, namespace, namespacedResources[parts[1]])
+
+		case len(parts) == 6 && parts[1] == "pods" && parts[2] == "logs":
+			// cluster-resources/pods/logs/<namespace>/<pod>/<container>.log
+			if e.exportPodLog(relPath, parts[3], parts[4], parts[5]) {
+				return nil
+			}
+
+		case len(parts) == 3 && parts[1] == "custom-resources" && strings.HasSuffix(parts[2], ".yaml"):
+			// cluster-resources/custom-resources/<crd>.yaml
+			return e.exportClusterCustomResources(relPath, strings.TrimSuffix(parts[2], ".yaml"))
+
+		case len(parts) == 4 && parts[1] == "custom-resources" && strings.HasSuffix(parts[3], ".yaml"):
+			// cluster-resources/custom-resources/<crd>/<namespace>.yaml
+			return e.exportNamespacedCustomResources(relPath, parts[2], strings.TrimSuffix(parts[3], ".yaml"))
+		}
+	} else if len(parts) >= 2 && strings.HasSuffix(relPath, ".log") {
+		// logs collectors write <collector name>/<pod>.log or <collector name>/<pod>/<container>.log
+		if len(parts) == 2 && e.exportCollectedPodLog(relPath, strings.TrimSuffix(parts[1], ".log"), "") {
+			return nil
+		}
+		podName, fileName := parts[len(parts)-2], parts[len(parts)-1]
+		if e.exportCollectedPodLog(relPath, podName, fileName) {
+			return nil
+		}
+	}
+
+	return copyFile(e.bundleDir, relPath, e.dstDir, filepath.Join(unmappedDir, relPath))
+}
+
+func (e *mustGatherExporter) exportPods(relPath string) error {
+	namespace := strings.TrimSuffix(filepath.Base(relPath), ".json")
+
+	items, err := e.readList(relPath)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		var o object
+		if err := json.Unmarshal(item, &o); err != nil {
+			return errors.Wrap(err, "failed to unmarshal pod")
+		}
+
+		p := pod{namespace: namespace}
+		for _, c := range o.Spec.InitContainers {
+			p.containers = append(p.containers, c.Name)
+		}
+		for _, c := range o.Spec.Containers {
+			p.containers = append(p.containers, c.Name)
+		}
+		e.pods[o.Metadata.Name] = append(e.pods[o.Metadata.Name], p)
+
+		podDir := filepath.Join("namespaces", namespace, "pods", o.Metadata.Name)
+		if err := e.writeYaml(filepath.Join(podDir, o.Metadata.Name+".yaml"), item); err != nil {
+			return err
+		}
+	}
+
+	return e.exportNamespacedList(relPath, namespace, namespacedResources["pods"])
+}
+
+func (e *mustGatherExporter) exportNamespacedList(relPath string, namespace string, gr groupResource) error {
+	data, err := ioutil.ReadFile(filepath.Join(e.bundleDir, relPath))
+	if err != nil {
+		return errors.Wrap(err, "failed to read file")
+	}
+
+	return e.writeYaml(filepath.Join("namespaces", namespace, gr.group, gr.resource+".yaml"), data)
+}
+
+func (e *mustGatherExporter) exportClusterList(relPath string, gr groupResource) error {
+	items, err := e.readList(relPath)
+	if err != nil {
+		return err
+	}
+
+	return e.writeClusterObjects(items, gr, gr.resource == "namespaces")
+}
+
+func (e *mustGatherExporter) writeClusterObjects(items []json.RawMessage, gr groupResource, isNamespace bool) error {
+	for _, item := range items {
+		var o object
+		if err := json.Unmarshal(item, &o); err != nil {
+			return errors.Wrap(err, "failed to unmarshal object")
+		}
+		if o.Metadata.Name == "" {
+			continue
+		}
+
+		if err := e.writeYaml(filepath.Join("cluster-scoped-resources", gr.group, gr.resource, o.Metadata.Name+".yaml"), item); err != nil {
+			return err
+		}
+
+		// must-gather also keeps each namespace in its own directory
+		if isNamespace {
+			if err := e.writeYaml(filepath.Join("namespaces", o.Metadata.Name, o.Metadata.Name+".yaml"), item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (e *mustGatherExporter) exportClusterCustomResources(relPath string, crdName string) error {
+	items, err := e.readCustomResources(relPath)
+	if err != nil {
+		return err
+	}
+
+	return e.writeClusterObjects(items, customResourceGroupResource(crdName), false)
+}
+
+func (e *mustGatherExporter) exportNamespacedCustomResources(relPath string, crdName string, namespace string) error {
+	items, err := e.readCustomResources(relPath)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal list")
+	}
+
+	gr := customResourceGroupResource(crdName)
+	return e.writeYaml(filepath.Join("namespaces", namespace, gr.group, gr.resource+".yaml"), data)
+}
+
+func (e *mustGatherExporter) exportPodLog(relPath string, namespace string, podName string, fileName string) bool {
+	container, logName := logFileNames(fileName)
+	if container == "" {
+		return false
+	}
+
+	dst := filepath.Join("namespaces", namespace, "pods", podName, container, container, "logs", logName)
+	return copyFile(e.bundleDir, relPath, e.dstDir, dst) == nil
+}
+
+// exportCollectedPodLog exports logs from logs collectors, which don't include the namespace in the path.
+// The log is only exported if the pod name matches a single collected pod.
+func (e *mustGatherExporter) exportCollectedPodLog(relPath string, podName string, fileName string) bool {
+	pods := e.pods[podName]
+	if len(pods) != 1 {
+		return false
+	}
+	p := pods[0]
+
+	container, logName := "", ""
+	if fileName == "" {
+		// logs of pods with a single container are saved as <pod>.log
+		if len(p.containers) != 1 {
+			return false
+		}
+		container, logName = p.containers[0], "current.log"
+		if strings.HasSuffix(relPath, "-previous.log") {
+			return false
+		}
+	} else {
+		container, logName = logFileNames(fileName)
+		if !containsString(p.containers, container) {
+			return false
+		}
+	}
+
+	dst := filepath.Join("namespaces", p.namespace, "pods", podName, container, container, "logs", logName)
+	return copyFile(e.bundleDir, relPath, e.dstDir, dst) == nil
+}
+
+func (e *mustGatherExporter) readList(relPath string) ([]json.RawMessage, error) {
+	data, err := ioutil.ReadFile(filepath.Join(e.bundleDir, relPath))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read file")
+	}
+
+	var l list
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal list")
+	}
+
+	return l.Items, nil
+}
+
+// readCustomResources reads custom resources, which are saved as a yaml array rather than a list
+func (e *mustGatherExporter) readCustomResources(relPath string) ([]json.RawMessage, error) {
+	data, err := ioutil.ReadFile(filepath.Join(e.bundleDir, relPath))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read file")
+	}
+
+	items := []json.RawMessage{}
+	if err := yaml.Unmarshal(data, &items); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal custom resources")
+	}
+
+	return items, nil
+}
+
+// writeYaml converts a json document to yaml and writes it to a path in the must-gather
+func (e *mustGatherExporter) writeYaml(relPath string, data []byte) error {
+	y, err := yaml.JSONToYAML(data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to convert %s to yaml", relPath)
+	}
+
+	return writeFile(filepath.Join(e.dstDir, relPath), y)
+}
+
+func copyFile(srcDir string, srcPath string, dstDir string, dstPath string) error {
+	src, err := os.Open(filepath.Join(srcDir, srcPath))
+	if err != nil {
+		return errors.Wrap(err, "failed to open file")
+	}
+	defer src.Close()
+
+	filename := filepath.Join(dstDir, dstPath)
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return errors.Wrap(err, "failed to create dir")
+	}
+
+	dst, err := os.Create(filename)
+	if err != nil {
+		return errors.Wrap(err, "failed to create file")
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return errors.Wrapf(err, "failed to copy %s", srcPath)
+	}
+
+	return nil
+}
+
+// logFileNames returns the container and must-gather log file name for <container>.log or <container>-previous.log
+func logFileNames(fileName string) (string, string) {
+	if strings.HasSuffix(fileName, "-previous.log") {
+		return strings.TrimSuffix(fileName, "-previous.log"), "previous.log"
+	}
+	if strings.HasSuffix(fileName, ".log") {
+		return strings.TrimSuffix(fileName, ".log"), "current.log"
+	}
+	return "", ""
+}
+
+// customResourceGroupResource splits a crd name such as installers.cluster.kurl.sh into its group and resource
+func customResourceGroupResource(crdName string) groupResource {
+	parts := strings.SplitN(crdName, ".", 2)
+	if len(parts) != 2 {
+		return groupResource{group: "core", resource: crdName}
+	}
+	return groupResource{group: parts[1], resource: parts[0]}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
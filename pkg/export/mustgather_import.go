@@ -0,0 +1,347 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/version"
+	"sigs.k8s.io/yaml"
+)
+
+// mustGatherFilesDir holds the files of a must-gather that have no equivalent in a support bundle
+const mustGatherFilesDir = "must-gather"
+
+type mustGatherImporter struct {
+	mustGatherDir string
+	bundleDir     string
+
+	// cluster scoped objects by bundle file and name, merged from all the places must-gather writes them
+	clusterObjects map[string]map[string]json.RawMessage
+}
+
+// FindMustGatherRootDir returns the directory of a must-gather inside dir. must-gather archives usually
+// hold a directory per gather image, so the root may be up to two levels below dir.
+func FindMustGatherRootDir(dir string) (string, bool) {
+	if isMustGatherDir(dir) {
+		return dir, true
+	}
+
+	for _, pattern := range []string{"*", filepath.Join("*", "*")} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return "", false
+		}
+		for _, match := range matches {
+			if isMustGatherDir(match) {
+				return match, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func isMustGatherDir(dir string) bool {
+	for _, name := range []string{"namespaces", "cluster-scoped-resources"} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportMustGather writes the contents of a must-gather to bundleDir in the layout of a support bundle,
+// so that analyzers can run against it. Resources are converted to the lists in cluster-resources/ and
+// pod logs are written to cluster-resources/pods/logs/. Files with no support bundle equivalent are
+// copied to must-gather/ with their original paths, except those written by MustGather to troubleshoot/,
+// which are restored to where they came from.
+func ImportMustGather(mustGatherDir, bundleDir string) error {
+	i := &mustGatherImporter{
+		mustGatherDir:  mustGatherDir,
+		bundleDir:      bundleDir,
+		clusterObjects: map[string]map[string]json.RawMessage{},
+	}
+
+	err := filepath.Walk(mustGatherDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(mustGatherDir, path)
+		if err != nil {
+			return errors.Wrap(err, "failed to get relative path")
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if err := i.importFile(relPath); err != nil {
+			return errors.Wrapf(err, "failed to import %s", relPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to import must-gather files")
+	}
+
+	if err := i.writeClusterObjects(); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(filepath.Join(bundleDir, "version.yaml")); os.IsNotExist(err) {
+		if err := i.writeVersion(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i *mustGatherImporter) importFile(relPath string) error {
+	parts := strings.Split(relPath, "/")
+
+	switch {
+	case parts[0] == unmappedDir && len(parts) > 1:
+		return copyFile(i.mustGatherDir, relPath, i.bundleDir, strings.Join(parts[1:], "/"))
+
+	case parts[0] == "namespaces" && len(parts) == 3 && parts[2] == parts[1]+".yaml":
+		// namespaces/<namespace>/<namespace>.yaml
+		return i.addClusterObjects(relPath, "namespaces.json", false)
+
+	case parts[0] == "namespaces" && len(parts) == 4 && strings.HasSuffix(parts[3], ".yaml"):
+		// namespaces/<namespace>/<group>/<resource>.yaml
+		gr := groupResource{group: parts[2], resource: strings.TrimSuffix(parts[3], ".yaml")}
+		if dir := namespacedResourceDir(gr); dir != "" {
+			return i.importNamespacedList(relPath, filepath.Join("cluster-resources", dir, parts[1]+".json"))
+		}
+		if strings.Contains(gr.group, ".") {
+			return i.importNamespacedCustomResources(relPath, gr, parts[1])
+		}
+
+	case parts[0] == "namespaces" && len(parts) == 5 && parts[2] == "pods" && parts[4] == parts[3]+".yaml":
+		// namespaces/<namespace>/pods/<pod>/<pod>.yaml is also in namespaces/<namespace>/core/pods.yaml
+		return nil
+
+	case parts[0] == "namespaces" && len(parts) == 8 && parts[2] == "pods" && parts[6] == "logs":
+		// namespaces/<namespace>/pods/<pod>/<container>/<container>/logs/<current|previous>.log
+		fileName := ""
+		switch parts[7] {
+		case "current.log":
+			fileName = parts[4] + ".log"
+		case "previous.log":
+			fileName = parts[4] + "-previous.log"
+		}
+		if fileName != "" {
+			return copyFile(i.mustGatherDir, relPath, i.bundleDir, filepath.Join("cluster-resources", "pods", "logs", parts[1], parts[3], fileName))
+		}
+
+	case parts[0] == "cluster-scoped-resources" && len(parts) == 4 && strings.HasSuffix(parts[3], ".yaml"):
+		// cluster-scoped-resources/<group>/<resource>/<name>.yaml
+		gr := groupResource{group: parts[1], resource: parts[2]}
+		if fileName := clusterResourceFile(gr); fileName != "" {
+			return i.addClusterObjects(relPath, fileName, false)
+		}
+
+	case parts[0] == "cluster-scoped-resources" && len(parts) == 3 && strings.HasSuffix(parts[2], ".yaml"):
+		// cluster-scoped-resources/<group>/<resource>.yaml
+		gr := groupResource{group: parts[1], resource: strings.TrimSuffix(parts[2], ".yaml")}
+		if fileName := clusterResourceFile(gr); fileName != "" {
+			return i.addClusterObjects(relPath, fileName, true)
+		}
+	}
+
+	return copyFile(i.mustGatherDir, relPath, i.bundleDir, filepath.Join(mustGatherFilesDir, relPath))
+}
+
+func (i *mustGatherImporter) importNamespacedList(relPath string, dstPath string) error {
+	data, err := readYamlAsJson(filepath.Join(i.mustGatherDir, relPath))
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	if err := json.Indent(&b, data, "", "  "); err != nil {
+		return errors.Wrap(err, "failed to indent json")
+	}
+
+	return writeFile(filepath.Join(i.bundleDir, dstPath), b.Bytes())
+}
+
+// importNamespacedCustomResources writes custom resources as a yaml array, the way the cluster resources
+// collector does
+func (i *mustGatherImporter) importNamespacedCustomResources(relPath string, gr groupResource, namespace string) error {
+	data, err := readYamlAsJson(filepath.Join(i.mustGatherDir, relPath))
+	if err != nil {
+		return err
+	}
+
+	var l list
+	if err := json.Unmarshal(data, &l); err != nil {
+		return errors.Wrap(err, "failed to unmarshal list")
+	}
+
+	b, err := yaml.Marshal(l.Items)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal custom resources")
+	}
+
+	dstPath := filepath.Join("cluster-resources", "custom-resources", gr.resource+"."+gr.group, namespace+".yaml")
+	return writeFile(filepath.Join(i.bundleDir, dstPath), b)
+}
+
+// addClusterObjects reads a single object, or a list of objects if isList is set, to be written to fileName
+func (i *mustGatherImporter) addClusterObjects(relPath string, fileName string, isList bool) error {
+	data, err := readYamlAsJson(filepath.Join(i.mustGatherDir, relPath))
+	if err != nil {
+		return err
+	}
+
+	items := []json.RawMessage{data}
+	if isList {
+		var l list
+		if err := json.Unmarshal(data, &l); err != nil {
+			return errors.Wrap(err, "failed to unmarshal list")
+		}
+		items = l.Items
+	}
+
+	if i.clusterObjects[fileName] == nil {
+		i.clusterObjects[fileName] = map[string]json.RawMessage{}
+	}
+	for _, item := range items {
+		var o object
+		if err := json.Unmarshal(item, &o); err != nil {
+			return errors.Wrap(err, "failed to unmarshal object")
+		}
+		i.clusterObjects[fileName][o.Metadata.Name] = item
+	}
+
+	return nil
+}
+
+func (i *mustGatherImporter) writeClusterObjects() error {
+	for fileName, objects := range i.clusterObjects {
+		names := []string{}
+		for name := range objects {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		items := []json.RawMessage{}
+		for _, name := range names {
+			items = append(items, objects[name])
+		}
+
+		var b []byte
+		var err error
+		if strings.HasSuffix(fileName, ".yaml") {
+			b, err = yaml.Marshal(items)
+		} else {
+			b, err = json.MarshalIndent(clusterList(items), "", "  ")
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal %s", fileName)
+		}
+
+		if err := writeFile(filepath.Join(i.bundleDir, "cluster-resources", fileName), b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i *mustGatherImporter) writeVersion() error {
+	v := troubleshootv1beta2.SupportBundleVersion{
+		ApiVersion: "troubleshoot.sh/v1beta2",
+		Kind:       "SupportBundle",
+		Spec: troubleshootv1beta2.SupportBundleVersionSpec{
+			VersionNumber: version.Version(),
+		},
+	}
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal version data")
+	}
+
+	return writeFile(filepath.Join(i.bundleDir, "version.yaml"), b)
+}
+
+// clusterList wraps objects in a list of their kind, e.g. a NodeList, since analyzers expect lists
+func clusterList(items []json.RawMessage) map[string]interface{} {
+	l := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	}
+
+	if len(items) > 0 {
+		var typeMeta struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+		}
+		if err := json.Unmarshal(items[0], &typeMeta); err == nil && typeMeta.Kind != "" {
+			l["apiVersion"] = typeMeta.APIVersion
+			l["kind"] = typeMeta.Kind + "List"
+		}
+	}
+
+	return l
+}
+
+// namespacedResourceDir returns the cluster-resources directory for a namespaced kind
+func namespacedResourceDir(gr groupResource) string {
+	for dir, r := range namespacedResources {
+		if r == gr {
+			return dir
+		}
+	}
+	return ""
+}
+
+// clusterResourceFile returns the cluster-resources file for a cluster scoped kind
+func clusterResourceFile(gr groupResource) string {
+	for fileName, r := range clusterResources {
+		if r == gr {
+			return fileName
+		}
+	}
+	if strings.Contains(gr.group, ".") {
+		return filepath.Join("custom-resources", gr.resource+"."+gr.group+".yaml")
+	}
+	return ""
+}
+
+func readYamlAsJson(filename string) ([]byte, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read file")
+	}
+
+	j, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert yaml to json")
+	}
+
+	return j, nil
+}
+
+func writeFile(filename string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return errors.Wrap(err, "failed to create dir")
+	}
+
+	if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write %s", filename)
+	}
+
+	return nil
+}
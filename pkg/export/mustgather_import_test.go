@@ -0,0 +1,169 @@
+package export
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, contents := range files {
+		filename := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755))
+		require.NoError(t, ioutil.WriteFile(filename, []byte(contents), 0644))
+	}
+}
+
+func TestFindMustGatherRootDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"must-gather.local.123/timestamp": "",
+		"must-gather.local.123/quay-io-openshift-must-gather/cluster-scoped-resources/core/nodes/node-1.yaml": "",
+	})
+
+	rootDir, ok := FindMustGatherRootDir(dir)
+	require.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "must-gather.local.123", "quay-io-openshift-must-gather"), rootDir)
+
+	_, ok = FindMustGatherRootDir(t.TempDir())
+	assert.False(t, ok)
+}
+
+func TestImportMustGather(t *testing.T) {
+	mustGatherDir := t.TempDir()
+	writeFiles(t, mustGatherDir, map[string]string{
+		"timestamp": "2022-10-01 10:00:00\n",
+		"namespaces/default/default.yaml": `apiVersion: v1
+kind: Namespace
+metadata:
+  name: default
+`,
+		"namespaces/default/core/pods.yaml": `apiVersion: v1
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: web
+    namespace: default
+kind: PodList
+`,
+		"namespaces/default/pods/web/web.yaml":                               "apiVersion: v1\nkind: Pod\n",
+		"namespaces/default/pods/web/nginx/nginx/logs/current.log":           "current\n",
+		"namespaces/default/pods/web/nginx/nginx/logs/previous.log":          "previous\n",
+		"namespaces/default/pods/web/nginx/nginx/logs/previous.insecure.log": "insecure\n",
+		"namespaces/default/route.openshift.io/routes.yaml": `apiVersion: v1
+items:
+- apiVersion: route.openshift.io/v1
+  kind: Route
+  metadata:
+    name: web
+    namespace: default
+kind: RouteList
+`,
+		"cluster-scoped-resources/core/nodes/node-2.yaml": "apiVersion: v1\nkind: Node\nmetadata:\n  name: node-2\n",
+		"cluster-scoped-resources/core/nodes/node-1.yaml": "apiVersion: v1\nkind: Node\nmetadata:\n  name: node-1\n",
+		"cluster-scoped-resources/core/namespaces/default.yaml": `apiVersion: v1
+kind: Namespace
+metadata:
+  name: default
+`,
+		"cluster-scoped-resources/config.openshift.io/clusterversions.yaml": `apiVersion: config.openshift.io/v1
+items:
+- apiVersion: config.openshift.io/v1
+  kind: ClusterVersion
+  metadata:
+    name: version
+kind: ClusterVersionList
+`,
+		"host_service_logs/masters/kubelet_service.log": "kubelet\n",
+	})
+
+	bundleDir := t.TempDir()
+	require.NoError(t, ImportMustGather(mustGatherDir, bundleDir))
+
+	nodesData, err := ioutil.ReadFile(filepath.Join(bundleDir, "cluster-resources/nodes.json"))
+	require.NoError(t, err)
+	var nodes corev1.NodeList
+	require.NoError(t, json.Unmarshal(nodesData, &nodes))
+	assert.Equal(t, "NodeList", nodes.Kind)
+	require.Len(t, nodes.Items, 2)
+	assert.Equal(t, "node-1", nodes.Items[0].Name)
+	assert.Equal(t, "node-2", nodes.Items[1].Name)
+
+	namespacesData, err := ioutil.ReadFile(filepath.Join(bundleDir, "cluster-resources/namespaces.json"))
+	require.NoError(t, err)
+	var namespaces corev1.NamespaceList
+	require.NoError(t, json.Unmarshal(namespacesData, &namespaces))
+	assert.Len(t, namespaces.Items, 1)
+
+	podsData, err := ioutil.ReadFile(filepath.Join(bundleDir, "cluster-resources/pods/default.json"))
+	require.NoError(t, err)
+	var pods corev1.PodList
+	require.NoError(t, json.Unmarshal(podsData, &pods))
+	require.Len(t, pods.Items, 1)
+	assert.Equal(t, "web", pods.Items[0].Name)
+
+	wantFiles := map[string]string{
+		"cluster-resources/pods/logs/default/web/nginx.log":          "current\n",
+		"cluster-resources/pods/logs/default/web/nginx-previous.log": "previous\n",
+		"cluster-resources/custom-resources/routes.route.openshift.io/default.yaml": `- apiVersion: route.openshift.io/v1
+  kind: Route
+  metadata:
+    name: web
+    namespace: default
+`,
+		"cluster-resources/custom-resources/clusterversions.config.openshift.io.yaml": `- apiVersion: config.openshift.io/v1
+  kind: ClusterVersion
+  metadata:
+    name: version
+`,
+		"must-gather/timestamp": "2022-10-01 10:00:00\n",
+		"must-gather/host_service_logs/masters/kubelet_service.log":                      "kubelet\n",
+		"must-gather/namespaces/default/pods/web/nginx/nginx/logs/previous.insecure.log": "insecure\n",
+	}
+	for name, want := range wantFiles {
+		got, err := ioutil.ReadFile(filepath.Join(bundleDir, name))
+		require.NoError(t, err, name)
+		assert.Equal(t, want, string(got), name)
+	}
+
+	_, err = os.Stat(filepath.Join(bundleDir, "version.yaml"))
+	assert.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join(bundleDir, "must-gather/namespaces/default/pods/web/web.yaml"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestMustGather_RoundTrip(t *testing.T) {
+	bundleDir := t.TempDir()
+	files := map[string]string{
+		"version.yaml": "apiVersion: troubleshoot.sh/v1beta2\nkind: SupportBundle\n",
+		"cluster-resources/deployments/default.json":        `{"kind": "DeploymentList", "apiVersion": "apps/v1", "items": []}`,
+		"cluster-resources/pods/logs/default/web/nginx.log": "log\n",
+		"cluster-resources/image-pull-secrets/default.json": "{}",
+		"analysis.json": "[]",
+	}
+	writeFiles(t, bundleDir, files)
+
+	mustGatherDir := t.TempDir()
+	require.NoError(t, MustGather(bundleDir, mustGatherDir))
+
+	importedDir := t.TempDir()
+	require.NoError(t, ImportMustGather(mustGatherDir, importedDir))
+
+	for name, want := range files {
+		got, err := ioutil.ReadFile(filepath.Join(importedDir, name))
+		require.NoError(t, err, name)
+		if filepath.Ext(name) == ".json" {
+			assert.JSONEq(t, want, string(got), name)
+		} else {
+			assert.Equal(t, want, string(got), name)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package export
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMustGather(t *testing.T) {
+	bundleDir := t.TempDir()
+	files := map[string]string{
+		"version.yaml": "apiVersion: troubleshoot.sh/v1beta2\n",
+		"cluster-resources/pods/default.json": `{
+  "kind": "PodList",
+  "apiVersion": "v1",
+  "items": [
+    {"kind": "Pod", "apiVersion": "v1", "metadata": {"name": "web", "namespace": "default"}, "spec": {"containers": [{"name": "nginx"}]}},
+    {"kind": "Pod", "apiVersion": "v1", "metadata": {"name": "worker", "namespace": "default"}, "spec": {"initContainers": [{"name": "init"}], "containers": [{"name": "app"}, {"name": "sidecar"}]}}
+  ]
+}`,
+		"cluster-resources/deployments/default.json":                  `{"kind": "DeploymentList", "apiVersion": "apps/v1", "items": []}`,
+		"cluster-resources/namespaces.json":                           `{"kind": "NamespaceList", "apiVersion": "v1", "items": [{"kind": "Namespace", "apiVersion": "v1", "metadata": {"name": "default"}}]}`,
+		"cluster-resources/nodes.json":                                `{"kind": "NodeList", "apiVersion": "v1", "items": [{"kind": "Node", "apiVersion": "v1", "metadata": {"name": "node-1"}}]}`,
+		"cluster-resources/pods/logs/default/worker/app.log":          "app log\n",
+		"cluster-resources/pods/logs/default/worker/app-previous.log": "previous app log\n",
+		"cluster-resources/custom-resources/installers.cluster.kurl.sh.yaml": `- apiVersion: cluster.kurl.sh/v1beta1
+  kind: Installer
+  metadata:
+    name: latest
+`,
+		"cluster-resources/custom-resources/backups.velero.io/velero.yaml": `- apiVersion: velero.io/v1
+  kind: Backup
+  metadata:
+    name: daily
+    namespace: velero
+`,
+		"cluster-resources/image-pull-secrets/default.json": "{}",
+		"web-logs/web.log":             "web log\n",
+		"worker-logs/worker/init.log":  "init log\n",
+		"unknown-logs/missing/app.log": "missing log\n",
+	}
+	writeFiles(t, bundleDir, files)
+
+	dstDir := t.TempDir()
+	require.NoError(t, MustGather(bundleDir, dstDir))
+
+	wantFiles := map[string]string{
+		"namespaces/default/core/pods.yaml": "",
+		"namespaces/default/pods/web/web.yaml": `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  namespace: default
+spec:
+  containers:
+  - name: nginx
+`,
+		"namespaces/default/pods/worker/worker.yaml":                      "",
+		"namespaces/default/apps/deployments.yaml":                        "apiVersion: apps/v1\nitems: []\nkind: DeploymentList\n",
+		"namespaces/default/default.yaml":                                 "",
+		"cluster-scoped-resources/core/namespaces/default.yaml":           "",
+		"cluster-scoped-resources/core/nodes/node-1.yaml":                 "apiVersion: v1\nkind: Node\nmetadata:\n  name: node-1\n",
+		"namespaces/default/pods/worker/app/app/logs/current.log":         "app log\n",
+		"namespaces/default/pods/worker/app/app/logs/previous.log":        "previous app log\n",
+		"namespaces/default/pods/web/nginx/nginx/logs/current.log":        "web log\n",
+		"namespaces/default/pods/worker/init/init/logs/current.log":       "init log\n",
+		"cluster-scoped-resources/cluster.kurl.sh/installers/latest.yaml": "apiVersion: cluster.kurl.sh/v1beta1\nkind: Installer\nmetadata:\n  name: latest\n",
+		"namespaces/velero/velero.io/backups.yaml":                        "",
+		"troubleshoot/version.yaml":                                       "apiVersion: troubleshoot.sh/v1beta2\n",
+		"troubleshoot/cluster-resources/image-pull-secrets/default.json":  "{}",
+		"troubleshoot/unknown-logs/missing/app.log":                       "missing log\n",
+	}
+	for name, want := range wantFiles {
+		got, err := ioutil.ReadFile(filepath.Join(dstDir, name))
+		require.NoError(t, err, name)
+		if want != "" {
+			assert.Equal(t, want, string(got), name)
+		}
+	}
+
+	backups, err := ioutil.ReadFile(filepath.Join(dstDir, "namespaces/velero/velero.io/backups.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(backups), "kind: List\n")
+	assert.Contains(t, string(backups), "name: daily\n")
+
+	// files that were mapped to the must-gather layout are not copied again
+	for _, name := range []string{
+		"troubleshoot/cluster-resources/pods/default.json",
+		"troubleshoot/cluster-resources/pods/logs/default/worker/app.log",
+		"troubleshoot/web-logs/web.log",
+	} {
+		_, err := os.Stat(filepath.Join(dstDir, name))
+		assert.True(t, os.IsNotExist(err), name)
+	}
+}
+
+func Test_customResourceGroupResource(t *testing.T) {
+	assert.Equal(t, groupResource{group: "cluster.kurl.sh", resource: "installers"}, customResourceGroupResource("installers.cluster.kurl.sh"))
+	assert.Equal(t, groupResource{group: "core", resource: "widgets"}, customResourceGroupResource("widgets"))
+}
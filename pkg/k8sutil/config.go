@@ -26,3 +26,13 @@ func GetKubeconfig() clientcmd.ClientConfig {
 func GetRESTConfig() (*rest.Config, error) {
 	return kubernetesConfigFlags.ToRESTConfig()
 }
+
+// ProtobufConfig returns a copy of config that requests protobuf from the API server, falling back to JSON.
+// Protobuf is cheaper to encode and decode for large lists of built-in types. It is not supported for
+// custom resources, so don't use it with dynamic clients.
+func ProtobufConfig(config *rest.Config) *rest.Config {
+	protobufConfig := rest.CopyConfig(config)
+	protobufConfig.AcceptContentTypes = "application/vnd.kubernetes.protobuf,application/json"
+	protobufConfig.ContentType = "application/vnd.kubernetes.protobuf"
+	return protobufConfig
+}
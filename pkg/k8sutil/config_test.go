@@ -0,0 +1,60 @@
+package k8sutil
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	flag "github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRESTConfig_Flags(t *testing.T) {
+	req := require.New(t)
+
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	req.NoError(ioutil.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com
+- name: prod
+  cluster:
+    server: https://prod.example.com
+users:
+- name: admin
+  user:
+    token: abc123
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: admin
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+current-context: dev
+`), 0600))
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	AddFlags(flags)
+	req.NoError(flags.Parse([]string{
+		"--kubeconfig", kubeconfig,
+		"--context", "prod",
+		"--as", "system:serviceaccount:default:support-bundle",
+		"--as-group", "system:authenticated",
+		"--as-group", "support",
+	}))
+
+	config, err := GetRESTConfig()
+	req.NoError(err)
+	assert.Equal(t, "https://prod.example.com", config.Host)
+	assert.Equal(t, "system:serviceaccount:default:support-bundle", config.Impersonate.UserName)
+	assert.Equal(t, []string{"system:authenticated", "support"}, config.Impersonate.Groups)
+
+	// collectors that need protobuf keep the impersonated identity
+	assert.Equal(t, config.Impersonate, ProtobufConfig(config).Impersonate)
+}
@@ -0,0 +1,100 @@
+package k8sutil
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/pkg/errors"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ListPageSize is the number of objects requested from the API server in each list call
+var ListPageSize int64 = 500
+
+// ListFunc lists one page of objects
+type ListFunc func(opts metav1.ListOptions) (runtime.Object, error)
+
+// ListAll lists every object using limit/continue pagination and stores them in list, which must be a
+// pointer to the list type returned by listFunc, e.g. &corev1.PodList{}. Paging keeps the API server from
+// having to build the whole list in a single response on clusters with many objects. If the continue token
+// expires before all pages are read, the list is retried without pagination.
+func ListAll(ctx context.Context, list runtime.Object, listFunc ListFunc) error {
+	opts := metav1.ListOptions{Limit: ListPageSize}
+
+	var items []runtime.Object
+	for page := 0; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		obj, err := listFunc(opts)
+		if err != nil {
+			if page > 0 && kuberneteserrors.IsResourceExpired(err) {
+				return listAllWithoutPaging(list, listFunc)
+			}
+			return err
+		}
+
+		pageItems, err := meta.ExtractList(obj)
+		if err != nil {
+			return errors.Wrap(err, "failed to extract list items")
+		}
+
+		listMeta, err := meta.ListAccessor(obj)
+		if err != nil {
+			return errors.Wrap(err, "failed to get list metadata")
+		}
+
+		if page == 0 {
+			if err := setList(list, obj); err != nil {
+				return err
+			}
+			if listMeta.GetContinue() == "" {
+				return nil
+			}
+		}
+		items = append(items, pageItems...)
+
+		if listMeta.GetContinue() == "" {
+			break
+		}
+		opts.Continue = listMeta.GetContinue()
+	}
+
+	if err := meta.SetList(list, items); err != nil {
+		return errors.Wrap(err, "failed to set list items")
+	}
+
+	listMeta, err := meta.ListAccessor(list)
+	if err != nil {
+		return errors.Wrap(err, "failed to get list metadata")
+	}
+	listMeta.SetContinue("")
+	listMeta.SetRemainingItemCount(nil)
+
+	return nil
+}
+
+func listAllWithoutPaging(list runtime.Object, listFunc ListFunc) error {
+	obj, err := listFunc(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	return setList(list, obj)
+}
+
+// setList copies obj into list, which must be a pointer to the same type
+func setList(list runtime.Object, obj runtime.Object) error {
+	dst := reflect.ValueOf(list)
+	src := reflect.ValueOf(obj)
+	if dst.Type() != src.Type() || dst.Kind() != reflect.Ptr {
+		return errors.Errorf("cannot store %T in %T", obj, list)
+	}
+
+	dst.Elem().Set(src.Elem())
+	return nil
+}
@@ -0,0 +1,119 @@
+package k8sutil
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+// pagedPods returns a ListFunc that serves total pods in pages of opts.Limit
+func pagedPods(total int, calls *[]metav1.ListOptions) ListFunc {
+	return func(opts metav1.ListOptions) (runtime.Object, error) {
+		*calls = append(*calls, opts)
+
+		start := 0
+		if opts.Continue != "" {
+			start, _ = strconv.Atoi(opts.Continue)
+		}
+		end := total
+		if opts.Limit > 0 && start+int(opts.Limit) < total {
+			end = start + int(opts.Limit)
+		}
+
+		list := &corev1.PodList{ListMeta: metav1.ListMeta{ResourceVersion: "1"}}
+		for i := start; i < end; i++ {
+			list.Items = append(list.Items, corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i)}})
+		}
+		if end < total {
+			list.Continue = strconv.Itoa(end)
+		}
+		return list, nil
+	}
+}
+
+func TestListAll(t *testing.T) {
+	defer func(size int64) { ListPageSize = size }(ListPageSize)
+	ListPageSize = 2
+
+	tests := []struct {
+		name      string
+		total     int
+		wantCalls int
+	}{
+		{name: "empty", total: 0, wantCalls: 1},
+		{name: "single page", total: 2, wantCalls: 1},
+		{name: "multiple pages", total: 5, wantCalls: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := []metav1.ListOptions{}
+			pods := &corev1.PodList{}
+			err := ListAll(context.Background(), pods, pagedPods(tt.total, &calls))
+			require.NoError(t, err)
+
+			require.Len(t, pods.Items, tt.total)
+			for i, pod := range pods.Items {
+				assert.Equal(t, fmt.Sprintf("pod-%d", i), pod.Name)
+			}
+			assert.Equal(t, "1", pods.ResourceVersion)
+			assert.Empty(t, pods.Continue)
+
+			assert.Len(t, calls, tt.wantCalls)
+			for _, opts := range calls {
+				assert.Equal(t, int64(2), opts.Limit)
+			}
+		})
+	}
+}
+
+func TestListAll_ExpiredContinue(t *testing.T) {
+	defer func(size int64) { ListPageSize = size }(ListPageSize)
+	ListPageSize = 2
+
+	calls := []metav1.ListOptions{}
+	paged := pagedPods(5, &calls)
+	listFunc := func(opts metav1.ListOptions) (runtime.Object, error) {
+		if opts.Continue != "" {
+			return nil, kuberneteserrors.NewResourceExpired("continue token expired")
+		}
+		return paged(opts)
+	}
+
+	pods := &corev1.PodList{}
+	err := ListAll(context.Background(), pods, listFunc)
+	require.NoError(t, err)
+	assert.Len(t, pods.Items, 5)
+	assert.Equal(t, metav1.ListOptions{}, calls[len(calls)-1])
+}
+
+func TestListAll_WrongListType(t *testing.T) {
+	calls := []metav1.ListOptions{}
+	err := ListAll(context.Background(), &corev1.NodeList{}, pagedPods(1, &calls))
+	assert.Error(t, err)
+}
+
+func TestListAll_Error(t *testing.T) {
+	err := ListAll(context.Background(), &corev1.PodList{}, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return nil, kuberneteserrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", nil)
+	})
+	assert.True(t, kuberneteserrors.IsForbidden(err))
+}
+
+func TestProtobufConfig(t *testing.T) {
+	config := &rest.Config{Host: "https://example.com"}
+	protobufConfig := ProtobufConfig(config)
+
+	assert.Equal(t, "application/vnd.kubernetes.protobuf", protobufConfig.ContentType)
+	assert.Equal(t, "https://example.com", protobufConfig.Host)
+	assert.Empty(t, config.ContentType)
+}
@@ -8,17 +8,18 @@ import (
 
 	analyze "github.com/replicatedhq/troubleshoot/pkg/analyze"
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
 	"github.com/replicatedhq/troubleshoot/pkg/logger"
 )
 
 // Analyze runs the analyze phase of preflight checks
 func (c ClusterCollectResult) Analyze() []*analyze.AnalyzeResult {
-	return doAnalyze(c.AllCollectedData, c.Spec.Spec.Analyzers, nil, "")
+	return doAnalyze(c.AllCollectedData, c.SpillDir, c.Spec.Spec.Analyzers, nil, "")
 }
 
 // Analyze runs the analyze phase of host preflight checks
 func (c HostCollectResult) Analyze() []*analyze.AnalyzeResult {
-	return doAnalyze(c.AllCollectedData, nil, c.Spec.Spec.Analyzers, "")
+	return doAnalyze(c.AllCollectedData, c.SpillDir, nil, c.Spec.Spec.Analyzers, "")
 }
 
 // Analyze runs the analyze phase of host preflight checks.
@@ -43,40 +44,55 @@ func (c RemoteCollectResult) Analyze() []*analyze.AnalyzeResult {
 			byteResult[k] = []byte(v)
 
 		}
-		results = append(results, doAnalyze(byteResult, nil, c.Spec.Spec.Analyzers, nodeName)...)
+		results = append(results, doAnalyze(byteResult, "", nil, c.Spec.Spec.Analyzers, nodeName)...)
 	}
 	return results
 }
 
-func doAnalyze(allCollectedData map[string][]byte, analyzers []*troubleshootv1beta2.Analyze, hostAnalyzers []*troubleshootv1beta2.HostAnalyze, nodeName string) []*analyze.AnalyzeResult {
+func doAnalyze(allCollectedData map[string][]byte, spillDir string, analyzers []*troubleshootv1beta2.Analyze, hostAnalyzers []*troubleshootv1beta2.HostAnalyze, nodeName string) []*analyze.AnalyzeResult {
+	// large results are spilled to disk rather than held in memory, and are only read when analyzed
+	collected := collect.CollectorResult(allCollectedData)
 	getCollectedFileContents := func(fileName string) ([]byte, error) {
-		contents, ok := allCollectedData[fileName]
-		if !ok {
+		if _, ok := collected[fileName]; !ok {
 			return nil, fmt.Errorf("file %s was not collected", fileName)
 		}
 
-		return contents, nil
+		return collected.ReadResult(spillDir, fileName)
 	}
 	getChildCollectedFileContents := func(prefix string) (map[string][]byte, error) {
 		matching := make(map[string][]byte)
-		for k, v := range allCollectedData {
+		for k := range collected {
 			if strings.HasPrefix(k, prefix) {
-				matching[k] = v
+				matching[k] = nil
 			}
 		}
 
-		for k, v := range allCollectedData {
+		for k := range collected {
 			if ok, _ := filepath.Match(prefix, k); ok {
-				matching[k] = v
+				matching[k] = nil
 			}
 		}
 
+		for k := range matching {
+			contents, err := collected.ReadResult(spillDir, k)
+			if err != nil {
+				return nil, err
+			}
+			matching[k] = contents
+		}
+
 		return matching, nil
 	}
 
 	analyzeResults := []*analyze.AnalyzeResult{}
 	for _, analyzer := range analyzers {
-		analyzeResult, err := analyze.Analyze(analyzer, getCollectedFileContents, getChildCollectedFileContents)
+		var analyzeResult []*analyze.AnalyzeResult
+		var err error
+		if analyzer.Compound != nil {
+			analyzeResult, err = analyze.AnalyzeCompound(analyzer, analyzeResults)
+		} else {
+			analyzeResult, err = analyze.Analyze(analyzer, getCollectedFileContents, getChildCollectedFileContents)
+		}
 		if err != nil {
 			strict, strictErr := HasStrictAnalyzer(analyzer)
 			if strictErr != nil {
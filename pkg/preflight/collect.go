@@ -10,7 +10,6 @@ import (
 	analyze "github.com/replicatedhq/troubleshoot/pkg/analyze"
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
 	"github.com/replicatedhq/troubleshoot/pkg/collect"
-	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
@@ -23,6 +22,9 @@ type CollectOpts struct {
 	LabelSelector          string
 	Timeout                time.Duration
 	ProgressChan           chan interface{}
+	// SpillDir is where results too large to keep in memory are spilled to, from collect.NewSpillDir.
+	// Without one, results are kept in memory.
+	SpillDir string
 }
 
 type CollectProgress struct {
@@ -52,6 +54,7 @@ type ClusterCollectResult struct {
 	RemoteCollectors collect.RemoteCollectors
 	isRBACAllowed    bool
 	Spec             *troubleshootv1beta2.Preflight
+	SpillDir         string
 }
 
 func (cr ClusterCollectResult) IsRBACAllowed() bool {
@@ -62,6 +65,7 @@ type HostCollectResult struct {
 	AllCollectedData map[string][]byte
 	Collectors       []collect.HostCollector
 	Spec             *troubleshootv1beta2.HostPreflight
+	SpillDir         string
 }
 
 func (cr HostCollectResult) IsRBACAllowed() bool {
@@ -87,7 +91,7 @@ func CollectHost(opts CollectOpts, p *troubleshootv1beta2.HostPreflight) (Collec
 
 	var collectors []collect.HostCollector
 	for _, desiredCollector := range collectSpecs {
-		collector, ok := collect.GetHostCollector(desiredCollector, "")
+		collector, ok := collect.GetHostCollector(desiredCollector, opts.SpillDir)
 		if ok {
 			collectors = append(collectors, collector)
 		}
@@ -96,6 +100,7 @@ func CollectHost(opts CollectOpts, p *troubleshootv1beta2.HostPreflight) (Collec
 	collectResult := HostCollectResult{
 		Collectors: collectors,
 		Spec:       p,
+		SpillDir:   opts.SpillDir,
 	}
 
 	for _, collector := range collectors {
@@ -131,15 +136,15 @@ func Collect(opts CollectOpts, p *troubleshootv1beta2.Preflight) (CollectResult,
 
 	allCollectedData := make(map[string][]byte)
 
-	k8sClient, err := kubernetes.NewForConfig(opts.KubernetesRestConfig)
+	clients, err := collect.NewClientFactory(opts.KubernetesRestConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to instantiate Kubernetes client")
 	}
 
 	for _, desiredCollector := range collectSpecs {
-		if collectorInterface, ok := collect.GetCollector(desiredCollector, "", opts.Namespace, opts.KubernetesRestConfig, k8sClient, nil); ok {
+		if collectorInterface, ok := collect.GetCollectorWithClients(desiredCollector, opts.SpillDir, opts.Namespace, clients, nil); ok {
 			if collector, ok := collectorInterface.(collect.Collector); ok {
-				err := collector.CheckRBAC(context.Background(), collector, desiredCollector, opts.KubernetesRestConfig, opts.Namespace)
+				err := collector.CheckRBAC(context.Background(), collector, desiredCollector, clients.Config(), opts.Namespace)
 				if err != nil {
 					return nil, errors.Wrap(err, "failed to check RBAC for collectors")
 				}
@@ -160,6 +165,7 @@ func Collect(opts CollectOpts, p *troubleshootv1beta2.Preflight) (CollectResult,
 	collectResult := ClusterCollectResult{
 		Collectors: allCollectors,
 		Spec:       p,
+		SpillDir:   opts.SpillDir,
 	}
 
 	foundForbidden := false
@@ -215,7 +221,7 @@ func Collect(opts CollectOpts, p *troubleshootv1beta2.Preflight) (CollectResult,
 			Collectors:     collectorList,
 		}
 
-		result, err := collector.Collect(opts.ProgressChan)
+		result, err := collect.RunCollector(context.Background(), collector, opts.ProgressChan)
 		if err != nil {
 			collectorList[collector.Title()] = CollectorStatus{
 				Status: "failed",
@@ -16,6 +16,10 @@ const (
 	flagSince                     = "since"
 	flagOutput                    = "output"
 	flagDebug                     = "debug"
+	flagValues                    = "values"
+	flagSet                       = "set"
+	flagMessageCatalog            = "message-catalog"
+	flagLanguage                  = "language"
 )
 
 type PreflightFlags struct {
@@ -29,6 +33,10 @@ type PreflightFlags struct {
 	Since                     *string
 	Output                    *string
 	Debug                     *bool
+	Values                    *[]string
+	Set                       *[]string
+	MessageCatalog            *[]string
+	Language                  *string
 }
 
 var preflightFlags *PreflightFlags
@@ -45,6 +53,10 @@ func NewPreflightFlags() *PreflightFlags {
 		Since:                     utilpointer.String(""),
 		Output:                    utilpointer.String("o"),
 		Debug:                     utilpointer.Bool(false),
+		Values:                    &[]string{},
+		Set:                       &[]string{},
+		MessageCatalog:            &[]string{},
+		Language:                  utilpointer.String(""),
 	}
 }
 
@@ -66,7 +78,7 @@ func (f *PreflightFlags) addFlags(flags *flag.FlagSet) {
 		flags.BoolVar(f.Interactive, flagInteractive, *f.Interactive, "interactive preflights")
 	}
 	if f.Format != nil {
-		flags.StringVar(f.Format, flagFormat, *f.Format, "output format, one of human, json, yaml. only used when interactive is set to false")
+		flags.StringVar(f.Format, flagFormat, *f.Format, "output format, one of human, json, analysis. only used when interactive is set to false")
 	}
 
 	if f.CollectorImage != nil {
@@ -93,4 +105,16 @@ func (f *PreflightFlags) addFlags(flags *flag.FlagSet) {
 	if f.Debug != nil {
 		flags.BoolVar(f.Debug, flagDebug, *f.Debug, "enable debug logging")
 	}
+	if f.Values != nil {
+		flags.StringSliceVar(f.Values, flagValues, *f.Values, "path to a yaml file with values available to templated exclude expressions")
+	}
+	if f.Set != nil {
+		flags.StringArrayVar(f.Set, flagSet, *f.Set, "set values available to templated exclude expressions (e.g. --set key=value)")
+	}
+	if f.MessageCatalog != nil {
+		flags.StringSliceVar(f.MessageCatalog, flagMessageCatalog, *f.MessageCatalog, "files with translations of analyzer outcome messages")
+	}
+	if f.Language != nil {
+		flags.StringVar(f.Language, flagLanguage, *f.Language, "language to translate analyzer outcome messages to with --message-catalog, such as de or pt-BR. defaults to the language of the locale")
+	}
 }
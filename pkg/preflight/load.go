@@ -0,0 +1,166 @@
+package preflight
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/troubleshoot/cmd/util"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	troubleshootclientsetscheme "github.com/replicatedhq/troubleshoot/pkg/client/troubleshootclientset/scheme"
+	"github.com/replicatedhq/troubleshoot/pkg/docrewrite"
+	"github.com/replicatedhq/troubleshoot/pkg/oci"
+	"github.com/replicatedhq/troubleshoot/pkg/specs"
+	"github.com/replicatedhq/troubleshoot/pkg/strictdecode"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// loadPreflightSpecs loads the specs of all of args, and merges their Preflight and HostPreflight
+// documents in the order they are in. Either of the returned specs is nil if there were none.
+func loadPreflightSpecs(args []string) (*troubleshootv1beta2.Preflight, *troubleshootv1beta2.HostPreflight, error) {
+	var preflightSpec *troubleshootv1beta2.Preflight
+	var hostPreflightSpec *troubleshootv1beta2.HostPreflight
+
+	for _, arg := range args {
+		preflightContent, err := loadPreflightSpec(arg)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		preflightSpec, hostPreflightSpec, err = parsePreflightDocs(preflightSpec, hostPreflightSpec, preflightContent)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to parse %s", arg)
+		}
+	}
+
+	if preflightSpec == nil && hostPreflightSpec == nil {
+		return nil, nil, errors.New("no Preflight or HostPreflight documents in specs")
+	}
+
+	return preflightSpec, hostPreflightSpec, nil
+}
+
+// parsePreflightDocs parses a multi-document spec and merges its Preflight and HostPreflight documents
+// into preflightSpec and hostPreflightSpec. Documents of other kinds are skipped.
+func parsePreflightDocs(preflightSpec *troubleshootv1beta2.Preflight, hostPreflightSpec *troubleshootv1beta2.HostPreflight, preflightContent []byte) (*troubleshootv1beta2.Preflight, *troubleshootv1beta2.HostPreflight, error) {
+	troubleshootclientsetscheme.AddToScheme(scheme.Scheme)
+	decode := strictdecode.NewDecoder(scheme.Codecs.UniversalDeserializer()).Decode
+
+	for i, doc := range strings.Split(string(preflightContent), "\n---\n") {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+
+		converted, err := docrewrite.ConvertToV1Beta2([]byte(doc))
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to convert doc %d to v1beta2", i)
+		}
+
+		obj, _, err := decode(converted, nil, nil)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to parse doc %d", i)
+		}
+
+		switch spec := obj.(type) {
+		case *troubleshootv1beta2.Preflight:
+			preflightSpec = concatPreflightSpec(preflightSpec, spec)
+		case *troubleshootv1beta2.HostPreflight:
+			hostPreflightSpec = concatHostPreflightSpec(hostPreflightSpec, spec)
+		}
+	}
+
+	return preflightSpec, hostPreflightSpec, nil
+}
+
+// concatPreflightSpec appends the collectors and analyzers of source to target. The name and
+// uploadResultsTo of target are kept, unless it has none.
+func concatPreflightSpec(target *troubleshootv1beta2.Preflight, source *troubleshootv1beta2.Preflight) *troubleshootv1beta2.Preflight {
+	if target == nil {
+		return source
+	}
+
+	newPreflight := target.DeepCopy()
+	newPreflight.Spec.Collectors = append(newPreflight.Spec.Collectors, source.Spec.Collectors...)
+	newPreflight.Spec.RemoteCollectors = append(newPreflight.Spec.RemoteCollectors, source.Spec.RemoteCollectors...)
+	newPreflight.Spec.Analyzers = append(newPreflight.Spec.Analyzers, source.Spec.Analyzers...)
+	if newPreflight.Spec.UploadResultsTo == "" {
+		newPreflight.Spec.UploadResultsTo = source.Spec.UploadResultsTo
+	}
+	return newPreflight
+}
+
+// concatHostPreflightSpec appends the collectors and analyzers of source to target. The name of target
+// is kept.
+func concatHostPreflightSpec(target *troubleshootv1beta2.HostPreflight, source *troubleshootv1beta2.HostPreflight) *troubleshootv1beta2.HostPreflight {
+	if target == nil {
+		return source
+	}
+
+	newHostPreflight := target.DeepCopy()
+	newHostPreflight.Spec.Collectors = append(newHostPreflight.Spec.Collectors, source.Spec.Collectors...)
+	newHostPreflight.Spec.RemoteCollectors = append(newHostPreflight.Spec.RemoteCollectors, source.Spec.RemoteCollectors...)
+	newHostPreflight.Spec.Analyzers = append(newHostPreflight.Spec.Analyzers, source.Spec.Analyzers...)
+	return newHostPreflight
+}
+
+// loadPreflightSpec loads a spec from a secret, a file, an oci:// registry or a url
+func loadPreflightSpec(arg string) ([]byte, error) {
+	if strings.HasPrefix(arg, "secret/") {
+		// format secret/namespace-name/secret-name
+		pathParts := strings.Split(arg, "/")
+		if len(pathParts) != 3 {
+			return nil, errors.Errorf("path %s must have 3 components", arg)
+		}
+
+		spec, err := specs.LoadFromSecret(pathParts[1], pathParts[2], "preflight-spec")
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get spec from secret")
+		}
+
+		return spec, nil
+	}
+
+	_, err := os.Stat(arg)
+	if err == nil {
+		return ioutil.ReadFile(arg)
+	}
+
+	u, parseErr := url.Parse(arg)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if u.Scheme == "oci" {
+		content, err := oci.PullPreflightFromOCI(arg)
+		if err != nil {
+			if err == oci.ErrNoRelease {
+				return nil, errors.Errorf("no release found for %s.\nCheck the oci:// uri for errors or contact the application vendor for support.", arg)
+			}
+
+			return nil, err
+		}
+
+		return content, nil
+	}
+
+	if !util.IsURL(arg) {
+		return nil, fmt.Errorf("%s is not a URL and was not found (err %s)", arg, err)
+	}
+
+	req, err := http.NewRequest("GET", arg, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Replicated_Preflight/v1beta2")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return ioutil.ReadAll(resp.Body)
+}
@@ -0,0 +1,87 @@
+package preflight
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_loadPreflightSpecs(t *testing.T) {
+	req := require.New(t)
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	req.NoError(os.WriteFile(base, []byte(`apiVersion: troubleshoot.sh/v1beta2
+kind: Preflight
+metadata:
+  name: base
+spec:
+  uploadResultsTo: https://example.com/base
+  analyzers:
+  - clusterVersion: {}
+---
+apiVersion: troubleshoot.sh/v1beta2
+kind: HostPreflight
+metadata:
+  name: base-host
+spec:
+  collectors:
+  - cpu: {}
+`), 0644))
+
+	app := filepath.Join(dir, "app.yaml")
+	req.NoError(os.WriteFile(app, []byte(`apiVersion: troubleshoot.replicated.com/v1beta1
+kind: Preflight
+metadata:
+  name: app
+spec:
+  uploadResultsTo: https://example.com/app
+  collectors:
+  - clusterResources: {}
+  analyzers:
+  - nodeResources: {}
+---
+apiVersion: troubleshoot.sh/v1beta2
+kind: Redactor
+metadata:
+  name: redactors
+---
+apiVersion: troubleshoot.sh/v1beta2
+kind: HostPreflight
+metadata:
+  name: app-host
+spec:
+  collectors:
+  - memory: {}
+`), 0644))
+
+	preflightSpec, hostPreflightSpec, err := loadPreflightSpecs([]string{base, app})
+	req.NoError(err)
+
+	req.NotNil(preflightSpec)
+	assert.Equal(t, "base", preflightSpec.Name)
+	assert.Equal(t, "https://example.com/base", preflightSpec.Spec.UploadResultsTo)
+	req.Len(preflightSpec.Spec.Collectors, 1)
+	assert.NotNil(t, preflightSpec.Spec.Collectors[0].ClusterResources)
+	req.Len(preflightSpec.Spec.Analyzers, 2)
+	assert.NotNil(t, preflightSpec.Spec.Analyzers[0].ClusterVersion)
+	assert.NotNil(t, preflightSpec.Spec.Analyzers[1].NodeResources)
+
+	req.NotNil(hostPreflightSpec)
+	assert.Equal(t, "base-host", hostPreflightSpec.Name)
+	req.Len(hostPreflightSpec.Spec.Collectors, 2)
+	assert.NotNil(t, hostPreflightSpec.Spec.Collectors[0].CPU)
+	assert.NotNil(t, hostPreflightSpec.Spec.Collectors[1].Memory)
+
+	redactors := filepath.Join(dir, "redactors.yaml")
+	req.NoError(os.WriteFile(redactors, []byte(`apiVersion: troubleshoot.sh/v1beta2
+kind: Redactor
+metadata:
+  name: redactors
+`), 0644))
+	_, _, err = loadPreflightSpecs([]string{redactors})
+	req.Error(err)
+}
@@ -3,34 +3,29 @@ package preflight
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"net/url"
 	"os"
 	"os/signal"
-	"strings"
 	"time"
 
 	cursor "github.com/ahmetalpbalkan/go-cursor"
 	"github.com/fatih/color"
 	"github.com/pkg/errors"
-	"github.com/replicatedhq/troubleshoot/cmd/util"
 	analyzer "github.com/replicatedhq/troubleshoot/pkg/analyze"
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
-	troubleshootclientsetscheme "github.com/replicatedhq/troubleshoot/pkg/client/troubleshootclientset/scheme"
-	"github.com/replicatedhq/troubleshoot/pkg/docrewrite"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
 	"github.com/replicatedhq/troubleshoot/pkg/k8sutil"
-	"github.com/replicatedhq/troubleshoot/pkg/oci"
 	"github.com/replicatedhq/troubleshoot/pkg/specs"
 	"github.com/spf13/viper"
 	spin "github.com/tj/go-spin"
 	"golang.org/x/sync/errgroup"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
-func RunPreflights(interactive bool, output, format, arg string) error {
+// RunPreflights runs the preflight checks of the specs in args. The Preflight and HostPreflight
+// documents of all of the specs are merged in order, and both are run if there are both.
+func RunPreflights(interactive bool, output, format string, args []string) error {
 	if interactive {
 		fmt.Print(cursor.Hide())
 		defer fmt.Print(cursor.Show())
@@ -43,80 +38,24 @@ func RunPreflights(interactive bool, output, format, arg string) error {
 		os.Exit(0)
 	}()
 
-	var preflightContent []byte
-	var err error
-	if strings.HasPrefix(arg, "secret/") {
-		// format secret/namespace-name/secret-name
-		pathParts := strings.Split(arg, "/")
-		if len(pathParts) != 3 {
-			return errors.Errorf("path %s must have 3 components", arg)
-		}
-
-		spec, err := specs.LoadFromSecret(pathParts[1], pathParts[2], "preflight-spec")
-		if err != nil {
-			return errors.Wrap(err, "failed to get spec from secret")
-		}
-
-		preflightContent = spec
-	} else if _, err = os.Stat(arg); err == nil {
-		b, err := ioutil.ReadFile(arg)
-		if err != nil {
-			return err
-		}
-
-		preflightContent = b
-	} else {
-		u, err := url.Parse(arg)
-		if err != nil {
-			return err
-		}
-
-		if u.Scheme == "oci" {
-			content, err := oci.PullPreflightFromOCI(arg)
-			if err != nil {
-				if err == oci.ErrNoRelease {
-					return errors.Errorf("no release found for %s.\nCheck the oci:// uri for errors or contact the application vendor for support.", arg)
-				}
-
-				return err
-			}
-
-			preflightContent = content
-		} else {
-			if !util.IsURL(arg) {
-				return fmt.Errorf("%s is not a URL and was not found (err %s)", arg, err)
-			}
-
-			req, err := http.NewRequest("GET", arg, nil)
-			if err != nil {
-				return err
-			}
-			req.Header.Set("User-Agent", "Replicated_Preflight/v1beta2")
-			resp, err := http.DefaultClient.Do(req)
-			if err != nil {
-				return err
-			}
-			defer resp.Body.Close()
-
-			body, err := ioutil.ReadAll(resp.Body)
-			if err != nil {
-				return err
-			}
+	preflightSpec, hostPreflightSpec, err := loadPreflightSpecs(args)
+	if err != nil {
+		return err
+	}
 
-			preflightContent = body
+	if preflightSpec != nil {
+		if err := renderExcludes(preflightSpec); err != nil {
+			return errors.Wrap(err, "failed to render exclude expressions")
 		}
 	}
-
-	preflightContent, err = docrewrite.ConvertToV1Beta2(preflightContent)
-	if err != nil {
-		return errors.Wrap(err, "failed to convert to v1beta2")
+	if hostPreflightSpec != nil {
+		if err := renderExcludes(hostPreflightSpec); err != nil {
+			return errors.Wrap(err, "failed to render exclude expressions")
+		}
 	}
 
-	troubleshootclientsetscheme.AddToScheme(scheme.Scheme)
-	decode := scheme.Codecs.UniversalDeserializer().Decode
-	obj, _, err := decode([]byte(preflightContent), nil, nil)
-	if err != nil {
-		return errors.Wrapf(err, "failed to parse %s", arg)
+	if err := localizeMessages(preflightSpec, hostPreflightSpec); err != nil {
+		return err
 	}
 
 	var collectResults []CollectResult
@@ -136,16 +75,24 @@ func RunPreflights(interactive bool, output, format, arg string) error {
 		progressCollection.Go(collectNonInteractiveProgess(ctx, progressCh))
 	}
 
-	if preflightSpec, ok := obj.(*troubleshootv1beta2.Preflight); ok {
-		r, err := collectInCluster(preflightSpec, progressCh)
+	// results too large to keep in memory are spilled to temp files until they have been analyzed
+	spillDir, err := collect.NewSpillDir()
+	if err != nil {
+		return errors.Wrap(err, "failed to create spill dir")
+	}
+	defer collect.RemoveSpillDir(spillDir)
+
+	if preflightSpec != nil {
+		r, err := collectInCluster(preflightSpec, spillDir, progressCh)
 		if err != nil {
 			return errors.Wrap(err, "failed to collect in cluster")
 		}
 		collectResults = append(collectResults, *r)
 		preflightSpecName = preflightSpec.Name
-	} else if hostPreflightSpec, ok := obj.(*troubleshootv1beta2.HostPreflight); ok {
+	}
+	if hostPreflightSpec != nil {
 		if len(hostPreflightSpec.Spec.Collectors) > 0 {
-			r, err := collectHost(hostPreflightSpec, progressCh)
+			r, err := collectHost(hostPreflightSpec, spillDir, progressCh)
 			if err != nil {
 				return errors.Wrap(err, "failed to collect from host")
 			}
@@ -158,7 +105,9 @@ func RunPreflights(interactive bool, output, format, arg string) error {
 			}
 			collectResults = append(collectResults, *r)
 		}
-		preflightSpecName = hostPreflightSpec.Name
+		if preflightSpecName == "" {
+			preflightSpecName = hostPreflightSpec.Name
+		}
 	}
 
 	if collectResults == nil {
@@ -170,12 +119,10 @@ func RunPreflights(interactive bool, output, format, arg string) error {
 		analyzeResults = append(analyzeResults, res.Analyze()...)
 	}
 
-	if preflightSpec, ok := obj.(*troubleshootv1beta2.Preflight); ok {
-		if preflightSpec.Spec.UploadResultsTo != "" {
-			err := uploadResults(preflightSpec.Spec.UploadResultsTo, analyzeResults)
-			if err != nil {
-				progressCh <- err
-			}
+	if preflightSpec != nil && preflightSpec.Spec.UploadResultsTo != "" {
+		err := uploadResults(preflightSpec.Spec.UploadResultsTo, analyzeResults)
+		if err != nil {
+			progressCh <- err
 		}
 	}
 
@@ -192,6 +139,48 @@ func RunPreflights(interactive bool, output, format, arg string) error {
 	return showStdoutResults(format, preflightSpecName, analyzeResults)
 }
 
+// localizeMessages translates the outcome messages of the analyzers of the specs with the message
+// catalogs, to the language of the flag or else of the locale
+func localizeMessages(preflightSpec *troubleshootv1beta2.Preflight, hostPreflightSpec *troubleshootv1beta2.HostPreflight) error {
+	v := viper.GetViper()
+
+	catalog, err := analyzer.LoadMessageCatalogs(v.GetStringSlice(flagMessageCatalog))
+	if err != nil {
+		return err
+	}
+	language := v.GetString(flagLanguage)
+	if language == "" {
+		language = analyzer.LanguageFromEnv()
+	}
+
+	if preflightSpec != nil {
+		analyzer.LocalizeAnalyzers(preflightSpec.Spec.Analyzers, nil, catalog, language)
+	}
+	if hostPreflightSpec != nil {
+		analyzer.LocalizeAnalyzers(nil, hostPreflightSpec.Spec.Analyzers, catalog, language)
+	}
+	return nil
+}
+
+func renderExcludes(obj runtime.Object) error {
+	v := viper.GetViper()
+
+	values, err := specs.LoadValues(v.GetStringSlice("values"), v.GetStringSlice("set"))
+	if err != nil {
+		return errors.Wrap(err, "failed to load values")
+	}
+
+	if _, ok := obj.(*troubleshootv1beta2.Preflight); ok {
+		restConfig, err := k8sutil.GetRESTConfig()
+		if err != nil {
+			return errors.Wrap(err, "failed to convert kube flags to rest config")
+		}
+		return specs.RenderExcludesForCluster(obj, values, restConfig)
+	}
+
+	return specs.RenderExcludesForCluster(obj, values, nil)
+}
+
 func collectInteractiveProgress(ctx context.Context, progressCh <-chan interface{}) func() error {
 	return func() error {
 		spinner := spin.New()
@@ -245,7 +234,7 @@ func collectNonInteractiveProgess(ctx context.Context, progressCh <-chan interfa
 	}
 }
 
-func collectInCluster(preflightSpec *troubleshootv1beta2.Preflight, progressCh chan interface{}) (*CollectResult, error) {
+func collectInCluster(preflightSpec *troubleshootv1beta2.Preflight, spillDir string, progressCh chan interface{}) (*CollectResult, error) {
 	v := viper.GetViper()
 
 	restConfig, err := k8sutil.GetRESTConfig()
@@ -258,6 +247,7 @@ func collectInCluster(preflightSpec *troubleshootv1beta2.Preflight, progressCh c
 		IgnorePermissionErrors: v.GetBool("collect-without-permissions"),
 		ProgressChan:           progressCh,
 		KubernetesRestConfig:   restConfig,
+		SpillDir:               spillDir,
 	}
 
 	if v.GetString("since") != "" || v.GetString("since-time") != "" {
@@ -326,9 +316,10 @@ func collectRemote(preflightSpec *troubleshootv1beta2.HostPreflight, progressCh
 	return &collectResults, nil
 }
 
-func collectHost(hostPreflightSpec *troubleshootv1beta2.HostPreflight, progressCh chan interface{}) (*CollectResult, error) {
+func collectHost(hostPreflightSpec *troubleshootv1beta2.HostPreflight, spillDir string, progressCh chan interface{}) (*CollectResult, error) {
 	collectOpts := CollectOpts{
 		ProgressChan: progressCh,
+		SpillDir:     spillDir,
 	}
 
 	collectResults, err := CollectHost(collectOpts, hostPreflightSpec)
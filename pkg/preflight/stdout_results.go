@@ -6,6 +6,7 @@ import (
 
 	"github.com/pkg/errors"
 	analyzerunner "github.com/replicatedhq/troubleshoot/pkg/analyze"
+	"github.com/replicatedhq/troubleshoot/pkg/convert"
 )
 
 func showStdoutResults(format string, preflightName string, analyzeResults []*analyzerunner.AnalyzeResult) error {
@@ -13,6 +14,8 @@ func showStdoutResults(format string, preflightName string, analyzeResults []*an
 		return showStdoutResultsHuman(preflightName, analyzeResults)
 	} else if format == "json" {
 		return showStdoutResultsJSON(preflightName, analyzeResults)
+	} else if format == "analysis" {
+		return showStdoutResultsAnalysis(analyzeResults)
 	}
 
 	return errors.Errorf("unknown output format: %q", format)
@@ -21,10 +24,15 @@ func showStdoutResults(format string, preflightName string, analyzeResults []*an
 func showStdoutResultsHuman(preflightName string, analyzeResults []*analyzerunner.AnalyzeResult) error {
 	fmt.Println("")
 	var failed bool
-	for _, analyzeResult := range analyzeResults {
-		testResultfailed := outputResult(analyzeResult)
-		if testResultfailed {
-			failed = true
+	for _, group := range groupResultsByCategory(analyzeResults) {
+		if group.category != "" {
+			fmt.Printf("--- %s\n", group.category)
+		}
+		for _, analyzeResult := range group.results {
+			testResultfailed := outputResult(analyzeResult)
+			if testResultfailed {
+				failed = true
+			}
 		}
 	}
 	if failed {
@@ -39,10 +47,14 @@ func showStdoutResultsHuman(preflightName string, analyzeResults []*analyzerunne
 
 func showStdoutResultsJSON(preflightName string, analyzeResults []*analyzerunner.AnalyzeResult) error {
 	type ResultOutput struct {
-		Title   string `json:"title"`
-		Message string `json:"message"`
-		URI     string `json:"uri,omitempty"`
-		Strict  bool   `json:"strict,omitempty"`
+		Title          string   `json:"title"`
+		Message        string   `json:"message"`
+		URI            string   `json:"uri,omitempty"`
+		Strict         bool     `json:"strict,omitempty"`
+		DocString      string   `json:"docString,omitempty"`
+		Category       string   `json:"category,omitempty"`
+		Tags           []string `json:"tags,omitempty"`
+		RemediationURI string   `json:"remediationUri,omitempty"`
 	}
 	type Output struct {
 		Pass []ResultOutput `json:"pass,omitempty"`
@@ -58,9 +70,13 @@ func showStdoutResultsJSON(preflightName string, analyzeResults []*analyzerunner
 
 	for _, analyzeResult := range analyzeResults {
 		resultOutput := ResultOutput{
-			Title:   analyzeResult.Title,
-			Message: analyzeResult.Message,
-			URI:     analyzeResult.URI,
+			Title:          analyzeResult.Title,
+			Message:        analyzeResult.Message,
+			URI:            analyzeResult.URI,
+			DocString:      analyzeResult.DocString,
+			Category:       analyzeResult.Category,
+			Tags:           analyzeResult.Tags,
+			RemediationURI: analyzeResult.RemediationURI,
 		}
 
 		if analyzeResult.Strict {
@@ -86,6 +102,18 @@ func showStdoutResultsJSON(preflightName string, analyzeResults []*analyzerunner
 	return nil
 }
 
+// showStdoutResultsAnalysis prints the results in the same versioned format as analysis.json in a support bundle
+func showStdoutResultsAnalysis(analyzeResults []*analyzerunner.AnalyzeResult) error {
+	b, err := convert.MarshalAnalysisResults(analyzeResults)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal results")
+	}
+
+	fmt.Printf("%s\n", b)
+
+	return nil
+}
+
 func outputResult(analyzeResult *analyzerunner.AnalyzeResult) bool {
 	if analyzeResult.IsPass {
 		fmt.Printf("   --- PASS %s\n", analyzeResult.Title)
@@ -102,8 +130,36 @@ func outputResult(analyzeResult *analyzerunner.AnalyzeResult) bool {
 		fmt.Printf("      --- Strict: %t\n", analyzeResult.Strict)
 	}
 
+	if analyzeResult.IsFail || analyzeResult.IsWarn {
+		if analyzeResult.RemediationURI != "" {
+			fmt.Printf("      --- Remediation: %s\n", analyzeResult.RemediationURI)
+		}
+	}
+
 	if analyzeResult.IsFail {
 		return true
 	}
 	return false
 }
+
+type resultGroup struct {
+	category string
+	results  []*analyzerunner.AnalyzeResult
+}
+
+// groupResultsByCategory groups results by their analyzer category, keeping the order in which
+// categories first appear. Results without a category are grouped together.
+func groupResultsByCategory(analyzeResults []*analyzerunner.AnalyzeResult) []resultGroup {
+	groups := []resultGroup{}
+	index := map[string]int{}
+	for _, analyzeResult := range analyzeResults {
+		i, ok := index[analyzeResult.Category]
+		if !ok {
+			i = len(groups)
+			index[analyzeResult.Category] = i
+			groups = append(groups, resultGroup{category: analyzeResult.Category})
+		}
+		groups[i].results = append(groups[i].results, analyzeResult)
+	}
+	return groups
+}
@@ -9,6 +9,11 @@ type UploadPreflightResult struct {
 	Title   string `json:"title"`
 	Message string `json:"message"`
 	URI     string `json:"uri,omitempty"`
+
+	DocString      string   `json:"docString,omitempty"`
+	Category       string   `json:"category,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	RemediationURI string   `json:"remediationUri,omitempty"`
 }
 
 type UploadPreflightError struct {
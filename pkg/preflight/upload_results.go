@@ -23,6 +23,11 @@ func uploadResults(uri string, analyzeResults []*analyzerunner.AnalyzeResult) er
 			Title:   analyzeResult.Title,
 			Message: analyzeResult.Message,
 			URI:     analyzeResult.URI,
+
+			DocString:      analyzeResult.DocString,
+			Category:       analyzeResult.Category,
+			Tags:           analyzeResult.Tags,
+			RemediationURI: analyzeResult.RemediationURI,
 		}
 
 		uploadPreflightResults.Results = append(uploadPreflightResults.Results, uploadPreflightResult)
@@ -0,0 +1,82 @@
+package profiling
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	CPUProfileFilename  = "cpu.pprof"
+	HeapProfileFilename = "heap.pprof"
+	TraceFilename       = "trace.out"
+)
+
+// Start captures a CPU profile and an execution trace of the running process into dir, creating it if
+// needed. The returned function stops them and writes a heap profile. It must be called before the
+// process exits or the profiles will be incomplete.
+func Start(dir string) (func() error, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create profile dir")
+	}
+
+	cpuFile, err := os.Create(filepath.Join(dir, CPUProfileFilename))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cpu profile")
+	}
+
+	if err := pprof.StartCPUProfile(cpuFile); err != nil {
+		cpuFile.Close()
+		return nil, errors.Wrap(err, "failed to start cpu profile")
+	}
+
+	traceFile, err := os.Create(filepath.Join(dir, TraceFilename))
+	if err != nil {
+		pprof.StopCPUProfile()
+		cpuFile.Close()
+		return nil, errors.Wrap(err, "failed to create trace")
+	}
+
+	if err := trace.Start(traceFile); err != nil {
+		pprof.StopCPUProfile()
+		cpuFile.Close()
+		traceFile.Close()
+		return nil, errors.Wrap(err, "failed to start trace")
+	}
+
+	stop := func() error {
+		trace.Stop()
+		pprof.StopCPUProfile()
+
+		if err := traceFile.Close(); err != nil {
+			return errors.Wrap(err, "failed to close trace")
+		}
+		if err := cpuFile.Close(); err != nil {
+			return errors.Wrap(err, "failed to close cpu profile")
+		}
+
+		return writeHeapProfile(filepath.Join(dir, HeapProfileFilename))
+	}
+
+	return stop, nil
+}
+
+func writeHeapProfile(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return errors.Wrap(err, "failed to create heap profile")
+	}
+	defer f.Close()
+
+	// collect garbage first so the profile shows live memory
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return errors.Wrap(err, "failed to write heap profile")
+	}
+
+	return nil
+}
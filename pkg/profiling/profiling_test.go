@@ -0,0 +1,29 @@
+package profiling
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStart(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "profile")
+
+	stop, err := Start(dir)
+	require.NoError(t, err)
+
+	// profiles can't be started twice
+	_, err = Start(t.TempDir())
+	assert.Error(t, err)
+
+	require.NoError(t, stop())
+
+	for _, filename := range []string{CPUProfileFilename, HeapProfileFilename, TraceFilename} {
+		info, err := os.Stat(filepath.Join(dir, filename))
+		require.NoError(t, err)
+		assert.NotZero(t, info.Size(), filename)
+	}
+}
@@ -0,0 +1,48 @@
+package redact
+
+import (
+	"regexp"
+	"sync"
+)
+
+// compiledPattern is a compiled redaction regex along with the replacement string built from its
+// named groups and the mask text
+type compiledPattern struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+type patternKey struct {
+	pattern  string
+	maskText string
+}
+
+// patternCache holds the compiled patterns of the default redactors for the lifetime of the process, so
+// each pattern only needs to be compiled once. regexp.Regexp is safe for concurrent use, so cached patterns
+// are shared between redactors. The patterns of additional redactors can have the values they redact in
+// them, so they are not cached here, and are kept only as long as the templates of their spec.
+var patternCache sync.Map
+
+func compilePattern(pattern, maskText string, cached bool) (*compiledPattern, error) {
+	key := patternKey{pattern: pattern, maskText: maskText}
+	if cached {
+		if compiled, ok := patternCache.Load(key); ok {
+			return compiled.(*compiledPattern), nil
+		}
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := &compiledPattern{
+		re:          re,
+		replacement: getReplacementPattern(re, maskText),
+	}
+	if !cached {
+		return compiled, nil
+	}
+	actual, _ := patternCache.LoadOrStore(key, compiled)
+	return actual.(*compiledPattern), nil
+}
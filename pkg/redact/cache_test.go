@@ -0,0 +1,41 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_compilePattern(t *testing.T) {
+	first, err := compilePattern(`(?P<mask>secret)`, MASK_TEXT, true)
+	require.NoError(t, err)
+	assert.Equal(t, MASK_TEXT, first.replacement)
+
+	second, err := compilePattern(`(?P<mask>secret)`, MASK_TEXT, true)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	otherMask, err := compilePattern(`(?P<mask>secret)`, "REDACTED", true)
+	require.NoError(t, err)
+	assert.NotSame(t, first, otherMask)
+	assert.Equal(t, "REDACTED", otherMask.replacement)
+
+	// patterns that are not cached are compiled each time, and are not kept
+	uncached, err := compilePattern(`(?P<mask>hunter2)`, MASK_TEXT, false)
+	require.NoError(t, err)
+	assert.Equal(t, MASK_TEXT, uncached.replacement)
+	_, ok := patternCache.Load(patternKey{pattern: `(?P<mask>hunter2)`, maskText: MASK_TEXT})
+	assert.False(t, ok)
+
+	_, err = compilePattern(`(?P<mask>secret`, MASK_TEXT, true)
+	assert.Error(t, err)
+}
+
+func Benchmark_getRedactors(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := getRedactors("cluster-resources/pods/default.json", nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
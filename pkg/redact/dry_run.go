@@ -0,0 +1,61 @@
+package redact
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+)
+
+// DryRun applies the default redactors that opts do not exclude and the additional redactors to the files
+// in rootDir without changing them, and returns the redactions that would be made. Tar archives within the
+// directory are not read.
+func DryRun(rootDir string, additionalRedactors []*troubleshootv1beta2.Redact, opts Options) (RedactionList, error) {
+	if err := ValidateDefaultRedactorGroups(opts.ExcludeDefaultRedactors); err != nil {
+		return RedactionList{}, err
+	}
+	defer ReleaseRedactors(additionalRedactors)
+
+	ResetRedactionList()
+
+	err := filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(rootDir, path)
+		if err != nil {
+			return errors.Wrap(err, "failed to get relative path")
+		}
+		relPath = filepath.ToSlash(relPath)
+		if filepath.Ext(relPath) == ".tar" || filepath.Ext(relPath) == ".tgz" || strings.HasSuffix(relPath, ".tar.gz") {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open %s", relPath)
+		}
+		defer f.Close()
+
+		redacted, err := RedactWithOptions(f, relPath, "", additionalRedactors, opts)
+		if err != nil {
+			return errors.Wrapf(err, "failed to redact %s", relPath)
+		}
+		if _, err := io.Copy(io.Discard, redacted); err != nil {
+			return errors.Wrapf(err, "failed to redact %s", relPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return RedactionList{}, err
+	}
+
+	return GetRedactionList(), nil
+}
@@ -0,0 +1,47 @@
+package redact
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"cluster-info/cluster_version.json": `{"info": {"gitVersion": "v1.25.3"}}`,
+		"app/config.txt":                    "host: 10.0.0.1\napi_key=abcdef\nplain\n",
+		"app/logs.tar.gz":                   "api_key=abcdef\n",
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+	}
+
+	redactors := []*troubleshootv1beta2.Redact{
+		{
+			Name: "api keys",
+			Removals: troubleshootv1beta2.Removals{
+				Regex: []troubleshootv1beta2.Regex{{Redactor: `(api_key=)(?P<mask>.*)`}},
+			},
+		},
+	}
+
+	list, err := DryRun(dir, redactors, Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, `app/config.txt  1  Redact ipv4 addresses
+app/config.txt  2  api keys.regex.0
+`, list.Details())
+
+	for name, contents := range files {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Equal(t, contents, string(b))
+	}
+}
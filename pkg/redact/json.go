@@ -0,0 +1,167 @@
+package redact
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/replicatedhq/troubleshoot/pkg/logger"
+)
+
+// JSONRedactor masks the values at a path in JSON documents, e.g. items.*.spec.containers.*.env.*.value
+type JSONRedactor struct {
+	maskPath   []string
+	maskText   string
+	tokenize   bool
+	foundMatch bool
+	filePath   string
+	redactName string
+	isDefault  bool
+}
+
+func NewJSONRedactor(jsonPath, filePath, name string) *JSONRedactor {
+	pathComponents := strings.Split(jsonPath, ".")
+	return &JSONRedactor{maskPath: pathComponents, maskText: MASK_TEXT, filePath: filePath, redactName: name}
+}
+
+func (r *JSONRedactor) Redact(input io.Reader, path string) io.Reader {
+	if r.filePath != "" {
+		match, err := filepath.Match(r.filePath, path)
+		if err != nil {
+			logger.Printf("Failed to match %q and %q: %v", r.filePath, path, err)
+			return input
+		}
+		if !match {
+			return input
+		}
+	}
+	reader, writer := io.Pipe()
+	go func() {
+		var err error
+		defer func() {
+			if err == io.EOF {
+				writer.Close()
+			} else {
+				writer.CloseWithError(err)
+			}
+		}()
+
+		// only json documents are read into memory, anything else such as logs is passed through as it is read
+		bufReader := bufio.NewReader(input)
+		if !startsWithJSONDocument(bufReader) {
+			_, err = io.Copy(writer, bufReader)
+			return
+		}
+
+		var doc []byte
+		doc, err = ioutil.ReadAll(bufReader)
+		if err != nil {
+			return
+		}
+
+		var jsonInterface interface{}
+		decoder := json.NewDecoder(bytes.NewReader(doc))
+		decoder.UseNumber() // keep numbers exactly as they were written
+		if decodeErr := decoder.Decode(&jsonInterface); decodeErr != nil || decoder.More() {
+			// not a single json document, this is not a fatal error
+			_, err = writer.Write(doc)
+			return
+		}
+
+		newJson := r.redactJson(jsonInterface, r.maskPath)
+		if !r.foundMatch {
+			// no match found, so make no changes
+			_, err = writer.Write(doc)
+			return
+		}
+
+		var newBytes bytes.Buffer
+		encoder := json.NewEncoder(&newBytes)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(newJson)
+		if err != nil {
+			return
+		}
+
+		newLen := newBytes.Len()
+		_, err = newBytes.WriteTo(writer)
+		if err != nil {
+			return
+		}
+
+		addRedaction(Redaction{
+			RedactorName:      r.redactName,
+			CharactersRemoved: len(doc) - newLen,
+			Line:              0, // line 0 because we have no way to tell what line was impacted
+			File:              path,
+			IsDefaultRedactor: r.isDefault,
+		})
+	}()
+	return reader
+}
+
+func (r *JSONRedactor) redactJson(in interface{}, path []string) interface{} {
+	if len(path) == 0 {
+		r.foundMatch = true
+		if r.tokenize {
+			return tokenizeValue(in)
+		}
+		return r.maskText
+	}
+	switch typed := in.(type) {
+	case []interface{}:
+		// check if first path element is * - if it is, run redact on all children
+		if path[0] == "*" {
+			for i, child := range typed {
+				typed[i] = r.redactJson(child, path[1:])
+			}
+			return typed
+		}
+		// check if first path element is an integer - if it is, run redact on that child
+		pathIdx, err := strconv.Atoi(path[0])
+		if err != nil {
+			return typed
+		}
+		if pathIdx >= 0 && len(typed) > pathIdx {
+			typed[pathIdx] = r.redactJson(typed[pathIdx], path[1:])
+		}
+		return typed
+	case map[string]interface{}:
+		if path[0] == "*" {
+			for key, child := range typed {
+				typed[key] = r.redactJson(child, path[1:])
+			}
+			return typed
+		}
+
+		if child, ok := typed[path[0]]; ok {
+			typed[path[0]] = r.redactJson(child, path[1:])
+		}
+		return typed
+	default:
+		return typed
+	}
+}
+
+// startsWithJSONDocument peeks at the start of r to check if it holds a json object or array. Input that
+// is only whitespace as far as can be peeked is treated as a possible document.
+func startsWithJSONDocument(r *bufio.Reader) bool {
+	b, _ := r.Peek(r.Size())
+	for _, c := range b {
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '{', '[':
+			return true
+		default:
+			return false
+		}
+	}
+	return len(b) == r.Size()
+}
@@ -0,0 +1,210 @@
+package redact
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewJSONRedactor(t *testing.T) {
+	pods := `{
+  "kind": "PodList",
+  "items": [
+    {
+      "spec": {
+        "containers": [
+          {
+            "name": "app",
+            "env": [
+              {"name": "PASSWORD", "value": "hunter2"},
+              {"name": "PORT", "value": "8080"}
+            ]
+          },
+          {
+            "name": "sidecar"
+          }
+        ]
+      }
+    }
+  ],
+  "metadata": {"resourceVersion": "12345678901234567890"}
+}`
+
+	tests := []struct {
+		name           string
+		path           string
+		inputString    string
+		wantString     string
+		wantRedactions int
+	}{
+		{
+			name:        "wildcards through arrays",
+			path:        "items.*.spec.containers.*.env.*.value",
+			inputString: pods,
+			wantString: `{
+  "items": [
+    {
+      "spec": {
+        "containers": [
+          {
+            "env": [
+              {
+                "name": "PASSWORD",
+                "value": "***HIDDEN***"
+              },
+              {
+                "name": "PORT",
+                "value": "***HIDDEN***"
+              }
+            ],
+            "name": "app"
+          },
+          {
+            "name": "sidecar"
+          }
+        ]
+      }
+    }
+  ],
+  "kind": "PodList",
+  "metadata": {
+    "resourceVersion": "12345678901234567890"
+  }
+}
+`,
+			wantRedactions: 1,
+		},
+		{
+			name:        "array index and map wildcard",
+			path:        "items.0.spec.containers.0.*",
+			inputString: `{"items": [{"spec": {"containers": [{"name": "app", "image": "app:1", "port": 8080}]}}]}`,
+			wantString: `{
+  "items": [
+    {
+      "spec": {
+        "containers": [
+          {
+            "image": "***HIDDEN***",
+            "name": "***HIDDEN***",
+            "port": "***HIDDEN***"
+          }
+        ]
+      }
+    }
+  ]
+}
+`,
+			wantRedactions: 1,
+		},
+		{
+			name:        "numbers and html are preserved",
+			path:        "secret",
+			inputString: `{"secret": "a", "big": 12345678901234567890, "html": "<a>&"}`,
+			wantString: `{
+  "big": 12345678901234567890,
+  "html": "<a>&",
+  "secret": "***HIDDEN***"
+}
+`,
+			wantRedactions: 1,
+		},
+		{
+			name:        "no match",
+			path:        "items.*.spec.volumes",
+			inputString: pods,
+			wantString:  pods,
+		},
+		{
+			name:        "index after end of array",
+			path:        "items.5.spec",
+			inputString: pods,
+			wantString:  pods,
+		},
+		{
+			name:        "not json",
+			path:        "abc",
+			inputString: "abc: xyz\n",
+			wantString:  "abc: xyz\n",
+		},
+		{
+			name:        "multiple documents",
+			path:        "abc",
+			inputString: `{"abc": 1}{"abc": 2}`,
+			wantString:  `{"abc": 1}{"abc": 2}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+			jsonRunner := NewJSONRedactor(tt.path, "testfile", tt.name)
+
+			outReader := jsonRunner.Redact(bytes.NewReader([]byte(tt.inputString)), "testfile")
+			gotBytes, err := ioutil.ReadAll(outReader)
+			req.NoError(err)
+			req.Equal(tt.wantString, string(gotBytes))
+
+			actualRedactions := GetRedactionList()
+			ResetRedactionList()
+			req.Len(actualRedactions.ByFile["testfile"], tt.wantRedactions)
+			for _, redaction := range actualRedactions.ByFile["testfile"] {
+				req.Equal(tt.name, redaction.RedactorName)
+			}
+		})
+	}
+}
+
+func TestNewJSONRedactor_FileSelector(t *testing.T) {
+	req := require.New(t)
+	defer ResetRedactionList()
+
+	jsonRunner := NewJSONRedactor("secret", "cluster-resources/*.json", "")
+
+	input := `{"secret": "a"}`
+	outReader := jsonRunner.Redact(bytes.NewReader([]byte(input)), "other/file.json")
+	gotBytes, err := ioutil.ReadAll(outReader)
+	req.NoError(err)
+	req.Equal(input, string(gotBytes))
+}
+
+func TestNewJSONRedactor_PassThrough(t *testing.T) {
+	req := require.New(t)
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "log file larger than the peek buffer",
+			input: strings.Repeat("2022-10-01T10:00:00Z level=info msg=\"secret: abc\"\n", 1000),
+		},
+		{
+			name:  "empty",
+			input: "",
+		},
+	}
+
+	for _, tt := range tests {
+		jsonRunner := NewJSONRedactor("secret", "", tt.name)
+
+		outReader := jsonRunner.Redact(iotest.OneByteReader(strings.NewReader(tt.input)), "testfile")
+		gotBytes, err := ioutil.ReadAll(outReader)
+		req.NoError(err, tt.name)
+		req.Equal(tt.input, string(gotBytes), tt.name)
+	}
+
+	// leading whitespace is skipped when looking for a document
+	jsonRunner := NewJSONRedactor("secret", "", "whitespace")
+	outReader := jsonRunner.Redact(strings.NewReader(strings.Repeat(" ", 5000)+`{"secret": "a"}`), "testfile")
+	gotBytes, err := ioutil.ReadAll(outReader)
+	req.NoError(err)
+	req.Equal("{\n  \"secret\": \"***HIDDEN***\"\n}\n", string(gotBytes))
+
+	actualRedactions := GetRedactionList()
+	ResetRedactionList()
+	req.Len(actualRedactions.ByFile["testfile"], 1)
+}
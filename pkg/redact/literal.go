@@ -1,22 +1,22 @@
 package redact
 
 import (
-	"bufio"
-	"fmt"
+	"bytes"
 	"io"
-	"strings"
 )
 
 type literalRedactor struct {
 	matchString string
+	maskText    string
 	filePath    string
 	redactName  string
 	isDefault   bool
 }
 
-func literalString(matchString, path, name string) Redactor {
+func literalString(matchString, maskText, path, name string) Redactor {
 	return literalRedactor{
 		matchString: matchString,
+		maskText:    maskText,
 		filePath:    path,
 		redactName:  name,
 	}
@@ -27,7 +27,14 @@ func (r literalRedactor) Redact(input io.Reader, path string) io.Reader {
 
 	go func() {
 		var err error
+		reader := getReader(input)
+		bufWriter := getWriter(writer)
+		lineBuf := getLineBuffer()
 		defer func() {
+			bufWriter.Flush()
+			putLineBuffer(lineBuf)
+			putWriter(bufWriter)
+			putReader(reader)
 			if err == io.EOF {
 				writer.Close()
 			} else {
@@ -35,25 +42,27 @@ func (r literalRedactor) Redact(input io.Reader, path string) io.Reader {
 			}
 		}()
 
-		reader := bufio.NewReader(input)
+		match := []byte(r.matchString)
+		mask := []byte(r.maskText)
+
 		lineNum := 0
 		for {
 			lineNum++
-			var line string
-			line, err = readLine(reader)
+			var line []byte
+			line, err = readLine(reader, lineBuf)
 			if err != nil {
 				return
 			}
 
-			clean := strings.ReplaceAll(line, r.matchString, MASK_TEXT)
-
-			// io.WriteString would be nicer, but scanner strips new lines
-			fmt.Fprintf(writer, "%s\n", clean)
-			if err != nil {
-				return
+			if !bytes.Contains(line, match) {
+				writeLine(bufWriter, line)
+				continue
 			}
 
-			if clean != line {
+			clean := bytes.ReplaceAll(line, match, mask)
+			writeLine(bufWriter, clean)
+
+			if !bytes.Equal(clean, line) {
 				addRedaction(Redaction{
 					RedactorName:      r.redactName,
 					CharactersRemoved: len(line) - len(clean),
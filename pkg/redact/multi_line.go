@@ -2,7 +2,7 @@ package redact
 
 import (
 	"bufio"
-	"fmt"
+	"bytes"
 	"io"
 	"regexp"
 )
@@ -10,6 +10,7 @@ import (
 type MultiLineRedactor struct {
 	re1        *regexp.Regexp
 	re2        *regexp.Regexp
+	substStr   []byte
 	maskText   string
 	filePath   string
 	redactName string
@@ -17,33 +18,38 @@ type MultiLineRedactor struct {
 }
 
 func NewMultiLineRedactor(re1, re2, maskText, path, name string, isDefault bool) (*MultiLineRedactor, error) {
-	compiled1, err := regexp.Compile(re1)
+	compiled1, err := compilePattern(re1, maskText, isDefault)
 	if err != nil {
 		return nil, err
 	}
-	compiled2, err := regexp.Compile(re2)
+	compiled2, err := compilePattern(re2, maskText, isDefault)
 	if err != nil {
 		return nil, err
 	}
-	return &MultiLineRedactor{re1: compiled1, re2: compiled2, maskText: maskText, filePath: path, redactName: name, isDefault: isDefault}, nil
+	return &MultiLineRedactor{re1: compiled1.re, re2: compiled2.re, substStr: []byte(compiled2.replacement), maskText: maskText, filePath: path, redactName: name, isDefault: isDefault}, nil
 }
 
 func (r *MultiLineRedactor) Redact(input io.Reader, path string) io.Reader {
 	out, writer := io.Pipe()
 	go func() {
 		var err error
+		reader := getReader(input)
+		bufWriter := getWriter(writer)
+		line1Buf, line2Buf := getLineBuffer(), getLineBuffer()
 		defer func() {
+			bufWriter.Flush()
+			putLineBuffer(line1Buf)
+			putLineBuffer(line2Buf)
+			putWriter(bufWriter)
+			putReader(reader)
 			writer.CloseWithError(err)
 		}()
 
-		substStr := getReplacementPattern(r.re2, r.maskText)
-
-		reader := bufio.NewReader(input)
-		line1, line2, err := getNextTwoLines(reader, nil)
+		line1, line2, err := getNextTwoLines(reader, line1Buf, line2Buf)
 		if err != nil {
 			// this will print 2 blank lines for empty input...
-			fmt.Fprintf(writer, "%s\n", line1)
-			fmt.Fprintf(writer, "%s\n", line2)
+			writeLine(bufWriter, line1)
+			writeLine(bufWriter, line2)
 			return
 		}
 
@@ -53,24 +59,25 @@ func (r *MultiLineRedactor) Redact(input io.Reader, path string) io.Reader {
 			lineNum++ // the first line that can be redacted is line 2
 
 			// If line1 matches re1, then transform line2 using re2
-			if !r.re1.MatchString(line1) {
-				fmt.Fprintf(writer, "%s\n", line1)
-				line1, line2, err = getNextTwoLines(reader, &line2)
+			if !r.re1.Match(line1) {
+				writeLine(bufWriter, line1)
+
+				// line2 becomes the next line1, so swap the buffers instead of copying it
+				line1Buf, line2Buf = line2Buf, line1Buf
+				line1 = line2
+				line2, err = readLine(reader, line2Buf)
+
 				flushLastLine = true
 				continue
 			}
 			flushLastLine = false
 
-			clean := r.re2.ReplaceAllString(line2, substStr)
-
-			// io.WriteString would be nicer, but reader strips new lines
-			fmt.Fprintf(writer, "%s\n%s\n", line1, clean)
-			if err != nil {
-				return
-			}
+			clean := r.re2.ReplaceAll(line2, r.substStr)
+			writeLine(bufWriter, line1)
+			writeLine(bufWriter, clean)
 
 			// if clean is not equal to line2, a redaction was performed
-			if clean != line2 {
+			if !bytes.Equal(clean, line2) {
 				addRedaction(Redaction{
 					RedactorName:      r.redactName,
 					CharactersRemoved: len(line2) - len(clean),
@@ -80,35 +87,23 @@ func (r *MultiLineRedactor) Redact(input io.Reader, path string) io.Reader {
 				})
 			}
 
-			line1, line2, err = getNextTwoLines(reader, nil)
+			line1, line2, err = getNextTwoLines(reader, line1Buf, line2Buf)
 		}
 
 		if flushLastLine {
-			fmt.Fprintf(writer, "%s\n", line1)
+			writeLine(bufWriter, line1)
 		}
 	}()
 	return out
 }
 
-func getNextTwoLines(reader *bufio.Reader, curLine2 *string) (line1 string, line2 string, err error) {
-	line1 = ""
-	line2 = ""
-
-	if curLine2 == nil {
-		line1, err = readLine(reader)
-		if err != nil {
-			return
-		}
-
-		line2, err = readLine(reader)
-		return
-	}
-
-	line1 = *curLine2
-	line2, err = readLine(reader)
+// getNextTwoLines reads the next two lines into line1Buf and line2Buf
+func getNextTwoLines(reader *bufio.Reader, line1Buf, line2Buf *bytes.Buffer) (line1 []byte, line2 []byte, err error) {
+	line1, err = readLine(reader, line1Buf)
 	if err != nil {
-		return
+		return nil, nil, err
 	}
 
+	line2, err = readLine(reader, line2Buf)
 	return
 }
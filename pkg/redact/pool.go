@@ -0,0 +1,73 @@
+package redact
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sync"
+)
+
+// redactors are chained, with every file passing through each of them, so the readers, writers
+// and line buffers they use are pooled rather than allocated for every file
+const redactBufferSize = 64 * 1024
+
+var readerPool = sync.Pool{
+	New: func() interface{} {
+		return bufio.NewReaderSize(nil, redactBufferSize)
+	},
+}
+
+var writerPool = sync.Pool{
+	New: func() interface{} {
+		return bufio.NewWriterSize(nil, redactBufferSize)
+	},
+}
+
+var lineBufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+func getReader(r io.Reader) *bufio.Reader {
+	reader := readerPool.Get().(*bufio.Reader)
+	reader.Reset(r)
+	return reader
+}
+
+func putReader(reader *bufio.Reader) {
+	reader.Reset(nil)
+	readerPool.Put(reader)
+}
+
+func getWriter(w io.Writer) *bufio.Writer {
+	writer := writerPool.Get().(*bufio.Writer)
+	writer.Reset(w)
+	return writer
+}
+
+func putWriter(writer *bufio.Writer) {
+	writer.Reset(nil)
+	writerPool.Put(writer)
+}
+
+func getLineBuffer() *bytes.Buffer {
+	return lineBufferPool.Get().(*bytes.Buffer)
+}
+
+func putLineBuffer(buf *bytes.Buffer) {
+	// don't keep buffers that grew to hold a very long line
+	if buf.Cap() > redactBufferSize {
+		return
+	}
+	buf.Reset()
+	lineBufferPool.Put(buf)
+}
+
+// writeLine writes line followed by a newline
+func writeLine(w *bufio.Writer, line []byte) error {
+	if _, err := w.Write(line); err != nil {
+		return err
+	}
+	return w.WriteByte('\n')
+}
@@ -1726,7 +1726,7 @@ func Test_Redactors(t *testing.T) {
 
 	t.Run("test default redactors", func(t *testing.T) {
 		req := require.New(t)
-		redactors, err := getRedactors("testpath")
+		redactors, err := getRedactors("testpath", nil)
 		req.NoError(err)
 
 		nextReader := io.Reader(strings.NewReader(original))
@@ -1874,3 +1874,272 @@ func Test_redactMatchesPath(t *testing.T) {
 		})
 	}
 }
+
+func Test_RedactWithOptions_contentType(t *testing.T) {
+	redactors := []*troubleshootv1beta2.Redact{
+		{
+			Name: "log tokens",
+			FileSelector: troubleshootv1beta2.FileSelector{
+				ContentTypes: []string{"pod-log"},
+			},
+			Removals: troubleshootv1beta2.Removals{
+				Regex: []troubleshootv1beta2.Regex{
+					{Redactor: `(?i)(token=)(?P<mask>[^\s]+)`},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		want        string
+	}{
+		{
+			name:        "selected content type",
+			contentType: "pod-log",
+			want:        "token=" + MASK_TEXT,
+		},
+		{
+			name:        "other content type",
+			contentType: "k8s-json",
+			want:        "token=abc123",
+		},
+		{
+			name:        "untagged file",
+			contentType: "",
+			want:        "token=abc123",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+
+			redacted, err := RedactWithOptions(strings.NewReader("token=abc123\n"), "app/pod.log", tt.contentType, redactors, Options{})
+			req.NoError(err)
+
+			got, err := ioutil.ReadAll(redacted)
+			req.NoError(err)
+			req.Equal(tt.want, strings.TrimSpace(string(got)))
+		})
+	}
+
+	GetRedactionList()
+	ResetRedactionList()
+}
+
+func Test_RedactLongLines(t *testing.T) {
+	req := require.New(t)
+
+	// longer than the pooled reader and line buffers
+	longLine := strings.Repeat("a", 3*redactBufferSize) + " 10.0.0.1"
+	input := longLine + "\nshort line 192.168.0.1\n" + longLine + "\n"
+
+	for i := 0; i < 2; i++ {
+		redacted, err := Redact(strings.NewReader(input), "long", nil)
+		req.NoError(err)
+
+		got, err := ioutil.ReadAll(redacted)
+		req.NoError(err)
+
+		lines := strings.Split(strings.TrimSuffix(string(got), "\n"), "\n")
+		req.Len(lines, 3)
+		req.Equal(strings.Repeat("a", 3*redactBufferSize)+" "+MASK_TEXT, lines[0])
+		req.Equal("short line "+MASK_TEXT, lines[1])
+		req.Equal(lines[0], lines[2])
+	}
+
+	ResetRedactionList()
+}
+
+func Test_RedactIPv6(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "full",
+			input: `I1012 10:14:45.123456       1 server.go:42] listening on 2001:0db8:85a3:0000:0000:8a2e:0370:7334 port 6443`,
+			want:  `I1012 10:14:45.123456       1 server.go:42] listening on ***HIDDEN*** port 6443`,
+		},
+		{
+			name:  "compressed in json",
+			input: `{"podIPs":[{"ip":"10.32.0.5"},{"ip":"fd00:10:32::5"}],"hostIP":"2001:db8::1:2"}`,
+			want:  `{"podIPs":[{"ip":"***HIDDEN***"},{"ip":"***HIDDEN***"}],"hostIP":"***HIDDEN***"}`,
+		},
+		{
+			name:  "bracketed with port",
+			input: `dial tcp [fe80::a00:27ff:fe4e:66a1]:10250: connect: connection refused`,
+			want:  `dial tcp [***HIDDEN***]:10250: connect: connection refused`,
+		},
+		{
+			name:  "prefix and loopback",
+			input: `route fd00:10:96::/108 via ::1 dev lo`,
+			want:  `route ***HIDDEN***/108 via ***HIDDEN*** dev lo`,
+		},
+		{
+			name:  "mapped",
+			input: `remote_addr=::ffff:192.168.1.20 upstream=64:ff9b::10.0.0.1`,
+			want:  `remote_addr=***HIDDEN*** upstream=***HIDDEN***`,
+		},
+		{
+			name:  "comma separated",
+			input: `addresses: fd00::1,fd00::2`,
+			want:  `addresses: ***HIDDEN***,***HIDDEN***`,
+		},
+		{
+			name:  "not addresses",
+			input: `12:30:45 mac=00:1a:2b:3c:4d:5e std::deque<int> sha256:abcdef throw Foo::Bar`,
+			want:  `12:30:45 mac=00:1a:2b:3c:4d:5e std::deque<int> sha256:abcdef throw Foo::Bar`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+
+			redacted, err := Redact(strings.NewReader(tt.input+"\n"), "ipv6", nil)
+			req.NoError(err)
+
+			got, err := ioutil.ReadAll(redacted)
+			req.NoError(err)
+			req.Equal(tt.want, strings.Split(string(got), "\n")[0])
+		})
+	}
+
+	ResetRedactionList()
+}
+
+func Benchmark_Redact(b *testing.B) {
+	lines := []string{}
+	for i := 0; i < 10000; i++ {
+		lines = append(lines, `{"name":"PASSWORD","value":"hunter2"}, "host": "10.0.0.1", "message": "a log line without anything to redact"`)
+	}
+	input := strings.Join(lines, "\n")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		redacted, err := Redact(strings.NewReader(input), "bench", nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := io.Copy(ioutil.Discard, redacted); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	ResetRedactionList()
+}
+
+func Test_RedactMaskText(t *testing.T) {
+	tests := []struct {
+		name     string
+		removals troubleshootv1beta2.Removals
+		input    string
+		want     string
+	}{
+		{
+			name:     "literal",
+			removals: troubleshootv1beta2.Removals{Values: []string{"abc123"}},
+			input:    "key abc123",
+			want:     "key <$1 removed>",
+		},
+		{
+			name: "regex",
+			removals: troubleshootv1beta2.Removals{
+				Regex: []troubleshootv1beta2.Regex{{Redactor: `(?i)(token=)(?P<mask>[^\s]+)`}},
+			},
+			input: "token=xyz",
+			want:  "token=<$1 removed>",
+		},
+		{
+			name:     "yaml",
+			removals: troubleshootv1beta2.Removals{YamlPath: []string{"password"}},
+			input:    "password: hunter2\n",
+			want:     "password: <$1 removed>",
+		},
+		{
+			name:     "json",
+			removals: troubleshootv1beta2.Removals{JSONPath: []string{"secret"}},
+			input:    `{"secret":"hunter2"}`,
+			want:     "{\n  \"secret\": \"<$1 removed>\"\n}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+
+			redacts := []*troubleshootv1beta2.Redact{
+				{
+					Name:     tt.name,
+					MaskText: "<$1 removed>",
+					Removals: tt.removals,
+				},
+			}
+
+			redacted, err := Redact(strings.NewReader(tt.input), "mask-text", redacts)
+			req.NoError(err)
+
+			got, err := ioutil.ReadAll(redacted)
+			req.NoError(err)
+			req.Equal(tt.want, strings.TrimSpace(string(got)))
+		})
+	}
+
+	GetRedactionList()
+	ResetRedactionList()
+}
+
+func Test_RedactWithOptions_excludeDefaultRedactors(t *testing.T) {
+	input := `kubelet version 1.24.8.1 is running at 10.32.0.5 with Server=db.internal;Pwd=hunter2;`
+
+	tests := []struct {
+		name     string
+		excluded []string
+		want     string
+	}{
+		{
+			name: "none excluded",
+			want: `kubelet version ***HIDDEN*** is running at ***HIDDEN*** with Server=***HIDDEN***;Pwd=***HIDDEN***;`,
+		},
+		{
+			name:     "ipv4",
+			excluded: []string{"ipv4"},
+			want:     `kubelet version 1.24.8.1 is running at 10.32.0.5 with Server=***HIDDEN***;Pwd=***HIDDEN***;`,
+		},
+		{
+			name:     "ipv4 and connection strings",
+			excluded: []string{"ipv4", "connection-strings"},
+			want:     input,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+
+			redacted, err := RedactWithOptions(strings.NewReader(input), "node.log", "", nil, Options{ExcludeDefaultRedactors: tt.excluded})
+			req.NoError(err)
+
+			got, err := ioutil.ReadAll(redacted)
+			req.NoError(err)
+			req.Equal(tt.want, strings.TrimSpace(string(got)))
+		})
+	}
+
+	// the default redactors of other bundles are not affected
+	redacted, err := Redact(strings.NewReader(input), "node.log", nil)
+	require.NoError(t, err)
+	got, err := ioutil.ReadAll(redacted)
+	require.NoError(t, err)
+	require.Equal(t, tests[0].want, strings.TrimSpace(string(got)))
+
+	_, err = RedactWithOptions(strings.NewReader(input), "node.log", "", nil, Options{ExcludeDefaultRedactors: []string{"ipv5"}})
+	require.EqualError(t, err, `unknown default redactor group "ipv5", must be one of aws-keys, ceph-keys, connection-strings, database, ipv4, ipv6, kurl-tokens, passwords, tokens, usernames`)
+
+	GetRedactionList()
+	ResetRedactionList()
+}
@@ -0,0 +1,291 @@
+package redact
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/gobwas/glob"
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+)
+
+// redactorTemplate returns a redactor for the file at path. Templates are built once for the default
+// redactors and once for each spec of additional redactors, so their regexes and globs are not built
+// again for every file of a bundle. The redactors of a template are not shared between files, as they
+// record the file they redacted and yaml and json redactors keep state while redacting.
+type redactorTemplate func(path string) Redactor
+
+// redactTemplate is a redact of a spec, with the globs of the files it applies to
+type redactTemplate struct {
+	redact    *troubleshootv1beta2.Redact
+	globs     []glob.Glob
+	globErr   error
+	err       error // returned for the files the redact applies to, if its redactors could not be built
+	templates []redactorTemplate
+}
+
+// maxCachedRedactTemplates is how many specs of additional redactors have their templates cached
+const maxCachedRedactTemplates = 8
+
+// redactTemplates holds the templates of the specs of additional redactors that are in use, by the hash
+// of the spec, so that they are built once for the files of a bundle. Templates are immutable once they
+// are stored. They have the values that are redacted, so only the most recently used specs are kept, and
+// a spec's templates are dropped with ReleaseRedactors once its bundle has been redacted.
+var redactTemplates struct {
+	sync.Mutex
+	byKey map[string]*list.Element
+	// lru has the cachedRedactTemplates, the most recently used first
+	lru *list.List
+}
+
+type cachedRedactTemplates struct {
+	key       string
+	templates []redactTemplate
+}
+
+func getRedactTemplates(redacts []*troubleshootv1beta2.Redact) ([]redactTemplate, error) {
+	if len(redacts) == 0 {
+		return nil, nil
+	}
+
+	key, err := redactTemplatesKey(redacts)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached, ok := loadRedactTemplates(key, nil); ok {
+		return cached, nil
+	}
+
+	templates := []redactTemplate{}
+	for i, redact := range redacts {
+		if redact == nil {
+			continue
+		}
+		templates = append(templates, buildRedactTemplate(i, redact))
+	}
+
+	actual, _ := loadRedactTemplates(key, templates)
+	return actual, nil
+}
+
+// ReleaseRedactors drops the redactors that were built for redacts, so that the values they redact are not
+// kept in memory once a bundle has been redacted. They are built again if they are used after this.
+func ReleaseRedactors(redacts []*troubleshootv1beta2.Redact) {
+	if len(redacts) == 0 {
+		return
+	}
+
+	key, err := redactTemplatesKey(redacts)
+	if err != nil {
+		return
+	}
+
+	redactTemplates.Lock()
+	defer redactTemplates.Unlock()
+
+	if e, ok := redactTemplates.byKey[key]; ok {
+		redactTemplates.lru.Remove(e)
+		delete(redactTemplates.byKey, key)
+	}
+}
+
+func redactTemplatesKey(redacts []*troubleshootv1beta2.Redact) (string, error) {
+	b, err := json.Marshal(redacts)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to hash redactors")
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// loadRedactTemplates returns the cached templates of key. If there are none and templates is not nil,
+// templates are cached and returned, and the least recently used are dropped if there are too many.
+func loadRedactTemplates(key string, templates []redactTemplate) ([]redactTemplate, bool) {
+	redactTemplates.Lock()
+	defer redactTemplates.Unlock()
+
+	if redactTemplates.byKey == nil {
+		redactTemplates.byKey = map[string]*list.Element{}
+		redactTemplates.lru = list.New()
+	}
+
+	if e, ok := redactTemplates.byKey[key]; ok {
+		redactTemplates.lru.MoveToFront(e)
+		return e.Value.(*cachedRedactTemplates).templates, true
+	}
+	if templates == nil {
+		return nil, false
+	}
+
+	redactTemplates.byKey[key] = redactTemplates.lru.PushFront(&cachedRedactTemplates{key: key, templates: templates})
+	for redactTemplates.lru.Len() > maxCachedRedactTemplates {
+		oldest := redactTemplates.lru.Back()
+		redactTemplates.lru.Remove(oldest)
+		delete(redactTemplates.byKey, oldest.Value.(*cachedRedactTemplates).key)
+	}
+	return templates, false
+}
+
+func buildRedactTemplate(i int, redact *troubleshootv1beta2.Redact) redactTemplate {
+	// copied so that the redact is not changed by the caller once it's stored
+	t := redactTemplate{redact: redact.DeepCopy()}
+	t.globs, t.globErr = compileFileSelector(redact)
+	t.templates, t.err = buildRedactorTemplates(i, redact)
+	return t
+}
+
+func buildRedactorTemplates(i int, redact *troubleshootv1beta2.Redact) ([]redactorTemplate, error) {
+	// the values would not be removed, so fail rather than collect them
+	if len(redact.Removals.ValuesFrom) > 0 {
+		return nil, errors.Errorf("redactor %s has valuesFrom that were not resolved", redactorName(i, 0, redact.Name, "literal"))
+	}
+
+	maskText := redact.MaskText
+	if maskText == "" {
+		maskText = MASK_TEXT
+	}
+
+	templates := []redactorTemplate{}
+	if redact.Tokenize {
+		tokenTemplates, err := buildTokenTemplates(i, redact)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, tokenTemplates...)
+	} else {
+		for j, literal := range redact.Removals.Values {
+			templates = append(templates, literalTemplate(literalRedactor{
+				matchString: literal,
+				maskText:    maskText,
+				redactName:  redactorName(i, j, redact.Name, "literal"),
+			}))
+		}
+
+		for j, re := range redact.Removals.Regex {
+			if re.Selector != "" {
+				r, err := NewMultiLineRedactor(re.Selector, re.Redactor, maskText, "", redactorName(i, j, redact.Name, "multiLine"), false)
+				if err != nil {
+					return nil, errors.Wrapf(err, "multiline redactor %+v", re)
+				}
+				templates = append(templates, multiLineTemplate(r))
+			} else {
+				r, err := NewSingleLineRedactor(re.Redactor, maskText, "", redactorName(i, j, redact.Name, "regex"), false)
+				if err != nil {
+					return nil, errors.Wrapf(err, "redactor %q", re)
+				}
+				templates = append(templates, singleLineTemplate(r))
+			}
+		}
+	}
+
+	for j, yaml := range redact.Removals.YamlPath {
+		r := NewYamlRedactor(yaml, "", redactorName(i, j, redact.Name, "yaml"))
+		r.maskText = maskText
+		r.tokenize = redact.Tokenize
+		templates = append(templates, yamlTemplate(r))
+	}
+
+	for j, jsonPath := range redact.Removals.JSONPath {
+		r := NewJSONRedactor(jsonPath, "", redactorName(i, j, redact.Name, "json"))
+		r.maskText = maskText
+		r.tokenize = redact.Tokenize
+		templates = append(templates, jsonTemplate(r))
+	}
+
+	return templates, nil
+}
+
+// buildTokenTemplates returns templates for the values and regexes of a redact that tokenizes values
+func buildTokenTemplates(i int, redact *troubleshootv1beta2.Redact) ([]redactorTemplate, error) {
+	templates := []redactorTemplate{}
+
+	for j, literal := range redact.Removals.Values {
+		r, err := literalTokenRedactor(literal, "", redactorName(i, j, redact.Name, "literal"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "literal redactor %q", literal)
+		}
+		templates = append(templates, tokenTemplate(r))
+	}
+
+	for j, re := range redact.Removals.Regex {
+		kind := "regex"
+		if re.Selector != "" {
+			kind = "multiLine"
+		}
+		r, err := NewTokenRedactor(re.Selector, re.Redactor, "", redactorName(i, j, redact.Name, kind))
+		if err != nil {
+			return nil, errors.Wrapf(err, "redactor %+v", re)
+		}
+		templates = append(templates, tokenTemplate(r))
+	}
+
+	return templates, nil
+}
+
+func bindRedactorTemplates(templates []redactorTemplate, path string) []Redactor {
+	redactors := make([]Redactor, 0, len(templates))
+	for _, t := range templates {
+		redactors = append(redactors, t(path))
+	}
+	return redactors
+}
+
+func singleLineTemplate(r *SingleLineRedactor) redactorTemplate {
+	return func(path string) Redactor {
+		bound := *r
+		bound.filePath = path
+		return &bound
+	}
+}
+
+func multiLineTemplate(r *MultiLineRedactor) redactorTemplate {
+	return func(path string) Redactor {
+		bound := *r
+		bound.filePath = path
+		return &bound
+	}
+}
+
+func tokenTemplate(r *TokenRedactor) redactorTemplate {
+	return func(path string) Redactor {
+		bound := *r
+		bound.filePath = path
+		return &bound
+	}
+}
+
+func literalTemplate(r literalRedactor) redactorTemplate {
+	return func(path string) Redactor {
+		bound := r
+		bound.filePath = path
+		return bound
+	}
+}
+
+func yamlTemplate(r *YamlRedactor) redactorTemplate {
+	return func(path string) Redactor {
+		bound := *r
+		bound.filePath = path
+		return &bound
+	}
+}
+
+// fileGlobYamlTemplate is a template for a yaml redactor whose filePath is a glob of the files it redacts
+func fileGlobYamlTemplate(r *YamlRedactor) redactorTemplate {
+	return func(path string) Redactor {
+		bound := *r
+		return &bound
+	}
+}
+
+func jsonTemplate(r *JSONRedactor) redactorTemplate {
+	return func(path string) Redactor {
+		bound := *r
+		bound.filePath = path
+		return &bound
+	}
+}
@@ -0,0 +1,162 @@
+package redact
+
+import (
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_getRedactTemplates(t *testing.T) {
+	redacts := []*troubleshootv1beta2.Redact{
+		{
+			Name:         "app secrets",
+			FileSelector: troubleshootv1beta2.FileSelector{Files: []string{"app/*"}},
+			Removals: troubleshootv1beta2.Removals{
+				Values: []string{"hunter2"},
+				Regex:  []troubleshootv1beta2.Regex{{Redactor: `(token=)(?P<mask>\w+)`}},
+			},
+		},
+		nil,
+		{
+			Name:     "bad regex",
+			Removals: troubleshootv1beta2.Removals{Regex: []troubleshootv1beta2.Regex{{Redactor: `(?P<mask>`}}},
+		},
+	}
+
+	first, err := getRedactTemplates(redacts)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	assert.Len(t, first[0].templates, 2)
+	assert.NoError(t, first[0].err)
+	assert.Error(t, first[1].err)
+
+	// an equal spec gets the templates that were already built
+	copied := []*troubleshootv1beta2.Redact{redacts[0].DeepCopy(), nil, redacts[2].DeepCopy()}
+	second, err := getRedactTemplates(copied)
+	require.NoError(t, err)
+	assert.Same(t, &first[0], &second[0])
+
+	// and a changed spec gets templates of its own
+	copied[0].Removals.Values = []string{"hunter3"}
+	third, err := getRedactTemplates(copied)
+	require.NoError(t, err)
+	assert.NotSame(t, &first[0], &third[0])
+
+	// each file is redacted by redactors of its own
+	a := bindRedactorTemplates(first[0].templates, "app/a.log")
+	b := bindRedactorTemplates(first[0].templates, "app/b.log")
+	assert.Equal(t, "app/a.log", a[1].(*SingleLineRedactor).filePath)
+	assert.Equal(t, "app/b.log", b[1].(*SingleLineRedactor).filePath)
+	assert.Same(t, a[1].(*SingleLineRedactor).re, b[1].(*SingleLineRedactor).re)
+}
+
+func Test_ReleaseRedactors(t *testing.T) {
+	redacts := []*troubleshootv1beta2.Redact{
+		{Name: "release", Removals: troubleshootv1beta2.Removals{Values: []string{"hunter2"}}},
+	}
+
+	first, err := getRedactTemplates(redacts)
+	require.NoError(t, err)
+
+	// released templates are not kept, and are built again when they are used
+	ReleaseRedactors(redacts)
+	key, err := redactTemplatesKey(redacts)
+	require.NoError(t, err)
+	_, ok := loadRedactTemplates(key, nil)
+	assert.False(t, ok)
+
+	second, err := getRedactTemplates(redacts)
+	require.NoError(t, err)
+	assert.NotSame(t, &first[0], &second[0])
+	ReleaseRedactors(redacts)
+}
+
+func Test_getRedactTemplates_bounded(t *testing.T) {
+	specs := [][]*troubleshootv1beta2.Redact{}
+	for i := 0; i <= maxCachedRedactTemplates; i++ {
+		redacts := []*troubleshootv1beta2.Redact{
+			{Name: "bounded", Removals: troubleshootv1beta2.Removals{Values: []string{strings.Repeat("x", i+1)}}},
+		}
+		_, err := getRedactTemplates(redacts)
+		require.NoError(t, err)
+		specs = append(specs, redacts)
+	}
+
+	// the least recently used spec is dropped once there are too many
+	key, err := redactTemplatesKey(specs[0])
+	require.NoError(t, err)
+	_, ok := loadRedactTemplates(key, nil)
+	assert.False(t, ok)
+
+	key, err = redactTemplatesKey(specs[maxCachedRedactTemplates])
+	require.NoError(t, err)
+	_, ok = loadRedactTemplates(key, nil)
+	assert.True(t, ok)
+
+	for _, redacts := range specs {
+		ReleaseRedactors(redacts)
+	}
+}
+
+func Test_buildAdditionalRedactorsConcurrently(t *testing.T) {
+	redacts := []*troubleshootv1beta2.Redact{
+		{
+			Name:     "tokens",
+			Removals: troubleshootv1beta2.Removals{Regex: []troubleshootv1beta2.Regex{{Redactor: `(token=)(?P<mask>\w+)`}}},
+		},
+		{
+			Name:         "selected",
+			FileSelector: troubleshootv1beta2.FileSelector{File: "app/*.log"},
+			Removals:     troubleshootv1beta2.Removals{Values: []string{"hunter2"}},
+		},
+	}
+
+	var wg sync.WaitGroup
+	for _, path := range []string{"app/a.log", "app/b.log", "other/c.log", "app/d.log"} {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+
+			redacted, err := Redact(strings.NewReader("token=abc123 password hunter2\n"), path, redacts)
+			require.NoError(t, err)
+			got, err := ioutil.ReadAll(redacted)
+			require.NoError(t, err)
+
+			if strings.HasPrefix(path, "app/") {
+				assert.Equal(t, "token=***HIDDEN*** password ***HIDDEN***", strings.TrimSpace(string(got)))
+			} else {
+				assert.Equal(t, "token=***HIDDEN*** password hunter2", strings.TrimSpace(string(got)))
+			}
+		}(path)
+	}
+	wg.Wait()
+
+	redactions := GetRedactionList()
+	ResetRedactionList()
+	assert.Len(t, redactions.ByFile["app/a.log"], 2)
+	assert.Len(t, redactions.ByFile["other/c.log"], 1)
+}
+
+func Benchmark_buildAdditionalRedactors(b *testing.B) {
+	redacts := []*troubleshootv1beta2.Redact{
+		{
+			Name:         "app secrets",
+			FileSelector: troubleshootv1beta2.FileSelector{Files: []string{"app/**", "cluster-resources/pods/logs/**"}},
+			Removals: troubleshootv1beta2.Removals{
+				Values:   []string{"hunter2"},
+				Regex:    []troubleshootv1beta2.Regex{{Redactor: `(token=)(?P<mask>\w+)`}},
+				JSONPath: []string{"items.*.spec.containers.*.env.*.value"},
+			},
+		},
+	}
+	for i := 0; i < b.N; i++ {
+		if _, err := buildAdditionalRedactors("app/pod.log", "", redacts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package redact
+
+import (
+	"bytes"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+)
+
+// Sample is a file to redact as if it were the file at Path in a support bundle, so that redactors
+// with file selectors apply to it
+type Sample struct {
+	Path  string
+	Input []byte
+}
+
+// SampleRedaction is a sample before and after it was redacted
+type SampleRedaction struct {
+	Path   string
+	Before []byte
+	After  []byte
+}
+
+// SampleResult is what the redactors of a spec did to samples
+type SampleResult struct {
+	Samples    []SampleRedaction
+	Redactions RedactionList
+	// Unmatched are the additional redactors that did not redact anything in any of the samples
+	Unmatched []string
+}
+
+// RedactSamples runs the default redactors that opts do not exclude and the additional redactors against
+// samples, so that redactors can be checked against example input without collecting a bundle.
+// Redactions are recorded for the whole process, so the redaction list is reset.
+func RedactSamples(samples []Sample, additionalRedactors []*troubleshootv1beta2.Redact, opts Options) (*SampleResult, error) {
+	if err := ValidateDefaultRedactorGroups(opts.ExcludeDefaultRedactors); err != nil {
+		return nil, err
+	}
+	defer ReleaseRedactors(additionalRedactors)
+
+	paths := map[string]bool{}
+	for _, sample := range samples {
+		if paths[sample.Path] {
+			return nil, errors.Errorf("more than one sample is redacted as %s", sample.Path)
+		}
+		paths[sample.Path] = true
+	}
+
+	ResetRedactionList()
+
+	result := &SampleResult{}
+	for _, sample := range samples {
+		redacted, err := RedactWithOptions(bytes.NewReader(sample.Input), sample.Path, "", additionalRedactors, opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to redact %s", sample.Path)
+		}
+		after, err := io.ReadAll(redacted)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to redact %s", sample.Path)
+		}
+
+		// redactors add newlines to the end of what they write, which are not a change to show
+		trailing := len(sample.Input) - len(bytes.TrimRight(sample.Input, "\n"))
+		after = append(bytes.TrimRight(after, "\n"), bytes.Repeat([]byte("\n"), trailing)...)
+
+		result.Samples = append(result.Samples, SampleRedaction{
+			Path:   sample.Path,
+			Before: sample.Input,
+			After:  after,
+		})
+	}
+
+	result.Redactions = GetRedactionList()
+	result.Redactions.Sort()
+
+	for _, name := range additionalRedactorNames(additionalRedactors) {
+		if len(result.Redactions.ByRedactor[name]) == 0 {
+			result.Unmatched = append(result.Unmatched, name)
+		}
+	}
+	sort.Strings(result.Unmatched)
+
+	return result, nil
+}
+
+// Diff is a unified diff of the sample before and after it was redacted, empty if nothing was redacted
+func (s SampleRedaction) Diff() (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(s.Before)),
+		B:        difflib.SplitLines(string(s.After)),
+		FromFile: "before/" + s.Path,
+		ToFile:   "after/" + s.Path,
+		Context:  1,
+	})
+}
+
+// additionalRedactorNames returns the names that the redactions of each of the redactors of redacts are
+// recorded with
+func additionalRedactorNames(redacts []*troubleshootv1beta2.Redact) []string {
+	names := []string{}
+	for i, redact := range redacts {
+		if redact == nil {
+			continue
+		}
+		for j := range redact.Removals.Values {
+			names = append(names, redactorName(i, j, redact.Name, "literal"))
+		}
+		for j, re := range redact.Removals.Regex {
+			kind := "regex"
+			if re.Selector != "" {
+				kind = "multiLine"
+			}
+			names = append(names, redactorName(i, j, redact.Name, kind))
+		}
+		for j := range redact.Removals.YamlPath {
+			names = append(names, redactorName(i, j, redact.Name, "yaml"))
+		}
+		for j := range redact.Removals.JSONPath {
+			names = append(names, redactorName(i, j, redact.Name, "json"))
+		}
+	}
+	return names
+}
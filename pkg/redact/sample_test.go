@@ -0,0 +1,62 @@
+package redact
+
+import (
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RedactSamples(t *testing.T) {
+	redacts := []*troubleshootv1beta2.Redact{
+		{
+			Name: "app",
+			FileSelector: troubleshootv1beta2.FileSelector{
+				File: "app/*",
+			},
+			Removals: troubleshootv1beta2.Removals{
+				Values: []string{"hunter2"},
+				Regex: []troubleshootv1beta2.Regex{
+					{Redactor: `(api_key=)(?P<mask>\w+)`},
+					{Redactor: `(license=)(?P<mask>\w+)`},
+				},
+			},
+		},
+	}
+
+	result, err := RedactSamples([]Sample{
+		{Path: "app/api.log", Input: []byte("starting\nlogin with password hunter2\napi_key=abc123 ok\nready")},
+		{Path: "other/worker.log", Input: []byte("password hunter2\n")},
+	}, redacts, Options{})
+	require.NoError(t, err)
+	require.Len(t, result.Samples, 2)
+
+	assert.Equal(t, "starting\nlogin with password ***HIDDEN***\napi_key=***HIDDEN*** ok\nready", string(result.Samples[0].After))
+	assert.Equal(t, "password hunter2\n", string(result.Samples[1].After))
+	assert.Len(t, result.Redactions.ByFile["app/api.log"], 2)
+	assert.Len(t, result.Redactions.ByRedactor["app.literal.0"], 1)
+	assert.Equal(t, []string{"app.regex.1"}, result.Unmatched)
+
+	diff, err := result.Samples[0].Diff()
+	require.NoError(t, err)
+	assert.Equal(t, `--- before/app/api.log
++++ after/app/api.log
+@@ -1,4 +1,4 @@
+ starting
+-login with password hunter2
+-api_key=abc123 ok
++login with password ***HIDDEN***
++api_key=***HIDDEN*** ok
+ ready
+`, diff)
+
+	diff, err = result.Samples[1].Diff()
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+
+	_, err = RedactSamples([]Sample{{Path: "a.log"}, {Path: "a.log"}}, nil, Options{})
+	assert.EqualError(t, err, "more than one sample is redacted as a.log")
+
+	ResetRedactionList()
+}
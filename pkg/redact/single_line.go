@@ -1,14 +1,14 @@
 package redact
 
 import (
-	"bufio"
-	"fmt"
+	"bytes"
 	"io"
 	"regexp"
 )
 
 type SingleLineRedactor struct {
 	re         *regexp.Regexp
+	substStr   []byte
 	maskText   string
 	filePath   string
 	redactName string
@@ -16,11 +16,11 @@ type SingleLineRedactor struct {
 }
 
 func NewSingleLineRedactor(re, maskText, path, name string, isDefault bool) (*SingleLineRedactor, error) {
-	compiled, err := regexp.Compile(re)
+	compiled, err := compilePattern(re, maskText, isDefault)
 	if err != nil {
 		return nil, err
 	}
-	return &SingleLineRedactor{re: compiled, maskText: maskText, filePath: path, redactName: name, isDefault: isDefault}, nil
+	return &SingleLineRedactor{re: compiled.re, substStr: []byte(compiled.replacement), maskText: maskText, filePath: path, redactName: name, isDefault: isDefault}, nil
 }
 
 func (r *SingleLineRedactor) Redact(input io.Reader, path string) io.Reader {
@@ -28,7 +28,14 @@ func (r *SingleLineRedactor) Redact(input io.Reader, path string) io.Reader {
 
 	go func() {
 		var err error
+		reader := getReader(input)
+		bufWriter := getWriter(writer)
+		lineBuf := getLineBuffer()
 		defer func() {
+			bufWriter.Flush()
+			putLineBuffer(lineBuf)
+			putWriter(bufWriter)
+			putReader(reader)
 			if err == io.EOF {
 				writer.Close()
 			} else {
@@ -36,33 +43,25 @@ func (r *SingleLineRedactor) Redact(input io.Reader, path string) io.Reader {
 			}
 		}()
 
-		substStr := getReplacementPattern(r.re, r.maskText)
-
-		reader := bufio.NewReader(input)
 		lineNum := 0
 		for {
 			lineNum++
-			var line string
-			line, err = readLine(reader)
+			var line []byte
+			line, err = readLine(reader, lineBuf)
 			if err != nil {
 				return
 			}
 
-			if !r.re.MatchString(line) {
-				fmt.Fprintf(writer, "%s\n", line)
+			if !r.re.Match(line) {
+				writeLine(bufWriter, line)
 				continue
 			}
 
-			clean := r.re.ReplaceAllString(line, substStr)
-
-			// io.WriteString would be nicer, but scanner strips new lines
-			fmt.Fprintf(writer, "%s\n", clean)
-			if err != nil {
-				return
-			}
+			clean := r.re.ReplaceAll(line, r.substStr)
+			writeLine(bufWriter, clean)
 
 			// if clean is not equal to line, a redaction was performed
-			if clean != line {
+			if !bytes.Equal(clean, line) {
 				addRedaction(Redaction{
 					RedactorName:      r.redactName,
 					CharactersRemoved: len(line) - len(clean),
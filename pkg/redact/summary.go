@@ -0,0 +1,73 @@
+package redact
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+// Summary is a human readable report of the redactions, with the number of redactions made by each
+// redactor and in each file
+func (r RedactionList) Summary() string {
+	total := 0
+	for _, redactions := range r.ByFile {
+		total += len(redactions)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%d redactions in %d files\n", total, len(r.ByFile))
+	if total == 0 {
+		return buf.String()
+	}
+
+	fmt.Fprintf(&buf, "\nBy redactor:\n")
+	writeRedactionCounts(&buf, r.ByRedactor)
+	fmt.Fprintf(&buf, "\nBy file:\n")
+	writeRedactionCounts(&buf, r.ByFile)
+
+	return buf.String()
+}
+
+// Details is a human readable report of every redaction, by file and line. Redactions of yamlPath and
+// jsonPath redactors have no line.
+func (r RedactionList) Details() string {
+	files := make([]string, 0, len(r.ByFile))
+	for file := range r.ByFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	for _, file := range files {
+		redactions := append([]Redaction{}, r.ByFile[file]...)
+		sortRedactions(redactions)
+
+		for _, redaction := range redactions {
+			line := "-"
+			if redaction.Line > 0 {
+				line = strconv.Itoa(redaction.Line)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", file, line, redaction.RedactorName)
+		}
+	}
+	w.Flush()
+
+	return buf.String()
+}
+
+func writeRedactionCounts(buf *bytes.Buffer, redactions map[string][]Redaction) {
+	names := make([]string, 0, len(redactions))
+	for name := range redactions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\t%d\n", name, len(redactions[name]))
+	}
+	w.Flush()
+}
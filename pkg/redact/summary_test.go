@@ -0,0 +1,74 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactionList_Summary(t *testing.T) {
+	list := RedactionList{
+		ByRedactor: map[string][]Redaction{
+			"Redact ipv4 addresses": {
+				{RedactorName: "Redact ipv4 addresses", File: "cluster-resources/pods/default.json", Line: 3},
+				{RedactorName: "Redact ipv4 addresses", File: "cluster-info/cluster_version.json", Line: 1},
+			},
+			"app tokens": {
+				{RedactorName: "app tokens", File: "cluster-resources/pods/default.json", Line: 8},
+			},
+		},
+		ByFile: map[string][]Redaction{
+			"cluster-resources/pods/default.json": {
+				{RedactorName: "Redact ipv4 addresses", File: "cluster-resources/pods/default.json", Line: 3},
+				{RedactorName: "app tokens", File: "cluster-resources/pods/default.json", Line: 8},
+			},
+			"cluster-info/cluster_version.json": {
+				{RedactorName: "Redact ipv4 addresses", File: "cluster-info/cluster_version.json", Line: 1},
+			},
+		},
+	}
+
+	assert.Equal(t, `3 redactions in 2 files
+
+By redactor:
+  Redact ipv4 addresses  2
+  app tokens             1
+
+By file:
+  cluster-info/cluster_version.json    1
+  cluster-resources/pods/default.json  2
+`, list.Summary())
+
+	assert.Equal(t, "0 redactions in 0 files\n", RedactionList{}.Summary())
+}
+
+func TestRedactionList_Sort(t *testing.T) {
+	list := RedactionList{
+		ByRedactor: map[string][]Redaction{
+			"Redact ipv4 addresses": {
+				{RedactorName: "Redact ipv4 addresses", File: "b.json", Line: 1},
+				{RedactorName: "Redact ipv4 addresses", File: "a.json", Line: 7},
+				{RedactorName: "Redact ipv4 addresses", File: "a.json", Line: 2},
+			},
+		},
+		ByFile: map[string][]Redaction{
+			"a.json": {
+				{RedactorName: "Redact ipv4 addresses", File: "a.json", Line: 7},
+				{RedactorName: "app tokens", File: "a.json", Line: 2},
+				{RedactorName: "Redact ipv4 addresses", File: "a.json", Line: 2},
+			},
+		},
+	}
+
+	list.Sort()
+	assert.Equal(t, []Redaction{
+		{RedactorName: "Redact ipv4 addresses", File: "a.json", Line: 2},
+		{RedactorName: "Redact ipv4 addresses", File: "a.json", Line: 7},
+		{RedactorName: "Redact ipv4 addresses", File: "b.json", Line: 1},
+	}, list.ByRedactor["Redact ipv4 addresses"])
+	assert.Equal(t, []Redaction{
+		{RedactorName: "Redact ipv4 addresses", File: "a.json", Line: 2},
+		{RedactorName: "app tokens", File: "a.json", Line: 2},
+		{RedactorName: "Redact ipv4 addresses", File: "a.json", Line: 7},
+	}, list.ByFile["a.json"])
+}
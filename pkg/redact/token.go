@@ -0,0 +1,170 @@
+package redact
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+)
+
+// tokenLength is the number of hex characters of the HMAC kept in a token
+const tokenLength = 12
+
+var tokenKey []byte
+var tokenKeyOnce sync.Once
+
+// tokenize returns a token for value, e.g. ***HIDDEN-ab12f3c4d5e6***. The HMAC key is random and kept
+// for the life of the process, so the same value always gets the same token within a bundle but tokens
+// can't be compared across bundles collected by different processes.
+func tokenize(value []byte) string {
+	tokenKeyOnce.Do(func() {
+		tokenKey = make([]byte, 32)
+		if _, err := rand.Read(tokenKey); err != nil {
+			panic(fmt.Sprintf("failed to generate redaction token key: %v", err))
+		}
+	})
+
+	mac := hmac.New(sha256.New, tokenKey)
+	mac.Write(value)
+	return fmt.Sprintf("***HIDDEN-%s***", hex.EncodeToString(mac.Sum(nil))[:tokenLength])
+}
+
+// tokenizeValue returns a token for a value decoded from yaml or json
+func tokenizeValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return tokenize([]byte(s))
+	}
+	if b, err := json.Marshal(value); err == nil {
+		return tokenize(b)
+	}
+	return tokenize([]byte(fmt.Sprintf("%v", value)))
+}
+
+// TokenRedactor replaces values with a token derived from the value instead of a mask, so the same secret
+// can be correlated across files without being revealed. Matches of re are replaced the way they are by
+// SingleLineRedactor, with the mask group replaced by its token. If selector is set, only lines following
+// a line that matches selector are redacted, like MultiLineRedactor.
+type TokenRedactor struct {
+	selector   *regexp.Regexp
+	re         *regexp.Regexp
+	filePath   string
+	redactName string
+	isDefault  bool
+}
+
+func NewTokenRedactor(selector, re, path, name string) (*TokenRedactor, error) {
+	r := &TokenRedactor{filePath: path, redactName: name}
+
+	compiled, err := compilePattern(re, MASK_TEXT, false)
+	if err != nil {
+		return nil, err
+	}
+	r.re = compiled.re
+
+	if selector != "" {
+		compiled, err := compilePattern(selector, MASK_TEXT, false)
+		if err != nil {
+			return nil, err
+		}
+		r.selector = compiled.re
+	}
+
+	return r, nil
+}
+
+func literalTokenRedactor(matchString, path, name string) (*TokenRedactor, error) {
+	return NewTokenRedactor("", fmt.Sprintf("(?P<mask>%s)", regexp.QuoteMeta(matchString)), path, name)
+}
+
+func (r *TokenRedactor) Redact(input io.Reader, path string) io.Reader {
+	out, writer := io.Pipe()
+
+	go func() {
+		var err error
+		reader := getReader(input)
+		bufWriter := getWriter(writer)
+		lineBuf := getLineBuffer()
+		defer func() {
+			bufWriter.Flush()
+			putLineBuffer(lineBuf)
+			putWriter(bufWriter)
+			putReader(reader)
+			if err == io.EOF {
+				writer.Close()
+			} else {
+				writer.CloseWithError(err)
+			}
+		}()
+
+		selected := r.selector == nil
+		lineNum := 0
+		for {
+			lineNum++
+			var line []byte
+			line, err = readLine(reader, lineBuf)
+			if err != nil {
+				return
+			}
+
+			if !selected {
+				selected = r.selector.Match(line)
+				writeLine(bufWriter, line)
+				continue
+			}
+			// a redacted line can't select the line after it
+			selected = r.selector == nil
+
+			if !r.re.Match(line) {
+				writeLine(bufWriter, line)
+				continue
+			}
+
+			clean := r.tokenizeLine(line)
+			writeLine(bufWriter, clean)
+
+			if !bytes.Equal(clean, line) {
+				addRedaction(Redaction{
+					RedactorName:      r.redactName,
+					CharactersRemoved: len(line) - len(clean),
+					Line:              lineNum,
+					File:              r.filePath,
+					IsDefaultRedactor: r.isDefault,
+				})
+			}
+		}
+	}()
+	return out
+}
+
+// tokenizeLine replaces each match with its groups, as getReplacementPattern does, tokenizing the mask group
+func (r *TokenRedactor) tokenizeLine(line []byte) []byte {
+	names := r.re.SubexpNames()
+
+	var clean []byte
+	last := 0
+	for _, loc := range r.re.FindAllSubmatchIndex(line, -1) {
+		clean = append(clean, line[last:loc[0]]...)
+		for i := 1; i < len(names); i++ {
+			start, end := loc[2*i], loc[2*i+1]
+			if start < 0 {
+				continue
+			}
+			switch names[i] {
+			case "mask":
+				clean = append(clean, tokenize(line[start:end])...)
+			case "drop":
+				// no-op, string is just dropped from result
+			default:
+				clean = append(clean, line[start:end]...)
+			}
+		}
+		last = loc[1]
+	}
+	return append(clean, line[last:]...)
+}
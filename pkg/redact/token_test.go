@@ -0,0 +1,109 @@
+package redact
+
+import (
+	"bytes"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_tokenize(t *testing.T) {
+	req := require.New(t)
+
+	token := tokenize([]byte("hunter2"))
+	req.Regexp(regexp.MustCompile(`^\*\*\*HIDDEN-[0-9a-f]{12}\*\*\*$`), token)
+	req.Equal(token, tokenize([]byte("hunter2")))
+	req.NotEqual(token, tokenize([]byte("hunter3")))
+	req.Equal(token, tokenizeValue("hunter2"))
+}
+
+func TestNewTokenRedactor(t *testing.T) {
+	secret := tokenize([]byte("abcdef"))
+
+	tests := []struct {
+		name        string
+		selector    string
+		re          string
+		inputString string
+		wantString  string
+	}{
+		{
+			name:        "mask group",
+			re:          `(?i)(Pwd *= *)(?P<mask>[^\;]+)(;)`,
+			inputString: "pwd = abcdef;\nPWD = abcdef; pwd = other;",
+			wantString:  "pwd = " + secret + ";\nPWD = " + secret + "; pwd = " + tokenize([]byte("other")) + ";\n",
+		},
+		{
+			name:        "drop group",
+			re:          `(?i)(Pwd *= *)(?P<mask>[^\;]+)(?P<drop>;)`,
+			inputString: "pwd = abcdef;",
+			wantString:  "pwd = " + secret + "\n",
+		},
+		{
+			name:        "no match",
+			re:          `(?i)(Pwd *= *)(?P<mask>[^\;]+)(;)`,
+			inputString: "user = abcdef;",
+			wantString:  "user = abcdef;\n",
+		},
+		{
+			name:        "selector",
+			selector:    `"name": "PASSWORD"`,
+			re:          `("value": ")(?P<mask>[^"]*)(")`,
+			inputString: "\"name\": \"PASSWORD\"\n\"value\": \"abcdef\"\n\"name\": \"USER\"\n\"value\": \"admin\"",
+			wantString:  "\"name\": \"PASSWORD\"\n\"value\": \"" + secret + "\"\n\"name\": \"USER\"\n\"value\": \"admin\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+
+			redactor, err := NewTokenRedactor(tt.selector, tt.re, "testfile", tt.name)
+			req.NoError(err)
+
+			outReader := redactor.Redact(bytes.NewReader([]byte(tt.inputString)), "")
+			gotBytes, err := ioutil.ReadAll(outReader)
+			req.NoError(err)
+			req.Equal(tt.wantString, string(gotBytes))
+		})
+	}
+
+	GetRedactionList()
+	ResetRedactionList()
+}
+
+func Test_RedactTokenize(t *testing.T) {
+	req := require.New(t)
+
+	redacts := []*troubleshootv1beta2.Redact{
+		{
+			Name:     "tokenize",
+			Tokenize: true,
+			Removals: troubleshootv1beta2.Removals{
+				Values:   []string{"abc123"},
+				YamlPath: []string{"password"},
+			},
+		},
+	}
+
+	secret := tokenize([]byte("abc123"))
+
+	redacted, err := Redact(strings.NewReader("key abc123 and abc123"), "tokenize.log", redacts)
+	req.NoError(err)
+	got, err := ioutil.ReadAll(redacted)
+	req.NoError(err)
+	req.Equal("key "+secret+" and "+secret, strings.TrimSpace(string(got)))
+
+	redacted, err = Redact(strings.NewReader("password: abc123\n"), "tokenize.yaml", redacts)
+	req.NoError(err)
+	got, err = ioutil.ReadAll(redacted)
+	req.NoError(err)
+	req.Equal("password: "+secret, strings.TrimSpace(string(got)))
+
+	GetRedactionList()
+	ResetRedactionList()
+}
@@ -0,0 +1,86 @@
+package redact
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ResolveValuesFrom returns copies of redacts with the values of their valuesFrom sources added to their
+// literal values. Sources are read once before collection rather than for every file, and client is
+// only used for secretKeyRef sources. Values are never included in errors.
+func ResolveValuesFrom(ctx context.Context, client kubernetes.Interface, redacts []*troubleshootv1beta2.Redact) ([]*troubleshootv1beta2.Redact, error) {
+	resolved := make([]*troubleshootv1beta2.Redact, 0, len(redacts))
+	for i, redact := range redacts {
+		if redact == nil || len(redact.Removals.ValuesFrom) == 0 {
+			resolved = append(resolved, redact)
+			continue
+		}
+
+		name := redact.Name
+		if name == "" {
+			name = fmt.Sprintf("unnamed-%d", i)
+		}
+
+		redact = redact.DeepCopy()
+		for j, valueFrom := range redact.Removals.ValuesFrom {
+			value, err := ResolveValueFrom(ctx, client, valueFrom)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to resolve valuesFrom %d of redactor %s", j, name)
+			}
+			// an empty value would match everywhere
+			if value != "" {
+				redact.Removals.Values = append(redact.Removals.Values, value)
+			}
+		}
+		redact.Removals.ValuesFrom = nil
+
+		resolved = append(resolved, redact)
+	}
+
+	return resolved, nil
+}
+
+// ResolveValueFrom reads the value of a single source. client is only used for secretKeyRef sources, and
+// may be nil otherwise.
+func ResolveValueFrom(ctx context.Context, client kubernetes.Interface, valueFrom troubleshootv1beta2.ValueFrom) (string, error) {
+	switch {
+	case valueFrom.EnvVar != "":
+		value, ok := os.LookupEnv(valueFrom.EnvVar)
+		if !ok {
+			return "", errors.Errorf("environment variable %s is not set", valueFrom.EnvVar)
+		}
+		return value, nil
+
+	case valueFrom.File != "":
+		b, err := ioutil.ReadFile(valueFrom.File)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read file")
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+
+	case valueFrom.SecretKeyRef != nil:
+		if client == nil {
+			return "", errors.New("no kubernetes client to read secrets with")
+		}
+		ref := valueFrom.SecretKeyRef
+		secret, err := client.CoreV1().Secrets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to get secret %s/%s", ref.Namespace, ref.Name)
+		}
+		value, ok := secret.Data[ref.Key]
+		if !ok {
+			return "", errors.Errorf("secret %s/%s has no key %s", ref.Namespace, ref.Name, ref.Key)
+		}
+		return strings.TrimRight(string(value), "\r\n"), nil
+	}
+
+	return "", errors.New("one of envVar, file or secretKeyRef is required")
+}
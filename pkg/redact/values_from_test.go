@@ -0,0 +1,110 @@
+package redact
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestResolveValuesFrom(t *testing.T) {
+	req := require.New(t)
+
+	t.Setenv("TEST_LICENSE_KEY", "license-abc123")
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	req.NoError(ioutil.WriteFile(passwordFile, []byte("hunter2\n"), 0600))
+	client := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "app"},
+		Data:       map[string][]byte{"password": []byte("s3cret")},
+	})
+
+	redacts := []*troubleshootv1beta2.Redact{
+		{
+			Name: "known secrets",
+			Removals: troubleshootv1beta2.Removals{
+				Values: []string{"literal"},
+				ValuesFrom: []troubleshootv1beta2.ValueFrom{
+					{EnvVar: "TEST_LICENSE_KEY"},
+					{File: passwordFile},
+					{SecretKeyRef: &troubleshootv1beta2.SecretKeyRef{Namespace: "app", Name: "db", Key: "password"}},
+				},
+			},
+		},
+		{
+			Name: "regex only",
+			Removals: troubleshootv1beta2.Removals{
+				Regex: []troubleshootv1beta2.Regex{{Redactor: `(token=)(?P<mask>.*)`}},
+			},
+		},
+	}
+
+	resolved, err := ResolveValuesFrom(context.Background(), client, redacts)
+	req.NoError(err)
+	req.Len(resolved, 2)
+	assert.Equal(t, []string{"literal", "license-abc123", "hunter2", "s3cret"}, resolved[0].Removals.Values)
+	assert.Nil(t, resolved[0].Removals.ValuesFrom)
+	assert.Same(t, redacts[1], resolved[1])
+
+	// the spec is not modified
+	assert.Equal(t, []string{"literal"}, redacts[0].Removals.Values)
+
+	redacted, err := Redact(strings.NewReader("license-abc123 hunter2 s3cret"), "app/config", resolved)
+	req.NoError(err)
+	got, err := ioutil.ReadAll(redacted)
+	req.NoError(err)
+	assert.Equal(t, MASK_TEXT+" "+MASK_TEXT+" "+MASK_TEXT, strings.TrimSpace(string(got)))
+
+	// unresolved values are an error rather than being left in the bundle
+	_, err = Redact(strings.NewReader("s3cret"), "app/config", redacts)
+	req.Error(err)
+
+	GetRedactionList()
+	ResetRedactionList()
+}
+
+func TestResolveValuesFrom_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		valueFrom troubleshootv1beta2.ValueFrom
+		wantErr   string
+	}{
+		{
+			name:      "missing env var",
+			valueFrom: troubleshootv1beta2.ValueFrom{EnvVar: "TEST_DOES_NOT_EXIST"},
+			wantErr:   "environment variable TEST_DOES_NOT_EXIST is not set",
+		},
+		{
+			name:      "missing secret key",
+			valueFrom: troubleshootv1beta2.ValueFrom{SecretKeyRef: &troubleshootv1beta2.SecretKeyRef{Namespace: "app", Name: "db", Key: "token"}},
+			wantErr:   "secret app/db has no key token",
+		},
+		{
+			name:      "no source",
+			valueFrom: troubleshootv1beta2.ValueFrom{},
+			wantErr:   "one of envVar, file or secretKeyRef is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "app"},
+				Data:       map[string][]byte{"password": []byte("s3cret")},
+			})
+
+			_, err := ResolveValuesFrom(context.Background(), client, []*troubleshootv1beta2.Redact{
+				{Removals: troubleshootv1beta2.Removals{ValuesFrom: []troubleshootv1beta2.ValueFrom{tt.valueFrom}}},
+			})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.Contains(t, err.Error(), "redactor unnamed-0")
+		})
+	}
+}
@@ -15,6 +15,8 @@ import (
 
 type YamlRedactor struct {
 	maskPath   []string
+	maskText   string
+	tokenize   bool
 	foundMatch bool
 	filePath   string
 	redactName string
@@ -23,7 +25,7 @@ type YamlRedactor struct {
 
 func NewYamlRedactor(yamlPath, filePath, name string) *YamlRedactor {
 	pathComponents := strings.Split(yamlPath, ".")
-	return &YamlRedactor{maskPath: pathComponents, filePath: filePath, redactName: name}
+	return &YamlRedactor{maskPath: pathComponents, maskText: MASK_TEXT, filePath: filePath, redactName: name}
 }
 
 func (r *YamlRedactor) Redact(input io.Reader, path string) io.Reader {
@@ -93,7 +95,10 @@ func (r *YamlRedactor) Redact(input io.Reader, path string) io.Reader {
 func (r *YamlRedactor) redactYaml(in interface{}, path []string) interface{} {
 	if len(path) == 0 {
 		r.foundMatch = true
-		return MASK_TEXT
+		if r.tokenize {
+			return tokenizeValue(in)
+		}
+		return r.maskText
 	}
 	switch typed := in.(type) {
 	case []interface{}:
@@ -0,0 +1,81 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// CollectorResult is the files a CustomCollector collected, by their path in the bundle
+type CollectorResult map[string][]byte
+
+// CustomCollector is created by a CollectorFactory to run a custom collector from a spec. Its results
+// are redacted and archived with the results of the built-in collectors.
+type CustomCollector interface {
+	// Collect returns the files to add to the bundle. ctx is cancelled at the collector's timeout, or
+	// when the bundle's collection is cancelled.
+	Collect(ctx context.Context) (CollectorResult, error)
+}
+
+// CustomCollectorOptions are passed to a CollectorFactory
+type CustomCollectorOptions struct {
+	// Namespace is the namespace of the collection, for collectors that don't set one
+	Namespace    string
+	ClientConfig *rest.Config
+	Client       kubernetes.Interface
+	// SinceTime limits the logs that are collected
+	SinceTime *time.Time
+}
+
+// CollectorFactory creates the collector for a custom collector spec each time it runs
+type CollectorFactory func(collector *troubleshootv1beta2.Custom, opts CustomCollectorOptions) (CustomCollector, error)
+
+// RegisterCollector makes a collector available to specs as a custom collector with this type. It
+// panics if the type is already registered, so it should be called from init.
+func RegisterCollector(collectorType string, factory CollectorFactory) {
+	if factory == nil {
+		collect.Register(collectorType, nil)
+		return
+	}
+
+	collect.Register(collectorType, func(collector *troubleshootv1beta2.Custom, opts collect.CustomCollectorOptions) (collect.CustomCollector, error) {
+		c, err := factory(collector, CustomCollectorOptions{
+			Namespace:    opts.Namespace,
+			ClientConfig: opts.ClientConfig,
+			Client:       opts.Client,
+			SinceTime:    opts.SinceTime,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &customCollector{collector: c, ctx: opts.Context, bundlePath: opts.BundlePath}, nil
+	})
+}
+
+// customCollector is a collect.CustomCollector that saves the results of a CustomCollector to the bundle
+type customCollector struct {
+	collector  CustomCollector
+	ctx        context.Context
+	bundlePath string
+}
+
+func (c *customCollector) Collect(progressChan chan<- interface{}) (collect.CollectorResult, error) {
+	files, err := c.collector.Collect(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	output := collect.NewResult()
+	for name, data := range files {
+		if err := output.SaveResult(c.bundlePath, name, bytes.NewReader(data)); err != nil {
+			return nil, errors.Wrapf(err, "failed to save %s", name)
+		}
+	}
+	return output, nil
+}
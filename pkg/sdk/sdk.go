@@ -0,0 +1,265 @@
+// Package sdk is the API for programs that embed troubleshoot to collect and analyze support bundles.
+// It is kept stable across releases, unlike the packages it wraps, and only exposes the troubleshoot.sh
+// API types in addition to its own.
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	analyzer "github.com/replicatedhq/troubleshoot/pkg/analyze"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"github.com/replicatedhq/troubleshoot/pkg/supportbundle"
+	"k8s.io/client-go/rest"
+)
+
+// Progress is reported while a bundle is collected
+type Progress struct {
+	// Message describes what is being collected
+	Message string
+	// Err is a problem that did not stop collection, such as a collector that failed
+	Err error
+	// Collector is set when a collector starts running and when it has completed or failed
+	Collector *CollectorProgress
+}
+
+// CollectorProgress is the progress of a single collector
+type CollectorProgress struct {
+	Name string
+	// Status is running, completed or failed
+	Status string
+	// Completed is how many of the Total collectors have completed or failed
+	Completed int
+	Total     int
+	// BytesCollected is the size of what the collector collected, once it has completed
+	BytesCollected int64
+}
+
+type CollectOptions struct {
+	// KubernetesRestConfig is the cluster to collect from, it is required
+	KubernetesRestConfig *rest.Config
+	// Namespace limits namespaced collectors that don't set a namespace
+	Namespace string
+	// SinceTime limits the logs that are collected
+	SinceTime *time.Time
+	// OutputPath is the archive to write, by default one is created in os.TempDir()
+	OutputPath string
+	// Sink receives the archive instead of it being written to OutputPath
+	Sink BundleSink
+	// Retention deletes old archives from Sink after the archive is written, Sink must be a BundleStore
+	Retention RetentionPolicy
+	// DisableRedaction stops the default and additional redactors from running
+	DisableRedaction bool
+	// Redactors are run in addition to the default redactors
+	Redactors []*troubleshootv1beta2.Redact
+	// ExcludeDefaultRedactors are groups of the default redactors that are not run, one of
+	// redact.DefaultRedactorGroups
+	ExcludeDefaultRedactors []string
+	// CollectWithoutPermissions collects what it can when RBAC does not allow some collectors to run
+	CollectWithoutPermissions bool
+	// NamespaceBundles are namespaces to also write an archive for, with only the namespace's data and
+	// cluster scoped data
+	NamespaceBundles []string
+	// CollectConcurrency is how many collectors can run at the same time, it overrides the spec's
+	// collectConcurrency
+	CollectConcurrency int
+	// OnProgress is called for progress while collecting, from a single goroutine
+	OnProgress func(Progress)
+}
+
+type Bundle struct {
+	// ArchivePath is the .tar.gz support bundle, or where the sink stored it
+	ArchivePath string
+	// AnalyzeResults are the results of the spec's analyzers
+	AnalyzeResults []AnalyzeResult
+	// Uploaded is set if the bundle was uploaded by an afterCollection step
+	Uploaded bool
+	// NamespaceArchivePaths are the archives of CollectOptions.NamespaceBundles, by namespace
+	NamespaceArchivePaths map[string]string
+}
+
+type AnalyzeResult struct {
+	Title   string
+	IsPass  bool
+	IsWarn  bool
+	IsFail  bool
+	Strict  bool
+	Message string
+	URI     string
+}
+
+// collectSem is held while a bundle is collected. The redactions of a bundle are recorded in a list
+// that is global to the process, so bundles can't be collected at the same time.
+var collectSem = make(chan struct{}, 1)
+
+// CollectBundle runs the collectors and analyzers of spec and writes a support bundle archive. When ctx
+// is cancelled, the collectors that are running are stopped and no archive is written.
+//
+// Bundles are collected one at a time: a call waits for the calls before it to return, or for ctx to
+// be cancelled.
+func CollectBundle(ctx context.Context, spec *troubleshootv1beta2.SupportBundleSpec, opts CollectOptions) (*Bundle, error) {
+	if spec == nil {
+		return nil, errors.New("spec is required")
+	}
+	if opts.KubernetesRestConfig == nil {
+		return nil, errors.New("kubernetes rest config is required")
+	}
+
+	select {
+	case collectSem <- struct{}{}:
+		defer func() { <-collectSem }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	progressChan := make(chan interface{})
+	progressDone := make(chan struct{})
+	go func() {
+		defer close(progressDone)
+		for p := range progressChan {
+			if opts.OnProgress != nil {
+				opts.OnProgress(toProgress(p))
+			}
+		}
+	}()
+
+	createOpts := supportbundle.SupportBundleCreateOpts{
+		CollectorProgressCallback: func(c chan interface{}, msg string) {
+			c <- msg
+		},
+		CollectWithoutPermissions: opts.CollectWithoutPermissions,
+		KubernetesRestConfig:      opts.KubernetesRestConfig,
+		Namespace:                 opts.Namespace,
+		ProgressChan:              progressChan,
+		SinceTime:                 opts.SinceTime,
+		OutputPath:                opts.OutputPath,
+		Redact:                    !opts.DisableRedaction,
+		Sink:                      toBundleSink(opts.Sink),
+		Retention:                 supportbundle.RetentionPolicy(opts.Retention),
+		NamespaceBundles:          opts.NamespaceBundles,
+		CollectConcurrency:        opts.CollectConcurrency,
+		Context:                   ctx,
+	}
+
+	redactors := &troubleshootv1beta2.Redactor{
+		Spec: troubleshootv1beta2.RedactorSpec{
+			Redactors:               opts.Redactors,
+			ExcludeDefaultRedactors: opts.ExcludeDefaultRedactors,
+		},
+	}
+
+	response, err := supportbundle.CollectSupportBundleFromSpec(spec, redactors, createOpts)
+	close(progressChan)
+	<-progressDone
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to collect support bundle")
+	}
+
+	return &Bundle{
+		ArchivePath:           response.ArchivePath,
+		AnalyzeResults:        toAnalyzeResults(response.AnalyzerResults),
+		Uploaded:              response.FileUploaded,
+		NamespaceArchivePaths: response.NamespaceArchivePaths,
+	}, nil
+}
+
+// Analyze runs the analyzers of spec against a support bundle, which can be an archive or an extracted
+// directory
+func Analyze(ctx context.Context, bundlePath string, spec *troubleshootv1beta2.SupportBundleSpec) ([]AnalyzeResult, error) {
+	if spec == nil {
+		return nil, errors.New("spec is required")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(bundlePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to stat bundle")
+	}
+
+	bundleDir := bundlePath
+	if !info.IsDir() {
+		tmpDir, err := ioutil.TempDir("", "troubleshoot-sdk-")
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create temp dir")
+		}
+		defer os.RemoveAll(tmpDir)
+
+		f, err := os.Open(bundlePath)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open bundle")
+		}
+		defer f.Close()
+
+		if err := analyzer.ExtractTroubleshootBundle(f, tmpDir); err != nil {
+			return nil, errors.Wrap(err, "failed to extract bundle")
+		}
+		bundleDir = tmpDir
+	}
+
+	rootDir, err := analyzer.FindBundleRootDir(bundleDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find bundle root dir")
+	}
+	if err := collect.VerifyBundleManifest(rootDir); err != nil {
+		return nil, err
+	}
+
+	results, err := analyzer.AnalyzeLocal(bundleDir, spec.Analyzers, spec.HostAnalyzers)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to analyze bundle")
+	}
+
+	return toAnalyzeResults(results), nil
+}
+
+func toProgress(p interface{}) Progress {
+	switch p := p.(type) {
+	case string:
+		return Progress{Message: p}
+	case error:
+		return Progress{Message: p.Error(), Err: p}
+	case collect.CollectProgress:
+		return Progress{
+			Message: fmt.Sprintf("[%d/%d] %s %s", p.CompletedCount, p.TotalCount, p.CurrentName, p.CurrentStatus),
+			Err:     p.Err,
+			Collector: &CollectorProgress{
+				Name:           p.CurrentName,
+				Status:         p.CurrentStatus,
+				Completed:      p.CompletedCount,
+				Total:          p.TotalCount,
+				BytesCollected: p.BytesCollected,
+			},
+		}
+	default:
+		return Progress{Message: fmt.Sprintf("%v", p)}
+	}
+}
+
+func toAnalyzeResults(results []*analyzer.AnalyzeResult) []AnalyzeResult {
+	analyzeResults := []AnalyzeResult{}
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		analyzeResults = append(analyzeResults, AnalyzeResult{
+			Title:   r.Title,
+			IsPass:  r.IsPass,
+			IsWarn:  r.IsWarn,
+			IsFail:  r.IsFail,
+			Strict:  r.Strict,
+			Message: r.Message,
+			URI:     r.URI,
+		})
+	}
+	return analyzeResults
+}
@@ -0,0 +1,237 @@
+package sdk
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+func TestAnalyze(t *testing.T) {
+	bundleDir := t.TempDir()
+	files := map[string]string{
+		"version.yaml":                 "apiVersion: troubleshoot.sh/v1beta2\nkind: SupportBundle\n",
+		"cluster-resources/nodes.json": `{"kind": "NodeList", "apiVersion": "v1", "items": [{"metadata": {"name": "node-1"}}]}`,
+	}
+	for name, contents := range files {
+		filename := filepath.Join(bundleDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755))
+		require.NoError(t, ioutil.WriteFile(filename, []byte(contents), 0644))
+	}
+
+	spec := &troubleshootv1beta2.SupportBundleSpec{
+		Analyzers: []*troubleshootv1beta2.Analyze{
+			{
+				NodeResources: &troubleshootv1beta2.NodeResources{
+					AnalyzeMeta: troubleshootv1beta2.AnalyzeMeta{CheckName: "Node count"},
+					Outcomes: []*troubleshootv1beta2.Outcome{
+						{Fail: &troubleshootv1beta2.SingleOutcome{When: "count() < 2", Message: "Not enough nodes"}},
+						{Pass: &troubleshootv1beta2.SingleOutcome{Message: "Enough nodes"}},
+					},
+				},
+			},
+		},
+	}
+
+	results, err := Analyze(context.Background(), bundleDir, spec)
+	require.NoError(t, err)
+	assert.Equal(t, []AnalyzeResult{{Title: "Node count", IsFail: true, Message: "Not enough nodes"}}, results)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Analyze(ctx, bundleDir, spec)
+	assert.Equal(t, context.Canceled, err)
+
+	_, err = Analyze(context.Background(), filepath.Join(bundleDir, "missing"), spec)
+	assert.Error(t, err)
+}
+
+func TestCollectBundle_Validation(t *testing.T) {
+	spec := &troubleshootv1beta2.SupportBundleSpec{}
+
+	_, err := CollectBundle(context.Background(), nil, CollectOptions{KubernetesRestConfig: &rest.Config{}})
+	assert.Error(t, err)
+
+	_, err = CollectBundle(context.Background(), spec, CollectOptions{})
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = CollectBundle(ctx, spec, CollectOptions{KubernetesRestConfig: &rest.Config{}})
+	assert.Equal(t, context.Canceled, err)
+}
+
+type collectorFunc func(ctx context.Context) (CollectorResult, error)
+
+func (f collectorFunc) Collect(ctx context.Context) (CollectorResult, error) {
+	return f(ctx)
+}
+
+// memoryStore is a BundleStore that keeps the archives written to it in memory
+type memoryStore struct {
+	bundles  []StoredBundle
+	archives map[string][]byte
+}
+
+func (s *memoryStore) Write(name string, r io.Reader) (string, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	s.bundles = append(s.bundles, StoredBundle{Name: name, Time: time.Now(), Size: int64(len(b))})
+	s.archives[name] = b
+	return "memory://" + name, nil
+}
+
+func (s *memoryStore) List() ([]StoredBundle, error) {
+	return s.bundles, nil
+}
+
+func (s *memoryStore) Delete(name string) error {
+	for i, b := range s.bundles {
+		if b.Name == name {
+			s.bundles = append(s.bundles[:i], s.bundles[i+1:]...)
+			return nil
+		}
+	}
+	return errors.Errorf("%s not found", name)
+}
+
+// newTestAPIServer returns an API server that allows everything and has nothing
+func newTestAPIServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/selfsubjectaccessreviews") {
+			w.Write([]byte(`{"apiVersion": "authorization.k8s.io/v1", "kind": "SelfSubjectAccessReview", "status": {"allowed": true}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"apiVersion": "v1", "kind": "Status", "status": "Failure", "reason": "NotFound", "code": 404}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCollectBundle_CustomCollector(t *testing.T) {
+	server := newTestAPIServer(t)
+
+	RegisterCollector("sdk-test-files", func(collector *troubleshootv1beta2.Custom, opts CustomCollectorOptions) (CustomCollector, error) {
+		return collectorFunc(func(ctx context.Context) (CollectorResult, error) {
+			return CollectorResult{"custom/" + opts.Namespace + ".txt": []byte("collected")}, nil
+		}), nil
+	})
+
+	spec := &troubleshootv1beta2.SupportBundleSpec{
+		Collectors: []*troubleshootv1beta2.Collect{
+			{Custom: &troubleshootv1beta2.Custom{Type: "sdk-test-files"}},
+		},
+	}
+
+	store := &memoryStore{
+		bundles:  []StoredBundle{{Name: "old.tar.gz", Time: time.Now().Add(-time.Hour)}},
+		archives: map[string][]byte{},
+	}
+	bundle, err := CollectBundle(context.Background(), spec, CollectOptions{
+		KubernetesRestConfig: &rest.Config{Host: server.URL},
+		Namespace:            "app",
+		Sink:                 store,
+		Retention:            RetentionPolicy{MaxBundles: 1},
+		DisableRedaction:     true,
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(bundle.ArchivePath, "memory://support-bundle-"), bundle.ArchivePath)
+
+	// the old archive was deleted by the retention policy
+	require.Len(t, store.bundles, 1)
+	assert.Equal(t, strings.TrimPrefix(bundle.ArchivePath, "memory://"), store.bundles[0].Name)
+
+	gz, err := gzip.NewReader(bytes.NewReader(store.archives[store.bundles[0].Name]))
+	require.NoError(t, err)
+	files := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(tr)
+		require.NoError(t, err)
+		files[filepath.Base(filepath.Dir(header.Name))+"/"+filepath.Base(header.Name)] = string(b)
+	}
+	assert.Equal(t, "collected", files["custom/app.txt"])
+}
+
+func TestCollectBundle_Cancelled(t *testing.T) {
+	server := newTestAPIServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the collector is cancelled while it is running, and waits for the cancellation to reach it
+	stopped := make(chan struct{})
+	RegisterCollector("sdk-test-cancelled", func(collector *troubleshootv1beta2.Custom, opts CustomCollectorOptions) (CustomCollector, error) {
+		return collectorFunc(func(ctx context.Context) (CollectorResult, error) {
+			cancel()
+			defer close(stopped)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(10 * time.Second):
+				return nil, errors.New("collector was not cancelled")
+			}
+		}), nil
+	})
+
+	spec := &troubleshootv1beta2.SupportBundleSpec{
+		Collectors: []*troubleshootv1beta2.Collect{
+			{Custom: &troubleshootv1beta2.Custom{Type: "sdk-test-cancelled"}},
+		},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	_, err := CollectBundle(ctx, spec, CollectOptions{
+		KubernetesRestConfig: &rest.Config{Host: server.URL},
+		OutputPath:           outputPath,
+		DisableRedaction:     true,
+	})
+	assert.True(t, errors.Is(err, context.Canceled), "%v", err)
+	<-stopped
+
+	_, err = os.Stat(outputPath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func Test_toProgress(t *testing.T) {
+	err := errors.New("failed to run collector")
+
+	assert.Equal(t, Progress{Message: "cluster-info"}, toProgress("cluster-info"))
+	assert.Equal(t, Progress{Message: "failed to run collector", Err: err}, toProgress(err))
+	assert.Equal(t, Progress{Message: "1"}, toProgress(1))
+	assert.Equal(t, Progress{
+		Message:   "[3/5] cluster-resources completed",
+		Collector: &CollectorProgress{Name: "cluster-resources", Status: "completed", Completed: 3, Total: 5, BytesCollected: 1024},
+	}, toProgress(collect.CollectProgress{
+		CurrentName:    "cluster-resources",
+		CurrentStatus:  "completed",
+		CompletedCount: 3,
+		TotalCount:     5,
+		BytesCollected: 1024,
+	}))
+}
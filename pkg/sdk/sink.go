@@ -0,0 +1,79 @@
+package sdk
+
+import (
+	"io"
+	"time"
+
+	"github.com/replicatedhq/troubleshoot/pkg/supportbundle"
+)
+
+// BundleSink is a destination for bundle archives. The archive is streamed to the sink as it is
+// written, so it does not need to be written to a local file first.
+type BundleSink interface {
+	// Write stores the .tar.gz archive read from r. name is the archive's file name, e.g.
+	// support-bundle-2022-10-01T10_00_00.tar.gz. It returns where the archive was stored.
+	Write(name string, r io.Reader) (string, error)
+}
+
+// BundleStore is a BundleSink that keeps the archives written to it, and can list and delete them so
+// that a RetentionPolicy can be applied
+type BundleStore interface {
+	BundleSink
+	// List returns the archives in the store
+	List() ([]StoredBundle, error)
+	// Delete removes the archive with this name
+	Delete(name string) error
+}
+
+// StoredBundle is an archive in a BundleStore
+type StoredBundle struct {
+	Name string
+	Time time.Time
+	Size int64
+}
+
+// RetentionPolicy is which archives a BundleStore keeps. Archives are removed oldest first, and the
+// zero value keeps all of them.
+type RetentionPolicy struct {
+	// MaxBundles is how many archives are kept
+	MaxBundles int
+	// MaxAge is how long archives are kept
+	MaxAge time.Duration
+}
+
+// toBundleSink returns the sink that supportbundle writes to for sink, which is a store if sink is one
+func toBundleSink(sink BundleSink) supportbundle.BundleSink {
+	if sink == nil {
+		return nil
+	}
+	if store, ok := sink.(BundleStore); ok {
+		return &bundleStore{store: store}
+	}
+	return sink
+}
+
+// bundleStore is a supportbundle.BundleStore for a BundleStore
+type bundleStore struct {
+	store BundleStore
+}
+
+func (s *bundleStore) Write(name string, r io.Reader) (string, error) {
+	return s.store.Write(name, r)
+}
+
+func (s *bundleStore) List() ([]supportbundle.StoredBundle, error) {
+	bundles, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	stored := []supportbundle.StoredBundle{}
+	for _, b := range bundles {
+		stored = append(stored, supportbundle.StoredBundle{Name: b.Name, Time: b.Time, Size: b.Size})
+	}
+	return stored, nil
+}
+
+func (s *bundleStore) Delete(name string) error {
+	return s.store.Delete(name)
+}
@@ -0,0 +1,101 @@
+package serve
+
+import (
+	"io"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/replicatedhq/troubleshoot/pkg/supportbundle"
+)
+
+// the outcomes of collections, and of writing their bundles to the data dir
+const (
+	outcomeCompleted = "completed"
+	outcomeFailed    = "failed"
+	outcomeCancelled = "cancelled"
+	outcomeSucceeded = "succeeded"
+)
+
+// metrics are what the server serves at /metrics, so that operators can alert on collections that fail.
+// Each server has its own registry, so that servers in the same process don't share their metrics.
+type metrics struct {
+	registry *prometheus.Registry
+
+	collections *prometheus.CounterVec
+	rejected    prometheus.Counter
+	queued      prometheus.Gauge
+	duration    *prometheus.HistogramVec
+	bundleSize  prometheus.Histogram
+	uploads     *prometheus.CounterVec
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		collections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "troubleshoot_serve_collections_total",
+			Help: "Collections that finished, by outcome: completed, failed or cancelled.",
+		}, []string{"outcome"}),
+		rejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "troubleshoot_serve_collections_rejected_total",
+			Help: "Collections that were not accepted as the queue was full or the server was shutting down.",
+		}),
+		queued: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "troubleshoot_serve_collections_queued",
+			Help: "Collections waiting for the one that is running.",
+		}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "troubleshoot_serve_collection_duration_seconds",
+			Help:    "How long collections ran for, by outcome.",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+		}, []string{"outcome"}),
+		bundleSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "troubleshoot_serve_bundle_size_bytes",
+			Help:    "The size of the bundles that were stored.",
+			Buckets: prometheus.ExponentialBuckets(1024*1024, 4, 8),
+		}),
+		uploads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "troubleshoot_serve_bundle_uploads_total",
+			Help: "Bundles written to where the server stores them, by outcome: succeeded or failed.",
+		}, []string{"outcome"}),
+	}
+
+	m.registry.MustRegister(m.collections, m.rejected, m.queued, m.duration, m.bundleSize, m.uploads)
+	for _, outcome := range []string{outcomeCompleted, outcomeFailed, outcomeCancelled} {
+		m.collections.WithLabelValues(outcome)
+	}
+	for _, outcome := range []string{outcomeSucceeded, outcomeFailed} {
+		m.uploads.WithLabelValues(outcome)
+	}
+
+	return m
+}
+
+// meteredSink records the size and outcome of the bundles that are written to sink
+type meteredSink struct {
+	sink    supportbundle.BundleSink
+	metrics *metrics
+}
+
+func (s *meteredSink) Write(name string, r io.Reader) (string, error) {
+	counter := &countingReader{r: r}
+	location, err := s.sink.Write(name, counter)
+	if err != nil {
+		s.metrics.uploads.WithLabelValues(outcomeFailed).Inc()
+		return "", err
+	}
+
+	s.metrics.uploads.WithLabelValues(outcomeSucceeded).Inc()
+	s.metrics.bundleSize.Observe(float64(counter.n))
+	return location, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
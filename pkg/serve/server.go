@@ -0,0 +1,489 @@
+// Package serve is an API for collecting support bundles on request, so that support portals can ask
+// for a bundle from a cluster and download it once it has been collected.
+package serve
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/logger"
+	"github.com/replicatedhq/troubleshoot/pkg/sdk"
+	"github.com/replicatedhq/troubleshoot/pkg/supportbundle"
+	"k8s.io/client-go/rest"
+)
+
+// maxSpecSize is the largest spec that can be submitted
+const maxSpecSize = 10 * 1024 * 1024
+
+// DefaultMaxQueuedCollections is how many collections can wait to run when ServerOptions does not set it
+const DefaultMaxQueuedCollections = 10
+
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+type ServerOptions struct {
+	// Address is the host and port to listen on, e.g. :8080
+	Address string
+	// Token is the bearer token requests are authenticated with. Anyone with the token can run any
+	// collector with the server's access to the cluster.
+	Token string
+	// DataDir is where bundles are kept until they are deleted
+	DataDir string
+	// CertFile and KeyFile are served with TLS if they are set
+	CertFile string
+	KeyFile  string
+	// KubernetesRestConfig is the cluster to collect from
+	KubernetesRestConfig *rest.Config
+	// MaxQueuedCollections is how many collections can wait for the one that is running. Requests for
+	// more are rejected until the queue has room. DefaultMaxQueuedCollections is used when it is 0.
+	MaxQueuedCollections int
+}
+
+// Collection is a support bundle requested from the API
+type Collection struct {
+	ID       string     `json:"id"`
+	Status   string     `json:"status"`
+	Created  time.Time  `json:"created"`
+	Finished *time.Time `json:"finished,omitempty"`
+	// CurrentCollector is the collector that last started running
+	CurrentCollector    string `json:"currentCollector,omitempty"`
+	CompletedCollectors int    `json:"completedCollectors"`
+	TotalCollectors     int    `json:"totalCollectors"`
+	Error               string `json:"error,omitempty"`
+
+	archivePath string
+}
+
+// Server runs the collections requested from its API one at a time, as redaction keeps its list of
+// redactions globally. The collections that are requested while one is running wait in a queue, and
+// requests are rejected while the queue is full.
+type Server struct {
+	opts ServerOptions
+	// collectBundle is sdk.CollectBundle, other than in tests
+	collectBundle func(ctx context.Context, spec *troubleshootv1beta2.SupportBundleSpec, opts sdk.CollectOptions) (*sdk.Bundle, error)
+
+	mu          sync.Mutex
+	collections map[string]*Collection
+
+	// queue has the collections waiting to run, it is only sent to with mu held
+	queue chan queuedCollection
+	// ctx is cancelled by Close, which cancels the collection that is running and the queued ones
+	ctx    context.Context
+	cancel context.CancelFunc
+	// done is closed once the collections have stopped running
+	done chan struct{}
+
+	metrics *metrics
+}
+
+type queuedCollection struct {
+	id        string
+	spec      *troubleshootv1beta2.SupportBundleSpec
+	redactors *troubleshootv1beta2.Redactor
+}
+
+func NewServer(opts ServerOptions) (*Server, error) {
+	if opts.Token == "" {
+		return nil, errors.New("a token is required")
+	}
+	if opts.DataDir == "" {
+		return nil, errors.New("a data dir is required")
+	}
+	if err := os.MkdirAll(opts.DataDir, 0700); err != nil {
+		return nil, errors.Wrap(err, "failed to create data dir")
+	}
+
+	maxQueued := opts.MaxQueuedCollections
+	if maxQueued <= 0 {
+		maxQueued = DefaultMaxQueuedCollections
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		opts:          opts,
+		collectBundle: sdk.CollectBundle,
+		collections:   map[string]*Collection{},
+		queue:         make(chan queuedCollection, maxQueued),
+		ctx:           ctx,
+		cancel:        cancel,
+		done:          make(chan struct{}),
+		metrics:       newMetrics(),
+	}
+	go s.runQueue()
+
+	return s, nil
+}
+
+// Close cancels the collection that is running, fails the collections that are queued, and waits for
+// the collection that was running to stop
+func (s *Server) Close() {
+	s.cancel()
+	<-s.done
+}
+
+// Run serves the API until ctx is cancelled, then cancels the collection that is running and those that
+// are queued
+func Run(ctx context.Context, opts ServerOptions) error {
+	server, err := NewServer(opts)
+	if err != nil {
+		return err
+	}
+
+	httpServer := &http.Server{
+		Addr:    opts.Address,
+		Handler: server.Handler(),
+	}
+	go func() {
+		<-ctx.Done()
+		httpServer.Shutdown(context.Background())
+	}()
+
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		err = httpServer.ListenAndServeTLS(opts.CertFile, opts.KeyFile)
+	} else {
+		err = httpServer.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
+		return errors.Wrap(err, "failed to serve")
+	}
+
+	server.Close()
+	return nil
+}
+
+// Handler serves the API:
+//
+//	POST   /v1/collections             submit a SupportBundle spec, with its Redactor documents, to collect
+//	GET    /v1/collections             list the collections
+//	GET    /v1/collections/:id         get a collection and its progress
+//	GET    /v1/collections/:id/bundle  download the bundle of a completed collection
+//	DELETE /v1/collections/:id         delete a collection that is not running, and its bundle
+//	GET    /healthz                    is not authenticated
+//	GET    /metrics                    Prometheus metrics of the collections, is not authenticated
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle("/metrics", promhttp.HandlerFor(s.metrics.registry, promhttp.HandlerOpts{}))
+	mux.Handle("/v1/collections", s.authenticate(http.HandlerFunc(s.handleCollections)))
+	mux.Handle("/v1/collections/", s.authenticate(http.HandlerFunc(s.handleCollection)))
+	return mux
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		token := strings.TrimPrefix(authorization, "Bearer ")
+		if token == authorization || subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleCollections(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.createCollection(w, r)
+	case http.MethodGet:
+		s.mu.Lock()
+		collections := []Collection{}
+		for _, c := range s.collections {
+			collections = append(collections, *c)
+		}
+		s.mu.Unlock()
+
+		sort.Slice(collections, func(i, j int) bool {
+			return collections[i].Created.Before(collections[j].Created)
+		})
+		writeJSON(w, http.StatusOK, collections)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) handleCollection(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/collections/"), "/")
+	id := parts[0]
+
+	s.mu.Lock()
+	c, ok := s.collections[id]
+	var collection Collection
+	if ok {
+		collection = *c
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "collection not found")
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, collection)
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		s.deleteCollection(w, collection)
+	case len(parts) == 2 && parts[1] == "bundle" && r.Method == http.MethodGet:
+		s.downloadBundle(w, r, collection)
+	case len(parts) <= 2:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) createCollection(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxSpecSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read spec")
+		return
+	}
+
+	// upstream specs and imports are not loaded, the server only runs what it was sent
+	multidocs := strings.Split(string(body), "\n---\n")
+	supportBundle, err := supportbundle.ParseSupportBundleDocs(multidocs, false)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.Wrap(err, "failed to parse spec").Error())
+		return
+	}
+	redactors, err := supportbundle.ParseRedactorFromSpec(multidocs)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.Wrap(err, "failed to parse redactors").Error())
+		return
+	}
+
+	id, err := newID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	collection := &Collection{
+		ID:      id,
+		Status:  StatusPending,
+		Created: time.Now(),
+	}
+
+	s.mu.Lock()
+	if s.ctx.Err() != nil {
+		s.mu.Unlock()
+		s.metrics.rejected.Inc()
+		writeError(w, http.StatusServiceUnavailable, "the server is shutting down")
+		return
+	}
+	select {
+	case s.queue <- queuedCollection{id: id, spec: &supportBundle.Spec, redactors: redactors}:
+		s.metrics.queued.Inc()
+	default:
+		s.mu.Unlock()
+		s.metrics.rejected.Inc()
+		w.Header().Set("Retry-After", "60")
+		writeError(w, http.StatusServiceUnavailable, "too many collections are queued")
+		return
+	}
+	s.collections[id] = collection
+	response := *collection
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusAccepted, response)
+}
+
+// runQueue runs the queued collections one at a time until the server is closed, then fails those that
+// are still queued
+func (s *Server) runQueue() {
+	defer close(s.done)
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			s.failQueued()
+			return
+		case q := <-s.queue:
+			s.metrics.queued.Dec()
+			if s.ctx.Err() != nil {
+				s.failQueued(q)
+				return
+			}
+			s.runCollection(q.id, q.spec, q.redactors)
+		}
+	}
+}
+
+// failQueued fails the collections that are queued, and any that were already taken from the queue,
+// once the server has been closed. Nothing is queued after this, as requests are rejected once the
+// server is closed.
+func (s *Server) failQueued(taken ...queuedCollection) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	finished := time.Now()
+	fail := func(q queuedCollection) {
+		s.metrics.collections.WithLabelValues(outcomeCancelled).Inc()
+		if c, ok := s.collections[q.id]; ok {
+			c.Status = StatusFailed
+			c.Error = "cancelled as the server is shutting down"
+			c.Finished = &finished
+		}
+	}
+
+	for _, q := range taken {
+		fail(q)
+	}
+	for {
+		select {
+		case q := <-s.queue:
+			s.metrics.queued.Dec()
+			fail(q)
+		default:
+			return
+		}
+	}
+}
+
+func (s *Server) runCollection(id string, spec *troubleshootv1beta2.SupportBundleSpec, redactors *troubleshootv1beta2.Redactor) {
+	started := time.Now()
+	s.update(id, func(c *Collection) {
+		c.Status = StatusRunning
+	})
+
+	bundle, err := s.collect(id, spec, redactors)
+	finished := time.Now()
+
+	outcome := outcomeCompleted
+	if err != nil && s.ctx.Err() != nil {
+		outcome = outcomeCancelled
+	} else if err != nil {
+		outcome = outcomeFailed
+	}
+	s.metrics.collections.WithLabelValues(outcome).Inc()
+	s.metrics.duration.WithLabelValues(outcome).Observe(finished.Sub(started).Seconds())
+
+	s.update(id, func(c *Collection) {
+		c.Finished = &finished
+		if err != nil {
+			c.Status = StatusFailed
+			c.Error = err.Error()
+			return
+		}
+		c.Status = StatusCompleted
+		c.archivePath = bundle.ArchivePath
+	})
+	if err != nil {
+		logger.Printf("Collection %s failed: %v", id, err)
+	}
+}
+
+func (s *Server) collect(id string, spec *troubleshootv1beta2.SupportBundleSpec, redactors *troubleshootv1beta2.Redactor) (*sdk.Bundle, error) {
+	dir := filepath.Join(s.opts.DataDir, id)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrap(err, "failed to create collection dir")
+	}
+
+	return s.collectBundle(s.ctx, spec, sdk.CollectOptions{
+		KubernetesRestConfig:      s.opts.KubernetesRestConfig,
+		Sink:                      &meteredSink{sink: &supportbundle.FileSink{Dir: dir}, metrics: s.metrics},
+		Redactors:                 redactors.Spec.Redactors,
+		ExcludeDefaultRedactors:   redactors.Spec.ExcludeDefaultRedactors,
+		CollectWithoutPermissions: true,
+		OnProgress: func(p sdk.Progress) {
+			if p.Collector == nil {
+				return
+			}
+			s.update(id, func(c *Collection) {
+				if p.Collector.Status == "running" {
+					c.CurrentCollector = p.Collector.Name
+				}
+				c.CompletedCollectors = p.Collector.Completed
+				c.TotalCollectors = p.Collector.Total
+			})
+		},
+	})
+}
+
+func (s *Server) update(id string, f func(c *Collection)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.collections[id]; ok {
+		f(c)
+	}
+}
+
+func (s *Server) downloadBundle(w http.ResponseWriter, r *http.Request, collection Collection) {
+	if collection.Status != StatusCompleted {
+		writeError(w, http.StatusConflict, "collection is "+collection.Status)
+		return
+	}
+
+	f, err := os.Open(collection.archivePath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to open bundle")
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to stat bundle")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/tar+gzip")
+	w.Header().Set("Content-Disposition", "attachment; filename="+filepath.Base(collection.archivePath))
+	http.ServeContent(w, r, filepath.Base(collection.archivePath), info.ModTime(), f)
+}
+
+func (s *Server) deleteCollection(w http.ResponseWriter, collection Collection) {
+	if collection.Status == StatusPending || collection.Status == StatusRunning {
+		writeError(w, http.StatusConflict, "collection is "+collection.Status)
+		return
+	}
+
+	if err := os.RemoveAll(filepath.Join(s.opts.DataDir, collection.ID)); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete bundle")
+		return
+	}
+
+	s.mu.Lock()
+	delete(s.collections, collection.ID)
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "failed to generate id")
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
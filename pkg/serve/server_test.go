@@ -0,0 +1,272 @@
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSpec = `apiVersion: troubleshoot.sh/v1beta2
+kind: SupportBundle
+metadata:
+  name: example
+spec:
+  collectors:
+  - clusterInfo: {}
+---
+apiVersion: troubleshoot.sh/v1beta2
+kind: Redactor
+metadata:
+  name: example
+spec:
+  redactors:
+  - name: passwords
+    removals:
+      values:
+      - hunter2
+`
+
+func newTestServer(t *testing.T, collectBundle func(ctx context.Context, spec *troubleshootv1beta2.SupportBundleSpec, opts sdk.CollectOptions) (*sdk.Bundle, error)) (*Server, *httptest.Server) {
+	return newTestServerWithOptions(t, ServerOptions{}, collectBundle)
+}
+
+func newTestServerWithOptions(t *testing.T, opts ServerOptions, collectBundle func(ctx context.Context, spec *troubleshootv1beta2.SupportBundleSpec, opts sdk.CollectOptions) (*sdk.Bundle, error)) (*Server, *httptest.Server) {
+	opts.Token = "secret"
+	opts.DataDir = t.TempDir()
+	server, err := NewServer(opts)
+	require.NoError(t, err)
+	server.collectBundle = collectBundle
+	t.Cleanup(server.Close)
+
+	httpServer := httptest.NewServer(server.Handler())
+	t.Cleanup(httpServer.Close)
+	return server, httpServer
+}
+
+func request(t *testing.T, method string, url string, body string) *http.Response {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func decodeCollection(t *testing.T, resp *http.Response) Collection {
+	var collection Collection
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&collection))
+	return collection
+}
+
+func waitForCollection(t *testing.T, url string, id string) Collection {
+	for i := 0; i < 100; i++ {
+		collection := decodeCollection(t, request(t, http.MethodGet, url+"/v1/collections/"+id, ""))
+		if collection.Status == StatusCompleted || collection.Status == StatusFailed {
+			return collection
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("collection did not finish")
+	return Collection{}
+}
+
+func TestServer(t *testing.T) {
+	_, httpServer := newTestServer(t, func(ctx context.Context, spec *troubleshootv1beta2.SupportBundleSpec, opts sdk.CollectOptions) (*sdk.Bundle, error) {
+		assert.Len(t, spec.Collectors, 1)
+		assert.Len(t, opts.Redactors, 1)
+		opts.OnProgress(sdk.Progress{Collector: &sdk.CollectorProgress{Name: "cluster-info", Status: "running", Total: 1}})
+		opts.OnProgress(sdk.Progress{Collector: &sdk.CollectorProgress{Name: "cluster-info", Status: "completed", Completed: 1, Total: 1}})
+
+		location, err := opts.Sink.Write("support-bundle.tar.gz", strings.NewReader("archive"))
+		if err != nil {
+			return nil, err
+		}
+		return &sdk.Bundle{ArchivePath: location}, nil
+	})
+
+	resp := request(t, http.MethodPost, httpServer.URL+"/v1/collections", testSpec)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	created := decodeCollection(t, resp)
+	assert.NotEmpty(t, created.ID)
+
+	collection := waitForCollection(t, httpServer.URL, created.ID)
+	assert.Equal(t, StatusCompleted, collection.Status)
+	assert.Equal(t, "cluster-info", collection.CurrentCollector)
+	assert.Equal(t, 1, collection.CompletedCollectors)
+	assert.Equal(t, 1, collection.TotalCollectors)
+	assert.NotNil(t, collection.Finished)
+
+	resp = request(t, http.MethodGet, httpServer.URL+"/v1/collections/"+created.ID+"/bundle", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/tar+gzip", resp.Header.Get("Content-Type"))
+	archive, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "archive", string(archive))
+
+	resp = request(t, http.MethodGet, httpServer.URL+"/v1/collections", "")
+	var collections []Collection
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&collections))
+	assert.Len(t, collections, 1)
+
+	resp = request(t, http.MethodDelete, httpServer.URL+"/v1/collections/"+created.ID, "")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp = request(t, http.MethodGet, httpServer.URL+"/v1/collections/"+created.ID, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestServer_Failed(t *testing.T) {
+	_, httpServer := newTestServer(t, func(ctx context.Context, spec *troubleshootv1beta2.SupportBundleSpec, opts sdk.CollectOptions) (*sdk.Bundle, error) {
+		return nil, errors.New("insufficient permissions")
+	})
+
+	resp := request(t, http.MethodPost, httpServer.URL+"/v1/collections", testSpec)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	created := decodeCollection(t, resp)
+
+	collection := waitForCollection(t, httpServer.URL, created.ID)
+	assert.Equal(t, StatusFailed, collection.Status)
+	assert.Equal(t, "insufficient permissions", collection.Error)
+
+	resp = request(t, http.MethodGet, httpServer.URL+"/v1/collections/"+created.ID+"/bundle", "")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
+
+func TestServer_Metrics(t *testing.T) {
+	calls := 0
+	_, httpServer := newTestServer(t, func(ctx context.Context, spec *troubleshootv1beta2.SupportBundleSpec, opts sdk.CollectOptions) (*sdk.Bundle, error) {
+		calls++
+		if calls > 1 {
+			return nil, errors.New("insufficient permissions")
+		}
+		location, err := opts.Sink.Write("support-bundle.tar.gz", strings.NewReader("archive"))
+		if err != nil {
+			return nil, err
+		}
+		return &sdk.Bundle{ArchivePath: location}, nil
+	})
+
+	for i := 0; i < 2; i++ {
+		created := decodeCollection(t, request(t, http.MethodPost, httpServer.URL+"/v1/collections", testSpec))
+		waitForCollection(t, httpServer.URL, created.ID)
+	}
+
+	resp, err := http.Get(httpServer.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	metrics := string(b)
+	assert.Contains(t, metrics, `troubleshoot_serve_collections_total{outcome="completed"} 1`)
+	assert.Contains(t, metrics, `troubleshoot_serve_collections_total{outcome="failed"} 1`)
+	assert.Contains(t, metrics, `troubleshoot_serve_collections_total{outcome="cancelled"} 0`)
+	assert.Contains(t, metrics, `troubleshoot_serve_collection_duration_seconds_count{outcome="completed"} 1`)
+	assert.Contains(t, metrics, `troubleshoot_serve_bundle_uploads_total{outcome="succeeded"} 1`)
+	assert.Contains(t, metrics, `troubleshoot_serve_bundle_size_bytes_sum 7`)
+	assert.Contains(t, metrics, `troubleshoot_serve_collections_queued 0`)
+}
+
+func TestServer_Requests(t *testing.T) {
+	_, httpServer := newTestServer(t, nil)
+
+	resp, err := http.Post(httpServer.URL+"/v1/collections", "application/yaml", strings.NewReader(testSpec))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, err := http.NewRequest(http.MethodGet, httpServer.URL+"/v1/collections", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "secret")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, err = http.Get(httpServer.URL + "/healthz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = request(t, http.MethodPost, httpServer.URL+"/v1/collections", "kind: Redactor\n")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = request(t, http.MethodGet, httpServer.URL+"/v1/collections/missing", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestNewServer(t *testing.T) {
+	_, err := NewServer(ServerOptions{DataDir: t.TempDir()})
+	assert.Error(t, err)
+
+	_, err = NewServer(ServerOptions{Token: "secret"})
+	assert.Error(t, err)
+}
+
+func TestServer_QueueFull(t *testing.T) {
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	_, httpServer := newTestServerWithOptions(t, ServerOptions{MaxQueuedCollections: 1}, func(ctx context.Context, spec *troubleshootv1beta2.SupportBundleSpec, opts sdk.CollectOptions) (*sdk.Bundle, error) {
+		started <- struct{}{}
+		<-release
+		return nil, errors.New("released")
+	})
+
+	running := decodeCollection(t, request(t, http.MethodPost, httpServer.URL+"/v1/collections", testSpec))
+	<-started
+
+	resp := request(t, http.MethodPost, httpServer.URL+"/v1/collections", testSpec)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	queued := decodeCollection(t, resp)
+	assert.Equal(t, StatusPending, queued.Status)
+
+	resp = request(t, http.MethodPost, httpServer.URL+"/v1/collections", testSpec)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+
+	close(release)
+	assert.Equal(t, StatusFailed, waitForCollection(t, httpServer.URL, running.ID).Status)
+	assert.Equal(t, StatusFailed, waitForCollection(t, httpServer.URL, queued.ID).Status)
+
+	resp = request(t, http.MethodPost, httpServer.URL+"/v1/collections", testSpec)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+}
+
+func TestServer_Close(t *testing.T) {
+	started := make(chan struct{}, 2)
+	server, httpServer := newTestServer(t, func(ctx context.Context, spec *troubleshootv1beta2.SupportBundleSpec, opts sdk.CollectOptions) (*sdk.Bundle, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	running := decodeCollection(t, request(t, http.MethodPost, httpServer.URL+"/v1/collections", testSpec))
+	<-started
+	queued := decodeCollection(t, request(t, http.MethodPost, httpServer.URL+"/v1/collections", testSpec))
+
+	server.Close()
+
+	collection := waitForCollection(t, httpServer.URL, running.ID)
+	assert.Equal(t, StatusFailed, collection.Status)
+	assert.Contains(t, collection.Error, context.Canceled.Error())
+
+	collection = waitForCollection(t, httpServer.URL, queued.ID)
+	assert.Equal(t, StatusFailed, collection.Status)
+	assert.Equal(t, "cancelled as the server is shutting down", collection.Error)
+	assert.Len(t, started, 0)
+
+	resp := request(t, http.MethodPost, httpServer.URL+"/v1/collections", testSpec)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
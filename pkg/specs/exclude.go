@@ -0,0 +1,286 @@
+package specs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	analyzer "github.com/replicatedhq/troubleshoot/pkg/analyze"
+	"github.com/replicatedhq/troubleshoot/pkg/multitype"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// ExcludeContext is the data available to templated exclude and when expressions.
+// e.g. `exclude: '{{ .Cluster.IsOpenShift }}'` or `when: '{{ .Values.ha }}'`
+type ExcludeContext struct {
+	Values  map[string]interface{}
+	Cluster ClusterFacts
+}
+
+// ClusterFacts are basic facts about the cluster that are known before any collectors run
+type ClusterFacts struct {
+	KubernetesVersion     string
+	Distribution          string
+	IsOpenShift           bool
+	NodeCount             int
+	ControlPlaneNodeCount int
+	IsHA                  bool
+}
+
+// GetClusterFacts queries the api server for the facts made available to exclude expressions
+func GetClusterFacts(ctx context.Context, client kubernetes.Interface) (*ClusterFacts, error) {
+	facts := &ClusterFacts{}
+
+	serverVersion, err := client.Discovery().ServerVersion()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get server version")
+	}
+	facts.KubernetesVersion = serverVersion.GitVersion
+
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list nodes")
+	}
+	facts.NodeCount = len(nodes.Items)
+	for _, node := range nodes.Items {
+		_, isMaster := node.Labels["node-role.kubernetes.io/master"]
+		_, isControlPlane := node.Labels["node-role.kubernetes.io/control-plane"]
+		if isMaster || isControlPlane {
+			facts.ControlPlaneNodeCount++
+		}
+	}
+	facts.IsHA = facts.ControlPlaneNodeCount > 1
+
+	foundProviders, distribution := analyzer.ParseNodesForProviders(nodes.Items)
+
+	// a partial list of resources is still useful, so errors are ignored here
+	_, apiResources, _ := client.Discovery().ServerGroupsAndResources()
+	distribution = analyzer.CheckApiResourcesForProviders(&foundProviders, apiResources, distribution)
+
+	facts.Distribution = distribution
+	facts.IsOpenShift = distribution == "openShift"
+
+	return facts, nil
+}
+
+// RenderExcludesForCluster evaluates templated exclude and when fields in the spec using the provided values.
+// Cluster facts are only queried if the spec contains templated excludes and clientConfig is not nil.
+func RenderExcludesForCluster(spec interface{}, values map[string]interface{}, clientConfig *rest.Config) error {
+	if !HasTemplatedExcludes(spec) {
+		return nil
+	}
+
+	excludeContext := ExcludeContext{
+		Values: values,
+	}
+
+	if clientConfig != nil {
+		client, err := kubernetes.NewForConfig(clientConfig)
+		if err != nil {
+			return errors.Wrap(err, "failed to create kubernetes client")
+		}
+
+		facts, err := GetClusterFacts(context.Background(), client)
+		if err != nil {
+			return errors.Wrap(err, "failed to get cluster facts")
+		}
+		excludeContext.Cluster = *facts
+	}
+
+	return RenderExcludes(spec, excludeContext)
+}
+
+// HasTemplatedExcludes returns true if any exclude or when field in the spec contains a template expression
+func HasTemplatedExcludes(spec interface{}) bool {
+	found := false
+	walkExcludes(reflect.ValueOf(spec), func(name string, exclude *multitype.BoolOrString) error {
+		if isTemplatedExclude(exclude) {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
+// RenderExcludes evaluates all templated exclude and when fields in the spec and replaces them with their boolean result.
+// The spec is modified in place.
+func RenderExcludes(spec interface{}, excludeContext ExcludeContext) error {
+	return walkExcludes(reflect.ValueOf(spec), func(name string, exclude *multitype.BoolOrString) error {
+		if !isTemplatedExclude(exclude) {
+			return nil
+		}
+
+		rendered, err := renderExclude(exclude.StrVal, excludeContext)
+		if err != nil {
+			return errors.Wrapf(err, "failed to render %s %q", strings.ToLower(name), exclude.StrVal)
+		}
+
+		*exclude = *multitype.FromBool(rendered)
+		return nil
+	})
+}
+
+func isTemplatedExclude(exclude *multitype.BoolOrString) bool {
+	return exclude != nil && exclude.Type == multitype.String && strings.Contains(exclude.StrVal, "{{")
+}
+
+func renderExclude(text string, excludeContext ExcludeContext) (bool, error) {
+	tmpl, err := template.New("exclude").Funcs(excludeFuncMap()).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to parse template")
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, excludeContext); err != nil {
+		return false, errors.Wrap(err, "failed to execute template")
+	}
+
+	result := strings.TrimSpace(buf.String())
+	if result == "" || result == "<no value>" {
+		return false, nil
+	}
+
+	parsed, err := strconv.ParseBool(result)
+	if err != nil {
+		return false, errors.Errorf("must evaluate to a boolean, got %q", result)
+	}
+
+	return parsed, nil
+}
+
+var boolOrStringPtrType = reflect.TypeOf(&multitype.BoolOrString{})
+
+// walkExcludes calls fn for every non-nil field named Exclude or When of type *multitype.BoolOrString
+// found in v, with the name of the field
+func walkExcludes(v reflect.Value, fn func(string, *multitype.BoolOrString) error) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return walkExcludes(v.Elem(), fn)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := walkExcludes(v.Index(i), fn); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			name := t.Field(i).Name
+			if (name == "Exclude" || name == "When") && field.Type() == boolOrStringPtrType {
+				if field.IsNil() {
+					continue
+				}
+				if err := fn(name, field.Interface().(*multitype.BoolOrString)); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := walkExcludes(field, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// excludeFuncMap returns the template functions available to exclude expressions.
+// The names and behaviour follow the equivalent sprig functions.
+func excludeFuncMap() template.FuncMap {
+	return template.FuncMap{
+		"default": func(d interface{}, given ...interface{}) interface{} {
+			if len(given) == 0 || isEmpty(given[0]) {
+				return d
+			}
+			return given[0]
+		},
+		"empty": isEmpty,
+		"coalesce": func(v ...interface{}) interface{} {
+			for _, val := range v {
+				if !isEmpty(val) {
+					return val
+				}
+			}
+			return nil
+		},
+		"ternary": func(vt interface{}, vf interface{}, v bool) interface{} {
+			if v {
+				return vt
+			}
+			return vf
+		},
+		"toString": func(v interface{}) string {
+			return fmt.Sprintf("%v", v)
+		},
+		"lower":     strings.ToLower,
+		"upper":     strings.ToUpper,
+		"trim":      strings.TrimSpace,
+		"contains":  func(substr string, str string) bool { return strings.Contains(str, substr) },
+		"hasPrefix": func(prefix string, str string) bool { return strings.HasPrefix(str, prefix) },
+		"hasSuffix": func(suffix string, str string) bool { return strings.HasSuffix(str, suffix) },
+		"list":      func(v ...interface{}) []interface{} { return v },
+		"has": func(needle interface{}, haystack []interface{}) bool {
+			for _, v := range haystack {
+				if reflect.DeepEqual(v, needle) {
+					return true
+				}
+			}
+			return false
+		},
+		"semverCompare": semverCompare,
+	}
+}
+
+func semverCompare(constraint string, version string) (bool, error) {
+	v, err := semver.ParseTolerant(version)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to parse version %s", version)
+	}
+	// pre-release versions would never match a range without a pre-release
+	v.Pre = nil
+
+	r, err := semver.ParseRange(constraint)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to parse constraint %s", constraint)
+	}
+
+	return r(v), nil
+}
+
+func isEmpty(given interface{}) bool {
+	g := reflect.ValueOf(given)
+	if !g.IsValid() {
+		return true
+	}
+
+	switch g.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.String:
+		return g.Len() == 0
+	case reflect.Bool:
+		return !g.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return g.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return g.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return g.Float() == 0
+	case reflect.Ptr, reflect.Interface:
+		return g.IsNil()
+	default:
+		return false
+	}
+}
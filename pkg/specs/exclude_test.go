@@ -0,0 +1,176 @@
+package specs
+
+import (
+	"context"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/multitype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_RenderExcludes(t *testing.T) {
+	tests := []struct {
+		name    string
+		exclude string
+		context ExcludeContext
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:    "skip on openshift",
+			exclude: "{{ .Cluster.IsOpenShift }}",
+			context: ExcludeContext{Cluster: ClusterFacts{IsOpenShift: true}},
+			want:    true,
+		},
+		{
+			name:    "only when ha",
+			exclude: "{{ not .Values.ha }}",
+			context: ExcludeContext{Values: map[string]interface{}{"ha": true}},
+			want:    false,
+		},
+		{
+			name:    "missing value",
+			exclude: "{{ .Values.missing }}",
+			context: ExcludeContext{Values: map[string]interface{}{}},
+			want:    false,
+		},
+		{
+			name:    "sprig style functions",
+			exclude: `{{ and (eq (lower .Cluster.Distribution) "eks") (semverCompare "<1.22.0" .Cluster.KubernetesVersion) }}`,
+			context: ExcludeContext{Cluster: ClusterFacts{Distribution: "EKS", KubernetesVersion: "v1.21.5-eks-1234"}},
+			want:    true,
+		},
+		{
+			name:    "default",
+			exclude: `{{ default "true" .Values.skip }}`,
+			context: ExcludeContext{},
+			want:    true,
+		},
+		{
+			name:    "not a boolean",
+			exclude: "{{ .Cluster.NodeCount }}",
+			context: ExcludeContext{Cluster: ClusterFacts{NodeCount: 3}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			supportBundle := &troubleshootv1beta2.SupportBundle{
+				Spec: troubleshootv1beta2.SupportBundleSpec{
+					Collectors: []*troubleshootv1beta2.Collect{
+						{
+							Logs: &troubleshootv1beta2.Logs{
+								CollectorMeta: troubleshootv1beta2.CollectorMeta{
+									Exclude: multitype.FromString(tt.exclude),
+								},
+							},
+						},
+					},
+					Analyzers: []*troubleshootv1beta2.Analyze{
+						{
+							ClusterVersion: &troubleshootv1beta2.ClusterVersion{
+								AnalyzeMeta: troubleshootv1beta2.AnalyzeMeta{
+									Exclude: multitype.FromString(tt.exclude),
+								},
+							},
+						},
+					},
+				},
+			}
+
+			require.True(t, HasTemplatedExcludes(supportBundle))
+
+			err := RenderExcludes(supportBundle, tt.context)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, supportBundle.Spec.Collectors[0].Logs.Exclude.BoolOrDefaultFalse())
+			assert.Equal(t, tt.want, supportBundle.Spec.Analyzers[0].ClusterVersion.Exclude.BoolOrDefaultFalse())
+			assert.False(t, HasTemplatedExcludes(supportBundle))
+		})
+	}
+}
+
+func Test_RenderExcludes_when(t *testing.T) {
+	supportBundle := &troubleshootv1beta2.SupportBundle{
+		Spec: troubleshootv1beta2.SupportBundleSpec{
+			Collectors: []*troubleshootv1beta2.Collect{
+				{
+					Postgres: &troubleshootv1beta2.Database{
+						CollectorMeta: troubleshootv1beta2.CollectorMeta{
+							When: multitype.FromString("{{ .Values.postgres.enabled }}"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	require.True(t, HasTemplatedExcludes(supportBundle))
+
+	err := RenderExcludes(supportBundle, ExcludeContext{
+		Values: map[string]interface{}{"postgres": map[string]interface{}{"enabled": false}},
+	})
+	require.NoError(t, err)
+
+	when := supportBundle.Spec.Collectors[0].Postgres.When
+	assert.Equal(t, multitype.Bool, when.Type)
+	assert.False(t, when.BoolVal)
+	assert.False(t, HasTemplatedExcludes(supportBundle))
+}
+
+func Test_LoadValues(t *testing.T) {
+	values, err := LoadValues(nil, []string{"ha=true", "database.replicas=3", "name=app", "database.host=db"})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"ha":   true,
+		"name": "app",
+		"database": map[string]interface{}{
+			"replicas": 3,
+			"host":     "db",
+		},
+	}, values)
+
+	_, err = LoadValues(nil, []string{"novalue"})
+	assert.Error(t, err)
+}
+
+func Test_GetClusterFacts(t *testing.T) {
+	client := testclient.NewSimpleClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "node-1",
+				Labels: map[string]string{"node-role.kubernetes.io/control-plane": "", "kurl.sh/cluster": "true"},
+			},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "node-2",
+				Labels: map[string]string{"node-role.kubernetes.io/control-plane": ""},
+			},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-3",
+			},
+		},
+	)
+
+	facts, err := GetClusterFacts(context.Background(), client)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, facts.NodeCount)
+	assert.Equal(t, 2, facts.ControlPlaneNodeCount)
+	assert.True(t, facts.IsHA)
+	assert.Equal(t, "kurl", facts.Distribution)
+	assert.False(t, facts.IsOpenShift)
+}
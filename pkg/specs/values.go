@@ -0,0 +1,94 @@
+package specs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// LoadValues reads values from yaml files and key=value pairs, in that order, so that
+// values set on the command line take precedence over values from files.
+// Keys in setValues may use dots to set nested values, e.g. "database.ha=true".
+func LoadValues(valuesFiles []string, setValues []string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+
+	for _, valuesFile := range valuesFiles {
+		b, err := ioutil.ReadFile(valuesFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read values file %s", valuesFile)
+		}
+
+		fileValues := map[string]interface{}{}
+		if err := yaml.Unmarshal(b, &fileValues); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse values file %s", valuesFile)
+		}
+
+		mergeValues(values, normalizeValues(fileValues).(map[string]interface{}))
+	}
+
+	for _, setValue := range setValues {
+		parts := strings.SplitN(setValue, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.Errorf("invalid value %q, expected key=value", setValue)
+		}
+
+		var parsed interface{}
+		if err := yaml.Unmarshal([]byte(parts[1]), &parsed); err != nil {
+			parsed = parts[1]
+		}
+
+		keys := strings.Split(parts[0], ".")
+		current := values
+		for _, key := range keys[:len(keys)-1] {
+			next, ok := current[key].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+				current[key] = next
+			}
+			current = next
+		}
+		current[keys[len(keys)-1]] = normalizeValues(parsed)
+	}
+
+	return values, nil
+}
+
+func mergeValues(dst map[string]interface{}, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]interface{})
+		dstMap, dstIsMap := dst[k].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeValues(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// normalizeValues converts the map[interface{}]interface{} produced by yaml.v2 into
+// map[string]interface{} so values can be accessed by key in templates
+func normalizeValues(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for k, val := range t {
+			m[fmt.Sprintf("%v", k)] = normalizeValues(val)
+		}
+		return m
+	case map[string]interface{}:
+		for k, val := range t {
+			t[k] = normalizeValues(val)
+		}
+		return t
+	case []interface{}:
+		for i, val := range t {
+			t[i] = normalizeValues(val)
+		}
+		return t
+	default:
+		return v
+	}
+}
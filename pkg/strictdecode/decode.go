@@ -0,0 +1,360 @@
+package strictdecode
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	troubleshootscheme "github.com/replicatedhq/troubleshoot/pkg/client/troubleshootclientset/scheme"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// FieldError describes a single field in a document that does not match the spec
+type FieldError struct {
+	Path    string
+	Line    int
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("line %d: %s: %s", e.Line, e.Path, e.Message)
+}
+
+// FieldErrors is returned when one or more fields in a document are unknown or have the wrong type
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fieldError := range e {
+		msgs = append(msgs, fieldError.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+type strictDecoder struct {
+	decoder runtime.Decoder
+}
+
+// NewDecoder returns a decoder that rejects troubleshoot documents with unknown or mistyped fields
+// before passing them to the wrapped decoder. Documents of other kinds are passed through unchanged.
+func NewDecoder(decoder runtime.Decoder) runtime.Decoder {
+	return &strictDecoder{
+		decoder: decoder,
+	}
+}
+
+func (d *strictDecoder) Decode(data []byte, defaults *schema.GroupVersionKind, into runtime.Object) (runtime.Object, *schema.GroupVersionKind, error) {
+	if err := Validate(data); err != nil {
+		return nil, nil, err
+	}
+	return d.decoder.Decode(data, defaults, into)
+}
+
+// Validate checks that every field in a troubleshoot document exists in the type for its kind and
+// has a value of the expected type. Documents that cannot be parsed as yaml, or that are not a
+// troubleshoot kind, are not validated and are left for the decoder to report on.
+func Validate(doc []byte) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(doc, &root); err != nil {
+		return nil
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return nil
+	}
+
+	node := root.Content[0]
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	apiVersion := mappingValue(node, "apiVersion")
+	kind := mappingValue(node, "kind")
+	if apiVersion == nil || kind == nil {
+		return nil
+	}
+
+	obj, err := troubleshootscheme.Scheme.New(schema.FromAPIVersionAndKind(apiVersion.Value, kind.Value))
+	if err != nil {
+		return nil
+	}
+
+	v := &validator{}
+	v.validate(node, reflect.TypeOf(obj), "")
+	if len(v.errs) > 0 {
+		return errors.Wrapf(v.errs, "invalid %s", kind.Value)
+	}
+
+	return nil
+}
+
+type validator struct {
+	errs FieldErrors
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+func (v *validator) validate(node *yaml.Node, t reflect.Type, path string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.ShortTag() == "!!null" {
+		return
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// types with custom unmarshalling (quantities, timestamps, bool or string) accept more than one kind of value
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return
+
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			v.typeError(node, "object", path)
+			return
+		}
+
+		fields := structFields(t)
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			fieldPath := joinPath(path, key.Value)
+
+			if seen[key.Value] {
+				v.addError(key, fieldPath, "duplicate field")
+				continue
+			}
+			seen[key.Value] = true
+
+			fieldType, ok := fields[key.Value]
+			if !ok {
+				message := "unknown field"
+				if suggestion := suggest(key.Value, fields); suggestion != "" {
+					message = fmt.Sprintf("%s, did you mean %q?", message, suggestion)
+				}
+				v.addError(key, fieldPath, message)
+				continue
+			}
+
+			v.validate(value, fieldType, fieldPath)
+		}
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			v.typeError(node, "object", path)
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			v.validate(node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value))
+		}
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			v.expectScalar(node, path, "string", "!!str")
+			return
+		}
+		if node.Kind != yaml.SequenceNode {
+			v.typeError(node, "array", path)
+			return
+		}
+		for i, item := range node.Content {
+			v.validate(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+
+	case reflect.String:
+		v.expectScalar(node, path, "string", "!!str")
+
+	case reflect.Bool:
+		if isYAML11Bool(node) {
+			return
+		}
+		v.expectScalar(node, path, "bool", "!!bool")
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.expectScalar(node, path, "int", "!!int")
+
+	case reflect.Float32, reflect.Float64:
+		v.expectScalar(node, path, "float", "!!int", "!!float")
+	}
+}
+
+func (v *validator) expectScalar(node *yaml.Node, path string, expected string, tags ...string) {
+	if node.Kind == yaml.ScalarNode {
+		for _, tag := range tags {
+			if node.ShortTag() == tag {
+				return
+			}
+		}
+	}
+	v.typeError(node, expected, path)
+}
+
+// yaml11Bools are the booleans of YAML 1.1, such as yes and off. The decoder parses yaml as YAML 1.1,
+// while yaml.v3 parses them as strings.
+var yaml11Bools = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true, "on": true, "On": true, "ON": true,
+	"n": true, "N": true, "no": true, "No": true, "NO": true, "off": true, "Off": true, "OFF": true,
+}
+
+// isYAML11Bool returns true if node is an unquoted YAML 1.1 boolean
+func isYAML11Bool(node *yaml.Node) bool {
+	if node.Kind != yaml.ScalarNode || node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) != 0 {
+		return false
+	}
+	return yaml11Bools[node.Value]
+}
+
+func (v *validator) typeError(node *yaml.Node, expected string, path string) {
+	v.addError(node, path, fmt.Sprintf("expected %s, got %s", expected, nodeType(node)))
+}
+
+func (v *validator) addError(node *yaml.Node, path string, message string) {
+	v.errs = append(v.errs, FieldError{
+		Path:    path,
+		Line:    node.Line,
+		Message: message,
+	})
+}
+
+// structFields returns the types of all fields of a struct by their json name, including fields of inlined structs
+func structFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		if name == "" && field.Anonymous {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for embeddedName, embeddedType := range structFields(embedded) {
+					if _, ok := fields[embeddedName]; !ok {
+						fields[embeddedName] = embeddedType
+					}
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// suggest returns the known field name closest to name, or an empty string if none is close enough
+func suggest(name string, fields map[string]reflect.Type) string {
+	candidates := make([]string, 0, len(fields))
+	for candidate := range fields {
+		candidates = append(candidates, candidate)
+	}
+	sort.Strings(candidates)
+
+	best := ""
+	bestDistance := len(name)/3 + 1
+	if bestDistance < 2 {
+		bestDistance = 2
+	}
+	for _, candidate := range candidates {
+		if strings.EqualFold(candidate, name) {
+			return candidate
+		}
+		distance := levenshtein(strings.ToLower(name), strings.ToLower(candidate))
+		if distance <= bestDistance && (best == "" || distance < bestDistance) {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+	return best
+}
+
+func levenshtein(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, value := range values[1:] {
+		if value < m {
+			m = value
+		}
+	}
+	return m
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func nodeType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+
+	switch node.ShortTag() {
+	case "!!int":
+		return "int"
+	case "!!float":
+		return "float"
+	case "!!bool":
+		return "bool"
+	default:
+		return "string"
+	}
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,170 @@
+package strictdecode
+
+import (
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/client/troubleshootclientset/scheme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr string
+	}{
+		{
+			name: "valid support bundle",
+			doc: `apiVersion: troubleshoot.sh/v1beta2
+kind: SupportBundle
+metadata:
+  name: example
+spec:
+  collectors:
+    - logs:
+        collectorName: app
+        exclude: true
+        limits:
+          maxLines: 1000
+  analyzers:
+    - clusterVersion:
+        outcomes:
+          - pass:
+              message: ok
+`,
+		},
+		{
+			name: "unknown field with suggestion",
+			doc: `apiVersion: troubleshoot.sh/v1beta2
+kind: SupportBundle
+metadata:
+  name: example
+spec:
+  collectors:
+    - logz:
+        collectorName: app
+`,
+			wantErr: `line 7: spec.collectors[0].logz: unknown field, did you mean "logs"?`,
+		},
+		{
+			name: "unknown inline field",
+			doc: `apiVersion: troubleshoot.sh/v1beta2
+kind: Preflight
+metadata:
+  name: example
+spec:
+  analyzers:
+    - clusterVersion:
+        checkNmae: version
+`,
+			wantErr: `line 8: spec.analyzers[0].clusterVersion.checkNmae: unknown field, did you mean "checkName"?`,
+		},
+		{
+			name: "mistyped field",
+			doc: `apiVersion: troubleshoot.sh/v1beta2
+kind: SupportBundle
+metadata:
+  name: example
+spec:
+  collectors:
+    - logs:
+        selector: app=nginx
+        limits:
+          maxLines: lots
+`,
+			wantErr: "line 8: spec.collectors[0].logs.selector: expected array, got string\nline 10: spec.collectors[0].logs.limits.maxLines: expected int, got string",
+		},
+		{
+			name: "yaml 1.1 bool",
+			doc: `apiVersion: troubleshoot.sh/v1beta2
+kind: SupportBundle
+metadata:
+  name: example
+spec:
+  collectors:
+    - clusterResources:
+        ignoreRBAC: yes
+    - secret:
+        name: app
+        includeValue: Off
+`,
+		},
+		{
+			name: "quoted yaml 1.1 bool",
+			doc: `apiVersion: troubleshoot.sh/v1beta2
+kind: SupportBundle
+metadata:
+  name: example
+spec:
+  collectors:
+    - clusterResources:
+        ignoreRBAC: "yes"
+`,
+			wantErr: "line 8: spec.collectors[0].clusterResources.ignoreRBAC: expected bool, got string",
+		},
+		{
+			name: "not a troubleshoot kind",
+			doc: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: example
+anything: goes
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate([]byte(tt.doc))
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func Test_NewDecoder(t *testing.T) {
+	decode := NewDecoder(scheme.Codecs.UniversalDeserializer()).Decode
+
+	obj, _, err := decode([]byte(`apiVersion: troubleshoot.sh/v1beta2
+kind: Collector
+metadata:
+  name: example
+spec:
+  collectors:
+    - clusterInfo: {}
+`), nil, nil)
+	require.NoError(t, err)
+	assert.IsType(t, &troubleshootv1beta2.Collector{}, obj)
+
+	obj, _, err = decode([]byte(`apiVersion: troubleshoot.sh/v1beta2
+kind: Collector
+metadata:
+  name: example
+spec:
+  collectors:
+    - clusterResources:
+        ignoreRBAC: on
+`), nil, nil)
+	require.NoError(t, err)
+	assert.True(t, obj.(*troubleshootv1beta2.Collector).Spec.Collectors[0].ClusterResources.IgnoreRBAC)
+
+	_, _, err = decode([]byte(`apiVersion: troubleshoot.sh/v1beta2
+kind: Collector
+metadata:
+  name: example
+spec:
+  colectors:
+    - clusterInfo: {}
+`), nil, nil)
+	require.Error(t, err)
+
+	var fieldErrors FieldErrors
+	require.ErrorAs(t, err, &fieldErrors)
+	require.Len(t, fieldErrors, 1)
+	assert.Equal(t, FieldError{Path: "spec.colectors", Line: 6, Message: `unknown field, did you mean "collectors"?`}, fieldErrors[0])
+}
@@ -0,0 +1,38 @@
+package supportbundle
+
+import (
+	"strings"
+
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+)
+
+// OutputDirPrefix is the prefix of an output path that is a directory to write the files of the bundle to
+// instead of an archive, such as dir://./support-bundle
+const OutputDirPrefix = "dir://"
+
+const (
+	tarGzExtension = "tar.gz"
+	zipExtension   = "zip"
+)
+
+// archiveExtension returns the extension of the archive at outputPath, which is .tar.gz unless the path
+// ends in .zip
+func archiveExtension(outputPath string) string {
+	if strings.HasSuffix(outputPath, "."+zipExtension) {
+		return zipExtension
+	}
+	return tarGzExtension
+}
+
+// archiveSupportBundleDir writes the files of a support bundle to the archive at filename, in the format
+// of its extension
+func archiveSupportBundleDir(bundlePath string, result collect.CollectorResult, filename string, opts SupportBundleCreateOpts) error {
+	if archiveExtension(filename) == zipExtension {
+		return collect.ZipSupportBundleDir(bundlePath, result, filename, opts.archiveOptions())
+	}
+	return collect.TarSupportBundleDirWithOptions(bundlePath, result, filename, opts.archiveOptions())
+}
+
+func (opts SupportBundleCreateOpts) archiveOptions() collect.ArchiveOptions {
+	return collect.ArchiveOptions{Reproducible: opts.Reproducible}
+}
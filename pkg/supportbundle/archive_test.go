@@ -0,0 +1,34 @@
+package supportbundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_archiveExtension(t *testing.T) {
+	assert.Equal(t, "tar.gz", archiveExtension(""))
+	assert.Equal(t, "tar.gz", archiveExtension("bundles/support-bundle.tar.gz"))
+	assert.Equal(t, "tar.gz", archiveExtension("support-bundle"))
+	assert.Equal(t, "zip", archiveExtension(`C:\bundles\support-bundle.zip`))
+}
+
+func Test_archiveSupportBundleDir(t *testing.T) {
+	bundlePath := filepath.Join(t.TempDir(), "support-bundle")
+	result := collect.NewResult()
+	require.NoError(t, result.SaveResult(bundlePath, "version.yaml", bytes.NewBufferString("apiVersion: troubleshoot.sh/v1beta2\n")))
+
+	filename := filepath.Join(t.TempDir(), "support-bundle.zip")
+	require.NoError(t, archiveSupportBundleDir(bundlePath, result, filename, SupportBundleCreateOpts{}))
+
+	zipReader, err := zip.OpenReader(filename)
+	require.NoError(t, err)
+	defer zipReader.Close()
+	require.Len(t, zipReader.File, 2)
+	assert.Equal(t, "support-bundle/version.yaml", zipReader.File[0].Name)
+}
@@ -2,47 +2,74 @@ package supportbundle
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"github.com/pkg/errors"
 	analyze "github.com/replicatedhq/troubleshoot/pkg/analyze"
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
 	"github.com/replicatedhq/troubleshoot/pkg/collect"
 	"github.com/replicatedhq/troubleshoot/pkg/convert"
+	"github.com/replicatedhq/troubleshoot/pkg/redact"
 	"github.com/replicatedhq/troubleshoot/pkg/version"
 	"gopkg.in/yaml.v2"
-	"k8s.io/client-go/kubernetes"
 )
 
-func runHostCollectors(hostCollectors []*troubleshootv1beta2.HostCollect, additionalRedactors *troubleshootv1beta2.Redactor, bundlePath string, opts SupportBundleCreateOpts) (collect.CollectorResult, error) {
+func runHostCollectors(hostCollectors []*troubleshootv1beta2.HostCollect, additionalRedactors *troubleshootv1beta2.Redactor, bundlePath string, metadata *collect.CollectionMetadata, collectionErrors collect.CollectionErrors, execLog *executionLog, diskGuard *collect.DiskGuard, opts SupportBundleCreateOpts) (collect.CollectorResult, error) {
 	collectSpecs := make([]*troubleshootv1beta2.HostCollect, 0, 0)
 	collectSpecs = append(collectSpecs, hostCollectors...)
 
 	allCollectedData := make(map[string][]byte)
 
 	var collectors []collect.HostCollector
+	var specs []interface{}
 	for _, desiredCollector := range collectSpecs {
 		collector, ok := collect.GetHostCollector(desiredCollector, bundlePath)
 		if ok {
 			collectors = append(collectors, collector)
+			_, spec := specField(desiredCollector)
+			specs = append(specs, spec)
 		}
 	}
 
-	for _, collector := range collectors {
+	var collectorsToRun []collect.HostCollector
+	var specsToRun []interface{}
+	for i, collector := range collectors {
 		isExcluded, _ := collector.IsExcluded()
 		if isExcluded {
+			execLog.add(executionTypeHostCollector, collector.Title(), specs[i], time.Now(), executionOutcomeExcluded, "")
 			continue
 		}
+		collectorsToRun = append(collectorsToRun, collector)
+		specsToRun = append(specsToRun, specs[i])
+	}
 
+	progress := newCollectProgress(opts.ProgressChan, bundlePath, len(collectorsToRun))
+	for i, collector := range collectorsToRun {
+		if err := opts.Context.Err(); err != nil {
+			return nil, errors.Wrap(err, "collection was cancelled")
+		}
+		if err := diskGuard.BeforeRun(); err != nil {
+			execLog.add(executionTypeHostCollector, collector.Title(), specsToRun[i], time.Now(), executionOutcomeSkipped, err.Error())
+			collectionErrors.Add(collector.Title(), bundlePath, nil, errors.Wrap(err, "skipped"))
+			continue
+		}
 		opts.ProgressChan <- fmt.Sprintf("[%s] Running host collector...", collector.Title())
+		progress.started(collector.Title())
+		startTime := time.Now()
 		result, err := collector.Collect(opts.ProgressChan)
 		if err != nil {
 			opts.ProgressChan <- errors.Errorf("failed to run host collector: %s: %v", collector.Title(), err)
+			execLog.add(executionTypeHostCollector, collector.Title(), specsToRun[i], startTime, executionOutcomeFailed, err.Error())
+		} else {
+			execLog.add(executionTypeHostCollector, collector.Title(), specsToRun[i], startTime, executionOutcomeSucceeded, "")
 		}
+		progress.finished(collector.Title(), result, err)
+		metadata.AddCollector(collector.Title(), startTime, time.Now(), bundlePath, result)
+		collectionErrors.Add(collector.Title(), bundlePath, result, err)
 		for k, v := range result {
 			allCollectedData[k] = v
 		}
@@ -56,7 +83,7 @@ func runHostCollectors(hostCollectors []*troubleshootv1beta2.HostCollect, additi
 	}
 
 	if opts.Redact {
-		err := collect.RedactResult(bundlePath, collectResult, globalRedactors)
+		err := collect.RedactResultWithOptions(bundlePath, collectResult, nil, globalRedactors, redactOptions(additionalRedactors))
 		if err != nil {
 			err = errors.Wrap(err, "failed to redact")
 			return collectResult, err
@@ -66,7 +93,7 @@ func runHostCollectors(hostCollectors []*troubleshootv1beta2.HostCollect, additi
 	return collectResult, nil
 }
 
-func runCollectors(collectors []*troubleshootv1beta2.Collect, additionalRedactors *troubleshootv1beta2.Redactor, bundlePath string, opts SupportBundleCreateOpts) (collect.CollectorResult, error) {
+func runCollectors(collectors []*troubleshootv1beta2.Collect, additionalRedactors *troubleshootv1beta2.Redactor, bundlePath string, metadata *collect.CollectionMetadata, collectionErrors collect.CollectionErrors, execLog *executionLog, diskGuard *collect.DiskGuard, opts SupportBundleCreateOpts) (collect.CollectorResult, error) {
 	collectSpecs := make([]*troubleshootv1beta2.Collect, 0)
 	collectSpecs = append(collectSpecs, collectors...)
 	collectSpecs = collect.EnsureCollectorInList(collectSpecs, troubleshootv1beta2.Collect{ClusterInfo: &troubleshootv1beta2.ClusterInfo{}})
@@ -77,15 +104,21 @@ func runCollectors(collectors []*troubleshootv1beta2.Collect, additionalRedactor
 
 	allCollectedData := make(map[string][]byte)
 
-	k8sClient, err := kubernetes.NewForConfig(opts.KubernetesRestConfig)
+	// the collectors' requests are watched so that they can be run fewer at a time, and the low priority
+	// ones deferred, while the API server is throttling them
+	throttle := collect.NewAPIThrottle()
+	restConfig := throttle.WrapConfig(opts.KubernetesRestConfig)
+
+	// the collectors share their clients, and so their connections to the API server
+	clients, err := collect.NewClientFactory(restConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to instantiate Kubernetes client")
 	}
 
 	for _, desiredCollector := range collectSpecs {
-		if collectorInterface, ok := collect.GetCollector(desiredCollector, bundlePath, opts.Namespace, opts.KubernetesRestConfig, k8sClient, opts.SinceTime); ok {
+		if collectorInterface, ok := collect.GetCollectorWithClients(desiredCollector, bundlePath, opts.Namespace, clients, opts.SinceTime); ok {
 			if collector, ok := collectorInterface.(collect.Collector); ok {
-				err := collector.CheckRBAC(context.Background(), collector, desiredCollector, opts.KubernetesRestConfig, opts.Namespace)
+				err := collector.CheckRBAC(opts.Context, collector, desiredCollector, clients.Config(), opts.Namespace)
 				if err != nil {
 					return nil, errors.Wrap(err, "failed to check RBAC for collectors")
 				}
@@ -115,9 +148,11 @@ func runCollectors(collectors []*troubleshootv1beta2.Collect, additionalRedactor
 		return nil, errors.New("insufficient permissions to run all collectors")
 	}
 
+	collectorsToRun := []collect.Collector{}
 	for _, collector := range allCollectors {
 		isExcluded, _ := collector.IsExcluded()
 		if isExcluded {
+			execLog.add(executionTypeCollector, collector.Title(), collectorSpec(collector), time.Now(), executionOutcomeExcluded, "")
 			continue
 		}
 
@@ -126,19 +161,60 @@ func runCollectors(collectors []*troubleshootv1beta2.Collect, additionalRedactor
 			if _, ok := collector.(*collect.CollectClusterResources); !ok {
 				msg := fmt.Sprintf("skipping collector %s with insufficient RBAC permissions", collector.Title())
 				opts.CollectorProgressCallback(opts.ProgressChan, msg)
+				execLog.add(executionTypeCollector, collector.Title(), collectorSpec(collector), time.Now(), executionOutcomeSkipped, "insufficient RBAC permissions")
+				collectionErrors.Add(collector.Title(), bundlePath, nil, errors.New("skipped with insufficient RBAC permissions"))
 				continue
 			}
 		}
 
-		opts.CollectorProgressCallback(opts.ProgressChan, collector.Title())
-		result, err := collector.Collect(opts.ProgressChan)
-		if err != nil {
-			opts.ProgressChan <- errors.Errorf("failed to run collector: %s: %v", collector.Title(), err)
+		collectorsToRun = append(collectorsToRun, collector)
+	}
+
+	progress := newCollectProgress(opts.ProgressChan, bundlePath, len(collectorsToRun))
+	runs := collect.RunCollectorsWithOptions(opts.Context, collectorsToRun, opts.ProgressChan, collect.RunOptions{
+		Concurrency: opts.CollectConcurrency,
+		BeforeRun: func(collector collect.Collector) {
+			opts.CollectorProgressCallback(opts.ProgressChan, collector.Title())
+			progress.started(collector.Title())
+		},
+		AfterRun: func(run collect.CollectorRun) {
+			progress.finished(run.Collector.Title(), run.Result, run.Err)
+		},
+		Throttle:  throttle,
+		DiskGuard: diskGuard,
+	})
+	skippedCollectors := []string{}
+	for _, run := range runs {
+		if errors.Is(run.Err, collect.ErrThrottled) {
+			msg := fmt.Sprintf("skipping collector %s, it was %s", run.Collector.Title(), run.Err)
+			opts.CollectorProgressCallback(opts.ProgressChan, msg)
+			execLog.add(executionTypeCollector, run.Collector.Title(), collectorSpec(run.Collector), run.StartTime, executionOutcomeSkipped, run.Err.Error())
+			skippedCollectors = append(skippedCollectors, run.Collector.Title())
+			collectionErrors.Add(run.Collector.Title(), bundlePath, nil, errors.Wrap(run.Err, "skipped"))
+			continue
 		}
-		for k, v := range result {
+
+		if run.Err == collect.ErrInsufficientDiskSpace {
+			execLog.add(executionTypeCollector, run.Collector.Title(), collectorSpec(run.Collector), run.StartTime, executionOutcomeSkipped, run.Err.Error())
+			collectionErrors.Add(run.Collector.Title(), bundlePath, nil, errors.Wrap(run.Err, "skipped"))
+			continue
+		}
+
+		if run.Err != nil {
+			opts.ProgressChan <- errors.Errorf("failed to run collector: %s: %v", run.Collector.Title(), run.Err)
+			execLog.add(executionTypeCollector, run.Collector.Title(), collectorSpec(run.Collector), run.StartTime, executionOutcomeFailed, run.Err.Error())
+		} else {
+			execLog.add(executionTypeCollector, run.Collector.Title(), collectorSpec(run.Collector), run.StartTime, executionOutcomeSucceeded, "")
+		}
+		metadata.AddCollector(run.Collector.Title(), run.StartTime, run.EndTime, bundlePath, run.Result)
+		metadata.ContentTypes.Add(run.Collector, bundlePath, run.Result)
+		collectionErrors.Add(run.Collector.Title(), bundlePath, run.Result, run.Err)
+		for k, v := range run.Result {
 			allCollectedData[k] = v
 		}
 	}
+	metadata.SetThrottling(throttle.ThrottledRequests(), skippedCollectors)
+	metadata.SetAPIRequests(clients.Stats())
 
 	collectResult := allCollectedData
 
@@ -148,7 +224,7 @@ func runCollectors(collectors []*troubleshootv1beta2.Collect, additionalRedactor
 	}
 
 	if opts.Redact {
-		err := collect.RedactResult(bundlePath, collectResult, globalRedactors)
+		err := collect.RedactResultWithOptions(bundlePath, collectResult, metadata.ContentTypes, globalRedactors, redactOptions(additionalRedactors))
 		if err != nil {
 			err = errors.Wrap(err, "failed to redact")
 			return collectResult, err
@@ -192,7 +268,70 @@ func getVersionFile() (io.Reader, error) {
 	return bytes.NewBuffer(b), nil
 }
 
-const AnalysisFilename = "analysis.json"
+func getCollectionMetadataFile(metadata *collect.CollectionMetadata) (io.Reader, error) {
+	b, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal collection metadata")
+	}
+
+	return bytes.NewBuffer(b), nil
+}
+
+// getCollectionErrorsFile returns the error manifest of a bundle. The errors are redacted like the
+// files they were read from, as they can have the values that collectors were given in them.
+func getCollectionErrorsFile(collectionErrors collect.CollectionErrors, additionalRedactors *troubleshootv1beta2.Redactor, redactErrors bool) (io.Reader, error) {
+	b, err := collectionErrors.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	if !redactErrors {
+		return bytes.NewBuffer(b), nil
+	}
+
+	globalRedactors := []*troubleshootv1beta2.Redact{}
+	if additionalRedactors != nil {
+		globalRedactors = additionalRedactors.Spec.Redactors
+	}
+	redacted, err := redact.RedactWithOptions(bytes.NewReader(b), collect.CollectionErrorsFilename, "", globalRedactors, redactOptions(additionalRedactors))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to redact collection errors")
+	}
+	return redacted, nil
+}
+
+// redactOptions are the options of the redactors of a bundle, which exclude the default redactors that
+// its redactor spec excludes
+func redactOptions(additionalRedactors *troubleshootv1beta2.Redactor) redact.Options {
+	if additionalRedactors == nil {
+		return redact.Options{}
+	}
+	return redact.Options{ExcludeDefaultRedactors: additionalRedactors.Spec.ExcludeDefaultRedactors}
+}
+
+// RedactionsFilename and RedactionsSummaryFilename are the redaction report of a support bundle, so that
+// what was removed from the bundle can be audited
+const (
+	RedactionsFilename        = "redactions.json"
+	RedactionsSummaryFilename = "redactions.txt"
+)
+
+func getRedactionsFiles(redactions redact.RedactionList) (io.Reader, io.Reader, error) {
+	redactions.Sort()
+	b, err := json.MarshalIndent(redactions, "", "  ")
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to marshal redactions")
+	}
+
+	return bytes.NewBuffer(b), bytes.NewBufferString(redactions.Summary()), nil
+}
+
+const (
+	// AnalysisFilename has a bare list of analysis results, as bundles always have
+	AnalysisFilename = "analysis.json"
+	// VersionedAnalysisFilename has the versioned analysis results document described by
+	// schemas/analysisresults-troubleshoot-v1beta2.json
+	VersionedAnalysisFilename = "analysis-v1beta2.json"
+)
 
 func getAnalysisFile(analyzeResults []*analyze.AnalyzeResult) (io.Reader, error) {
 	data := convert.FromAnalyzerResult(analyzeResults)
@@ -203,3 +342,12 @@ func getAnalysisFile(analyzeResults []*analyze.AnalyzeResult) (io.Reader, error)
 
 	return bytes.NewBuffer(analysis), nil
 }
+
+func getVersionedAnalysisFile(analyzeResults []*analyze.AnalyzeResult) (io.Reader, error) {
+	analysis, err := convert.MarshalAnalysisResults(analyzeResults)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal analysis")
+	}
+
+	return bytes.NewBuffer(analysis), nil
+}
@@ -0,0 +1,75 @@
+package supportbundle
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	analyze "github.com/replicatedhq/troubleshoot/pkg/analyze"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"github.com/replicatedhq/troubleshoot/pkg/convert"
+	"github.com/replicatedhq/troubleshoot/pkg/redact"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_getCollectionErrorsFile(t *testing.T) {
+	collectionErrors := collect.CollectionErrors{
+		"postgres": {{Code: collect.ErrorCodeUnknown, Message: "failed to connect: Server=db;Database=app;User Id=app;Pwd=hunter2;"}},
+	}
+
+	reader, err := getCollectionErrorsFile(collectionErrors, nil, false)
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	parsed, err := collect.ParseCollectionErrors(b)
+	require.NoError(t, err)
+	assert.Equal(t, collectionErrors, parsed)
+
+	redact.ResetRedactionList()
+	defer redact.ResetRedactionList()
+
+	reader, err = getCollectionErrorsFile(collectionErrors, nil, true)
+	require.NoError(t, err)
+	b, err = ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	parsed, err = collect.ParseCollectionErrors(b)
+	require.NoError(t, err)
+	require.Len(t, parsed["postgres"], 1)
+	assert.NotContains(t, parsed["postgres"][0].Message, "hunter2")
+	assert.Contains(t, redact.GetRedactionList().ByFile, collect.CollectionErrorsFilename)
+}
+
+func Test_getCollectionErrorsFile_empty(t *testing.T) {
+	reader, err := getCollectionErrorsFile(collect.CollectionErrors{}, nil, false)
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(b))
+}
+
+func Test_getAnalysisFile(t *testing.T) {
+	analyzeResults := []*analyze.AnalyzeResult{
+		{IsFail: true, Title: "Kubernetes Version", Message: "Kubernetes 1.20 or later is required"},
+	}
+
+	reader, err := getAnalysisFile(analyzeResults)
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+
+	results := []*convert.Result{}
+	require.NoError(t, json.Unmarshal(b, &results))
+	require.Len(t, results, 1)
+	assert.Equal(t, convert.SeverityError, results[0].Severity)
+
+	reader, err = getVersionedAnalysisFile(analyzeResults)
+	require.NoError(t, err)
+	b, err = ioutil.ReadAll(reader)
+	require.NoError(t, err)
+
+	versioned, err := convert.ParseAnalysisResults(b)
+	require.NoError(t, err)
+	assert.Equal(t, convert.AnalysisAPIVersion, versioned.APIVersion)
+	assert.Equal(t, results, versioned.Results)
+}
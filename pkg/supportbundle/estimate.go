@@ -0,0 +1,90 @@
+package supportbundle
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+)
+
+// EstimateSupportBundle returns the projected size of what each of the collectors of spec that are not
+// excluded will collect, without running them, so that a spec can be trimmed before a long collection.
+// Host collectors are not estimated.
+func EstimateSupportBundle(spec *troubleshootv1beta2.SupportBundleSpec, opts SupportBundleCreateOpts) ([]collect.CollectorEstimate, error) {
+	// the same collectors as runCollectors runs
+	collectSpecs := collect.ScopeCollectorsToNodes(spec.Collectors, spec.NodeSelector)
+	collectSpecs = collect.EnsureCollectorInList(collectSpecs, troubleshootv1beta2.Collect{ClusterInfo: &troubleshootv1beta2.ClusterInfo{}})
+	collectSpecs = collect.EnsureCollectorInList(collectSpecs, troubleshootv1beta2.Collect{ClusterResources: &troubleshootv1beta2.ClusterResources{}})
+	collectSpecs = collect.EnsureClusterResourcesFirst(collectSpecs)
+
+	clients, err := collect.NewClientFactory(opts.KubernetesRestConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to instantiate Kubernetes client")
+	}
+
+	return estimateCollectors(context.Background(), collect.NewBundleEstimator(clients.Kubernetes(), opts.SinceTime), collectSpecs, opts)
+}
+
+func estimateCollectors(ctx context.Context, estimator *collect.BundleEstimator, collectSpecs []*troubleshootv1beta2.Collect, opts SupportBundleCreateOpts) ([]collect.CollectorEstimate, error) {
+	estimates := []collect.CollectorEstimate{}
+	for _, desiredCollector := range collectSpecs {
+		collectorInterface, ok := collect.GetCollector(desiredCollector, "", opts.Namespace, opts.KubernetesRestConfig, nil, opts.SinceTime)
+		if !ok {
+			continue
+		}
+		collector, ok := collectorInterface.(collect.Collector)
+		if !ok {
+			continue
+		}
+
+		isExcluded, err := collector.IsExcluded()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to check if %s is excluded", collector.Title())
+		}
+		if isExcluded {
+			continue
+		}
+
+		estimates = append(estimates, estimator.Estimate(ctx, collector.Title(), desiredCollector))
+	}
+
+	return estimates, nil
+}
+
+// WriteEstimates writes estimates as a table, with the total projected size of the bundle
+func WriteEstimates(w io.Writer, estimates []collect.CollectorEstimate) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "COLLECTOR\tOBJECTS\tSIZE\tNOTE")
+
+	var total int64
+	for _, estimate := range estimates {
+		objects, size := "-", "-"
+		if estimate.Estimated {
+			objects = fmt.Sprintf("%d", estimate.Objects)
+			size = formatBytes(estimate.Bytes)
+		}
+		total += estimate.Bytes
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", estimate.Collector, objects, size, estimate.Note)
+	}
+	fmt.Fprintf(tw, "TOTAL\t\t%s\tbefore compression, of the collectors that were estimated\n", formatBytes(total))
+
+	return tw.Flush()
+}
+
+// formatBytes formats a size in bytes with binary units, like kubectl formats quantities
+func formatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%dB", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit && exp < 4; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(b)/float64(div), "KMGTP"[exp])
+}
@@ -0,0 +1,35 @@
+package supportbundle
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteEstimates(t *testing.T) {
+	var b bytes.Buffer
+	err := WriteEstimates(&b, []collect.CollectorEstimate{
+		{Collector: "cluster-resources", Estimated: true, Objects: 1200, Bytes: 5 * 1024 * 1024},
+		{Collector: "logs/web", Estimated: true, Objects: 4, Bytes: 2048, Note: "failed to get the stats of 1 nodes"},
+		{Collector: "run-pod", Note: "not estimated, its size is only known once it has run"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `COLLECTOR          OBJECTS  SIZE    NOTE
+cluster-resources  1200     5.0MiB  
+logs/web           4        2.0KiB  failed to get the stats of 1 nodes
+run-pod            -        -       not estimated, its size is only known once it has run
+TOTAL                       5.0MiB  before compression, of the collectors that were estimated
+`, b.String())
+}
+
+func Test_formatBytes(t *testing.T) {
+	assert.Equal(t, "0B", formatBytes(0))
+	assert.Equal(t, "1023B", formatBytes(1023))
+	assert.Equal(t, "1.0KiB", formatBytes(1024))
+	assert.Equal(t, "1.5MiB", formatBytes(3*512*1024))
+	assert.Equal(t, "2.0GiB", formatBytes(2<<30))
+}
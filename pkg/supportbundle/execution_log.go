@@ -0,0 +1,321 @@
+package supportbundle
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/redact"
+	"k8s.io/client-go/rest"
+)
+
+// ExecutionLogFilename is the audit trail of a support bundle, with every collector and analyzer that was
+// run, so that what was done in a cluster can be reviewed
+const ExecutionLogFilename = "execution.log"
+
+const (
+	executionTypeCollector     = "collector"
+	executionTypeHostCollector = "hostCollector"
+	executionTypeAnalyzer      = "analyzer"
+	executionTypeHostAnalyzer  = "hostAnalyzer"
+
+	executionOutcomeSucceeded = "succeeded"
+	executionOutcomeFailed    = "failed"
+	executionOutcomeSkipped   = "skipped"
+	executionOutcomeExcluded  = "excluded"
+)
+
+// sensitiveParameterPattern matches the names of spec fields whose values are not written to the
+// execution log, as they can have credentials in them. Commands and their args are included, as a
+// command such as `sh -c "curl -u user:password ..."` has them inline.
+var sensitiveParameterPattern = regexp.MustCompile(`(?i)password|passwd|secret|token|credential|^uri$|^headers$|^body$|^data$|^env$|^command$|^args$`)
+
+// urlParameterPattern matches the names of spec fields that are urls, which are written without the
+// user and password they can have
+var urlParameterPattern = regexp.MustCompile(`(?i)^url$`)
+
+type executionLogEntry struct {
+	Time time.Time `json:"time"`
+	Type string    `json:"type"`
+	Name string    `json:"name"`
+	// Identity is who the cluster was accessed as. It is not set for host collectors and analyzers.
+	Identity   string      `json:"identity,omitempty"`
+	Parameters interface{} `json:"parameters,omitempty"`
+	Outcome    string      `json:"outcome"`
+	Reason     string      `json:"reason,omitempty"`
+}
+
+// executionLog is written as one json entry per line, in the order the collectors and analyzers
+// finished. Entries are only ever added.
+type executionLog struct {
+	mu       sync.Mutex
+	identity string
+	entries  []executionLogEntry
+}
+
+func newExecutionLog(config *rest.Config) *executionLog {
+	return &executionLog{
+		identity: executionIdentity(config),
+		entries:  []executionLogEntry{},
+	}
+}
+
+// add appends an entry for a collector or analyzer. spec is its spec, and is written without the values
+// of sensitive fields.
+func (l *executionLog) add(executionType string, name string, spec interface{}, startTime time.Time, outcome string, reason string) {
+	entry := executionLogEntry{
+		Time:       startTime,
+		Type:       executionType,
+		Name:       name,
+		Parameters: executionParameters(spec),
+		Outcome:    outcome,
+		Reason:     reason,
+	}
+	if executionType == executionTypeCollector {
+		entry.Identity = l.identity
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+}
+
+// clearTimes zeroes when the entries ran, and orders them by type and name rather than by when they
+// finished, so that the log of bundles collected from the same cluster state is the same for
+// reproducible archives
+func (l *executionLog) clearTimes() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i := range l.entries {
+		l.entries[i].Time = time.Time{}
+	}
+	sort.SliceStable(l.entries, func(i, j int) bool {
+		if l.entries[i].Type != l.entries[j].Type {
+			return l.entries[i].Type < l.entries[j].Type
+		}
+		return l.entries[i].Name < l.entries[j].Name
+	})
+}
+
+func (l *executionLog) marshal() ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, entry := range l.entries {
+		if err := encoder.Encode(entry); err != nil {
+			return nil, errors.Wrap(err, "failed to marshal execution log entry")
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// executionParameters returns spec as it is marshalled to json, with the values of sensitive fields
+// masked. Specs that can't be marshalled have no parameters.
+func executionParameters(spec interface{}) interface{} {
+	if spec == nil || (reflect.ValueOf(spec).Kind() == reflect.Ptr && reflect.ValueOf(spec).IsNil()) {
+		return nil
+	}
+
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return nil
+	}
+	var parameters interface{}
+	if err := json.Unmarshal(b, &parameters); err != nil {
+		return nil
+	}
+	return maskParameters(parameters)
+}
+
+func maskParameters(parameters interface{}) interface{} {
+	switch v := parameters.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if sensitiveParameterPattern.MatchString(key) {
+				v[key] = redact.MASK_TEXT
+			} else if s, ok := value.(string); ok && urlParameterPattern.MatchString(key) {
+				v[key] = maskURLUser(s)
+			} else {
+				v[key] = maskParameters(value)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = maskParameters(value)
+		}
+	}
+	return parameters
+}
+
+// maskURLUser returns rawURL with the user and password it has masked. Values that are not urls are
+// masked completely.
+func maskURLUser(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return redact.MASK_TEXT
+	}
+	if u.User == nil {
+		return rawURL
+	}
+	u.User = nil
+	return strings.Replace(u.String(), "//", "//"+redact.MASK_TEXT+"@", 1)
+}
+
+// getExecutionLogFile returns the execution log of a bundle. It is redacted like the collected files
+// are when redact is set, as the reasons can have the output of collectors in them.
+func getExecutionLogFile(l *executionLog, additionalRedactors *troubleshootv1beta2.Redactor, redactLog bool) ([]byte, error) {
+	b, err := l.marshal()
+	if err != nil {
+		return nil, err
+	}
+	if !redactLog {
+		return b, nil
+	}
+
+	globalRedactors := []*troubleshootv1beta2.Redact{}
+	if additionalRedactors != nil {
+		globalRedactors = additionalRedactors.Spec.Redactors
+	}
+	redacted, err := redact.RedactWithOptions(bytes.NewReader(b), ExecutionLogFilename, "", globalRedactors, redactOptions(additionalRedactors))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to redact execution log")
+	}
+	b, err = ioutil.ReadAll(redacted)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read redacted execution log")
+	}
+	return b, nil
+}
+
+// specField returns the json name and value of the field that is set in a Collect, HostCollect, Analyze
+// or HostAnalyze
+func specField(spec interface{}) (string, interface{}) {
+	reflected := reflect.ValueOf(spec)
+	if reflected.Kind() != reflect.Ptr || reflected.IsNil() {
+		return "", nil
+	}
+
+	reflected = reflected.Elem()
+	for i := 0; i < reflected.NumField(); i++ {
+		if reflected.Field(i).Kind() != reflect.Ptr || reflected.Field(i).IsNil() {
+			continue
+		}
+		name := strings.Split(reflected.Type().Field(i).Tag.Get("json"), ",")[0]
+		return name, reflected.Field(i).Interface()
+	}
+
+	return "", nil
+}
+
+// collectorSpec returns the spec of a collector, which the collectors have in their Collector field
+func collectorSpec(collector interface{}) interface{} {
+	reflected := reflect.Indirect(reflect.ValueOf(collector))
+	if reflected.Kind() != reflect.Struct {
+		return nil
+	}
+
+	field := reflected.FieldByName("Collector")
+	if !field.IsValid() || !field.CanInterface() {
+		return nil
+	}
+	return field.Interface()
+}
+
+// executionIdentity describes who the cluster is accessed as, as well as it can be told from the client
+// config without asking the API server
+func executionIdentity(config *rest.Config) string {
+	if config == nil {
+		return ""
+	}
+
+	if config.Impersonate.UserName != "" {
+		return config.Impersonate.UserName + " (impersonated)"
+	}
+	if user := certificateUser(config.TLSClientConfig); user != "" {
+		return user
+	}
+	if config.BearerToken != "" || config.BearerTokenFile != "" {
+		if subject := tokenSubject(config); subject != "" {
+			return subject
+		}
+		return "bearer token"
+	}
+	if config.Username != "" {
+		return config.Username
+	}
+	if config.ExecProvider != nil {
+		return "exec credential plugin " + config.ExecProvider.Command
+	}
+	if config.AuthProvider != nil {
+		return "auth provider " + config.AuthProvider.Name
+	}
+
+	return "anonymous"
+}
+
+// certificateUser returns the user of a client certificate, which is its common name
+func certificateUser(tlsConfig rest.TLSClientConfig) string {
+	certData := tlsConfig.CertData
+	if len(certData) == 0 && tlsConfig.CertFile != "" {
+		b, err := ioutil.ReadFile(tlsConfig.CertFile)
+		if err != nil {
+			return ""
+		}
+		certData = b
+	}
+
+	block, _ := pem.Decode(certData)
+	if block == nil {
+		return ""
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return ""
+	}
+	return cert.Subject.CommonName
+}
+
+// tokenSubject returns the subject of a bearer token that is a JWT, such as a service account token.
+// The token is not verified, the subject is only read to be recorded.
+func tokenSubject(config *rest.Config) string {
+	token := config.BearerToken
+	if token == "" {
+		b, err := ioutil.ReadFile(config.BearerTokenFile)
+		if err != nil {
+			return ""
+		}
+		token = strings.TrimSpace(string(b))
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+
+	var claims struct {
+		Subject string `json:"sub"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	return claims.Subject
+}
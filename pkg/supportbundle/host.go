@@ -0,0 +1,45 @@
+package supportbundle
+
+import (
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+)
+
+// DefaultHostCollectors are collected from every host in host mode: the cpu, memory, disk usage of /,
+// loaded kernel modules, systemd services and network interfaces
+func DefaultHostCollectors() []*troubleshootv1beta2.HostCollect {
+	return []*troubleshootv1beta2.HostCollect{
+		{CPU: &troubleshootv1beta2.CPU{}},
+		{Memory: &troubleshootv1beta2.Memory{}},
+		{DiskUsage: &troubleshootv1beta2.DiskUsage{
+			HostCollectorMeta: troubleshootv1beta2.HostCollectorMeta{CollectorName: "root"},
+			Path:              "/",
+		}},
+		{KernelModules: &troubleshootv1beta2.HostKernelModules{}},
+		{HostServices: &troubleshootv1beta2.HostServices{}},
+		{IPV4Interfaces: &troubleshootv1beta2.IPV4Interfaces{}},
+	}
+}
+
+// HostModeSpec returns the spec for a host that may not be in a cluster yet, such as before an embedded
+// cluster is installed. It has only the host collectors and host analyzers of spec, and the default host
+// collectors that spec doesn't have one of the same type of.
+func HostModeSpec(spec troubleshootv1beta2.SupportBundleSpec) troubleshootv1beta2.SupportBundleSpec {
+	spec.Collectors = nil
+	spec.Analyzers = nil
+
+	inSpec := map[string]bool{}
+	for _, hostCollector := range spec.HostCollectors {
+		name, _ := specField(hostCollector)
+		inSpec[name] = true
+	}
+
+	hostCollectors := []*troubleshootv1beta2.HostCollect{}
+	for _, hostCollector := range DefaultHostCollectors() {
+		if name, _ := specField(hostCollector); !inSpec[name] {
+			hostCollectors = append(hostCollectors, hostCollector)
+		}
+	}
+	spec.HostCollectors = append(hostCollectors, spec.HostCollectors...)
+
+	return spec
+}
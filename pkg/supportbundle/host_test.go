@@ -0,0 +1,45 @@
+package supportbundle
+
+import (
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostModeSpec(t *testing.T) {
+	spec := troubleshootv1beta2.SupportBundleSpec{
+		Collectors: []*troubleshootv1beta2.Collect{
+			{ClusterInfo: &troubleshootv1beta2.ClusterInfo{}},
+		},
+		Analyzers: []*troubleshootv1beta2.Analyze{
+			{ClusterVersion: &troubleshootv1beta2.ClusterVersion{}},
+		},
+		HostCollectors: []*troubleshootv1beta2.HostCollect{
+			{DiskUsage: &troubleshootv1beta2.DiskUsage{Path: "/var/lib/embedded-cluster"}},
+			{Time: &troubleshootv1beta2.HostTime{}},
+		},
+		HostAnalyzers: []*troubleshootv1beta2.HostAnalyze{
+			{CPU: &troubleshootv1beta2.CPUAnalyze{}},
+		},
+	}
+
+	hostSpec := HostModeSpec(spec)
+
+	assert.Nil(t, hostSpec.Collectors)
+	assert.Nil(t, hostSpec.Analyzers)
+	assert.Equal(t, spec.HostAnalyzers, hostSpec.HostAnalyzers)
+
+	names := []string{}
+	for _, hostCollector := range hostSpec.HostCollectors {
+		name, _ := specField(hostCollector)
+		names = append(names, name)
+	}
+	// the spec's disk usage is collected instead of the default one
+	assert.Equal(t, []string{"cpu", "memory", "kernelModules", "hostServices", "ipv4Interfaces", "diskUsage", "time"}, names)
+	assert.Equal(t, "/var/lib/embedded-cluster", hostSpec.HostCollectors[5].DiskUsage.Path)
+
+	// the spec that was passed is not changed
+	assert.Len(t, spec.Collectors, 1)
+	assert.Len(t, spec.HostCollectors, 2)
+}
@@ -0,0 +1,157 @@
+package supportbundle
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/troubleshoot/cmd/util"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+)
+
+// ResolveImports loads all specs referenced by the imports field of the support bundle, recursively,
+// and merges them into a single support bundle. Imported specs are merged in the order they are listed
+// and before the importing spec, so the importing spec acts as an overlay on top of its imports: its
+// collectors and analyzers come after theirs, and its settings, such as collectConcurrency, take
+// precedence over theirs. The Redactor documents of the imported specs are returned as one Redactor.
+// sourceURI is the location the support bundle was loaded from and is used to resolve relative file
+// paths and URLs. It can be empty, in which case relative imports are resolved against the working directory.
+func ResolveImports(supportBundle *troubleshootv1beta2.SupportBundle, sourceURI string) (*troubleshootv1beta2.SupportBundle, *troubleshootv1beta2.Redactor, error) {
+	r := importResolver{
+		loaded: map[string]bool{},
+	}
+
+	stack := []string{}
+	if sourceURI != "" {
+		r.loaded[sourceURI] = true
+		stack = append(stack, sourceURI)
+	}
+
+	redactor := &troubleshootv1beta2.Redactor{}
+	resolved, err := r.resolve(supportBundle, sourceURI, stack, redactor)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resolved, redactor, nil
+}
+
+type importResolver struct {
+	// loaded tracks specs that were already merged, so a spec imported from more than one place is only included once
+	loaded map[string]bool
+}
+
+// resolve merges the imports of supportBundle into it, and appends the redactors of the imports to redactor
+func (r *importResolver) resolve(supportBundle *troubleshootv1beta2.SupportBundle, sourceURI string, stack []string, redactor *troubleshootv1beta2.Redactor) (*troubleshootv1beta2.SupportBundle, error) {
+	overlay := supportBundle.DeepCopy()
+	overlay.Spec.Imports = nil
+
+	if len(supportBundle.Spec.Imports) == 0 {
+		return overlay, nil
+	}
+
+	var merged *troubleshootv1beta2.SupportBundle
+	for _, importRef := range supportBundle.Spec.Imports {
+		importURI, err := resolveImportURI(importRef, sourceURI)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to resolve import %s", importRef)
+		}
+
+		for _, uri := range stack {
+			if uri == importURI {
+				return nil, errors.Errorf("import cycle detected: %s -> %s", strings.Join(stack, " -> "), importURI)
+			}
+		}
+
+		if r.loaded[importURI] {
+			continue
+		}
+		r.loaded[importURI] = true
+
+		content, err := LoadSupportBundleSpec(importURI)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load import %s", importURI)
+		}
+
+		multidocs := strings.Split(string(content), "\n---\n")
+
+		imported, err := ParseSupportBundleDocs(multidocs, false)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse import %s", importURI)
+		}
+
+		importedRedactor, err := ParseRedactorFromSpec(multidocs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse redactors of import %s", importURI)
+		}
+		AppendRedactor(redactor, importedRedactor)
+
+		imported, err = r.resolve(imported, importURI, append(stack, importURI), redactor)
+		if err != nil {
+			return nil, err
+		}
+
+		if merged == nil {
+			merged = imported
+		} else {
+			merged = ConcatSpec(merged, imported)
+		}
+	}
+
+	if merged == nil {
+		return overlay, nil
+	}
+
+	result := ConcatSpec(merged, overlay)
+	result.TypeMeta = overlay.TypeMeta
+	result.ObjectMeta = overlay.ObjectMeta
+	result.Spec.Uri = overlay.Spec.Uri
+	if overlay.Spec.CollectConcurrency != 0 {
+		result.Spec.CollectConcurrency = overlay.Spec.CollectConcurrency
+	}
+	if overlay.Spec.NodeSelector != nil {
+		result.Spec.NodeSelector = overlay.Spec.NodeSelector
+	}
+
+	return result, nil
+}
+
+// resolveImportURI returns the location of an import. Relative file paths are resolved against the
+// directory of the importing spec, and relative references in specs loaded from a URL against that URL.
+func resolveImportURI(importRef string, sourceURI string) (string, error) {
+	if importRef == "" {
+		return "", errors.New("import must not be empty")
+	}
+
+	if isAbsoluteSpecRef(importRef) || sourceURI == "" {
+		return importRef, nil
+	}
+
+	if strings.HasPrefix(sourceURI, "secret/") || strings.HasPrefix(sourceURI, "oci://") {
+		return importRef, nil
+	}
+
+	if util.IsURL(sourceURI) {
+		base, err := url.Parse(sourceURI)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to parse %s", sourceURI)
+		}
+		ref, err := url.Parse(importRef)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to parse %s", importRef)
+		}
+		return base.ResolveReference(ref).String(), nil
+	}
+
+	return filepath.Join(filepath.Dir(sourceURI), importRef), nil
+}
+
+func isAbsoluteSpecRef(ref string) bool {
+	if filepath.IsAbs(ref) {
+		return true
+	}
+	if strings.HasPrefix(ref, "secret/") || strings.HasPrefix(ref, "oci://") {
+		return true
+	}
+	return util.IsURL(ref)
+}
@@ -0,0 +1,102 @@
+package supportbundle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ResolveImports(t *testing.T) {
+	supportBundle, err := GetSupportBundleFromURI("test/imports-overlay.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, "overlay", supportBundle.Name)
+	assert.Empty(t, supportBundle.Spec.Imports)
+
+	// base is imported twice (directly and through product) but must only be merged once
+	require.Len(t, supportBundle.Spec.Collectors, 3)
+	assert.NotNil(t, supportBundle.Spec.Collectors[0].ClusterResources)
+	assert.NotNil(t, supportBundle.Spec.Collectors[1].Secret)
+	assert.NotNil(t, supportBundle.Spec.Collectors[2].Logs)
+
+	require.Len(t, supportBundle.Spec.Analyzers, 1)
+	assert.NotNil(t, supportBundle.Spec.Analyzers[0].ClusterVersion)
+}
+
+func Test_ResolveImportsSettings(t *testing.T) {
+	content, err := LoadSupportBundleSpec("test/imports-settings.yaml")
+	require.NoError(t, err)
+	parsed, err := ParseSupportBundleDocs([]string{string(content)}, false)
+	require.NoError(t, err)
+
+	supportBundle, redactor, err := ResolveImports(parsed, "test/imports-settings.yaml")
+	require.NoError(t, err)
+
+	// the settings of the importing spec take precedence, and those it doesn't set are imported
+	assert.Equal(t, 4, supportBundle.Spec.CollectConcurrency)
+	assert.Equal(t, map[string]string{"pool": "default"}, supportBundle.Spec.NodeSelector)
+
+	// every document of the import is merged
+	require.Len(t, supportBundle.Spec.Collectors, 3)
+	assert.NotNil(t, supportBundle.Spec.Collectors[0].ClusterResources)
+	assert.NotNil(t, supportBundle.Spec.Collectors[1].ClusterInfo)
+	assert.NotNil(t, supportBundle.Spec.Collectors[2].Logs)
+	require.Len(t, supportBundle.Spec.Analyzers, 1)
+
+	require.Len(t, redactor.Spec.Redactors, 1)
+	assert.Equal(t, "replace password", redactor.Spec.Redactors[0].Name)
+}
+
+func Test_ResolveImportsCycle(t *testing.T) {
+	_, err := GetSupportBundleFromURI("test/imports-cycle-a.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "import cycle detected")
+}
+
+func Test_resolveImportURI(t *testing.T) {
+	tests := []struct {
+		name      string
+		importRef string
+		sourceURI string
+		want      string
+	}{
+		{
+			name:      "relative file",
+			importRef: "base.yaml",
+			sourceURI: "specs/overlay.yaml",
+			want:      "specs/base.yaml",
+		},
+		{
+			name:      "absolute file",
+			importRef: "/etc/troubleshoot/base.yaml",
+			sourceURI: "specs/overlay.yaml",
+			want:      "/etc/troubleshoot/base.yaml",
+		},
+		{
+			name:      "relative to url",
+			importRef: "base.yaml",
+			sourceURI: "https://example.com/specs/overlay.yaml",
+			want:      "https://example.com/specs/base.yaml",
+		},
+		{
+			name:      "oci reference",
+			importRef: "oci://registry.example.com/app/base",
+			sourceURI: "specs/overlay.yaml",
+			want:      "oci://registry.example.com/app/base",
+		},
+		{
+			name:      "no source",
+			importRef: "base.yaml",
+			sourceURI: "",
+			want:      "base.yaml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveImportURI(tt.importRef, tt.sourceURI)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
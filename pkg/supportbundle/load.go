@@ -18,6 +18,8 @@ import (
 	"github.com/replicatedhq/troubleshoot/pkg/logger"
 	"github.com/replicatedhq/troubleshoot/pkg/oci"
 	"github.com/replicatedhq/troubleshoot/pkg/specs"
+	"github.com/replicatedhq/troubleshoot/pkg/strictdecode"
+	"gopkg.in/yaml.v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -29,11 +31,17 @@ func GetSupportBundleFromURI(bundleURI string) (*troubleshootv1beta2.SupportBund
 
 	multidocs := strings.Split(string(collectorContent), "\n---\n")
 
-	supportbundle, err := ParseSupportBundle([]byte(multidocs[0]), true)
+	supportbundle, err := ParseSupportBundleDocs(multidocs, true)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to parse collector")
 	}
 
+	// like those of the spec itself, the redactors of its imports are not returned
+	supportbundle, _, err = ResolveImports(supportbundle, bundleURI)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve imports")
+	}
+
 	return supportbundle, nil
 }
 
@@ -44,7 +52,7 @@ func ParseSupportBundle(doc []byte, followURI bool) (*troubleshootv1beta2.Suppor
 	}
 
 	troubleshootclientsetscheme.AddToScheme(scheme.Scheme)
-	decode := scheme.Codecs.UniversalDeserializer().Decode
+	decode := strictdecode.NewDecoder(scheme.Codecs.UniversalDeserializer()).Decode
 
 	obj, _, err := decode(doc, nil, nil)
 	if err != nil {
@@ -100,31 +108,101 @@ func ParseSupportBundle(doc []byte, followURI bool) (*troubleshootv1beta2.Suppor
 	return nil, errors.New("spec was not parseable as a troubleshoot kind")
 }
 
+// ParseSupportBundleDocs parses the SupportBundle and Collector documents of a multi-document spec, and
+// merges them in the order they are in. The name and settings of the first document are kept, and the
+// collectors and analyzers of the documents after it are appended. Documents of other kinds, like
+// Redactors, are skipped.
+func ParseSupportBundleDocs(docs []string, followURI bool) (*troubleshootv1beta2.SupportBundle, error) {
+	var supportBundle *troubleshootv1beta2.SupportBundle
+
+	for i, doc := range docs {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+
+		var typeMeta struct {
+			Kind string `yaml:"kind"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &typeMeta); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse doc %d", i)
+		}
+		if typeMeta.Kind != "SupportBundle" && typeMeta.Kind != "Collector" {
+			continue
+		}
+
+		docBundle, err := ParseSupportBundle([]byte(doc), followURI)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse doc %d", i)
+		}
+
+		if supportBundle == nil {
+			supportBundle = docBundle
+		} else {
+			supportBundle = ConcatSpec(supportBundle, docBundle)
+		}
+	}
+
+	if supportBundle == nil {
+		return nil, errors.New("no SupportBundle or Collector documents in spec")
+	}
+
+	return supportBundle, nil
+}
+
 func ParseSupportBundleFromDoc(doc []byte) (*troubleshootv1beta2.SupportBundle, error) {
 	return ParseSupportBundle(doc, true)
 }
 
+// GetRedactorFromURI loads a redaction policy that is maintained separately from the collectors. The
+// policy can have several Redactor documents, and their redactors are returned as one Redactor.
 func GetRedactorFromURI(redactorURI string) (*troubleshootv1beta2.Redactor, error) {
-	decode := scheme.Codecs.UniversalDeserializer().Decode
-
 	redactorContent, err := LoadRedactorSpec(redactorURI)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to load redactor spec %s", redactorURI)
 	}
 
-	redactorContent, err = docrewrite.ConvertToV1Beta2(redactorContent)
+	redactor, err := ParseRedactor(redactorContent)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to convert to v1beta2")
+		return nil, errors.Wrapf(err, "failed to parse redactors %s", redactorURI)
 	}
 
-	obj, _, err := decode([]byte(redactorContent), nil, nil)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to parse redactors %s", redactorURI)
+	return redactor, nil
+}
+
+// ParseRedactor parses one or more Redactor documents into a single Redactor
+func ParseRedactor(redactorContent []byte) (*troubleshootv1beta2.Redactor, error) {
+	decode := strictdecode.NewDecoder(scheme.Codecs.UniversalDeserializer()).Decode
+
+	var redactor *troubleshootv1beta2.Redactor
+	for i, doc := range strings.Split(string(redactorContent), "\n---\n") {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+
+		v1beta2Doc, err := docrewrite.ConvertToV1Beta2([]byte(doc))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to convert to v1beta2")
+		}
+
+		obj, _, err := decode(v1beta2Doc, nil, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse doc %d", i)
+		}
+
+		docRedactor, ok := obj.(*troubleshootv1beta2.Redactor)
+		if !ok {
+			return nil, fmt.Errorf("doc %d is not a troubleshootv1beta2 redactor type", i)
+		}
+
+		if redactor == nil {
+			redactor = docRedactor
+		} else {
+			AppendRedactor(redactor, docRedactor)
+		}
 	}
 
-	redactor, ok := obj.(*troubleshootv1beta2.Redactor)
-	if !ok {
-		return nil, fmt.Errorf("%s is not a troubleshootv1beta2 redactor type", redactorURI)
+	if redactor == nil {
+		return nil, errors.New("no redactors found")
 	}
 
 	return redactor, nil
@@ -248,13 +326,32 @@ func loadSpecFromURL(arg string) ([]byte, error) {
 	}
 }
 
+// AppendRedactor appends the redactors of other to redactor, along with the default redactors it excludes
+func AppendRedactor(redactor *troubleshootv1beta2.Redactor, other *troubleshootv1beta2.Redactor) {
+	if other == nil {
+		return
+	}
+	redactor.Spec.Redactors = append(redactor.Spec.Redactors, other.Spec.Redactors...)
+	redactor.Spec.ExcludeDefaultRedactors = append(redactor.Spec.ExcludeDefaultRedactors, other.Spec.ExcludeDefaultRedactors...)
+}
+
 func ParseRedactorsFromSpec(docs []string) ([]*troubleshootv1beta2.Redact, error) {
-	var redactors []*troubleshootv1beta2.Redact
+	redactor, err := ParseRedactorFromSpec(docs)
+	if err != nil {
+		return nil, err
+	}
+	return redactor.Spec.Redactors, nil
+}
+
+// ParseRedactorFromSpec parses the Redactor documents of docs into a single Redactor, skipping documents
+// of other kinds
+func ParseRedactorFromSpec(docs []string) (*troubleshootv1beta2.Redactor, error) {
+	redactor := &troubleshootv1beta2.Redactor{}
 
-	decode := scheme.Codecs.UniversalDeserializer().Decode
+	decode := strictdecode.NewDecoder(scheme.Codecs.UniversalDeserializer()).Decode
 
 	for i, additionalDoc := range docs {
-		if i == 0 {
+		if strings.TrimSpace(additionalDoc) == "" {
 			continue
 		}
 		additionalDoc, err := docrewrite.ConvertToV1Beta2([]byte(additionalDoc))
@@ -269,8 +366,8 @@ func ParseRedactorsFromSpec(docs []string) ([]*troubleshootv1beta2.Redact, error
 		if !ok {
 			continue
 		}
-		redactors = append(redactors, multidocRedactors.Spec.Redactors...)
+		AppendRedactor(redactor, multidocRedactors)
 	}
 
-	return redactors, nil
+	return redactor, nil
 }
@@ -0,0 +1,117 @@
+package supportbundle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseRedactor(t *testing.T) {
+	req := require.New(t)
+
+	redactor, err := ParseRedactor([]byte(`apiVersion: troubleshoot.sh/v1beta2
+kind: Redactor
+metadata:
+  name: credentials
+spec:
+  excludeDefaultRedactors:
+  - ipv4
+  redactors:
+  - name: bearer tokens
+    removals:
+      regex:
+      - redactor: '(Bearer )(?P<mask>.*)'
+---
+apiVersion: troubleshoot.replicated.com/v1beta1
+kind: Redactor
+metadata:
+  name: network
+spec:
+  redactors:
+  - name: internal hostnames
+    removals:
+      values:
+      - db.corp.example.com
+`))
+	req.NoError(err)
+	req.Len(redactor.Spec.Redactors, 2)
+	assert.Equal(t, "bearer tokens", redactor.Spec.Redactors[0].Name)
+	assert.Equal(t, "internal hostnames", redactor.Spec.Redactors[1].Name)
+	assert.Equal(t, []string{"ipv4"}, redactor.Spec.ExcludeDefaultRedactors)
+
+	_, err = ParseRedactor([]byte(`apiVersion: troubleshoot.sh/v1beta2
+kind: Redactor
+metadata:
+  name: credentials
+---
+apiVersion: troubleshoot.sh/v1beta2
+kind: Collector
+metadata:
+  name: collectors
+`))
+	req.Error(err)
+	assert.Contains(t, err.Error(), "doc 1 is not a troubleshootv1beta2 redactor type")
+
+	_, err = ParseRedactor([]byte("\n"))
+	req.Error(err)
+}
+
+func Test_ParseSupportBundleDocs(t *testing.T) {
+	req := require.New(t)
+
+	supportBundle, err := ParseSupportBundleDocs([]string{
+		`apiVersion: troubleshoot.sh/v1beta2
+kind: SupportBundle
+metadata:
+  name: base
+spec:
+  collectConcurrency: 2
+  collectors:
+  - clusterInfo: {}
+  analyzers:
+  - clusterVersion: {}`,
+		`apiVersion: troubleshoot.sh/v1beta2
+kind: Redactor
+metadata:
+  name: redactors
+spec:
+  redactors:
+  - name: passwords
+    removals:
+      values:
+      - hunter2`,
+		"",
+		`apiVersion: troubleshoot.replicated.com/v1beta1
+kind: Collector
+metadata:
+  name: app
+spec:
+  collectors:
+  - clusterResources: {}`,
+		`apiVersion: troubleshoot.sh/v1beta2
+kind: SupportBundle
+metadata:
+  name: app-analyzers
+spec:
+  collectConcurrency: 4
+  analyzers:
+  - nodeResources: {}`,
+	}, false)
+	req.NoError(err)
+
+	assert.Equal(t, "base", supportBundle.Name)
+	assert.Equal(t, 2, supportBundle.Spec.CollectConcurrency)
+	req.Len(supportBundle.Spec.Collectors, 2)
+	assert.NotNil(t, supportBundle.Spec.Collectors[0].ClusterInfo)
+	assert.NotNil(t, supportBundle.Spec.Collectors[1].ClusterResources)
+	req.Len(supportBundle.Spec.Analyzers, 2)
+	assert.NotNil(t, supportBundle.Spec.Analyzers[0].ClusterVersion)
+	assert.NotNil(t, supportBundle.Spec.Analyzers[1].NodeResources)
+
+	_, err = ParseSupportBundleDocs([]string{`apiVersion: troubleshoot.sh/v1beta2
+kind: Redactor
+metadata:
+  name: redactors`}, false)
+	req.Error(err)
+}
@@ -0,0 +1,100 @@
+package supportbundle
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+)
+
+// fileScope returns the namespace of a file in a support bundle, or clusterScoped if the file holds
+// cluster scoped data such as nodes. Files that are neither, such as the output of collectors that can
+// read from any namespace but don't include the namespace in their paths, return false for both.
+func fileScope(relativePath string) (namespace string, clusterScoped bool) {
+	parts := strings.Split(filepath.ToSlash(relativePath), "/")
+
+	switch parts[0] {
+	case VersionFilename:
+		return "", true
+
+	case "cluster-info":
+		return "", true
+
+	case "cluster-resources":
+		switch {
+		case len(parts) == 2:
+			// cluster-resources/nodes.json
+			return "", true
+		case len(parts) > 4 && parts[1] == "pods" && parts[2] == "logs":
+			// cluster-resources/pods/logs/<namespace>/<pod>/<container>.log
+			return parts[3], false
+		case len(parts) == 4 && parts[1] == "custom-resources":
+			// cluster-resources/custom-resources/<resource>/<namespace>.yaml
+			return strings.TrimSuffix(parts[3], filepath.Ext(parts[3])), false
+		case len(parts) == 3 && parts[1] == "custom-resources":
+			// cluster-resources/custom-resources/<resource>.yaml
+			return "", true
+		case len(parts) == 3:
+			// cluster-resources/<resource>/<namespace>.json
+			return strings.TrimSuffix(parts[2], filepath.Ext(parts[2])), false
+		}
+
+	case "secrets", "secrets-errors", "configmaps", "configmaps-errors":
+		// secrets/<namespace>/<name>.json
+		if len(parts) > 2 {
+			return parts[1], false
+		}
+	}
+
+	return "", false
+}
+
+// namespaceResult returns the files of result that belong in the bundle of namespace, which are the
+// namespace's own files and the cluster scoped files
+func namespaceResult(result collect.CollectorResult, namespace string) collect.CollectorResult {
+	namespaceResult := collect.NewResult()
+	for relativePath, data := range result {
+		fileNamespace, clusterScoped := fileScope(relativePath)
+		if clusterScoped || fileNamespace == namespace {
+			namespaceResult[relativePath] = data
+		}
+	}
+	return namespaceResult
+}
+
+// writeNamespaceBundles writes an archive for each namespace next to the support bundle archive, or to
+// the sink if there is one. basename is the path of the support bundle archive without its extension,
+// which the namespace archives have too.
+func writeNamespaceBundles(namespaces []string, basename string, extension string, bundlePath string, result collect.CollectorResult, opts SupportBundleCreateOpts) (map[string]string, error) {
+	archivePaths := map[string]string{}
+	for _, namespace := range namespaces {
+		if namespace == "" || strings.ContainsAny(namespace, `/\`) {
+			return nil, errors.Errorf("invalid namespace %q", namespace)
+		}
+
+		namespaceBasename := fmt.Sprintf("%s-%s", basename, namespace)
+		files := namespaceResult(result, namespace)
+
+		if opts.Sink != nil {
+			location, err := writeToSink(opts.Sink, filepath.Base(namespaceBasename)+".tar.gz", bundlePath, files, opts.archiveOptions())
+			if err != nil {
+				return nil, errors.Wrapf(err, "write bundle for namespace %s to sink", namespace)
+			}
+			archivePaths[namespace] = location
+			continue
+		}
+
+		filename, err := findFileName(namespaceBasename, extension)
+		if err != nil {
+			return nil, errors.Wrap(err, "find file name")
+		}
+		if err := archiveSupportBundleDir(bundlePath, files, filename, opts); err != nil {
+			return nil, errors.Wrapf(err, "create bundle file for namespace %s", namespace)
+		}
+		archivePaths[namespace] = filename
+	}
+
+	return archivePaths, nil
+}
@@ -0,0 +1,87 @@
+package supportbundle
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_fileScope(t *testing.T) {
+	tests := []struct {
+		path              string
+		wantNamespace     string
+		wantClusterScoped bool
+	}{
+		{path: "version.yaml", wantClusterScoped: true},
+		{path: "cluster-info/cluster_version.json", wantClusterScoped: true},
+		{path: "cluster-resources/nodes.json", wantClusterScoped: true},
+		{path: "cluster-resources/pods-errors.json", wantClusterScoped: true},
+		{path: "cluster-resources/custom-resources/routes.route.openshift.io.yaml", wantClusterScoped: true},
+		{path: "cluster-resources/pods/default.json", wantNamespace: "default"},
+		{path: "cluster-resources/auth-cani-list/tenant-a.json", wantNamespace: "tenant-a"},
+		{path: "cluster-resources/pods/logs/tenant-a/web/nginx.log", wantNamespace: "tenant-a"},
+		{path: "cluster-resources/custom-resources/routes.route.openshift.io/tenant-a.yaml", wantNamespace: "tenant-a"},
+		{path: "secrets/tenant-a/db/password.json", wantNamespace: "tenant-a"},
+		{path: "configmaps-errors/tenant-a/app.json", wantNamespace: "tenant-a"},
+		{path: "analysis.json"},
+		{path: "collection-metadata.json"},
+		{path: "my-logs/web/nginx.log"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			namespace, clusterScoped := fileScope(tt.path)
+			assert.Equal(t, tt.wantNamespace, namespace)
+			assert.Equal(t, tt.wantClusterScoped, clusterScoped)
+		})
+	}
+}
+
+func Test_writeNamespaceBundles(t *testing.T) {
+	req := require.New(t)
+
+	bundlePath, result := testBundle(t)
+	for _, name := range []string{
+		"cluster-resources/nodes.json",
+		"cluster-resources/pods/tenant-a.json",
+		"cluster-resources/pods/tenant-b.json",
+		"cluster-resources/pods/logs/tenant-a/web/nginx.log",
+		"my-logs/web/nginx.log",
+		"analysis.json",
+	} {
+		req.NoError(result.SaveResult(bundlePath, name, bytes.NewBufferString(name)))
+	}
+
+	basename := filepath.Join(t.TempDir(), "support-bundle")
+	archivePaths, err := writeNamespaceBundles([]string{"tenant-a", "tenant-b"}, basename, "tar.gz", bundlePath, result, SupportBundleCreateOpts{})
+	req.NoError(err)
+	req.Equal(map[string]string{
+		"tenant-a": basename + "-tenant-a.tar.gz",
+		"tenant-b": basename + "-tenant-b.tar.gz",
+	}, archivePaths)
+
+	archive, err := ioutil.ReadFile(archivePaths["tenant-a"])
+	req.NoError(err)
+	req.Equal(map[string]string{
+		"support-bundle/version.yaml":                                       "apiVersion: troubleshoot.sh/v1beta2\n",
+		"support-bundle/cluster-resources/nodes.json":                       "cluster-resources/nodes.json",
+		"support-bundle/cluster-resources/pods/tenant-a.json":               "cluster-resources/pods/tenant-a.json",
+		"support-bundle/cluster-resources/pods/logs/tenant-a/web/nginx.log": "cluster-resources/pods/logs/tenant-a/web/nginx.log",
+	}, archiveFiles(t, archive))
+
+	sink := &MemorySink{}
+	archivePaths, err = writeNamespaceBundles([]string{"tenant-b"}, basename, "tar.gz", bundlePath, result, SupportBundleCreateOpts{Sink: sink})
+	req.NoError(err)
+	req.Equal(map[string]string{"tenant-b": "support-bundle-tenant-b.tar.gz"}, archivePaths)
+	req.Equal(map[string]string{
+		"support-bundle/version.yaml":                         "apiVersion: troubleshoot.sh/v1beta2\n",
+		"support-bundle/cluster-resources/nodes.json":         "cluster-resources/nodes.json",
+		"support-bundle/cluster-resources/pods/tenant-b.json": "cluster-resources/pods/tenant-b.json",
+	}, archiveFiles(t, sink.Archive.Bytes()))
+
+	_, err = writeNamespaceBundles([]string{"../tenant-a"}, basename, "tar.gz", bundlePath, result, SupportBundleCreateOpts{})
+	req.Error(err)
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/mholt/archiver/v3"
 	"github.com/pkg/errors"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
 	types "github.com/replicatedhq/troubleshoot/pkg/supportbundle/types"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -100,6 +101,22 @@ func getPodDetailsFromFiles(files map[string][]byte, podNamespace string, podNam
 	return &podDetails, nil
 }
 
+// GetCollectionErrors returns the error manifest of a support bundle archive, with the errors of the
+// collectors that failed or collected only some of their data. Bundles collected by older versions have
+// no manifest, and have no collection errors.
+func GetCollectionErrors(bundleArchive string) (collect.CollectionErrors, error) {
+	files, err := GetFilesContents(bundleArchive, []string{collect.CollectionErrorsFilename})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get files contents")
+	}
+
+	b, ok := files[collect.CollectionErrorsFilename]
+	if !ok {
+		return collect.CollectionErrors{}, nil
+	}
+	return collect.ParseCollectionErrors(b)
+}
+
 // GetFilesContents will return the file contents for filenames matching the filenames parameter.
 func GetFilesContents(bundleArchive string, filenames []string) (map[string][]byte, error) {
 	bundleDir, err := ioutil.TempDir("", "troubleshoot")
@@ -0,0 +1,61 @@
+package supportbundle
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+)
+
+// collectProgress sends collect.CollectProgress for a run of collectors, with the same statuses as
+// remote collection. Collectors that run at the same time can report to it.
+type collectProgress struct {
+	mu           sync.Mutex
+	progressChan chan interface{}
+	bundlePath   string
+	total        int
+	completed    int
+}
+
+func newCollectProgress(progressChan chan interface{}, bundlePath string, total int) *collectProgress {
+	return &collectProgress{
+		progressChan: progressChan,
+		bundlePath:   bundlePath,
+		total:        total,
+	}
+}
+
+func (p *collectProgress) started(name string) {
+	p.mu.Lock()
+	completed := p.completed
+	p.mu.Unlock()
+
+	p.progressChan <- collect.CollectProgress{
+		CurrentName:    name,
+		CurrentStatus:  "running",
+		CompletedCount: completed,
+		TotalCount:     p.total,
+	}
+}
+
+func (p *collectProgress) finished(name string, result collect.CollectorResult, err error) {
+	p.mu.Lock()
+	p.completed++
+	completed := p.completed
+	p.mu.Unlock()
+
+	event := collect.CollectProgress{
+		CurrentName:    name,
+		CurrentStatus:  "completed",
+		CompletedCount: completed,
+		TotalCount:     p.total,
+		BytesCollected: result.Size(p.bundlePath),
+	}
+	if errors.Is(err, collect.ErrThrottled) {
+		event.CurrentStatus = "skipped"
+	} else if err != nil {
+		event.CurrentStatus = "failed"
+		event.Err = err
+	}
+	p.progressChan <- event
+}
@@ -0,0 +1,50 @@
+package supportbundle
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_collectProgress(t *testing.T) {
+	progressChan := make(chan interface{}, 10)
+	progress := newCollectProgress(progressChan, "", 3)
+
+	progress.started("cluster-info")
+	progress.finished("cluster-info", collect.CollectorResult{"cluster-info/cluster_version.json": []byte("v1.25.3")}, nil)
+	progress.started("logs")
+	progress.finished("logs", nil, errors.New("timed out"))
+	progress.finished("exec", nil, collect.ErrThrottled)
+	close(progressChan)
+
+	events := []collect.CollectProgress{}
+	for msg := range progressChan {
+		event, ok := msg.(collect.CollectProgress)
+		require.True(t, ok)
+		events = append(events, event)
+	}
+	require.Len(t, events, 5)
+
+	assert.Equal(t, "running", events[0].CurrentStatus)
+	assert.Equal(t, "cluster-info", events[0].CurrentName)
+	assert.Equal(t, 0, events[0].CompletedCount)
+	assert.Equal(t, 3, events[0].TotalCount)
+
+	assert.Equal(t, "completed", events[1].CurrentStatus)
+	assert.Equal(t, 1, events[1].CompletedCount)
+	assert.Equal(t, int64(len("v1.25.3")), events[1].BytesCollected)
+
+	assert.Equal(t, "running", events[2].CurrentStatus)
+	assert.Equal(t, 1, events[2].CompletedCount)
+
+	assert.Equal(t, "failed", events[3].CurrentStatus)
+	assert.Equal(t, 2, events[3].CompletedCount)
+	assert.EqualError(t, events[3].Err, "timed out")
+
+	assert.Equal(t, "skipped", events[4].CurrentStatus)
+	assert.Equal(t, 3, events[4].CompletedCount)
+	assert.NoError(t, events[4].Err)
+}
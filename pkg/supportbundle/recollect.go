@@ -0,0 +1,245 @@
+package supportbundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"github.com/replicatedhq/troubleshoot/pkg/redact"
+)
+
+// RecollectBundle runs the collectors of spec with the given titles again, such as logs/my-app, and
+// writes a copy of the extracted support bundle in rootDir to outputFilename with their files replaced by
+// what they collect now, so that a stale file can be refreshed without collecting the whole bundle again.
+// rootDir is not modified.
+//
+// The files the collectors collected before are removed when the bundle's collection metadata lists them,
+// which it does for bundles collected by this version onwards, and are otherwise overwritten. The
+// collection metadata, error manifest, execution log and redaction report are updated for the collectors
+// that were run again. The analysis is not run again.
+func RecollectBundle(rootDir string, spec *troubleshootv1beta2.SupportBundleSpec, additionalRedactors *troubleshootv1beta2.Redactor, titles []string, outputFilename string, opts SupportBundleCreateOpts) (*SupportBundleResponse, error) {
+	if opts.KubernetesRestConfig == nil {
+		return nil, errors.New("did not receive kube rest config")
+	}
+	if opts.ProgressChan == nil {
+		return nil, errors.New("did not receive collector progress chan")
+	}
+	if len(titles) == 0 {
+		return nil, errors.New("no collectors to run again")
+	}
+	if opts.Redact {
+		if err := redact.ValidateDefaultRedactorGroups(redactOptions(additionalRedactors).ExcludeDefaultRedactors); err != nil {
+			return nil, errors.Wrap(err, "failed to exclude default redactors")
+		}
+	}
+
+	tmpDir, err := ioutil.TempDir("", "troubleshoot-recollect-")
+	if err != nil {
+		return nil, errors.Wrap(err, "create temp dir")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	extension := archiveExtension(outputFilename)
+	bundlePath := filepath.Join(tmpDir, strings.TrimSuffix(filepath.Base(outputFilename), "."+extension))
+
+	// the reports of the bundle are read to be updated rather than copied
+	result := collect.NewResult()
+	reports := map[string][]byte{}
+	err = filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(rootDir, path)
+		if err != nil {
+			return errors.Wrap(err, "failed to get relative path")
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		switch relPath {
+		case collect.ManifestFilename:
+			return nil
+		case collect.CollectionMetadataFilename, collect.CollectionErrorsFilename, ExecutionLogFilename, RedactionsFilename, RedactionsSummaryFilename:
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return errors.Wrapf(err, "failed to read %s", relPath)
+			}
+			reports[relPath] = b
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open %s", relPath)
+		}
+		defer f.Close()
+
+		return result.SaveResult(bundlePath, relPath, f)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read bundle")
+	}
+
+	metadata := collect.NewCollectionMetadata(time.Now())
+	if b, ok := reports[collect.CollectionMetadataFilename]; ok {
+		if err := json.Unmarshal(b, metadata); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal collection metadata")
+		}
+		if metadata.ContentTypes == nil {
+			metadata.ContentTypes = collect.ContentTypes{}
+		}
+	}
+
+	collectionErrors := collect.CollectionErrors{}
+	if b, ok := reports[collect.CollectionErrorsFilename]; ok {
+		collectionErrors, err = collect.ParseCollectionErrors(b)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	clients, err := collect.NewClientFactory(opts.KubernetesRestConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to instantiate Kubernetes client")
+	}
+
+	wanted := map[string]bool{}
+	for _, title := range titles {
+		wanted[title] = true
+	}
+	found := map[string]bool{}
+	collectors := []collect.Collector{}
+	for _, desiredCollector := range collect.ScopeCollectorsToNodes(spec.Collectors, spec.NodeSelector) {
+		collectorInterface, ok := collect.GetCollectorWithClients(desiredCollector, bundlePath, opts.Namespace, clients, opts.SinceTime)
+		if !ok {
+			continue
+		}
+		collector, ok := collectorInterface.(collect.Collector)
+		if !ok || !wanted[collector.Title()] {
+			continue
+		}
+		found[collector.Title()] = true
+		collectors = append(collectors, collector)
+	}
+	for _, title := range titles {
+		if !found[title] {
+			return nil, errors.Errorf("collector %s is not in the support bundle specs", title)
+		}
+	}
+
+	for title := range found {
+		for _, name := range metadata.CollectorFiles(title) {
+			delete(result, name)
+			if err := os.Remove(filepath.Join(bundlePath, name)); err != nil && !os.IsNotExist(err) {
+				return nil, errors.Wrapf(err, "failed to remove %s", name)
+			}
+		}
+		delete(collectionErrors, title)
+	}
+
+	execLog := newExecutionLog(opts.KubernetesRestConfig)
+	recollected := collect.NewResult()
+	runs := collect.RunCollectorsWithOptions(context.Background(), collectors, opts.ProgressChan, collect.RunOptions{
+		Concurrency: opts.CollectConcurrency,
+		BeforeRun: func(collector collect.Collector) {
+			opts.ProgressChan <- fmt.Sprintf("[%s] Running collector again...", collector.Title())
+		},
+	})
+	for _, run := range runs {
+		if run.Err != nil {
+			opts.ProgressChan <- errors.Errorf("failed to run collector: %s: %v", run.Collector.Title(), run.Err)
+			execLog.add(executionTypeCollector, run.Collector.Title(), collectorSpec(run.Collector), run.StartTime, executionOutcomeFailed, run.Err.Error())
+		} else {
+			execLog.add(executionTypeCollector, run.Collector.Title(), collectorSpec(run.Collector), run.StartTime, executionOutcomeSucceeded, "")
+		}
+		metadata.ReplaceCollector(run.Collector.Title(), run.StartTime, run.EndTime, bundlePath, run.Result)
+		metadata.ContentTypes.Add(run.Collector, bundlePath, run.Result)
+		collectionErrors.Add(run.Collector.Title(), bundlePath, run.Result, run.Err)
+		for k, v := range run.Result {
+			result[k] = v
+			recollected[k] = v
+		}
+	}
+
+	if opts.Redact {
+		globalRedactors := []*troubleshootv1beta2.Redact{}
+		if additionalRedactors != nil {
+			globalRedactors = additionalRedactors.Spec.Redactors
+		}
+
+		defer redact.ReleaseRedactors(globalRedactors)
+
+		redact.ResetRedactionList()
+		if err := collect.RedactResultWithOptions(bundlePath, recollected, metadata.ContentTypes, globalRedactors, redactOptions(additionalRedactors)); err != nil {
+			return nil, errors.Wrap(err, "failed to redact")
+		}
+		if err := addToRedactionReport(bundlePath, result, reports[RedactionsFilename], redact.GetRedactionList()); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, name := range []string{RedactionsFilename, RedactionsSummaryFilename} {
+			if b, ok := reports[name]; ok {
+				if err := result.SaveResult(bundlePath, name, bytes.NewReader(b)); err != nil {
+					return nil, errors.Wrapf(err, "failed to write %s", name)
+				}
+			}
+		}
+	}
+
+	collectionErrorsFile, err := getCollectionErrorsFile(collectionErrors, additionalRedactors, opts.Redact)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get collection errors file")
+	}
+	if err := result.SaveResult(bundlePath, collect.CollectionErrorsFilename, collectionErrorsFile); err != nil {
+		return nil, errors.Wrap(err, "failed to write collection errors")
+	}
+
+	if opts.Reproducible {
+		metadata.ClearTimes()
+	}
+	collectionMetadata, err := getCollectionMetadataFile(metadata)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get collection metadata file")
+	}
+	if err := result.SaveResult(bundlePath, collect.CollectionMetadataFilename, collectionMetadata); err != nil {
+		return nil, errors.Wrap(err, "failed to write collection metadata")
+	}
+
+	// the runs are added to the end of the execution log, which entries are only ever added to
+	if opts.Reproducible {
+		execLog.clearTimes()
+	}
+	executionLogData, err := getExecutionLogFile(execLog, additionalRedactors, opts.Redact)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get execution log")
+	}
+	previous := reports[ExecutionLogFilename]
+	if len(previous) > 0 && !bytes.HasSuffix(previous, []byte("\n")) {
+		previous = append(previous, '\n')
+	}
+	executionLogData = append(previous, executionLogData...)
+	if err := result.SaveResult(bundlePath, ExecutionLogFilename, bytes.NewReader(executionLogData)); err != nil {
+		return nil, errors.Wrap(err, "failed to write execution log")
+	}
+
+	if err := archiveSupportBundleDir(bundlePath, result, outputFilename, opts); err != nil {
+		return nil, errors.Wrap(err, "create bundle file")
+	}
+
+	return &SupportBundleResponse{
+		ArchivePath:      outputFilename,
+		CollectionErrors: collectionErrors,
+	}, nil
+}
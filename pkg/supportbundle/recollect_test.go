@@ -0,0 +1,94 @@
+package supportbundle
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+func TestRecollectBundle(t *testing.T) {
+	req := require.New(t)
+
+	metadata := collect.NewCollectionMetadata(time.Now())
+	metadata.Collectors = []collect.CollectorMetadata{
+		{Title: "data/config.txt", Files: []string{"app/config.txt", "app/stale.txt"}},
+		{Title: "data/keep.txt", Files: []string{"other/keep.txt"}},
+	}
+	metadataFile, err := json.Marshal(metadata)
+	req.NoError(err)
+
+	rootDir := t.TempDir()
+	files := map[string]string{
+		"version.yaml":                     "apiVersion: troubleshoot.sh/v1beta2\n",
+		"app/config.txt":                   "old",
+		"app/stale.txt":                    "stale",
+		"other/keep.txt":                   "keep",
+		collect.CollectionMetadataFilename: string(metadataFile),
+		ExecutionLogFilename:               `{"type":"collector","name":"data/config.txt"}` + "\n",
+		"manifest.json":                    `{"files": []}`,
+	}
+	for name, contents := range files {
+		path := filepath.Join(rootDir, name)
+		req.NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		req.NoError(ioutil.WriteFile(path, []byte(contents), 0644))
+	}
+
+	spec := &troubleshootv1beta2.SupportBundleSpec{
+		Collectors: []*troubleshootv1beta2.Collect{
+			{Data: &troubleshootv1beta2.Data{CollectorMeta: troubleshootv1beta2.CollectorMeta{CollectorName: "config.txt"}, Name: "app", Data: "new"}},
+			{Data: &troubleshootv1beta2.Data{CollectorMeta: troubleshootv1beta2.CollectorMeta{CollectorName: "keep.txt"}, Name: "other", Data: "changed"}},
+		},
+	}
+
+	progressChan := make(chan interface{})
+	go func() {
+		for range progressChan {
+		}
+	}()
+	defer close(progressChan)
+
+	outputFilename := filepath.Join(t.TempDir(), "support-bundle-recollected.tar.gz")
+	response, err := RecollectBundle(rootDir, spec, nil, []string{"data/config.txt"}, outputFilename, SupportBundleCreateOpts{
+		KubernetesRestConfig: &rest.Config{Host: "https://localhost:6443"},
+		ProgressChan:         progressChan,
+	})
+	req.NoError(err)
+	assert.Equal(t, outputFilename, response.ArchivePath)
+
+	archive, err := ioutil.ReadFile(outputFilename)
+	req.NoError(err)
+	archived := archiveFiles(t, archive)
+	assert.Equal(t, "new", archived["support-bundle-recollected/app/config.txt"])
+	assert.Equal(t, "keep", archived["support-bundle-recollected/other/keep.txt"])
+	assert.NotContains(t, archived, "support-bundle-recollected/app/stale.txt")
+
+	got := collect.CollectionMetadata{}
+	req.NoError(json.Unmarshal([]byte(archived["support-bundle-recollected/"+collect.CollectionMetadataFilename]), &got))
+	req.Len(got.Collectors, 2)
+	assert.Equal(t, "data/keep.txt", got.Collectors[0].Title)
+	assert.Equal(t, "data/config.txt", got.Collectors[1].Title)
+	assert.Equal(t, []string{"app/config.txt"}, got.Collectors[1].Files)
+
+	assert.Contains(t, archived["support-bundle-recollected/"+ExecutionLogFilename], files[ExecutionLogFilename])
+	assert.Greater(t, len(archived["support-bundle-recollected/"+ExecutionLogFilename]), len(files[ExecutionLogFilename]))
+}
+
+func TestRecollectBundle_unknownCollector(t *testing.T) {
+	progressChan := make(chan interface{})
+	defer close(progressChan)
+
+	_, err := RecollectBundle(t.TempDir(), &troubleshootv1beta2.SupportBundleSpec{}, nil, []string{"logs/my-app"}, filepath.Join(t.TempDir(), "out.tar.gz"), SupportBundleCreateOpts{
+		KubernetesRestConfig: &rest.Config{Host: "https://localhost:6443"},
+		ProgressChan:         progressChan,
+	})
+	require.EqualError(t, err, "collector logs/my-app is not in the support bundle specs")
+}
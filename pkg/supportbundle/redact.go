@@ -0,0 +1,136 @@
+package supportbundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"github.com/replicatedhq/troubleshoot/pkg/redact"
+)
+
+// RedactBundle applies the default redactors that opts do not exclude and the additional redactors to an
+// extracted support bundle in rootDir and writes the redacted bundle to a new archive, for bundles that were
+// collected before a secret was known to need redacting. rootDir is not modified. The redactions that were
+// made are returned, and are added to the bundle's redaction report.
+func RedactBundle(rootDir string, additionalRedactors []*troubleshootv1beta2.Redact, outputFilename string, opts redact.Options) (redact.RedactionList, error) {
+	if err := redact.ValidateDefaultRedactorGroups(opts.ExcludeDefaultRedactors); err != nil {
+		return redact.RedactionList{}, err
+	}
+	defer redact.ReleaseRedactors(additionalRedactors)
+
+	tmpDir, err := ioutil.TempDir("", "troubleshoot-redact-")
+	if err != nil {
+		return redact.RedactionList{}, errors.Wrap(err, "create temp dir")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	name := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(outputFilename), ".tar.gz"), ".tgz")
+	bundlePath := filepath.Join(tmpDir, name)
+
+	// the redaction report and the manifest are rewritten for the redacted files, and the collection
+	// metadata is kept as it is, so they are not redacted
+	result := collect.NewResult()
+	reports := map[string][]byte{}
+	err = filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(rootDir, path)
+		if err != nil {
+			return errors.Wrap(err, "failed to get relative path")
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		switch relPath {
+		case collect.ManifestFilename:
+			return nil
+		case RedactionsFilename, RedactionsSummaryFilename, collect.CollectionMetadataFilename:
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return errors.Wrapf(err, "failed to read %s", relPath)
+			}
+			reports[relPath] = b
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open %s", relPath)
+		}
+		defer f.Close()
+
+		return result.SaveResult(bundlePath, relPath, f)
+	})
+	if err != nil {
+		return redact.RedactionList{}, errors.Wrap(err, "failed to read bundle")
+	}
+
+	var contentTypes collect.ContentTypes
+	if metadata, ok := reports[collect.CollectionMetadataFilename]; ok {
+		contentTypes = collect.ContentTypesFromMetadata(metadata)
+	}
+
+	redact.ResetRedactionList()
+	if err := collect.RedactResultWithOptions(bundlePath, result, contentTypes, additionalRedactors, opts); err != nil {
+		return redact.RedactionList{}, errors.Wrap(err, "failed to redact bundle")
+	}
+	redactions := redact.GetRedactionList()
+
+	if metadata, ok := reports[collect.CollectionMetadataFilename]; ok {
+		if err := result.SaveResult(bundlePath, collect.CollectionMetadataFilename, bytes.NewReader(metadata)); err != nil {
+			return redact.RedactionList{}, errors.Wrap(err, "failed to write collection metadata")
+		}
+	}
+
+	if err := addToRedactionReport(bundlePath, result, reports[RedactionsFilename], redactions); err != nil {
+		return redact.RedactionList{}, err
+	}
+
+	if err := collect.TarSupportBundleDir(bundlePath, result, outputFilename); err != nil {
+		return redact.RedactionList{}, errors.Wrap(err, "create bundle file")
+	}
+
+	return redactions, nil
+}
+
+// addToRedactionReport writes the redaction report of a bundle, which was report before, with redactions
+// added to it. report is empty for bundles that were collected without redactions.
+func addToRedactionReport(bundlePath string, result collect.CollectorResult, report []byte, redactions redact.RedactionList) error {
+	list := redact.RedactionList{
+		ByRedactor: map[string][]redact.Redaction{},
+		ByFile:     map[string][]redact.Redaction{},
+	}
+	if len(report) > 0 {
+		if err := json.Unmarshal(report, &list); err != nil {
+			return errors.Wrap(err, "failed to unmarshal redactions")
+		}
+	}
+	for name, r := range redactions.ByRedactor {
+		list.ByRedactor[name] = append(list.ByRedactor[name], r...)
+	}
+	for name, r := range redactions.ByFile {
+		list.ByFile[name] = append(list.ByFile[name], r...)
+	}
+
+	reportFile, summaryFile, err := getRedactionsFiles(list)
+	if err != nil {
+		return errors.Wrap(err, "failed to get redactions files")
+	}
+	if err := result.SaveResult(bundlePath, RedactionsFilename, reportFile); err != nil {
+		return errors.Wrap(err, "failed to write redactions")
+	}
+	if err := result.SaveResult(bundlePath, RedactionsSummaryFilename, summaryFile); err != nil {
+		return errors.Wrap(err, "failed to write redactions summary")
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package supportbundle
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/redact"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactBundle(t *testing.T) {
+	req := require.New(t)
+
+	earlier := redact.Redaction{RedactorName: "Redact ipv4 addresses", File: "app/config.txt", Line: 1, IsDefaultRedactor: true}
+	report, err := json.Marshal(redact.RedactionList{
+		ByRedactor: map[string][]redact.Redaction{earlier.RedactorName: {earlier}},
+		ByFile:     map[string][]redact.Redaction{earlier.File: {earlier}},
+	})
+	req.NoError(err)
+
+	rootDir := t.TempDir()
+	files := map[string]string{
+		"version.yaml":             "apiVersion: troubleshoot.sh/v1beta2\n",
+		"app/config.txt":           "host: ***HIDDEN***\napi_key=abcdef\n",
+		RedactionsFilename:         string(report),
+		"collection-metadata.json": `{"collectionEpoch": "2022-10-01T12:00:00Z"}`,
+		"manifest.json":            `{"files": []}`,
+	}
+	for name, contents := range files {
+		path := filepath.Join(rootDir, name)
+		req.NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		req.NoError(ioutil.WriteFile(path, []byte(contents), 0644))
+	}
+
+	redactors := []*troubleshootv1beta2.Redact{
+		{
+			Name: "api keys",
+			Removals: troubleshootv1beta2.Removals{
+				Regex: []troubleshootv1beta2.Regex{{Redactor: `(api_key=)(?P<mask>.*)`}},
+			},
+		},
+	}
+
+	outputFilename := filepath.Join(t.TempDir(), "support-bundle-redacted.tar.gz")
+	redactions, err := RedactBundle(rootDir, redactors, outputFilename, redact.Options{})
+	req.NoError(err)
+
+	added := redact.Redaction{RedactorName: "api keys.regex.0", CharactersRemoved: -6, File: "app/config.txt", Line: 2}
+	assert.Equal(t, map[string][]redact.Redaction{"app/config.txt": {added}}, redactions.ByFile)
+
+	archive, err := ioutil.ReadFile(outputFilename)
+	req.NoError(err)
+	archived := archiveFiles(t, archive)
+	assert.Equal(t, "host: ***HIDDEN***\napi_key=***HIDDEN***\n", archived["support-bundle-redacted/app/config.txt"])
+	assert.Equal(t, files["collection-metadata.json"], archived["support-bundle-redacted/collection-metadata.json"])
+	assert.Contains(t, archived["support-bundle-redacted/"+RedactionsSummaryFilename], "2 redactions in 1 files")
+
+	var merged redact.RedactionList
+	req.NoError(json.Unmarshal([]byte(archived["support-bundle-redacted/"+RedactionsFilename]), &merged))
+	assert.Equal(t, []redact.Redaction{earlier, added}, merged.ByFile["app/config.txt"])
+
+	// the original bundle is not modified
+	b, err := ioutil.ReadFile(filepath.Join(rootDir, "app/config.txt"))
+	req.NoError(err)
+	assert.Equal(t, files["app/config.txt"], string(b))
+}
@@ -0,0 +1,143 @@
+package supportbundle
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"github.com/replicatedhq/troubleshoot/pkg/httputil"
+)
+
+// BundleSink is a destination for support bundle archives. The archive is streamed to the sink as it
+// is written, so it does not need to be written to a local file first.
+type BundleSink interface {
+	// Write stores the .tar.gz archive read from r. name is the archive's file name, e.g.
+	// support-bundle-2022-10-01T10_00_00.tar.gz. It returns where the archive was stored.
+	Write(name string, r io.Reader) (string, error)
+}
+
+// FileSink writes archives to a local directory
+type FileSink struct {
+	Dir string
+}
+
+func (s *FileSink) Write(name string, r io.Reader) (string, error) {
+	filename := filepath.Join(s.Dir, name)
+	f, err := os.Create(filename)
+	if err != nil {
+		return "", errors.Wrap(err, "create file")
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return "", errors.Wrap(err, "write file")
+	}
+
+	if err := f.Close(); err != nil {
+		return "", errors.Wrap(err, "close file")
+	}
+
+	return filename, nil
+}
+
+// MemorySink keeps the archive in memory
+type MemorySink struct {
+	Name    string
+	Archive bytes.Buffer
+}
+
+func (s *MemorySink) Write(name string, r io.Reader) (string, error) {
+	s.Name = name
+	s.Archive.Reset()
+	if _, err := s.Archive.ReadFrom(r); err != nil {
+		return "", errors.Wrap(err, "read archive")
+	}
+	return name, nil
+}
+
+// HTTPSink sends the archive as the body of a request to URL. The length of the archive is not known
+// while it is streamed, so the body is chunked. Presigned S3 urls need a length, use S3Sink for S3.
+type HTTPSink struct {
+	URL string
+	// Method defaults to PUT
+	Method string
+	// Client defaults to the client from httputil
+	Client *http.Client
+}
+
+func (s *HTTPSink) Write(name string, r io.Reader) (string, error) {
+	method := s.Method
+	if method == "" {
+		method = http.MethodPut
+	}
+
+	req, err := http.NewRequest(method, s.URL, r)
+	if err != nil {
+		return "", errors.Wrap(err, "create request")
+	}
+	req.Header.Set("Content-Type", "application/tar+gzip")
+
+	httpClient := s.Client
+	if httpClient == nil {
+		httpClient = httputil.GetHttpClient()
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "execute request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return s.URL, nil
+}
+
+// S3Sink uploads the archive to a bucket with a multipart upload
+type S3Sink struct {
+	Uploader *s3manager.Uploader
+	Bucket   string
+	// Prefix is joined with the archive name to make the object key
+	Prefix string
+}
+
+func (s *S3Sink) Write(name string, r io.Reader) (string, error) {
+	output, err := s.Uploader.Upload(&s3manager.UploadInput{
+		Bucket:      aws.String(s.Bucket),
+		Key:         aws.String(path.Join(s.Prefix, name)),
+		Body:        r,
+		ContentType: aws.String("application/tar+gzip"),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "upload archive")
+	}
+
+	return output.Location, nil
+}
+
+// writeToSink streams the archive of the bundle in bundlePath to sink
+func writeToSink(sink BundleSink, name string, bundlePath string, result collect.CollectorResult, opts collect.ArchiveOptions) (string, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(collect.WriteSupportBundleArchiveWithOptions(bundlePath, result, pw, opts))
+	}()
+
+	location, err := sink.Write(name, pr)
+	// stop the archive writer if the sink returned before reading everything
+	pr.CloseWithError(errors.New("sink closed"))
+	if err != nil {
+		return "", errors.Wrap(err, "write archive to sink")
+	}
+
+	return location, nil
+}
@@ -0,0 +1,162 @@
+package supportbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBundle(t *testing.T) (string, collect.CollectorResult) {
+	bundlePath := filepath.Join(t.TempDir(), "support-bundle")
+	result := collect.NewResult()
+	require.NoError(t, result.SaveResult(bundlePath, "version.yaml", bytes.NewBufferString("apiVersion: troubleshoot.sh/v1beta2\n")))
+	return bundlePath, result
+}
+
+// archiveFiles returns the files in a .tar.gz archive, other than the manifest
+func archiveFiles(t *testing.T, archive []byte) map[string]string {
+	gzReader, err := gzip.NewReader(bytes.NewReader(archive))
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	tarReader := tar.NewReader(gzReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		b, err := ioutil.ReadAll(tarReader)
+		require.NoError(t, err)
+		if filepath.Base(header.Name) == collect.ManifestFilename {
+			continue
+		}
+		files[header.Name] = string(b)
+	}
+	return files
+}
+
+func Test_writeToSink(t *testing.T) {
+	wantFiles := map[string]string{"support-bundle/version.yaml": "apiVersion: troubleshoot.sh/v1beta2\n"}
+
+	t.Run("file", func(t *testing.T) {
+		bundlePath, result := testBundle(t)
+		dir := t.TempDir()
+
+		location, err := writeToSink(&FileSink{Dir: dir}, "support-bundle.tar.gz", bundlePath, result, collect.ArchiveOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "support-bundle.tar.gz"), location)
+
+		archive, err := ioutil.ReadFile(location)
+		require.NoError(t, err)
+		assert.Equal(t, wantFiles, archiveFiles(t, archive))
+	})
+
+	t.Run("memory", func(t *testing.T) {
+		bundlePath, result := testBundle(t)
+		sink := &MemorySink{}
+
+		location, err := writeToSink(sink, "support-bundle.tar.gz", bundlePath, result, collect.ArchiveOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "support-bundle.tar.gz", location)
+		assert.Equal(t, "support-bundle.tar.gz", sink.Name)
+		assert.Equal(t, wantFiles, archiveFiles(t, sink.Archive.Bytes()))
+	})
+
+	t.Run("http", func(t *testing.T) {
+		bundlePath, result := testBundle(t)
+
+		var received []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/tar+gzip", r.Header.Get("Content-Type"))
+			received, _ = ioutil.ReadAll(r.Body)
+		}))
+		defer server.Close()
+
+		sink := &HTTPSink{URL: server.URL + "/bundles", Method: http.MethodPost, Client: server.Client()}
+		location, err := writeToSink(sink, "support-bundle.tar.gz", bundlePath, result, collect.ArchiveOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, server.URL+"/bundles", location)
+		assert.Equal(t, wantFiles, archiveFiles(t, received))
+	})
+
+	t.Run("http error", func(t *testing.T) {
+		bundlePath, result := testBundle(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		_, err := writeToSink(&HTTPSink{URL: server.URL, Client: server.Client()}, "support-bundle.tar.gz", bundlePath, result, collect.ArchiveOptions{})
+		assert.Error(t, err)
+	})
+
+	t.Run("s3", func(t *testing.T) {
+		bundlePath, result := testBundle(t)
+
+		var key string
+		var received []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPut, r.Method)
+			key = r.URL.Path
+			received, _ = ioutil.ReadAll(r.Body)
+		}))
+		defer server.Close()
+
+		sess, err := session.NewSession(&aws.Config{
+			Credentials:      credentials.NewStaticCredentials("id", "secret", ""),
+			Endpoint:         aws.String(server.URL),
+			Region:           aws.String("us-east-1"),
+			S3ForcePathStyle: aws.Bool(true),
+		})
+		require.NoError(t, err)
+
+		sink := &S3Sink{Uploader: s3manager.NewUploader(sess), Bucket: "bundles", Prefix: "cluster-1"}
+		location, err := writeToSink(sink, "support-bundle.tar.gz", bundlePath, result, collect.ArchiveOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, server.URL+"/bundles/cluster-1/support-bundle.tar.gz", location)
+		assert.Equal(t, "/bundles/cluster-1/support-bundle.tar.gz", key)
+		assert.Equal(t, wantFiles, archiveFiles(t, received))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		bundlePath, result := testBundle(t)
+		require.NoError(t, os.Remove(filepath.Join(bundlePath, "version.yaml")))
+
+		_, err := writeToSink(&MemorySink{}, "support-bundle.tar.gz", bundlePath, result, collect.ArchiveOptions{})
+		assert.Error(t, err)
+	})
+}
+
+type failingSink struct{}
+
+func (s *failingSink) Write(name string, r io.Reader) (string, error) {
+	return "", errors.New("sink is full")
+}
+
+func Test_writeToSink_SinkError(t *testing.T) {
+	bundlePath, result := testBundle(t)
+
+	// the archive writer must not block when the sink stops reading
+	_, err := writeToSink(&failingSink{}, "support-bundle.tar.gz", bundlePath, result, collect.ArchiveOptions{})
+	assert.EqualError(t, err, "write archive to sink: sink is full")
+}
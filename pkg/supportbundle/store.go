@@ -0,0 +1,183 @@
+package supportbundle
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+)
+
+// BundleStore is a BundleSink that keeps the archives written to it, and can list and delete them so
+// that a RetentionPolicy can be applied. FileSink, for a local or mounted volume, and S3Sink, for S3 or
+// MinIO, are stores.
+type BundleStore interface {
+	BundleSink
+	// List returns the archives in the store
+	List() ([]StoredBundle, error)
+	// Delete removes the archive with this name
+	Delete(name string) error
+}
+
+// StoredBundle is an archive in a BundleStore
+type StoredBundle struct {
+	Name string
+	Time time.Time
+	Size int64
+}
+
+// RetentionPolicy is which archives a BundleStore keeps. Archives are removed oldest first, and the
+// zero value keeps all of them.
+type RetentionPolicy struct {
+	// MaxBundles is how many archives are kept
+	MaxBundles int
+	// MaxAge is how long archives are kept
+	MaxAge time.Duration
+}
+
+func (p RetentionPolicy) isSet() bool {
+	return p.MaxBundles > 0 || p.MaxAge > 0
+}
+
+// ApplyRetention deletes the archives of store that policy does not keep, and returns their names
+func ApplyRetention(store BundleStore, policy RetentionPolicy, now time.Time) ([]string, error) {
+	deleted := []string{}
+	if !policy.isSet() {
+		return deleted, nil
+	}
+
+	bundles, err := store.List()
+	if err != nil {
+		return deleted, errors.Wrap(err, "list archives")
+	}
+	sort.Slice(bundles, func(i, j int) bool {
+		if !bundles[i].Time.Equal(bundles[j].Time) {
+			return bundles[i].Time.After(bundles[j].Time)
+		}
+		return bundles[i].Name > bundles[j].Name
+	})
+
+	for i, bundle := range bundles {
+		tooMany := policy.MaxBundles > 0 && i >= policy.MaxBundles
+		tooOld := policy.MaxAge > 0 && now.Sub(bundle.Time) > policy.MaxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+
+		if err := store.Delete(bundle.Name); err != nil {
+			return deleted, errors.Wrapf(err, "delete %s", bundle.Name)
+		}
+		deleted = append(deleted, bundle.Name)
+	}
+
+	return deleted, nil
+}
+
+func isArchiveName(name string) bool {
+	return strings.HasSuffix(name, ".tar.gz")
+}
+
+func (s *FileSink) List() ([]StoredBundle, error) {
+	files, err := ioutil.ReadDir(s.Dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "read dir")
+	}
+
+	bundles := []StoredBundle{}
+	for _, file := range files {
+		if !file.Mode().IsRegular() || !isArchiveName(file.Name()) {
+			continue
+		}
+		bundles = append(bundles, StoredBundle{
+			Name: file.Name(),
+			Time: file.ModTime(),
+			Size: file.Size(),
+		})
+	}
+
+	return bundles, nil
+}
+
+func (s *FileSink) Delete(name string) error {
+	if filepath.Base(name) != name {
+		return errors.Errorf("invalid archive name %q", name)
+	}
+
+	if err := os.Remove(filepath.Join(s.Dir, name)); err != nil {
+		return errors.Wrap(err, "remove file")
+	}
+	return nil
+}
+
+// NewMinIOSink creates an S3Sink for a MinIO server, such as one running in the cluster. endpoint is
+// the server's url, e.g. http://minio.minio.svc:9000.
+func NewMinIOSink(endpoint, accessKeyID, secretAccessKey, bucket, prefix string) (*S3Sink, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Credentials: credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""),
+		Endpoint:    aws.String(endpoint),
+		// MinIO ignores the region, but the signature needs one
+		Region:           aws.String("us-east-1"),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create session")
+	}
+
+	return &S3Sink{
+		Uploader: s3manager.NewUploader(sess),
+		Bucket:   bucket,
+		Prefix:   prefix,
+	}, nil
+}
+
+// List returns the archives directly under the sink's prefix
+func (s *S3Sink) List() ([]StoredBundle, error) {
+	prefix := ""
+	if s.Prefix != "" {
+		prefix = strings.TrimSuffix(s.Prefix, "/") + "/"
+	}
+
+	bundles := []StoredBundle{}
+	err := s.Uploader.S3.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			name := strings.TrimPrefix(aws.StringValue(object.Key), prefix)
+			if strings.Contains(name, "/") || !isArchiveName(name) {
+				continue
+			}
+			bundles = append(bundles, StoredBundle{
+				Name: name,
+				Time: aws.TimeValue(object.LastModified),
+				Size: aws.Int64Value(object.Size),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list objects")
+	}
+
+	return bundles, nil
+}
+
+func (s *S3Sink) Delete(name string) error {
+	_, err := s.Uploader.S3.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(path.Join(s.Prefix, name)),
+	})
+	if err != nil {
+		return errors.Wrap(err, "delete object")
+	}
+	return nil
+}
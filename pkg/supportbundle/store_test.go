@@ -0,0 +1,116 @@
+package supportbundle
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyRetention(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		policy      RetentionPolicy
+		wantDeleted []string
+	}{
+		{
+			name:        "no policy",
+			policy:      RetentionPolicy{},
+			wantDeleted: []string{},
+		},
+		{
+			name:        "max bundles",
+			policy:      RetentionPolicy{MaxBundles: 2},
+			wantDeleted: []string{"bundle-2.tar.gz", "bundle-1.tar.gz"},
+		},
+		{
+			name:        "max age",
+			policy:      RetentionPolicy{MaxAge: 60 * time.Hour},
+			wantDeleted: []string{"bundle-1.tar.gz"},
+		},
+		{
+			name:        "both",
+			policy:      RetentionPolicy{MaxBundles: 3, MaxAge: 12 * time.Hour},
+			wantDeleted: []string{"bundle-3.tar.gz", "bundle-2.tar.gz", "bundle-1.tar.gz"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for i := 1; i <= 4; i++ {
+				filename := filepath.Join(dir, fmt.Sprintf("bundle-%d.tar.gz", i))
+				require.NoError(t, os.WriteFile(filename, []byte("archive"), 0644))
+				modTime := now.Add(-time.Duration(4-i) * 24 * time.Hour).Add(-time.Hour)
+				require.NoError(t, os.Chtimes(filename, modTime, modTime))
+			}
+			// files that are not archives are not managed by the policy
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0644))
+
+			store := &FileSink{Dir: dir}
+			deleted, err := ApplyRetention(store, tt.policy, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDeleted, deleted)
+
+			bundles, err := store.List()
+			require.NoError(t, err)
+			assert.Len(t, bundles, 4-len(tt.wantDeleted))
+			assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+		})
+	}
+}
+
+func TestFileSink_Delete(t *testing.T) {
+	store := &FileSink{Dir: t.TempDir()}
+	assert.Error(t, store.Delete("../bundle.tar.gz"))
+	assert.Error(t, store.Delete("missing.tar.gz"))
+}
+
+func TestS3Sink_Store(t *testing.T) {
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			assert.Equal(t, "/bundles", r.URL.Path)
+			assert.Equal(t, "cluster-1/", r.URL.Query().Get("prefix"))
+			w.Header().Set("Content-Type", "application/xml")
+			fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult>
+  <Name>bundles</Name>
+  <IsTruncated>false</IsTruncated>
+  <Contents><Key>cluster-1/bundle-1.tar.gz</Key><LastModified>2022-09-29T12:00:00.000Z</LastModified><Size>10</Size></Contents>
+  <Contents><Key>cluster-1/bundle-2.tar.gz</Key><LastModified>2022-09-30T12:00:00.000Z</LastModified><Size>20</Size></Contents>
+  <Contents><Key>cluster-1/nested/bundle-3.tar.gz</Key><LastModified>2022-09-30T12:00:00.000Z</LastModified><Size>30</Size></Contents>
+  <Contents><Key>cluster-1/notes.txt</Key><LastModified>2022-09-30T12:00:00.000Z</LastModified><Size>5</Size></Contents>
+</ListBucketResult>`)
+		case http.MethodDelete:
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected method %s", r.Method)
+		}
+	}))
+	defer server.Close()
+
+	store, err := NewMinIOSink(server.URL, "id", "secret", "bundles", "cluster-1")
+	require.NoError(t, err)
+
+	bundles, err := store.List()
+	require.NoError(t, err)
+	assert.Equal(t, []StoredBundle{
+		{Name: "bundle-1.tar.gz", Time: time.Date(2022, 9, 29, 12, 0, 0, 0, time.UTC), Size: 10},
+		{Name: "bundle-2.tar.gz", Time: time.Date(2022, 9, 30, 12, 0, 0, 0, time.UTC), Size: 20},
+	}, bundles)
+
+	removed, err := ApplyRetention(store, RetentionPolicy{MaxBundles: 1}, time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bundle-1.tar.gz"}, removed)
+	assert.Equal(t, []string{"/bundles/cluster-1/bundle-1.tar.gz"}, deleted)
+}
@@ -0,0 +1,130 @@
+package supportbundle
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+)
+
+// Exit codes of a support bundle run that created a bundle, so that wrappers can decide whether it is
+// good enough to use. A run that did not create a bundle exits with 1.
+const (
+	ExitCodeOK = 0
+	// ExitCodeCollectionErrors is for a bundle that some collectors failed to collect all of their data for
+	ExitCodeCollectionErrors = 3
+	// ExitCodeAnalysisFailed is for a bundle that was collected in full, and that analyzers failed for
+	ExitCodeAnalysisFailed = 4
+)
+
+// RunSummary is the machine readable summary of a support bundle run
+type RunSummary struct {
+	ArchivePath string `json:"archivePath"`
+	// Size is the size of the archive, or of the files of a bundle written to a directory, in bytes. It is
+	// 0 for an archive that was written to a sink rather than a local path.
+	Size                  int64             `json:"size"`
+	NamespaceArchivePaths map[string]string `json:"namespaceArchivePaths,omitempty"`
+	FileUploaded          bool              `json:"fileUploaded"`
+	// CollectorFailures are the collectors that had errors, with the codes of their errors
+	CollectorFailures []CollectorFailure `json:"collectorFailures"`
+	Analysis          AnalysisSummary    `json:"analysis"`
+	ExitCode          int                `json:"exitCode"`
+}
+
+type CollectorFailure struct {
+	Collector string              `json:"collector"`
+	Codes     []collect.ErrorCode `json:"codes"`
+	Errors    int                 `json:"errors"`
+}
+
+// AnalysisSummary counts the analyzer results by outcome
+type AnalysisSummary struct {
+	Pass int `json:"pass"`
+	Warn int `json:"warn"`
+	Fail int `json:"fail"`
+}
+
+// NewRunSummary summarizes the response of a support bundle run. Collector errors take precedence
+// over failed analyzers for the exit code, as they are about the bundle rather than the cluster.
+func NewRunSummary(response *SupportBundleResponse) (*RunSummary, error) {
+	size, err := pathSize(response.ArchivePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "failed to get the size of the bundle")
+	}
+
+	summary := &RunSummary{
+		ArchivePath:           response.ArchivePath,
+		Size:                  size,
+		NamespaceArchivePaths: response.NamespaceArchivePaths,
+		FileUploaded:          response.FileUploaded,
+		CollectorFailures:     []CollectorFailure{},
+	}
+
+	for title, collectionErrors := range response.CollectionErrors {
+		failure := CollectorFailure{Collector: title, Codes: []collect.ErrorCode{}, Errors: len(collectionErrors)}
+		codes := map[collect.ErrorCode]bool{}
+		for _, collectionError := range collectionErrors {
+			if !codes[collectionError.Code] {
+				codes[collectionError.Code] = true
+				failure.Codes = append(failure.Codes, collectionError.Code)
+			}
+		}
+		summary.CollectorFailures = append(summary.CollectorFailures, failure)
+	}
+	sort.Slice(summary.CollectorFailures, func(i, j int) bool {
+		return summary.CollectorFailures[i].Collector < summary.CollectorFailures[j].Collector
+	})
+
+	for _, result := range response.AnalyzerResults {
+		switch {
+		case result.IsFail:
+			summary.Analysis.Fail++
+		case result.IsWarn:
+			summary.Analysis.Warn++
+		case result.IsPass:
+			summary.Analysis.Pass++
+		}
+	}
+
+	switch {
+	case len(summary.CollectorFailures) > 0:
+		summary.ExitCode = ExitCodeCollectionErrors
+	case summary.Analysis.Fail > 0:
+		summary.ExitCode = ExitCodeAnalysisFailed
+	default:
+		summary.ExitCode = ExitCodeOK
+	}
+
+	return summary, nil
+}
+
+// WriteRunSummary writes the summary as json to filename
+func WriteRunSummary(filename string, summary *RunSummary) error {
+	b, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal summary")
+	}
+	if err := ioutil.WriteFile(filename, append(b, '\n'), 0644); err != nil {
+		return errors.Wrap(err, "failed to write summary")
+	}
+	return nil
+}
+
+// pathSize returns the size of a file, or of the files in a directory
+func pathSize(path string) (int64, error) {
+	var size int64
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
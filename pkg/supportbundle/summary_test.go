@@ -0,0 +1,71 @@
+package supportbundle
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	analyzer "github.com/replicatedhq/troubleshoot/pkg/analyze"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRunSummary(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "support-bundle.tar.gz")
+	require.NoError(t, ioutil.WriteFile(archivePath, []byte("0123456789"), 0644))
+
+	results := []*analyzer.AnalyzeResult{{IsPass: true}, {IsPass: true}, {IsWarn: true}}
+
+	summary, err := NewRunSummary(&SupportBundleResponse{ArchivePath: archivePath, AnalyzerResults: results})
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), summary.Size)
+	assert.Equal(t, AnalysisSummary{Pass: 2, Warn: 1}, summary.Analysis)
+	assert.Equal(t, ExitCodeOK, summary.ExitCode)
+
+	results = append(results, &analyzer.AnalyzeResult{IsFail: true})
+	summary, err = NewRunSummary(&SupportBundleResponse{ArchivePath: archivePath, AnalyzerResults: results})
+	require.NoError(t, err)
+	assert.Equal(t, ExitCodeAnalysisFailed, summary.ExitCode)
+
+	summary, err = NewRunSummary(&SupportBundleResponse{
+		ArchivePath:     archivePath,
+		AnalyzerResults: results,
+		CollectionErrors: collect.CollectionErrors{
+			"logs/app": {
+				{Code: collect.ErrorCodePermissionDenied, Message: "forbidden"},
+				{Code: collect.ErrorCodePermissionDenied, Message: "forbidden"},
+			},
+			"cluster-resources": {{Code: collect.ErrorCodeTimeout, Message: "timeout"}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ExitCodeCollectionErrors, summary.ExitCode)
+	assert.Equal(t, []CollectorFailure{
+		{Collector: "cluster-resources", Codes: []collect.ErrorCode{collect.ErrorCodeTimeout}, Errors: 1},
+		{Collector: "logs/app", Codes: []collect.ErrorCode{collect.ErrorCodePermissionDenied}, Errors: 2},
+	}, summary.CollectorFailures)
+
+	summaryFile := filepath.Join(dir, "summary.json")
+	require.NoError(t, WriteRunSummary(summaryFile, summary))
+	b, err := ioutil.ReadFile(summaryFile)
+	require.NoError(t, err)
+	written := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(b, &written))
+	assert.Equal(t, archivePath, written["archivePath"])
+	assert.Equal(t, float64(ExitCodeCollectionErrors), written["exitCode"])
+	assert.Equal(t, map[string]interface{}{"pass": float64(2), "warn": float64(1), "fail": float64(1)}, written["analysis"])
+}
+
+func TestNewRunSummary_outputDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "version.yaml"), []byte("12345"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "manifest.json"), []byte("123"), 0644))
+
+	summary, err := NewRunSummary(&SupportBundleResponse{ArchivePath: dir})
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), summary.Size)
+	assert.Equal(t, []CollectorFailure{}, summary.CollectorFailures)
+}
@@ -1,6 +1,8 @@
 package supportbundle
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -16,6 +18,8 @@ import (
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
 	"github.com/replicatedhq/troubleshoot/pkg/collect"
 	"github.com/replicatedhq/troubleshoot/pkg/convert"
+	"github.com/replicatedhq/troubleshoot/pkg/redact"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
@@ -27,15 +31,47 @@ type SupportBundleCreateOpts struct {
 	Namespace                 string
 	ProgressChan              chan interface{}
 	SinceTime                 *time.Time
-	OutputPath                string
-	Redact                    bool
-	FromCLI                   bool
+	// OutputPath is the path of the archive, which is a .zip archive if it ends in .zip and a .tar.gz
+	// archive otherwise. A path of dir://path is the same as an OutputDir of path.
+	OutputPath string
+	Redact     bool
+	FromCLI    bool
+	// Sink receives the archive instead of it being written to a local file. afterCollection steps read
+	// the local file, so they can't be used with a sink.
+	Sink BundleSink
+	// Retention is applied to the sink after the archive has been written to it. The sink must be a
+	// BundleStore to have a retention policy.
+	Retention RetentionPolicy
+	// OutputDir is a directory to write the bundle's files to instead of an archive, so that bundles can
+	// be committed and diffed. It must be empty or not exist.
+	OutputDir string
+	// NamespaceBundles are namespaces to write an archive for in addition to the support bundle. Each
+	// has the namespace's files and the cluster scoped files, and is named after the support bundle
+	// with the namespace appended.
+	NamespaceBundles []string
+	// Reproducible archives are written with the same bytes for the same files, without the timestamps,
+	// owners and permissions of the files. The files are in a directory that is not named after when the
+	// bundle was collected, and the collection metadata and execution log are written without times.
+	Reproducible bool
+	// CollectConcurrency is how many collectors can run at the same time. When it is not set, the
+	// spec's collectConcurrency is used, and collectors run one at a time if neither is set.
+	CollectConcurrency int
+	// Disk is how much disk space collection leaves free, and how fast it writes collected files
+	Disk collect.DiskGuardOptions
+	// Context cancels collection. The collectors that are running are stopped, the ones that have not
+	// started are not run, and no archive is written. context.Background() is used when it is not set.
+	Context context.Context
 }
 
 type SupportBundleResponse struct {
 	AnalyzerResults []*analyzer.AnalyzeResult
 	ArchivePath     string
 	FileUploaded    bool
+	// NamespaceArchivePaths are the archives of NamespaceBundles, by namespace
+	NamespaceArchivePaths map[string]string
+	// CollectionErrors are the errors of the collectors that failed or collected only some of their
+	// data. They are not redacted.
+	CollectionErrors collect.CollectionErrors
 }
 
 // CollectSupportBundleFromSpec collects support bundle from start to finish, including running
@@ -44,15 +80,91 @@ type SupportBundleResponse struct {
 // if FromCLI option is set to false, the support bundle is archived in the OS temp folder (os.TempDir()).
 func CollectSupportBundleFromSpec(spec *troubleshootv1beta2.SupportBundleSpec, additionalRedactors *troubleshootv1beta2.Redactor, opts SupportBundleCreateOpts) (*SupportBundleResponse, error) {
 	resultsResponse := SupportBundleResponse{}
+	metadata := collect.NewCollectionMetadata(time.Now())
+	collectionErrors := collect.CollectionErrors{}
 
 	if opts.KubernetesRestConfig == nil {
 		return nil, errors.New("did not receive kube rest config")
 	}
+	metadata.SetImpersonation(opts.KubernetesRestConfig.Impersonate)
+	execLog := newExecutionLog(opts.KubernetesRestConfig)
 
 	if opts.ProgressChan == nil {
 		return nil, errors.New("did not receive collector progress chan")
 	}
 
+	if opts.Retention.isSet() {
+		if _, ok := opts.Sink.(BundleStore); !ok {
+			return nil, errors.New("a retention policy needs a bundle sink that can list and delete archives")
+		}
+	}
+
+	if opts.Sink != nil && len(spec.AfterCollection) > 0 {
+		return nil, errors.New("afterCollection can not be used with a bundle sink")
+	}
+
+	if strings.HasPrefix(opts.OutputPath, OutputDirPrefix) {
+		if opts.OutputDir != "" {
+			return nil, errors.New("at most one of an output path or an output dir may be set")
+		}
+		opts.OutputDir = strings.TrimPrefix(opts.OutputPath, OutputDirPrefix)
+		opts.OutputPath = ""
+	}
+
+	extension := archiveExtension(opts.OutputPath)
+	if extension == zipExtension {
+		if opts.Sink != nil {
+			return nil, errors.New("a .zip archive can not be written to a bundle sink")
+		}
+		if len(spec.AfterCollection) > 0 {
+			return nil, errors.New("afterCollection can not be used with a .zip archive")
+		}
+	}
+
+	if opts.OutputDir != "" {
+		if opts.Sink != nil {
+			return nil, errors.New("an output dir can not be used with a bundle sink")
+		}
+		if len(spec.AfterCollection) > 0 {
+			return nil, errors.New("afterCollection can not be used with an output dir")
+		}
+	}
+
+	if opts.Context == nil {
+		opts.Context = context.Background()
+	}
+
+	if opts.CollectConcurrency == 0 {
+		opts.CollectConcurrency = spec.CollectConcurrency
+	}
+
+	// redactions are recorded for the whole process, and the bundle's redaction report should only
+	// have its own
+	if opts.Redact {
+		redact.ResetRedactionList()
+
+		if err := redact.ValidateDefaultRedactorGroups(redactOptions(additionalRedactors).ExcludeDefaultRedactors); err != nil {
+			return nil, errors.Wrap(err, "failed to exclude default redactors")
+		}
+	}
+
+	if opts.Redact && additionalRedactors != nil {
+		client, err := kubernetes.NewForConfig(opts.KubernetesRestConfig)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create kubernetes client")
+		}
+
+		// the caller's redactors are not modified, so the values aren't kept after collection
+		redactors, err := redact.ResolveValuesFrom(opts.Context, client, additionalRedactors.Spec.Redactors)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to resolve redactor values")
+		}
+		additionalRedactors = additionalRedactors.DeepCopy()
+		additionalRedactors.Spec.Redactors = redactors
+		// the redactors built with the values are dropped once the bundle has been redacted
+		defer redact.ReleaseRedactors(redactors)
+	}
+
 	tmpDir, err := ioutil.TempDir("", "supportbundle")
 	if err != nil {
 		return nil, errors.Wrap(err, "create temp dir")
@@ -66,7 +178,7 @@ func CollectSupportBundleFromSpec(spec *troubleshootv1beta2.SupportBundleSpec, a
 		if err != nil {
 			return nil, errors.Wrap(err, "override output file path")
 		}
-		basename = strings.TrimSuffix(overridePath, ".tar.gz")
+		basename = strings.TrimSuffix(overridePath, "."+extension)
 	} else {
 		// use default output path
 		basename = fmt.Sprintf("support-bundle-%s", time.Now().Format("2006-01-02T15_04_05"))
@@ -75,22 +187,41 @@ func CollectSupportBundleFromSpec(spec *troubleshootv1beta2.SupportBundleSpec, a
 		}
 	}
 
-	filename, err := findFileName(basename, "tar.gz")
-	if err != nil {
-		return nil, errors.Wrap(err, "find file name")
+	filename := filepath.Base(basename) + "." + extension
+	if opts.Sink == nil {
+		filename, err = findFileName(basename, extension)
+		if err != nil {
+			return nil, errors.Wrap(err, "find file name")
+		}
 	}
 	resultsResponse.ArchivePath = filename
 
-	bundlePath := filepath.Join(tmpDir, strings.TrimSuffix(filename, ".tar.gz"))
+	bundlePath := filepath.Join(tmpDir, strings.TrimSuffix(filename, "."+extension))
 	if err := os.MkdirAll(bundlePath, 0777); err != nil {
 		return nil, errors.Wrap(err, "create bundle dir")
 	}
 
+	// the disks of the collected files and of the archive are checked, a sink has no local archive
+	guardPaths := []string{bundlePath}
+	if opts.OutputDir != "" {
+		guardPaths = append(guardPaths, opts.OutputDir)
+	} else if opts.Sink == nil {
+		guardPaths = append(guardPaths, filepath.Dir(filename))
+	}
+	diskGuard := collect.NewDiskGuard(opts.Disk, guardPaths...)
+	if err := diskGuard.Check(); err != nil {
+		return nil, errors.Wrap(err, "not enough free disk space to collect a support bundle")
+	}
+	// the bundle's metadata and archive are written to the reserve, so that the files that were
+	// collected before collection was stopped are still a valid bundle
+	releaseDiskGuard := diskGuard.GuardWrites(bundlePath)
+	defer releaseDiskGuard()
+
 	var result, files, hostFiles collect.CollectorResult
 
 	if spec.HostCollectors != nil {
 		// Run host collectors
-		hostFiles, err = runHostCollectors(spec.HostCollectors, additionalRedactors, bundlePath, opts)
+		hostFiles, err = runHostCollectors(spec.HostCollectors, additionalRedactors, bundlePath, metadata, collectionErrors, execLog, diskGuard, opts)
 		if err != nil {
 			fmt.Println(errors.Wrap(err, "failed to run host collectors"))
 		}
@@ -98,12 +229,16 @@ func CollectSupportBundleFromSpec(spec *troubleshootv1beta2.SupportBundleSpec, a
 
 	if spec.Collectors != nil {
 		// Run collectors
-		files, err = runCollectors(spec.Collectors, additionalRedactors, bundlePath, opts)
+		files, err = runCollectors(collect.ScopeCollectorsToNodes(spec.Collectors, spec.NodeSelector), additionalRedactors, bundlePath, metadata, collectionErrors, execLog, diskGuard, opts)
 		if err != nil {
 			fmt.Println(errors.Wrap(err, "failed to run collectors"))
 		}
 	}
 
+	if err := opts.Context.Err(); err != nil {
+		return nil, errors.Wrap(err, "collection was cancelled")
+	}
+
 	if files != nil && hostFiles != nil {
 		result = files
 		for k, v := range hostFiles {
@@ -116,6 +251,10 @@ func CollectSupportBundleFromSpec(spec *troubleshootv1beta2.SupportBundleSpec, a
 	} else {
 		return nil, errors.Wrap(err, "failed to generate support bundle")
 	}
+	releaseDiskGuard()
+	if diskGuard.Exhausted() {
+		opts.ProgressChan <- errors.New("collection was stopped as the free disk space was below the reserve, the bundle only has the files collected before then")
+	}
 
 	version, err := getVersionFile()
 	if err != nil {
@@ -127,8 +266,51 @@ func CollectSupportBundleFromSpec(spec *troubleshootv1beta2.SupportBundleSpec, a
 		return nil, errors.Wrap(err, "failed to write version")
 	}
 
+	// written before the redactions file, as it is redacted too, and before the analyzers run, so that
+	// they can tell which collectors failed
+	collectionErrorsFile, err := getCollectionErrorsFile(collectionErrors, additionalRedactors, opts.Redact)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get collection errors file")
+	}
+
+	err = result.SaveResult(bundlePath, collect.CollectionErrorsFilename, collectionErrorsFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to write collection errors")
+	}
+	resultsResponse.CollectionErrors = collectionErrors
+
+	if opts.Redact {
+		redactions, redactionsSummary, err := getRedactionsFiles(redact.GetRedactionList())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get redactions files")
+		}
+
+		err = result.SaveResult(bundlePath, RedactionsFilename, redactions)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to write redactions")
+		}
+
+		err = result.SaveResult(bundlePath, RedactionsSummaryFilename, redactionsSummary)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to write redactions summary")
+		}
+	}
+
+	if opts.Reproducible {
+		metadata.ClearTimes()
+	}
+	collectionMetadata, err := getCollectionMetadataFile(metadata)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get collection metadata file")
+	}
+
+	err = result.SaveResult(bundlePath, collect.CollectionMetadataFilename, collectionMetadata)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to write collection metadata")
+	}
+
 	// Run Analyzers
-	analyzeResults, err := AnalyzeSupportBundle(spec, bundlePath)
+	analyzeResults, err := analyzeSupportBundle(spec, bundlePath, execLog)
 	if err != nil {
 		if opts.FromCLI {
 			c := color.New(color.FgHiRed)
@@ -150,7 +332,63 @@ func CollectSupportBundleFromSpec(spec *troubleshootv1beta2.SupportBundleSpec, a
 		return nil, errors.Wrap(err, "failed to write analysis")
 	}
 
-	if err := collect.TarSupportBundleDir(bundlePath, result, filename); err != nil {
+	versionedAnalysis, err := getVersionedAnalysisFile(analyzeResults)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get versioned analysis file")
+	}
+
+	err = result.SaveResult(bundlePath, VersionedAnalysisFilename, versionedAnalysis)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to write versioned analysis")
+	}
+
+	if opts.Reproducible {
+		execLog.clearTimes()
+	}
+	executionLogData, err := getExecutionLogFile(execLog, additionalRedactors, opts.Redact)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get execution log")
+	}
+
+	err = result.SaveResult(bundlePath, ExecutionLogFilename, bytes.NewBuffer(executionLogData))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to write execution log")
+	}
+
+	if len(opts.NamespaceBundles) > 0 {
+		namespaceArchivePaths, err := writeNamespaceBundles(opts.NamespaceBundles, basename, extension, bundlePath, result, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "write namespace bundles")
+		}
+		resultsResponse.NamespaceArchivePaths = namespaceArchivePaths
+	}
+
+	if opts.Sink != nil {
+		location, err := writeToSink(opts.Sink, filename, bundlePath, result, opts.archiveOptions())
+		if err != nil {
+			return nil, errors.Wrap(err, "write bundle to sink")
+		}
+		resultsResponse.ArchivePath = location
+
+		if opts.Retention.isSet() {
+			if _, err := ApplyRetention(opts.Sink.(BundleStore), opts.Retention, time.Now()); err != nil {
+				return nil, errors.Wrap(err, "apply retention policy")
+			}
+		}
+
+		return &resultsResponse, nil
+	}
+
+	if opts.OutputDir != "" {
+		if err := collect.WriteSupportBundleDir(bundlePath, result, opts.OutputDir); err != nil {
+			return nil, errors.Wrap(err, "write bundle dir")
+		}
+		resultsResponse.ArchivePath = opts.OutputDir
+
+		return &resultsResponse, nil
+	}
+
+	if err := archiveSupportBundleDir(bundlePath, result, filename, opts); err != nil {
 		return nil, errors.Wrap(err, "create bundle file")
 	}
 
@@ -185,9 +423,7 @@ func CollectSupportBundleFromURI(specURI string, redactorURIs []string, opts Sup
 			return nil, errors.Wrapf(err, "failed to get redactor spec %s", redactor)
 		}
 
-		if redactorObj != nil {
-			additionalRedactors.Spec.Redactors = append(additionalRedactors.Spec.Redactors, redactorObj.Spec.Redactors...)
-		}
+		AppendRedactor(additionalRedactors, redactorObj)
 	}
 
 	return CollectSupportBundleFromSpec(&supportBundle.Spec, additionalRedactors, opts)
@@ -217,10 +453,37 @@ func ProcessSupportBundleAfterCollection(spec *troubleshootv1beta2.SupportBundle
 // AnalyzeSupportBundle performs analysis on a support bundle using the support bundle spec and an already unpacked support
 // bundle on disk
 func AnalyzeSupportBundle(spec *troubleshootv1beta2.SupportBundleSpec, tmpDir string) ([]*analyzer.AnalyzeResult, error) {
+	return analyzeSupportBundle(spec, tmpDir, nil)
+}
+
+// analyzeSupportBundle is AnalyzeSupportBundle that records the analyzers that were run in execLog, if it
+// is not nil
+func analyzeSupportBundle(spec *troubleshootv1beta2.SupportBundleSpec, tmpDir string, execLog *executionLog) ([]*analyzer.AnalyzeResult, error) {
 	if len(spec.Analyzers) == 0 && len(spec.HostAnalyzers) == 0 {
 		return nil, nil
 	}
-	analyzeResults, err := analyzer.AnalyzeLocal(tmpDir, spec.Analyzers, spec.HostAnalyzers)
+
+	var onRun func(analyzer.AnalyzerRun)
+	if execLog != nil {
+		onRun = func(run analyzer.AnalyzerRun) {
+			executionType := executionTypeAnalyzer
+			if _, ok := run.Analyzer.(*troubleshootv1beta2.HostAnalyze); ok {
+				executionType = executionTypeHostAnalyzer
+			}
+			name, spec := specField(run.Analyzer)
+			if meta := analyzer.GetAnalyzeMeta(run.Analyzer); meta != nil && meta.CheckName != "" {
+				name = name + "/" + meta.CheckName
+			}
+
+			if run.Err != nil {
+				execLog.add(executionType, name, spec, run.StartTime, executionOutcomeFailed, run.Err.Error())
+			} else {
+				execLog.add(executionType, name, spec, run.StartTime, executionOutcomeSucceeded, "")
+			}
+		}
+	}
+
+	analyzeResults, err := analyzer.AnalyzeLocalWithCallback(tmpDir, spec.Analyzers, spec.HostAnalyzers, onRun)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to analyze support bundle")
 	}
@@ -235,5 +498,11 @@ func ConcatSpec(target *troubleshootv1beta2.SupportBundle, source *troubleshootv
 	newBundle.Spec.HostCollectors = append(target.Spec.HostCollectors, source.Spec.HostCollectors...)
 	newBundle.Spec.HostAnalyzers = append(target.Spec.HostAnalyzers, source.Spec.HostAnalyzers...)
 	newBundle.Spec.Analyzers = append(target.Spec.Analyzers, source.Spec.Analyzers...)
+	if newBundle.Spec.CollectConcurrency == 0 {
+		newBundle.Spec.CollectConcurrency = source.Spec.CollectConcurrency
+	}
+	if newBundle.Spec.NodeSelector == nil {
+		newBundle.Spec.NodeSelector = source.Spec.NodeSelector
+	}
 	return newBundle
 }
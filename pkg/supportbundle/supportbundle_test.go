@@ -1,8 +1,18 @@
 package supportbundle
 
 import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
 )
 
 func Test_LoadAndConcatSpec(t *testing.T) {
@@ -44,3 +54,72 @@ func Test_LoadAndConcatSpec(t *testing.T) {
 	}
 
 }
+
+func Test_ConcatSpec_CollectConcurrency(t *testing.T) {
+	load := func(path string) *troubleshootv1beta2.SupportBundle {
+		doc, err := LoadSupportBundleSpec(path)
+		require.NoError(t, err)
+		bundle, err := ParseSupportBundleFromDoc(doc)
+		require.NoError(t, err)
+		return bundle
+	}
+
+	withoutConcurrency := load("test/supportbundle1.yaml")
+	concurrency2 := load("test/concurrency1.yaml")
+	concurrency4 := load("test/concurrency2.yaml")
+
+	assert.Equal(t, 4, ConcatSpec(withoutConcurrency, concurrency4).Spec.CollectConcurrency)
+	assert.Equal(t, 2, ConcatSpec(concurrency2, concurrency4).Spec.CollectConcurrency)
+	assert.Equal(t, 2, ConcatSpec(concurrency2, withoutConcurrency).Spec.CollectConcurrency)
+	assert.Len(t, ConcatSpec(concurrency2, concurrency4).Spec.Collectors, 2)
+}
+
+func Test_CollectSupportBundleFromSpec_Reproducible(t *testing.T) {
+	// an API server that allows everything and only has its version
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/version":
+			w.Write([]byte(`{"major": "1", "minor": "25", "gitVersion": "v1.25.0"}`))
+		case strings.HasSuffix(r.URL.Path, "/selfsubjectaccessreviews"):
+			w.Write([]byte(`{"apiVersion": "authorization.k8s.io/v1", "kind": "SelfSubjectAccessReview", "status": {"allowed": true}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"apiVersion": "v1", "kind": "Status", "status": "Failure", "reason": "NotFound", "code": 404}`))
+		}
+	}))
+	defer server.Close()
+
+	spec := &troubleshootv1beta2.SupportBundleSpec{
+		Collectors: []*troubleshootv1beta2.Collect{
+			{ClusterInfo: &troubleshootv1beta2.ClusterInfo{}},
+		},
+	}
+
+	collectBundle := func(name string) []byte {
+		progressChan := make(chan interface{})
+		defer close(progressChan)
+		go func() {
+			for range progressChan {
+			}
+		}()
+
+		outputPath := filepath.Join(t.TempDir(), name)
+		_, err := CollectSupportBundleFromSpec(spec, nil, SupportBundleCreateOpts{
+			KubernetesRestConfig: &rest.Config{Host: server.URL},
+			ProgressChan:         progressChan,
+			CollectorProgressCallback: func(progressChan chan interface{}, msg string) {
+				progressChan <- msg
+			},
+			OutputPath:   outputPath,
+			Reproducible: true,
+		})
+		require.NoError(t, err)
+
+		b, err := ioutil.ReadFile(outputPath)
+		require.NoError(t, err)
+		return b
+	}
+
+	assert.Equal(t, collectBundle("first.tar.gz"), collectBundle("second.tar.gz"))
+}
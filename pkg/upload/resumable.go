@@ -0,0 +1,214 @@
+package upload
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/troubleshoot/pkg/httputil"
+)
+
+// DefaultChunkSize is the size of the chunks that ResumableUploader sends
+const DefaultChunkSize = 16 * 1024 * 1024
+
+// statusResumeIncomplete is the response to a chunk when the upload is not complete
+const statusResumeIncomplete = 308
+
+// ResumableUploader uploads archives to a signed url in chunks, so that an upload that fails part way
+// is resumed from the last chunk the server received instead of from the start. Each chunk is a PUT
+// with a Content-Range header. The server responds 308 with the range it has received until the
+// upload is complete. Archives that fit in a single chunk are sent with a single PUT, so that signed
+// urls that do not support resumable uploads can be used for them.
+type ResumableUploader struct {
+	URL string
+	// ChunkSize is DefaultChunkSize if it is 0
+	ChunkSize int64
+	// MaxRetries is how many times in a row a failed chunk is retried, DefaultMaxRetries if it is 0
+	MaxRetries int
+	// Client is the client from httputil if it is nil
+	Client *http.Client
+	// Progress, if it is not nil, is called after each chunk with how much of the archive has been sent
+	Progress func(uploaded int64, total int64)
+}
+
+func (u *ResumableUploader) Upload(archivePath string) (string, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open archive")
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to stat archive")
+	}
+	total := info.Size()
+
+	chunkSize := u.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	maxRetries := u.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+	progress := u.Progress
+	if progress == nil {
+		progress = func(int64, int64) {}
+	}
+
+	var offset int64
+	failures := 0
+	for {
+		end := offset + chunkSize
+		if end > total {
+			end = total
+		}
+
+		next, done, err := u.sendChunk(f, offset, end, total)
+		if err == nil {
+			failures = 0
+			offset = next
+			progress(offset, total)
+			if done {
+				return u.URL, nil
+			}
+			continue
+		}
+
+		failures++
+		if failures > maxRetries {
+			return "", errors.Wrapf(err, "failed to upload %s", filepath.Base(archivePath))
+		}
+		time.Sleep(retryBackoff(failures))
+
+		// the server can have received some of the chunk that failed
+		next, done, statusErr := u.uploadStatus(total)
+		if statusErr != nil {
+			continue
+		}
+		if done {
+			progress(total, total)
+			return u.URL, nil
+		}
+		offset = next
+	}
+}
+
+// sendChunk sends bytes [start, end) of the archive, and returns the offset that the server has received
+// up to, and whether the upload is complete
+func (u *ResumableUploader) sendChunk(f *os.File, start int64, end int64, total int64) (int64, bool, error) {
+	req, err := http.NewRequest(http.MethodPut, u.URL, io.NewSectionReader(f, start, end-start))
+	if err != nil {
+		return 0, false, errors.Wrap(err, "failed to create request")
+	}
+	req.ContentLength = end - start
+	req.Header.Set("Content-Type", "application/tar+gzip")
+	if start > 0 || end < total {
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, total))
+	}
+
+	return u.do(req, end)
+}
+
+// uploadStatus asks the server how much of the archive it has received
+func (u *ResumableUploader) uploadStatus(total int64) (int64, bool, error) {
+	req, err := http.NewRequest(http.MethodPut, u.URL, nil)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", total))
+
+	return u.do(req, 0)
+}
+
+func (u *ResumableUploader) do(req *http.Request, sent int64) (int64, bool, error) {
+	client := u.Client
+	if client == nil {
+		client = httputil.GetHttpClient()
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "failed to execute request")
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode == statusResumeIncomplete:
+		received, err := parseReceivedRange(resp.Header.Get("Range"))
+		if err != nil {
+			return 0, false, err
+		}
+		return received, false, nil
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return sent, true, nil
+	default:
+		return 0, false, errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+}
+
+// parseReceivedRange returns the offset after the range of a 308 response, e.g. 100 for bytes=0-99.
+// A response without a range has received nothing.
+func parseReceivedRange(header string) (int64, error) {
+	if header == "" {
+		return 0, nil
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(header, "bytes="), "-", 2)
+	if len(parts) != 2 {
+		return 0, errors.Errorf("invalid range %q", header)
+	}
+	last, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid range %q", header)
+	}
+	return last + 1, nil
+}
+
+func retryBackoff(failures int) time.Duration {
+	backoff := time.Duration(1<<uint(failures-1)) * 500 * time.Millisecond
+	if backoff > 10*time.Second {
+		backoff = 10 * time.Second
+	}
+	return backoff
+}
+
+// ProgressBar returns a Progress func for ResumableUploader that draws a progress bar on a line of w
+func ProgressBar(w io.Writer, title string) func(uploaded int64, total int64) {
+	const width = 30
+	return func(uploaded int64, total int64) {
+		filled := width
+		percent := 100
+		if total > 0 {
+			filled = int(uploaded * width / total)
+			percent = int(uploaded * 100 / total)
+		}
+		bar := strings.Repeat("=", filled) + strings.Repeat(" ", width-filled)
+		fmt.Fprintf(w, "\r%s [%s] %3d%% %s/%s", title, bar, percent, formatBytes(uploaded), formatBytes(total))
+		if uploaded >= total {
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// formatBytes formats a size in bytes with binary units
+func formatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%dB", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit && exp < 4; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(b)/float64(div), "KMGTP"[exp])
+}
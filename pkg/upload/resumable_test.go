@@ -0,0 +1,130 @@
+package upload
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResumableUploader_Upload(t *testing.T) {
+	contents := []byte(strings.Repeat("0123456789", 25))
+	archivePath := filepath.Join(t.TempDir(), "support-bundle.tar.gz")
+	require.NoError(t, ioutil.WriteFile(archivePath, contents, 0644))
+
+	received := bytes.Buffer{}
+	contentRanges := []string{}
+	chunks := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		contentRange := r.Header.Get("Content-Range")
+		contentRanges = append(contentRanges, contentRange)
+
+		if contentRange == fmt.Sprintf("bytes */%d", len(contents)) {
+			w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", received.Len()-1))
+			w.WriteHeader(statusResumeIncomplete)
+			return
+		}
+
+		b, _ := ioutil.ReadAll(r.Body)
+		chunks++
+		// the second chunk fails after the server received half of it
+		if chunks == 2 {
+			received.Write(b[:len(b)/2])
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		received.Write(b)
+
+		if received.Len() == len(contents) {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", received.Len()-1))
+		w.WriteHeader(statusResumeIncomplete)
+	}))
+	defer server.Close()
+
+	progress := []int64{}
+	uploader := &ResumableUploader{
+		URL:       server.URL + "/upload?signature=abc",
+		ChunkSize: 100,
+		Progress: func(uploaded int64, total int64) {
+			assert.Equal(t, int64(len(contents)), total)
+			progress = append(progress, uploaded)
+		},
+	}
+
+	location, err := uploader.Upload(archivePath)
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/upload?signature=abc", location)
+	assert.Equal(t, contents, received.Bytes())
+	assert.Equal(t, []string{
+		"bytes 0-99/250",
+		"bytes 100-199/250",
+		"bytes */250",
+		"bytes 150-249/250",
+	}, contentRanges)
+	assert.Equal(t, []int64{100, 250}, progress)
+}
+
+func TestResumableUploader_Upload_SingleChunk(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "support-bundle.tar.gz")
+	require.NoError(t, ioutil.WriteFile(archivePath, []byte("archive"), 0644))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// presigned urls that are not resumable get a plain PUT
+		assert.Empty(t, r.Header.Get("Content-Range"))
+		assert.Equal(t, int64(7), r.ContentLength)
+	}))
+	defer server.Close()
+
+	_, err := (&ResumableUploader{URL: server.URL}).Upload(archivePath)
+	require.NoError(t, err)
+}
+
+func TestResumableUploader_Upload_Fails(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "support-bundle.tar.gz")
+	require.NoError(t, ioutil.WriteFile(archivePath, []byte("archive"), 0644))
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	_, err := (&ResumableUploader{URL: server.URL, MaxRetries: 1}).Upload(archivePath)
+	assert.Error(t, err)
+	// the chunk, the status of the upload, and the chunk again
+	assert.Equal(t, 3, requests)
+}
+
+func Test_parseReceivedRange(t *testing.T) {
+	received, err := parseReceivedRange("bytes=0-99")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), received)
+
+	received, err = parseReceivedRange("")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), received)
+
+	_, err = parseReceivedRange("bytes=abc")
+	assert.Error(t, err)
+}
+
+func TestProgressBar(t *testing.T) {
+	out := bytes.Buffer{}
+	progress := ProgressBar(&out, "Uploading")
+	progress(1536, 3072)
+	progress(3072, 3072)
+	assert.Equal(t, "\rUploading [===============               ]  50% 1.5KiB/3.0KiB"+
+		"\rUploading [==============================] 100% 3.0KiB/3.0KiB\n", out.String())
+}
@@ -0,0 +1,146 @@
+package upload
+
+import (
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+)
+
+// DefaultMaxRetries is how many times a failed request of an upload is retried
+const DefaultMaxRetries = 3
+
+// Uploader uploads finished support bundle archives
+type Uploader interface {
+	// Upload uploads the archive at archivePath, and returns where it was uploaded to
+	Upload(archivePath string) (string, error)
+}
+
+// Options are the settings of an upload
+type Options struct {
+	// ServerSideEncryption is the encryption that the bucket applies to the archive, AES256 or aws:kms.
+	// The bucket's default encryption is used if it is not set.
+	ServerSideEncryption string
+	// SSEKMSKeyID is the KMS key of aws:kms encryption, the bucket's key if it is not set
+	SSEKMSKeyID string
+	// MaxRetries is how many times a failed request, such as the upload of a part, is retried.
+	// DefaultMaxRetries is used if it is 0.
+	MaxRetries int
+	// PartSize is the size of the parts of archives that are uploaded in parts. Archives smaller than a
+	// part are uploaded with a single request. The SDK's default of 5 MiB is used if it is 0.
+	PartSize int64
+}
+
+func (o Options) validate() error {
+	switch o.ServerSideEncryption {
+	case "", s3.ServerSideEncryptionAes256:
+		if o.SSEKMSKeyID != "" {
+			return errors.New("a kms key id needs aws:kms server side encryption")
+		}
+	case s3.ServerSideEncryptionAwsKms:
+	default:
+		return errors.Errorf("unsupported server side encryption %q, use AES256 or aws:kms", o.ServerSideEncryption)
+	}
+	return nil
+}
+
+// NewUploader creates the uploader of uploadURL. Only S3 is supported, with s3://bucket/prefix urls.
+// Credentials are read from the environment or the shared AWS config. For MinIO or other S3
+// compatible servers, the url can have the server in an endpoint parameter, e.g.
+// s3://bundles/cluster-1?endpoint=http://minio.minio.svc:9000, and a region parameter sets the region.
+func NewUploader(uploadURL string, opts Options) (Uploader, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(uploadURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse upload url")
+	}
+	if u.Scheme != "s3" {
+		return nil, errors.Errorf("unsupported upload url %s, only s3://bucket/prefix urls are supported", uploadURL)
+	}
+	if u.Host == "" {
+		return nil, errors.Errorf("upload url %s has no bucket", uploadURL)
+	}
+
+	maxRetries := opts.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+
+	config := aws.NewConfig().WithMaxRetries(maxRetries)
+	if endpoint := u.Query().Get("endpoint"); endpoint != "" {
+		config = config.WithEndpoint(endpoint).WithS3ForcePathStyle(true)
+		// S3 compatible servers ignore the region, but the signature needs one
+		config = config.WithRegion("us-east-1")
+	}
+	if region := u.Query().Get("region"); region != "" {
+		config = config.WithRegion(region)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *config,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create session")
+	}
+
+	return &S3Uploader{
+		Client:  s3.New(sess),
+		Bucket:  u.Host,
+		Prefix:  strings.Trim(u.Path, "/"),
+		Options: opts,
+	}, nil
+}
+
+// S3Uploader uploads archives to a bucket, in parts if they are larger than a part
+type S3Uploader struct {
+	Client s3iface.S3API
+	Bucket string
+	// Prefix is joined with the archive's file name to make the object key
+	Prefix  string
+	Options Options
+}
+
+func (u *S3Uploader) Upload(archivePath string) (string, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open archive")
+	}
+	defer f.Close()
+
+	input := &s3manager.UploadInput{
+		Bucket:      aws.String(u.Bucket),
+		Key:         aws.String(path.Join(u.Prefix, filepath.Base(archivePath))),
+		Body:        f,
+		ContentType: aws.String("application/tar+gzip"),
+	}
+	if u.Options.ServerSideEncryption != "" {
+		input.ServerSideEncryption = aws.String(u.Options.ServerSideEncryption)
+	}
+	if u.Options.SSEKMSKeyID != "" {
+		input.SSEKMSKeyId = aws.String(u.Options.SSEKMSKeyID)
+	}
+
+	uploader := s3manager.NewUploaderWithClient(u.Client, func(uploader *s3manager.Uploader) {
+		if u.Options.PartSize > 0 {
+			uploader.PartSize = u.Options.PartSize
+		}
+	})
+	output, err := uploader.Upload(input)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to upload to s3://%s/%s", u.Bucket, aws.StringValue(input.Key))
+	}
+
+	return output.Location, nil
+}
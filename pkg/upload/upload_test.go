@@ -0,0 +1,108 @@
+package upload
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestS3Uploader_Upload(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "id")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	archivePath := filepath.Join(t.TempDir(), "support-bundle.tar.gz")
+	require.NoError(t, ioutil.WriteFile(archivePath, []byte("archive"), 0644))
+
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/bundles/cluster-1/support-bundle.tar.gz", r.URL.Path)
+		assert.Equal(t, "aws:kms", r.Header.Get("X-Amz-Server-Side-Encryption"))
+		assert.Equal(t, "key-1", r.Header.Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"))
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	uploader, err := NewUploader("s3://bundles/cluster-1/?endpoint="+server.URL, Options{
+		ServerSideEncryption: "aws:kms",
+		SSEKMSKeyID:          "key-1",
+	})
+	require.NoError(t, err)
+
+	location, err := uploader.Upload(archivePath)
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/bundles/cluster-1/support-bundle.tar.gz", location)
+	assert.Equal(t, "archive", string(body))
+}
+
+func TestS3Uploader_Upload_Multipart(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "id")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	partSize := int64(5 * 1024 * 1024)
+	contents := bytes.Repeat([]byte("a"), int(partSize)+10)
+	archivePath := filepath.Join(t.TempDir(), "support-bundle.tar.gz")
+	require.NoError(t, ioutil.WriteFile(archivePath, contents, 0644))
+
+	mu := sync.Mutex{}
+	parts := map[string]int{}
+	failed := false
+	completed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		query := r.URL.Query()
+		switch {
+		case r.Method == http.MethodPost && query.Has("uploads"):
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><Bucket>bundles</Bucket><Key>support-bundle.tar.gz</Key><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == http.MethodPut && query.Get("uploadId") == "upload-1":
+			// the first part fails once, and is retried
+			if query.Get("partNumber") == "1" && !failed {
+				failed = true
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			b, _ := ioutil.ReadAll(r.Body)
+			parts[query.Get("partNumber")] = len(b)
+			w.Header().Set("ETag", `"etag-`+query.Get("partNumber")+`"`)
+		case r.Method == http.MethodPost && query.Get("uploadId") == "upload-1":
+			completed = true
+			fmt.Fprint(w, `<CompleteMultipartUploadResult><Location>s3://bundles/support-bundle.tar.gz</Location></CompleteMultipartUploadResult>`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	}))
+	defer server.Close()
+
+	uploader, err := NewUploader("s3://bundles?endpoint="+server.URL, Options{PartSize: partSize})
+	require.NoError(t, err)
+
+	_, err = uploader.Upload(archivePath)
+	require.NoError(t, err)
+	assert.True(t, failed)
+	assert.True(t, completed)
+	assert.Equal(t, map[string]int{"1": int(partSize), "2": 10}, parts)
+}
+
+func TestNewUploader_Invalid(t *testing.T) {
+	_, err := NewUploader("https://bundles.example.com", Options{})
+	assert.Error(t, err)
+
+	_, err = NewUploader("s3:///prefix", Options{})
+	assert.Error(t, err)
+
+	_, err = NewUploader("s3://bundles", Options{ServerSideEncryption: "rot13"})
+	assert.Error(t, err)
+
+	_, err = NewUploader("s3://bundles", Options{SSEKMSKeyID: "key-1"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/collect"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// defaulter sets the defaults applied when a spec is run, so the stored spec shows what will be collected
+type defaulter struct{}
+
+var _ admission.CustomDefaulter = &defaulter{}
+
+func (d *defaulter) Default(ctx context.Context, obj runtime.Object) error {
+	switch o := obj.(type) {
+	case *troubleshootv1beta2.Preflight:
+		SetPreflightDefaults(o)
+	case *troubleshootv1beta2.SupportBundle:
+		SetSupportBundleDefaults(o)
+	case *troubleshootv1beta2.Redactor:
+		SetRedactorDefaults(o)
+	default:
+		return errors.Errorf("unexpected type %T", obj)
+	}
+	return nil
+}
+
+// SetPreflightDefaults adds the cluster info and cluster resources collectors that are always run
+func SetPreflightDefaults(preflight *troubleshootv1beta2.Preflight) {
+	preflight.Spec.Collectors = defaultCollectors(preflight.Spec.Collectors)
+}
+
+// SetSupportBundleDefaults adds the cluster info and cluster resources collectors that are always run
+func SetSupportBundleDefaults(supportBundle *troubleshootv1beta2.SupportBundle) {
+	supportBundle.Spec.Collectors = defaultCollectors(supportBundle.Spec.Collectors)
+}
+
+// SetRedactorDefaults names unnamed redactors the same way they are named in redaction reports
+func SetRedactorDefaults(redactor *troubleshootv1beta2.Redactor) {
+	for i, redact := range redactor.Spec.Redactors {
+		if redact != nil && redact.Name == "" {
+			redact.Name = fmt.Sprintf("unnamed-%d", i)
+		}
+	}
+}
+
+func defaultCollectors(collectors []*troubleshootv1beta2.Collect) []*troubleshootv1beta2.Collect {
+	collectors = collect.EnsureCollectorInList(collectors, troubleshootv1beta2.Collect{ClusterInfo: &troubleshootv1beta2.ClusterInfo{}})
+	collectors = collect.EnsureCollectorInList(collectors, troubleshootv1beta2.Collect{ClusterResources: &troubleshootv1beta2.ClusterResources{}})
+	return collect.EnsureClusterResourcesFirst(collectors)
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	troubleshootscheme "github.com/replicatedhq/troubleshoot/pkg/client/troubleshootclientset/scheme"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+type ServerOptions struct {
+	Host    string
+	Port    int
+	CertDir string
+}
+
+// Run starts an admission webhook server that defaults and validates Preflight, SupportBundle and
+// Redactor resources until ctx is cancelled. The server serves TLS with tls.crt and tls.key from CertDir.
+func Run(ctx context.Context, opts ServerOptions) error {
+	server := &webhook.Server{
+		Host:    opts.Host,
+		Port:    opts.Port,
+		CertDir: opts.CertDir,
+	}
+
+	Register(server)
+
+	if err := server.StartStandalone(ctx, troubleshootscheme.Scheme); err != nil {
+		return errors.Wrap(err, "failed to run webhook server")
+	}
+
+	return nil
+}
+
+// Register adds the mutating and validating webhooks for each troubleshoot kind to the server
+func Register(server *webhook.Server) {
+	for _, obj := range []runtime.Object{
+		&troubleshootv1beta2.Preflight{},
+		&troubleshootv1beta2.SupportBundle{},
+		&troubleshootv1beta2.Redactor{},
+	} {
+		server.Register(MutatePath(obj), admission.WithCustomDefaulter(obj, &defaulter{}))
+		server.Register(ValidatePath(obj), admission.WithCustomValidator(obj, &validator{}))
+	}
+}
+
+// MutatePath returns the path the defaulting webhook for the kind is served on, e.g. /mutate-troubleshoot-sh-v1beta2-preflight
+func MutatePath(obj runtime.Object) string {
+	return "/mutate-" + pathSuffix(obj)
+}
+
+// ValidatePath returns the path the validating webhook for the kind is served on, e.g. /validate-troubleshoot-sh-v1beta2-preflight
+func ValidatePath(obj runtime.Object) string {
+	return "/validate-" + pathSuffix(obj)
+}
+
+func pathSuffix(obj runtime.Object) string {
+	kind := fmt.Sprintf("%T", obj)
+	kind = kind[strings.LastIndex(kind, ".")+1:]
+	group := strings.ReplaceAll(troubleshootv1beta2.SchemeGroupVersion.Group, ".", "-")
+	return fmt.Sprintf("%s-%s-%s", group, troubleshootv1beta2.SchemeGroupVersion.Version, strings.ToLower(kind))
+}
@@ -0,0 +1,271 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/replicatedhq/troubleshoot/pkg/redact"
+	"github.com/replicatedhq/troubleshoot/pkg/strictdecode"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// validator rejects troubleshoot specs that would fail or do nothing when they are run
+type validator struct{}
+
+var _ admission.CustomValidator = &validator{}
+
+func (v *validator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	return v.validate(ctx, obj)
+}
+
+func (v *validator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	return v.validate(ctx, newObj)
+}
+
+func (v *validator) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+func (v *validator) validate(ctx context.Context, obj runtime.Object) error {
+	allErrs := field.ErrorList{}
+
+	// the decoded object has already lost any unknown fields, so they are checked in the raw request
+	if req, err := admission.RequestFromContext(ctx); err == nil && len(req.Object.Raw) > 0 {
+		var fieldErrors strictdecode.FieldErrors
+		if err := strictdecode.Validate(req.Object.Raw); errors.As(err, &fieldErrors) {
+			for _, fieldError := range fieldErrors {
+				allErrs = append(allErrs, field.Forbidden(field.NewPath(fieldError.Path), fieldError.Message))
+			}
+		}
+	}
+
+	var name string
+	switch o := obj.(type) {
+	case *troubleshootv1beta2.Preflight:
+		name = o.Name
+		allErrs = append(allErrs, ValidatePreflight(o)...)
+	case *troubleshootv1beta2.SupportBundle:
+		name = o.Name
+		allErrs = append(allErrs, ValidateSupportBundle(o)...)
+	case *troubleshootv1beta2.Redactor:
+		name = o.Name
+		allErrs = append(allErrs, ValidateRedactor(o)...)
+	default:
+		return errors.Errorf("unexpected type %T", obj)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(obj.GetObjectKind().GroupVersionKind().GroupKind(), name, allErrs)
+}
+
+// ValidatePreflight returns the problems found in a preflight spec
+func ValidatePreflight(preflight *troubleshootv1beta2.Preflight) field.ErrorList {
+	specPath := field.NewPath("spec")
+
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, validateCollectors(preflight.Spec.Collectors, specPath.Child("collectors"))...)
+	allErrs = append(allErrs, validateCollectors(preflight.Spec.RemoteCollectors, specPath.Child("remoteCollectors"))...)
+	allErrs = append(allErrs, validateAnalyzers(preflight.Spec.Analyzers, specPath.Child("analyzers"))...)
+
+	if len(preflight.Spec.Analyzers) == 0 {
+		allErrs = append(allErrs, field.Required(specPath.Child("analyzers"), "a preflight must have at least one analyzer"))
+	}
+
+	return allErrs
+}
+
+// ValidateSupportBundle returns the problems found in a support bundle spec
+func ValidateSupportBundle(supportBundle *troubleshootv1beta2.SupportBundle) field.ErrorList {
+	specPath := field.NewPath("spec")
+
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, validateCollectors(supportBundle.Spec.Collectors, specPath.Child("collectors"))...)
+	allErrs = append(allErrs, validateCollectors(supportBundle.Spec.HostCollectors, specPath.Child("hostCollectors"))...)
+	allErrs = append(allErrs, validateAnalyzers(supportBundle.Spec.Analyzers, specPath.Child("analyzers"))...)
+	allErrs = append(allErrs, validateAnalyzers(supportBundle.Spec.HostAnalyzers, specPath.Child("hostAnalyzers"))...)
+
+	return allErrs
+}
+
+// ValidateRedactor returns the problems found in a redactor spec
+func ValidateRedactor(redactor *troubleshootv1beta2.Redactor) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	excludePath := field.NewPath("spec", "excludeDefaultRedactors")
+	for i, group := range redactor.Spec.ExcludeDefaultRedactors {
+		if err := redact.ValidateDefaultRedactorGroups([]string{group}); err != nil {
+			allErrs = append(allErrs, field.Invalid(excludePath.Index(i), group, err.Error()))
+		}
+	}
+
+	redactorsPath := field.NewPath("spec", "redactors")
+	for i, redact := range redactor.Spec.Redactors {
+		path := redactorsPath.Index(i)
+		if redact == nil {
+			allErrs = append(allErrs, field.Required(path, "redactor must not be empty"))
+			continue
+		}
+
+		removals := redact.Removals
+		if len(removals.Values) == 0 && len(removals.ValuesFrom) == 0 && len(removals.Regex) == 0 && len(removals.YamlPath) == 0 && len(removals.JSONPath) == 0 {
+			allErrs = append(allErrs, field.Required(path.Child("removals"), "at least one of values, valuesFrom, regex, yamlPath or jsonPath is required"))
+		}
+
+		for j, valueFrom := range removals.ValuesFrom {
+			if err := validateValueFrom(valueFrom, path.Child("removals", "valuesFrom").Index(j)); err != nil {
+				allErrs = append(allErrs, err)
+			}
+		}
+
+		for j, regex := range removals.Regex {
+			regexPath := path.Child("removals", "regex").Index(j)
+			if regex.Redactor == "" {
+				allErrs = append(allErrs, field.Required(regexPath.Child("redactor"), ""))
+			}
+			allErrs = append(allErrs, validateRegex(regex.Selector, regexPath.Child("selector"))...)
+			allErrs = append(allErrs, validateRegex(regex.Redactor, regexPath.Child("redactor"))...)
+		}
+	}
+
+	return allErrs
+}
+
+// validateValueFrom checks that a valuesFrom entry sets exactly one source
+func validateValueFrom(valueFrom troubleshootv1beta2.ValueFrom, path *field.Path) *field.Error {
+	set := []string{}
+	if valueFrom.EnvVar != "" {
+		set = append(set, "envVar")
+	}
+	if valueFrom.File != "" {
+		set = append(set, "file")
+	}
+	if valueFrom.SecretKeyRef != nil {
+		set = append(set, "secretKeyRef")
+	}
+
+	switch len(set) {
+	case 0:
+		return field.Required(path, "one of envVar, file or secretKeyRef is required")
+	case 1:
+		return nil
+	default:
+		return field.Invalid(path, set, fmt.Sprintf("only one of %v may be set in a single entry", set))
+	}
+}
+
+// validateCollectors checks that each entry in a list of collectors sets exactly one collector.
+// list is a slice of pointers to a Collect, HostCollect or RemoteCollect struct.
+func validateCollectors(list interface{}, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	forEachEntry(list, func(i int, entry reflect.Value) {
+		if err := validateOneOf(entry, path.Index(i)); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	})
+	return allErrs
+}
+
+// validateAnalyzers checks that each entry in a list of analyzers sets exactly one analyzer, and that
+// analyzers that choose an outcome have at least one outcome and valid regular expressions
+func validateAnalyzers(list interface{}, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	forEachEntry(list, func(i int, entry reflect.Value) {
+		entryPath := path.Index(i)
+		if err := validateOneOf(entry, entryPath); err != nil {
+			allErrs = append(allErrs, err)
+			return
+		}
+
+		analyzer, name := setField(entry)
+		if analyzer.Kind() != reflect.Struct {
+			return
+		}
+		analyzerPath := entryPath.Child(name)
+
+		if outcomes := analyzer.FieldByName("Outcomes"); outcomes.IsValid() && outcomes.Kind() == reflect.Slice && outcomes.Len() == 0 {
+			allErrs = append(allErrs, field.Required(analyzerPath.Child("outcomes"), "at least one outcome is required"))
+		}
+
+		if regex := analyzer.FieldByName("RegexPattern"); regex.IsValid() && regex.Kind() == reflect.String {
+			allErrs = append(allErrs, validateRegex(regex.String(), analyzerPath.Child("regex"))...)
+		}
+		if regex := analyzer.FieldByName("RegexGroups"); regex.IsValid() && regex.Kind() == reflect.String {
+			allErrs = append(allErrs, validateRegex(regex.String(), analyzerPath.Child("regexGroups"))...)
+		}
+	})
+	return allErrs
+}
+
+func validateRegex(expression string, path *field.Path) field.ErrorList {
+	if expression == "" {
+		return nil
+	}
+	if _, err := regexp.Compile(expression); err != nil {
+		return field.ErrorList{field.Invalid(path, expression, err.Error())}
+	}
+	return nil
+}
+
+func forEachEntry(list interface{}, fn func(int, reflect.Value)) {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice {
+		return
+	}
+	for i := 0; i < v.Len(); i++ {
+		fn(i, v.Index(i))
+	}
+}
+
+// validateOneOf returns an error unless exactly one of the pointer fields of the struct that entry points to is set
+func validateOneOf(entry reflect.Value, path *field.Path) *field.Error {
+	if entry.IsNil() {
+		return field.Required(path, "entry must not be empty")
+	}
+
+	set := []string{}
+	s := entry.Elem()
+	for i := 0; i < s.NumField(); i++ {
+		if s.Field(i).Kind() == reflect.Ptr && !s.Field(i).IsNil() {
+			set = append(set, jsonName(s.Type().Field(i)))
+		}
+	}
+
+	switch len(set) {
+	case 0:
+		return field.Required(path, "entry must not be empty")
+	case 1:
+		return nil
+	default:
+		return field.Invalid(path, set, fmt.Sprintf("only one of %v may be set in a single entry", set))
+	}
+}
+
+// setField returns the struct and json name of the only pointer field set in the struct that entry points to
+func setField(entry reflect.Value) (reflect.Value, string) {
+	s := entry.Elem()
+	for i := 0; i < s.NumField(); i++ {
+		if s.Field(i).Kind() == reflect.Ptr && !s.Field(i).IsNil() {
+			return s.Field(i).Elem(), jsonName(s.Type().Field(i))
+		}
+	}
+	return reflect.Value{}, ""
+}
+
+func jsonName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
@@ -0,0 +1,196 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	troubleshootscheme "github.com/replicatedhq/troubleshoot/pkg/client/troubleshootclientset/scheme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func Test_ValidatePreflight(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      troubleshootv1beta2.PreflightSpec
+		wantField []string
+	}{
+		{
+			name: "valid",
+			spec: troubleshootv1beta2.PreflightSpec{
+				Collectors: []*troubleshootv1beta2.Collect{
+					{ClusterInfo: &troubleshootv1beta2.ClusterInfo{}},
+				},
+				Analyzers: []*troubleshootv1beta2.Analyze{
+					{
+						ClusterVersion: &troubleshootv1beta2.ClusterVersion{
+							Outcomes: []*troubleshootv1beta2.Outcome{{Pass: &troubleshootv1beta2.SingleOutcome{Message: "ok"}}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "two collectors in one entry",
+			spec: troubleshootv1beta2.PreflightSpec{
+				Collectors: []*troubleshootv1beta2.Collect{
+					{ClusterInfo: &troubleshootv1beta2.ClusterInfo{}, ClusterResources: &troubleshootv1beta2.ClusterResources{}},
+				},
+				Analyzers: []*troubleshootv1beta2.Analyze{
+					{
+						ClusterVersion: &troubleshootv1beta2.ClusterVersion{
+							Outcomes: []*troubleshootv1beta2.Outcome{{Pass: &troubleshootv1beta2.SingleOutcome{Message: "ok"}}},
+						},
+					},
+				},
+			},
+			wantField: []string{"spec.collectors[0]"},
+		},
+		{
+			name:      "no analyzers",
+			spec:      troubleshootv1beta2.PreflightSpec{},
+			wantField: []string{"spec.analyzers"},
+		},
+		{
+			name: "analyzer without outcomes and a bad regex",
+			spec: troubleshootv1beta2.PreflightSpec{
+				Analyzers: []*troubleshootv1beta2.Analyze{
+					{},
+					{
+						TextAnalyze: &troubleshootv1beta2.TextAnalyze{
+							RegexPattern: "([a-z",
+						},
+					},
+				},
+			},
+			wantField: []string{"spec.analyzers[0]", "spec.analyzers[1].textAnalyze.outcomes", "spec.analyzers[1].textAnalyze.regex"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidatePreflight(&troubleshootv1beta2.Preflight{Spec: tt.spec})
+
+			fields := []string{}
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			assert.ElementsMatch(t, tt.wantField, fields)
+		})
+	}
+}
+
+func Test_ValidateRedactor(t *testing.T) {
+	errs := ValidateRedactor(&troubleshootv1beta2.Redactor{
+		Spec: troubleshootv1beta2.RedactorSpec{
+			ExcludeDefaultRedactors: []string{"ipv4", "ipv5"},
+			Redactors: []*troubleshootv1beta2.Redact{
+				{Name: "empty"},
+				{
+					Name: "bad regex",
+					Removals: troubleshootv1beta2.Removals{
+						Regex: []troubleshootv1beta2.Regex{{Redactor: "(?P<mask>"}},
+					},
+				},
+				{
+					Name: "values from",
+					Removals: troubleshootv1beta2.Removals{
+						ValuesFrom: []troubleshootv1beta2.ValueFrom{
+							{EnvVar: "LICENSE_KEY"},
+							{},
+							{File: "/etc/license", SecretKeyRef: &troubleshootv1beta2.SecretKeyRef{Namespace: "default", Name: "license", Key: "key"}},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	require.Len(t, errs, 5)
+	assert.Equal(t, "spec.excludeDefaultRedactors[1]", errs[0].Field)
+	assert.Equal(t, "spec.redactors[0].removals", errs[1].Field)
+	assert.Equal(t, "spec.redactors[1].removals.regex[0].redactor", errs[2].Field)
+	assert.Equal(t, "spec.redactors[2].removals.valuesFrom[1]", errs[3].Field)
+	assert.Equal(t, field.ErrorTypeRequired, errs[3].Type)
+	assert.Equal(t, "spec.redactors[2].removals.valuesFrom[2]", errs[4].Field)
+	assert.Equal(t, field.ErrorTypeInvalid, errs[4].Type)
+}
+
+func Test_SetDefaults(t *testing.T) {
+	supportBundle := &troubleshootv1beta2.SupportBundle{
+		Spec: troubleshootv1beta2.SupportBundleSpec{
+			Collectors: []*troubleshootv1beta2.Collect{
+				{Logs: &troubleshootv1beta2.Logs{}},
+			},
+		},
+	}
+	SetSupportBundleDefaults(supportBundle)
+
+	require.Len(t, supportBundle.Spec.Collectors, 3)
+	assert.NotNil(t, supportBundle.Spec.Collectors[0].ClusterResources)
+	assert.NotNil(t, supportBundle.Spec.Collectors[1].Logs)
+	assert.NotNil(t, supportBundle.Spec.Collectors[2].ClusterInfo)
+
+	redactor := &troubleshootv1beta2.Redactor{
+		Spec: troubleshootv1beta2.RedactorSpec{
+			Redactors: []*troubleshootv1beta2.Redact{{}, {Name: "named"}},
+		},
+	}
+	SetRedactorDefaults(redactor)
+
+	assert.Equal(t, "unnamed-0", redactor.Spec.Redactors[0].Name)
+	assert.Equal(t, "named", redactor.Spec.Redactors[1].Name)
+}
+
+func Test_Webhooks(t *testing.T) {
+	request := func(raw string) admission.Request {
+		return admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				UID:       "uid",
+				Operation: admissionv1.Create,
+				Object:    runtime.RawExtension{Raw: []byte(raw)},
+			},
+		}
+	}
+
+	validating := admission.WithCustomValidator(&troubleshootv1beta2.Preflight{}, &validator{})
+	require.NoError(t, validating.InjectScheme(troubleshootscheme.Scheme))
+
+	resp := validating.Handle(context.Background(), request(`{
+  "apiVersion": "troubleshoot.sh/v1beta2",
+  "kind": "Preflight",
+  "metadata": {"name": "example"},
+  "spec": {"analyzers": [{"clusterVersion": {"outcomes": [{"pass": {"message": "ok"}}]}}]}
+}`))
+	assert.True(t, resp.Allowed)
+
+	resp = validating.Handle(context.Background(), request(`{
+  "apiVersion": "troubleshoot.sh/v1beta2",
+  "kind": "Preflight",
+  "metadata": {"name": "example"},
+  "spec": {"analyzerz": [{"clusterVersion": {"outcomes": [{"pass": {"message": "ok"}}]}}]}
+}`))
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, `did you mean "analyzers"?`)
+
+	mutating := admission.WithCustomDefaulter(&troubleshootv1beta2.Preflight{}, &defaulter{})
+	require.NoError(t, mutating.InjectScheme(troubleshootscheme.Scheme))
+
+	resp = mutating.Handle(context.Background(), request(`{
+  "apiVersion": "troubleshoot.sh/v1beta2",
+  "kind": "Preflight",
+  "metadata": {"name": "example"},
+  "spec": {"analyzers": [{"clusterVersion": {"outcomes": [{"pass": {"message": "ok"}}]}}]}
+}`))
+	assert.True(t, resp.Allowed)
+	assert.NotEmpty(t, resp.Patches)
+}
+
+func Test_Paths(t *testing.T) {
+	assert.Equal(t, "/mutate-troubleshoot-sh-v1beta2-preflight", MutatePath(&troubleshootv1beta2.Preflight{}))
+	assert.Equal(t, "/validate-troubleshoot-sh-v1beta2-supportbundle", ValidatePath(&troubleshootv1beta2.SupportBundle{}))
+}